@@ -0,0 +1,344 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	actions_model "code.gitea.io/gitea/models/actions"
+	"code.gitea.io/gitea/models/db"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/models/unittest"
+	"code.gitea.io/gitea/modules/json"
+	"code.gitea.io/gitea/modules/setting"
+	api "code.gitea.io/gitea/modules/structs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// testRunner is a minimal runner which talks to the runner API
+type testRunner struct {
+	t     *testing.T
+	token string
+}
+
+func (r *testRunner) request(method, url string, body []byte, expectedStatus int) *http.Response {
+	req := NewRequestWithBody(r.t, method, url, bytes.NewReader(body))
+	req.Header.Set("Authorization", "Bearer "+r.token)
+	return MakeRequest(r.t, req, expectedStatus).Result()
+}
+
+func (r *testRunner) fetch(expectedStatus int) *api.ActionRunnerJob {
+	req := NewRequest(r.t, "POST", "/api/actions/runner/fetch")
+	req.Header.Set("Authorization", "Bearer "+r.token)
+	resp := MakeRequest(r.t, req, expectedStatus)
+	if expectedStatus != http.StatusOK {
+		return nil
+	}
+
+	var job api.ActionRunnerJob
+	DecodeJSON(r.t, resp, &job)
+	return &job
+}
+
+func (r *testRunner) log(jobID int64, content string, expectedStatus int) {
+	r.request("POST", fmt.Sprintf("/api/actions/runner/jobs/%d/logs", jobID), []byte(content), expectedStatus)
+}
+
+func (r *testRunner) report(jobID int64, opts *api.UpdateActionRunnerJobOption, expectedStatus int) {
+	body, err := json.Marshal(opts)
+	assert.NoError(r.t, err)
+	r.request("POST", fmt.Sprintf("/api/actions/runner/jobs/%d/status", jobID), body, expectedStatus)
+}
+
+// pull lists the refs of the repository with the token of a job, the repository is treated as if viewing it required signing in
+func (r *testRunner) pull(repo *repo_model.Repository, jobToken string, expectedStatus int) {
+	defer func(requireSignInView bool) {
+		setting.Service.RequireSignInView = requireSignInView
+	}(setting.Service.RequireSignInView)
+	setting.Service.RequireSignInView = true
+
+	req := NewRequest(r.t, "GET", fmt.Sprintf("/%s.git/info/refs?service=git-upload-pack", repo.FullName()))
+	req.SetBasicAuth("x-access-token", jobToken)
+	MakeRequest(r.t, req, expectedStatus)
+}
+
+func TestActions(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	defer func(enabled bool) {
+		setting.Actions.Enabled = enabled
+	}(setting.Actions.Enabled)
+	setting.Actions.Enabled = true
+
+	repo := unittest.AssertExistsAndLoadBean(t, &repo_model.Repository{ID: 1}).(*repo_model.Repository)
+
+	adminSession := loginUser(t, "user1")
+	adminToken := getTokenForLoggedInUser(t, adminSession)
+
+	req := NewRequestWithJSON(t, "POST", "/api/v1/admin/actions/runners?token="+adminToken, &api.CreateActionRunnerOption{
+		Name:   "test-runner",
+		Labels: []string{"linux"},
+	})
+	resp := adminSession.MakeRequest(t, req, http.StatusCreated)
+
+	var apiRunner api.ActionRunner
+	DecodeJSON(t, resp, &apiRunner)
+	assert.Equal(t, "test-runner", apiRunner.Name)
+	assert.Len(t, apiRunner.Token, 40)
+
+	runner := &testRunner{t: t, token: apiRunner.Token}
+
+	t.Run("Unauthorized", func(t *testing.T) {
+		defer PrintCurrentTest(t)()
+
+		req := NewRequest(t, "POST", "/api/actions/runner/fetch")
+		MakeRequest(t, req, http.StatusUnauthorized)
+
+		req = NewRequest(t, "POST", "/api/actions/runner/fetch")
+		req.Header.Set("Authorization", "Bearer "+strings.Repeat("0", 40))
+		MakeRequest(t, req, http.StatusUnauthorized)
+	})
+
+	runner.fetch(http.StatusNoContent)
+
+	session := loginUser(t, "user2")
+	token := getTokenForLoggedInUser(t, session)
+
+	workflow := `name: CI
+on: push
+jobs:
+  build:
+    runs-on: linux
+    steps:
+      - name: Build
+        run: make build
+  test:
+    runs-on: linux
+    needs: build
+    steps:
+      - run: make test
+  deploy:
+    runs-on: linux
+    needs: test
+    steps:
+      - run: make deploy
+`
+	req = NewRequestWithJSON(t, "POST", fmt.Sprintf("/api/v1/repos/%s/contents/.gitea/workflows/ci.yml?token=%s", repo.FullName(), token), &api.CreateFileOptions{
+		FileOptions: api.FileOptions{
+			BranchName: repo.DefaultBranch,
+			Message:    "Add workflow",
+		},
+		Content: base64.StdEncoding.EncodeToString([]byte(workflow)),
+	})
+	resp = session.MakeRequest(t, req, http.StatusCreated)
+
+	var fileResponse api.FileResponse
+	DecodeJSON(t, resp, &fileResponse)
+	commitSHA := fileResponse.Commit.SHA
+
+	var run *actions_model.ActionRun
+	assert.Eventually(t, func() bool {
+		runs, _, err := actions_model.GetRunsByRepoID(db.DefaultContext, repo.ID, db.ListOptions{})
+		assert.NoError(t, err)
+		if len(runs) == 0 {
+			return false
+		}
+		run = runs[0]
+		return true
+	}, 30*time.Second, 100*time.Millisecond)
+
+	assert.Equal(t, "ci.yml", run.WorkflowID)
+	assert.Equal(t, "CI", run.Name)
+	assert.Equal(t, "push", run.Event)
+	assert.Equal(t, "refs/heads/"+repo.DefaultBranch, run.Ref)
+	assert.Equal(t, commitSHA, run.CommitSHA)
+	assert.Equal(t, actions_model.StatusWaiting, run.Status)
+
+	// getStatuses returns the latest status of every context
+	getStatuses := func(t *testing.T) map[string]*api.CommitStatus {
+		req := NewRequest(t, "GET", fmt.Sprintf("/api/v1/repos/%s/commits/%s/statuses?sort=leastindex&limit=50&token=%s", repo.FullName(), commitSHA, token))
+		resp := session.MakeRequest(t, req, http.StatusOK)
+
+		var statuses []*api.CommitStatus
+		DecodeJSON(t, resp, &statuses)
+
+		latest := make(map[string]*api.CommitStatus)
+		for _, s := range statuses {
+			if _, ok := latest[s.Context]; !ok {
+				latest[s.Context] = s
+			}
+		}
+		return latest
+	}
+
+	statuses := getStatuses(t)
+	assert.Len(t, statuses, 3)
+	assert.Equal(t, api.CommitStatusPending, statuses["CI / build (push)"].State)
+
+	var buildJobID int64
+
+	t.Run("Build", func(t *testing.T) {
+		defer PrintCurrentTest(t)()
+
+		job := runner.fetch(http.StatusOK)
+		assert.Equal(t, run.ID, job.RunID)
+		assert.Equal(t, "build", job.Name)
+		assert.Equal(t, repo.FullName(), job.Repository)
+		assert.Equal(t, commitSHA, job.CommitSHA)
+		assert.Len(t, job.Steps, 1)
+		assert.Equal(t, "Build", job.Steps[0].Name)
+		assert.Equal(t, "make build", job.Steps[0].Run)
+		assert.Len(t, job.Token, 40)
+
+		buildJobID = job.ID
+
+		runner.pull(repo, job.Token, http.StatusOK)
+		runner.pull(repo, strings.Repeat("0", 40), http.StatusUnauthorized)
+
+		// test needs build
+		runner.fetch(http.StatusNoContent)
+
+		runner.log(job.ID, "building\n", http.StatusCreated)
+		runner.report(job.ID, &api.UpdateActionRunnerJobOption{
+			Status: "running",
+			Steps:  []*api.UpdateActionRunnerStepOption{{Index: 0, Status: "running"}},
+		}, http.StatusNoContent)
+		runner.log(job.ID, "done\n", http.StatusCreated)
+		runner.report(job.ID, &api.UpdateActionRunnerJobOption{
+			Status: "invalid",
+		}, http.StatusBadRequest)
+		runner.report(job.ID, &api.UpdateActionRunnerJobOption{
+			Status: "success",
+			Steps:  []*api.UpdateActionRunnerStepOption{{Index: 0, Status: "success"}},
+		}, http.StatusNoContent)
+
+		runner.report(job.ID, &api.UpdateActionRunnerJobOption{Status: "success"}, http.StatusConflict)
+
+		// the token is revoked when the job is finished
+		runner.pull(repo, job.Token, http.StatusUnauthorized)
+
+		steps, err := actions_model.GetRunStepsByJobID(db.DefaultContext, job.ID)
+		assert.NoError(t, err)
+		assert.Len(t, steps, 1)
+		assert.Equal(t, actions_model.StatusSuccess, steps[0].Status)
+
+		statuses := getStatuses(t)
+		assert.Equal(t, api.CommitStatusSuccess, statuses["CI / build (push)"].State)
+		assert.Equal(t, api.CommitStatusPending, statuses["CI / test (push)"].State)
+		assert.Equal(t, fmt.Sprintf("%s/actions/jobs/%d/logs", repo.HTMLURL(), job.ID), statuses["CI / build (push)"].TargetURL)
+	})
+
+	t.Run("Logs", func(t *testing.T) {
+		defer PrintCurrentTest(t)()
+
+		req := NewRequest(t, "GET", fmt.Sprintf("/%s/actions/jobs/%d/logs", repo.FullName(), buildJobID))
+		resp := session.MakeRequest(t, req, http.StatusOK)
+		assert.Equal(t, "building\ndone\n", resp.Body.String())
+	})
+
+	t.Run("Test", func(t *testing.T) {
+		defer PrintCurrentTest(t)()
+
+		job := runner.fetch(http.StatusOK)
+		assert.Equal(t, "test", job.Name)
+
+		runner.report(job.ID, &api.UpdateActionRunnerJobOption{Status: "failure"}, http.StatusNoContent)
+
+		// deploy is skipped because test failed
+		runner.fetch(http.StatusNoContent)
+
+		statuses := getStatuses(t)
+		assert.Equal(t, api.CommitStatusFailure, statuses["CI / test (push)"].State)
+		assert.Equal(t, api.CommitStatusWarning, statuses["CI / deploy (push)"].State)
+
+		finishedRun, err := actions_model.GetRunByID(db.DefaultContext, run.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, actions_model.StatusFailure, finishedRun.Status)
+	})
+
+	t.Run("DeleteRunner", func(t *testing.T) {
+		defer PrintCurrentTest(t)()
+
+		req := NewRequest(t, "DELETE", fmt.Sprintf("/api/v1/admin/actions/runners/%d?token=%s", apiRunner.ID, adminToken))
+		adminSession.MakeRequest(t, req, http.StatusNoContent)
+
+		runner.fetch(http.StatusUnauthorized)
+	})
+}
+
+func TestActionsForkPullRequest(t *testing.T) {
+	onGiteaRun(t, func(t *testing.T, u *url.URL) {
+		defer func(enabled bool) {
+			setting.Actions.Enabled = enabled
+		}(setting.Actions.Enabled)
+		setting.Actions.Enabled = true
+
+		adminSession := loginUser(t, "user1")
+		adminToken := getTokenForLoggedInUser(t, adminSession)
+		req := NewRequestWithJSON(t, "POST", "/api/v1/admin/actions/runners?token="+adminToken, &api.CreateActionRunnerOption{
+			Name:   "test-runner",
+			Labels: []string{"linux"},
+		})
+		resp := adminSession.MakeRequest(t, req, http.StatusCreated)
+		var apiRunner api.ActionRunner
+		DecodeJSON(t, resp, &apiRunner)
+		runner := &testRunner{t: t, token: apiRunner.Token}
+
+		// user4 cannot write to user2/repo1 and opens a pull request from a fork
+		forkCtx := NewAPITestContext(t, "user4", "repo1")
+		t.Run("Fork", doAPIForkRepository(forkCtx, "user2"))
+		workflow := "on: pull_request\njobs:\n  test:\n    runs-on: linux\n    steps:\n      - run: make test\n"
+		t.Run("CreateWorkflow", doAPICreateFile(forkCtx, ".gitea/workflows/pr.yml", &api.CreateFileOptions{
+			FileOptions: api.FileOptions{
+				BranchName:    "master",
+				NewBranchName: "fork-pr",
+				Message:       "Add workflow",
+			},
+			Content: base64.StdEncoding.EncodeToString([]byte(workflow)),
+		}))
+		_, err := doAPICreatePullRequest(forkCtx, "user2", "repo1", "master", "user4:fork-pr")(t)
+		assert.NoError(t, err)
+
+		repo := unittest.AssertExistsAndLoadBean(t, &repo_model.Repository{OwnerName: "user2", Name: "repo1"}).(*repo_model.Repository)
+		var run *actions_model.ActionRun
+		assert.Eventually(t, func() bool {
+			runs, _, err := actions_model.GetRunsByRepoID(db.DefaultContext, repo.ID, db.ListOptions{})
+			assert.NoError(t, err)
+			if len(runs) == 0 {
+				return false
+			}
+			run = runs[0]
+			return true
+		}, 30*time.Second, 100*time.Millisecond)
+		assert.Equal(t, "pull_request", run.Event)
+		assert.True(t, run.NeedApproval)
+
+		// the jobs of the run are not handed to runners before the run is approved
+		runner.fetch(http.StatusNoContent)
+
+		approveURL := fmt.Sprintf("/api/v1/repos/user2/repo1/actions/runs/%d/approve?token=", run.ID)
+		req = NewRequest(t, "POST", approveURL+forkCtx.Token)
+		forkCtx.Session.MakeRequest(t, req, http.StatusForbidden)
+
+		session := loginUser(t, "user2")
+		token := getTokenForLoggedInUser(t, session)
+		req = NewRequest(t, "POST", approveURL+token)
+		session.MakeRequest(t, req, http.StatusNoContent)
+		req = NewRequest(t, "POST", approveURL+token)
+		session.MakeRequest(t, req, http.StatusConflict)
+
+		job := runner.fetch(http.StatusOK)
+		assert.Equal(t, run.ID, job.RunID)
+		assert.Equal(t, "test", job.Name)
+	})
+}
@@ -0,0 +1,104 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"testing"
+
+	quota_model "code.gitea.io/gitea/models/quota"
+	"code.gitea.io/gitea/models/unittest"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/setting"
+	api "code.gitea.io/gitea/modules/structs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIAdminQuota(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	defer func(enabled bool) {
+		setting.Quota.Enabled = enabled
+	}(setting.Quota.Enabled)
+	setting.Quota.Enabled = true
+
+	// user1 is an admin user
+	session := loginUser(t, "user1")
+	token := getTokenForLoggedInUser(t, session)
+
+	owner := unittest.AssertExistsAndLoadBean(t, &user_model.User{Name: "user2"}).(*user_model.User)
+
+	quotaURL := fmt.Sprintf("/api/v1/admin/users/%s/quota?token=%s", owner.Name, token)
+
+	t.Run("Default", func(t *testing.T) {
+		defer PrintCurrentTest(t)()
+
+		req := NewRequest(t, "GET", quotaURL)
+		resp := session.MakeRequest(t, req, http.StatusOK)
+
+		var q api.Quota
+		DecodeJSON(t, resp, &q)
+		assert.True(t, q.IsDefault)
+		assert.EqualValues(t, -1, q.LimitTotal)
+		assert.EqualValues(t, -1, q.LimitPackages)
+		assert.NotNil(t, q.Usage)
+	})
+
+	t.Run("Edit", func(t *testing.T) {
+		defer PrintCurrentTest(t)()
+
+		limit := int64(5)
+		req := NewRequestWithJSON(t, "PATCH", quotaURL, &api.EditQuotaOption{
+			LimitPackages: &limit,
+		})
+		resp := session.MakeRequest(t, req, http.StatusOK)
+
+		var q api.Quota
+		DecodeJSON(t, resp, &q)
+		assert.False(t, q.IsDefault)
+		assert.EqualValues(t, -1, q.LimitTotal)
+		assert.EqualValues(t, 5, q.LimitPackages)
+
+		unittest.AssertExistsAndLoadBean(t, &quota_model.Quota{OwnerID: owner.ID, LimitPackages: 5})
+	})
+
+	t.Run("Enforce", func(t *testing.T) {
+		defer PrintCurrentTest(t)()
+
+		packageURL := func(packageVersion string) string {
+			return fmt.Sprintf("/api/packages/%s/generic/quota-package/%s/file.bin", owner.Name, packageVersion)
+		}
+
+		req := NewRequestWithBody(t, "PUT", packageURL("1.0.0"), bytes.NewReader([]byte{1, 2, 3}))
+		AddBasicAuthHeader(req, owner.Name)
+		MakeRequest(t, req, http.StatusCreated)
+
+		req = NewRequestWithBody(t, "PUT", packageURL("1.0.1"), bytes.NewReader([]byte{4, 5, 6}))
+		AddBasicAuthHeader(req, owner.Name)
+		MakeRequest(t, req, http.StatusRequestEntityTooLarge)
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		defer PrintCurrentTest(t)()
+
+		req := NewRequest(t, "DELETE", quotaURL)
+		session.MakeRequest(t, req, http.StatusNoContent)
+
+		unittest.AssertNotExistsBean(t, &quota_model.Quota{OwnerID: owner.ID})
+	})
+
+	t.Run("NotAdmin", func(t *testing.T) {
+		defer PrintCurrentTest(t)()
+
+		session := loginUser(t, owner.Name)
+		token := getTokenForLoggedInUser(t, session)
+
+		req := NewRequest(t, "GET", fmt.Sprintf("/api/v1/admin/users/%s/quota?token=%s", owner.Name, token))
+		session.MakeRequest(t, req, http.StatusForbidden)
+	})
+}
@@ -0,0 +1,175 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	issues_model "code.gitea.io/gitea/models/issues"
+	"code.gitea.io/gitea/models/unittest"
+	api "code.gitea.io/gitea/modules/structs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIModifyCustomFields(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	session := loginUser(t, "user2")
+	token := getTokenForLoggedInUser(t, session)
+	urlStr := fmt.Sprintf("/api/v1/repos/user2/repo1/custom_fields?token=%s", token)
+
+	// CreateCustomField
+	req := NewRequestWithJSON(t, "POST", urlStr, &api.CreateCustomFieldOption{
+		Name:    "Affected Version",
+		Type:    "select",
+		Options: []string{"1.16", "1.17"},
+	})
+	resp := session.MakeRequest(t, req, http.StatusCreated)
+	apiField := new(api.CustomField)
+	DecodeJSON(t, resp, apiField)
+	unittest.AssertExistsAndLoadBean(t, &issues_model.CustomField{ID: apiField.ID, RepoID: 1, Name: "Affected Version"})
+	assert.Equal(t, []string{"1.16", "1.17"}, apiField.Options)
+
+	req = NewRequestWithJSON(t, "POST", urlStr, &api.CreateCustomFieldOption{Name: "Severity", Type: "text"})
+	session.MakeRequest(t, req, http.StatusUnprocessableEntity)
+	req = NewRequestWithJSON(t, "POST", urlStr, &api.CreateCustomFieldOption{Name: "Estimate", Type: "checkbox"})
+	session.MakeRequest(t, req, http.StatusUnprocessableEntity)
+
+	// ListCustomFields
+	req = NewRequest(t, "GET", urlStr)
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	var apiFields []*api.CustomField
+	DecodeJSON(t, resp, &apiFields)
+	assert.Len(t, apiFields, 3)
+
+	// EditCustomField
+	singleURLStr := fmt.Sprintf("/api/v1/repos/user2/repo1/custom_fields/%d?token=%s", apiField.ID, token)
+	newName := "Version"
+	req = NewRequestWithJSON(t, "PATCH", singleURLStr, &api.EditCustomFieldOption{
+		Name:    &newName,
+		Options: []string{"1.16", "1.17", "1.18"},
+	})
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	DecodeJSON(t, resp, apiField)
+	assert.Equal(t, "Version", apiField.Name)
+	assert.Equal(t, "select", apiField.Type)
+	assert.Len(t, apiField.Options, 3)
+
+	// the fields of other repositories and organizations cannot be edited
+	req = NewRequestWithJSON(t, "PATCH", fmt.Sprintf("/api/v1/repos/user2/repo1/custom_fields/2?token=%s", token), &api.EditCustomFieldOption{Name: &newName})
+	session.MakeRequest(t, req, http.StatusNotFound)
+
+	// DeleteCustomField
+	req = NewRequest(t, "DELETE", singleURLStr)
+	session.MakeRequest(t, req, http.StatusNoContent)
+	unittest.AssertNotExistsBean(t, &issues_model.CustomField{ID: apiField.ID})
+}
+
+func TestAPIIssueCustomFields(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	session := loginUser(t, "user2")
+	token := getTokenForLoggedInUser(t, session)
+
+	// ListIssueCustomFields
+	req := NewRequestf(t, "GET", "/api/v1/repos/user2/repo1/issues/1/custom_fields?token=%s", token)
+	resp := session.MakeRequest(t, req, http.StatusOK)
+	var values []*api.IssueCustomFieldValue
+	DecodeJSON(t, resp, &values)
+	if assert.Len(t, values, 2) {
+		assert.Equal(t, "Severity", values[0].Name)
+		assert.Equal(t, "critical", values[0].Value)
+		assert.Equal(t, "Reviewer", values[1].Name)
+		assert.Equal(t, "user", values[1].Type)
+		assert.Equal(t, "user4", values[1].Value)
+	}
+
+	// EditIssue
+	urlStr := fmt.Sprintf("/api/v1/repos/user2/repo1/issues/4?token=%s", token)
+	req = NewRequestWithJSON(t, "PATCH", urlStr, &api.EditIssueOption{
+		CustomFields: map[int64]string{1: "low", 3: "user5"},
+	})
+	session.MakeRequest(t, req, http.StatusCreated)
+	unittest.AssertExistsAndLoadBean(t, &issues_model.CustomFieldValue{IssueID: 5, FieldID: 1, Value: "low"})
+	unittest.AssertExistsAndLoadBean(t, &issues_model.CustomFieldValue{IssueID: 5, FieldID: 3, Value: "5"})
+
+	// invalid values and the fields of other organizations are rejected without changing anything
+	req = NewRequestWithJSON(t, "PATCH", urlStr, &api.EditIssueOption{
+		CustomFields: map[int64]string{1: "medium", 3: ""},
+	})
+	session.MakeRequest(t, req, http.StatusUnprocessableEntity)
+	req = NewRequestWithJSON(t, "PATCH", urlStr, &api.EditIssueOption{
+		CustomFields: map[int64]string{2: "ACME Corporation", 3: ""},
+	})
+	session.MakeRequest(t, req, http.StatusUnprocessableEntity)
+	unittest.AssertExistsAndLoadBean(t, &issues_model.CustomFieldValue{IssueID: 5, FieldID: 3, Value: "5"})
+
+	// ListIssues
+	filter := func(filters string, expectedIDs ...int64) {
+		req := NewRequestf(t, "GET", "/api/v1/repos/user2/repo1/issues?state=all&type=issues&%s&token=%s", filters, token)
+		resp := session.MakeRequest(t, req, http.StatusOK)
+		var apiIssues []*api.Issue
+		DecodeJSON(t, resp, &apiIssues)
+		ids := make([]int64, 0, len(apiIssues))
+		for _, issue := range apiIssues {
+			ids = append(ids, issue.ID)
+		}
+		assert.ElementsMatch(t, expectedIDs, ids)
+	}
+	filter("custom_fields=1:critical", 1)
+	filter("custom_fields=1:low", 5)
+	filter("custom_fields=1:low&custom_fields=3:user5", 5)
+	filter("custom_fields=1:critical&custom_fields=3:user5")
+
+	req = NewRequestf(t, "GET", "/api/v1/repos/user2/repo1/issues?custom_fields=1:medium&token=%s", token)
+	session.MakeRequest(t, req, http.StatusUnprocessableEntity)
+	req = NewRequestf(t, "GET", "/api/v1/repos/user2/repo1/issues?custom_fields=2:ACME&token=%s", token)
+	session.MakeRequest(t, req, http.StatusUnprocessableEntity)
+}
+
+func TestAPIOrgCustomFields(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	session := loginUser(t, "user2")
+	token := getTokenForLoggedInUser(t, session)
+	urlStr := fmt.Sprintf("/api/v1/orgs/user3/custom_fields?token=%s", token)
+
+	req := NewRequestWithJSON(t, "POST", urlStr, &api.CreateCustomFieldOption{Name: "Due Release", Type: "date"})
+	resp := session.MakeRequest(t, req, http.StatusCreated)
+	apiField := new(api.CustomField)
+	DecodeJSON(t, resp, apiField)
+	assert.True(t, apiField.IsOrgField)
+
+	req = NewRequest(t, "GET", urlStr)
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	var apiFields []*api.CustomField
+	DecodeJSON(t, resp, &apiFields)
+	assert.Len(t, apiFields, 2)
+
+	// the fields of an organization apply to the issues of its repositories
+	req = NewRequestf(t, "GET", "/api/v1/repos/user3/repo3/custom_fields?token=%s", token)
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	DecodeJSON(t, resp, &apiFields)
+	assert.Len(t, apiFields, 2)
+
+	req = NewRequestWithJSON(t, "PATCH", fmt.Sprintf("/api/v1/repos/user3/repo3/issues/1?token=%s", token), &api.EditIssueOption{
+		CustomFields: map[int64]string{apiField.ID: "2022-09-01", 2: "ACME Corporation"},
+	})
+	session.MakeRequest(t, req, http.StatusCreated)
+	unittest.AssertExistsAndLoadBean(t, &issues_model.CustomFieldValue{IssueID: 6, FieldID: apiField.ID, Value: "2022-09-01"})
+	unittest.AssertExistsAndLoadBean(t, &issues_model.CustomFieldValue{IssueID: 6, FieldID: 2, Value: "ACME Corporation"})
+
+	// only the owners of an organization can manage its fields
+	session = loginUser(t, "user4")
+	token = getTokenForLoggedInUser(t, session)
+	req = NewRequestWithJSON(t, "POST", fmt.Sprintf("/api/v1/orgs/user3/custom_fields?token=%s", token), &api.CreateCustomFieldOption{Name: "Team", Type: "text"})
+	session.MakeRequest(t, req, http.StatusForbidden)
+
+	req = NewRequestf(t, "DELETE", "/api/v1/orgs/user3/custom_fields/%d?token=%s", apiField.ID, token)
+	session.MakeRequest(t, req, http.StatusForbidden)
+}
@@ -0,0 +1,214 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/packages"
+	"code.gitea.io/gitea/models/unittest"
+	user_model "code.gitea.io/gitea/models/user"
+	alpine_module "code.gitea.io/gitea/modules/packages/alpine"
+	"code.gitea.io/gitea/modules/setting"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPackageAlpine(t *testing.T) {
+	defer prepareTestEnv(t)()
+	user := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 2}).(*user_model.User)
+
+	packageName := "gitea-test"
+	packageVersion := "1.0.2-r0"
+	packageArchitecture := "x86_64"
+	branch := "v3.16"
+	repository := "main"
+
+	createTarGz := func(name string, content []byte) []byte {
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		tw := tar.NewWriter(zw)
+		tw.WriteHeader(&tar.Header{
+			Name: name,
+			Mode: 0o600,
+			Size: int64(len(content)),
+		})
+		tw.Write(content)
+		tw.Close()
+		zw.Close()
+		return buf.Bytes()
+	}
+
+	control := createTarGz(".PKGINFO", []byte(`pkgname = `+packageName+`
+pkgver = `+packageVersion+`
+pkgdesc = Gitea Test Package
+url = https://gitea.io/
+builddate = 1662034800
+size = 1024
+arch = `+packageArchitecture+`
+license = MIT
+depend = musl
+`))
+	content := append(control, createTarGz("usr/bin/gitea-test", []byte("binary"))...)
+
+	filename := fmt.Sprintf("%s-%s.apk", packageName, packageVersion)
+
+	rootURL := fmt.Sprintf("/api/packages/%s/alpine", user.Name)
+	repositoryURL := fmt.Sprintf("%s/%s/%s", rootURL, branch, repository)
+
+	var publicKey string
+
+	t.Run("RepositoryKey", func(t *testing.T) {
+		defer PrintCurrentTest(t)()
+
+		req := NewRequest(t, "GET", rootURL+"/key")
+		resp := MakeRequest(t, req, http.StatusOK)
+
+		publicKey = resp.Body.String()
+		assert.Contains(t, publicKey, "-----BEGIN PUBLIC KEY-----")
+		assert.Contains(t, resp.Header().Get("Content-Disposition"), fmt.Sprintf("%s@%s.rsa.pub", user.LowerName, setting.Domain))
+	})
+
+	t.Run("Upload", func(t *testing.T) {
+		defer PrintCurrentTest(t)()
+
+		req := NewRequestWithBody(t, "PUT", repositoryURL, bytes.NewReader(content))
+		MakeRequest(t, req, http.StatusUnauthorized)
+
+		req = NewRequestWithBody(t, "PUT", repositoryURL, bytes.NewReader([]byte{}))
+		AddBasicAuthHeader(req, user.Name)
+		MakeRequest(t, req, http.StatusBadRequest)
+
+		req = NewRequestWithBody(t, "PUT", repositoryURL, bytes.NewReader(content))
+		AddBasicAuthHeader(req, user.Name)
+		MakeRequest(t, req, http.StatusCreated)
+
+		pvs, err := packages.GetVersionsByPackageType(db.DefaultContext, user.ID, packages.TypeAlpine)
+		assert.NoError(t, err)
+		assert.Len(t, pvs, 1)
+
+		pd, err := packages.GetPackageDescriptor(db.DefaultContext, pvs[0])
+		assert.NoError(t, err)
+		assert.Nil(t, pd.SemVer)
+		assert.IsType(t, &alpine_module.VersionMetadata{}, pd.Metadata)
+		assert.Equal(t, packageName, pd.Package.Name)
+		assert.Equal(t, packageVersion, pd.Version.Version)
+
+		pfs, err := packages.GetFilesByVersionID(db.DefaultContext, pvs[0].ID)
+		assert.NoError(t, err)
+		assert.Len(t, pfs, 1)
+		assert.Equal(t, filename, pfs[0].Name)
+		assert.True(t, pfs[0].IsLead)
+
+		req = NewRequestWithBody(t, "PUT", repositoryURL, bytes.NewReader(content))
+		AddBasicAuthHeader(req, user.Name)
+		MakeRequest(t, req, http.StatusConflict)
+	})
+
+	t.Run("Index", func(t *testing.T) {
+		defer PrintCurrentTest(t)()
+
+		req := NewRequest(t, "GET", fmt.Sprintf("%s/aarch64/APKINDEX.tar.gz", repositoryURL))
+		MakeRequest(t, req, http.StatusNotFound)
+
+		req = NewRequest(t, "GET", fmt.Sprintf("%s/%s/APKINDEX.tar.gz", repositoryURL, packageArchitecture))
+		resp := MakeRequest(t, req, http.StatusOK)
+
+		br := bytes.NewReader(resp.Body.Bytes())
+
+		gzr, err := gzip.NewReader(br)
+		assert.NoError(t, err)
+		gzr.Multistream(false)
+
+		tr := tar.NewReader(gzr)
+		hd, err := tr.Next()
+		assert.NoError(t, err)
+		assert.Equal(t, fmt.Sprintf(".SIGN.RSA.%s@%s.rsa.pub", user.LowerName, setting.Domain), hd.Name)
+		signature, err := io.ReadAll(tr)
+		assert.NoError(t, err)
+		_, err = io.Copy(io.Discard, gzr)
+		assert.NoError(t, err)
+
+		// the signature covers the remaining index stream
+		index := resp.Body.Bytes()[len(resp.Body.Bytes())-br.Len():]
+
+		block, _ := pem.Decode([]byte(publicKey))
+		pub, err := x509.ParsePKIXPublicKey(block.Bytes)
+		assert.NoError(t, err)
+		sum := sha1.Sum(index)
+		assert.NoError(t, rsa.VerifyPKCS1v15(pub.(*rsa.PublicKey), crypto.SHA1, sum[:], signature))
+
+		gzr, err = gzip.NewReader(bytes.NewReader(index))
+		assert.NoError(t, err)
+		tr = tar.NewReader(gzr)
+
+		found := false
+		for {
+			hd, err := tr.Next()
+			if err == io.EOF {
+				break
+			}
+			assert.NoError(t, err)
+			if hd.Name != "APKINDEX" {
+				continue
+			}
+			found = true
+
+			content, err := io.ReadAll(tr)
+			assert.NoError(t, err)
+
+			apkindex := string(content)
+			assert.True(t, strings.HasPrefix(apkindex, "C:Q1"))
+			assert.Contains(t, apkindex, "P:"+packageName+"\n")
+			assert.Contains(t, apkindex, "V:"+packageVersion+"\n")
+			assert.Contains(t, apkindex, "A:"+packageArchitecture+"\n")
+			assert.Contains(t, apkindex, "D:musl\n")
+		}
+		assert.True(t, found)
+	})
+
+	t.Run("Download", func(t *testing.T) {
+		defer PrintCurrentTest(t)()
+
+		req := NewRequest(t, "GET", fmt.Sprintf("%s/%s/%s", repositoryURL, packageArchitecture, filename))
+		resp := MakeRequest(t, req, http.StatusOK)
+
+		assert.Equal(t, content, resp.Body.Bytes())
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		defer PrintCurrentTest(t)()
+
+		deleteURL := fmt.Sprintf("%s/%s/%s", repositoryURL, packageArchitecture, filename)
+
+		req := NewRequest(t, "DELETE", deleteURL)
+		MakeRequest(t, req, http.StatusUnauthorized)
+
+		req = NewRequest(t, "DELETE", deleteURL)
+		AddBasicAuthHeader(req, user.Name)
+		MakeRequest(t, req, http.StatusNoContent)
+
+		req = NewRequest(t, "DELETE", deleteURL)
+		AddBasicAuthHeader(req, user.Name)
+		MakeRequest(t, req, http.StatusNotFound)
+
+		pvs, err := packages.GetVersionsByPackageType(db.DefaultContext, user.ID, packages.TypeAlpine)
+		assert.NoError(t, err)
+		assert.Empty(t, pvs)
+	})
+}
@@ -0,0 +1,188 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/packages"
+	"code.gitea.io/gitea/models/unittest"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/json"
+	cargo_module "code.gitea.io/gitea/modules/packages/cargo"
+	"code.gitea.io/gitea/modules/setting"
+	cargo_router "code.gitea.io/gitea/routers/api/packages/cargo"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPackageCargo(t *testing.T) {
+	defer prepareTestEnv(t)()
+	user := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 2}).(*user_model.User)
+
+	packageName := "gitea_test"
+	packageVersion := "1.0.3"
+	packageDescription := "Package Description"
+	crateContent := "crate content"
+
+	createPayload := func(version string) []byte {
+		metadata := `{"name":"` + packageName + `","vers":"` + version + `","description":"` + packageDescription + `","deps":[{"name":"dep","version_req":"^1.0","features":[],"optional":false,"default_features":true,"target":null,"kind":"normal","registry":null}],"features":{"default":[]},"license":"MIT"}`
+
+		var buf bytes.Buffer
+		binary.Write(&buf, binary.LittleEndian, uint32(len(metadata)))
+		buf.WriteString(metadata)
+		binary.Write(&buf, binary.LittleEndian, uint32(len(crateContent)))
+		buf.WriteString(crateContent)
+		return buf.Bytes()
+	}
+
+	rootURL := fmt.Sprintf("/api/packages/%s/cargo", user.Name)
+	indexURL := fmt.Sprintf("%s/ge/ni/%s", rootURL, packageName)
+	crateURL := fmt.Sprintf("%s/api/v1/crates/%s/%s", rootURL, packageName, packageVersion)
+
+	t.Run("RepositoryConfig", func(t *testing.T) {
+		defer PrintCurrentTest(t)()
+
+		req := NewRequest(t, "GET", rootURL+"/config.json")
+		resp := MakeRequest(t, req, http.StatusOK)
+
+		var config cargo_router.RegistryConfig
+		DecodeJSON(t, resp, &config)
+
+		url := setting.AppURL + rootURL[1:]
+		assert.Equal(t, url+"/api/v1/crates", config.DownloadURL)
+		assert.Equal(t, url, config.APIURL)
+	})
+
+	t.Run("Upload", func(t *testing.T) {
+		defer PrintCurrentTest(t)()
+
+		url := rootURL + "/api/v1/crates/new"
+
+		req := NewRequestWithBody(t, "PUT", url, bytes.NewReader(createPayload(packageVersion)))
+		MakeRequest(t, req, http.StatusUnauthorized)
+
+		req = NewRequestWithBody(t, "PUT", url, strings.NewReader("invalid"))
+		AddBasicAuthHeader(req, user.Name)
+		MakeRequest(t, req, http.StatusBadRequest)
+
+		req = NewRequestWithBody(t, "PUT", url, bytes.NewReader(createPayload(packageVersion)))
+		AddBasicAuthHeader(req, user.Name)
+		resp := MakeRequest(t, req, http.StatusOK)
+
+		var result cargo_router.PublishResponse
+		DecodeJSON(t, resp, &result)
+		assert.Empty(t, result.Warnings.Other)
+
+		pvs, err := packages.GetVersionsByPackageType(db.DefaultContext, user.ID, packages.TypeCargo)
+		assert.NoError(t, err)
+		assert.Len(t, pvs, 1)
+
+		pd, err := packages.GetPackageDescriptor(db.DefaultContext, pvs[0])
+		assert.NoError(t, err)
+		assert.NotNil(t, pd.SemVer)
+		assert.IsType(t, &cargo_module.Metadata{}, pd.Metadata)
+		assert.Equal(t, packageName, pd.Package.Name)
+		assert.Equal(t, packageVersion, pd.Version.Version)
+		assert.Equal(t, "false", pd.Properties.GetByName(cargo_module.PropertyYanked))
+
+		pfs, err := packages.GetFilesByVersionID(db.DefaultContext, pvs[0].ID)
+		assert.NoError(t, err)
+		assert.Len(t, pfs, 1)
+		assert.Equal(t, fmt.Sprintf("%s-%s.crate", packageName, packageVersion), pfs[0].Name)
+		assert.True(t, pfs[0].IsLead)
+
+		req = NewRequestWithBody(t, "PUT", url, bytes.NewReader(createPayload(packageVersion)))
+		AddBasicAuthHeader(req, user.Name)
+		MakeRequest(t, req, http.StatusConflict)
+	})
+
+	t.Run("Index", func(t *testing.T) {
+		defer PrintCurrentTest(t)()
+
+		req := NewRequest(t, "GET", fmt.Sprintf("%s/ab/cd/%s", rootURL, packageName))
+		MakeRequest(t, req, http.StatusNotFound)
+
+		req = NewRequest(t, "GET", indexURL)
+		resp := MakeRequest(t, req, http.StatusOK)
+
+		lines := strings.Split(strings.TrimSpace(resp.Body.String()), "\n")
+		assert.Len(t, lines, 1)
+
+		var entry cargo_router.IndexVersionEntry
+		assert.NoError(t, json.Unmarshal([]byte(lines[0]), &entry))
+		assert.Equal(t, packageName, entry.Name)
+		assert.Equal(t, packageVersion, entry.Version)
+		assert.False(t, entry.Yanked)
+		assert.Len(t, entry.Dependencies, 1)
+		assert.Equal(t, "dep", entry.Dependencies[0].Name)
+		assert.Equal(t, "^1.0", entry.Dependencies[0].Req)
+		assert.Contains(t, entry.Features, "default")
+		assert.Len(t, entry.Checksum, 64)
+	})
+
+	t.Run("Search", func(t *testing.T) {
+		defer PrintCurrentTest(t)()
+
+		req := NewRequest(t, "GET", rootURL+"/api/v1/crates?q=gitea")
+		resp := MakeRequest(t, req, http.StatusOK)
+
+		var result cargo_router.SearchResultResponse
+		DecodeJSON(t, resp, &result)
+
+		assert.EqualValues(t, 1, result.Meta.Total)
+		assert.Len(t, result.Crates, 1)
+		assert.Equal(t, packageName, result.Crates[0].Name)
+		assert.Equal(t, packageVersion, result.Crates[0].MaxVersion)
+		assert.Equal(t, packageDescription, result.Crates[0].Description)
+	})
+
+	t.Run("Download", func(t *testing.T) {
+		defer PrintCurrentTest(t)()
+
+		req := NewRequest(t, "GET", crateURL+"/download")
+		resp := MakeRequest(t, req, http.StatusOK)
+
+		assert.Equal(t, crateContent, resp.Body.String())
+	})
+
+	t.Run("Yank", func(t *testing.T) {
+		defer PrintCurrentTest(t)()
+
+		req := NewRequest(t, "DELETE", crateURL+"/yank")
+		MakeRequest(t, req, http.StatusUnauthorized)
+
+		req = NewRequest(t, "DELETE", crateURL+"/yank")
+		AddBasicAuthHeader(req, user.Name)
+		MakeRequest(t, req, http.StatusOK)
+
+		req = NewRequest(t, "GET", indexURL)
+		resp := MakeRequest(t, req, http.StatusOK)
+
+		var entry cargo_router.IndexVersionEntry
+		assert.NoError(t, json.Unmarshal(bytes.TrimSpace(resp.Body.Bytes()), &entry))
+		assert.True(t, entry.Yanked)
+
+		req = NewRequest(t, "PUT", crateURL+"/unyank")
+		AddBasicAuthHeader(req, user.Name)
+		MakeRequest(t, req, http.StatusOK)
+
+		req = NewRequest(t, "GET", indexURL)
+		resp = MakeRequest(t, req, http.StatusOK)
+
+		assert.NoError(t, json.Unmarshal(bytes.TrimSpace(resp.Body.Bytes()), &entry))
+		assert.False(t, entry.Yanked)
+
+		req = NewRequest(t, "DELETE", fmt.Sprintf("%s/api/v1/crates/%s/0.0.1/yank", rootURL, packageName))
+		AddBasicAuthHeader(req, user.Name)
+		MakeRequest(t, req, http.StatusNotFound)
+	})
+}
@@ -0,0 +1,202 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/packages"
+	"code.gitea.io/gitea/models/unittest"
+	user_model "code.gitea.io/gitea/models/user"
+	conda_module "code.gitea.io/gitea/modules/packages/conda"
+	conda_service "code.gitea.io/gitea/services/packages/conda"
+
+	"github.com/dsnet/compress/bzip2"
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPackageConda(t *testing.T) {
+	defer prepareTestEnv(t)()
+	user := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 2}).(*user_model.User)
+
+	packageName := "gitea-test"
+	packageVersion := "1.0.1"
+	packageBuild := "py_0"
+	packageSubdir := "noarch"
+	packageDescription := "Gitea Test Package"
+
+	indexJSON := `{"name":"` + packageName + `","version":"` + packageVersion + `","build":"` + packageBuild + `","build_number":0,"depends":["python >=3.7"],"license":"MIT","noarch":"python","subdir":"` + packageSubdir + `"}`
+	aboutJSON := `{"description":"` + packageDescription + `","home":"https://gitea.io"}`
+
+	createInfoTar := func() []byte {
+		var buf bytes.Buffer
+		tw := tar.NewWriter(&buf)
+		for name, content := range map[string]string{
+			"info/index.json": indexJSON,
+			"info/about.json": aboutJSON,
+		} {
+			tw.WriteHeader(&tar.Header{
+				Name: name,
+				Mode: 0o600,
+				Size: int64(len(content)),
+			})
+			tw.Write([]byte(content))
+		}
+		tw.Close()
+		return buf.Bytes()
+	}
+
+	var bz2Content bytes.Buffer
+	bw, _ := bzip2.NewWriter(&bz2Content, nil)
+	io.Copy(bw, bytes.NewReader(createInfoTar()))
+	bw.Close()
+
+	var condaContent bytes.Buffer
+	zw := zip.NewWriter(&condaContent)
+	w, _ := zw.Create(fmt.Sprintf("info-%s-%s-%s.tar.zst", packageName, packageVersion, packageBuild))
+	zsw, _ := zstd.NewWriter(w)
+	io.Copy(zsw, bytes.NewReader(createInfoTar()))
+	zsw.Close()
+	zw.Close()
+
+	filenameBz2 := fmt.Sprintf("%s-%s-%s.tar.bz2", packageName, packageVersion, packageBuild)
+	filenameConda := fmt.Sprintf("%s-%s-%s.conda", packageName, packageVersion, packageBuild)
+
+	root := fmt.Sprintf("/api/packages/%s/conda", user.Name)
+
+	t.Run("Upload", func(t *testing.T) {
+		defer PrintCurrentTest(t)()
+
+		req := NewRequestWithBody(t, "PUT", root+"/"+filenameBz2, bytes.NewReader(bz2Content.Bytes()))
+		MakeRequest(t, req, http.StatusUnauthorized)
+
+		req = NewRequestWithBody(t, "PUT", root+"/package.zip", bytes.NewReader(bz2Content.Bytes()))
+		AddBasicAuthHeader(req, user.Name)
+		MakeRequest(t, req, http.StatusBadRequest)
+
+		req = NewRequestWithBody(t, "PUT", root+"/"+filenameBz2, bytes.NewReader(bz2Content.Bytes()))
+		AddBasicAuthHeader(req, user.Name)
+		MakeRequest(t, req, http.StatusCreated)
+
+		req = NewRequestWithBody(t, "PUT", root+"/"+filenameConda, bytes.NewReader(condaContent.Bytes()))
+		AddBasicAuthHeader(req, user.Name)
+		MakeRequest(t, req, http.StatusCreated)
+
+		pvs, err := packages.GetVersionsByPackageType(db.DefaultContext, user.ID, packages.TypeConda)
+		assert.NoError(t, err)
+		assert.Len(t, pvs, 1)
+
+		pd, err := packages.GetPackageDescriptor(db.DefaultContext, pvs[0])
+		assert.NoError(t, err)
+		assert.Nil(t, pd.SemVer)
+		assert.IsType(t, &conda_module.VersionMetadata{}, pd.Metadata)
+		assert.Equal(t, packageName, pd.Package.Name)
+		assert.Equal(t, packageVersion, pd.Version.Version)
+		assert.Equal(t, packageDescription, pd.Metadata.(*conda_module.VersionMetadata).Description)
+
+		pfs, err := packages.GetFilesByVersionID(db.DefaultContext, pvs[0].ID)
+		assert.NoError(t, err)
+		assert.Len(t, pfs, 2)
+
+		req = NewRequestWithBody(t, "PUT", root+"/"+filenameConda, bytes.NewReader(condaContent.Bytes()))
+		AddBasicAuthHeader(req, user.Name)
+		MakeRequest(t, req, http.StatusConflict)
+	})
+
+	t.Run("ChannelData", func(t *testing.T) {
+		defer PrintCurrentTest(t)()
+
+		req := NewRequest(t, "GET", root+"/channeldata.json")
+		resp := MakeRequest(t, req, http.StatusOK)
+
+		var result conda_service.ChannelData
+		DecodeJSON(t, resp, &result)
+
+		assert.Equal(t, []string{packageSubdir}, result.Subdirs)
+		assert.Contains(t, result.Packages, packageName)
+		entry := result.Packages[packageName]
+		assert.Equal(t, packageVersion, entry.Version)
+		assert.Equal(t, packageDescription, entry.Description)
+		assert.Equal(t, "https://gitea.io", entry.Home)
+		assert.Equal(t, []string{packageSubdir}, entry.Subdirs)
+	})
+
+	t.Run("RepositoryData", func(t *testing.T) {
+		defer PrintCurrentTest(t)()
+
+		req := NewRequest(t, "GET", root+"/linux-64/repodata.json")
+		resp := MakeRequest(t, req, http.StatusOK)
+
+		var result conda_service.RepositoryData
+		DecodeJSON(t, resp, &result)
+		assert.Equal(t, "linux-64", result.Info.Subdir)
+		assert.Empty(t, result.Packages)
+		assert.Empty(t, result.PackagesConda)
+
+		req = NewRequest(t, "GET", fmt.Sprintf("%s/%s/repodata.json", root, packageSubdir))
+		resp = MakeRequest(t, req, http.StatusOK)
+
+		result = conda_service.RepositoryData{}
+		DecodeJSON(t, resp, &result)
+		assert.Equal(t, packageSubdir, result.Info.Subdir)
+		assert.Equal(t, 1, result.RepodataVersion)
+		assert.Contains(t, result.Packages, filenameBz2)
+		assert.Contains(t, result.PackagesConda, filenameConda)
+
+		entry := result.PackagesConda[filenameConda]
+		assert.Equal(t, packageName, entry.Name)
+		assert.Equal(t, packageVersion, entry.Version)
+		assert.Equal(t, packageBuild, entry.Build)
+		assert.Equal(t, []string{"python >=3.7"}, entry.Dependencies)
+		assert.Equal(t, int64(condaContent.Len()), entry.Size)
+		assert.NotEmpty(t, entry.SHA256)
+	})
+
+	t.Run("Download", func(t *testing.T) {
+		defer PrintCurrentTest(t)()
+
+		req := NewRequest(t, "GET", fmt.Sprintf("%s/%s/%s", root, packageSubdir, filenameBz2))
+		resp := MakeRequest(t, req, http.StatusOK)
+		assert.Equal(t, bz2Content.Bytes(), resp.Body.Bytes())
+
+		req = NewRequest(t, "GET", fmt.Sprintf("%s/%s/%s", root, packageSubdir, filenameConda))
+		resp = MakeRequest(t, req, http.StatusOK)
+		assert.Equal(t, condaContent.Bytes(), resp.Body.Bytes())
+
+		req = NewRequest(t, "GET", fmt.Sprintf("%s/linux-64/%s", root, filenameConda))
+		MakeRequest(t, req, http.StatusNotFound)
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		defer PrintCurrentTest(t)()
+
+		for _, filename := range []string{filenameBz2, filenameConda} {
+			deleteURL := fmt.Sprintf("%s/%s/%s", root, packageSubdir, filename)
+
+			req := NewRequest(t, "DELETE", deleteURL)
+			MakeRequest(t, req, http.StatusUnauthorized)
+
+			req = NewRequest(t, "DELETE", deleteURL)
+			AddBasicAuthHeader(req, user.Name)
+			MakeRequest(t, req, http.StatusNoContent)
+
+			req = NewRequest(t, "DELETE", deleteURL)
+			AddBasicAuthHeader(req, user.Name)
+			MakeRequest(t, req, http.StatusNotFound)
+		}
+
+		pvs, err := packages.GetVersionsByPackageType(db.DefaultContext, user.ID, packages.TypeConda)
+		assert.NoError(t, err)
+		assert.Empty(t, pvs)
+	})
+}
@@ -0,0 +1,208 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/packages"
+	"code.gitea.io/gitea/models/unittest"
+	user_model "code.gitea.io/gitea/models/user"
+	debian_module "code.gitea.io/gitea/modules/packages/debian"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPackageDebian(t *testing.T) {
+	defer prepareTestEnv(t)()
+	user := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 2}).(*user_model.User)
+
+	packageName := "gitea"
+	packageVersion := "1.0.3"
+	packageArchitecture := "amd64"
+	packageDescription := "Gitea Test Package"
+	distribution := "test"
+	component := "main"
+
+	createPackage := func(name, version, architecture string) []byte {
+		var cbuf bytes.Buffer
+		zw := gzip.NewWriter(&cbuf)
+		tw := tar.NewWriter(zw)
+		content := fmt.Sprintf("Package: %s\nVersion: %s\nArchitecture: %s\nMaintainer: KN4CK3R <dummy@gitea.io>\nDescription: %s\n", name, version, architecture, packageDescription)
+		tw.WriteHeader(&tar.Header{
+			Name: "./control",
+			Mode: 0o600,
+			Size: int64(len(content)),
+		})
+		tw.Write([]byte(content))
+		tw.Close()
+		zw.Close()
+
+		var buf bytes.Buffer
+		buf.WriteString("!<arch>\n")
+		for _, f := range []struct {
+			Name    string
+			Content []byte
+		}{
+			{"debian-binary", []byte("2.0\n")},
+			{"control.tar.gz", cbuf.Bytes()},
+		} {
+			fmt.Fprintf(&buf, "%-16s%-12s%-6s%-6s%-8s%-10d`\n", f.Name, "0", "0", "0", "100644", len(f.Content))
+			buf.Write(f.Content)
+			if len(f.Content)%2 != 0 {
+				buf.WriteByte('\n')
+			}
+		}
+		return buf.Bytes()
+	}
+
+	content := createPackage(packageName, packageVersion, packageArchitecture)
+	filename := fmt.Sprintf("%s_%s_%s.deb", packageName, packageVersion, packageArchitecture)
+
+	rootURL := fmt.Sprintf("/api/packages/%s/debian", user.Name)
+	poolURL := fmt.Sprintf("%s/pool/%s/%s", rootURL, distribution, component)
+
+	t.Run("RepositoryKey", func(t *testing.T) {
+		defer PrintCurrentTest(t)()
+
+		req := NewRequest(t, "GET", rootURL+"/repository.key")
+		resp := MakeRequest(t, req, http.StatusOK)
+
+		assert.Contains(t, resp.Body.String(), "-----BEGIN PGP PUBLIC KEY BLOCK-----")
+	})
+
+	t.Run("Upload", func(t *testing.T) {
+		defer PrintCurrentTest(t)()
+
+		req := NewRequestWithBody(t, "PUT", poolURL+"/upload", bytes.NewReader([]byte{}))
+		AddBasicAuthHeader(req, user.Name)
+		MakeRequest(t, req, http.StatusBadRequest)
+
+		req = NewRequestWithBody(t, "PUT", poolURL+"/upload", bytes.NewReader(content))
+		MakeRequest(t, req, http.StatusUnauthorized)
+
+		req = NewRequestWithBody(t, "PUT", poolURL+"/upload", bytes.NewReader(content))
+		AddBasicAuthHeader(req, user.Name)
+		MakeRequest(t, req, http.StatusCreated)
+
+		pvs, err := packages.GetVersionsByPackageType(db.DefaultContext, user.ID, packages.TypeDebian)
+		assert.NoError(t, err)
+		assert.Len(t, pvs, 1)
+
+		pd, err := packages.GetPackageDescriptor(db.DefaultContext, pvs[0])
+		assert.NoError(t, err)
+		assert.Nil(t, pd.SemVer)
+		assert.IsType(t, &debian_module.Metadata{}, pd.Metadata)
+		assert.Equal(t, packageName, pd.Package.Name)
+		assert.Equal(t, packageVersion, pd.Version.Version)
+
+		pfs, err := packages.GetFilesByVersionID(db.DefaultContext, pvs[0].ID)
+		assert.NoError(t, err)
+		assert.Len(t, pfs, 1)
+		assert.Equal(t, filename, pfs[0].Name)
+		assert.True(t, pfs[0].IsLead)
+
+		pb, err := packages.GetBlobByID(db.DefaultContext, pfs[0].BlobID)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(len(content)), pb.Size)
+
+		req = NewRequestWithBody(t, "PUT", poolURL+"/upload", bytes.NewReader(content))
+		AddBasicAuthHeader(req, user.Name)
+		MakeRequest(t, req, http.StatusConflict)
+	})
+
+	t.Run("Download", func(t *testing.T) {
+		defer PrintCurrentTest(t)()
+
+		req := NewRequest(t, "GET", fmt.Sprintf("%s/%s", poolURL, filename))
+		resp := MakeRequest(t, req, http.StatusOK)
+
+		assert.Equal(t, content, resp.Body.Bytes())
+	})
+
+	t.Run("Packages", func(t *testing.T) {
+		defer PrintCurrentTest(t)()
+
+		req := NewRequest(t, "GET", fmt.Sprintf("%s/dists/%s/%s/binary-%s/Packages", rootURL, distribution, component, packageArchitecture))
+		resp := MakeRequest(t, req, http.StatusOK)
+
+		body := resp.Body.String()
+		assert.Contains(t, body, "Package: "+packageName+"\n")
+		assert.Contains(t, body, "Version: "+packageVersion+"\n")
+		assert.Contains(t, body, "Architecture: "+packageArchitecture+"\n")
+		assert.Contains(t, body, fmt.Sprintf("Filename: pool/%s/%s/%s\n", distribution, component, filename))
+		assert.Contains(t, body, fmt.Sprintf("Size: %d\n", len(content)))
+
+		req = NewRequest(t, "GET", fmt.Sprintf("%s/dists/%s/%s/binary-%s/Packages.gz", rootURL, distribution, component, packageArchitecture))
+		resp = MakeRequest(t, req, http.StatusOK)
+
+		zr, err := gzip.NewReader(resp.Body)
+		assert.NoError(t, err)
+		var buf bytes.Buffer
+		buf.ReadFrom(zr)
+		assert.Equal(t, body, buf.String())
+	})
+
+	t.Run("Release", func(t *testing.T) {
+		defer PrintCurrentTest(t)()
+
+		req := NewRequest(t, "GET", fmt.Sprintf("%s/dists/%s/Release", rootURL, distribution))
+		resp := MakeRequest(t, req, http.StatusOK)
+
+		body := resp.Body.String()
+		assert.Contains(t, body, "Components: "+component+"\n")
+		assert.Contains(t, body, "Architectures: "+packageArchitecture+"\n")
+		assert.Contains(t, body, fmt.Sprintf("%s/binary-%s/Packages\n", component, packageArchitecture))
+		assert.Contains(t, body, fmt.Sprintf("%s/binary-%s/Packages.gz\n", component, packageArchitecture))
+
+		// the date is the time the indices have been generated, so the file does not change between requests
+		assert.Regexp(t, `(?m)^Date: .+ UTC$`, body)
+
+		req = NewRequest(t, "GET", fmt.Sprintf("%s/dists/%s/Release", rootURL, distribution))
+		resp = MakeRequest(t, req, http.StatusOK)
+		assert.Equal(t, body, resp.Body.String())
+
+		req = NewRequest(t, "GET", fmt.Sprintf("%s/dists/%s/InRelease", rootURL, distribution))
+		resp = MakeRequest(t, req, http.StatusOK)
+
+		assert.True(t, strings.HasPrefix(resp.Body.String(), "-----BEGIN PGP SIGNED MESSAGE-----"))
+
+		req = NewRequest(t, "GET", fmt.Sprintf("%s/dists/%s/Release.gpg", rootURL, distribution))
+		resp = MakeRequest(t, req, http.StatusOK)
+
+		assert.True(t, strings.HasPrefix(resp.Body.String(), "-----BEGIN PGP SIGNATURE-----"))
+
+		req = NewRequest(t, "GET", fmt.Sprintf("%s/dists/%s/Release", rootURL, "unknown"))
+		MakeRequest(t, req, http.StatusNotFound)
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		defer PrintCurrentTest(t)()
+
+		deleteURL := fmt.Sprintf("%s/%s/%s/%s", poolURL, packageName, packageVersion, packageArchitecture)
+
+		req := NewRequest(t, "DELETE", deleteURL)
+		MakeRequest(t, req, http.StatusUnauthorized)
+
+		req = NewRequest(t, "DELETE", deleteURL)
+		AddBasicAuthHeader(req, user.Name)
+		MakeRequest(t, req, http.StatusNoContent)
+
+		req = NewRequest(t, "DELETE", deleteURL)
+		AddBasicAuthHeader(req, user.Name)
+		MakeRequest(t, req, http.StatusNotFound)
+
+		pvs, err := packages.GetVersionsByPackageType(db.DefaultContext, user.ID, packages.TypeDebian)
+		assert.NoError(t, err)
+		assert.Empty(t, pvs)
+	})
+}
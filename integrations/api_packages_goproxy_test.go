@@ -0,0 +1,155 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/packages"
+	"code.gitea.io/gitea/models/unittest"
+	user_model "code.gitea.io/gitea/models/user"
+	goproxy_module "code.gitea.io/gitea/modules/packages/goproxy"
+	goproxy_router "code.gitea.io/gitea/routers/api/packages/goproxy"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPackageGo(t *testing.T) {
+	defer prepareTestEnv(t)()
+	user := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 2}).(*user_model.User)
+
+	modulePath := "gitea.io/Gitea/test"
+	escapedModulePath := "gitea.io/!gitea/test"
+	goMod := "module " + modulePath + "\n\ngo 1.18\n"
+
+	createArchive := func(version string) []byte {
+		var buf bytes.Buffer
+		zw := zip.NewWriter(&buf)
+		for name, content := range map[string]string{
+			"go.mod":  goMod,
+			"main.go": "package main\n",
+		} {
+			w, _ := zw.Create(modulePath + "@" + version + "/" + name)
+			w.Write([]byte(content))
+		}
+		zw.Close()
+		return buf.Bytes()
+	}
+
+	rootURL := fmt.Sprintf("/api/packages/%s/go", user.Name)
+	moduleURL := rootURL + "/" + escapedModulePath
+
+	content := createArchive("v1.0.0")
+
+	t.Run("Upload", func(t *testing.T) {
+		defer PrintCurrentTest(t)()
+
+		url := rootURL + "/upload"
+
+		req := NewRequestWithBody(t, "PUT", url, bytes.NewReader(content))
+		MakeRequest(t, req, http.StatusUnauthorized)
+
+		req = NewRequestWithBody(t, "PUT", url, bytes.NewReader([]byte("invalid")))
+		AddBasicAuthHeader(req, user.Name)
+		MakeRequest(t, req, http.StatusBadRequest)
+
+		req = NewRequestWithBody(t, "PUT", url, bytes.NewReader(createArchive("1.0.0")))
+		AddBasicAuthHeader(req, user.Name)
+		MakeRequest(t, req, http.StatusBadRequest)
+
+		req = NewRequestWithBody(t, "PUT", url, bytes.NewReader(content))
+		AddBasicAuthHeader(req, user.Name)
+		MakeRequest(t, req, http.StatusCreated)
+
+		pvs, err := packages.GetVersionsByPackageType(db.DefaultContext, user.ID, packages.TypeGo)
+		assert.NoError(t, err)
+		assert.Len(t, pvs, 1)
+
+		pd, err := packages.GetPackageDescriptor(db.DefaultContext, pvs[0])
+		assert.NoError(t, err)
+		assert.Nil(t, pd.Metadata)
+		assert.Equal(t, modulePath, pd.Package.Name)
+		assert.Equal(t, "v1.0.0", pd.Version.Version)
+		assert.Equal(t, goMod, pd.Properties.GetByName(goproxy_module.PropertyGoMod))
+
+		pfs, err := packages.GetFilesByVersionID(db.DefaultContext, pvs[0].ID)
+		assert.NoError(t, err)
+		assert.Len(t, pfs, 1)
+		assert.Equal(t, "v1.0.0.zip", pfs[0].Name)
+		assert.True(t, pfs[0].IsLead)
+
+		req = NewRequestWithBody(t, "PUT", url, bytes.NewReader(content))
+		AddBasicAuthHeader(req, user.Name)
+		MakeRequest(t, req, http.StatusConflict)
+
+		for _, version := range []string{"v1.1.0-beta.1", "v1.0.1"} {
+			req = NewRequestWithBody(t, "PUT", url, bytes.NewReader(createArchive(version)))
+			AddBasicAuthHeader(req, user.Name)
+			MakeRequest(t, req, http.StatusCreated)
+		}
+	})
+
+	t.Run("List", func(t *testing.T) {
+		defer PrintCurrentTest(t)()
+
+		req := NewRequest(t, "GET", rootURL+"/gitea.io/unknown/@v/list")
+		MakeRequest(t, req, http.StatusNotFound)
+
+		req = NewRequest(t, "GET", moduleURL+"/@v/list")
+		resp := MakeRequest(t, req, http.StatusOK)
+
+		assert.ElementsMatch(t, []string{"v1.0.0", "v1.1.0-beta.1", "v1.0.1"}, strings.Fields(resp.Body.String()))
+	})
+
+	t.Run("Info", func(t *testing.T) {
+		defer PrintCurrentTest(t)()
+
+		req := NewRequest(t, "GET", moduleURL+"/@v/v0.0.1.info")
+		MakeRequest(t, req, http.StatusNotFound)
+
+		req = NewRequest(t, "GET", moduleURL+"/@v/v1.0.0.info")
+		resp := MakeRequest(t, req, http.StatusOK)
+
+		var info goproxy_router.VersionInfo
+		DecodeJSON(t, resp, &info)
+		assert.Equal(t, "v1.0.0", info.Version)
+		assert.False(t, info.Time.IsZero())
+	})
+
+	t.Run("Latest", func(t *testing.T) {
+		defer PrintCurrentTest(t)()
+
+		req := NewRequest(t, "GET", moduleURL+"/@latest")
+		resp := MakeRequest(t, req, http.StatusOK)
+
+		var info goproxy_router.VersionInfo
+		DecodeJSON(t, resp, &info)
+		assert.Equal(t, "v1.0.1", info.Version)
+	})
+
+	t.Run("GoMod", func(t *testing.T) {
+		defer PrintCurrentTest(t)()
+
+		req := NewRequest(t, "GET", moduleURL+"/@v/v1.0.0.mod")
+		resp := MakeRequest(t, req, http.StatusOK)
+
+		assert.Equal(t, goMod, resp.Body.String())
+	})
+
+	t.Run("Download", func(t *testing.T) {
+		defer PrintCurrentTest(t)()
+
+		req := NewRequest(t, "GET", moduleURL+"/@v/v1.0.0.zip")
+		resp := MakeRequest(t, req, http.StatusOK)
+
+		assert.Equal(t, content, resp.Body.Bytes())
+	})
+}
@@ -0,0 +1,196 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/packages"
+	"code.gitea.io/gitea/models/unittest"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/packages/npm"
+	"code.gitea.io/gitea/modules/setting"
+	api "code.gitea.io/gitea/modules/structs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPackageProxy(t *testing.T) {
+	defer prepareTestEnv(t)()
+	user := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 2}).(*user_model.User)
+
+	token := getTokenForLoggedInUser(t, loginUser(t, user.Name))
+
+	defer func(allowedHostList string, limitSize int64) {
+		setting.Packages.ProxyAllowedHostList = allowedHostList
+		setting.Packages.ProxyLimitSize = limitSize
+	}(setting.Packages.ProxyAllowedHostList, setting.Packages.ProxyLimitSize)
+	setting.Packages.ProxyAllowedHostList = "loopback"
+
+	npmPackageName := "@scope/test-package"
+	npmPackageVersion := "1.0.1-pre"
+	npmData := "H4sIAAAAAAAA/ytITM5OTE/VL4DQelnF+XkMVAYGBgZmJiYK2MRBwNDcSIHB2NTMwNDQzMwAqA7IMDUxA9LUdgg2UFpcklgEdAql5kD8ogCnhwio5lJQUMpLzE1VslJQcihOzi9I1S9JLS7RhSYIJR2QgrLUouLM/DyQGkM9Az1D3YIiqExKanFyUWZBCVQ2BKhVwQVJDKwosbQkI78IJO/tZ+LsbRykxFXLNdA+HwWjYBSMgpENACgAbtAACAAA"
+	npmFilename := fmt.Sprintf("%s-%s.tgz", strings.Split(npmPackageName, "/")[1], npmPackageVersion)
+	npmContent, _ := base64.StdEncoding.DecodeString(npmData)
+
+	mavenPath := "/com/gitea/test-project/1.0.1/test-project-1.0.1.jar"
+	mavenContent := "test jar content"
+
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/" + npmPackageName:
+			fmt.Fprintf(w, `{
+				"_id": "%[1]s",
+				"name": "%[1]s",
+				"dist-tags": {
+					"latest": "%[2]s"
+				},
+				"versions": {
+					"%[2]s": {
+						"name": "%[1]s",
+						"version": "%[2]s",
+						"dist": {
+							"integrity": "sha512-yA4FJsVhetynGfOC1jFf79BuS+jrHbm0fhh+aHzCQkOaOBXKf9oBnC4a6DnLLnEsHQDRLYd00cwj8sCXpC+wIg==",
+							"shasum": "aaa7eaf852a948b0aa05afeda35b1badca155d90",
+							"tarball": "http://%[3]s/files/%[4]s"
+						}
+					}
+				}
+			}`, npmPackageName, npmPackageVersion, r.Host, npmFilename)
+		case "/files/" + npmFilename:
+			w.Write(npmContent)
+		case mavenPath:
+			w.Write([]byte(mavenContent))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer upstream.Close()
+
+	proxyURL := func(packageType string) string {
+		return fmt.Sprintf("/api/v1/packages/%s/proxies/%s?token=%s", user.Name, packageType, token)
+	}
+
+	t.Run("Configure", func(t *testing.T) {
+		defer PrintCurrentTest(t)()
+
+		req := NewRequest(t, "GET", proxyURL("npm"))
+		MakeRequest(t, req, http.StatusNotFound)
+
+		req = NewRequestWithJSON(t, "PUT", proxyURL("generic"), &api.SetPackageProxyOption{UpstreamURL: upstream.URL})
+		MakeRequest(t, req, http.StatusNotFound)
+
+		req = NewRequestWithJSON(t, "PUT", proxyURL("npm"), &api.SetPackageProxyOption{UpstreamURL: "invalid"})
+		MakeRequest(t, req, http.StatusUnprocessableEntity)
+
+		for _, packageType := range []string{"maven", "npm"} {
+			req = NewRequestWithJSON(t, "PUT", proxyURL(packageType), &api.SetPackageProxyOption{UpstreamURL: upstream.URL + "/"})
+			MakeRequest(t, req, http.StatusOK)
+		}
+
+		req = NewRequest(t, "GET", proxyURL("npm"))
+		resp := MakeRequest(t, req, http.StatusOK)
+
+		var proxy *api.PackageProxy
+		DecodeJSON(t, resp, &proxy)
+		assert.Equal(t, "npm", proxy.Type)
+		assert.Equal(t, upstream.URL, proxy.UpstreamURL)
+	})
+
+	t.Run("Npm", func(t *testing.T) {
+		defer PrintCurrentTest(t)()
+
+		root := fmt.Sprintf("/api/packages/%s/npm/%s", user.Name, url.QueryEscape(npmPackageName))
+
+		req := NewRequest(t, "GET", root)
+		resp := MakeRequest(t, req, http.StatusOK)
+
+		var result npm.PackageMetadata
+		DecodeJSON(t, resp, &result)
+
+		assert.Equal(t, npmPackageName, result.Name)
+		assert.Equal(t, npmPackageVersion, result.DistTags["latest"])
+		assert.Contains(t, result.Versions, npmPackageVersion)
+		assert.Equal(t, fmt.Sprintf("%s%s/-/%s/%s", setting.AppURL, root[1:], npmPackageVersion, npmFilename), result.Versions[npmPackageVersion].Dist.Tarball)
+
+		pvs, err := packages.GetVersionsByPackageType(db.DefaultContext, user.ID, packages.TypeNpm)
+		assert.NoError(t, err)
+		assert.Empty(t, pvs)
+
+		req = NewRequest(t, "GET", fmt.Sprintf("%s/-/%s/%s", root, npmPackageVersion, npmFilename))
+		resp = MakeRequest(t, req, http.StatusOK)
+		assert.Equal(t, npmContent, resp.Body.Bytes())
+
+		pvs, err = packages.GetVersionsByPackageType(db.DefaultContext, user.ID, packages.TypeNpm)
+		assert.NoError(t, err)
+		assert.Len(t, pvs, 1)
+		assert.Equal(t, npmPackageVersion, pvs[0].Version)
+
+		req = NewRequest(t, "GET", fmt.Sprintf("%s/-/%s/%s", root, "9.9.9", "test-package-9.9.9.tgz"))
+		MakeRequest(t, req, http.StatusNotFound)
+	})
+
+	t.Run("Maven", func(t *testing.T) {
+		defer PrintCurrentTest(t)()
+
+		root := fmt.Sprintf("/api/packages/%s/maven", user.Name)
+
+		// files exceeding the size limit are not fetched
+		setting.Packages.ProxyLimitSize = int64(len(mavenContent)) - 1
+		req := NewRequest(t, "GET", root+mavenPath)
+		MakeRequest(t, req, http.StatusBadGateway)
+		setting.Packages.ProxyLimitSize = -1
+
+		pvs, err := packages.GetVersionsByPackageType(db.DefaultContext, user.ID, packages.TypeMaven)
+		assert.NoError(t, err)
+		assert.Empty(t, pvs)
+
+		req = NewRequest(t, "GET", root+mavenPath)
+		resp := MakeRequest(t, req, http.StatusOK)
+		assert.Equal(t, mavenContent, resp.Body.String())
+
+		pvs, err = packages.GetVersionsByPackageType(db.DefaultContext, user.ID, packages.TypeMaven)
+		assert.NoError(t, err)
+		assert.Len(t, pvs, 1)
+
+		req = NewRequest(t, "GET", root+"/com/gitea/test-project/1.0.1/test-project-1.0.1-sources.jar")
+		MakeRequest(t, req, http.StatusNotFound)
+	})
+
+	t.Run("UpstreamOffline", func(t *testing.T) {
+		defer PrintCurrentTest(t)()
+
+		upstream.Close()
+
+		req := NewRequest(t, "GET", fmt.Sprintf("/api/packages/%s/npm/%s/-/%s/%s", user.Name, url.QueryEscape(npmPackageName), npmPackageVersion, npmFilename))
+		resp := MakeRequest(t, req, http.StatusOK)
+		assert.Equal(t, npmContent, resp.Body.Bytes())
+
+		req = NewRequest(t, "GET", fmt.Sprintf("/api/packages/%s/maven%s", user.Name, mavenPath))
+		resp = MakeRequest(t, req, http.StatusOK)
+		assert.Equal(t, mavenContent, resp.Body.String())
+	})
+
+	t.Run("Remove", func(t *testing.T) {
+		defer PrintCurrentTest(t)()
+
+		req := NewRequest(t, "DELETE", proxyURL("npm"))
+		MakeRequest(t, req, http.StatusNoContent)
+
+		req = NewRequest(t, "GET", proxyURL("npm"))
+		MakeRequest(t, req, http.StatusNotFound)
+
+		pvs, err := packages.GetVersionsByPackageType(db.DefaultContext, user.ID, packages.TypeNpm)
+		assert.NoError(t, err)
+		assert.Len(t, pvs, 1)
+	})
+}
@@ -0,0 +1,179 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/packages"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/models/unit"
+	"code.gitea.io/gitea/models/unittest"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/packages/container/oci"
+	"code.gitea.io/gitea/modules/setting"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPackageRepositoryLink(t *testing.T) {
+	defer prepareTestEnv(t)()
+	owner := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 5}).(*user_model.User)
+	collaborator := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 4}).(*user_model.User)
+	other := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 2}).(*user_model.User)
+	repo := unittest.AssertExistsAndLoadBean(t, &repo_model.Repository{ID: 4, OwnerID: owner.ID}).(*repo_model.Repository)
+
+	assert.NoError(t, db.Insert(db.DefaultContext, &repo_model.RepoUnit{RepoID: repo.ID, Type: unit.TypePackages}))
+
+	npmPackage := func(packageName, repositoryURL string) string {
+		return `{
+			"_id": "` + packageName + `",
+			"name": "` + packageName + `",
+			"dist-tags": {
+				"latest": "1.0.0"
+			},
+			"versions": {
+				"1.0.0": {
+					"name": "` + packageName + `",
+					"version": "1.0.0",
+					"repository": {
+						"type": "git",
+						"url": "` + repositoryURL + `"
+					},
+					"dist": {
+						"integrity": "sha512-yA4FJsVhetynGfOC1jFf79BuS+jrHbm0fhh+aHzCQkOaOBXKf9oBnC4a6DnLLnEsHQDRLYd00cwj8sCXpC+wIg==",
+						"shasum": "aaa7eaf852a948b0aa05afeda35b1badca155d90"
+					}
+				}
+			},
+			"_attachments": {
+				"` + packageName + `-1.0.0.tgz": {
+					"data": "H4sIAAAAAAAA/ytITM5OTE/VL4DQelnF+XkMVAYGBgZmJiYK2MRBwNDcSIHB2NTMwNDQzMwAqA7IMDUxA9LUdgg2UFpcklgEdAql5kD8ogCnhwio5lJQUMpLzE1VslJQcihOzi9I1S9JLS7RhSYIJR2QgrLUouLM/DyQGkM9Az1D3YIiqExKanFyUWZBCVQ2BKhVwQVJDKwosbQkI78IJO/tZ+LsbRykxFXLNdA+HwWjYBSMgpENACgAbtAACAAA"
+				}
+			}
+		}`
+	}
+
+	genericURL := func(packageVersion string) string {
+		return fmt.Sprintf("/api/packages/%s/generic/test-package/%s/file.bin", owner.Name, packageVersion)
+	}
+
+	t.Run("InferFromMetadata", func(t *testing.T) {
+		defer PrintCurrentTest(t)()
+
+		repositoryURL := fmt.Sprintf("git+%s%s.git", setting.AppURL, repo.FullName())
+
+		req := NewRequestWithBody(t, "PUT", fmt.Sprintf("/api/packages/%s/npm/%s", owner.Name, url.QueryEscape("linked-package")), strings.NewReader(npmPackage("linked-package", repositoryURL)))
+		AddBasicAuthHeader(req, collaborator.Name)
+		MakeRequest(t, req, http.StatusCreated)
+
+		p, err := packages.GetPackageByName(db.DefaultContext, owner.ID, packages.TypeNpm, "linked-package")
+		assert.NoError(t, err)
+		assert.Equal(t, repo.ID, p.RepoID)
+
+		req = NewRequestWithBody(t, "PUT", fmt.Sprintf("/api/packages/%s/npm/%s", owner.Name, url.QueryEscape("unlinked-package")), strings.NewReader(npmPackage("unlinked-package", "https://example.com/user5/repo4.git")))
+		AddBasicAuthHeader(req, collaborator.Name)
+		MakeRequest(t, req, http.StatusForbidden)
+
+		_, err = packages.GetPackageByName(db.DefaultContext, owner.ID, packages.TypeNpm, "unlinked-package")
+		assert.ErrorIs(t, err, packages.ErrPackageNotExist)
+	})
+
+	t.Run("Container", func(t *testing.T) {
+		defer PrintCurrentTest(t)()
+
+		imageURL := fmt.Sprintf("%sv2/%s/test-image", setting.AppURL, owner.Name)
+
+		uploadImage := func(t *testing.T, tag, source string, expectedStatus int) {
+			configContent := `{"architecture":"amd64","os":"linux","config":{"Labels":{"org.opencontainers.image.source":"` + source + `"}},"rootfs":{"type":"layers","diff_ids":[]}}`
+			configDigest := fmt.Sprintf("sha256:%x", sha256.Sum256([]byte(configContent)))
+
+			req := NewRequestWithBody(t, "POST", fmt.Sprintf("%s/blobs/uploads?digest=%s", imageURL, configDigest), strings.NewReader(configContent))
+			AddBasicAuthHeader(req, collaborator.Name)
+			MakeRequest(t, req, http.StatusCreated)
+
+			manifestContent := fmt.Sprintf(`{"schemaVersion":2,"mediaType":"%s","config":{"mediaType":"application/vnd.docker.container.image.v1+json","digest":"%s","size":%d},"layers":[]}`, oci.MediaTypeDockerManifest, configDigest, len(configContent))
+			req = NewRequestWithBody(t, "PUT", fmt.Sprintf("%s/manifests/%s", imageURL, tag), strings.NewReader(manifestContent))
+			AddBasicAuthHeader(req, collaborator.Name)
+			req.Header.Set("Content-Type", oci.MediaTypeDockerManifest)
+			MakeRequest(t, req, expectedStatus)
+		}
+
+		// the image does not reference a repository the collaborator can write to
+		uploadImage(t, "unlinked", "https://example.com/user5/repo4", http.StatusForbidden)
+
+		pvs, err := packages.GetVersionsByPackageName(db.DefaultContext, owner.ID, packages.TypeContainer, "test-image")
+		assert.NoError(t, err)
+		assert.Empty(t, pvs)
+
+		uploadImage(t, "linked", setting.AppURL+repo.FullName(), http.StatusCreated)
+
+		p, err := packages.GetPackageByName(db.DefaultContext, owner.ID, packages.TypeContainer, "test-image")
+		assert.NoError(t, err)
+		assert.Equal(t, repo.ID, p.RepoID)
+
+		// blobs of a published image which is not linked to the repository can't be uploaded
+		assert.NoError(t, packages.SetRepositoryLink(db.DefaultContext, p.ID, 0))
+
+		blobContent := "blob"
+		blobDigest := fmt.Sprintf("sha256:%x", sha256.Sum256([]byte(blobContent)))
+
+		req := NewRequestWithBody(t, "POST", fmt.Sprintf("%s/blobs/uploads?digest=%s", imageURL, blobDigest), strings.NewReader(blobContent))
+		AddBasicAuthHeader(req, collaborator.Name)
+		MakeRequest(t, req, http.StatusForbidden)
+
+		req = NewRequestWithBody(t, "POST", fmt.Sprintf("%s/blobs/uploads?digest=%s", imageURL, blobDigest), strings.NewReader(blobContent))
+		AddBasicAuthHeader(req, owner.Name)
+		MakeRequest(t, req, http.StatusCreated)
+	})
+
+	t.Run("Collaborator", func(t *testing.T) {
+		defer PrintCurrentTest(t)()
+
+		req := NewRequestWithBody(t, "PUT", genericURL("1.0.0"), bytes.NewReader([]byte{1, 2, 3}))
+		AddBasicAuthHeader(req, owner.Name)
+		MakeRequest(t, req, http.StatusCreated)
+
+		req = NewRequestWithBody(t, "PUT", genericURL("1.0.1"), bytes.NewReader([]byte{1, 2, 3}))
+		AddBasicAuthHeader(req, collaborator.Name)
+		MakeRequest(t, req, http.StatusForbidden)
+
+		p, err := packages.GetPackageByName(db.DefaultContext, owner.ID, packages.TypeGeneric, "test-package")
+		assert.NoError(t, err)
+		assert.NoError(t, packages.SetRepositoryLink(db.DefaultContext, p.ID, repo.ID))
+
+		req = NewRequestWithBody(t, "PUT", genericURL("1.0.1"), bytes.NewReader([]byte{1, 2, 3}))
+		AddBasicAuthHeader(req, collaborator.Name)
+		MakeRequest(t, req, http.StatusCreated)
+
+		req = NewRequest(t, "DELETE", genericURL("1.0.0"))
+		AddBasicAuthHeader(req, collaborator.Name)
+		MakeRequest(t, req, http.StatusOK)
+
+		pvs, err := packages.GetVersionsByPackageType(db.DefaultContext, owner.ID, packages.TypeGeneric)
+		assert.NoError(t, err)
+		assert.Len(t, pvs, 1)
+		assert.Equal(t, "1.0.1", pvs[0].Version)
+	})
+
+	t.Run("NoRepositoryAccess", func(t *testing.T) {
+		defer PrintCurrentTest(t)()
+
+		req := NewRequestWithBody(t, "PUT", genericURL("1.0.2"), bytes.NewReader([]byte{1, 2, 3}))
+		AddBasicAuthHeader(req, other.Name)
+		MakeRequest(t, req, http.StatusUnauthorized)
+
+		req = NewRequest(t, "DELETE", genericURL("1.0.1"))
+		AddBasicAuthHeader(req, other.Name)
+		MakeRequest(t, req, http.StatusUnauthorized)
+	})
+}
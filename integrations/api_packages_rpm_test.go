@@ -0,0 +1,233 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/packages"
+	"code.gitea.io/gitea/models/unittest"
+	user_model "code.gitea.io/gitea/models/user"
+	rpm_module "code.gitea.io/gitea/modules/packages/rpm"
+	"code.gitea.io/gitea/modules/setting"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPackageRpm(t *testing.T) {
+	defer prepareTestEnv(t)()
+	user := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 2}).(*user_model.User)
+
+	packageName := "gitea-test"
+	packageVersion := "1.0.2"
+	packageRelease := "1"
+	packageArchitecture := "x86_64"
+	packageSummary := "Gitea Test Package"
+
+	// createHeader writes a RPM header with string (6), string array (8) and int32 (4) entries
+	createHeader := func(buf *bytes.Buffer, entries map[uint32]interface{}) {
+		var index, data bytes.Buffer
+		for tag := uint32(1000); tag < 1200; tag++ {
+			value, ok := entries[tag]
+			if !ok {
+				continue
+			}
+			switch v := value.(type) {
+			case string:
+				binary.Write(&index, binary.BigEndian, []uint32{tag, 6, uint32(data.Len()), 1})
+				data.WriteString(v + "\x00")
+			case []string:
+				binary.Write(&index, binary.BigEndian, []uint32{tag, 8, uint32(data.Len()), uint32(len(v))})
+				for _, s := range v {
+					data.WriteString(s + "\x00")
+				}
+			case []uint32:
+				for data.Len()%4 != 0 {
+					data.WriteByte(0)
+				}
+				binary.Write(&index, binary.BigEndian, []uint32{tag, 4, uint32(data.Len()), uint32(len(v))})
+				binary.Write(&data, binary.BigEndian, v)
+			}
+		}
+		buf.Write([]byte{0x8e, 0xad, 0xe8, 0x01, 0, 0, 0, 0})
+		binary.Write(buf, binary.BigEndian, []uint32{uint32(index.Len() / 16), uint32(data.Len())})
+		buf.Write(index.Bytes())
+		buf.Write(data.Bytes())
+	}
+
+	var buf bytes.Buffer
+	lead := make([]byte, 96)
+	copy(lead, []byte{0xed, 0xab, 0xee, 0xdb})
+	buf.Write(lead)
+	createHeader(&buf, map[uint32]interface{}{})
+	createHeader(&buf, map[uint32]interface{}{
+		1000: packageName,
+		1001: packageVersion,
+		1002: packageRelease,
+		1004: packageSummary,
+		1009: []uint32{1024},
+		1014: "MIT",
+		1022: packageArchitecture,
+		1030: []uint32{0o100755},
+		1047: []string{packageName},
+		1112: []uint32{8},
+		1113: []string{packageVersion + "-" + packageRelease},
+		1116: []uint32{0},
+		1117: []string{"gitea-test"},
+		1118: []string{"/usr/bin/"},
+	})
+	buf.WriteString("payload")
+	content := buf.Bytes()
+
+	filename := fmt.Sprintf("%s-%s-%s.%s.rpm", packageName, packageVersion, packageRelease, packageArchitecture)
+
+	rootURL := fmt.Sprintf("/api/packages/%s/rpm", user.Name)
+
+	t.Run("RepositoryConfig", func(t *testing.T) {
+		defer PrintCurrentTest(t)()
+
+		req := NewRequest(t, "GET", rootURL+".repo")
+		resp := MakeRequest(t, req, http.StatusOK)
+
+		body := resp.Body.String()
+		assert.Contains(t, body, "baseurl="+setting.AppURL+rootURL[1:]+"\n")
+		assert.Contains(t, body, "repo_gpgcheck=1\n")
+	})
+
+	t.Run("RepositoryKey", func(t *testing.T) {
+		defer PrintCurrentTest(t)()
+
+		req := NewRequest(t, "GET", rootURL+"/repository.key")
+		resp := MakeRequest(t, req, http.StatusOK)
+
+		assert.Contains(t, resp.Body.String(), "-----BEGIN PGP PUBLIC KEY BLOCK-----")
+	})
+
+	t.Run("Upload", func(t *testing.T) {
+		defer PrintCurrentTest(t)()
+
+		url := rootURL + "/upload"
+
+		req := NewRequestWithBody(t, "PUT", url, bytes.NewReader([]byte{}))
+		AddBasicAuthHeader(req, user.Name)
+		MakeRequest(t, req, http.StatusBadRequest)
+
+		req = NewRequestWithBody(t, "PUT", url, bytes.NewReader(content))
+		MakeRequest(t, req, http.StatusUnauthorized)
+
+		req = NewRequestWithBody(t, "PUT", url, bytes.NewReader(content))
+		AddBasicAuthHeader(req, user.Name)
+		MakeRequest(t, req, http.StatusCreated)
+
+		pvs, err := packages.GetVersionsByPackageType(db.DefaultContext, user.ID, packages.TypeRPM)
+		assert.NoError(t, err)
+		assert.Len(t, pvs, 1)
+
+		pd, err := packages.GetPackageDescriptor(db.DefaultContext, pvs[0])
+		assert.NoError(t, err)
+		assert.Nil(t, pd.SemVer)
+		assert.IsType(t, &rpm_module.VersionMetadata{}, pd.Metadata)
+		assert.Equal(t, packageName, pd.Package.Name)
+		assert.Equal(t, packageVersion+"-"+packageRelease, pd.Version.Version)
+
+		pfs, err := packages.GetFilesByVersionID(db.DefaultContext, pvs[0].ID)
+		assert.NoError(t, err)
+		assert.Len(t, pfs, 1)
+		assert.Equal(t, filename, pfs[0].Name)
+		assert.True(t, pfs[0].IsLead)
+
+		req = NewRequestWithBody(t, "PUT", url, bytes.NewReader(content))
+		AddBasicAuthHeader(req, user.Name)
+		MakeRequest(t, req, http.StatusConflict)
+	})
+
+	t.Run("Download", func(t *testing.T) {
+		defer PrintCurrentTest(t)()
+
+		req := NewRequest(t, "GET", fmt.Sprintf("%s/package/%s", rootURL, filename))
+		resp := MakeRequest(t, req, http.StatusOK)
+
+		assert.Equal(t, content, resp.Body.Bytes())
+	})
+
+	t.Run("Repodata", func(t *testing.T) {
+		defer PrintCurrentTest(t)()
+
+		req := NewRequest(t, "GET", rootURL+"/repodata/dummy.xml")
+		MakeRequest(t, req, http.StatusNotFound)
+
+		req = NewRequest(t, "GET", rootURL+"/repodata/repomd.xml")
+		resp := MakeRequest(t, req, http.StatusOK)
+
+		type Repomd struct {
+			Data []struct {
+				Type     string `xml:"type,attr"`
+				Location struct {
+					Href string `xml:"href,attr"`
+				} `xml:"location"`
+			} `xml:"data"`
+		}
+
+		var repomd Repomd
+		assert.NoError(t, xml.NewDecoder(resp.Body).Decode(&repomd))
+		assert.Len(t, repomd.Data, 3)
+		for i, typ := range []string{"primary", "filelists", "other"} {
+			assert.Equal(t, typ, repomd.Data[i].Type)
+			assert.Equal(t, "repodata/"+typ+".xml.gz", repomd.Data[i].Location.Href)
+		}
+
+		req = NewRequest(t, "GET", rootURL+"/repodata/repomd.xml.asc")
+		resp = MakeRequest(t, req, http.StatusOK)
+
+		assert.True(t, strings.HasPrefix(resp.Body.String(), "-----BEGIN PGP SIGNATURE-----"))
+
+		req = NewRequest(t, "GET", rootURL+"/repodata/primary.xml.gz")
+		resp = MakeRequest(t, req, http.StatusOK)
+
+		zr, err := gzip.NewReader(resp.Body)
+		assert.NoError(t, err)
+		primary, err := io.ReadAll(zr)
+		assert.NoError(t, err)
+
+		assert.Contains(t, string(primary), `packages="1"`)
+		assert.Contains(t, string(primary), "<name>"+packageName+"</name>")
+		assert.Contains(t, string(primary), "<summary>"+packageSummary+"</summary>")
+		assert.Contains(t, string(primary), `<location href="package/`+filename+`"></location>`)
+		assert.Contains(t, string(primary), "<file>/usr/bin/gitea-test</file>")
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		defer PrintCurrentTest(t)()
+
+		deleteURL := fmt.Sprintf("%s/package/%s/%s-%s/%s", rootURL, packageName, packageVersion, packageRelease, packageArchitecture)
+
+		req := NewRequest(t, "DELETE", deleteURL)
+		MakeRequest(t, req, http.StatusUnauthorized)
+
+		req = NewRequest(t, "DELETE", deleteURL)
+		AddBasicAuthHeader(req, user.Name)
+		MakeRequest(t, req, http.StatusNoContent)
+
+		req = NewRequest(t, "DELETE", deleteURL)
+		AddBasicAuthHeader(req, user.Name)
+		MakeRequest(t, req, http.StatusNotFound)
+
+		pvs, err := packages.GetVersionsByPackageType(db.DefaultContext, user.ID, packages.TypeRPM)
+		assert.NoError(t, err)
+		assert.Empty(t, pvs)
+
+		req = NewRequest(t, "GET", rootURL+"/repodata/repomd.xml")
+		MakeRequest(t, req, http.StatusNotFound)
+	})
+}
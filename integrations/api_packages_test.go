@@ -18,6 +18,7 @@ import (
 	user_model "code.gitea.io/gitea/models/user"
 	api "code.gitea.io/gitea/modules/structs"
 	packages_service "code.gitea.io/gitea/services/packages"
+	packages_cleanup_service "code.gitea.io/gitea/services/packages/cleanup"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -165,3 +166,126 @@ func TestPackageCleanup(t *testing.T) {
 	_, err = packages_model.GetInternalVersionByNameAndVersion(db.DefaultContext, 2, packages_model.TypeContainer, "test", container_model.UploadVersion)
 	assert.ErrorIs(t, err, packages_model.ErrPackageNotExist)
 }
+
+func TestPackageCleanupRules(t *testing.T) {
+	defer prepareTestEnv(t)()
+	user := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 4}).(*user_model.User)
+	token := getTokenForLoggedInUser(t, loginUser(t, user.Name))
+
+	packageName := "test-cleanup"
+	otherPackageName := "other-package"
+
+	upload := func(t *testing.T, name, version string) {
+		url := fmt.Sprintf("/api/packages/%s/generic/%s/%s/file.bin", user.Name, name, version)
+		req := NewRequestWithBody(t, "PUT", url, bytes.NewReader([]byte{1}))
+		AddBasicAuthHeader(req, user.Name)
+		MakeRequest(t, req, http.StatusCreated)
+	}
+
+	// the versions need different creation times to be ordered
+	for _, version := range []string{"1.0.0", "1.1.0", "1.2.0"} {
+		upload(t, packageName, version)
+		time.Sleep(time.Second)
+	}
+	upload(t, otherPackageName, "1.0.0")
+
+	rulesURL := fmt.Sprintf("/api/v1/packages/%s/cleanup-rules", user.Name)
+
+	var rule *api.PackageCleanupRule
+
+	t.Run("Create", func(t *testing.T) {
+		defer PrintCurrentTest(t)()
+
+		req := NewRequestWithJSON(t, "POST", fmt.Sprintf("%s?token=%s", rulesURL, token), &api.CreatePackageCleanupRuleOption{Type: "dummy"})
+		MakeRequest(t, req, http.StatusUnprocessableEntity)
+
+		req = NewRequestWithJSON(t, "POST", fmt.Sprintf("%s?token=%s", rulesURL, token), &api.CreatePackageCleanupRuleOption{Type: "generic", KeepPattern: "("})
+		MakeRequest(t, req, http.StatusUnprocessableEntity)
+
+		req = NewRequestWithJSON(t, "POST", fmt.Sprintf("%s?token=%s", rulesURL, token), &api.CreatePackageCleanupRuleOption{Type: "generic", KeepCount: -1})
+		MakeRequest(t, req, http.StatusUnprocessableEntity)
+
+		req = NewRequestWithJSON(t, "POST", fmt.Sprintf("%s?token=%s", rulesURL, token), &api.CreatePackageCleanupRuleOption{
+			Enabled:     true,
+			Type:        "generic",
+			NamePattern: "test-.*",
+			KeepCount:   1,
+			KeepPattern: `1\.0\.\d+`,
+		})
+		resp := MakeRequest(t, req, http.StatusCreated)
+
+		DecodeJSON(t, resp, &rule)
+		assert.True(t, rule.Enabled)
+		assert.Equal(t, "generic", rule.Type)
+		assert.Equal(t, 1, rule.KeepCount)
+
+		req = NewRequest(t, "GET", fmt.Sprintf("%s?token=%s", rulesURL, token))
+		resp = MakeRequest(t, req, http.StatusOK)
+
+		var rules []*api.PackageCleanupRule
+		DecodeJSON(t, resp, &rules)
+		assert.Len(t, rules, 1)
+		assert.Equal(t, rule.ID, rules[0].ID)
+	})
+
+	t.Run("Preview", func(t *testing.T) {
+		defer PrintCurrentTest(t)()
+
+		req := NewRequest(t, "GET", fmt.Sprintf("%s/%d/preview?token=%s", rulesURL, rule.ID, token))
+		resp := MakeRequest(t, req, http.StatusOK)
+
+		var apiPackages []*api.Package
+		DecodeJSON(t, resp, &apiPackages)
+		assert.Len(t, apiPackages, 1)
+		assert.Equal(t, packageName, apiPackages[0].Name)
+		assert.Equal(t, "1.1.0", apiPackages[0].Version)
+
+		pvs, err := packages_model.GetVersionsByPackageName(db.DefaultContext, user.ID, packages_model.TypeGeneric, packageName)
+		assert.NoError(t, err)
+		assert.Len(t, pvs, 3)
+	})
+
+	t.Run("Execute", func(t *testing.T) {
+		defer PrintCurrentTest(t)()
+
+		assert.NoError(t, packages_cleanup_service.ExecuteCleanupRules(db.DefaultContext))
+
+		pvs, err := packages_model.GetVersionsByPackageName(db.DefaultContext, user.ID, packages_model.TypeGeneric, packageName)
+		assert.NoError(t, err)
+		assert.Len(t, pvs, 2)
+		for _, pv := range pvs {
+			assert.NotEqual(t, "1.1.0", pv.Version)
+		}
+
+		pvs, err = packages_model.GetVersionsByPackageName(db.DefaultContext, user.ID, packages_model.TypeGeneric, otherPackageName)
+		assert.NoError(t, err)
+		assert.Len(t, pvs, 1)
+	})
+
+	t.Run("Edit", func(t *testing.T) {
+		defer PrintCurrentTest(t)()
+
+		enabled := false
+		req := NewRequestWithJSON(t, "PATCH", fmt.Sprintf("%s/%d?token=%s", rulesURL, rule.ID, token), &api.EditPackageCleanupRuleOption{Enabled: &enabled})
+		resp := MakeRequest(t, req, http.StatusOK)
+
+		var edited *api.PackageCleanupRule
+		DecodeJSON(t, resp, &edited)
+		assert.False(t, edited.Enabled)
+		assert.Equal(t, rule.KeepPattern, edited.KeepPattern)
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		defer PrintCurrentTest(t)()
+
+		otherToken := getTokenForLoggedInUser(t, loginUser(t, "user5"))
+		req := NewRequest(t, "DELETE", fmt.Sprintf("%s/%d?token=%s", rulesURL, rule.ID, otherToken))
+		MakeRequest(t, req, http.StatusForbidden)
+
+		req = NewRequest(t, "DELETE", fmt.Sprintf("%s/%d?token=%s", rulesURL, rule.ID, token))
+		MakeRequest(t, req, http.StatusNoContent)
+
+		req = NewRequest(t, "GET", fmt.Sprintf("%s/%d?token=%s", rulesURL, rule.ID, token))
+		MakeRequest(t, req, http.StatusNotFound)
+	})
+}
@@ -0,0 +1,224 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	project_model "code.gitea.io/gitea/models/project"
+	"code.gitea.io/gitea/models/unittest"
+	api "code.gitea.io/gitea/modules/structs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIRepoProjects(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	session := loginUser(t, "user2")
+	token := getTokenForLoggedInUser(t, session)
+
+	req := NewRequestf(t, "GET", "/api/v1/repos/user2/repo1/projects?token=%s", token)
+	resp := session.MakeRequest(t, req, http.StatusOK)
+	var apiProjects []*api.Project
+	DecodeJSON(t, resp, &apiProjects)
+	assert.Len(t, apiProjects, 1)
+	assert.EqualValues(t, 1, apiProjects[0].ID)
+	assert.Equal(t, "repository", apiProjects[0].Type)
+
+	req = NewRequestWithJSON(t, "POST", fmt.Sprintf("/api/v1/repos/user2/repo1/projects?token=%s", token), &api.CreateProjectOption{
+		Title:     "API project",
+		BoardType: "basic_kanban",
+	})
+	resp = session.MakeRequest(t, req, http.StatusCreated)
+	var apiProject api.Project
+	DecodeJSON(t, resp, &apiProject)
+	assert.Equal(t, "API project", apiProject.Title)
+	assert.Equal(t, "basic_kanban", apiProject.BoardType)
+	assert.Equal(t, api.StateOpen, apiProject.State)
+	unittest.AssertExistsAndLoadBean(t, &project_model.Project{ID: apiProject.ID, RepoID: 1})
+
+	req = NewRequestWithJSON(t, "POST", fmt.Sprintf("/api/v1/repos/user2/repo1/projects?token=%s", token), &api.CreateProjectOption{
+		Title:     "API project",
+		BoardType: "unknown",
+	})
+	session.MakeRequest(t, req, http.StatusUnprocessableEntity)
+
+	// boards
+	boardsURL := fmt.Sprintf("/api/v1/projects/%d/boards", apiProject.ID)
+	req = NewRequestf(t, "GET", "%s?token=%s", boardsURL, token)
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	var apiBoards []*api.ProjectBoard
+	DecodeJSON(t, resp, &apiBoards)
+	assert.Len(t, apiBoards, 3)
+
+	req = NewRequestWithJSON(t, "POST", fmt.Sprintf("%s?token=%s", boardsURL, token), &api.CreateProjectBoardOption{
+		Title: "Review",
+		Color: "#ff0000",
+	})
+	resp = session.MakeRequest(t, req, http.StatusCreated)
+	var apiBoard api.ProjectBoard
+	DecodeJSON(t, resp, &apiBoard)
+	assert.Equal(t, "Review", apiBoard.Title)
+	assert.Equal(t, "#ff0000", apiBoard.Color)
+
+	title := "In Review"
+	req = NewRequestWithJSON(t, "PATCH", fmt.Sprintf("%s/%d?token=%s", boardsURL, apiBoard.ID, token), &api.EditProjectBoardOption{
+		Title: &title,
+	})
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	DecodeJSON(t, resp, &apiBoard)
+	assert.Equal(t, "In Review", apiBoard.Title)
+
+	// issues
+	req = NewRequestWithJSON(t, "POST", fmt.Sprintf("/api/v1/projects/%d/issues?token=%s", apiProject.ID, token), &api.AddProjectIssueOption{
+		IssueID: 1,
+	})
+	session.MakeRequest(t, req, http.StatusNoContent)
+	unittest.AssertExistsAndLoadBean(t, &project_model.ProjectIssue{IssueID: 1, ProjectID: apiProject.ID})
+	unittest.AssertNotExistsBean(t, &project_model.ProjectIssue{IssueID: 1, ProjectID: 1})
+
+	req = NewRequestWithJSON(t, "PUT", fmt.Sprintf("%s/%d/issues?token=%s", boardsURL, apiBoard.ID, token), &api.MoveProjectIssuesOption{
+		Issues: []int64{1},
+	})
+	session.MakeRequest(t, req, http.StatusNoContent)
+
+	req = NewRequestf(t, "GET", "%s/%d/issues?token=%s", boardsURL, apiBoard.ID, token)
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	var apiIssues []*api.Issue
+	DecodeJSON(t, resp, &apiIssues)
+	if assert.Len(t, apiIssues, 1) {
+		assert.EqualValues(t, 1, apiIssues[0].ID)
+	}
+
+	// issue 3 belongs to another project
+	req = NewRequestWithJSON(t, "PUT", fmt.Sprintf("%s/%d/issues?token=%s", boardsURL, apiBoard.ID, token), &api.MoveProjectIssuesOption{
+		Issues: []int64{1, 3},
+	})
+	session.MakeRequest(t, req, http.StatusUnprocessableEntity)
+
+	// issue 4 belongs to another repository
+	req = NewRequestWithJSON(t, "POST", fmt.Sprintf("/api/v1/projects/%d/issues?token=%s", apiProject.ID, token), &api.AddProjectIssueOption{
+		IssueID: 4,
+	})
+	session.MakeRequest(t, req, http.StatusUnprocessableEntity)
+
+	req = NewRequestf(t, "DELETE", "/api/v1/projects/%d/issues/1?token=%s", apiProject.ID, token)
+	session.MakeRequest(t, req, http.StatusNoContent)
+	unittest.AssertNotExistsBean(t, &project_model.ProjectIssue{IssueID: 1})
+
+	req = NewRequestf(t, "DELETE", "%s/%d?token=%s", boardsURL, apiBoard.ID, token)
+	session.MakeRequest(t, req, http.StatusNoContent)
+	unittest.AssertNotExistsBean(t, &project_model.Board{ID: apiBoard.ID})
+
+	// close and delete the project
+	state := "closed"
+	req = NewRequestWithJSON(t, "PATCH", fmt.Sprintf("/api/v1/projects/%d?token=%s", apiProject.ID, token), &api.EditProjectOption{
+		State: &state,
+	})
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	DecodeJSON(t, resp, &apiProject)
+	assert.Equal(t, api.StateClosed, apiProject.State)
+
+	req = NewRequestf(t, "GET", "/api/v1/repos/user2/repo1/projects?state=closed&token=%s", token)
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	DecodeJSON(t, resp, &apiProjects)
+	assert.Len(t, apiProjects, 1)
+
+	req = NewRequestf(t, "DELETE", "/api/v1/projects/%d?token=%s", apiProject.ID, token)
+	session.MakeRequest(t, req, http.StatusNoContent)
+	unittest.AssertNotExistsBean(t, &project_model.Project{ID: apiProject.ID})
+
+	// a user without write access to the repository
+	session = loginUser(t, "user4")
+	token = getTokenForLoggedInUser(t, session)
+	req = NewRequestf(t, "PATCH", "/api/v1/projects/1?token=%s", token)
+	session.MakeRequest(t, req, http.StatusForbidden)
+}
+
+func TestAPIOrgProjects(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	req := NewRequest(t, "GET", "/api/v1/orgs/user3/projects")
+	resp := MakeRequest(t, req, http.StatusOK)
+	var apiProjects []*api.Project
+	DecodeJSON(t, resp, &apiProjects)
+	if assert.Len(t, apiProjects, 1) {
+		assert.EqualValues(t, 4, apiProjects[0].ID)
+		assert.Equal(t, "organization", apiProjects[0].Type)
+		assert.Nil(t, apiProjects[0].Repo)
+	}
+
+	// user4 can see the projects of the organization but is not a member
+	session := loginUser(t, "user4")
+	token := getTokenForLoggedInUser(t, session)
+	req = NewRequestWithJSON(t, "POST", fmt.Sprintf("/api/v1/orgs/user3/projects?token=%s", token), &api.CreateProjectOption{
+		Title: "Not allowed",
+	})
+	session.MakeRequest(t, req, http.StatusForbidden)
+
+	session = loginUser(t, "user2")
+	token = getTokenForLoggedInUser(t, session)
+	req = NewRequestWithJSON(t, "POST", fmt.Sprintf("/api/v1/orgs/user3/projects?token=%s", token), &api.CreateProjectOption{
+		Title: "Organization project",
+	})
+	resp = session.MakeRequest(t, req, http.StatusCreated)
+	var apiProject api.Project
+	DecodeJSON(t, resp, &apiProject)
+	assert.Equal(t, "organization", apiProject.Type)
+	assert.EqualValues(t, 3, apiProject.Owner.ID)
+
+	// issues of all repositories of the organization can be added
+	req = NewRequestWithJSON(t, "POST", fmt.Sprintf("/api/v1/projects/4/issues?token=%s", token), &api.AddProjectIssueOption{
+		IssueID: 6,
+	})
+	session.MakeRequest(t, req, http.StatusNoContent)
+
+	// but not issues of repositories of other owners
+	req = NewRequestWithJSON(t, "POST", fmt.Sprintf("/api/v1/projects/4/issues?token=%s", token), &api.AddProjectIssueOption{
+		IssueID: 1,
+	})
+	session.MakeRequest(t, req, http.StatusUnprocessableEntity)
+
+	req = NewRequestf(t, "GET", "/api/v1/projects/4/boards/0/issues?token=%s", token)
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	var apiIssues []*api.Issue
+	DecodeJSON(t, resp, &apiIssues)
+	assert.Len(t, apiIssues, 1)
+
+	// issue 6 belongs to a private repository, so it is hidden from anonymous users
+	req = NewRequest(t, "GET", "/api/v1/projects/4/boards/0/issues")
+	resp = MakeRequest(t, req, http.StatusOK)
+	DecodeJSON(t, resp, &apiIssues)
+	assert.Len(t, apiIssues, 0)
+}
+
+func TestAPIUserProjects(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	session := loginUser(t, "user2")
+	token := getTokenForLoggedInUser(t, session)
+	req := NewRequestWithJSON(t, "POST", fmt.Sprintf("/api/v1/user/projects?token=%s", token), &api.CreateProjectOption{
+		Title: "Personal project",
+	})
+	resp := session.MakeRequest(t, req, http.StatusCreated)
+	var apiProject api.Project
+	DecodeJSON(t, resp, &apiProject)
+	assert.Equal(t, "individual", apiProject.Type)
+
+	req = NewRequest(t, "GET", "/api/v1/users/user2/projects")
+	resp = MakeRequest(t, req, http.StatusOK)
+	var apiProjects []*api.Project
+	DecodeJSON(t, resp, &apiProjects)
+	if assert.Len(t, apiProjects, 1) {
+		assert.Equal(t, apiProject.ID, apiProjects[0].ID)
+	}
+
+	// anonymous users cannot change the project
+	req = NewRequestf(t, "DELETE", "/api/v1/projects/%d", apiProject.ID)
+	MakeRequest(t, req, http.StatusUnauthorized)
+}
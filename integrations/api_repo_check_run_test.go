@@ -0,0 +1,170 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+
+	api "code.gitea.io/gitea/modules/structs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIRepoCheckRuns(t *testing.T) {
+	onGiteaRun(t, func(t *testing.T, u *url.URL) {
+		session := loginUser(t, "user2")
+		token := getTokenForLoggedInUser(t, session)
+
+		testEditFileToNewBranch(t, session, "user2", "repo1", "master", "checks", "README.md", "Hello\nWorld\n")
+
+		req := NewRequestWithJSON(t, "POST", fmt.Sprintf("/api/v1/repos/user2/repo1/pulls?token=%s", token), &api.CreatePullRequestOption{
+			Head:  "checks",
+			Base:  "master",
+			Title: "Check runs",
+		})
+		resp := session.MakeRequest(t, req, http.StatusCreated)
+		var pull api.PullRequest
+		DecodeJSON(t, resp, &pull)
+		headSHA := pull.Head.Sha
+
+		rootURL := "/api/v1/repos/user2/repo1/check-runs"
+
+		var run api.CheckRun
+		t.Run("Create", func(t *testing.T) {
+			defer PrintCurrentTest(t)()
+
+			req := NewRequestWithJSON(t, "POST", fmt.Sprintf("%s?token=%s", rootURL, token), &api.CreateCheckRunOption{
+				Name:    "lint",
+				HeadSHA: headSHA,
+				Status:  "in_progress",
+			})
+			resp := MakeRequest(t, req, http.StatusCreated)
+			DecodeJSON(t, resp, &run)
+
+			assert.Equal(t, "lint", run.Name)
+			assert.Equal(t, headSHA, run.HeadSHA)
+			assert.Equal(t, "in_progress", run.Status)
+			assert.Empty(t, run.Conclusion)
+			assert.NotNil(t, run.StartedAt)
+			assert.Nil(t, run.CompletedAt)
+
+			req = NewRequest(t, "GET", fmt.Sprintf("/api/v1/repos/user2/repo1/commits/%s/statuses?token=%s", headSHA, token))
+			resp = MakeRequest(t, req, http.StatusOK)
+			var statuses []*api.CommitStatus
+			DecodeJSON(t, resp, &statuses)
+			assert.Len(t, statuses, 1)
+			assert.Equal(t, "lint", statuses[0].Context)
+			assert.Equal(t, api.CommitStatusPending, statuses[0].State)
+			assert.Equal(t, run.HTMLURL, statuses[0].TargetURL)
+		})
+
+		t.Run("Invalid", func(t *testing.T) {
+			defer PrintCurrentTest(t)()
+
+			for _, opts := range []*api.CreateCheckRunOption{
+				{Name: "lint", HeadSHA: "0000000000000000000000000000000000000000"},
+				{Name: "lint", HeadSHA: headSHA, Status: "completed"},
+				{Name: "lint", HeadSHA: headSHA, Output: &api.CheckRunOutputOption{
+					Annotations: []*api.CheckAnnotation{{Path: "README.md", StartLine: 1, AnnotationLevel: "bogus", Message: "message"}},
+				}},
+				{Name: "lint", HeadSHA: headSHA, Output: &api.CheckRunOutputOption{
+					Annotations: []*api.CheckAnnotation{{Path: "README.md", StartLine: 2, EndLine: 1, AnnotationLevel: "notice", Message: "message"}},
+				}},
+			} {
+				req := NewRequestWithJSON(t, "POST", fmt.Sprintf("%s?token=%s", rootURL, token), opts)
+				MakeRequest(t, req, http.StatusUnprocessableEntity)
+			}
+		})
+
+		t.Run("NoWritePermission", func(t *testing.T) {
+			defer PrintCurrentTest(t)()
+
+			token4 := getTokenForLoggedInUser(t, loginUser(t, "user4"))
+			req := NewRequestWithJSON(t, "POST", fmt.Sprintf("%s?token=%s", rootURL, token4), &api.CreateCheckRunOption{
+				Name:    "lint",
+				HeadSHA: headSHA,
+			})
+			MakeRequest(t, req, http.StatusForbidden)
+		})
+
+		t.Run("Edit", func(t *testing.T) {
+			defer PrintCurrentTest(t)()
+
+			conclusion := "failure"
+			req := NewRequestWithJSON(t, "PATCH", fmt.Sprintf("%s/%d?token=%s", rootURL, run.ID, token), &api.EditCheckRunOption{
+				Conclusion: &conclusion,
+				Output: &api.CheckRunOutputOption{
+					Title:   "2 problems",
+					Summary: "Found **2** problems",
+					Annotations: []*api.CheckAnnotation{
+						{Path: "README.md", StartLine: 1, AnnotationLevel: "warning", Message: "Greeting is too short"},
+						{Path: "README.md", StartLine: 2, AnnotationLevel: "failure", Title: "Spelling", Message: "World should be lower case"},
+					},
+				},
+			})
+			resp := MakeRequest(t, req, http.StatusOK)
+			DecodeJSON(t, resp, &run)
+
+			assert.Equal(t, "completed", run.Status)
+			assert.Equal(t, "failure", run.Conclusion)
+			assert.NotNil(t, run.CompletedAt)
+			assert.Equal(t, "2 problems", run.Output.Title)
+			assert.EqualValues(t, 2, run.Output.AnnotationsCount)
+
+			req = NewRequest(t, "GET", fmt.Sprintf("%s/%d/annotations?token=%s", rootURL, run.ID, token))
+			resp = MakeRequest(t, req, http.StatusOK)
+			var annotations []*api.CheckAnnotation
+			DecodeJSON(t, resp, &annotations)
+			assert.Len(t, annotations, 2)
+			assert.Equal(t, 2, annotations[1].StartLine)
+			assert.Equal(t, 2, annotations[1].EndLine)
+			assert.Equal(t, "failure", annotations[1].AnnotationLevel)
+
+			req = NewRequest(t, "GET", fmt.Sprintf("/api/v1/repos/user2/repo1/commits/%s/statuses?sort=leastindex&token=%s", headSHA, token))
+			resp = MakeRequest(t, req, http.StatusOK)
+			var statuses []*api.CommitStatus
+			DecodeJSON(t, resp, &statuses)
+			assert.Len(t, statuses, 2)
+			assert.Equal(t, api.CommitStatusFailure, statuses[0].State)
+			assert.Equal(t, "2 problems", statuses[0].Description)
+		})
+
+		t.Run("List", func(t *testing.T) {
+			defer PrintCurrentTest(t)()
+
+			req := NewRequest(t, "GET", fmt.Sprintf("/api/v1/repos/user2/repo1/commits/checks/check-runs?token=%s", token))
+			resp := MakeRequest(t, req, http.StatusOK)
+			var runs []*api.CheckRun
+			DecodeJSON(t, resp, &runs)
+			assert.Len(t, runs, 1)
+			assert.Equal(t, run.ID, runs[0].ID)
+
+			req = NewRequest(t, "GET", fmt.Sprintf("/api/v1/repos/user2/repo1/commits/master/check-runs?token=%s", token))
+			resp = MakeRequest(t, req, http.StatusOK)
+			DecodeJSON(t, resp, &runs)
+			assert.Empty(t, runs)
+		})
+
+		t.Run("Web", func(t *testing.T) {
+			defer PrintCurrentTest(t)()
+
+			req := NewRequest(t, "GET", fmt.Sprintf("/user2/repo1/pulls/%d/files", pull.Index))
+			resp := session.MakeRequest(t, req, http.StatusOK)
+			htmlDoc := NewHTMLParser(t, resp.Body)
+			messages := htmlDoc.doc.Find(".check-annotations .check-annotation-message")
+			assert.Equal(t, 2, messages.Length())
+			assert.Equal(t, "World should be lower case", messages.Eq(1).Text())
+
+			req = NewRequest(t, "GET", fmt.Sprintf("/user2/repo1/checks/%d", run.ID))
+			resp = session.MakeRequest(t, req, http.StatusOK)
+			htmlDoc = NewHTMLParser(t, resp.Body)
+			assert.Equal(t, "2", htmlDoc.doc.Find(".markup strong").Text())
+			assert.Equal(t, 2, htmlDoc.doc.Find(".check-annotation").Length())
+		})
+	})
+}
@@ -13,6 +13,8 @@ import (
 	"testing"
 
 	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/models/db"
+	quota_model "code.gitea.io/gitea/models/quota"
 	repo_model "code.gitea.io/gitea/models/repo"
 	"code.gitea.io/gitea/models/unittest"
 	user_model "code.gitea.io/gitea/models/user"
@@ -246,6 +248,51 @@ func TestAPILFSBatch(t *testing.T) {
 			setting.LFS.MaxFileSize = oldMaxFileSize
 		})
 
+		t.Run("AddMetaQuotaExceeded", func(t *testing.T) {
+			defer PrintCurrentTest(t)()
+
+			defer func(enabled bool) {
+				setting.Quota.Enabled = enabled
+			}(setting.Quota.Enabled)
+			setting.Quota.Enabled = true
+
+			assert.NoError(t, quota_model.SetQuota(db.DefaultContext, &quota_model.Quota{
+				OwnerID:          repo.OwnerID,
+				LimitTotal:       -1,
+				LimitGit:         -1,
+				LimitLFS:         0,
+				LimitPackages:    -1,
+				LimitAttachments: -1,
+			}))
+			defer func() {
+				assert.NoError(t, quota_model.DeleteQuotaByOwnerID(db.DefaultContext, repo.OwnerID))
+			}()
+
+			// the object is in the content store already, but must not be linked to the repository
+			repo3 := createLFSTestRepository(t, "batch3")
+			content := []byte("dummy7")
+			p := lfs.Pointer{Oid: storeObjectInRepo(t, repo3.ID, &content), Size: int64(len(content))}
+			defer models.RemoveLFSMetaObjectByOid(repo3.ID, p.Oid)
+
+			req := newRequest(t, &lfs.BatchRequest{
+				Operation: "upload",
+				Objects:   []lfs.Pointer{p},
+			})
+
+			resp := session.MakeRequest(t, req, http.StatusOK)
+			br := decodeResponse(t, resp.Body)
+			assert.Len(t, br.Objects, 1)
+			assert.NotNil(t, br.Objects[0].Error)
+			assert.Equal(t, http.StatusRequestEntityTooLarge, br.Objects[0].Error.Code)
+
+			meta, err := models.GetLFSMetaObjectByOid(repo.ID, p.Oid)
+			assert.Nil(t, meta)
+			assert.Equal(t, models.ErrLFSObjectNotExist, err)
+
+			// Cleanup
+			assert.NoError(t, lfs.NewContentStore().Delete(p.RelativePath()))
+		})
+
 		t.Run("AddMeta", func(t *testing.T) {
 			defer PrintCurrentTest(t)()
 
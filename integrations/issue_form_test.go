@@ -0,0 +1,118 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"encoding/base64"
+	"net/http"
+	"net/url"
+	"path"
+	"testing"
+
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/test"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIssueForms(t *testing.T) {
+	onGiteaRun(t, func(t *testing.T, u *url.URL) {
+		ctx := NewAPITestContext(t, "user2", "repo1")
+
+		template := `name: Bug Report
+description: File a bug report
+title: "[Bug]: "
+body:
+  - type: input
+    id: version
+    attributes:
+      label: Version
+    validations:
+      required: true
+  - type: dropdown
+    id: os
+    attributes:
+      label: Operating System
+      options:
+        - Linux
+        - Windows
+  - type: checkboxes
+    id: terms
+    attributes:
+      label: Terms
+      options:
+        - label: I searched for existing issues
+          required: true
+`
+		t.Run("CreateTemplate", doAPICreateFile(ctx, ".gitea/ISSUE_TEMPLATE/bug.yaml", &api.CreateFileOptions{
+			FileOptions: api.FileOptions{
+				BranchName:    "master",
+				NewBranchName: "master",
+				Message:       "Add bug report issue form",
+			},
+			Content: base64.StdEncoding.EncodeToString([]byte(template)),
+		}))
+
+		req := NewRequestf(t, "GET", "/api/v1/repos/user2/repo1/issue_templates?token=%s", ctx.Token)
+		resp := ctx.Session.MakeRequest(t, req, http.StatusOK)
+		var templates []*api.IssueTemplate
+		DecodeJSON(t, resp, &templates)
+		if !assert.Len(t, templates, 1) {
+			return
+		}
+		assert.EqualValues(t, "Bug Report", templates[0].Name)
+		assert.EqualValues(t, "File a bug report", templates[0].About)
+		assert.EqualValues(t, "bug.yaml", templates[0].FileName)
+		assert.Len(t, templates[0].Body, 3)
+
+		// the form replaces the text of the issue
+		req = NewRequest(t, "GET", "/user2/repo1/issues/new?template=bug.yaml")
+		resp = ctx.Session.MakeRequest(t, req, http.StatusOK)
+		htmlDoc := NewHTMLParser(t, resp.Body)
+		assert.EqualValues(t, "[Bug]: ", htmlDoc.Find("#issue_title").AttrOr("value", ""))
+		assert.EqualValues(t, 0, htmlDoc.Find("textarea[name=content]").Length())
+		assert.EqualValues(t, 1, htmlDoc.Find("input[name=form-field-version]").Length())
+		assert.EqualValues(t, 3, htmlDoc.Find("select[name=form-field-os] option").Length())
+		assert.EqualValues(t, 1, htmlDoc.Find("input[name=form-field-terms-0]").Length())
+		link := htmlDoc.Find("form#new-issue").AttrOr("action", "")
+
+		// required fields have to be filled in
+		req = NewRequestWithValues(t, "POST", link, map[string]string{
+			"_csrf":              htmlDoc.GetCSRF(),
+			"title":              "[Bug]: Crash",
+			"form_template":      "bug.yaml",
+			"form-field-version": "1.17.0",
+		})
+		resp = ctx.Session.MakeRequest(t, req, http.StatusOK)
+		htmlDoc = NewHTMLParser(t, resp.Body)
+		assert.Contains(t, htmlDoc.Find(".flash-error").Text(), "I searched for existing issues")
+		assert.EqualValues(t, "1.17.0", htmlDoc.Find("input[name=form-field-version]").AttrOr("value", ""))
+
+		req = NewRequestWithValues(t, "POST", link, map[string]string{
+			"_csrf":              htmlDoc.GetCSRF(),
+			"title":              "[Bug]: Crash",
+			"form_template":      "bug.yaml",
+			"form-field-version": "1.17.0",
+			"form-field-os":      "Linux",
+			"form-field-terms-0": "on",
+		})
+		resp = ctx.Session.MakeRequest(t, req, http.StatusSeeOther)
+		issueURL := test.RedirectURL(resp)
+
+		req = NewRequestf(t, "GET", "/api/v1/repos/user2/repo1/issues/%s?token=%s", path.Base(issueURL), ctx.Token)
+		resp = ctx.Session.MakeRequest(t, req, http.StatusOK)
+		var issue api.Issue
+		DecodeJSON(t, resp, &issue)
+		assert.EqualValues(t, "### Version\n\n1.17.0\n\n### Operating System\n\nLinux\n\n### Terms\n\n- [x] I searched for existing issues\n", issue.Body)
+
+		// the form template has to exist
+		req = NewRequestWithValues(t, "POST", link, map[string]string{
+			"_csrf":         htmlDoc.GetCSRF(),
+			"title":         "[Bug]: Crash",
+			"form_template": "nonexistent.yaml",
+		})
+		ctx.Session.MakeRequest(t, req, http.StatusNotFound)
+	})
+}
@@ -36,6 +36,8 @@ func TestLinksNoLogin(t *testing.T) {
 		"/user2/repo1/",
 		"/user2/repo1/projects",
 		"/user2/repo1/projects/1",
+		"/user3/-/projects",
+		"/user3/-/projects/4",
 		"/assets/img/404.png",
 		"/assets/img/500.png",
 	}
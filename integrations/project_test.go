@@ -0,0 +1,157 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	project_model "code.gitea.io/gitea/models/project"
+	"code.gitea.io/gitea/models/unittest"
+	api "code.gitea.io/gitea/modules/structs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOrgProjectCards(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	session := loginUser(t, "user2")
+
+	// issue 6 of the private repository user3/repo3 can be added to the project of the organization
+	req := NewRequestWithValues(t, "POST", "/user3/repo3/issues/projects", map[string]string{
+		"_csrf":     GetCSRF(t, session, "/user3/repo3/issues/1"),
+		"issue_ids": "6",
+		"id":        "4",
+	})
+	session.MakeRequest(t, req, http.StatusOK)
+	unittest.AssertExistsAndLoadBean(t, &project_model.ProjectIssue{IssueID: 6, ProjectID: 4})
+
+	// but issues of repositories of other owners cannot
+	req = NewRequestWithValues(t, "POST", "/user2/repo1/issues/projects", map[string]string{
+		"_csrf":     GetCSRF(t, session, "/user2/repo1/issues/1"),
+		"issue_ids": "1",
+		"id":        "4",
+	})
+	session.MakeRequest(t, req, http.StatusNotFound)
+
+	// collaborators of the repository need write access to the organization to add issues to its projects
+	adminSession := loginUser(t, "user1")
+	adminToken := getTokenForLoggedInUser(t, adminSession)
+	permission := "write"
+	req = NewRequestWithJSON(t, "PUT", fmt.Sprintf("/api/v1/repos/user3/repo3/collaborators/user5?token=%s", adminToken), &api.AddCollaboratorOption{
+		Permission: &permission,
+	})
+	adminSession.MakeRequest(t, req, http.StatusNoContent)
+
+	collaboratorSession := loginUser(t, "user5")
+	req = NewRequestWithValues(t, "POST", "/user3/repo3/issues/projects", map[string]string{
+		"_csrf":     GetCSRF(t, collaboratorSession, "/user3/repo3/issues/1"),
+		"issue_ids": "6",
+		"id":        "4",
+	})
+	collaboratorSession.MakeRequest(t, req, http.StatusForbidden)
+
+	req = NewRequest(t, "GET", "/user3/-/projects/4")
+	resp := session.MakeRequest(t, req, http.StatusOK)
+	htmlDoc := NewHTMLParser(t, resp.Body)
+	assert.EqualValues(t, 1, htmlDoc.doc.Find(".board-card[data-issue=\"6\"]").Length())
+
+	// the card is hidden from users who cannot read the repository
+	req = NewRequest(t, "GET", "/user3/-/projects/4")
+	resp = MakeRequest(t, req, http.StatusOK)
+	htmlDoc = NewHTMLParser(t, resp.Body)
+	assert.EqualValues(t, 0, htmlDoc.doc.Find(".board-card").Length())
+
+	// repository projects are not shown on the pages of their owner
+	req = NewRequest(t, "GET", "/user2/-/projects/1")
+	session.MakeRequest(t, req, http.StatusNotFound)
+}
+
+func TestUserProjects(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	session := loginUser(t, "user2")
+	req := NewRequestWithValues(t, "POST", "/user2/-/projects/new", map[string]string{
+		"_csrf":      GetCSRF(t, session, "/user2/-/projects/new"),
+		"title":      "Roadmap",
+		"board_type": "1",
+	})
+	session.MakeRequest(t, req, http.StatusSeeOther)
+	p := unittest.AssertExistsAndLoadBean(t, &project_model.Project{Title: "Roadmap"}).(*project_model.Project)
+	assert.EqualValues(t, 2, p.OwnerID)
+	assert.Equal(t, project_model.TypeIndividual, p.Type)
+
+	req = NewRequest(t, "GET", "/user2/-/projects")
+	resp := session.MakeRequest(t, req, http.StatusOK)
+	htmlDoc := NewHTMLParser(t, resp.Body)
+	assert.EqualValues(t, 1, htmlDoc.doc.Find(fmt.Sprintf(".milestone.list .item a[href=\"/user2/-/projects/%d\"]", p.ID)).Length())
+
+	// only the user can change its projects
+	session = loginUser(t, "user4")
+	req = NewRequest(t, "GET", "/user2/-/projects/new")
+	session.MakeRequest(t, req, http.StatusNotFound)
+}
+
+func TestProjectAutomation(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	session := loginUser(t, "user2")
+	token := getTokenForLoggedInUser(t, session)
+
+	// closed issues of project 1 are moved to the board "Done"
+	req := NewRequestWithValues(t, "POST", "/user2/repo1/projects/1/automation", map[string]string{
+		"_csrf":        GetCSRF(t, session, "/user2/repo1/projects/1/automation"),
+		"trigger_type": "issue_closed",
+		"board_id":     "3",
+	})
+	session.MakeRequest(t, req, http.StatusSeeOther)
+	rule := unittest.AssertExistsAndLoadBean(t, &project_model.AutomationRule{ProjectID: 1, BoardID: 3}).(*project_model.AutomationRule)
+	assert.Equal(t, project_model.AutomationTriggerIssueClosed, rule.TriggerType)
+
+	// board 4 belongs to another project
+	req = NewRequestWithValues(t, "POST", "/user2/repo1/projects/1/automation", map[string]string{
+		"_csrf":        GetCSRF(t, session, "/user2/repo1/projects/1/automation"),
+		"trigger_type": "issue_closed",
+		"board_id":     "4",
+	})
+	session.MakeRequest(t, req, http.StatusSeeOther)
+	unittest.AssertNotExistsBean(t, &project_model.AutomationRule{BoardID: 4})
+
+	closed := "closed"
+	req = NewRequestWithJSON(t, "PATCH", fmt.Sprintf("/api/v1/repos/user2/repo1/issues/1?token=%s", token), &api.EditIssueOption{
+		State: &closed,
+	})
+	session.MakeRequest(t, req, http.StatusCreated)
+	unittest.AssertExistsAndLoadBean(t, &project_model.ProjectIssue{IssueID: 1, ProjectID: 1, ProjectBoardID: 3})
+
+	// issues getting the label "orglabel3" are added to the organization project
+	req = NewRequestWithValues(t, "POST", "/user3/-/projects/4/automation", map[string]string{
+		"_csrf":        GetCSRF(t, session, "/user3/-/projects/4/automation"),
+		"trigger_type": "issue_labeled",
+		"label_id":     "3",
+		"board_id":     "4",
+	})
+	session.MakeRequest(t, req, http.StatusSeeOther)
+	unittest.AssertExistsAndLoadBean(t, &project_model.AutomationRule{ProjectID: 4, LabelID: 3, BoardID: 4})
+
+	req = NewRequestWithJSON(t, "POST", fmt.Sprintf("/api/v1/repos/user3/repo3/issues/6/labels?token=%s", token), &api.IssueLabelsOption{
+		Labels: []int64{3},
+	})
+	session.MakeRequest(t, req, http.StatusOK)
+	unittest.AssertExistsAndLoadBean(t, &project_model.ProjectIssue{IssueID: 6, ProjectID: 4, ProjectBoardID: 4})
+
+	req = NewRequestWithValues(t, "POST", fmt.Sprintf("/user2/repo1/projects/1/automation/%d/delete", rule.ID), map[string]string{
+		"_csrf": GetCSRF(t, session, "/user2/repo1/projects/1/automation"),
+	})
+	session.MakeRequest(t, req, http.StatusSeeOther)
+	unittest.AssertNotExistsBean(t, &project_model.AutomationRule{ID: rule.ID})
+
+	// only users who can write the projects can change the rules
+	session = loginUser(t, "user4")
+	req = NewRequest(t, "GET", "/user2/repo1/projects/1/automation")
+	session.MakeRequest(t, req, http.StatusNotFound)
+}
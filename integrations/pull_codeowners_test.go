@@ -0,0 +1,74 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"code.gitea.io/gitea/models"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/models/unittest"
+	"code.gitea.io/gitea/modules/queue"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/services/forms"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPullCodeOwners(t *testing.T) {
+	onGiteaRun(t, func(t *testing.T, u *url.URL) {
+		ctx := NewAPITestContext(t, "user2", "repo1")
+
+		// "*.md @user4"
+		t.Run("CreateCodeOwners", doAPICreateFile(ctx, ".gitea/CODEOWNERS", &api.CreateFileOptions{
+			FileOptions: api.FileOptions{
+				BranchName:    "master",
+				NewBranchName: "master",
+				Message:       "Add CODEOWNERS",
+			},
+			Content: "Ki5tZCBAdXNlcjQK",
+		}))
+
+		testEditFileToNewBranch(t, ctx.Session, "user2", "repo1", "master", "codeowners", "README.md", "Owned by user4")
+		apiPull, err := doAPICreatePullRequest(ctx, "user2", "repo1", "master", "codeowners")(t)
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		// the code owner of README.md has been asked for a review
+		pr := unittest.AssertExistsAndLoadBean(t, &models.PullRequest{ID: apiPull.ID}).(*models.PullRequest)
+		unittest.AssertExistsAndLoadBean(t, &models.Review{IssueID: pr.IssueID, ReviewerID: 4, Type: models.ReviewTypeRequest})
+
+		csrf := GetCSRF(t, ctx.Session, "/user2/repo1/settings/branches")
+		req := NewRequestWithValues(t, "POST", "/user2/repo1/settings/branches/master", map[string]string{
+			"_csrf":                       csrf,
+			"protected":                   "on",
+			"require_code_owner_approval": "on",
+		})
+		ctx.Session.MakeRequest(t, req, http.StatusSeeOther)
+
+		queue.GetManager().FlushAll(context.Background(), 5*time.Second)
+
+		mergeURL := fmt.Sprintf("/api/v1/repos/user2/repo1/pulls/%d/merge?token=%s", apiPull.Index, ctx.Token)
+		req = NewRequestWithJSON(t, http.MethodPost, mergeURL, &forms.MergePullRequestForm{Do: string(repo_model.MergeStyleMerge)})
+		ctx.Session.MakeRequest(t, req, http.StatusMethodNotAllowed)
+
+		// approval of the code owner unblocks the merge
+		reviewerCtx := NewAPITestContext(t, "user4", "repo1")
+		req = NewRequestWithJSON(t, http.MethodPost, fmt.Sprintf("/api/v1/repos/user2/repo1/pulls/%d/reviews?token=%s", apiPull.Index, reviewerCtx.Token), &api.CreatePullReviewOptions{
+			Event: api.ReviewStateApproved,
+			Body:  "LGTM",
+		})
+		reviewerCtx.Session.MakeRequest(t, req, http.StatusOK)
+
+		req = NewRequestWithJSON(t, http.MethodPost, mergeURL, &forms.MergePullRequestForm{Do: string(repo_model.MergeStyleMerge)})
+		ctx.Session.MakeRequest(t, req, http.StatusOK)
+	})
+}
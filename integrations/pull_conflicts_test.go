@@ -0,0 +1,108 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"code.gitea.io/gitea/models"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/models/unittest"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/test"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPullResolveConflicts(t *testing.T) {
+	onGiteaRun(t, func(t *testing.T, u *url.URL) {
+		ctx := NewAPITestContext(t, "user2", "repo1")
+
+		testEditFileToNewBranch(t, ctx.Session, "user2", "repo1", "master", "conflicting", "README.md", "Head side\n")
+		testEditFile(t, ctx.Session, "user2", "repo1", "master", "README.md", "Base side\n")
+		apiPull, err := doAPICreatePullRequest(ctx, "user2", "repo1", "master", "conflicting")(t)
+		if !assert.NoError(t, err) {
+			return
+		}
+		pr := unittest.AssertExistsAndLoadBean(t, &models.PullRequest{ID: apiPull.ID}).(*models.PullRequest)
+		if !assert.EqualValues(t, []string{"README.md"}, pr.ConflictedFiles) {
+			return
+		}
+
+		conflictsURL := fmt.Sprintf("/user2/repo1/pulls/%d/conflicts", apiPull.Index)
+		req := NewRequest(t, "GET", conflictsURL)
+		resp := ctx.Session.MakeRequest(t, req, http.StatusOK)
+		htmlDoc := NewHTMLParser(t, resp.Body)
+		assert.EqualValues(t, "README.md", htmlDoc.Find("input[name=tree_path]").AttrOr("value", ""))
+		headCommitID := htmlDoc.Find("input[name=head_commit_id]").AttrOr("value", "")
+		assert.Len(t, headCommitID, 40)
+		merged := htmlDoc.Find("textarea[name=content]").Text()
+		assert.Contains(t, merged, "<<<<<<< ")
+		assert.Contains(t, merged, "Head side")
+		assert.Contains(t, merged, "Base side")
+
+		// a resolution which still contains the conflict markers is refused
+		req = NewRequestWithValues(t, "POST", conflictsURL, map[string]string{
+			"_csrf":          GetCSRF(t, ctx.Session, conflictsURL),
+			"head_commit_id": headCommitID,
+			"tree_path":      "README.md",
+			"content":        merged,
+		})
+		resp = ctx.Session.MakeRequest(t, req, http.StatusSeeOther)
+		assert.EqualValues(t, conflictsURL, test.RedirectURL(resp))
+
+		// a resolution made for another head commit is refused
+		req = NewRequestWithValues(t, "POST", conflictsURL, map[string]string{
+			"_csrf":          GetCSRF(t, ctx.Session, conflictsURL),
+			"head_commit_id": strings.Repeat("0", 40),
+			"tree_path":      "README.md",
+			"content":        "Head and base side\r\n",
+		})
+		resp = ctx.Session.MakeRequest(t, req, http.StatusSeeOther)
+		assert.EqualValues(t, conflictsURL, test.RedirectURL(resp))
+		flashCookie := ctx.Session.GetCookie("macaron_flash")
+		assert.NotNil(t, flashCookie)
+		assert.Contains(t, flashCookie.Value, "error")
+
+		req = NewRequestWithValues(t, "POST", conflictsURL, map[string]string{
+			"_csrf":          GetCSRF(t, ctx.Session, conflictsURL),
+			"head_commit_id": headCommitID,
+			"tree_path":      "README.md",
+			"content":        "Head and base side\r\n",
+		})
+		resp = ctx.Session.MakeRequest(t, req, http.StatusSeeOther)
+		assert.EqualValues(t, fmt.Sprintf("/user2/repo1/pulls/%d", apiPull.Index), test.RedirectURL(resp))
+
+		// the head branch now contains a merge of the base branch with the resolution
+		repo := unittest.AssertExistsAndLoadBean(t, &repo_model.Repository{ID: pr.HeadRepoID}).(*repo_model.Repository)
+		gitRepo, err := git.OpenRepository(git.DefaultContext, repo.RepoPath())
+		if !assert.NoError(t, err) {
+			return
+		}
+		defer gitRepo.Close()
+		commit, err := gitRepo.GetBranchCommit("conflicting")
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.EqualValues(t, 2, commit.ParentCount())
+		masterCommitID, err := gitRepo.GetBranchCommitID("master")
+		assert.NoError(t, err)
+		parent, err := commit.ParentID(1)
+		assert.NoError(t, err)
+		assert.EqualValues(t, masterCommitID, parent.String())
+
+		entry, err := commit.GetTreeEntryByPath("README.md")
+		if !assert.NoError(t, err) {
+			return
+		}
+		content, err := entry.Blob().GetBlobContent()
+		assert.NoError(t, err)
+		assert.EqualValues(t, "Head and base side\n", content)
+	})
+}
@@ -0,0 +1,105 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	pull_model "code.gitea.io/gitea/models/pull"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/models/unittest"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/queue"
+	api "code.gitea.io/gitea/modules/structs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPullIterations(t *testing.T) {
+	onGiteaRun(t, func(t *testing.T, u *url.URL) {
+		ctx := NewAPITestContext(t, "user2", "repo1")
+
+		testEditFileToNewBranch(t, ctx.Session, "user2", "repo1", "master", "iterations", "README.md", "First iteration")
+		apiPull, err := doAPICreatePullRequest(ctx, "user2", "repo1", "master", "iterations")(t)
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		// a regular push to the head branch
+		testEditFile(t, ctx.Session, "user2", "repo1", "iterations", "README.md", "Second iteration")
+		queue.GetManager().FlushAll(context.Background(), 5*time.Second)
+
+		// rebasing the head branch onto the advanced base branch force-pushes it
+		t.Run("AdvanceBase", doAPICreateFile(ctx, "iterations.txt", &api.CreateFileOptions{
+			FileOptions: api.FileOptions{
+				BranchName:    "master",
+				NewBranchName: "master",
+				Message:       "Advance master",
+			},
+			Content: "YWR2YW5jZWQK",
+		}))
+		req := NewRequestf(t, "POST", "/api/v1/repos/user2/repo1/pulls/%d/update?style=rebase&token=%s", apiPull.Index, ctx.Token)
+		ctx.Session.MakeRequest(t, req, http.StatusOK)
+		queue.GetManager().FlushAll(context.Background(), 5*time.Second)
+
+		req = NewRequestf(t, "GET", "/api/v1/repos/user2/repo1/pulls/%d/iterations?token=%s", apiPull.Index, ctx.Token)
+		resp := ctx.Session.MakeRequest(t, req, http.StatusOK)
+		var iterations []*api.PullRequestIteration
+		DecodeJSON(t, resp, &iterations)
+		if !assert.Len(t, iterations, 3) {
+			return
+		}
+		assert.EqualValues(t, apiPull.Head.Sha, iterations[0].HeadSHA)
+		assert.False(t, iterations[1].IsForcePush)
+		assert.True(t, iterations[2].IsForcePush)
+		assert.NotEqual(t, iterations[1].MergeBase, iterations[2].MergeBase)
+
+		compareURL := fmt.Sprintf("/api/v1/repos/user2/repo1/pulls/%d/iterations/compare?token=%s", apiPull.Index, ctx.Token)
+
+		req = NewRequest(t, "GET", compareURL+"&from=1&to=2")
+		resp = ctx.Session.MakeRequest(t, req, http.StatusOK)
+		var comparison api.PullRequestIterationComparison
+		DecodeJSON(t, resp, &comparison)
+		assert.False(t, comparison.IsRebased)
+		assert.Contains(t, comparison.Diff, "+Second iteration")
+		assert.Empty(t, comparison.RangeDiff)
+
+		// the latest iteration is compared to the one before by default
+		req = NewRequest(t, "GET", compareURL)
+		resp = ctx.Session.MakeRequest(t, req, http.StatusOK)
+		comparison = api.PullRequestIterationComparison{}
+		DecodeJSON(t, resp, &comparison)
+		assert.EqualValues(t, 2, comparison.From.Index)
+		assert.EqualValues(t, 3, comparison.To.Index)
+		assert.True(t, comparison.IsRebased)
+
+		req = NewRequest(t, "GET", compareURL+"&from=1&to=4")
+		ctx.Session.MakeRequest(t, req, http.StatusNotFound)
+
+		// the web page lists the iterations
+		req = NewRequestf(t, "GET", "/user2/repo1/pulls/%d/iterations", apiPull.Index)
+		ctx.Session.MakeRequest(t, req, http.StatusOK)
+
+		// deleting the pull request removes its iterations
+		req = NewRequestf(t, "DELETE", "/api/v1/repos/user2/repo1/issues/%d?token=%s", apiPull.Index, ctx.Token)
+		ctx.Session.MakeRequest(t, req, http.StatusNoContent)
+		unittest.AssertNotExistsBean(t, &pull_model.Iteration{PullID: apiPull.ID})
+
+		repo := unittest.AssertExistsAndLoadBean(t, &repo_model.Repository{OwnerName: "user2", Name: "repo1"}).(*repo_model.Repository)
+		gitRepo, err := git.OpenRepository(git.DefaultContext, repo.RepoPath())
+		if !assert.NoError(t, err) {
+			return
+		}
+		defer gitRepo.Close()
+		refs, err := gitRepo.GetRefsFiltered(fmt.Sprintf("%s%d/", git.PullPrefix, apiPull.Index))
+		assert.NoError(t, err)
+		assert.Empty(t, refs)
+	})
+}
@@ -0,0 +1,157 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/models/db"
+	pull_model "code.gitea.io/gitea/models/pull"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/models/unittest"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/queue"
+	api "code.gitea.io/gitea/modules/structs"
+	pull_service "code.gitea.io/gitea/services/pull"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPullMergeQueue(t *testing.T) {
+	onGiteaRun(t, func(t *testing.T, u *url.URL) {
+		ctx := NewAPITestContext(t, "user2", "repo1")
+		repo := unittest.AssertExistsAndLoadBean(t, &repo_model.Repository{OwnerName: "user2", Name: "repo1"}).(*repo_model.Repository)
+
+		// require the "ci" status check and enable the merge queue on master
+		csrf := GetCSRF(t, ctx.Session, "/user2/repo1/settings/branches")
+		req := NewRequestWithValues(t, "POST", "/user2/repo1/settings/branches/master", map[string]string{
+			"_csrf":                 csrf,
+			"protected":             "on",
+			"enable_status_check":   "on",
+			"status_check_contexts": "ci",
+			"enable_merge_queue":    "on",
+		})
+		ctx.Session.MakeRequest(t, req, http.StatusSeeOther)
+
+		createQueuedPull := func(t *testing.T, branch string) *models.PullRequest {
+			t.Run("CreateFile", doAPICreateFile(ctx, fmt.Sprintf("merge-queue-%s.txt", branch), &api.CreateFileOptions{
+				FileOptions: api.FileOptions{
+					BranchName:    "master",
+					NewBranchName: branch,
+					Message:       "Add " + branch,
+				},
+				Content: "bWVyZ2UgcXVldWU=",
+			}))
+			apiPull, err := doAPICreatePullRequest(ctx, "user2", "repo1", "master", branch)(t)
+			assert.NoError(t, err)
+			setCommitStatus(t, ctx, apiPull.Head.Sha, api.CommitStatusSuccess)
+
+			mergeCtx := ctx
+			mergeCtx.ExpectedCode = http.StatusAccepted
+			t.Run("Merge", doAPIMergePullRequest(mergeCtx, "user2", "repo1", apiPull.Index))
+
+			return unittest.AssertExistsAndLoadBean(t, &models.PullRequest{ID: apiPull.ID}).(*models.PullRequest)
+		}
+
+		pr1 := createQueuedPull(t, "queue-1")
+		pr2 := createQueuedPull(t, "queue-2")
+		assert.False(t, pr1.HasMerged)
+		assert.False(t, pr2.HasMerged)
+
+		// queuing a pull request twice is refused
+		mergeCtx := ctx
+		mergeCtx.ExpectedCode = http.StatusConflict
+		t.Run("MergeAgain", doAPIMergePullRequest(mergeCtx, "user2", "repo1", pr1.Index))
+
+		queue.GetManager().FlushAll(context.Background(), 5*time.Second)
+
+		// the second entry is built on top of the first one
+		entry1, err := pull_model.GetMergeQueueEntryByPullID(db.DefaultContext, pr1.ID)
+		assert.NoError(t, err)
+		entry2, err := pull_model.GetMergeQueueEntryByPullID(db.DefaultContext, pr2.ID)
+		assert.NoError(t, err)
+		assert.NotEmpty(t, entry1.CommitID)
+		assert.Equal(t, entry1.CommitID, entry2.ParentCommitID)
+
+		gitRepo, err := git.OpenRepository(git.DefaultContext, repo.RepoPath())
+		assert.NoError(t, err)
+		defer gitRepo.Close()
+		assert.True(t, gitRepo.IsBranchExist(pull_service.MergeQueueBranch(pr1)))
+		masterCommitID, err := gitRepo.GetBranchCommitID("master")
+		assert.NoError(t, err)
+		assert.NotEqual(t, entry1.CommitID, masterCommitID)
+
+		// a successful check of the temporary merge commit merges the first entry
+		setCommitStatus(t, ctx, entry1.CommitID, api.CommitStatusSuccess)
+		queue.GetManager().FlushAll(context.Background(), 5*time.Second)
+
+		pr1 = unittest.AssertExistsAndLoadBean(t, &models.PullRequest{ID: pr1.ID}).(*models.PullRequest)
+		assert.True(t, pr1.HasMerged)
+		assert.Equal(t, entry1.CommitID, pr1.MergedCommitID)
+		masterCommitID, err = gitRepo.GetBranchCommitID("master")
+		assert.NoError(t, err)
+		assert.Equal(t, entry1.CommitID, masterCommitID)
+		assert.False(t, gitRepo.IsBranchExist(pull_service.MergeQueueBranch(pr1)))
+		unittest.AssertNotExistsBean(t, &pull_model.MergeQueueEntry{PullID: pr1.ID})
+
+		// a failed check removes the second entry from the queue
+		setCommitStatus(t, ctx, entry2.CommitID, api.CommitStatusFailure)
+		queue.GetManager().FlushAll(context.Background(), 5*time.Second)
+
+		pr2 = unittest.AssertExistsAndLoadBean(t, &models.PullRequest{ID: pr2.ID}).(*models.PullRequest)
+		assert.False(t, pr2.HasMerged)
+		unittest.AssertNotExistsBean(t, &pull_model.MergeQueueEntry{PullID: pr2.ID})
+		unittest.AssertExistsAndLoadBean(t, &models.Comment{
+			IssueID: pr2.IssueID,
+			Type:    models.CommentTypePRRemovedFromMergeQueue,
+			Content: "checks_failed",
+		})
+
+		// a commit without statuses is merged if no context is required
+		csrf = GetCSRF(t, ctx.Session, "/user2/repo1/settings/branches")
+		req = NewRequestWithValues(t, "POST", "/user2/repo1/settings/branches/master", map[string]string{
+			"_csrf":               csrf,
+			"protected":           "on",
+			"enable_status_check": "on",
+			"enable_merge_queue":  "on",
+		})
+		ctx.Session.MakeRequest(t, req, http.StatusSeeOther)
+
+		pr3 := createQueuedPull(t, "queue-3")
+		queue.GetManager().FlushAll(context.Background(), 5*time.Second)
+		pr3 = unittest.AssertExistsAndLoadBean(t, &models.PullRequest{ID: pr3.ID}).(*models.PullRequest)
+		assert.True(t, pr3.HasMerged)
+		unittest.AssertNotExistsBean(t, &pull_model.MergeQueueEntry{PullID: pr3.ID})
+
+		// statuses are ignored if the status checks of the branch are disabled
+		csrf = GetCSRF(t, ctx.Session, "/user2/repo1/settings/branches")
+		req = NewRequestWithValues(t, "POST", "/user2/repo1/settings/branches/master", map[string]string{
+			"_csrf":              csrf,
+			"protected":          "on",
+			"enable_merge_queue": "on",
+		})
+		ctx.Session.MakeRequest(t, req, http.StatusSeeOther)
+
+		pr4 := createQueuedPull(t, "queue-4")
+		queue.GetManager().FlushAll(context.Background(), 5*time.Second)
+		pr4 = unittest.AssertExistsAndLoadBean(t, &models.PullRequest{ID: pr4.ID}).(*models.PullRequest)
+		assert.True(t, pr4.HasMerged)
+		unittest.AssertNotExistsBean(t, &pull_model.MergeQueueEntry{PullID: pr4.ID})
+	})
+}
+
+func setCommitStatus(t *testing.T, ctx APITestContext, sha string, state api.CommitStatusState) {
+	req := NewRequestWithJSON(t, "POST", fmt.Sprintf("/api/v1/repos/%s/%s/statuses/%s?token=%s", ctx.Username, ctx.Reponame, sha, ctx.Token), api.CreateStatusOption{
+		State:   state,
+		Context: "ci",
+	})
+	ctx.Session.MakeRequest(t, req, http.StatusCreated)
+}
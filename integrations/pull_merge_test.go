@@ -23,9 +23,11 @@ import (
 	user_model "code.gitea.io/gitea/models/user"
 	"code.gitea.io/gitea/models/webhook"
 	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/queue"
 	api "code.gitea.io/gitea/modules/structs"
 	"code.gitea.io/gitea/modules/test"
 	"code.gitea.io/gitea/modules/translation/i18n"
+	"code.gitea.io/gitea/services/forms"
 	"code.gitea.io/gitea/services/pull"
 	repo_service "code.gitea.io/gitea/services/repository"
 	files_service "code.gitea.io/gitea/services/repository/files"
@@ -155,6 +157,55 @@ func TestPullSquash(t *testing.T) {
 	})
 }
 
+func TestPullFastForwardOnly(t *testing.T) {
+	onGiteaRun(t, func(t *testing.T, giteaURL *url.URL) {
+		ownerCtx := NewAPITestContext(t, "user2", "repo1")
+		allow := true
+		req := NewRequestWithJSON(t, "PATCH", "/api/v1/repos/user2/repo1?token="+ownerCtx.Token, &api.EditRepoOption{
+			AllowFastForwardOnly: &allow,
+		})
+		ownerCtx.Session.MakeRequest(t, req, http.StatusOK)
+
+		session := loginUser(t, "user1")
+		testRepoFork(t, session, "user2", "repo1", "user1", "repo1")
+		testEditFile(t, session, "user1", "repo1", "master", "README.md", "Hello, World (Edited)\n")
+
+		resp := testPullCreate(t, session, "user1", "repo1", "master", "This is a pull title")
+
+		elem := strings.Split(test.RedirectURL(resp), "/")
+		assert.EqualValues(t, "pulls", elem[3])
+		testPullMerge(t, session, elem[1], elem[2], elem[4], repo_model.MergeStyleFastForwardOnly)
+
+		// the base branch points to the very same commit as the head branch
+		forkRepo := unittest.AssertExistsAndLoadBean(t, &repo_model.Repository{OwnerName: "user1", Name: "repo1"}).(*repo_model.Repository)
+		headCommitID, err := git.GetFullCommitID(git.DefaultContext, forkRepo.RepoPath(), "master")
+		assert.NoError(t, err)
+		baseRepo := unittest.AssertExistsAndLoadBean(t, &repo_model.Repository{OwnerName: "user2", Name: "repo1"}).(*repo_model.Repository)
+		baseCommitID, err := git.GetFullCommitID(git.DefaultContext, baseRepo.RepoPath(), "master")
+		assert.NoError(t, err)
+		assert.Equal(t, headCommitID, baseCommitID)
+
+		// once the base branch has diverged the head can't be fast-forwarded to anymore
+		t.Run("CreateFile", doAPICreateFile(ownerCtx, "diverged.txt", &api.CreateFileOptions{
+			FileOptions: api.FileOptions{
+				BranchName:    "master",
+				NewBranchName: "master",
+				Message:       "Diverge",
+			},
+			Content: "ZGl2ZXJnZWQ=",
+		}))
+		testEditFile(t, session, "user1", "repo1", "master", "README.md", "Hello, World (Edited again)\n")
+		apiPull, err := doAPICreatePullRequest(ownerCtx, "user2", "repo1", "master", "user1:master")(t)
+		assert.NoError(t, err)
+		queue.GetManager().FlushAll(context.Background(), 5*time.Second)
+
+		req = NewRequestWithJSON(t, "POST", fmt.Sprintf("/api/v1/repos/user2/repo1/pulls/%d/merge?token=%s", apiPull.Index, ownerCtx.Token), &forms.MergePullRequestForm{
+			Do: string(repo_model.MergeStyleFastForwardOnly),
+		})
+		ownerCtx.Session.MakeRequest(t, req, http.StatusConflict)
+	})
+}
+
 func TestPullCleanUpAfterMerge(t *testing.T) {
 	onGiteaRun(t, func(t *testing.T, giteaURL *url.URL) {
 		session := loginUser(t, "user1")
@@ -0,0 +1,80 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"code.gitea.io/gitea/models"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/models/unittest"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/queue"
+	api "code.gitea.io/gitea/modules/structs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPullStack(t *testing.T) {
+	onGiteaRun(t, func(t *testing.T, u *url.URL) {
+		ctx := NewAPITestContext(t, "user2", "repo1")
+
+		rebase := true
+		t.Run("EnableRebase", doAPIEditRepository(ctx, &api.EditRepoOption{RebaseDependentPullsAfterMerge: &rebase}))
+
+		testEditFileToNewBranch(t, ctx.Session, "user2", "repo1", "master", "stack-bottom", "README.md", "Bottom of the stack")
+		bottom, err := doAPICreatePullRequest(ctx, "user2", "repo1", "master", "stack-bottom")(t)
+		if !assert.NoError(t, err) {
+			return
+		}
+		testEditFileToNewBranch(t, ctx.Session, "user2", "repo1", "stack-bottom", "stack-top", "README.md", "Top of the stack")
+		top, err := doAPICreatePullRequest(ctx, "user2", "repo1", "stack-bottom", "stack-top")(t)
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		// both pull requests show the stack
+		for _, index := range []int64{bottom.Index, top.Index} {
+			req := NewRequestf(t, "GET", "/user2/repo1/pulls/%d", index)
+			resp := ctx.Session.MakeRequest(t, req, http.StatusOK)
+			htmlDoc := NewHTMLParser(t, resp.Body)
+			assert.EqualValues(t, 2, htmlDoc.Find(".pull-stack .item").Length())
+			assert.EqualValues(t, fmt.Sprintf("/user2/repo1/pulls/%d", top.Index+bottom.Index-index),
+				htmlDoc.Find(".pull-stack .item a").AttrOr("href", ""))
+		}
+
+		queue.GetManager().FlushAll(context.Background(), 5*time.Second)
+		t.Run("MergeBottom", doAPIMergePullRequest(ctx, "user2", "repo1", bottom.Index))
+		queue.GetManager().FlushAll(context.Background(), 5*time.Second)
+
+		// the top pull request has been retargeted to master and rebased onto it
+		bottomPR := unittest.AssertExistsAndLoadBean(t, &models.PullRequest{ID: bottom.ID}).(*models.PullRequest)
+		topPR := unittest.AssertExistsAndLoadBean(t, &models.PullRequest{ID: top.ID}).(*models.PullRequest)
+		assert.True(t, bottomPR.HasMerged)
+		assert.False(t, topPR.HasMerged)
+		assert.EqualValues(t, "master", topPR.BaseBranch)
+		unittest.AssertExistsAndLoadBean(t, &models.Comment{
+			IssueID: topPR.IssueID,
+			Type:    models.CommentTypeChangeTargetBranch,
+			OldRef:  "stack-bottom",
+			NewRef:  "master",
+		})
+
+		repo := unittest.AssertExistsAndLoadBean(t, &repo_model.Repository{ID: topPR.BaseRepoID}).(*repo_model.Repository)
+		gitRepo, err := git.OpenRepository(git.DefaultContext, repo.RepoPath())
+		if !assert.NoError(t, err) {
+			return
+		}
+		defer gitRepo.Close()
+		rebased, err := gitRepo.IsCommitInBranch(bottomPR.MergedCommitID, "stack-top")
+		assert.NoError(t, err)
+		assert.True(t, rebased)
+	})
+}
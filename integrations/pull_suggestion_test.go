@@ -0,0 +1,84 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"code.gitea.io/gitea/models"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/models/unittest"
+	"code.gitea.io/gitea/modules/git"
+	api "code.gitea.io/gitea/modules/structs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPullApplySuggestions(t *testing.T) {
+	onGiteaRun(t, func(t *testing.T, u *url.URL) {
+		ctx := NewAPITestContext(t, "user2", "repo1")
+		repo := unittest.AssertExistsAndLoadBean(t, &repo_model.Repository{OwnerName: "user2", Name: "repo1"}).(*repo_model.Repository)
+
+		testEditFileToNewBranch(t, ctx.Session, "user2", "repo1", "master", "suggestions", "README.md", "first\nsecond\nthird\n")
+		apiPull, err := doAPICreatePullRequest(ctx, "user2", "repo1", "master", "suggestions")(t)
+		if !assert.NoError(t, err) {
+			return
+		}
+		pr := unittest.AssertExistsAndLoadBean(t, &models.PullRequest{ID: apiPull.ID}).(*models.PullRequest)
+
+		reviewerCtx := NewAPITestContext(t, "user4", "repo1")
+		req := NewRequestWithJSON(t, http.MethodPost, fmt.Sprintf("/api/v1/repos/user2/repo1/pulls/%d/reviews?token=%s", apiPull.Index, reviewerCtx.Token), &api.CreatePullReviewOptions{
+			Event: api.ReviewStateComment,
+			Body:  "Some suggestions",
+			Comments: []api.CreatePullReviewComment{
+				{Path: "README.md", Body: "Shout\n```suggestion\nSECOND\nSECOND AGAIN\n```", NewLineNum: 2},
+				{Path: "README.md", Body: "```suggestion\n```", NewLineNum: 3},
+			},
+		})
+		reviewerCtx.Session.MakeRequest(t, req, http.StatusOK)
+
+		comment2 := unittest.AssertExistsAndLoadBean(t, &models.Comment{IssueID: pr.IssueID, Type: models.CommentTypeCode, Line: 2}).(*models.Comment)
+		comment3 := unittest.AssertExistsAndLoadBean(t, &models.Comment{IssueID: pr.IssueID, Type: models.CommentTypeCode, Line: 3}).(*models.Comment)
+
+		readme := func(t *testing.T) string {
+			gitRepo, err := git.OpenRepository(git.DefaultContext, repo.RepoPath())
+			assert.NoError(t, err)
+			defer gitRepo.Close()
+			commit, err := gitRepo.GetBranchCommit("suggestions")
+			assert.NoError(t, err)
+			content, err := commit.GetFileContent("README.md", 1024)
+			assert.NoError(t, err)
+			return content
+		}
+
+		applySuggestions := func(t *testing.T, session *TestSession, commentIDs string) {
+			req := NewRequestWithValues(t, "POST", fmt.Sprintf("/user2/repo1/pulls/%d/suggestions/apply", apiPull.Index), map[string]string{
+				"_csrf":       GetCSRF(t, session, fmt.Sprintf("/user2/repo1/pulls/%d/files", apiPull.Index)),
+				"comment_ids": commentIDs,
+			})
+			session.MakeRequest(t, req, http.StatusSeeOther)
+		}
+
+		// the reviewer cannot push to the head branch
+		applySuggestions(t, reviewerCtx.Session, fmt.Sprint(comment2.ID))
+		assert.Equal(t, "first\nsecond\nthird\n", readme(t))
+
+		// both suggestions are applied in a single commit, repeated ones are only counted once
+		applySuggestions(t, ctx.Session, fmt.Sprintf("%d,%d,%d", comment2.ID, comment3.ID, comment2.ID))
+		assert.Equal(t, "first\nSECOND\nSECOND AGAIN\n", readme(t))
+		flashCookie := ctx.Session.GetCookie("macaron_flash")
+		if assert.NotNil(t, flashCookie) {
+			assert.True(t, strings.HasPrefix(flashCookie.Value, "success%3D2%2Bsuggestion"))
+		}
+
+		// the commented lines have changed, so the suggestion cannot be applied again
+		applySuggestions(t, ctx.Session, fmt.Sprint(comment2.ID))
+		assert.Equal(t, "first\nSECOND\nSECOND AGAIN\n", readme(t))
+	})
+}
@@ -0,0 +1,88 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+
+	api "code.gitea.io/gitea/modules/structs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPullRequestTemplates(t *testing.T) {
+	onGiteaRun(t, func(t *testing.T, u *url.URL) {
+		ctx := NewAPITestContext(t, "user2", "repo1")
+
+		template := "---\nname: \"Hotfix\"\nabout: \"Fix a bug of a release\"\ntitle: \"[HOTFIX] \"\n---\n- [ ] Backported to the release branches\n"
+		t.Run("CreateTemplate", doAPICreateFile(ctx, ".gitea/PULL_REQUEST_TEMPLATE/hotfix.md", &api.CreateFileOptions{
+			FileOptions: api.FileOptions{
+				BranchName:    "master",
+				NewBranchName: "master",
+				Message:       "Add hotfix pull request template",
+			},
+			Content: base64.StdEncoding.EncodeToString([]byte(template)),
+		}))
+
+		// templates without metadata are named after their file
+		t.Run("CreatePlainTemplate", doAPICreateFile(ctx, ".gitea/PULL_REQUEST_TEMPLATE/docs.md", &api.CreateFileOptions{
+			FileOptions: api.FileOptions{
+				BranchName:    "master",
+				NewBranchName: "master",
+				Message:       "Add docs pull request template",
+			},
+			Content: base64.StdEncoding.EncodeToString([]byte("- [ ] Documentation is updated\n")),
+		}))
+
+		req := NewRequestf(t, "GET", "/api/v1/repos/user2/repo1/pull_request_templates?token=%s", ctx.Token)
+		resp := ctx.Session.MakeRequest(t, req, http.StatusOK)
+		var templates []*api.IssueTemplate
+		DecodeJSON(t, resp, &templates)
+		if !assert.Len(t, templates, 2) {
+			return
+		}
+		assert.EqualValues(t, "docs", templates[0].Name)
+		assert.EqualValues(t, "docs.md", templates[0].FileName)
+		assert.EqualValues(t, "- [ ] Documentation is updated\n", templates[0].Content)
+		assert.EqualValues(t, "Hotfix", templates[1].Name)
+		assert.EqualValues(t, "hotfix.md", templates[1].FileName)
+
+		testEditFileToNewBranch(t, ctx.Session, "user2", "repo1", "master", "hotfix", "README.md", "Hotfix")
+
+		// the template pre-fills the form to create a pull request
+		req = NewRequest(t, "GET", "/user2/repo1/compare/master...hotfix?expand=1&template=hotfix.md")
+		resp = ctx.Session.MakeRequest(t, req, http.StatusOK)
+		htmlDoc := NewHTMLParser(t, resp.Body)
+		assert.EqualValues(t, "[HOTFIX] ", htmlDoc.Find("#issue_title").AttrOr("value", ""))
+		assert.Contains(t, htmlDoc.Find(".pullrequest-form textarea[name=content]").Text(), "- [ ] Backported to the release branches")
+		assert.EqualValues(t, 2, htmlDoc.Find(".pull-request-templates .menu .item").Length())
+		// choosing another template keeps the other parameters
+		assert.EqualValues(t, "?expand=1&template=docs.md", htmlDoc.Find(".pull-request-templates .menu .item").First().AttrOr("href", ""))
+
+		// the API fills in the body from the template
+		req = NewRequestWithJSON(t, http.MethodPost, fmt.Sprintf("/api/v1/repos/user2/repo1/pulls?token=%s", ctx.Token), &api.CreatePullRequestOption{
+			Head:     "hotfix",
+			Base:     "master",
+			Title:    "Hotfix",
+			Template: "nonexistent.md",
+		})
+		ctx.Session.MakeRequest(t, req, http.StatusUnprocessableEntity)
+
+		req = NewRequestWithJSON(t, http.MethodPost, fmt.Sprintf("/api/v1/repos/user2/repo1/pulls?token=%s", ctx.Token), &api.CreatePullRequestOption{
+			Head:     "hotfix",
+			Base:     "master",
+			Title:    "Hotfix",
+			Template: "Hotfix",
+		})
+		resp = ctx.Session.MakeRequest(t, req, http.StatusCreated)
+		var pull api.PullRequest
+		DecodeJSON(t, resp, &pull)
+		assert.Contains(t, pull.Body, "- [ ] Backported to the release branches")
+	})
+}
@@ -0,0 +1,85 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package actions
+
+import (
+	"context"
+	"errors"
+
+	"code.gitea.io/gitea/models/db"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/modules/timeutil"
+)
+
+// ErrRunNotExist indicates a run not exist error
+var ErrRunNotExist = errors.New("Run does not exist")
+
+func init() {
+	db.RegisterModel(new(ActionRun))
+}
+
+// ActionRun represents a single execution of a workflow
+type ActionRun struct {
+	ID            int64                  `xorm:"pk autoincr"`
+	RepoID        int64                  `xorm:"INDEX NOT NULL"`
+	Repo          *repo_model.Repository `xorm:"-"`
+	WorkflowID    string                 `xorm:"NOT NULL"` // the name of the workflow file
+	Name          string                 `xorm:"NOT NULL"`
+	TriggerUserID int64                  `xorm:"NOT NULL"`
+	Event         string                 `xorm:"NOT NULL"`
+	Ref           string                 `xorm:"NOT NULL"`
+	CommitSHA     string                 `xorm:"INDEX NOT NULL"`
+	PullRequestID int64                  `xorm:"NOT NULL DEFAULT 0"`
+	NeedApproval  bool                   `xorm:"INDEX NOT NULL DEFAULT false"` // the jobs are not picked until a user with write access approves the run
+	ApprovedBy    int64                  `xorm:"NOT NULL DEFAULT 0"`
+	Status        Status                 `xorm:"INDEX NOT NULL"`
+	StartedUnix   timeutil.TimeStamp     `xorm:"NOT NULL DEFAULT 0"`
+	StoppedUnix   timeutil.TimeStamp     `xorm:"NOT NULL DEFAULT 0"`
+	CreatedUnix   timeutil.TimeStamp     `xorm:"created INDEX NOT NULL"`
+	UpdatedUnix   timeutil.TimeStamp     `xorm:"updated NOT NULL"`
+}
+
+// LoadRepo loads the repository of the run
+func (run *ActionRun) LoadRepo(ctx context.Context) error {
+	if run.Repo != nil {
+		return nil
+	}
+	repo, err := repo_model.GetRepositoryByIDCtx(ctx, run.RepoID)
+	if err != nil {
+		return err
+	}
+	run.Repo = repo
+	return nil
+}
+
+// GetRunByID gets a run by id
+func GetRunByID(ctx context.Context, runID int64) (*ActionRun, error) {
+	run := &ActionRun{}
+	has, err := db.GetEngine(ctx).ID(runID).Get(run)
+	if err != nil {
+		return nil, err
+	}
+	if !has {
+		return nil, ErrRunNotExist
+	}
+	return run, nil
+}
+
+// GetRunsByRepoID gets the runs of a repository, newest first
+func GetRunsByRepoID(ctx context.Context, repoID int64, opts db.ListOptions) ([]*ActionRun, int64, error) {
+	sess := db.GetEngine(ctx).Where("repo_id = ?", repoID).OrderBy("id DESC")
+	if opts.PageSize > 0 {
+		sess = db.SetSessionPagination(sess, &opts)
+	}
+	runs := make([]*ActionRun, 0, 10)
+	count, err := sess.FindAndCount(&runs)
+	return runs, count, err
+}
+
+// UpdateRun updates the given columns of the run
+func UpdateRun(ctx context.Context, run *ActionRun, cols ...string) error {
+	_, err := db.GetEngine(ctx).ID(run.ID).Cols(cols...).Update(run)
+	return err
+}
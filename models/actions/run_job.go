@@ -0,0 +1,193 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package actions
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+
+	"code.gitea.io/gitea/models/auth"
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/timeutil"
+	"code.gitea.io/gitea/modules/util"
+
+	gouuid "github.com/google/uuid"
+
+	"xorm.io/builder"
+)
+
+// ErrRunJobNotExist indicates a job not exist error
+var ErrRunJobNotExist = errors.New("Job does not exist")
+
+// maxPickCandidates limits the number of waiting jobs inspected when a runner asks for a job
+const maxPickCandidates = 50
+
+func init() {
+	db.RegisterModel(new(ActionRunJob))
+}
+
+// ActionRunJob represents a job of a run
+type ActionRunJob struct {
+	ID          int64              `xorm:"pk autoincr"`
+	RunID       int64              `xorm:"INDEX NOT NULL"`
+	RepoID      int64              `xorm:"INDEX NOT NULL"`
+	JobID       string             `xorm:"NOT NULL"` // the id of the job in the workflow file
+	Name        string             `xorm:"NOT NULL"`
+	Needs       []string           `xorm:"TEXT JSON"`
+	RunsOn      []string           `xorm:"TEXT JSON"`
+	Payload     string             `xorm:"LONGTEXT"` // the job definition as JSON
+	Status      Status             `xorm:"INDEX NOT NULL"`
+	RunnerID    int64              `xorm:"INDEX NOT NULL DEFAULT 0"`
+	LogChunks   int64              `xorm:"NOT NULL DEFAULT 0"`
+	StartedUnix timeutil.TimeStamp `xorm:"NOT NULL DEFAULT 0"`
+	StoppedUnix timeutil.TimeStamp `xorm:"NOT NULL DEFAULT 0"`
+	CreatedUnix timeutil.TimeStamp `xorm:"created NOT NULL"`
+	UpdatedUnix timeutil.TimeStamp `xorm:"updated NOT NULL"`
+
+	// the token the runner accesses the repository with, it is only valid while the job is running
+	Token          string `xorm:"-"`
+	TokenHash      string
+	TokenSalt      string
+	TokenLastEight string `xorm:"INDEX token_last_eight"`
+}
+
+// LogChunkPath returns the storage path of a log chunk of the job
+func (job *ActionRunJob) LogChunkPath(chunk int64) string {
+	return fmt.Sprintf("%d/%d/%d.log", job.RepoID, job.ID, chunk)
+}
+
+// LogChunkPaths returns the storage paths of all log chunks of the job
+func (job *ActionRunJob) LogChunkPaths() []string {
+	paths := make([]string, 0, job.LogChunks)
+	for i := int64(0); i < job.LogChunks; i++ {
+		paths = append(paths, job.LogChunkPath(i))
+	}
+	return paths
+}
+
+// GetRunJobByID gets a job by id
+func GetRunJobByID(ctx context.Context, jobID int64) (*ActionRunJob, error) {
+	job := &ActionRunJob{}
+	has, err := db.GetEngine(ctx).ID(jobID).Get(job)
+	if err != nil {
+		return nil, err
+	}
+	if !has {
+		return nil, ErrRunJobNotExist
+	}
+	return job, nil
+}
+
+// GetRunJobsByRunID gets all jobs of a run
+func GetRunJobsByRunID(ctx context.Context, runID int64) ([]*ActionRunJob, error) {
+	jobs := make([]*ActionRunJob, 0, 5)
+	return jobs, db.GetEngine(ctx).Where("run_id = ?", runID).OrderBy("id").Find(&jobs)
+}
+
+// UpdateRunJob updates the given columns of the job
+func UpdateRunJob(ctx context.Context, job *ActionRunJob, cols ...string) error {
+	_, err := db.GetEngine(ctx).ID(job.ID).Cols(cols...).Update(job)
+	return err
+}
+
+func (job *ActionRunJob) generateToken() error {
+	salt, err := util.CryptoRandomString(10)
+	if err != nil {
+		return err
+	}
+	job.TokenSalt = salt
+	job.Token = base.EncodeSha1(gouuid.New().String())
+	job.TokenHash = auth.HashToken(job.Token, job.TokenSalt)
+	job.TokenLastEight = job.Token[len(job.Token)-8:]
+	return nil
+}
+
+// RevokeToken makes the token of the job invalid, the caller has to update the token columns
+func (job *ActionRunJob) RevokeToken() {
+	job.Token = ""
+	job.TokenHash = ""
+	job.TokenSalt = ""
+	job.TokenLastEight = ""
+}
+
+// GetRunningJobByToken gets the running job which owns the token
+func GetRunningJobByToken(ctx context.Context, token string) (*ActionRunJob, error) {
+	if len(token) != 40 {
+		return nil, ErrRunJobNotExist
+	}
+
+	jobs := make([]*ActionRunJob, 0, 1)
+	if err := db.GetEngine(ctx).
+		Where("token_last_eight = ? AND status = ?", token[len(token)-8:], StatusRunning).
+		Find(&jobs); err != nil {
+		return nil, err
+	}
+	for _, job := range jobs {
+		if job.TokenHash != "" && subtle.ConstantTimeCompare([]byte(job.TokenHash), []byte(auth.HashToken(token, job.TokenSalt))) == 1 {
+			return job, nil
+		}
+	}
+	return nil, ErrRunJobNotExist
+}
+
+// PickRunJob assigns the oldest waiting job the runner is able to execute to the runner
+// If there is no such job, nil is returned.
+func PickRunJob(ctx context.Context, runner *ActionRunner) (*ActionRunJob, error) {
+	candidates := make([]*ActionRunJob, 0, maxPickCandidates)
+	if err := db.GetEngine(ctx).
+		Where("status = ?", StatusWaiting).
+		And(builder.NotIn("run_id", builder.Select("id").From("action_run").Where(builder.Eq{"need_approval": true}))).
+		OrderBy("id").
+		Limit(maxPickCandidates).
+		Find(&candidates); err != nil {
+		return nil, err
+	}
+
+	for _, job := range candidates {
+		if !runner.CanRun(job) {
+			continue
+		}
+
+		job.Status = StatusRunning
+		job.RunnerID = runner.ID
+		job.StartedUnix = timeutil.TimeStampNow()
+		if err := job.generateToken(); err != nil {
+			return nil, err
+		}
+
+		// Another runner may have picked the job in the meantime
+		n, err := db.GetEngine(ctx).
+			Where("id = ? AND status = ?", job.ID, StatusWaiting).
+			Cols("status", "runner_id", "started_unix", "token_hash", "token_salt", "token_last_eight").
+			Update(job)
+		if err != nil {
+			return nil, err
+		}
+		if n == 1 {
+			return job, nil
+		}
+	}
+	return nil, nil
+}
+
+// IncrementLogChunks reserves the next log chunk of the job
+// ok is false if another chunk was written concurrently.
+func IncrementLogChunks(ctx context.Context, job *ActionRunJob) (bool, error) {
+	n, err := db.GetEngine(ctx).
+		Where("id = ? AND log_chunks = ?", job.ID, job.LogChunks).
+		Incr("log_chunks").
+		NoAutoTime().
+		Update(&ActionRunJob{})
+	if err != nil {
+		return false, err
+	}
+	if n == 1 {
+		job.LogChunks++
+	}
+	return n == 1, nil
+}
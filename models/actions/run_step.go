@@ -0,0 +1,40 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package actions
+
+import (
+	"context"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/modules/timeutil"
+)
+
+func init() {
+	db.RegisterModel(new(ActionRunStep))
+}
+
+// ActionRunStep represents a step of a job
+type ActionRunStep struct {
+	ID          int64              `xorm:"pk autoincr"`
+	JobID       int64              `xorm:"UNIQUE(s) NOT NULL"`
+	RepoID      int64              `xorm:"INDEX NOT NULL"`
+	Index       int64              `xorm:"UNIQUE(s) NOT NULL"`
+	Name        string             `xorm:"NOT NULL"`
+	Status      Status             `xorm:"NOT NULL"`
+	StartedUnix timeutil.TimeStamp `xorm:"NOT NULL DEFAULT 0"`
+	StoppedUnix timeutil.TimeStamp `xorm:"NOT NULL DEFAULT 0"`
+}
+
+// GetRunStepsByJobID gets the steps of a job ordered by their index
+func GetRunStepsByJobID(ctx context.Context, jobID int64) ([]*ActionRunStep, error) {
+	steps := make([]*ActionRunStep, 0, 10)
+	return steps, db.GetEngine(ctx).Where("job_id = ?", jobID).OrderBy("`index`").Find(&steps)
+}
+
+// UpdateRunStep updates the given columns of the step
+func UpdateRunStep(ctx context.Context, step *ActionRunStep, cols ...string) error {
+	_, err := db.GetEngine(ctx).ID(step.ID).Cols(cols...).Update(step)
+	return err
+}
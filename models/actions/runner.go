@@ -0,0 +1,127 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package actions
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+
+	"code.gitea.io/gitea/models/auth"
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/timeutil"
+	"code.gitea.io/gitea/modules/util"
+
+	gouuid "github.com/google/uuid"
+)
+
+// ErrRunnerNotExist indicates a runner not exist error
+var ErrRunnerNotExist = errors.New("Runner does not exist")
+
+func init() {
+	db.RegisterModel(new(ActionRunner))
+}
+
+// ActionRunner represents an external runner which executes jobs
+type ActionRunner struct {
+	ID             int64              `xorm:"pk autoincr"`
+	Name           string             `xorm:"NOT NULL"`
+	Labels         []string           `xorm:"TEXT JSON"`
+	Token          string             `xorm:"-"`
+	TokenHash      string             `xorm:"UNIQUE"`
+	TokenSalt      string             `xorm:"NOT NULL"`
+	TokenLastEight string             `xorm:"INDEX token_last_eight"`
+	LastOnlineUnix timeutil.TimeStamp `xorm:"INDEX NOT NULL DEFAULT 0"`
+	CreatedUnix    timeutil.TimeStamp `xorm:"created NOT NULL"`
+	UpdatedUnix    timeutil.TimeStamp `xorm:"updated NOT NULL"`
+}
+
+// CanRun checks if the runner has all labels the job runs on
+func (r *ActionRunner) CanRun(job *ActionRunJob) bool {
+	for _, label := range job.RunsOn {
+		if !util.IsStringInSlice(label, r.Labels, true) {
+			return false
+		}
+	}
+	return true
+}
+
+// CreateRunner creates a runner with a new token
+func CreateRunner(ctx context.Context, r *ActionRunner) error {
+	salt, err := util.CryptoRandomString(10)
+	if err != nil {
+		return err
+	}
+	r.TokenSalt = salt
+	r.Token = base.EncodeSha1(gouuid.New().String())
+	r.TokenHash = auth.HashToken(r.Token, r.TokenSalt)
+	r.TokenLastEight = r.Token[len(r.Token)-8:]
+	return db.Insert(ctx, r)
+}
+
+// GetRunnerByID gets a runner by id
+func GetRunnerByID(ctx context.Context, runnerID int64) (*ActionRunner, error) {
+	r := &ActionRunner{}
+	has, err := db.GetEngine(ctx).ID(runnerID).Get(r)
+	if err != nil {
+		return nil, err
+	}
+	if !has {
+		return nil, ErrRunnerNotExist
+	}
+	return r, nil
+}
+
+// GetRunnerByToken gets the runner which owns the token
+func GetRunnerByToken(ctx context.Context, token string) (*ActionRunner, error) {
+	if len(token) != 40 {
+		return nil, ErrRunnerNotExist
+	}
+
+	runners := make([]*ActionRunner, 0, 1)
+	if err := db.GetEngine(ctx).Where("token_last_eight = ?", token[len(token)-8:]).Find(&runners); err != nil {
+		return nil, err
+	}
+	for _, r := range runners {
+		if subtle.ConstantTimeCompare([]byte(r.TokenHash), []byte(auth.HashToken(token, r.TokenSalt))) == 1 {
+			return r, nil
+		}
+	}
+	return nil, ErrRunnerNotExist
+}
+
+// GetRunners gets all runners
+func GetRunners(ctx context.Context) ([]*ActionRunner, error) {
+	runners := make([]*ActionRunner, 0, 10)
+	return runners, db.GetEngine(ctx).OrderBy("id").Find(&runners)
+}
+
+// UpdateRunnerLastOnline sets the time the runner was last seen to now
+func UpdateRunnerLastOnline(ctx context.Context, r *ActionRunner) error {
+	r.LastOnlineUnix = timeutil.TimeStampNow()
+	_, err := db.GetEngine(ctx).ID(r.ID).Cols("last_online_unix").NoAutoTime().Update(r)
+	return err
+}
+
+// DeleteRunnerByID deletes a runner
+// Jobs which are currently executed by the runner are queued again.
+func DeleteRunnerByID(ctx context.Context, runnerID int64) error {
+	return db.WithTx(func(ctx context.Context) error {
+		n, err := db.GetEngine(ctx).ID(runnerID).Delete(&ActionRunner{})
+		if err != nil {
+			return err
+		}
+		if n == 0 {
+			return ErrRunnerNotExist
+		}
+
+		_, err = db.GetEngine(ctx).
+			Where("runner_id = ? AND status = ?", runnerID, StatusRunning).
+			Cols("runner_id", "status", "started_unix", "token_hash", "token_salt", "token_last_eight").
+			Update(&ActionRunJob{Status: StatusWaiting})
+		return err
+	}, ctx)
+}
@@ -0,0 +1,51 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package actions
+
+// Status represents the status of a run, job or step
+type Status int
+
+// List of statuses
+const (
+	StatusUnknown   Status = iota // 0, the status is not known
+	StatusBlocked                 // 1, the job waits for the jobs it needs
+	StatusWaiting                 // 2, the job waits for a runner
+	StatusRunning                 // 3, the job is executed by a runner
+	StatusSuccess                 // 4
+	StatusFailure                 // 5
+	StatusCancelled               // 6
+	StatusSkipped                 // 7, the job was skipped because a needed job did not succeed
+)
+
+var statusNames = map[Status]string{
+	StatusUnknown:   "unknown",
+	StatusBlocked:   "blocked",
+	StatusWaiting:   "waiting",
+	StatusRunning:   "running",
+	StatusSuccess:   "success",
+	StatusFailure:   "failure",
+	StatusCancelled: "cancelled",
+	StatusSkipped:   "skipped",
+}
+
+// String returns the name of the status
+func (s Status) String() string {
+	return statusNames[s]
+}
+
+// IsDone returns true if the status is final
+func (s Status) IsDone() bool {
+	return s >= StatusSuccess
+}
+
+// StatusFromString returns the status with the given name
+func StatusFromString(name string) (Status, bool) {
+	for s, n := range statusNames {
+		if n == name {
+			return s, true
+		}
+	}
+	return StatusUnknown, false
+}
@@ -0,0 +1,100 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package checks
+
+import (
+	"context"
+
+	"code.gitea.io/gitea/models/db"
+)
+
+// AnnotationLevel represents the severity of an annotation
+type AnnotationLevel string
+
+// The possible levels of an annotation
+const (
+	AnnotationLevelNotice  AnnotationLevel = "notice"
+	AnnotationLevelWarning AnnotationLevel = "warning"
+	AnnotationLevelFailure AnnotationLevel = "failure"
+)
+
+// IsValid returns true if the level is known
+func (l AnnotationLevel) IsValid() bool {
+	switch l {
+	case AnnotationLevelNotice, AnnotationLevelWarning, AnnotationLevelFailure:
+		return true
+	}
+	return false
+}
+
+func init() {
+	db.RegisterModel(new(CheckAnnotation))
+}
+
+// CheckAnnotation flags a range of lines of a file at the head commit of a check run
+type CheckAnnotation struct {
+	ID         int64           `xorm:"pk autoincr"`
+	CheckRunID int64           `xorm:"INDEX NOT NULL"`
+	CheckRun   *CheckRun       `xorm:"-"`
+	RepoID     int64           `xorm:"INDEX NOT NULL"`
+	Path       string          `xorm:"NOT NULL"`
+	StartLine  int             `xorm:"NOT NULL"`
+	EndLine    int             `xorm:"NOT NULL"`
+	Level      AnnotationLevel `xorm:"VARCHAR(20) NOT NULL"`
+	Title      string
+	Message    string `xorm:"TEXT NOT NULL"`
+	RawDetails string `xorm:"TEXT"`
+}
+
+// GetAnnotationsByCheckRunID gets the annotations of a check run
+func GetAnnotationsByCheckRunID(ctx context.Context, checkRunID int64, opts db.ListOptions) ([]*CheckAnnotation, int64, error) {
+	sess := db.GetEngine(ctx).Where("check_run_id = ?", checkRunID).OrderBy("id")
+	if opts.PageSize > 0 {
+		sess = db.SetSessionPagination(sess, &opts)
+	}
+	annotations := make([]*CheckAnnotation, 0, 10)
+	count, err := sess.FindAndCount(&annotations)
+	return annotations, count, err
+}
+
+// GetAnnotationsByCheckRuns gets the annotations of the given check runs with the check run loaded
+func GetAnnotationsByCheckRuns(ctx context.Context, runs []*CheckRun) ([]*CheckAnnotation, error) {
+	annotations := make([]*CheckAnnotation, 0, 10)
+	if len(runs) == 0 {
+		return annotations, nil
+	}
+
+	runMap := make(map[int64]*CheckRun, len(runs))
+	ids := make([]int64, 0, len(runs))
+	for _, run := range runs {
+		runMap[run.ID] = run
+		ids = append(ids, run.ID)
+	}
+
+	if err := db.GetEngine(ctx).In("check_run_id", ids).OrderBy("id").Find(&annotations); err != nil {
+		return nil, err
+	}
+	for _, annotation := range annotations {
+		annotation.CheckRun = runMap[annotation.CheckRunID]
+	}
+	return annotations, nil
+}
+
+// AddAnnotations inserts annotations of a check run and updates its annotation count
+func AddAnnotations(ctx context.Context, run *CheckRun, annotations []*CheckAnnotation) error {
+	if len(annotations) == 0 {
+		return nil
+	}
+	for _, annotation := range annotations {
+		annotation.CheckRunID = run.ID
+		annotation.RepoID = run.RepoID
+	}
+	if err := db.Insert(ctx, annotations); err != nil {
+		return err
+	}
+	run.AnnotationsCount += int64(len(annotations))
+	_, err := db.GetEngine(ctx).ID(run.ID).Incr("annotations_count", len(annotations)).NoAutoTime().Update(new(CheckRun))
+	return err
+}
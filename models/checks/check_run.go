@@ -0,0 +1,164 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package checks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"code.gitea.io/gitea/models/db"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/modules/timeutil"
+)
+
+// ErrCheckRunNotExist indicates a check run not exist error
+var ErrCheckRunNotExist = errors.New("Check run does not exist")
+
+// Status represents the progress of a check run
+type Status string
+
+// The possible statuses of a check run
+const (
+	StatusQueued     Status = "queued"
+	StatusInProgress Status = "in_progress"
+	StatusCompleted  Status = "completed"
+)
+
+// IsValid returns true if the status is known
+func (s Status) IsValid() bool {
+	switch s {
+	case StatusQueued, StatusInProgress, StatusCompleted:
+		return true
+	}
+	return false
+}
+
+// Conclusion represents the final result of a completed check run
+type Conclusion string
+
+// The possible conclusions of a check run
+const (
+	ConclusionNone      Conclusion = ""
+	ConclusionSuccess   Conclusion = "success"
+	ConclusionFailure   Conclusion = "failure"
+	ConclusionNeutral   Conclusion = "neutral"
+	ConclusionCancelled Conclusion = "cancelled"
+	ConclusionSkipped   Conclusion = "skipped"
+	ConclusionTimedOut  Conclusion = "timed_out"
+)
+
+// IsValid returns true if the conclusion is known
+func (c Conclusion) IsValid() bool {
+	switch c {
+	case ConclusionSuccess, ConclusionFailure, ConclusionNeutral, ConclusionCancelled, ConclusionSkipped, ConclusionTimedOut:
+		return true
+	}
+	return false
+}
+
+func init() {
+	db.RegisterModel(new(CheckRun))
+}
+
+// CheckRun represents a single check reported against a commit,
+// e.g. a linter or a test suite
+type CheckRun struct {
+	ID               int64                  `xorm:"pk autoincr"`
+	RepoID           int64                  `xorm:"INDEX(s) NOT NULL"`
+	Repo             *repo_model.Repository `xorm:"-"`
+	HeadSHA          string                 `xorm:"VARCHAR(64) INDEX(s) NOT NULL"`
+	Name             string                 `xorm:"NOT NULL"`
+	Status           Status                 `xorm:"VARCHAR(20) NOT NULL"`
+	Conclusion       Conclusion             `xorm:"VARCHAR(20)"`
+	DetailsURL       string                 `xorm:"TEXT"`
+	ExternalID       string
+	Title            string
+	Summary          string `xorm:"LONGTEXT"`
+	Text             string `xorm:"LONGTEXT"`
+	AnnotationsCount int64  `xorm:"NOT NULL DEFAULT 0"`
+	CreatorID        int64  `xorm:"NOT NULL"`
+
+	StartedUnix   timeutil.TimeStamp `xorm:"NOT NULL DEFAULT 0"`
+	CompletedUnix timeutil.TimeStamp `xorm:"NOT NULL DEFAULT 0"`
+	CreatedUnix   timeutil.TimeStamp `xorm:"created NOT NULL"`
+	UpdatedUnix   timeutil.TimeStamp `xorm:"updated NOT NULL"`
+}
+
+// LoadRepo loads the repository of the check run
+func (run *CheckRun) LoadRepo(ctx context.Context) error {
+	if run.Repo != nil {
+		return nil
+	}
+	repo, err := repo_model.GetRepositoryByIDCtx(ctx, run.RepoID)
+	if err != nil {
+		return err
+	}
+	run.Repo = repo
+	return nil
+}
+
+// HTMLURL returns the url of the page showing the check run
+func (run *CheckRun) HTMLURL() string {
+	return fmt.Sprintf("%s/checks/%d", run.Repo.HTMLURL(), run.ID)
+}
+
+// APIURL returns the api url of the check run
+func (run *CheckRun) APIURL() string {
+	return fmt.Sprintf("%s/check-runs/%d", run.Repo.APIURL(), run.ID)
+}
+
+// CreateCheckRun inserts a check run
+func CreateCheckRun(ctx context.Context, run *CheckRun) error {
+	return db.Insert(ctx, run)
+}
+
+// GetCheckRunByID gets a check run of a repository by id
+func GetCheckRunByID(ctx context.Context, repoID, id int64) (*CheckRun, error) {
+	run := &CheckRun{}
+	has, err := db.GetEngine(ctx).Where("repo_id = ? AND id = ?", repoID, id).Get(run)
+	if err != nil {
+		return nil, err
+	}
+	if !has {
+		return nil, ErrCheckRunNotExist
+	}
+	return run, nil
+}
+
+// GetCheckRunsBySHA gets the check runs reported against a commit, newest first
+func GetCheckRunsBySHA(ctx context.Context, repoID int64, sha string, opts db.ListOptions) ([]*CheckRun, int64, error) {
+	sess := db.GetEngine(ctx).Where("repo_id = ? AND head_sha = ?", repoID, sha).OrderBy("id DESC")
+	if opts.PageSize > 0 {
+		sess = db.SetSessionPagination(sess, &opts)
+	}
+	runs := make([]*CheckRun, 0, 10)
+	count, err := sess.FindAndCount(&runs)
+	return runs, count, err
+}
+
+// GetLatestCheckRunsBySHA gets the most recent check run of every name reported against a commit
+func GetLatestCheckRunsBySHA(ctx context.Context, repoID int64, sha string) ([]*CheckRun, error) {
+	runs, _, err := GetCheckRunsBySHA(ctx, repoID, sha, db.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[string]bool, len(runs))
+	latest := make([]*CheckRun, 0, len(runs))
+	for _, run := range runs {
+		if seen[run.Name] {
+			continue
+		}
+		seen[run.Name] = true
+		latest = append(latest, run)
+	}
+	return latest, nil
+}
+
+// UpdateCheckRun updates the given columns of the check run
+func UpdateCheckRun(ctx context.Context, run *CheckRun, cols ...string) error {
+	_, err := db.GetEngine(ctx).ID(run.ID).Cols(cols...).Update(run)
+	return err
+}
@@ -658,6 +658,23 @@ func (err ErrSHAOrCommitIDNotProvided) Error() string {
 	return "a SHA or commit ID must be proved when updating a file"
 }
 
+// ErrSuggestionNotApplicable represents a "SuggestionNotApplicable" kind of error.
+type ErrSuggestionNotApplicable struct {
+	CommentID int64
+	TreePath  string
+	Line      int64
+}
+
+// IsErrSuggestionNotApplicable checks if an error is a ErrSuggestionNotApplicable.
+func IsErrSuggestionNotApplicable(err error) bool {
+	_, ok := err.(ErrSuggestionNotApplicable)
+	return ok
+}
+
+func (err ErrSuggestionNotApplicable) Error() string {
+	return fmt.Sprintf("suggestion cannot be applied [comment_id: %d, path: %s, line: %d]", err.CommentID, err.TreePath, err.Line)
+}
+
 // .___
 // |   | ______ ________ __   ____
 // |   |/  ___//  ___/  |  \_/ __ \
@@ -867,6 +884,23 @@ func (err ErrMergeUnrelatedHistories) Error() string {
 	return fmt.Sprintf("Merge UnrelatedHistories Error: %v: %s\n%s", err.Err, err.StdErr, err.StdOut)
 }
 
+// ErrMergeDivergingFastForwardOnly represents an error if a fast-forward-only merge fails because the head is not a descendant of the base
+type ErrMergeDivergingFastForwardOnly struct {
+	StdOut string
+	StdErr string
+	Err    error
+}
+
+// IsErrMergeDivergingFastForwardOnly checks if an error is a ErrMergeDivergingFastForwardOnly.
+func IsErrMergeDivergingFastForwardOnly(err error) bool {
+	_, ok := err.(ErrMergeDivergingFastForwardOnly)
+	return ok
+}
+
+func (err ErrMergeDivergingFastForwardOnly) Error() string {
+	return fmt.Sprintf("Merge DivergingFastForwardOnly Error: %v: %s\n%s", err.Err, err.StdErr, err.StdOut)
+}
+
 // ErrRebaseConflicts represents an error if rebase fails with a conflict
 type ErrRebaseConflicts struct {
 	Style     repo_model.MergeStyle
@@ -886,6 +920,21 @@ func (err ErrRebaseConflicts) Error() string {
 	return fmt.Sprintf("Rebase Error: %v: Whilst Rebasing: %s\n%s\n%s", err.Err, err.CommitSHA, err.StdErr, err.StdOut)
 }
 
+// ErrConflictNotResolved represents an error if a conflicted file of a pull request has not been resolved
+type ErrConflictNotResolved struct {
+	TreePath string
+}
+
+// IsErrConflictNotResolved checks if an error is a ErrConflictNotResolved.
+func IsErrConflictNotResolved(err error) bool {
+	_, ok := err.(ErrConflictNotResolved)
+	return ok
+}
+
+func (err ErrConflictNotResolved) Error() string {
+	return fmt.Sprintf("conflict has not been resolved [path: %s]", err.TreePath)
+}
+
 // ErrPullRequestHasMerged represents a "PullRequestHasMerged"-error
 type ErrPullRequestHasMerged struct {
 	ID         int64
@@ -21,6 +21,7 @@ import (
 	"code.gitea.io/gitea/models/perm"
 	access_model "code.gitea.io/gitea/models/perm/access"
 	project_model "code.gitea.io/gitea/models/project"
+	pull_model "code.gitea.io/gitea/models/pull"
 	repo_model "code.gitea.io/gitea/models/repo"
 	"code.gitea.io/gitea/models/unit"
 	user_model "code.gitea.io/gitea/models/user"
@@ -1183,6 +1184,7 @@ type IssuesOptions struct {
 	IncludedLabelNames []string
 	ExcludedLabelNames []string
 	IncludeMilestones  []string
+	CustomFields       map[int64]string // the stored values of custom fields by the IDs of the fields
 	SortType           string
 	IssueIDs           []int64
 	UpdatedAfterUnix   int64
@@ -1348,6 +1350,10 @@ func (opts *IssuesOptions) setupSessionNoLimit(sess *xorm.Session) {
 				Where(builder.In("name", opts.IncludeMilestones)))
 	}
 
+	for fieldID, value := range opts.CustomFields {
+		sess.And(issues_model.CustomFieldValueCond(fieldID, value))
+	}
+
 	if opts.User != nil {
 		sess.And(
 			issuePullAccessibleRepoCond("issue.repo_id", opts.User.ID, opts.Org, opts.Team, opts.IsPull.IsTrue()),
@@ -1877,6 +1883,14 @@ func SearchIssueIDsByKeyword(ctx context.Context, kw string, repoIDs []int64, li
 					builder.Like{"UPPER(content)", kw},
 				)),
 			),
+			builder.In("id", builder.Select("issue_id").
+				From("custom_field_value").
+				Where(builder.And(
+					builder.In("issue_id", subQuery),
+					builder.NotIn("field_id", builder.Select("id").From("custom_field").Where(builder.Eq{"type": issues_model.CustomFieldTypeUser})),
+					builder.Like{"UPPER(value)", kw},
+				)),
+			),
 		),
 	)
 
@@ -2060,6 +2074,14 @@ func deleteIssue(ctx context.Context, issue *Issue) error {
 		admin_model.RemoveStorageWithNotice(ctx, storage.Attachments, "Delete issue attachment", attachments[i].RelativePath())
 	}
 
+	if issue.IsPull {
+		// delete iterations of the pull request before the pull request itself
+		if _, err := e.In("pull_id", builder.Select("id").From("pull_request").Where(builder.Eq{"issue_id": issue.ID})).
+			Delete(&pull_model.Iteration{}); err != nil {
+			return err
+		}
+	}
+
 	// delete all database data still assigned to this issue
 	if err := deleteInIssue(e, issue.ID,
 		&issues_model.ContentHistory{},
@@ -2076,6 +2098,7 @@ func deleteIssue(ctx context.Context, issue *Issue) error {
 		&project_model.ProjectIssue{},
 		&repo_model.Attachment{},
 		&PullRequest{},
+		&issues_model.CustomFieldValue{},
 	); err != nil {
 		return err
 	}
@@ -2411,6 +2434,11 @@ func deleteIssuesByRepoID(sess db.Engine, repoID int64) (attachmentPaths []strin
 		return
 	}
 
+	if _, err = sess.In("issue_id", deleteCond).
+		Delete(&issues_model.CustomFieldValue{}); err != nil {
+		return
+	}
+
 	if _, err = sess.In("dependent_issue_id", deleteCond).
 		Delete(&Comment{}); err != nil {
 		return
@@ -114,6 +114,10 @@ const (
 	CommentTypePRScheduledToAutoMerge
 	// 35 pr was un scheduled to auto merge when checks succeed
 	CommentTypePRUnScheduledToAutoMerge
+	// 36 pr was added to the merge queue
+	CommentTypePRAddedToMergeQueue
+	// 37 pr was removed from the merge queue
+	CommentTypePRRemovedFromMergeQueue
 )
 
 var commentStrings = []string{
@@ -153,6 +157,8 @@ var commentStrings = []string{
 	"change_issue_ref",
 	"pull_scheduled_merge",
 	"pull_cancel_scheduled_merge",
+	"pull_add_merge_queue",
+	"pull_remove_merge_queue",
 }
 
 func (t CommentType) String() string {
@@ -238,6 +244,8 @@ type Comment struct {
 	TreePath        string
 	Content         string `xorm:"LONGTEXT"`
 	RenderedContent string `xorm:"-"`
+	// Suggestion is the change proposed by a code comment, if any
+	Suggestion *CodeSuggestion `xorm:"-"`
 
 	// Path represents the 4 lines of code cemented by this comment
 	Patch       string `xorm:"-"`
@@ -1279,12 +1287,18 @@ func findCodeComments(ctx context.Context, opts FindCommentsOptions, issue *Issu
 			return nil, err
 		}
 
+		// the suggestion is rendered as a diff, so it is left out of the rendered content
+		content := comment.Content
+		if comment.LoadCodeSuggestion(); comment.Suggestion != nil {
+			content = comment.Suggestion.Text
+		}
+
 		var err error
 		if comment.RenderedContent, err = markdown.RenderString(&markup.RenderContext{
 			Ctx:       ctx,
 			URLPrefix: issue.Repo.Link(),
 			Metas:     issue.Repo.ComposeMetas(),
-		}, comment.Content); err != nil {
+		}, content); err != nil {
 			return nil, err
 		}
 	}
@@ -1382,6 +1396,29 @@ func CreateAutoMergeComment(ctx context.Context, typ CommentType, pr *PullReques
 	return
 }
 
+// CreateMergeQueueComment is a internal function, only use it for CommentTypePRAddedToMergeQueue and CommentTypePRRemovedFromMergeQueue CommentTypes
+func CreateMergeQueueComment(ctx context.Context, typ CommentType, pr *PullRequest, doer *user_model.User, reason string) (comment *Comment, err error) {
+	if typ != CommentTypePRAddedToMergeQueue && typ != CommentTypePRRemovedFromMergeQueue {
+		return nil, fmt.Errorf("comment type %d cannot be used to create a merge queue comment", typ)
+	}
+	if err = pr.LoadIssueCtx(ctx); err != nil {
+		return
+	}
+
+	if err = pr.LoadBaseRepoCtx(ctx); err != nil {
+		return
+	}
+
+	comment, err = CreateCommentCtx(ctx, &CreateCommentOptions{
+		Type:    typ,
+		Doer:    doer,
+		Repo:    pr.BaseRepo,
+		Issue:   pr.Issue,
+		Content: reason,
+	})
+	return
+}
+
 // getCommitsFromRepo get commit IDs from repo in between oldCommitID and newCommitID
 // isForcePush will be true if oldCommit isn't on the branch
 // Commit on baseBranch will skip
@@ -9,8 +9,12 @@ import (
 	"fmt"
 
 	"code.gitea.io/gitea/models/db"
+	access_model "code.gitea.io/gitea/models/perm/access"
 	project_model "code.gitea.io/gitea/models/project"
 	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/util"
+
+	"xorm.io/builder"
 )
 
 // LoadProject load the project the issue was assigned to
@@ -60,14 +64,15 @@ func (i *Issue) projectBoardID(e db.Engine) int64 {
 	return ip.ProjectBoardID
 }
 
-// LoadIssuesFromBoard load issues assigned to this board
-func LoadIssuesFromBoard(b *project_model.Board) (IssueList, error) {
+// GetIssuesOfBoard returns the issues assigned to this board in the order of the board
+func GetIssuesOfBoard(b *project_model.Board) (IssueList, error) {
 	issueList := make([]*Issue, 0, 10)
 
 	if b.ID != 0 {
 		issues, err := Issues(&IssuesOptions{
 			ProjectBoardID: b.ID,
 			ProjectID:      b.ProjectID,
+			SortType:       "project-column-sorting",
 		})
 		if err != nil {
 			return nil, err
@@ -79,6 +84,7 @@ func LoadIssuesFromBoard(b *project_model.Board) (IssueList, error) {
 		issues, err := Issues(&IssuesOptions{
 			ProjectBoardID: -1, // Issues without ProjectBoardID
 			ProjectID:      b.ProjectID,
+			SortType:       "project-column-sorting",
 		})
 		if err != nil {
 			return nil, err
@@ -86,26 +92,146 @@ func LoadIssuesFromBoard(b *project_model.Board) (IssueList, error) {
 		issueList = append(issueList, issues...)
 	}
 
-	if err := IssueList(issueList).LoadComments(); err != nil {
+	return issueList, nil
+}
+
+// LoadIssuesFromBoard load issues assigned to this board
+func LoadIssuesFromBoard(b *project_model.Board) (IssueList, error) {
+	issueList, err := GetIssuesOfBoard(b)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := issueList.LoadComments(); err != nil {
 		return nil, err
 	}
 
 	return issueList, nil
 }
 
-// LoadIssuesFromBoardList load issues assigned to the boards
-func LoadIssuesFromBoardList(bs project_model.BoardList) (map[int64]IssueList, error) {
+// LoadIssuesFromBoardList load issues assigned to the boards which the user is allowed to read
+func LoadIssuesFromBoardList(ctx context.Context, bs project_model.BoardList, doer *user_model.User) (map[int64]IssueList, error) {
 	issuesMap := make(map[int64]IssueList, len(bs))
 	for i := range bs {
 		il, err := LoadIssuesFromBoard(bs[i])
 		if err != nil {
 			return nil, err
 		}
+		if il, err = FilterIssuesReadableBy(ctx, il, doer); err != nil {
+			return nil, err
+		}
 		issuesMap[bs[i].ID] = il
 	}
 	return issuesMap, nil
 }
 
+// LoadLinkedPullsOfBoardIssues returns the pull requests referenced in the comments of the issues of the boards
+// which the user is allowed to read, mapped by the ID of the referencing issue
+func LoadLinkedPullsOfBoardIssues(ctx context.Context, issuesMap map[int64]IssueList, doer *user_model.User) (map[int64][]*Issue, error) {
+	linkedPrsMap := make(map[int64][]*Issue)
+	for _, issuesList := range issuesMap {
+		for _, issue := range issuesList {
+			var referencedIds []int64
+			for _, comment := range issue.Comments {
+				if comment.RefIssueID != 0 && comment.RefIsPull {
+					referencedIds = append(referencedIds, comment.RefIssueID)
+				}
+			}
+			if len(referencedIds) == 0 {
+				continue
+			}
+
+			linkedPrs, err := Issues(&IssuesOptions{
+				IssueIDs: referencedIds,
+				IsPull:   util.OptionalBoolTrue,
+			})
+			if err != nil {
+				return nil, err
+			}
+			if linkedPrsMap[issue.ID], err = FilterIssuesReadableBy(ctx, linkedPrs, doer); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return linkedPrsMap, nil
+}
+
+// FilterIssuesReadableBy returns the issues of a project the user is allowed to read, which is not the case
+// for all issues of an individual or organization project because they can belong to any repository of the owner
+func FilterIssuesReadableBy(ctx context.Context, issues IssueList, doer *user_model.User) (IssueList, error) {
+	if _, err := issues.loadRepositories(db.GetEngine(ctx)); err != nil {
+		return nil, err
+	}
+
+	perms := make(map[int64]access_model.Permission)
+	readable := make(IssueList, 0, len(issues))
+	for _, issue := range issues {
+		perm, ok := perms[issue.RepoID]
+		if !ok {
+			var err error
+			if perm, err = access_model.GetUserRepoPermission(ctx, issue.Repo, doer); err != nil {
+				return nil, err
+			}
+			perms[issue.RepoID] = perm
+		}
+		if perm.CanReadIssuesOrPulls(issue.IsPull) {
+			readable = append(readable, issue)
+		}
+	}
+	return readable, nil
+}
+
+// CanBeAddedToProject returns whether an issue can be added to a project: the issue has to belong to the repository
+// of a repository project, or to a repository of the owner of an individual or organization project
+func (i *Issue) CanBeAddedToProject(ctx context.Context, p *project_model.Project) (bool, error) {
+	if err := i.LoadRepo(ctx); err != nil {
+		return false, err
+	}
+	return p.AcceptsIssuesOf(i.Repo), nil
+}
+
+// GetLabelsOfProject returns the labels the issues of a project can have: the labels of the repository of a repository
+// project or of the repositories of the owner of an individual or organization project, and the organization labels
+func GetLabelsOfProject(ctx context.Context, p *project_model.Project) ([]*Label, error) {
+	var cond builder.Cond
+	if p.Type == project_model.TypeRepository {
+		if err := p.LoadAttributes(ctx); err != nil {
+			return nil, err
+		}
+		cond = builder.Eq{"repo_id": p.RepoID}.Or(builder.Eq{"org_id": p.Repo.OwnerID})
+	} else {
+		cond = builder.In("repo_id", builder.Select("id").From("repository").Where(builder.Eq{"owner_id": p.OwnerID})).
+			Or(builder.Eq{"org_id": p.OwnerID})
+	}
+
+	labels := make([]*Label, 0, 10)
+	return labels, db.GetEngine(ctx).Where(cond).Asc("name").Asc("id").Find(&labels)
+}
+
+// NewProjectAutomationRule adds an automation rule to a project, the label of the rule has to be one of the labels
+// the issues of the project can have
+func NewProjectAutomationRule(ctx context.Context, p *project_model.Project, rule *project_model.AutomationRule) error {
+	if rule.LabelID > 0 {
+		labels, err := GetLabelsOfProject(ctx, p)
+		if err != nil {
+			return err
+		}
+		found := false
+		for _, label := range labels {
+			if label.ID == rule.LabelID {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return project_model.ErrInvalidAutomationRule{ProjectID: p.ID, BoardID: rule.BoardID}
+		}
+	}
+
+	rule.ProjectID = p.ID
+	return project_model.NewAutomationRule(ctx, rule)
+}
+
 // ChangeProjectAssign changes the project associated with an issue
 func ChangeProjectAssign(issue *Issue, doer *user_model.User, newProjectID int64) error {
 	ctx, committer, err := db.TxContext()
@@ -146,6 +272,10 @@ func addUpdateIssueProject(ctx context.Context, issue *Issue, doer *user_model.U
 		}
 	}
 
+	if newProjectID == 0 {
+		return nil
+	}
+
 	_, err := e.Insert(&project_model.ProjectIssue{
 		IssueID:   issue.ID,
 		ProjectID: newProjectID,
@@ -369,3 +369,12 @@ func (pr *PullRequest) ResolveCrossReferences(ctx context.Context) ([]*Comment,
 
 	return refs, nil
 }
+
+// ReferencedIssueIDs returns the IDs of the issues referenced by the pull request
+func (pr *PullRequest) ReferencedIssueIDs(ctx context.Context) ([]int64, error) {
+	issueIDs := make([]int64, 0, 5)
+	return issueIDs, db.GetEngine(ctx).Table("comment").
+		Where("ref_repo_id = ? AND ref_issue_id = ? AND ref_action <> ?", pr.Issue.RepoID, pr.Issue.ID, references.XRefActionNeutered).
+		Distinct("issue_id").
+		Find(&issueIDs)
+}
@@ -0,0 +1,413 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package issues
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"code.gitea.io/gitea/models/db"
+	repo_model "code.gitea.io/gitea/models/repo"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/builder"
+)
+
+// CustomFieldType is the type of the values of a custom field
+type CustomFieldType uint8
+
+const (
+	// CustomFieldTypeText is a custom field holding a single line of text
+	CustomFieldTypeText CustomFieldType = iota + 1
+
+	// CustomFieldTypeNumber is a custom field holding a number
+	CustomFieldTypeNumber
+
+	// CustomFieldTypeDate is a custom field holding a date formatted as YYYY-MM-DD
+	CustomFieldTypeDate
+
+	// CustomFieldTypeSelect is a custom field holding one of the options of the field
+	CustomFieldTypeSelect
+
+	// CustomFieldTypeUser is a custom field holding a user
+	CustomFieldTypeUser
+)
+
+// customFieldTypes are the custom field types in the order they are presented to the user
+var customFieldTypes = []CustomFieldType{
+	CustomFieldTypeText,
+	CustomFieldTypeNumber,
+	CustomFieldTypeDate,
+	CustomFieldTypeSelect,
+	CustomFieldTypeUser,
+}
+
+// customFieldTypeNames are the names of the custom field types used by forms and the API
+var customFieldTypeNames = map[CustomFieldType]string{
+	CustomFieldTypeText:   "text",
+	CustomFieldTypeNumber: "number",
+	CustomFieldTypeDate:   "date",
+	CustomFieldTypeSelect: "select",
+	CustomFieldTypeUser:   "user",
+}
+
+// CustomFieldTypes returns all custom field types
+func CustomFieldTypes() []CustomFieldType {
+	return customFieldTypes
+}
+
+// Name returns the name of the custom field type used by forms and the API
+func (t CustomFieldType) Name() string {
+	return customFieldTypeNames[t]
+}
+
+// CustomFieldTypeFromName returns the custom field type with the given name
+func CustomFieldTypeFromName(name string) (CustomFieldType, bool) {
+	for t, n := range customFieldTypeNames {
+		if n == name {
+			return t, true
+		}
+	}
+	return 0, false
+}
+
+// maxCustomFieldValueLength is the maximum length of the value of a custom field
+const maxCustomFieldValueLength = 255
+
+// ErrCustomFieldNotExist represents a "CustomFieldNotExist" kind of error.
+type ErrCustomFieldNotExist struct {
+	ID int64
+}
+
+// IsErrCustomFieldNotExist checks if an error is a ErrCustomFieldNotExist.
+func IsErrCustomFieldNotExist(err error) bool {
+	_, ok := err.(ErrCustomFieldNotExist)
+	return ok
+}
+
+func (err ErrCustomFieldNotExist) Error() string {
+	return fmt.Sprintf("custom field does not exist [id: %d]", err.ID)
+}
+
+// ErrCustomFieldAlreadyExist represents a "CustomFieldAlreadyExist" kind of error.
+type ErrCustomFieldAlreadyExist struct {
+	Name string
+}
+
+// IsErrCustomFieldAlreadyExist checks if an error is a ErrCustomFieldAlreadyExist.
+func IsErrCustomFieldAlreadyExist(err error) bool {
+	_, ok := err.(ErrCustomFieldAlreadyExist)
+	return ok
+}
+
+func (err ErrCustomFieldAlreadyExist) Error() string {
+	return fmt.Sprintf("custom field already exists [name: %s]", err.Name)
+}
+
+// ErrInvalidCustomField represents a custom field without name or a single-select field without options
+type ErrInvalidCustomField struct {
+	Name string
+}
+
+// IsErrInvalidCustomField checks if an error is a ErrInvalidCustomField.
+func IsErrInvalidCustomField(err error) bool {
+	_, ok := err.(ErrInvalidCustomField)
+	return ok
+}
+
+func (err ErrInvalidCustomField) Error() string {
+	return fmt.Sprintf("invalid custom field [name: %s]", err.Name)
+}
+
+// ErrInvalidCustomFieldValue represents a value which does not match the type of its custom field
+type ErrInvalidCustomFieldValue struct {
+	Field string
+	Value string
+}
+
+// IsErrInvalidCustomFieldValue checks if an error is a ErrInvalidCustomFieldValue.
+func IsErrInvalidCustomFieldValue(err error) bool {
+	_, ok := err.(ErrInvalidCustomFieldValue)
+	return ok
+}
+
+func (err ErrInvalidCustomFieldValue) Error() string {
+	return fmt.Sprintf("invalid custom field value [field: %s, value: %s]", err.Field, err.Value)
+}
+
+// CustomField is a typed field the issues of a repository, or of all repositories of an organization, can have
+type CustomField struct {
+	ID          int64           `xorm:"pk autoincr"`
+	RepoID      int64           `xorm:"UNIQUE(s) NOT NULL DEFAULT 0"`
+	OrgID       int64           `xorm:"UNIQUE(s) NOT NULL DEFAULT 0"`
+	Name        string          `xorm:"UNIQUE(s) NOT NULL"`
+	Description string          `xorm:"TEXT"`
+	Type        CustomFieldType `xorm:"NOT NULL"`
+	Options     []string        `xorm:"TEXT JSON"` // the choices of a single-select field
+
+	CreatedUnix timeutil.TimeStamp `xorm:"INDEX created"`
+	UpdatedUnix timeutil.TimeStamp `xorm:"INDEX updated"`
+}
+
+// CustomFieldValue is the value of a custom field of an issue
+type CustomFieldValue struct {
+	ID      int64  `xorm:"pk autoincr"`
+	IssueID int64  `xorm:"UNIQUE(s) NOT NULL"`
+	FieldID int64  `xorm:"UNIQUE(s) INDEX NOT NULL"`
+	Value   string `xorm:"VARCHAR(255) NOT NULL"` // the ID of the user of a user field
+
+	Field *CustomField     `xorm:"-"`
+	User  *user_model.User `xorm:"-"`
+}
+
+func init() {
+	db.RegisterModel(new(CustomField))
+	db.RegisterModel(new(CustomFieldValue))
+}
+
+// BelongsToOrg returns true if the custom field is a field of all repositories of an organization
+func (f *CustomField) BelongsToOrg() bool {
+	return f.OrgID > 0
+}
+
+// AppliesTo returns whether the issues of the repository can have the custom field
+func (f *CustomField) AppliesTo(repo *repo_model.Repository) bool {
+	return f.RepoID == repo.ID || (f.OrgID > 0 && f.OrgID == repo.OwnerID)
+}
+
+// NormalizeValue validates a value of the custom field and returns the value to store: numbers and dates are
+// formatted canonically and users are stored by their ID. An empty value clears the field.
+func (f *CustomField) NormalizeValue(ctx context.Context, value string) (string, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return "", nil
+	}
+
+	invalid := ErrInvalidCustomFieldValue{Field: f.Name, Value: value}
+	switch f.Type {
+	case CustomFieldTypeText:
+		if len(value) > maxCustomFieldValueLength || strings.ContainsAny(value, "\r\n") {
+			return "", invalid
+		}
+		return value, nil
+	case CustomFieldTypeNumber:
+		n, err := strconv.ParseFloat(value, 64)
+		if err != nil || math.IsNaN(n) || math.IsInf(n, 0) {
+			return "", invalid
+		}
+		return strconv.FormatFloat(n, 'f', -1, 64), nil
+	case CustomFieldTypeDate:
+		t, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			return "", invalid
+		}
+		return t.Format("2006-01-02"), nil
+	case CustomFieldTypeSelect:
+		for _, option := range f.Options {
+			if option == value {
+				return value, nil
+			}
+		}
+		return "", invalid
+	case CustomFieldTypeUser:
+		u, err := user_model.GetUserByNameCtx(ctx, value)
+		if err != nil {
+			if user_model.IsErrUserNotExist(err) {
+				return "", invalid
+			}
+			return "", err
+		}
+		return strconv.FormatInt(u.ID, 10), nil
+	}
+	return "", invalid
+}
+
+func (f *CustomField) validate() error {
+	f.Name = strings.TrimSpace(f.Name)
+	if f.Name == "" || f.Type.Name() == "" {
+		return ErrInvalidCustomField{Name: f.Name}
+	}
+
+	if f.Type != CustomFieldTypeSelect {
+		f.Options = nil
+		return nil
+	}
+	options := make([]string, 0, len(f.Options))
+	for _, option := range f.Options {
+		option = strings.TrimSpace(option)
+		if option == "" {
+			continue
+		}
+		if len(option) > maxCustomFieldValueLength {
+			return ErrInvalidCustomField{Name: f.Name}
+		}
+		options = append(options, option)
+	}
+	if len(options) == 0 {
+		return ErrInvalidCustomField{Name: f.Name}
+	}
+	f.Options = options
+	return nil
+}
+
+func (f *CustomField) isNameUsed(ctx context.Context) (bool, error) {
+	return db.GetEngine(ctx).
+		Where("repo_id=? AND org_id=? AND id<>?", f.RepoID, f.OrgID, f.ID).
+		And("name=?", f.Name).
+		Exist(new(CustomField))
+}
+
+// NewCustomField creates a custom field of a repository or an organization
+func NewCustomField(ctx context.Context, f *CustomField) error {
+	if err := f.validate(); err != nil {
+		return err
+	}
+	if used, err := f.isNameUsed(ctx); err != nil {
+		return err
+	} else if used {
+		return ErrCustomFieldAlreadyExist{Name: f.Name}
+	}
+	return db.Insert(ctx, f)
+}
+
+// UpdateCustomField updates the name, the description and the options of a custom field, its type cannot change
+func UpdateCustomField(ctx context.Context, f *CustomField) error {
+	if err := f.validate(); err != nil {
+		return err
+	}
+	if used, err := f.isNameUsed(ctx); err != nil {
+		return err
+	} else if used {
+		return ErrCustomFieldAlreadyExist{Name: f.Name}
+	}
+	_, err := db.GetEngine(ctx).ID(f.ID).Cols("name", "description", "options").Update(f)
+	return err
+}
+
+// DeleteCustomField deletes a custom field and its values
+func DeleteCustomField(ctx context.Context, f *CustomField) error {
+	return db.WithTx(func(ctx context.Context) error {
+		if _, err := db.GetEngine(ctx).Where("field_id=?", f.ID).Delete(new(CustomFieldValue)); err != nil {
+			return err
+		}
+		_, err := db.GetEngine(ctx).ID(f.ID).Delete(new(CustomField))
+		return err
+	}, ctx)
+}
+
+// GetCustomFieldByID returns the custom field with the given ID
+func GetCustomFieldByID(ctx context.Context, id int64) (*CustomField, error) {
+	f := new(CustomField)
+	has, err := db.GetEngine(ctx).ID(id).Get(f)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrCustomFieldNotExist{ID: id}
+	}
+	return f, nil
+}
+
+// GetCustomFieldsByRepoID returns the custom fields defined by a repository
+func GetCustomFieldsByRepoID(ctx context.Context, repoID int64) ([]*CustomField, error) {
+	fields := make([]*CustomField, 0, 5)
+	return fields, db.GetEngine(ctx).Where("repo_id=?", repoID).Asc("name").Find(&fields)
+}
+
+// GetCustomFieldsByOrgID returns the custom fields defined by an organization
+func GetCustomFieldsByOrgID(ctx context.Context, orgID int64) ([]*CustomField, error) {
+	fields := make([]*CustomField, 0, 5)
+	return fields, db.GetEngine(ctx).Where("org_id=?", orgID).Asc("name").Find(&fields)
+}
+
+// GetCustomFieldsOfRepo returns the custom fields the issues of a repository can have: the fields of the
+// organization owning the repository followed by the fields of the repository
+func GetCustomFieldsOfRepo(ctx context.Context, repo *repo_model.Repository) ([]*CustomField, error) {
+	fields := make([]*CustomField, 0, 5)
+	return fields, db.GetEngine(ctx).
+		Where(builder.Eq{"repo_id": repo.ID}.Or(builder.Eq{"org_id": repo.OwnerID}.And(builder.Gt{"org_id": 0}))).
+		Desc("org_id").
+		Asc("name").
+		Find(&fields)
+}
+
+// DisplayValue returns the value of a custom field as it is shown to users, the name of the user of a user field
+func (v *CustomFieldValue) DisplayValue() string {
+	if v.User != nil {
+		return v.User.Name
+	}
+	return v.Value
+}
+
+// GetCustomFieldValues returns the values of the custom fields of an issue with their fields and users loaded
+func GetCustomFieldValues(ctx context.Context, issueID int64) ([]*CustomFieldValue, error) {
+	values := make([]*CustomFieldValue, 0, 5)
+	if err := db.GetEngine(ctx).Where("issue_id=?", issueID).Asc("field_id").Find(&values); err != nil {
+		return nil, err
+	}
+
+	for _, v := range values {
+		var err error
+		if v.Field, err = GetCustomFieldByID(ctx, v.FieldID); err != nil {
+			return nil, err
+		}
+		if v.Field.Type != CustomFieldTypeUser {
+			continue
+		}
+		userID, _ := strconv.ParseInt(v.Value, 10, 64)
+		if v.User, err = user_model.GetUserByIDCtx(ctx, userID); err != nil {
+			if !user_model.IsErrUserNotExist(err) {
+				return nil, err
+			}
+			v.User = user_model.NewGhostUser()
+		}
+	}
+	return values, nil
+}
+
+// SetCustomFieldValue sets the value of a custom field of an issue, an empty value clears the field.
+// It returns whether the stored value has changed.
+func SetCustomFieldValue(ctx context.Context, issueID int64, f *CustomField, value string) (bool, error) {
+	value, err := f.NormalizeValue(ctx, value)
+	if err != nil {
+		return false, err
+	}
+
+	changed := false
+	err = db.WithTx(func(ctx context.Context) error {
+		e := db.GetEngine(ctx)
+		v := &CustomFieldValue{IssueID: issueID, FieldID: f.ID}
+		has, err := e.Get(v)
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case value == "" && has:
+			_, err = e.ID(v.ID).Delete(new(CustomFieldValue))
+		case value == "" || v.Value == value:
+			return nil
+		case has:
+			v.Value = value
+			_, err = e.ID(v.ID).Cols("value").Update(v)
+		default:
+			v.Value = value
+			_, err = e.Insert(v)
+		}
+		changed = err == nil
+		return err
+	}, ctx)
+	return changed, err
+}
+
+// CustomFieldValueCond returns a condition matching the IDs of the issues whose custom field has the stored value
+func CustomFieldValueCond(fieldID int64, value string) builder.Cond {
+	return builder.In("issue.id", builder.Select("issue_id").From("custom_field_value").
+		Where(builder.Eq{"field_id": fieldID, "value": value}))
+}
@@ -0,0 +1,147 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package issues
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/models/db"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/models/unittest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCustomFieldTypeFromName(t *testing.T) {
+	for _, fieldType := range CustomFieldTypes() {
+		parsed, ok := CustomFieldTypeFromName(fieldType.Name())
+		assert.True(t, ok)
+		assert.Equal(t, fieldType, parsed)
+	}
+
+	_, ok := CustomFieldTypeFromName("checkbox")
+	assert.False(t, ok)
+}
+
+func TestCustomField_NormalizeValue(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	test := func(fieldType CustomFieldType, value, expected string) {
+		f := &CustomField{Name: "field", Type: fieldType, Options: []string{"low", "critical"}}
+		normalized, err := f.NormalizeValue(db.DefaultContext, value)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, normalized)
+	}
+	test(CustomFieldTypeText, " ACME Corporation ", "ACME Corporation")
+	test(CustomFieldTypeNumber, "1.50", "1.5")
+	test(CustomFieldTypeDate, "2022-06-01", "2022-06-01")
+	test(CustomFieldTypeSelect, "critical", "critical")
+	test(CustomFieldTypeUser, "user4", "4")
+	test(CustomFieldTypeUser, "", "")
+
+	testInvalid := func(fieldType CustomFieldType, value string) {
+		f := &CustomField{Name: "field", Type: fieldType, Options: []string{"low", "critical"}}
+		_, err := f.NormalizeValue(db.DefaultContext, value)
+		assert.True(t, IsErrInvalidCustomFieldValue(err))
+	}
+	testInvalid(CustomFieldTypeText, "first line\nsecond line")
+	testInvalid(CustomFieldTypeNumber, "one")
+	testInvalid(CustomFieldTypeNumber, "NaN")
+	testInvalid(CustomFieldTypeDate, "01/06/2022")
+	testInvalid(CustomFieldTypeSelect, "medium")
+	testInvalid(CustomFieldTypeUser, "nonexistent")
+}
+
+func TestGetCustomFieldsOfRepo(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	test := func(repoID int64, expectedNames ...string) {
+		repo := unittest.AssertExistsAndLoadBean(t, &repo_model.Repository{ID: repoID}).(*repo_model.Repository)
+		fields, err := GetCustomFieldsOfRepo(db.DefaultContext, repo)
+		assert.NoError(t, err)
+		names := make([]string, 0, len(fields))
+		for _, f := range fields {
+			assert.True(t, f.AppliesTo(repo))
+			names = append(names, f.Name)
+		}
+		assert.Equal(t, expectedNames, names)
+	}
+	test(1, "Reviewer", "Severity")
+	test(3, "Customer")
+	test(2)
+}
+
+func TestNewCustomField(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	f := &CustomField{RepoID: 1, Name: "Affected Version", Type: CustomFieldTypeText, Options: []string{"ignored"}}
+	assert.NoError(t, NewCustomField(db.DefaultContext, f))
+	assert.Nil(t, f.Options)
+	unittest.AssertExistsAndLoadBean(t, &CustomField{ID: f.ID, RepoID: 1, Name: "Affected Version"})
+
+	err := NewCustomField(db.DefaultContext, &CustomField{RepoID: 1, Name: "Severity", Type: CustomFieldTypeText})
+	assert.True(t, IsErrCustomFieldAlreadyExist(err))
+
+	// the fields of a repository and of an organization can have the same name
+	assert.NoError(t, NewCustomField(db.DefaultContext, &CustomField{OrgID: 3, Name: "Severity", Type: CustomFieldTypeText}))
+
+	err = NewCustomField(db.DefaultContext, &CustomField{RepoID: 1, Name: "Priority", Type: CustomFieldTypeSelect, Options: []string{" ", ""}})
+	assert.True(t, IsErrInvalidCustomField(err))
+
+	err = NewCustomField(db.DefaultContext, &CustomField{RepoID: 1, Name: " ", Type: CustomFieldTypeText})
+	assert.True(t, IsErrInvalidCustomField(err))
+}
+
+func TestGetCustomFieldValues(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	values, err := GetCustomFieldValues(db.DefaultContext, 1)
+	assert.NoError(t, err)
+	if assert.Len(t, values, 2) {
+		assert.Equal(t, "Severity", values[0].Field.Name)
+		assert.Equal(t, "critical", values[0].DisplayValue())
+		assert.Equal(t, "Reviewer", values[1].Field.Name)
+		assert.Equal(t, "user4", values[1].DisplayValue())
+	}
+}
+
+func TestSetCustomFieldValue(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	f := unittest.AssertExistsAndLoadBean(t, &CustomField{ID: 1}).(*CustomField)
+
+	changed, err := SetCustomFieldValue(db.DefaultContext, 1, f, "low")
+	assert.NoError(t, err)
+	assert.True(t, changed)
+	unittest.AssertExistsAndLoadBean(t, &CustomFieldValue{IssueID: 1, FieldID: 1, Value: "low"})
+
+	changed, err = SetCustomFieldValue(db.DefaultContext, 1, f, "low")
+	assert.NoError(t, err)
+	assert.False(t, changed)
+
+	_, err = SetCustomFieldValue(db.DefaultContext, 1, f, "medium")
+	assert.True(t, IsErrInvalidCustomFieldValue(err))
+	unittest.AssertExistsAndLoadBean(t, &CustomFieldValue{IssueID: 1, FieldID: 1, Value: "low"})
+
+	changed, err = SetCustomFieldValue(db.DefaultContext, 2, f, "critical")
+	assert.NoError(t, err)
+	assert.True(t, changed)
+	unittest.AssertExistsAndLoadBean(t, &CustomFieldValue{IssueID: 2, FieldID: 1, Value: "critical"})
+
+	changed, err = SetCustomFieldValue(db.DefaultContext, 1, f, "")
+	assert.NoError(t, err)
+	assert.True(t, changed)
+	unittest.AssertNotExistsBean(t, &CustomFieldValue{IssueID: 1, FieldID: 1})
+}
+
+func TestDeleteCustomField(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	f := unittest.AssertExistsAndLoadBean(t, &CustomField{ID: 1}).(*CustomField)
+	assert.NoError(t, DeleteCustomField(db.DefaultContext, f))
+	unittest.AssertNotExistsBean(t, &CustomField{ID: 1})
+	unittest.AssertNotExistsBean(t, &CustomFieldValue{FieldID: 1})
+	unittest.AssertExistsAndLoadBean(t, &CustomFieldValue{FieldID: 3})
+}
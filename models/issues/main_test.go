@@ -25,6 +25,8 @@ func TestMain(m *testing.M) {
 			"user.yml",
 			"repository.yml",
 			"milestone.yml",
+			"custom_field.yml",
+			"custom_field_value.yml",
 		},
 	})
 }
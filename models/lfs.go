@@ -10,6 +10,7 @@ import (
 	"fmt"
 
 	"code.gitea.io/gitea/models/db"
+	quota_model "code.gitea.io/gitea/models/quota"
 	repo_model "code.gitea.io/gitea/models/repo"
 	user_model "code.gitea.io/gitea/models/user"
 	"code.gitea.io/gitea/modules/lfs"
@@ -47,6 +48,17 @@ var ErrLFSObjectNotExist = errors.New("LFS Meta object does not exist")
 // NewLFSMetaObject stores a given populated LFSMetaObject structure in the database
 // if it is not already present.
 func NewLFSMetaObject(m *LFSMetaObject) (*LFSMetaObject, error) {
+	return newLFSMetaObject(m, false, 0)
+}
+
+// NewLFSMetaObjectWithinQuota stores a given populated LFSMetaObject structure in the database like NewLFSMetaObject,
+// but returns quota_model.ErrQuotaExceeded if a new object would exceed the LFS quota of the owner of the repository.
+// The quota is checked in the transaction inserting the object.
+func NewLFSMetaObjectWithinQuota(m *LFSMetaObject, ownerID int64) (*LFSMetaObject, error) {
+	return newLFSMetaObject(m, true, ownerID)
+}
+
+func newLFSMetaObject(m *LFSMetaObject, checkQuota bool, ownerID int64) (*LFSMetaObject, error) {
 	var err error
 
 	ctx, committer, err := db.TxContext()
@@ -65,6 +77,12 @@ func NewLFSMetaObject(m *LFSMetaObject) (*LFSMetaObject, error) {
 		return m, committer.Commit()
 	}
 
+	if checkQuota {
+		if err := quota_model.CheckQuota(ctx, ownerID, quota_model.CategoryLFS, m.Size); err != nil {
+			return nil, err
+		}
+	}
+
 	if err = db.Insert(ctx, m); err != nil {
 		return nil, err
 	}
@@ -387,6 +387,30 @@ var migrations = []Migration{
 	NewMigration("Add auto merge table", addAutoMergeTable),
 	// v215 -> v216
 	NewMigration("allow to view files in PRs", addReviewViewedFiles),
+	// v216 -> v217
+	NewMigration("Add package cleanup rule table", addPackageCleanupRuleTable),
+	// v217 -> v218
+	NewMigration("Add quota table", addQuotaTable),
+	// v218 -> v219
+	NewMigration("Add actions tables", addActionsTables),
+	// v219 -> v220
+	NewMigration("Add check run tables", addCheckRunTables),
+	// v220 -> v221
+	NewMigration("Add merge queue", addMergeQueue),
+	// v221 -> v222
+	NewMigration("Add require code owner approval to protected branch", addRequireCodeOwnerApprovalToProtectedBranch),
+	// v222 -> v223
+	NewMigration("Add pull request iteration table", addPullIterationTable),
+	// v223 -> v224
+	NewMigration("Add owner id to project", addOwnerIDToProject),
+	// v224 -> v225
+	NewMigration("Add project automation rule table", addProjectAutomationRuleTable),
+	// v225 -> v226
+	NewMigration("Add custom field tables", addCustomFieldTables),
+	// v226 -> v227
+	NewMigration("Add approval columns to action run", addApprovalToActionRun),
+	// v227 -> v228
+	NewMigration("Add token columns to action run job", addTokenToActionRunJob),
 }
 
 // GetCurrentDBVersion returns the current db version
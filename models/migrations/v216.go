@@ -0,0 +1,28 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func addPackageCleanupRuleTable(x *xorm.Engine) error {
+	type PackageCleanupRule struct {
+		ID          int64              `xorm:"pk autoincr"`
+		Enabled     bool               `xorm:"INDEX NOT NULL DEFAULT false"`
+		OwnerID     int64              `xorm:"INDEX NOT NULL DEFAULT 0"`
+		Type        string             `xorm:"INDEX NOT NULL"`
+		NamePattern string             `xorm:"NOT NULL DEFAULT ''"`
+		KeepCount   int                `xorm:"NOT NULL DEFAULT 0"`
+		KeepPattern string             `xorm:"NOT NULL DEFAULT ''"`
+		RemoveDays  int                `xorm:"NOT NULL DEFAULT 0"`
+		CreatedUnix timeutil.TimeStamp `xorm:"created NOT NULL DEFAULT 0"`
+		UpdatedUnix timeutil.TimeStamp `xorm:"updated NOT NULL DEFAULT 0"`
+	}
+
+	return x.Sync2(new(PackageCleanupRule))
+}
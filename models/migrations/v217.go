@@ -0,0 +1,27 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func addQuotaTable(x *xorm.Engine) error {
+	type Quota struct {
+		ID               int64              `xorm:"pk autoincr"`
+		OwnerID          int64              `xorm:"UNIQUE NOT NULL"`
+		LimitTotal       int64              `xorm:"NOT NULL DEFAULT -1"`
+		LimitGit         int64              `xorm:"NOT NULL DEFAULT -1"`
+		LimitLFS         int64              `xorm:"NOT NULL DEFAULT -1"`
+		LimitPackages    int64              `xorm:"NOT NULL DEFAULT -1"`
+		LimitAttachments int64              `xorm:"NOT NULL DEFAULT -1"`
+		CreatedUnix      timeutil.TimeStamp `xorm:"created NOT NULL DEFAULT 0"`
+		UpdatedUnix      timeutil.TimeStamp `xorm:"updated NOT NULL DEFAULT 0"`
+	}
+
+	return x.Sync2(new(Quota))
+}
@@ -0,0 +1,73 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func addActionsTables(x *xorm.Engine) error {
+	type ActionRunner struct {
+		ID             int64              `xorm:"pk autoincr"`
+		Name           string             `xorm:"NOT NULL"`
+		Labels         []string           `xorm:"TEXT JSON"`
+		TokenHash      string             `xorm:"UNIQUE"`
+		TokenSalt      string             `xorm:"NOT NULL"`
+		TokenLastEight string             `xorm:"INDEX token_last_eight"`
+		LastOnlineUnix timeutil.TimeStamp `xorm:"INDEX NOT NULL DEFAULT 0"`
+		CreatedUnix    timeutil.TimeStamp `xorm:"created NOT NULL"`
+		UpdatedUnix    timeutil.TimeStamp `xorm:"updated NOT NULL"`
+	}
+
+	type ActionRun struct {
+		ID            int64              `xorm:"pk autoincr"`
+		RepoID        int64              `xorm:"INDEX NOT NULL"`
+		WorkflowID    string             `xorm:"NOT NULL"`
+		Name          string             `xorm:"NOT NULL"`
+		TriggerUserID int64              `xorm:"NOT NULL"`
+		Event         string             `xorm:"NOT NULL"`
+		Ref           string             `xorm:"NOT NULL"`
+		CommitSHA     string             `xorm:"INDEX NOT NULL"`
+		PullRequestID int64              `xorm:"NOT NULL DEFAULT 0"`
+		Status        int                `xorm:"INDEX NOT NULL"`
+		StartedUnix   timeutil.TimeStamp `xorm:"NOT NULL DEFAULT 0"`
+		StoppedUnix   timeutil.TimeStamp `xorm:"NOT NULL DEFAULT 0"`
+		CreatedUnix   timeutil.TimeStamp `xorm:"created INDEX NOT NULL"`
+		UpdatedUnix   timeutil.TimeStamp `xorm:"updated NOT NULL"`
+	}
+
+	type ActionRunJob struct {
+		ID          int64              `xorm:"pk autoincr"`
+		RunID       int64              `xorm:"INDEX NOT NULL"`
+		RepoID      int64              `xorm:"INDEX NOT NULL"`
+		JobID       string             `xorm:"NOT NULL"`
+		Name        string             `xorm:"NOT NULL"`
+		Needs       []string           `xorm:"TEXT JSON"`
+		RunsOn      []string           `xorm:"TEXT JSON"`
+		Payload     string             `xorm:"LONGTEXT"`
+		Status      int                `xorm:"INDEX NOT NULL"`
+		RunnerID    int64              `xorm:"INDEX NOT NULL DEFAULT 0"`
+		LogChunks   int64              `xorm:"NOT NULL DEFAULT 0"`
+		StartedUnix timeutil.TimeStamp `xorm:"NOT NULL DEFAULT 0"`
+		StoppedUnix timeutil.TimeStamp `xorm:"NOT NULL DEFAULT 0"`
+		CreatedUnix timeutil.TimeStamp `xorm:"created NOT NULL"`
+		UpdatedUnix timeutil.TimeStamp `xorm:"updated NOT NULL"`
+	}
+
+	type ActionRunStep struct {
+		ID          int64              `xorm:"pk autoincr"`
+		JobID       int64              `xorm:"UNIQUE(s) NOT NULL"`
+		RepoID      int64              `xorm:"INDEX NOT NULL"`
+		Index       int64              `xorm:"UNIQUE(s) NOT NULL"`
+		Name        string             `xorm:"NOT NULL"`
+		Status      int                `xorm:"NOT NULL"`
+		StartedUnix timeutil.TimeStamp `xorm:"NOT NULL DEFAULT 0"`
+		StoppedUnix timeutil.TimeStamp `xorm:"NOT NULL DEFAULT 0"`
+	}
+
+	return x.Sync2(new(ActionRunner), new(ActionRun), new(ActionRunJob), new(ActionRunStep))
+}
@@ -0,0 +1,49 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func addCheckRunTables(x *xorm.Engine) error {
+	type CheckRun struct {
+		ID               int64  `xorm:"pk autoincr"`
+		RepoID           int64  `xorm:"INDEX(s) NOT NULL"`
+		HeadSHA          string `xorm:"VARCHAR(64) INDEX(s) NOT NULL"`
+		Name             string `xorm:"NOT NULL"`
+		Status           string `xorm:"VARCHAR(20) NOT NULL"`
+		Conclusion       string `xorm:"VARCHAR(20)"`
+		DetailsURL       string `xorm:"TEXT"`
+		ExternalID       string
+		Title            string
+		Summary          string `xorm:"LONGTEXT"`
+		Text             string `xorm:"LONGTEXT"`
+		AnnotationsCount int64  `xorm:"NOT NULL DEFAULT 0"`
+		CreatorID        int64  `xorm:"NOT NULL"`
+
+		StartedUnix   timeutil.TimeStamp `xorm:"NOT NULL DEFAULT 0"`
+		CompletedUnix timeutil.TimeStamp `xorm:"NOT NULL DEFAULT 0"`
+		CreatedUnix   timeutil.TimeStamp `xorm:"created NOT NULL"`
+		UpdatedUnix   timeutil.TimeStamp `xorm:"updated NOT NULL"`
+	}
+
+	type CheckAnnotation struct {
+		ID         int64  `xorm:"pk autoincr"`
+		CheckRunID int64  `xorm:"INDEX NOT NULL"`
+		RepoID     int64  `xorm:"INDEX NOT NULL"`
+		Path       string `xorm:"NOT NULL"`
+		StartLine  int    `xorm:"NOT NULL"`
+		EndLine    int    `xorm:"NOT NULL"`
+		Level      string `xorm:"VARCHAR(20) NOT NULL"`
+		Title      string
+		Message    string `xorm:"TEXT NOT NULL"`
+		RawDetails string `xorm:"TEXT"`
+	}
+
+	return x.Sync2(new(CheckRun), new(CheckAnnotation))
+}
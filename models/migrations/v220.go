@@ -0,0 +1,36 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"xorm.io/xorm"
+)
+
+func addMergeQueue(x *xorm.Engine) error {
+	type ProtectedBranch struct {
+		EnableMergeQueue bool `xorm:"NOT NULL DEFAULT false"`
+	}
+	if err := x.Sync2(new(ProtectedBranch)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+
+	type MergeStyle string
+	type PullMergeQueue struct {
+		ID             int64      `xorm:"pk autoincr"`
+		PullID         int64      `xorm:"UNIQUE"`
+		RepoID         int64      `xorm:"INDEX(s) NOT NULL"`
+		BaseBranch     string     `xorm:"INDEX(s) NOT NULL"`
+		DoerID         int64      `xorm:"NOT NULL"`
+		MergeStyle     MergeStyle `xorm:"varchar(30)"`
+		Message        string     `xorm:"LONGTEXT"`
+		HeadCommitID   string     `xorm:"VARCHAR(40)"`
+		ParentCommitID string     `xorm:"VARCHAR(40)"`
+		CommitID       string     `xorm:"VARCHAR(40)"`
+		CreatedUnix    int64      `xorm:"created"`
+	}
+	return x.Sync2(new(PullMergeQueue))
+}
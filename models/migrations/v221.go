@@ -0,0 +1,16 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"xorm.io/xorm"
+)
+
+func addRequireCodeOwnerApprovalToProtectedBranch(x *xorm.Engine) error {
+	type ProtectedBranch struct {
+		RequireCodeOwnerApproval bool `xorm:"NOT NULL DEFAULT false"`
+	}
+	return x.Sync2(new(ProtectedBranch))
+}
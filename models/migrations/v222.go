@@ -0,0 +1,24 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"xorm.io/xorm"
+)
+
+func addPullIterationTable(x *xorm.Engine) error {
+	type PullIteration struct {
+		ID           int64  `xorm:"pk autoincr"`
+		PullID       int64  `xorm:"NOT NULL UNIQUE(pull_index)"`
+		Index        int64  `xorm:"NOT NULL UNIQUE(pull_index)"`
+		PusherID     int64  `xorm:"NOT NULL"`
+		CommentID    int64  `xorm:"NOT NULL DEFAULT 0"`
+		HeadCommitID string `xorm:"VARCHAR(40)"`
+		MergeBase    string `xorm:"VARCHAR(40)"`
+		IsForcePush  bool   `xorm:"NOT NULL DEFAULT false"`
+		CreatedUnix  int64  `xorm:"created"`
+	}
+	return x.Sync2(new(PullIteration))
+}
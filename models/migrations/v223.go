@@ -0,0 +1,22 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"xorm.io/xorm"
+)
+
+func addOwnerIDToProject(x *xorm.Engine) error {
+	type Project struct {
+		OwnerID int64 `xorm:"INDEX"`
+	}
+	if err := x.Sync2(new(Project)); err != nil {
+		return err
+	}
+
+	// individual (1) and organization (3) projects used to be owned by their creator
+	_, err := x.Exec("UPDATE `project` SET owner_id = creator_id WHERE `type` IN (1, 3) AND owner_id = 0")
+	return err
+}
@@ -0,0 +1,24 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func addProjectAutomationRuleTable(x *xorm.Engine) error {
+	type ProjectAutomationRule struct {
+		ID          int64              `xorm:"pk autoincr"`
+		ProjectID   int64              `xorm:"INDEX NOT NULL"`
+		TriggerType uint8              `xorm:"NOT NULL"`
+		LabelID     int64              `xorm:"INDEX NOT NULL DEFAULT 0"`
+		BoardID     int64              `xorm:"NOT NULL"`
+		CreatorID   int64              `xorm:"NOT NULL"`
+		CreatedUnix timeutil.TimeStamp `xorm:"INDEX created"`
+	}
+	return x.Sync2(new(ProjectAutomationRule))
+}
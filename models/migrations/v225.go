@@ -0,0 +1,34 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func addCustomFieldTables(x *xorm.Engine) error {
+	type CustomField struct {
+		ID          int64              `xorm:"pk autoincr"`
+		RepoID      int64              `xorm:"UNIQUE(s) NOT NULL DEFAULT 0"`
+		OrgID       int64              `xorm:"UNIQUE(s) NOT NULL DEFAULT 0"`
+		Name        string             `xorm:"UNIQUE(s) NOT NULL"`
+		Description string             `xorm:"TEXT"`
+		Type        uint8              `xorm:"NOT NULL"`
+		Options     []string           `xorm:"TEXT JSON"`
+		CreatedUnix timeutil.TimeStamp `xorm:"INDEX created"`
+		UpdatedUnix timeutil.TimeStamp `xorm:"INDEX updated"`
+	}
+
+	type CustomFieldValue struct {
+		ID      int64  `xorm:"pk autoincr"`
+		IssueID int64  `xorm:"UNIQUE(s) NOT NULL"`
+		FieldID int64  `xorm:"UNIQUE(s) INDEX NOT NULL"`
+		Value   string `xorm:"VARCHAR(255) NOT NULL"`
+	}
+
+	return x.Sync2(new(CustomField), new(CustomFieldValue))
+}
@@ -0,0 +1,17 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"xorm.io/xorm"
+)
+
+func addApprovalToActionRun(x *xorm.Engine) error {
+	type ActionRun struct {
+		NeedApproval bool  `xorm:"INDEX NOT NULL DEFAULT false"`
+		ApprovedBy   int64 `xorm:"NOT NULL DEFAULT 0"`
+	}
+	return x.Sync2(new(ActionRun))
+}
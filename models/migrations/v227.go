@@ -0,0 +1,18 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"xorm.io/xorm"
+)
+
+func addTokenToActionRunJob(x *xorm.Engine) error {
+	type ActionRunJob struct {
+		TokenHash      string
+		TokenSalt      string
+		TokenLastEight string `xorm:"INDEX token_last_eight"`
+	}
+	return x.Sync2(new(ActionRunJob))
+}
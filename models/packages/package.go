@@ -31,29 +31,75 @@ type Type string
 
 // List of supported packages
 const (
+	TypeAlpine    Type = "alpine"
+	TypeCargo     Type = "cargo"
 	TypeComposer  Type = "composer"
 	TypeConan     Type = "conan"
+	TypeConda     Type = "conda"
 	TypeContainer Type = "container"
+	TypeDebian    Type = "debian"
 	TypeGeneric   Type = "generic"
+	TypeGo        Type = "go"
 	TypeHelm      Type = "helm"
 	TypeMaven     Type = "maven"
 	TypeNpm       Type = "npm"
 	TypeNuGet     Type = "nuget"
 	TypePyPI      Type = "pypi"
+	TypeRPM       Type = "rpm"
 	TypeRubyGems  Type = "rubygems"
 )
 
+// TypeList contains all supported package types
+var TypeList = []Type{
+	TypeAlpine,
+	TypeCargo,
+	TypeComposer,
+	TypeConan,
+	TypeConda,
+	TypeContainer,
+	TypeDebian,
+	TypeGeneric,
+	TypeGo,
+	TypeHelm,
+	TypeMaven,
+	TypeNpm,
+	TypeNuGet,
+	TypePyPI,
+	TypeRPM,
+	TypeRubyGems,
+}
+
+// IsValidType checks if the package type is supported
+func IsValidType(pt Type) bool {
+	for _, t := range TypeList {
+		if t == pt {
+			return true
+		}
+	}
+	return false
+}
+
 // Name gets the name of the package type
 func (pt Type) Name() string {
 	switch pt {
+	case TypeAlpine:
+		return "Alpine"
+	case TypeCargo:
+		return "Cargo"
 	case TypeComposer:
 		return "Composer"
 	case TypeConan:
 		return "Conan"
+	case TypeConda:
+		return "Conda"
 	case TypeContainer:
 		return "Container"
+	case TypeDebian:
+		return "Debian"
 	case TypeGeneric:
 		return "Generic"
+	case TypeGo:
+		return "Go"
 	case TypeHelm:
 		return "Helm"
 	case TypeMaven:
@@ -64,6 +110,8 @@ func (pt Type) Name() string {
 		return "NuGet"
 	case TypePyPI:
 		return "PyPI"
+	case TypeRPM:
+		return "RPM"
 	case TypeRubyGems:
 		return "RubyGems"
 	}
@@ -73,14 +121,24 @@ func (pt Type) Name() string {
 // SVGName gets the name of the package type svg image
 func (pt Type) SVGName() string {
 	switch pt {
+	case TypeAlpine:
+		return "octicon-package"
+	case TypeCargo:
+		return "octicon-package"
 	case TypeComposer:
 		return "gitea-composer"
 	case TypeConan:
 		return "gitea-conan"
+	case TypeConda:
+		return "octicon-package"
 	case TypeContainer:
 		return "octicon-container"
+	case TypeDebian:
+		return "octicon-package"
 	case TypeGeneric:
 		return "octicon-package"
+	case TypeGo:
+		return "octicon-package"
 	case TypeHelm:
 		return "gitea-helm"
 	case TypeMaven:
@@ -91,6 +149,8 @@ func (pt Type) SVGName() string {
 		return "gitea-nuget"
 	case TypePyPI:
 		return "gitea-python"
+	case TypeRPM:
+		return "octicon-package"
 	case TypeRubyGems:
 		return "gitea-rubygems"
 	}
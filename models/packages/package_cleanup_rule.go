@@ -0,0 +1,125 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package packages
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/builder"
+)
+
+// ErrPackageCleanupRuleNotExist indicates a package cleanup rule not exist error
+var ErrPackageCleanupRuleNotExist = errors.New("Package cleanup rule does not exist")
+
+func init() {
+	db.RegisterModel(new(PackageCleanupRule))
+}
+
+// PackageCleanupRule represents a rule which removes matching package versions of an owner
+type PackageCleanupRule struct {
+	ID                 int64              `xorm:"pk autoincr"`
+	Enabled            bool               `xorm:"INDEX NOT NULL DEFAULT false"`
+	OwnerID            int64              `xorm:"INDEX NOT NULL DEFAULT 0"`
+	Type               Type               `xorm:"INDEX NOT NULL"`
+	NamePattern        string             `xorm:"NOT NULL DEFAULT ''"`
+	NamePatternMatcher *regexp.Regexp     `xorm:"-"`
+	KeepCount          int                `xorm:"NOT NULL DEFAULT 0"`
+	KeepPattern        string             `xorm:"NOT NULL DEFAULT ''"`
+	KeepPatternMatcher *regexp.Regexp     `xorm:"-"`
+	RemoveDays         int                `xorm:"NOT NULL DEFAULT 0"`
+	CreatedUnix        timeutil.TimeStamp `xorm:"created NOT NULL DEFAULT 0"`
+	UpdatedUnix        timeutil.TimeStamp `xorm:"updated NOT NULL DEFAULT 0"`
+}
+
+// CompilePatterns compiles the name and keep patterns of the rule
+// The patterns must match the whole package name or version and are case insensitive.
+func (pcr *PackageCleanupRule) CompilePatterns() error {
+	var err error
+
+	pcr.NamePatternMatcher = nil
+	if pcr.NamePattern != "" {
+		pcr.NamePatternMatcher, err = regexp.Compile(fmt.Sprintf(`(?i)\A(?:%s)\z`, pcr.NamePattern))
+		if err != nil {
+			return err
+		}
+	}
+
+	pcr.KeepPatternMatcher = nil
+	if pcr.KeepPattern != "" {
+		pcr.KeepPatternMatcher, err = regexp.Compile(fmt.Sprintf(`(?i)\A(?:%s)\z`, pcr.KeepPattern))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// InsertCleanupRule inserts a cleanup rule
+func InsertCleanupRule(ctx context.Context, pcr *PackageCleanupRule) (*PackageCleanupRule, error) {
+	return pcr, db.Insert(ctx, pcr)
+}
+
+// GetCleanupRuleByID gets a cleanup rule by id
+func GetCleanupRuleByID(ctx context.Context, id int64) (*PackageCleanupRule, error) {
+	pcr := &PackageCleanupRule{}
+
+	has, err := db.GetEngine(ctx).ID(id).Get(pcr)
+	if err != nil {
+		return nil, err
+	}
+	if !has {
+		return nil, ErrPackageCleanupRuleNotExist
+	}
+	return pcr, nil
+}
+
+// GetCleanupRuleByOwnerAndID gets a cleanup rule of the owner by id
+func GetCleanupRuleByOwnerAndID(ctx context.Context, ownerID, id int64) (*PackageCleanupRule, error) {
+	pcr, err := GetCleanupRuleByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if pcr.OwnerID != ownerID {
+		return nil, ErrPackageCleanupRuleNotExist
+	}
+	return pcr, nil
+}
+
+// UpdateCleanupRule updates a cleanup rule
+func UpdateCleanupRule(ctx context.Context, pcr *PackageCleanupRule) error {
+	_, err := db.GetEngine(ctx).ID(pcr.ID).AllCols().Update(pcr)
+	return err
+}
+
+// GetCleanupRulesByOwner gets all cleanup rules of an owner
+func GetCleanupRulesByOwner(ctx context.Context, ownerID int64) ([]*PackageCleanupRule, error) {
+	pcrs := make([]*PackageCleanupRule, 0, 10)
+	return pcrs, db.GetEngine(ctx).Where("owner_id = ?", ownerID).Asc("id").Find(&pcrs)
+}
+
+// DeleteCleanupRuleByID deletes a cleanup rule by id
+func DeleteCleanupRuleByID(ctx context.Context, ruleID int64) error {
+	_, err := db.GetEngine(ctx).ID(ruleID).Delete(&PackageCleanupRule{})
+	return err
+}
+
+// DeleteCleanupRulesByOwner deletes all cleanup rules of an owner
+func DeleteCleanupRulesByOwner(ctx context.Context, ownerID int64) error {
+	_, err := db.GetEngine(ctx).Where("owner_id = ?", ownerID).Delete(&PackageCleanupRule{})
+	return err
+}
+
+// GetEnabledCleanupRules gets all enabled cleanup rules
+func GetEnabledCleanupRules(ctx context.Context) ([]*PackageCleanupRule, error) {
+	pcrs := make([]*PackageCleanupRule, 0, 10)
+	return pcrs, db.GetEngine(ctx).Where(builder.Eq{"enabled": true}).Asc("id").Find(&pcrs)
+}
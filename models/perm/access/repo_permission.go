@@ -438,3 +438,25 @@ func IsRepoReader(ctx context.Context, repo *repo_model.Repository, userID int64
 	}
 	return db.GetEngine(ctx).Where("repo_id = ? AND user_id = ? AND mode >= ?", repo.ID, userID, perm_model.AccessModeRead).Get(&Access{})
 }
+
+// CanWriteUnitOfAnyOwnerRepo returns true if user has write access to the unit of any repository of the owner.
+func CanWriteUnitOfAnyOwnerRepo(ctx context.Context, user *user_model.User, ownerID int64, unitType unit.Type) (bool, error) {
+	repos := make([]*repo_model.Repository, 0, 10)
+	if err := db.GetEngine(ctx).
+		Join("INNER", "access", "access.repo_id = repository.id").
+		Where("access.user_id = ? AND access.mode >= ? AND repository.owner_id = ?", user.ID, perm_model.AccessModeWrite, ownerID).
+		Find(&repos); err != nil {
+		return false, err
+	}
+
+	for _, repo := range repos {
+		perm, err := GetUserRepoPermission(ctx, repo, user)
+		if err != nil {
+			return false, err
+		}
+		if perm.CanWrite(unitType) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
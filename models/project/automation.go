@@ -0,0 +1,164 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package project
+
+import (
+	"context"
+	"fmt"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/builder"
+)
+
+// AutomationTrigger is the event which makes an automation rule of a project move an issue
+type AutomationTrigger uint8
+
+const (
+	// AutomationTriggerIssueClosed moves an issue of the project when it is closed
+	AutomationTriggerIssueClosed AutomationTrigger = iota + 1
+
+	// AutomationTriggerIssueReopened moves an issue of the project when it is reopened
+	AutomationTriggerIssueReopened
+
+	// AutomationTriggerPullOpened moves the issues of the project referenced by a newly opened pull request
+	AutomationTriggerPullOpened
+
+	// AutomationTriggerIssueLabeled adds an issue to the project when it gets the label of the rule
+	AutomationTriggerIssueLabeled
+)
+
+// automationTriggers are the automation triggers in the order they are presented to the user
+var automationTriggers = []AutomationTrigger{
+	AutomationTriggerIssueClosed,
+	AutomationTriggerIssueReopened,
+	AutomationTriggerPullOpened,
+	AutomationTriggerIssueLabeled,
+}
+
+// automationTriggerNames are the names of the automation triggers used by forms
+var automationTriggerNames = map[AutomationTrigger]string{
+	AutomationTriggerIssueClosed:   "issue_closed",
+	AutomationTriggerIssueReopened: "issue_reopened",
+	AutomationTriggerPullOpened:    "pull_opened",
+	AutomationTriggerIssueLabeled:  "issue_labeled",
+}
+
+// AutomationTriggers returns all automation triggers
+func AutomationTriggers() []AutomationTrigger {
+	return automationTriggers
+}
+
+// Name returns the name of the automation trigger used by forms
+func (t AutomationTrigger) Name() string {
+	return automationTriggerNames[t]
+}
+
+// AutomationTriggerFromName returns the automation trigger with the given name
+func AutomationTriggerFromName(name string) (AutomationTrigger, bool) {
+	for t, n := range automationTriggerNames {
+		if n == name {
+			return t, true
+		}
+	}
+	return 0, false
+}
+
+// ErrInvalidAutomationRule represents an automation rule whose board is not a board of its project
+// or whose trigger needs a label
+type ErrInvalidAutomationRule struct {
+	ProjectID int64
+	BoardID   int64
+}
+
+// IsErrInvalidAutomationRule checks if an error is a ErrInvalidAutomationRule
+func IsErrInvalidAutomationRule(err error) bool {
+	_, ok := err.(ErrInvalidAutomationRule)
+	return ok
+}
+
+func (err ErrInvalidAutomationRule) Error() string {
+	return fmt.Sprintf("invalid automation rule [project_id: %d, board_id: %d]", err.ProjectID, err.BoardID)
+}
+
+// AutomationRule moves the issues of a project to a board of the project when its trigger happens
+type AutomationRule struct {
+	ID          int64             `xorm:"pk autoincr"`
+	ProjectID   int64             `xorm:"INDEX NOT NULL"`
+	TriggerType AutomationTrigger `xorm:"NOT NULL"`
+	LabelID     int64             `xorm:"INDEX NOT NULL DEFAULT 0"` // only used by AutomationTriggerIssueLabeled
+	BoardID     int64             `xorm:"NOT NULL"`
+	CreatorID   int64             `xorm:"NOT NULL"`
+
+	CreatedUnix timeutil.TimeStamp `xorm:"INDEX created"`
+}
+
+// TableName return the real table name
+func (AutomationRule) TableName() string {
+	return "project_automation_rule"
+}
+
+func init() {
+	db.RegisterModel(new(AutomationRule))
+}
+
+// NewAutomationRule adds an automation rule to a project, the board of the rule has to belong to the project
+func NewAutomationRule(ctx context.Context, rule *AutomationRule) error {
+	e := db.GetEngine(ctx)
+	board, err := getBoard(e, rule.BoardID)
+	if err != nil {
+		if IsErrProjectBoardNotExist(err) {
+			return ErrInvalidAutomationRule{ProjectID: rule.ProjectID, BoardID: rule.BoardID}
+		}
+		return err
+	}
+	if board.ProjectID != rule.ProjectID || (rule.TriggerType == AutomationTriggerIssueLabeled) != (rule.LabelID > 0) {
+		return ErrInvalidAutomationRule{ProjectID: rule.ProjectID, BoardID: rule.BoardID}
+	}
+
+	_, err = e.Insert(rule)
+	return err
+}
+
+// GetAutomationRules returns the automation rules of a project
+func GetAutomationRules(ctx context.Context, projectID int64) ([]*AutomationRule, error) {
+	rules := make([]*AutomationRule, 0, 5)
+	return rules, db.GetEngine(ctx).Where("project_id=?", projectID).Asc("id").Find(&rules)
+}
+
+// GetAutomationRulesByTrigger returns the automation rules of a project with the given trigger
+func GetAutomationRulesByTrigger(ctx context.Context, projectID int64, trigger AutomationTrigger) ([]*AutomationRule, error) {
+	rules := make([]*AutomationRule, 0, 1)
+	return rules, db.GetEngine(ctx).Where("project_id=? AND trigger_type=?", projectID, trigger).Asc("id").Find(&rules)
+}
+
+// GetLabelAutomationRules returns the automation rules of all projects which are triggered by one of the labels
+func GetLabelAutomationRules(ctx context.Context, labelIDs []int64) ([]*AutomationRule, error) {
+	rules := make([]*AutomationRule, 0, 1)
+	if len(labelIDs) == 0 {
+		return rules, nil
+	}
+	return rules, db.GetEngine(ctx).
+		Where(builder.Eq{"trigger_type": AutomationTriggerIssueLabeled}.And(builder.In("label_id", labelIDs))).
+		Asc("id").
+		Find(&rules)
+}
+
+// DeleteAutomationRule deletes an automation rule of a project
+func DeleteAutomationRule(ctx context.Context, projectID, ruleID int64) error {
+	_, err := db.GetEngine(ctx).Where("id=? AND project_id=?", ruleID, projectID).Delete(&AutomationRule{})
+	return err
+}
+
+func deleteAutomationRulesByProjectID(e db.Engine, projectID int64) error {
+	_, err := e.Where("project_id=?", projectID).Delete(&AutomationRule{})
+	return err
+}
+
+func deleteAutomationRulesByBoardID(e db.Engine, boardID int64) error {
+	_, err := e.Where("board_id=?", boardID).Delete(&AutomationRule{})
+	return err
+}
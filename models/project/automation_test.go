@@ -0,0 +1,67 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package project
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/unittest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAutomationTriggerFromName(t *testing.T) {
+	for _, trigger := range AutomationTriggers() {
+		tr, ok := AutomationTriggerFromName(trigger.Name())
+		assert.True(t, ok)
+		assert.Equal(t, trigger, tr)
+	}
+
+	_, ok := AutomationTriggerFromName("unknown")
+	assert.False(t, ok)
+}
+
+func TestAutomationRule(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	rule := &AutomationRule{ProjectID: 1, TriggerType: AutomationTriggerIssueClosed, BoardID: 3, CreatorID: 2}
+	assert.NoError(t, NewAutomationRule(db.DefaultContext, rule))
+	assert.NoError(t, NewAutomationRule(db.DefaultContext, &AutomationRule{ProjectID: 4, TriggerType: AutomationTriggerIssueLabeled, LabelID: 3, BoardID: 4, CreatorID: 2}))
+
+	// the board has to belong to the project
+	err := NewAutomationRule(db.DefaultContext, &AutomationRule{ProjectID: 1, TriggerType: AutomationTriggerIssueClosed, BoardID: 4, CreatorID: 2})
+	assert.True(t, IsErrInvalidAutomationRule(err))
+	err = NewAutomationRule(db.DefaultContext, &AutomationRule{ProjectID: 1, TriggerType: AutomationTriggerIssueClosed, BoardID: 100, CreatorID: 2})
+	assert.True(t, IsErrInvalidAutomationRule(err))
+
+	// only label rules have a label
+	err = NewAutomationRule(db.DefaultContext, &AutomationRule{ProjectID: 1, TriggerType: AutomationTriggerIssueLabeled, BoardID: 3, CreatorID: 2})
+	assert.True(t, IsErrInvalidAutomationRule(err))
+	err = NewAutomationRule(db.DefaultContext, &AutomationRule{ProjectID: 1, TriggerType: AutomationTriggerIssueClosed, LabelID: 1, BoardID: 3, CreatorID: 2})
+	assert.True(t, IsErrInvalidAutomationRule(err))
+
+	rules, err := GetAutomationRulesByTrigger(db.DefaultContext, 1, AutomationTriggerIssueClosed)
+	assert.NoError(t, err)
+	if assert.Len(t, rules, 1) {
+		assert.Equal(t, rule.ID, rules[0].ID)
+	}
+	rules, err = GetAutomationRulesByTrigger(db.DefaultContext, 1, AutomationTriggerIssueReopened)
+	assert.NoError(t, err)
+	assert.Len(t, rules, 0)
+
+	rules, err = GetLabelAutomationRules(db.DefaultContext, []int64{1, 3})
+	assert.NoError(t, err)
+	if assert.Len(t, rules, 1) {
+		assert.EqualValues(t, 4, rules[0].ProjectID)
+	}
+
+	// the rules of a board are deleted with the board
+	assert.NoError(t, DeleteBoardByID(3))
+	unittest.AssertNotExistsBean(t, &AutomationRule{ID: rule.ID})
+
+	assert.NoError(t, DeleteProjectByID(4))
+	unittest.AssertNotExistsBean(t, &AutomationRule{ProjectID: 4})
+}
@@ -35,6 +35,31 @@ const (
 	BoardTypeBugTriage
 )
 
+// boardTypeNames are the names of the board types used by the API
+var boardTypeNames = map[BoardType]string{
+	BoardTypeNone:        "none",
+	BoardTypeBasicKanban: "basic_kanban",
+	BoardTypeBugTriage:   "bug_triage",
+}
+
+// Name returns the name of the board type used by the API
+func (bt BoardType) Name() string {
+	return boardTypeNames[bt]
+}
+
+// BoardTypeFromName returns the board type with the given name, an empty name is BoardTypeNone
+func BoardTypeFromName(name string) (BoardType, bool) {
+	if name == "" {
+		return BoardTypeNone, true
+	}
+	for bt, n := range boardTypeNames {
+		if n == name {
+			return bt, true
+		}
+	}
+	return BoardTypeNone, false
+}
+
 // BoardColorPattern is a regexp witch can validate BoardColor
 var BoardColorPattern = regexp.MustCompile("^#[0-9a-fA-F]{6}$")
 
@@ -161,6 +186,10 @@ func deleteBoardByID(ctx context.Context, boardID int64) error {
 		return err
 	}
 
+	if err = deleteAutomationRulesByBoardID(e, board.ID); err != nil {
+		return err
+	}
+
 	if _, err := e.ID(board.ID).Delete(board); err != nil {
 		return err
 	}
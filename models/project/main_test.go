@@ -18,9 +18,11 @@ func TestMain(m *testing.M) {
 		GiteaRootPath: filepath.Join("..", ".."),
 		FixtureFiles: []string{
 			"project.yml",
+			"project_automation_rule.yml",
 			"project_board.yml",
 			"project_issue.yml",
 			"repository.yml",
+			"user.yml",
 		},
 	})
 }
@@ -10,6 +10,8 @@ import (
 	"fmt"
 
 	"code.gitea.io/gitea/models/db"
+	repo_model "code.gitea.io/gitea/models/repo"
+	user_model "code.gitea.io/gitea/models/user"
 	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/timeutil"
 	"code.gitea.io/gitea/modules/util"
@@ -39,6 +41,19 @@ const (
 	TypeOrganization
 )
 
+// Name returns the name of the project type used by the API
+func (t Type) Name() string {
+	switch t {
+	case TypeIndividual:
+		return "individual"
+	case TypeRepository:
+		return "repository"
+	case TypeOrganization:
+		return "organization"
+	}
+	return ""
+}
+
 // ErrProjectNotExist represents a "ProjectNotExist" kind of error.
 type ErrProjectNotExist struct {
 	ID     int64
@@ -76,12 +91,16 @@ type Project struct {
 	Title       string `xorm:"INDEX NOT NULL"`
 	Description string `xorm:"TEXT"`
 	RepoID      int64  `xorm:"INDEX"`
+	OwnerID     int64  `xorm:"INDEX"` // the user or organization of an individual or organization project
 	CreatorID   int64  `xorm:"NOT NULL"`
 	IsClosed    bool   `xorm:"INDEX"`
 	BoardType   BoardType
 	Type        Type
 
-	RenderedContent string `xorm:"-"`
+	RenderedContent string                 `xorm:"-"`
+	Repo            *repo_model.Repository `xorm:"-"`
+	Owner           *user_model.User       `xorm:"-"`
+	Creator         *user_model.User       `xorm:"-"`
 
 	CreatedUnix    timeutil.TimeStamp `xorm:"INDEX created"`
 	UpdatedUnix    timeutil.TimeStamp `xorm:"INDEX updated"`
@@ -92,6 +111,52 @@ func init() {
 	db.RegisterModel(new(Project))
 }
 
+// LoadAttributes loads the repository or the owner and the creator of a project
+func (p *Project) LoadAttributes(ctx context.Context) (err error) {
+	if p.RepoID > 0 && p.Repo == nil {
+		if p.Repo, err = repo_model.GetRepositoryByIDCtx(ctx, p.RepoID); err != nil {
+			return err
+		}
+	}
+	if p.OwnerID > 0 && p.Owner == nil {
+		if p.Owner, err = user_model.GetUserByIDCtx(ctx, p.OwnerID); err != nil {
+			return err
+		}
+	}
+	if p.Creator == nil {
+		if p.Creator, err = user_model.GetUserByIDCtx(ctx, p.CreatorID); err != nil {
+			if !user_model.IsErrUserNotExist(err) {
+				return err
+			}
+			p.Creator = user_model.NewGhostUser()
+		}
+	}
+	return nil
+}
+
+// Link returns the link to the project, the repository or the owner of the project has to be loaded
+func (p *Project) Link() string {
+	if p.Type == TypeRepository {
+		if p.Repo == nil {
+			return ""
+		}
+		return fmt.Sprintf("%s/projects/%d", p.Repo.Link(), p.ID)
+	}
+	if p.Owner == nil {
+		return ""
+	}
+	return fmt.Sprintf("%s/-/projects/%d", p.Owner.HomeLink(), p.ID)
+}
+
+// AcceptsIssuesOf returns whether issues of the repository can be added to the project: a repository project only
+// holds issues of its repository, an individual or organization project those of all repositories of its owner
+func (p *Project) AcceptsIssuesOf(repo *repo_model.Repository) bool {
+	if p.Type == TypeRepository {
+		return p.RepoID == repo.ID
+	}
+	return p.OwnerID == repo.OwnerID
+}
+
 // GetProjectsConfig retrieves the types of configurations projects could have
 func GetProjectsConfig() []ProjectsConfig {
 	return []ProjectsConfig{
@@ -104,7 +169,7 @@ func GetProjectsConfig() []ProjectsConfig {
 // IsTypeValid checks if a project type is valid
 func IsTypeValid(p Type) bool {
 	switch p {
-	case TypeRepository:
+	case TypeIndividual, TypeRepository, TypeOrganization:
 		return true
 	default:
 		return false
@@ -114,23 +179,19 @@ func IsTypeValid(p Type) bool {
 // SearchOptions are options for GetProjects
 type SearchOptions struct {
 	RepoID   int64
+	OwnerID  int64 // overwrites RepoID if not 0
 	Page     int
+	PageSize int // defaults to setting.UI.IssuePagingNum
 	IsClosed util.OptionalBool
 	SortType string
 	Type     Type
 }
 
-// GetProjects returns a list of all projects that have been created in the repository
-func GetProjects(opts SearchOptions) ([]*Project, int64, error) {
-	return GetProjectsCtx(db.DefaultContext, opts)
-}
-
-// GetProjectsCtx returns a list of all projects that have been created in the repository
-func GetProjectsCtx(ctx context.Context, opts SearchOptions) ([]*Project, int64, error) {
-	e := db.GetEngine(ctx)
-	projects := make([]*Project, 0, setting.UI.IssuePagingNum)
-
+func (opts SearchOptions) toConds() builder.Cond {
 	var cond builder.Cond = builder.Eq{"repo_id": opts.RepoID}
+	if opts.OwnerID != 0 {
+		cond = builder.Eq{"owner_id": opts.OwnerID}
+	}
 	switch opts.IsClosed {
 	case util.OptionalBoolTrue:
 		cond = cond.And(builder.Eq{"is_closed": true})
@@ -141,7 +202,25 @@ func GetProjectsCtx(ctx context.Context, opts SearchOptions) ([]*Project, int64,
 	if opts.Type > 0 {
 		cond = cond.And(builder.Eq{"type": opts.Type})
 	}
+	return cond
+}
+
+// CountProjects counts the projects matching the options, paging and sorting are ignored
+func CountProjects(ctx context.Context, opts SearchOptions) (int64, error) {
+	return db.GetEngine(ctx).Where(opts.toConds()).Count(new(Project))
+}
+
+// GetProjects returns a list of all projects that have been created in the repository
+func GetProjects(opts SearchOptions) ([]*Project, int64, error) {
+	return GetProjectsCtx(db.DefaultContext, opts)
+}
 
+// GetProjectsCtx returns a list of all projects that have been created in the repository
+func GetProjectsCtx(ctx context.Context, opts SearchOptions) ([]*Project, int64, error) {
+	e := db.GetEngine(ctx)
+	projects := make([]*Project, 0, setting.UI.IssuePagingNum)
+
+	cond := opts.toConds()
 	count, err := e.Where(cond).Count(new(Project))
 	if err != nil {
 		return nil, 0, fmt.Errorf("Count: %v", err)
@@ -150,7 +229,11 @@ func GetProjectsCtx(ctx context.Context, opts SearchOptions) ([]*Project, int64,
 	e = e.Where(cond)
 
 	if opts.Page > 0 {
-		e = e.Limit(setting.UI.IssuePagingNum, (opts.Page-1)*setting.UI.IssuePagingNum)
+		pageSize := opts.PageSize
+		if pageSize <= 0 {
+			pageSize = setting.UI.IssuePagingNum
+		}
+		e = e.Limit(pageSize, (opts.Page-1)*pageSize)
 	}
 
 	switch opts.SortType {
@@ -187,8 +270,10 @@ func NewProject(p *Project) error {
 		return err
 	}
 
-	if _, err := db.Exec(ctx, "UPDATE `repository` SET num_projects = num_projects + 1 WHERE id = ?", p.RepoID); err != nil {
-		return err
+	if p.Type == TypeRepository {
+		if _, err := db.Exec(ctx, "UPDATE `repository` SET num_projects = num_projects + 1 WHERE id = ?", p.RepoID); err != nil {
+			return err
+		}
 	}
 
 	if err := createBoardsForProjectsType(ctx, p); err != nil {
@@ -298,7 +383,7 @@ func changeProjectStatus(ctx context.Context, p *Project, isClosed bool) error {
 	if err != nil {
 		return err
 	}
-	if count < 1 {
+	if count < 1 || p.RepoID == 0 {
 		return nil
 	}
 
@@ -339,9 +424,16 @@ func DeleteProjectByIDCtx(ctx context.Context, id int64) error {
 		return err
 	}
 
+	if err := deleteAutomationRulesByProjectID(e, id); err != nil {
+		return err
+	}
+
 	if _, err = e.ID(p.ID).Delete(new(Project)); err != nil {
 		return err
 	}
 
+	if p.RepoID == 0 {
+		return nil
+	}
 	return updateRepositoryProjectCount(e, p.RepoID)
 }
@@ -7,6 +7,8 @@ package project
 import (
 	"testing"
 
+	"code.gitea.io/gitea/models/db"
+	repo_model "code.gitea.io/gitea/models/repo"
 	"code.gitea.io/gitea/models/unittest"
 	"code.gitea.io/gitea/modules/timeutil"
 
@@ -20,9 +22,9 @@ func TestIsProjectTypeValid(t *testing.T) {
 		typ   Type
 		valid bool
 	}{
-		{TypeIndividual, false},
+		{TypeIndividual, true},
 		{TypeRepository, true},
-		{TypeOrganization, false},
+		{TypeOrganization, true},
 		{UnknownType, false},
 	}
 
@@ -45,6 +47,14 @@ func TestGetProjects(t *testing.T) {
 
 	// 1 value for this repo exists in the fixtures
 	assert.Len(t, projects, 1)
+
+	projects, _, err = GetProjects(SearchOptions{OwnerID: 3})
+	assert.NoError(t, err)
+
+	// 1 value for this organization exists in the fixtures
+	if assert.Len(t, projects, 1) {
+		assert.EqualValues(t, TypeOrganization, projects[0].Type)
+	}
 }
 
 func TestProject(t *testing.T) {
@@ -81,3 +91,23 @@ func TestProject(t *testing.T) {
 
 	assert.True(t, projectFromDB.IsClosed)
 }
+
+func TestProjectLink(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	p, err := GetProjectByID(1)
+	assert.NoError(t, err)
+	assert.NoError(t, p.LoadAttributes(db.DefaultContext))
+	assert.Equal(t, "/user2/repo1/projects/1", p.Link())
+	assert.True(t, p.AcceptsIssuesOf(p.Repo))
+
+	p, err = GetProjectByID(4)
+	assert.NoError(t, err)
+	assert.NoError(t, p.LoadAttributes(db.DefaultContext))
+	assert.Equal(t, "/user3/-/projects/4", p.Link())
+
+	repo3 := unittest.AssertExistsAndLoadBean(t, &repo_model.Repository{ID: 3}).(*repo_model.Repository)
+	assert.True(t, p.AcceptsIssuesOf(repo3))
+	repo1 := unittest.AssertExistsAndLoadBean(t, &repo_model.Repository{ID: 1}).(*repo_model.Repository)
+	assert.False(t, p.AcceptsIssuesOf(repo1))
+}
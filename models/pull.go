@@ -112,6 +112,18 @@ func deletePullsByBaseRepoID(sess db.Engine, repoID int64) error {
 		return err
 	}
 
+	// Delete iterations
+	if _, err := sess.In("pull_id", deleteCond).
+		Delete(&pull_model.Iteration{}); err != nil {
+		return err
+	}
+
+	// Delete merge queue entries
+	if _, err := sess.Where("repo_id = ?", repoID).
+		Delete(&pull_model.MergeQueueEntry{}); err != nil {
+		return err
+	}
+
 	_, err := sess.Delete(&PullRequest{BaseRepoID: repoID})
 	return err
 }
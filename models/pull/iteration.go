@@ -0,0 +1,124 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package pull
+
+import (
+	"context"
+	"fmt"
+
+	"code.gitea.io/gitea/models/db"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/timeutil"
+)
+
+// Iteration is the state of the head branch of a pull request after it has been created or pushed to
+type Iteration struct {
+	ID     int64 `xorm:"pk autoincr"`
+	PullID int64 `xorm:"NOT NULL UNIQUE(pull_index)"`
+	// Index numbers the iterations of a pull request starting from 1
+	Index    int64            `xorm:"NOT NULL UNIQUE(pull_index)"`
+	PusherID int64            `xorm:"NOT NULL"`
+	Pusher   *user_model.User `xorm:"-"`
+	// CommentID is the push comment of the iteration, there is none for the first iteration
+	CommentID    int64  `xorm:"NOT NULL DEFAULT 0"`
+	HeadCommitID string `xorm:"VARCHAR(40)"`
+	// MergeBase is the merge base of the head and the base branch at the time of the push
+	MergeBase   string             `xorm:"VARCHAR(40)"`
+	IsForcePush bool               `xorm:"NOT NULL DEFAULT false"`
+	CreatedUnix timeutil.TimeStamp `xorm:"created"`
+}
+
+// TableName return database table name for xorm
+func (Iteration) TableName() string {
+	return "pull_iteration"
+}
+
+func init() {
+	db.RegisterModel(new(Iteration))
+}
+
+// LoadPusher loads the user who pushed the iteration
+func (it *Iteration) LoadPusher(ctx context.Context) (err error) {
+	if it.Pusher != nil {
+		return nil
+	}
+	it.Pusher, err = user_model.GetUserByIDCtx(ctx, it.PusherID)
+	if user_model.IsErrUserNotExist(err) {
+		it.Pusher = user_model.NewGhostUser()
+		return nil
+	}
+	return err
+}
+
+// ErrIterationNotExist represents a "PullIterationNotExist" kind of error.
+type ErrIterationNotExist struct {
+	PullID int64
+	Index  int64
+}
+
+// IsErrIterationNotExist checks if an error is a ErrIterationNotExist.
+func IsErrIterationNotExist(err error) bool {
+	_, ok := err.(ErrIterationNotExist)
+	return ok
+}
+
+func (err ErrIterationNotExist) Error() string {
+	return fmt.Sprintf("pull request iteration does not exist [pull_id: %d, index: %d]", err.PullID, err.Index)
+}
+
+// CreateIteration adds the next iteration of a pull request, its index is assigned automatically.
+// Nothing is added if the head commit is the same as the one of the latest iteration.
+func CreateIteration(ctx context.Context, it *Iteration) (created bool, err error) {
+	err = db.WithTx(func(ctx context.Context) error {
+		latest, err := GetLatestIteration(ctx, it.PullID)
+		if err != nil && !IsErrIterationNotExist(err) {
+			return err
+		}
+		if latest != nil && latest.HeadCommitID == it.HeadCommitID {
+			return nil
+		}
+
+		it.Index = 1
+		if latest != nil {
+			it.Index = latest.Index + 1
+		}
+		created = true
+		return db.Insert(ctx, it)
+	}, ctx)
+	return created, err
+}
+
+// GetIterations returns all iterations of a pull request, oldest first
+func GetIterations(ctx context.Context, pullID int64) ([]*Iteration, error) {
+	iterations := make([]*Iteration, 0, 10)
+	return iterations, db.GetEngine(ctx).
+		Where("pull_id = ?", pullID).
+		Asc("`index`").
+		Find(&iterations)
+}
+
+// GetIterationByIndex returns an iteration of a pull request by its index
+func GetIterationByIndex(ctx context.Context, pullID, index int64) (*Iteration, error) {
+	it := &Iteration{}
+	has, err := db.GetEngine(ctx).Where("pull_id = ? AND `index` = ?", pullID, index).Get(it)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrIterationNotExist{PullID: pullID, Index: index}
+	}
+	return it, nil
+}
+
+// GetLatestIteration returns the most recent iteration of a pull request
+func GetLatestIteration(ctx context.Context, pullID int64) (*Iteration, error) {
+	it := &Iteration{}
+	has, err := db.GetEngine(ctx).Where("pull_id = ?", pullID).Desc("`index`").Get(it)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrIterationNotExist{PullID: pullID}
+	}
+	return it, nil
+}
@@ -0,0 +1,141 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package pull
+
+import (
+	"context"
+	"fmt"
+
+	"code.gitea.io/gitea/models/db"
+	repo_model "code.gitea.io/gitea/models/repo"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/timeutil"
+)
+
+// MergeQueueEntry represents a pull request waiting in the merge queue of its base branch.
+// The entries of a branch are merged in the order they were added.
+type MergeQueueEntry struct {
+	ID         int64                 `xorm:"pk autoincr"`
+	PullID     int64                 `xorm:"UNIQUE"`
+	RepoID     int64                 `xorm:"INDEX(s) NOT NULL"`
+	BaseBranch string                `xorm:"INDEX(s) NOT NULL"`
+	DoerID     int64                 `xorm:"NOT NULL"`
+	Doer       *user_model.User      `xorm:"-"`
+	MergeStyle repo_model.MergeStyle `xorm:"varchar(30)"`
+	Message    string                `xorm:"LONGTEXT"`
+	// HeadCommitID is the head of the pull request when it was added to the queue
+	HeadCommitID string `xorm:"VARCHAR(40)"`
+	// ParentCommitID is the commit the temporary merge commit has been built on,
+	// either the base branch or the merge commit of the previous entry
+	ParentCommitID string `xorm:"VARCHAR(40)"`
+	// CommitID is the temporary merge commit the required status checks run against
+	CommitID    string             `xorm:"VARCHAR(40)"`
+	CreatedUnix timeutil.TimeStamp `xorm:"created"`
+}
+
+// TableName return database table name for xorm
+func (MergeQueueEntry) TableName() string {
+	return "pull_merge_queue"
+}
+
+func init() {
+	db.RegisterModel(new(MergeQueueEntry))
+}
+
+// LoadDoer loads the user who added the pull request to the queue
+func (entry *MergeQueueEntry) LoadDoer(ctx context.Context) (err error) {
+	if entry.Doer != nil {
+		return nil
+	}
+	entry.Doer, err = user_model.GetUserByIDCtx(ctx, entry.DoerID)
+	if user_model.IsErrUserNotExist(err) {
+		entry.Doer = user_model.NewGhostUser()
+		return nil
+	}
+	return err
+}
+
+// ErrAlreadyInMergeQueue represents a "PullRequestAlreadyInMergeQueue"-error
+type ErrAlreadyInMergeQueue struct {
+	PullID int64
+}
+
+func (err ErrAlreadyInMergeQueue) Error() string {
+	return fmt.Sprintf("pull request is already in the merge queue [pull_id: %d]", err.PullID)
+}
+
+// IsErrAlreadyInMergeQueue checks if an error is a ErrAlreadyInMergeQueue.
+func IsErrAlreadyInMergeQueue(err error) bool {
+	_, ok := err.(ErrAlreadyInMergeQueue)
+	return ok
+}
+
+// AddToMergeQueue appends a pull request to the merge queue of its base branch
+func AddToMergeQueue(ctx context.Context, entry *MergeQueueEntry) error {
+	if exists, err := db.GetEngine(ctx).Where("pull_id = ?", entry.PullID).Exist(&MergeQueueEntry{}); err != nil {
+		return err
+	} else if exists {
+		return ErrAlreadyInMergeQueue{PullID: entry.PullID}
+	}
+
+	_, err := db.GetEngine(ctx).Insert(entry)
+	return err
+}
+
+// GetMergeQueueEntryByPullID returns the merge queue entry of a pull request
+func GetMergeQueueEntryByPullID(ctx context.Context, pullID int64) (*MergeQueueEntry, error) {
+	entry := &MergeQueueEntry{}
+	has, err := db.GetEngine(ctx).Where("pull_id = ?", pullID).Get(entry)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, db.ErrNotExist{ID: pullID}
+	}
+	return entry, nil
+}
+
+// GetMergeQueueEntries returns the merge queue of a branch in merge order
+func GetMergeQueueEntries(ctx context.Context, repoID int64, branch string) ([]*MergeQueueEntry, error) {
+	entries := make([]*MergeQueueEntry, 0, 10)
+	return entries, db.GetEngine(ctx).
+		Where("repo_id = ? AND base_branch = ?", repoID, branch).
+		Asc("id").
+		Find(&entries)
+}
+
+// GetMergeQueueEntriesByCommitID returns the merge queue entries whose temporary merge commit is commitID
+func GetMergeQueueEntriesByCommitID(ctx context.Context, repoID int64, commitID string) ([]*MergeQueueEntry, error) {
+	entries := make([]*MergeQueueEntry, 0, 1)
+	return entries, db.GetEngine(ctx).
+		Where("repo_id = ? AND commit_id = ?", repoID, commitID).
+		Find(&entries)
+}
+
+// HasMergeQueueEntries returns whether the merge queue of a branch is not empty
+func HasMergeQueueEntries(ctx context.Context, repoID int64, branch string) (bool, error) {
+	return db.GetEngine(ctx).
+		Where("repo_id = ? AND base_branch = ?", repoID, branch).
+		Exist(&MergeQueueEntry{})
+}
+
+// GetMergeQueuePosition returns the 1-based position of the entry in the merge queue of its branch
+func GetMergeQueuePosition(ctx context.Context, entry *MergeQueueEntry) (int64, error) {
+	count, err := db.GetEngine(ctx).
+		Where("repo_id = ? AND base_branch = ? AND id < ?", entry.RepoID, entry.BaseBranch, entry.ID).
+		Count(&MergeQueueEntry{})
+	return count + 1, err
+}
+
+// UpdateMergeQueueEntryCommit records the temporary merge commit of the entry
+func UpdateMergeQueueEntryCommit(ctx context.Context, entry *MergeQueueEntry) error {
+	_, err := db.GetEngine(ctx).ID(entry.ID).Cols("parent_commit_id", "commit_id").Update(entry)
+	return err
+}
+
+// DeleteMergeQueueEntry removes an entry from the merge queue
+func DeleteMergeQueueEntry(ctx context.Context, entry *MergeQueueEntry) error {
+	_, err := db.GetEngine(ctx).ID(entry.ID).Delete(&MergeQueueEntry{})
+	return err
+}
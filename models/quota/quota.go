@@ -0,0 +1,156 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package quota
+
+import (
+	"context"
+	"fmt"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/timeutil"
+)
+
+func init() {
+	db.RegisterModel(new(Quota))
+}
+
+// Category is the kind of storage a quota limit applies to
+type Category string
+
+// List of quota categories
+const (
+	CategoryGit         Category = "git"
+	CategoryLFS         Category = "lfs"
+	CategoryPackages    Category = "packages"
+	CategoryAttachments Category = "attachments"
+)
+
+// ErrQuotaExceeded represents a "QuotaExceeded" kind of error.
+type ErrQuotaExceeded struct {
+	OwnerID  int64
+	Category Category
+}
+
+// IsErrQuotaExceeded checks if an error is a ErrQuotaExceeded.
+func IsErrQuotaExceeded(err error) bool {
+	_, ok := err.(ErrQuotaExceeded)
+	return ok
+}
+
+func (err ErrQuotaExceeded) Error() string {
+	return fmt.Sprintf("storage quota exceeded [owner_id: %d, category: %s]", err.OwnerID, err.Category)
+}
+
+// Quota represents the storage limits of a user or organization
+// A negative limit means there is no limit.
+type Quota struct {
+	ID               int64              `xorm:"pk autoincr"`
+	OwnerID          int64              `xorm:"UNIQUE NOT NULL"`
+	LimitTotal       int64              `xorm:"NOT NULL DEFAULT -1"`
+	LimitGit         int64              `xorm:"NOT NULL DEFAULT -1"`
+	LimitLFS         int64              `xorm:"NOT NULL DEFAULT -1"`
+	LimitPackages    int64              `xorm:"NOT NULL DEFAULT -1"`
+	LimitAttachments int64              `xorm:"NOT NULL DEFAULT -1"`
+	CreatedUnix      timeutil.TimeStamp `xorm:"created NOT NULL DEFAULT 0"`
+	UpdatedUnix      timeutil.TimeStamp `xorm:"updated NOT NULL DEFAULT 0"`
+}
+
+// DefaultQuota returns the quota from the settings which applies to owners without an individual quota
+func DefaultQuota(ownerID int64) *Quota {
+	return &Quota{
+		OwnerID:          ownerID,
+		LimitTotal:       setting.Quota.DefaultTotal,
+		LimitGit:         setting.Quota.DefaultGit,
+		LimitLFS:         setting.Quota.DefaultLFS,
+		LimitPackages:    setting.Quota.DefaultPackages,
+		LimitAttachments: setting.Quota.DefaultAttachments,
+	}
+}
+
+// Limit returns the limit of the category
+func (q *Quota) Limit(category Category) int64 {
+	switch category {
+	case CategoryGit:
+		return q.LimitGit
+	case CategoryLFS:
+		return q.LimitLFS
+	case CategoryPackages:
+		return q.LimitPackages
+	case CategoryAttachments:
+		return q.LimitAttachments
+	}
+	return -1
+}
+
+// IsDefault returns true if the quota is not stored for the owner
+func (q *Quota) IsDefault() bool {
+	return q.ID == 0
+}
+
+// GetQuotaByOwnerID gets the quota of the owner. If the owner has no individual quota, the default quota is returned.
+func GetQuotaByOwnerID(ctx context.Context, ownerID int64) (*Quota, error) {
+	q := &Quota{}
+	has, err := db.GetEngine(ctx).Where("owner_id = ?", ownerID).Get(q)
+	if err != nil {
+		return nil, err
+	}
+	if !has {
+		return DefaultQuota(ownerID), nil
+	}
+	return q, nil
+}
+
+// SetQuota inserts or updates the quota of the owner
+func SetQuota(ctx context.Context, q *Quota) error {
+	existing := &Quota{}
+	has, err := db.GetEngine(ctx).Where("owner_id = ?", q.OwnerID).Get(existing)
+	if err != nil {
+		return err
+	}
+	if !has {
+		return db.Insert(ctx, q)
+	}
+	q.ID = existing.ID
+	_, err = db.GetEngine(ctx).ID(q.ID).AllCols().Update(q)
+	return err
+}
+
+// DeleteQuotaByOwnerID deletes the quota of the owner so the default quota applies again
+func DeleteQuotaByOwnerID(ctx context.Context, ownerID int64) error {
+	_, err := db.GetEngine(ctx).Where("owner_id = ?", ownerID).Delete(&Quota{})
+	return err
+}
+
+// CheckQuota checks if size bytes of the category can be added without exceeding the quota of the owner
+// ErrQuotaExceeded is returned if either the category limit or the total limit would be exceeded.
+func CheckQuota(ctx context.Context, ownerID int64, category Category, size int64) error {
+	if !setting.Quota.Enabled {
+		return nil
+	}
+
+	q, err := GetQuotaByOwnerID(ctx, ownerID)
+	if err != nil {
+		return err
+	}
+
+	limit := q.Limit(category)
+	if limit < 0 && q.LimitTotal < 0 {
+		return nil
+	}
+
+	u, err := GetUsageByOwnerID(ctx, ownerID)
+	if err != nil {
+		return err
+	}
+
+	if limit >= 0 && u.Size(category)+size > limit {
+		return ErrQuotaExceeded{OwnerID: ownerID, Category: category}
+	}
+	if q.LimitTotal >= 0 && u.Total()+size > q.LimitTotal {
+		return ErrQuotaExceeded{OwnerID: ownerID, Category: category}
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package quota
+
+import (
+	"context"
+
+	"code.gitea.io/gitea/models/db"
+
+	"xorm.io/builder"
+)
+
+// Usage contains the used storage of a user or organization in bytes
+type Usage struct {
+	Git         int64
+	LFS         int64
+	Packages    int64
+	Attachments int64
+}
+
+// Total returns the used storage of all categories
+func (u *Usage) Total() int64 {
+	return u.Git + u.LFS + u.Packages + u.Attachments
+}
+
+// Size returns the used storage of the category
+func (u *Usage) Size(category Category) int64 {
+	switch category {
+	case CategoryGit:
+		return u.Git
+	case CategoryLFS:
+		return u.LFS
+	case CategoryPackages:
+		return u.Packages
+	case CategoryAttachments:
+		return u.Attachments
+	}
+	return 0
+}
+
+// GetUsageByOwnerID calculates the used storage of the owner
+func GetUsageByOwnerID(ctx context.Context, ownerID int64) (*Usage, error) {
+	e := db.GetEngine(ctx)
+
+	u := &Usage{}
+
+	// The repository size contains the size of the LFS objects
+	var repositorySize int64
+	if _, err := e.Select("COALESCE(SUM(size), 0)").
+		Table("repository").
+		Where("owner_id = ?", ownerID).
+		Get(&repositorySize); err != nil {
+		return nil, err
+	}
+
+	if _, err := e.Select("COALESCE(SUM(lfs_meta_object.size), 0)").
+		Table("lfs_meta_object").
+		Join("INNER", "repository", "repository.id = lfs_meta_object.repository_id").
+		Where("repository.owner_id = ?", ownerID).
+		Get(&u.LFS); err != nil {
+		return nil, err
+	}
+
+	u.Git = repositorySize - u.LFS
+	if u.Git < 0 {
+		u.Git = 0
+	}
+
+	if _, err := e.Select("COALESCE(SUM(size), 0)").
+		Table("package_blob").
+		Where(builder.In("id",
+			builder.Select("package_file.blob_id").
+				From("package_file").
+				InnerJoin("package_version", "package_version.id = package_file.version_id").
+				InnerJoin("package", "package.id = package_version.package_id").
+				Where(builder.Eq{"package.owner_id": ownerID}),
+		)).
+		Get(&u.Packages); err != nil {
+		return nil, err
+	}
+
+	if _, err := e.Select("COALESCE(SUM(attachment.size), 0)").
+		Table("attachment").
+		Join("INNER", "repository", "repository.id = attachment.repo_id").
+		Where("repository.owner_id = ?", ownerID).
+		Get(&u.Attachments); err != nil {
+		return nil, err
+	}
+
+	return u, nil
+}
@@ -16,8 +16,10 @@ import (
 
 	_ "image/jpeg" // Needed for jpeg support
 
+	actions_model "code.gitea.io/gitea/models/actions"
 	admin_model "code.gitea.io/gitea/models/admin"
 	asymkey_model "code.gitea.io/gitea/models/asymkey"
+	checks_model "code.gitea.io/gitea/models/checks"
 	"code.gitea.io/gitea/models/db"
 	issues_model "code.gitea.io/gitea/models/issues"
 	"code.gitea.io/gitea/models/organization"
@@ -382,7 +384,7 @@ func CreateRepository(ctx context.Context, doer, u *user_model.User, repo *repo_
 			units = append(units, repo_model.RepoUnit{
 				RepoID: repo.ID,
 				Type:   tp,
-				Config: &repo_model.PullRequestsConfig{AllowMerge: true, AllowRebase: true, AllowRebaseMerge: true, AllowSquash: true, DefaultMergeStyle: repo_model.MergeStyleMerge, AllowRebaseUpdate: true},
+				Config: &repo_model.PullRequestsConfig{AllowMerge: true, AllowRebase: true, AllowRebaseMerge: true, AllowSquash: true, AllowFastForwardOnly: true, DefaultMergeStyle: repo_model.MergeStyleMerge, AllowRebaseUpdate: true},
 			})
 		} else {
 			units = append(units, repo_model.RepoUnit{
@@ -651,9 +653,23 @@ func DeleteRepository(doer *user_model.User, uid, repoID int64) error {
 		return err
 	}
 
+	var actionJobs []*actions_model.ActionRunJob
+	if err = sess.Where("repo_id = ?", repoID).Find(&actionJobs); err != nil {
+		return err
+	}
+	actionLogPaths := make([]string, 0, len(actionJobs))
+	for _, job := range actionJobs {
+		actionLogPaths = append(actionLogPaths, job.LogChunkPaths()...)
+	}
+
 	if err := db.DeleteBeans(ctx,
 		&access_model.Access{RepoID: repo.ID},
 		&Action{RepoID: repo.ID},
+		&actions_model.ActionRun{RepoID: repoID},
+		&actions_model.ActionRunJob{RepoID: repoID},
+		&actions_model.ActionRunStep{RepoID: repoID},
+		&checks_model.CheckAnnotation{RepoID: repoID},
+		&checks_model.CheckRun{RepoID: repoID},
 		&repo_model.Collaboration{RepoID: repoID},
 		&Comment{RefRepoID: repoID},
 		&CommitStatus{RepoID: repoID},
@@ -661,6 +677,7 @@ func DeleteRepository(doer *user_model.User, uid, repoID int64) error {
 		&webhook.HookTask{RepoID: repoID},
 		&LFSLock{RepoID: repoID},
 		&repo_model.LanguageStat{RepoID: repoID},
+		&issues_model.CustomField{RepoID: repoID},
 		&issues_model.Milestone{RepoID: repoID},
 		&repo_model.Mirror{RepoID: repoID},
 		&Notification{RepoID: repoID},
@@ -826,6 +843,11 @@ func DeleteRepository(doer *user_model.User, uid, repoID int64) error {
 		admin_model.RemoveStorageWithNotice(db.DefaultContext, storage.LFS, "Delete orphaned LFS file", lfsObj)
 	}
 
+	// Remove action logs
+	for _, actionLog := range actionLogPaths {
+		admin_model.RemoveStorageWithNotice(db.DefaultContext, storage.Actions, "Delete action log", actionLog)
+	}
+
 	// Remove issue attachment files.
 	for _, attachment := range attachmentPaths {
 		admin_model.RemoveStorageWithNotice(db.DefaultContext, storage.Attachments, "Delete issue attachment", attachment)
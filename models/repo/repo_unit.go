@@ -108,16 +108,18 @@ func (cfg *IssuesConfig) ToDB() ([]byte, error) {
 
 // PullRequestsConfig describes pull requests config
 type PullRequestsConfig struct {
-	IgnoreWhitespaceConflicts     bool
-	AllowMerge                    bool
-	AllowRebase                   bool
-	AllowRebaseMerge              bool
-	AllowSquash                   bool
-	AllowManualMerge              bool
-	AutodetectManualMerge         bool
-	AllowRebaseUpdate             bool
-	DefaultDeleteBranchAfterMerge bool
-	DefaultMergeStyle             MergeStyle
+	IgnoreWhitespaceConflicts      bool
+	AllowMerge                     bool
+	AllowRebase                    bool
+	AllowRebaseMerge               bool
+	AllowSquash                    bool
+	AllowFastForwardOnly           bool
+	AllowManualMerge               bool
+	AutodetectManualMerge          bool
+	AllowRebaseUpdate              bool
+	DefaultDeleteBranchAfterMerge  bool
+	DefaultMergeStyle              MergeStyle
+	RebaseDependentPullsAfterMerge bool
 }
 
 // FromDB fills up a PullRequestsConfig from serialized format.
@@ -138,6 +140,7 @@ func (cfg *PullRequestsConfig) IsMergeStyleAllowed(mergeStyle MergeStyle) bool {
 		mergeStyle == MergeStyleRebase && cfg.AllowRebase ||
 		mergeStyle == MergeStyleRebaseMerge && cfg.AllowRebaseMerge ||
 		mergeStyle == MergeStyleSquash && cfg.AllowSquash ||
+		mergeStyle == MergeStyleFastForwardOnly && cfg.AllowFastForwardOnly ||
 		mergeStyle == MergeStyleManuallyMerged && cfg.AllowManualMerge
 }
 
@@ -165,6 +168,9 @@ func (cfg *PullRequestsConfig) AllowedMergeStyleCount() int {
 	if cfg.AllowSquash {
 		count++
 	}
+	if cfg.AllowFastForwardOnly {
+		count++
+	}
 	return count
 }
 
@@ -529,6 +529,30 @@ func getReviewByIssueIDAndUserID(e db.Engine, issueID, userID int64) (*Review, e
 	return review, nil
 }
 
+// GetApproverIDs returns the ids of the users whose latest review of a pull request is an approval which has not been dismissed
+func GetApproverIDs(ctx context.Context, issueID int64, excludeStale bool) ([]int64, error) {
+	reviews := make([]*Review, 0, 10)
+	if err := db.GetEngine(ctx).Where("issue_id = ? AND original_author_id = 0 AND dismissed = ?", issueID, false).
+		In("type", ReviewTypeApprove, ReviewTypeReject).
+		Asc("id").
+		Find(&reviews); err != nil {
+		return nil, err
+	}
+
+	latest := make(map[int64]*Review, len(reviews))
+	for _, review := range reviews {
+		latest[review.ReviewerID] = review
+	}
+
+	approverIDs := make([]int64, 0, len(latest))
+	for reviewerID, review := range latest {
+		if review.Type == ReviewTypeApprove && !(excludeStale && review.Stale) {
+			approverIDs = append(approverIDs, reviewerID)
+		}
+	}
+	return approverIDs, nil
+}
+
 // GetTeamReviewerByIssueIDAndTeamID get the latest review request of reviewer team for a pull request
 func GetTeamReviewerByIssueIDAndTeamID(issueID, teamID int64) (review *Review, err error) {
 	return getTeamReviewerByIssueIDAndTeamID(db.GetEngine(db.DefaultContext), issueID, teamID)
@@ -0,0 +1,95 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"strings"
+)
+
+// CodeSuggestion is a change proposed by a ```suggestion block of a code comment, which replaces the commented line
+type CodeSuggestion struct {
+	// Text is the content of the comment without the suggestion block
+	Text string
+	// OldLine is the commented line as it was when the comment was made
+	OldLine string
+	// NewLines replace the commented line, there are none if the line is to be removed
+	NewLines []string
+}
+
+// ParseCodeSuggestion extracts the first ```suggestion block of the content of a code comment.
+// It returns the content without that block and the lines of the block, ok is false if there is no such block.
+func ParseCodeSuggestion(content string) (text string, lines []string, ok bool) {
+	contentLines := strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n")
+
+	start := -1
+	var fence string
+	for i, line := range contentLines {
+		trimmed := strings.TrimSpace(line)
+		if start < 0 {
+			if f := codeFence(trimmed); f != "" && strings.TrimSpace(trimmed[len(f):]) == "suggestion" {
+				start, fence = i, f
+			}
+			continue
+		}
+		// a block is closed by a fence of the same character which is at least as long as the opening one
+		if f := codeFence(trimmed); f != "" && f == trimmed && f[0] == fence[0] && len(f) >= len(fence) {
+			lines = contentLines[start+1 : i]
+			text = strings.Join(append(contentLines[:start:start], contentLines[i+1:]...), "\n")
+			return strings.TrimSpace(text), lines, true
+		}
+	}
+
+	// like in markdown an unclosed block runs until the end of the content
+	if start >= 0 {
+		return strings.TrimSpace(strings.Join(contentLines[:start], "\n")), contentLines[start+1:], true
+	}
+	return content, nil, false
+}
+
+// codeFence returns the code fence a line starts with, or an empty string if it doesn't start with one
+func codeFence(line string) string {
+	for _, c := range []byte{'`', '~'} {
+		n := 0
+		for n < len(line) && line[n] == c {
+			n++
+		}
+		if n >= 3 {
+			return line[:n]
+		}
+	}
+	return ""
+}
+
+// LoadCodeSuggestion parses the suggestion of a code comment on a line of the new version of a file
+func (c *Comment) LoadCodeSuggestion() {
+	c.Suggestion = nil
+	if c.Type != CommentTypeCode || c.Line <= 0 {
+		return
+	}
+	text, lines, ok := ParseCodeSuggestion(c.Content)
+	if !ok {
+		return
+	}
+	c.Suggestion = &CodeSuggestion{
+		Text:     text,
+		OldLine:  commentedLine(c.Patch),
+		NewLines: lines,
+	}
+}
+
+// CanApplySuggestion returns whether the suggestion of a code comment can still be applied
+func (c *Comment) CanApplySuggestion() bool {
+	return c.Suggestion != nil && !c.Invalidated && c.Review != nil && c.Review.Type != ReviewTypePending
+}
+
+// commentedLine returns the content of the commented line of the patch of a code comment,
+// which is its last line
+func commentedLine(patch string) string {
+	line := patch[strings.LastIndex(patch, "\n")+1:]
+	if len(line) == 0 || (line[0] != '+' && line[0] != ' ') {
+		return ""
+	}
+	return line[1:]
+}
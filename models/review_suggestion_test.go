@@ -0,0 +1,56 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseCodeSuggestion(t *testing.T) {
+	text, lines, ok := ParseCodeSuggestion("Better:\r\n```suggestion\r\nfoo()\r\n\r\nbar()\r\n```\r\nThanks")
+	assert.True(t, ok)
+	assert.Equal(t, "Better:\nThanks", text)
+	assert.Equal(t, []string{"foo()", "", "bar()"}, lines)
+
+	text, lines, ok = ParseCodeSuggestion("Remove it\n~~~~ suggestion\n~~~~")
+	assert.True(t, ok)
+	assert.Equal(t, "Remove it", text)
+	assert.Empty(t, lines)
+
+	// a fence inside the block which is shorter than the opening one doesn't close it
+	_, lines, ok = ParseCodeSuggestion("````suggestion\n```\n````")
+	assert.True(t, ok)
+	assert.Equal(t, []string{"```"}, lines)
+
+	// unclosed blocks run until the end
+	_, lines, ok = ParseCodeSuggestion("```suggestion\nfoo()")
+	assert.True(t, ok)
+	assert.Equal(t, []string{"foo()"}, lines)
+
+	text, _, ok = ParseCodeSuggestion("```go\nfoo()\n```")
+	assert.False(t, ok)
+	assert.Equal(t, "```go\nfoo()\n```", text)
+}
+
+func TestCommentLoadCodeSuggestion(t *testing.T) {
+	comment := &Comment{
+		Type:    CommentTypeCode,
+		Line:    2,
+		Content: "```suggestion\nfoo(1)\n```",
+		Patch:   "diff --git a/a.go b/a.go\n--- a/a.go\n+++ b/a.go\n@@ -1,2 +1,2 @@\n bar()\n+foo()",
+	}
+	comment.LoadCodeSuggestion()
+	if assert.NotNil(t, comment.Suggestion) {
+		assert.Equal(t, "foo()", comment.Suggestion.OldLine)
+		assert.Equal(t, []string{"foo(1)"}, comment.Suggestion.NewLines)
+	}
+
+	// suggestions for the old version of a file cannot be applied
+	comment.Line = -2
+	comment.LoadCodeSuggestion()
+	assert.Nil(t, comment.Suggestion)
+}
@@ -0,0 +1,260 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package actions
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"code.gitea.io/gitea/modules/git"
+
+	"github.com/gobwas/glob"
+	"gopkg.in/yaml.v2"
+)
+
+// List of supported events
+const (
+	EventPush        = "push"
+	EventPullRequest = "pull_request"
+)
+
+var (
+	// ErrInvalidWorkflow indicates an invalid workflow file
+	ErrInvalidWorkflow = errors.New("Workflow file is invalid")
+)
+
+// StringList is a list of strings which may be written as a single string in the workflow file
+type StringList []string
+
+// UnmarshalYAML implements yaml.Unmarshaler
+func (l *StringList) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var s string
+	if err := unmarshal(&s); err == nil {
+		*l = StringList{s}
+		return nil
+	}
+	var list []string
+	if err := unmarshal(&list); err != nil {
+		return err
+	}
+	*l = list
+	return nil
+}
+
+// EventFilter restricts the refs an event triggers a workflow for
+type EventFilter struct {
+	Branches       []string `yaml:"branches"`
+	BranchesIgnore []string `yaml:"branches-ignore"`
+	Tags           []string `yaml:"tags"`
+	TagsIgnore     []string `yaml:"tags-ignore"`
+}
+
+// Events maps the names of the events which trigger a workflow to their filters
+type Events map[string]*EventFilter
+
+// UnmarshalYAML implements yaml.Unmarshaler
+// The events may be written as a single event name, a list of event names or a map of event names to filters.
+func (e *Events) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var names StringList
+	if err := unmarshal(&names); err == nil {
+		events := make(Events, len(names))
+		for _, name := range names {
+			events[name] = &EventFilter{}
+		}
+		*e = events
+		return nil
+	}
+
+	var events map[string]*EventFilter
+	if err := unmarshal(&events); err != nil {
+		return err
+	}
+	for name, filter := range events {
+		if filter == nil {
+			events[name] = &EventFilter{}
+		}
+	}
+	*e = events
+	return nil
+}
+
+// Step is a single step of a job
+type Step struct {
+	Name string            `yaml:"name" json:"name,omitempty"`
+	Run  string            `yaml:"run" json:"run,omitempty"`
+	Uses string            `yaml:"uses" json:"uses,omitempty"`
+	With map[string]string `yaml:"with" json:"with,omitempty"`
+	Env  map[string]string `yaml:"env" json:"env,omitempty"`
+}
+
+// DisplayName returns the name of the step or a name derived from its command
+func (s *Step) DisplayName() string {
+	if s.Name != "" {
+		return s.Name
+	}
+	if s.Uses != "" {
+		return s.Uses
+	}
+	line := strings.TrimSpace(s.Run)
+	if pos := strings.IndexByte(line, '\n'); pos != -1 {
+		line = line[:pos]
+	}
+	return line
+}
+
+// Job is a job of a workflow
+type Job struct {
+	ID     string            `yaml:"-"`
+	Name   string            `yaml:"name"`
+	RunsOn StringList        `yaml:"runs-on"`
+	Needs  StringList        `yaml:"needs"`
+	Env    map[string]string `yaml:"env"`
+	Steps  []*Step           `yaml:"steps"`
+}
+
+// DisplayName returns the name of the job or its id
+func (j *Job) DisplayName() string {
+	if j.Name != "" {
+		return j.Name
+	}
+	return j.ID
+}
+
+// Workflow is a parsed workflow file
+type Workflow struct {
+	Name string            `yaml:"name"`
+	On   Events            `yaml:"on"`
+	Env  map[string]string `yaml:"env"`
+	Jobs []*Job            `yaml:"-"`
+}
+
+// ParseWorkflow parses and validates the content of a workflow file
+// The jobs are sorted by their id.
+func ParseWorkflow(content []byte) (*Workflow, error) {
+	var w struct {
+		Workflow `yaml:",inline"`
+		Jobs     map[string]*Job `yaml:"jobs"`
+	}
+	if err := yaml.Unmarshal(content, &w); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidWorkflow, err)
+	}
+
+	workflow := &w.Workflow
+	if len(workflow.On) == 0 {
+		return nil, fmt.Errorf("%w: no trigger events", ErrInvalidWorkflow)
+	}
+	if len(w.Jobs) == 0 {
+		return nil, fmt.Errorf("%w: no jobs", ErrInvalidWorkflow)
+	}
+
+	for id, job := range w.Jobs {
+		if job == nil || len(job.Steps) == 0 {
+			return nil, fmt.Errorf("%w: job %s has no steps", ErrInvalidWorkflow, id)
+		}
+		for _, step := range job.Steps {
+			if step == nil || (step.Run == "") == (step.Uses == "") {
+				return nil, fmt.Errorf("%w: every step of job %s must have either run or uses", ErrInvalidWorkflow, id)
+			}
+		}
+		for _, need := range job.Needs {
+			if _, ok := w.Jobs[need]; !ok {
+				return nil, fmt.Errorf("%w: job %s needs unknown job %s", ErrInvalidWorkflow, id, need)
+			}
+		}
+		job.ID = id
+		workflow.Jobs = append(workflow.Jobs, job)
+	}
+
+	sort.Slice(workflow.Jobs, func(i, j int) bool {
+		return workflow.Jobs[i].ID < workflow.Jobs[j].ID
+	})
+
+	if err := checkDependencyCycle(w.Jobs); err != nil {
+		return nil, err
+	}
+
+	return workflow, nil
+}
+
+func checkDependencyCycle(jobs map[string]*Job) error {
+	const (
+		visiting = 1
+		visited  = 2
+	)
+	state := make(map[string]int, len(jobs))
+
+	var visit func(id string) error
+	visit = func(id string) error {
+		switch state[id] {
+		case visiting:
+			return fmt.Errorf("%w: job %s has a dependency cycle", ErrInvalidWorkflow, id)
+		case visited:
+			return nil
+		}
+		state[id] = visiting
+		for _, need := range jobs[id].Needs {
+			if err := visit(need); err != nil {
+				return err
+			}
+		}
+		state[id] = visited
+		return nil
+	}
+
+	for id := range jobs {
+		if err := visit(id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Match checks if the event triggers the workflow
+// For push events ref is the full name of the pushed ref, for pull request events the name of the base branch.
+func (w *Workflow) Match(event, ref string) bool {
+	filter, ok := w.On[event]
+	if !ok {
+		return false
+	}
+
+	switch event {
+	case EventPush:
+		if strings.HasPrefix(ref, git.TagPrefix) {
+			if len(filter.Tags) == 0 && len(filter.TagsIgnore) == 0 && (len(filter.Branches) != 0 || len(filter.BranchesIgnore) != 0) {
+				return false
+			}
+			return matchFilter(strings.TrimPrefix(ref, git.TagPrefix), filter.Tags, filter.TagsIgnore)
+		}
+		if len(filter.Branches) == 0 && len(filter.BranchesIgnore) == 0 && (len(filter.Tags) != 0 || len(filter.TagsIgnore) != 0) {
+			return false
+		}
+		return matchFilter(strings.TrimPrefix(ref, git.BranchPrefix), filter.Branches, filter.BranchesIgnore)
+	case EventPullRequest:
+		return matchFilter(ref, filter.Branches, filter.BranchesIgnore)
+	}
+	return false
+}
+
+func matchFilter(name string, patterns, ignorePatterns []string) bool {
+	if len(patterns) != 0 && !matchAny(name, patterns) {
+		return false
+	}
+	return !matchAny(name, ignorePatterns)
+}
+
+func matchAny(name string, patterns []string) bool {
+	for _, pattern := range patterns {
+		g, err := glob.Compile(pattern, '/')
+		if err != nil {
+			continue
+		}
+		if g.Match(name) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,154 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package actions
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseWorkflow(t *testing.T) {
+	t.Run("Valid", func(t *testing.T) {
+		w, err := ParseWorkflow([]byte(`name: CI
+on:
+  push:
+    branches: [main, 'release/**']
+  pull_request:
+env:
+  GLOBAL: 1
+jobs:
+  test:
+    runs-on: [linux, amd64]
+    needs: lint
+    steps:
+      - name: Test
+        run: make test
+  lint:
+    name: Lint code
+    runs-on: linux
+    steps:
+      - uses: actions/checkout@v3
+        with:
+          fetch-depth: 0
+      - run: |
+          make lint
+          make fmt-check
+`))
+		assert.NoError(t, err)
+		assert.Equal(t, "CI", w.Name)
+		assert.Len(t, w.On, 2)
+		assert.Equal(t, []string{"main", "release/**"}, w.On[EventPush].Branches)
+		assert.NotNil(t, w.On[EventPullRequest])
+		assert.Equal(t, "1", w.Env["GLOBAL"])
+
+		assert.Len(t, w.Jobs, 2)
+		lint := w.Jobs[0]
+		assert.Equal(t, "lint", lint.ID)
+		assert.Equal(t, "Lint code", lint.DisplayName())
+		assert.Equal(t, StringList{"linux"}, lint.RunsOn)
+		assert.Empty(t, lint.Needs)
+		assert.Len(t, lint.Steps, 2)
+		assert.Equal(t, "actions/checkout@v3", lint.Steps[0].DisplayName())
+		assert.Equal(t, "0", lint.Steps[0].With["fetch-depth"])
+		assert.Equal(t, "make lint", lint.Steps[1].DisplayName())
+
+		test := w.Jobs[1]
+		assert.Equal(t, "test", test.ID)
+		assert.Equal(t, "test", test.DisplayName())
+		assert.Equal(t, StringList{"linux", "amd64"}, test.RunsOn)
+		assert.Equal(t, StringList{"lint"}, test.Needs)
+		assert.Equal(t, "Test", test.Steps[0].DisplayName())
+	})
+
+	t.Run("EventList", func(t *testing.T) {
+		w, err := ParseWorkflow([]byte(`on: [push, pull_request]
+jobs:
+  build:
+    runs-on: linux
+    steps:
+      - run: make
+`))
+		assert.NoError(t, err)
+		assert.Len(t, w.On, 2)
+		assert.Empty(t, w.On[EventPush].Branches)
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		cases := []string{
+			`jobs: {build: {steps: [{run: make}]}}`,
+			`on: push`,
+			`on: push
+jobs: {build: {steps: []}}`,
+			`on: push
+jobs: {build: {steps: [{name: empty}]}}`,
+			`on: push
+jobs: {build: {steps: [{run: make, uses: actions/checkout@v3}]}}`,
+			`on: push
+jobs: {build: {needs: test, steps: [{run: make}]}}`,
+			`on: push
+jobs: {a: {needs: b, steps: [{run: make}]}, b: {needs: a, steps: [{run: make}]}}`,
+			`on: [push`,
+		}
+
+		for _, c := range cases {
+			_, err := ParseWorkflow([]byte(c))
+			assert.ErrorIs(t, err, ErrInvalidWorkflow, c)
+		}
+	})
+}
+
+func TestWorkflowMatch(t *testing.T) {
+	w, err := ParseWorkflow([]byte(`on:
+  push:
+    branches: [main, 'release/**']
+    branches-ignore: [release/old]
+  pull_request:
+    branches: [main]
+jobs:
+  build:
+    runs-on: linux
+    steps:
+      - run: make
+`))
+	assert.NoError(t, err)
+
+	assert.True(t, w.Match(EventPush, "refs/heads/main"))
+	assert.True(t, w.Match(EventPush, "refs/heads/release/1.0"))
+	assert.False(t, w.Match(EventPush, "refs/heads/release/old"))
+	assert.False(t, w.Match(EventPush, "refs/heads/feature"))
+	assert.False(t, w.Match(EventPush, "refs/tags/v1.0.0"))
+	assert.True(t, w.Match(EventPullRequest, "main"))
+	assert.False(t, w.Match(EventPullRequest, "feature"))
+	assert.False(t, w.Match("release", "refs/tags/v1.0.0"))
+
+	w, err = ParseWorkflow([]byte(`on:
+  push:
+    tags: ['v*']
+jobs:
+  build:
+    runs-on: linux
+    steps:
+      - run: make
+`))
+	assert.NoError(t, err)
+
+	assert.True(t, w.Match(EventPush, "refs/tags/v1.0.0"))
+	assert.False(t, w.Match(EventPush, "refs/tags/test"))
+	assert.False(t, w.Match(EventPush, "refs/heads/main"))
+
+	w, err = ParseWorkflow([]byte(`on: push
+jobs:
+  build:
+    runs-on: linux
+    steps:
+      - run: make
+`))
+	assert.NoError(t, err)
+
+	assert.True(t, w.Match(EventPush, "refs/heads/feature"))
+	assert.True(t, w.Match(EventPush, "refs/tags/v1.0.0"))
+	assert.False(t, w.Match(EventPullRequest, "main"))
+}
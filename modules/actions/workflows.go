@@ -0,0 +1,86 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package actions
+
+import (
+	"io"
+	"path"
+	"strings"
+
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/log"
+)
+
+// WorkflowsDir is the directory of a repository which contains the workflow files
+const WorkflowsDir = ".gitea/workflows"
+
+const maxWorkflowSize = 1 << 20
+
+// WorkflowFile is a workflow file of a commit
+type WorkflowFile struct {
+	ID       string // the file name
+	Workflow *Workflow
+}
+
+// ListWorkflows parses the workflow files of the commit
+// Invalid workflow files are logged and skipped.
+func ListWorkflows(commit *git.Commit) ([]*WorkflowFile, error) {
+	tree, err := commit.SubTree(WorkflowsDir)
+	if err != nil {
+		if git.IsErrNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	entries, err := tree.ListEntries()
+	if err != nil {
+		return nil, err
+	}
+
+	workflows := make([]*WorkflowFile, 0, len(entries))
+	for _, entry := range entries {
+		if !entry.IsRegular() && !entry.IsExecutable() {
+			continue
+		}
+		if ext := path.Ext(entry.Name()); ext != ".yml" && ext != ".yaml" {
+			continue
+		}
+		if entry.Size() > maxWorkflowSize {
+			log.Warn("Workflow file %s of commit %s is too large", entry.Name(), commit.ID.String())
+			continue
+		}
+
+		content, err := readBlob(entry.Blob())
+		if err != nil {
+			return nil, err
+		}
+
+		w, err := ParseWorkflow(content)
+		if err != nil {
+			log.Warn("Invalid workflow file %s of commit %s: %v", entry.Name(), commit.ID.String(), err)
+			continue
+		}
+		if w.Name == "" {
+			w.Name = strings.TrimSuffix(entry.Name(), path.Ext(entry.Name()))
+		}
+
+		workflows = append(workflows, &WorkflowFile{
+			ID:       entry.Name(),
+			Workflow: w,
+		})
+	}
+	return workflows, nil
+}
+
+func readBlob(blob *git.Blob) ([]byte, error) {
+	rc, err := blob.DataAsync()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	return io.ReadAll(io.LimitReader(rc, maxWorkflowSize))
+}
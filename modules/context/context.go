@@ -73,6 +73,7 @@ type Context struct {
 	Repo        *Repository
 	Org         *Organization
 	Package     *Package
+	Project     *Project
 }
 
 // Close frees all resources hold by Context
@@ -0,0 +1,148 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package context
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"code.gitea.io/gitea/models/organization"
+	"code.gitea.io/gitea/models/perm"
+	access_model "code.gitea.io/gitea/models/perm/access"
+	project_model "code.gitea.io/gitea/models/project"
+	"code.gitea.io/gitea/models/unit"
+	user_model "code.gitea.io/gitea/models/user"
+)
+
+// Project contains the owner of the projects, the access mode and optional the project
+type Project struct {
+	// Owner is the user or organization owning the projects, or the owner of the repository of a repository project
+	Owner      *user_model.User
+	AccessMode perm.AccessMode
+	Project    *project_model.Project
+}
+
+// ProjectAssignment returns a middleware to handle Context.Project assignment
+func ProjectAssignment() func(ctx *Context) {
+	return func(ctx *Context) {
+		projectAssignment(ctx, func(status int, title string, obj interface{}) {
+			err, ok := obj.(error)
+			if !ok {
+				err = fmt.Errorf("%s", obj)
+			}
+			if status == http.StatusNotFound {
+				ctx.NotFound(title, err)
+			} else {
+				ctx.ServerError(title, err)
+			}
+		})
+	}
+}
+
+// ProjectAssignmentAPI returns a middleware to handle Context.Project assignment
+func ProjectAssignmentAPI() func(ctx *APIContext) {
+	return func(ctx *APIContext) {
+		projectAssignment(ctx.Context, ctx.Error)
+	}
+}
+
+// projectAssignment loads the project of the ":id" parameter if there is one, otherwise the projects are the ones
+// of the context user. Nobody but site admins can access projects if the projects unit is globally disabled.
+func projectAssignment(ctx *Context, errCb func(int, string, interface{})) {
+	ctx.Project = &Project{
+		Owner: ctx.ContextUser,
+	}
+
+	if unit.TypeProjects.UnitGlobalDisabled() {
+		errCb(http.StatusNotFound, "ProjectsDisabled", fmt.Errorf("projects are disabled"))
+		return
+	}
+
+	if id := ctx.ParamsInt64(":id"); id > 0 {
+		p, err := project_model.GetProjectByID(id)
+		if err != nil {
+			if project_model.IsErrProjectNotExist(err) {
+				errCb(http.StatusNotFound, "GetProjectByID", err)
+			} else {
+				errCb(http.StatusInternalServerError, "GetProjectByID", err)
+			}
+			return
+		}
+		if err := p.LoadAttributes(ctx); err != nil {
+			errCb(http.StatusInternalServerError, "LoadAttributes", err)
+			return
+		}
+		ctx.Project.Project = p
+
+		// the pages of a user or organization only show their own projects
+		if ctx.ContextUser != nil && p.OwnerID != ctx.ContextUser.ID {
+			errCb(http.StatusNotFound, "GetProjectByID", project_model.ErrProjectNotExist{ID: id})
+			return
+		}
+
+		if p.Type == project_model.TypeRepository {
+			if err := p.Repo.GetOwner(ctx); err != nil {
+				errCb(http.StatusInternalServerError, "GetOwner", err)
+				return
+			}
+			ctx.Project.Owner = p.Repo.Owner
+
+			permission, err := access_model.GetUserRepoPermission(ctx, p.Repo, ctx.Doer)
+			if err != nil {
+				errCb(http.StatusInternalServerError, "GetUserRepoPermission", err)
+				return
+			}
+			ctx.Project.AccessMode = permission.UnitAccessMode(unit.TypeProjects)
+		} else {
+			ctx.Project.Owner = p.Owner
+		}
+	}
+
+	if ctx.Project.Owner == nil {
+		errCb(http.StatusNotFound, "ProjectOwner", fmt.Errorf("projects without owner"))
+		return
+	}
+
+	if ctx.Project.Project == nil || ctx.Project.Project.Type != project_model.TypeRepository {
+		accessMode, err := OwnerProjectsAccessMode(ctx, ctx.Project.Owner, ctx.Doer)
+		if err != nil {
+			errCb(http.StatusInternalServerError, "OwnerProjectsAccessMode", err)
+			return
+		}
+		ctx.Project.AccessMode = accessMode
+	}
+
+	if ctx.Doer != nil && ctx.Doer.IsAdmin {
+		ctx.Project.AccessMode = perm.AccessModeOwner
+	}
+
+	if ctx.Project.AccessMode < perm.AccessModeRead {
+		errCb(http.StatusNotFound, "ProjectAccess", fmt.Errorf("project is not visible"))
+	}
+}
+
+// OwnerProjectsAccessMode returns the access mode of the doer to the projects of a user or organization.
+// Besides the owner, members of an organization get the highest access mode of their teams.
+func OwnerProjectsAccessMode(ctx context.Context, owner, doer *user_model.User) (perm.AccessMode, error) {
+	if doer != nil && (doer.IsAdmin || doer.ID == owner.ID) {
+		return perm.AccessModeOwner, nil
+	}
+	if !organization.HasOrgOrUserVisible(ctx, owner, doer) {
+		return perm.AccessModeNone, nil
+	}
+
+	accessMode := perm.AccessModeRead
+	if owner.IsOrganization() && doer != nil {
+		mode, err := organization.OrgFromUser(owner).GetOrgUserMaxAuthorizeLevel(doer.ID)
+		if err != nil {
+			return perm.AccessModeNone, err
+		}
+		if mode > accessMode {
+			accessMode = mode
+		}
+	}
+	return accessMode, nil
+}
@@ -23,6 +23,7 @@ import (
 	"code.gitea.io/gitea/modules/cache"
 	"code.gitea.io/gitea/modules/git"
 	code_indexer "code.gitea.io/gitea/modules/indexer/code"
+	issue_template "code.gitea.io/gitea/modules/issue/template"
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/markup/markdown"
 	"code.gitea.io/gitea/modules/setting"
@@ -45,6 +46,16 @@ var IssueTemplateDirCandidates = []string{
 	".gitlab/issue_template",
 }
 
+// PullRequestTemplateDirCandidates pull request templates directory
+var PullRequestTemplateDirCandidates = []string{
+	"PULL_REQUEST_TEMPLATE",
+	"pull_request_template",
+	".gitea/PULL_REQUEST_TEMPLATE",
+	".gitea/pull_request_template",
+	".github/PULL_REQUEST_TEMPLATE",
+	".github/pull_request_template",
+}
+
 // PullRequest contains information to make a pull request
 type PullRequest struct {
 	BaseRepo       *repo_model.Repository
@@ -1031,6 +1042,18 @@ func UnitTypes() func(ctx *Context) {
 
 // IssueTemplatesFromDefaultBranch checks for issue templates in the repo's default branch
 func (ctx *Context) IssueTemplatesFromDefaultBranch() []api.IssueTemplate {
+	return ctx.templatesFromDefaultBranch(IssueTemplateDirCandidates, false)
+}
+
+// PullRequestTemplatesFromDefaultBranch checks for pull request templates in the repo's default branch
+func (ctx *Context) PullRequestTemplatesFromDefaultBranch() []api.IssueTemplate {
+	return ctx.templatesFromDefaultBranch(PullRequestTemplateDirCandidates, true)
+}
+
+// templatesFromDefaultBranch reads the templates of the first of the given directories which contains any.
+// Issue templates may also be issue forms written in YAML, pull request templates may omit their metadata
+// and are named after their file then.
+func (ctx *Context) templatesFromDefaultBranch(dirCandidates []string, isPull bool) []api.IssueTemplate {
 	var issueTemplates []api.IssueTemplate
 
 	if ctx.Repo.Repository.IsEmpty {
@@ -1045,7 +1068,7 @@ func (ctx *Context) IssueTemplatesFromDefaultBranch() []api.IssueTemplate {
 		}
 	}
 
-	for _, dirName := range IssueTemplateDirCandidates {
+	for _, dirName := range dirCandidates {
 		tree, err := ctx.Repo.Commit.SubTree(dirName)
 		if err != nil {
 			continue
@@ -1055,9 +1078,10 @@ func (ctx *Context) IssueTemplatesFromDefaultBranch() []api.IssueTemplate {
 			return issueTemplates
 		}
 		for _, entry := range entries {
-			if strings.HasSuffix(entry.Name(), ".md") {
+			isForm := !isPull && issue_template.IsFormFile(entry.Name())
+			if strings.HasSuffix(entry.Name(), ".md") || isForm {
 				if entry.Blob().Size() >= setting.UI.MaxDisplayFileSize {
-					log.Debug("Issue template is too large: %s", entry.Name())
+					log.Debug("Template is too large: %s", entry.Name())
 					continue
 				}
 				r, err := entry.Blob().DataAsync()
@@ -1077,15 +1101,33 @@ func (ctx *Context) IssueTemplatesFromDefaultBranch() []api.IssueTemplate {
 					continue
 				}
 				_ = r.Close()
+				if isForm {
+					it, err := issue_template.Unmarshal(entry.Name(), data)
+					if err != nil {
+						log.Debug("Unmarshal: %v", err)
+						continue
+					}
+					issueTemplates = append(issueTemplates, *it)
+					continue
+				}
 				var it api.IssueTemplate
 				content, err := markdown.ExtractMetadata(string(data), &it)
 				if err != nil {
-					log.Debug("ExtractMetadata: %v", err)
-					continue
+					if !isPull {
+						log.Debug("ExtractMetadata: %v", err)
+						continue
+					}
+					it = api.IssueTemplate{}
+					content = string(data)
 				}
 				it.Content = content
 				it.FileName = entry.Name()
-				if it.Valid() {
+				// only issue forms have fields
+				it.Body = nil
+				if isPull && strings.TrimSpace(it.Name) == "" {
+					it.Name = strings.TrimSuffix(entry.Name(), ".md")
+				}
+				if isPull || it.Valid() {
 					issueTemplates = append(issueTemplates, it)
 				}
 			}
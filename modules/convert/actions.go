@@ -0,0 +1,29 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package convert
+
+import (
+	actions_model "code.gitea.io/gitea/models/actions"
+	api "code.gitea.io/gitea/modules/structs"
+)
+
+// ToActionRunner converts an actions_model.ActionRunner to api.ActionRunner
+func ToActionRunner(r *actions_model.ActionRunner) *api.ActionRunner {
+	apiRunner := &api.ActionRunner{
+		ID:      r.ID,
+		Name:    r.Name,
+		Labels:  r.Labels,
+		Token:   r.Token,
+		Created: r.CreatedUnix.AsTime(),
+	}
+	if apiRunner.Labels == nil {
+		apiRunner.Labels = []string{}
+	}
+	if r.LastOnlineUnix != 0 {
+		lastOnline := r.LastOnlineUnix.AsTime()
+		apiRunner.LastOnline = &lastOnline
+	}
+	return apiRunner
+}
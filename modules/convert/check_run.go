@@ -0,0 +1,72 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package convert
+
+import (
+	"context"
+
+	checks_model "code.gitea.io/gitea/models/checks"
+	user_model "code.gitea.io/gitea/models/user"
+	api "code.gitea.io/gitea/modules/structs"
+)
+
+// ToCheckRun converts a check run to api.CheckRun
+func ToCheckRun(ctx context.Context, run *checks_model.CheckRun) (*api.CheckRun, error) {
+	if err := run.LoadRepo(ctx); err != nil {
+		return nil, err
+	}
+
+	apiRun := &api.CheckRun{
+		ID:         run.ID,
+		Name:       run.Name,
+		HeadSHA:    run.HeadSHA,
+		ExternalID: run.ExternalID,
+		DetailsURL: run.DetailsURL,
+		HTMLURL:    run.HTMLURL(),
+		URL:        run.APIURL(),
+		Status:     string(run.Status),
+		Conclusion: string(run.Conclusion),
+		Output: &api.CheckRunOutput{
+			Title:            run.Title,
+			Summary:          run.Summary,
+			Text:             run.Text,
+			AnnotationsCount: run.AnnotationsCount,
+		},
+		Created: run.CreatedUnix.AsTime(),
+		Updated: run.UpdatedUnix.AsTime(),
+	}
+	if run.StartedUnix != 0 {
+		startedAt := run.StartedUnix.AsTime()
+		apiRun.StartedAt = &startedAt
+	}
+	if run.CompletedUnix != 0 {
+		completedAt := run.CompletedUnix.AsTime()
+		apiRun.CompletedAt = &completedAt
+	}
+
+	creator, err := user_model.GetUserByIDCtx(ctx, run.CreatorID)
+	if err != nil {
+		if !user_model.IsErrUserNotExist(err) {
+			return nil, err
+		}
+		creator = user_model.NewGhostUser()
+	}
+	apiRun.Creator = ToUser(creator, nil)
+
+	return apiRun, nil
+}
+
+// ToCheckAnnotation converts a check annotation to api.CheckAnnotation
+func ToCheckAnnotation(annotation *checks_model.CheckAnnotation) *api.CheckAnnotation {
+	return &api.CheckAnnotation{
+		Path:            annotation.Path,
+		StartLine:       annotation.StartLine,
+		EndLine:         annotation.EndLine,
+		AnnotationLevel: string(annotation.Level),
+		Title:           annotation.Title,
+		Message:         annotation.Message,
+		RawDetails:      annotation.RawDetails,
+	}
+}
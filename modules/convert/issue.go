@@ -214,6 +214,46 @@ func ToLabelList(labels []*models.Label, repo *repo_model.Repository, org *user_
 	return result
 }
 
+// ToCustomField converts CustomField to API format
+func ToCustomField(f *issues_model.CustomField) *api.CustomField {
+	options := f.Options
+	if options == nil {
+		options = []string{}
+	}
+	return &api.CustomField{
+		ID:          f.ID,
+		Name:        f.Name,
+		Description: f.Description,
+		Type:        f.Type.Name(),
+		Options:     options,
+		IsOrgField:  f.BelongsToOrg(),
+	}
+}
+
+// ToCustomFieldList converts list of CustomField to API format
+func ToCustomFieldList(fields []*issues_model.CustomField) []*api.CustomField {
+	result := make([]*api.CustomField, len(fields))
+	for i := range fields {
+		result[i] = ToCustomField(fields[i])
+	}
+	return result
+}
+
+// ToIssueCustomFieldValueList converts the values of the custom fields of an issue to API format,
+// the fields and users of the values have to be loaded
+func ToIssueCustomFieldValueList(values []*issues_model.CustomFieldValue) []*api.IssueCustomFieldValue {
+	result := make([]*api.IssueCustomFieldValue, len(values))
+	for i, v := range values {
+		result[i] = &api.IssueCustomFieldValue{
+			FieldID: v.FieldID,
+			Name:    v.Field.Name,
+			Type:    v.Field.Type.Name(),
+			Value:   v.DisplayValue(),
+		}
+	}
+	return result
+}
+
 // ToAPIMilestone converts Milestone into API Format
 func ToAPIMilestone(m *issues_model.Milestone) *api.Milestone {
 	apiMilestone := &api.Milestone{
@@ -51,3 +51,18 @@ func ToPackageFile(pfd *packages.PackageFileDescriptor) *api.PackageFile {
 		HashSHA512: pfd.Blob.HashSHA512,
 	}
 }
+
+// ToPackageCleanupRule converts packages.PackageCleanupRule to api.PackageCleanupRule
+func ToPackageCleanupRule(pcr *packages.PackageCleanupRule) *api.PackageCleanupRule {
+	return &api.PackageCleanupRule{
+		ID:          pcr.ID,
+		Enabled:     pcr.Enabled,
+		Type:        string(pcr.Type),
+		NamePattern: pcr.NamePattern,
+		KeepCount:   pcr.KeepCount,
+		KeepPattern: pcr.KeepPattern,
+		RemoveDays:  pcr.RemoveDays,
+		Created:     pcr.CreatedUnix.AsTime(),
+		Updated:     pcr.UpdatedUnix.AsTime(),
+	}
+}
@@ -0,0 +1,57 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package convert
+
+import (
+	project_model "code.gitea.io/gitea/models/project"
+	user_model "code.gitea.io/gitea/models/user"
+	api "code.gitea.io/gitea/modules/structs"
+)
+
+// ToAPIProject converts a project to api.Project, the attributes of the project have to be loaded
+func ToAPIProject(p *project_model.Project, doer *user_model.User) *api.Project {
+	apiProject := &api.Project{
+		ID:           p.ID,
+		Title:        p.Title,
+		Description:  p.Description,
+		Type:         p.Type.Name(),
+		BoardType:    p.BoardType.Name(),
+		State:        api.StateOpen,
+		Creator:      ToUser(p.Creator, doer),
+		OpenIssues:   p.NumOpenIssues(),
+		ClosedIssues: p.NumClosedIssues(),
+		Created:      p.CreatedUnix.AsTime(),
+		Updated:      p.UpdatedUnix.AsTime(),
+	}
+	if p.IsClosed {
+		apiProject.State = api.StateClosed
+		apiProject.Closed = p.ClosedDateUnix.AsTimePtr()
+	}
+	if p.Repo != nil {
+		apiProject.Repo = &api.RepositoryMeta{
+			ID:       p.Repo.ID,
+			Name:     p.Repo.Name,
+			Owner:    p.Repo.OwnerName,
+			FullName: p.Repo.FullName(),
+		}
+	}
+	if p.Owner != nil {
+		apiProject.Owner = ToUser(p.Owner, doer)
+	}
+	return apiProject
+}
+
+// ToAPIProjectBoard converts a project board to api.ProjectBoard
+func ToAPIProjectBoard(b *project_model.Board) *api.ProjectBoard {
+	return &api.ProjectBoard{
+		ID:      b.ID,
+		Title:   b.Title,
+		Color:   b.Color,
+		Sorting: int(b.Sorting),
+		Default: b.Default,
+		Created: b.CreatedUnix.AsTime(),
+		Updated: b.UpdatedUnix.AsTime(),
+	}
+}
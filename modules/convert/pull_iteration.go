@@ -0,0 +1,28 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package convert
+
+import (
+	"context"
+
+	pull_model "code.gitea.io/gitea/models/pull"
+	user_model "code.gitea.io/gitea/models/user"
+	api "code.gitea.io/gitea/modules/structs"
+)
+
+// ToPullRequestIteration converts an iteration of a pull request to api format
+func ToPullRequestIteration(ctx context.Context, it *pull_model.Iteration, doer *user_model.User) (*api.PullRequestIteration, error) {
+	if err := it.LoadPusher(ctx); err != nil {
+		return nil, err
+	}
+	return &api.PullRequestIteration{
+		Index:       it.Index,
+		Pusher:      ToUser(it.Pusher, doer),
+		HeadSHA:     it.HeadCommitID,
+		MergeBase:   it.MergeBase,
+		IsForcePush: it.IsForcePush,
+		Created:     it.CreatedUnix.AsTime(),
+	}, nil
+}
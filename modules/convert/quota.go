@@ -0,0 +1,29 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package convert
+
+import (
+	quota_model "code.gitea.io/gitea/models/quota"
+	api "code.gitea.io/gitea/modules/structs"
+)
+
+// ToQuota converts a quota and the usage to api.Quota
+func ToQuota(q *quota_model.Quota, u *quota_model.Usage) *api.Quota {
+	return &api.Quota{
+		IsDefault:        q.IsDefault(),
+		LimitTotal:       q.LimitTotal,
+		LimitGit:         q.LimitGit,
+		LimitLFS:         q.LimitLFS,
+		LimitPackages:    q.LimitPackages,
+		LimitAttachments: q.LimitAttachments,
+		Usage: &api.QuotaUsage{
+			Total:       u.Total(),
+			Git:         u.Git,
+			LFS:         u.LFS,
+			Packages:    u.Packages,
+			Attachments: u.Attachments,
+		},
+	}
+}
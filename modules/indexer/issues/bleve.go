@@ -28,7 +28,7 @@ import (
 const (
 	issueIndexerAnalyzer      = "issueIndexer"
 	issueIndexerDocType       = "issueIndexerDocType"
-	issueIndexerLatestVersion = 1
+	issueIndexerLatestVersion = 2
 )
 
 // indexerID a bleve-compatible unique identifier for an integer id
@@ -128,6 +128,7 @@ func createIssueIndexer(path string, latestVersion int) (bleve.Index, error) {
 	docMapping.AddFieldMappingsAt("Title", textFieldMapping)
 	docMapping.AddFieldMappingsAt("Content", textFieldMapping)
 	docMapping.AddFieldMappingsAt("Comments", textFieldMapping)
+	docMapping.AddFieldMappingsAt("CustomFields", textFieldMapping)
 
 	if err := addUnicodeNormalizeTokenFilter(mapping); err != nil {
 		return nil, err
@@ -210,15 +211,17 @@ func (b *BleveIndexer) Index(issues []*IndexerData) error {
 	batch := gitea_bleve.NewFlushingBatch(b.indexer, maxBatchSize)
 	for _, issue := range issues {
 		if err := batch.Index(indexerID(issue.ID), struct {
-			RepoID   int64
-			Title    string
-			Content  string
-			Comments []string
+			RepoID       int64
+			Title        string
+			Content      string
+			Comments     []string
+			CustomFields []string
 		}{
-			RepoID:   issue.RepoID,
-			Title:    issue.Title,
-			Content:  issue.Content,
-			Comments: issue.Comments,
+			RepoID:       issue.RepoID,
+			Title:        issue.Title,
+			Content:      issue.Content,
+			Comments:     issue.Comments,
+			CustomFields: issue.CustomFields,
 		}); err != nil {
 			return err
 		}
@@ -255,6 +258,7 @@ func (b *BleveIndexer) Search(ctx context.Context, keyword string, repoIDs []int
 			newMatchPhraseQuery(keyword, "Title", issueIndexerAnalyzer),
 			newMatchPhraseQuery(keyword, "Content", issueIndexerAnalyzer),
 			newMatchPhraseQuery(keyword, "Comments", issueIndexerAnalyzer),
+			newMatchPhraseQuery(keyword, "CustomFields", issueIndexerAnalyzer),
 		))
 	search := bleve.NewSearchRequestOptions(indexerQuery, limit, start, false)
 	search.SortBy([]string{"-_score"})
@@ -50,6 +50,9 @@ func TestBleveIndexAndSearch(t *testing.T) {
 				"LGTM",
 				"Good idea",
 			},
+			CustomFields: []string{
+				"ACME Corporation",
+			},
 		},
 	})
 	assert.NoError(t, err)
@@ -78,6 +81,10 @@ func TestBleveIndexAndSearch(t *testing.T) {
 			Keyword: "chinese",
 			IDs:     []int64{1, 2},
 		},
+		{
+			Keyword: "acme",
+			IDs:     []int64{2},
+		},
 		{
 			Keyword: "help",
 			IDs:     []int64{},
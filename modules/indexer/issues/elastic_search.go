@@ -109,6 +109,10 @@ const (
 				"comments": {
 					"type" : "text",
 					"index": true
+				},
+				"custom_fields": {
+					"type" : "text",
+					"index": true
 				}
 			}
 		}
@@ -163,11 +167,12 @@ func (b *ElasticSearchIndexer) Index(issues []*IndexerData) error {
 			Index(b.indexerName).
 			Id(fmt.Sprintf("%d", issue.ID)).
 			BodyJson(map[string]interface{}{
-				"id":       issue.ID,
-				"repo_id":  issue.RepoID,
-				"title":    issue.Title,
-				"content":  issue.Content,
-				"comments": issue.Comments,
+				"id":            issue.ID,
+				"repo_id":       issue.RepoID,
+				"title":         issue.Title,
+				"content":       issue.Content,
+				"comments":      issue.Comments,
+				"custom_fields": issue.CustomFields,
 			}).
 			Do(graceful.GetManager().HammerContext())
 		return b.checkError(err)
@@ -180,11 +185,12 @@ func (b *ElasticSearchIndexer) Index(issues []*IndexerData) error {
 				Index(b.indexerName).
 				Id(fmt.Sprintf("%d", issue.ID)).
 				Doc(map[string]interface{}{
-					"id":       issue.ID,
-					"repo_id":  issue.RepoID,
-					"title":    issue.Title,
-					"content":  issue.Content,
-					"comments": issue.Comments,
+					"id":            issue.ID,
+					"repo_id":       issue.RepoID,
+					"title":         issue.Title,
+					"content":       issue.Content,
+					"comments":      issue.Comments,
+					"custom_fields": issue.CustomFields,
 				}),
 		)
 	}
@@ -227,7 +233,7 @@ func (b *ElasticSearchIndexer) Delete(ids ...int64) error {
 // Search searches for issues by given conditions.
 // Returns the matching issue IDs
 func (b *ElasticSearchIndexer) Search(ctx context.Context, keyword string, repoIDs []int64, limit, start int) (*SearchResult, error) {
-	kwQuery := elastic.NewMultiMatchQuery(keyword, "title", "content", "comments")
+	kwQuery := elastic.NewMultiMatchQuery(keyword, "title", "content", "comments", "custom_fields")
 	query := elastic.NewBoolQuery()
 	query = query.Must(kwQuery)
 	if len(repoIDs) > 0 {
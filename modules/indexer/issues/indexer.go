@@ -14,6 +14,7 @@ import (
 
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/models/db"
+	issues_model "code.gitea.io/gitea/models/issues"
 	repo_model "code.gitea.io/gitea/models/repo"
 	"code.gitea.io/gitea/modules/graceful"
 	"code.gitea.io/gitea/modules/log"
@@ -25,13 +26,14 @@ import (
 
 // IndexerData data stored in the issue indexer
 type IndexerData struct {
-	ID       int64    `json:"id"`
-	RepoID   int64    `json:"repo_id"`
-	Title    string   `json:"title"`
-	Content  string   `json:"content"`
-	Comments []string `json:"comments"`
-	IsDelete bool     `json:"is_delete"`
-	IDs      []int64  `json:"ids"`
+	ID           int64    `json:"id"`
+	RepoID       int64    `json:"repo_id"`
+	Title        string   `json:"title"`
+	Content      string   `json:"content"`
+	Comments     []string `json:"comments"`
+	CustomFields []string `json:"custom_fields"`
+	IsDelete     bool     `json:"is_delete"`
+	IDs          []int64  `json:"ids"`
 }
 
 // Match represents on search result
@@ -346,12 +348,21 @@ func UpdateIssueIndexer(issue *models.Issue) {
 			comments = append(comments, comment.Content)
 		}
 	}
+	var customFields []string
+	values, err := issues_model.GetCustomFieldValues(db.DefaultContext, issue.ID)
+	if err != nil {
+		log.Error("GetCustomFieldValues: %v", err)
+	}
+	for _, value := range values {
+		customFields = append(customFields, value.DisplayValue())
+	}
 	indexerData := &IndexerData{
-		ID:       issue.ID,
-		RepoID:   issue.RepoID,
-		Title:    issue.Title,
-		Content:  issue.Content,
-		Comments: comments,
+		ID:           issue.ID,
+		RepoID:       issue.RepoID,
+		Title:        issue.Title,
+		Content:      issue.Content,
+		Comments:     comments,
+		CustomFields: customFields,
 	}
 	log.Debug("Adding to channel: %v", indexerData)
 	if err := issueIndexerQueue.Push(indexerData); err != nil {
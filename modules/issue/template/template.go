@@ -0,0 +1,214 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package template
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+
+	api "code.gitea.io/gitea/modules/structs"
+
+	"gopkg.in/yaml.v2"
+)
+
+var validFieldID = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+
+// yamlTemplate accepts description, which is the name of about in issue forms
+type yamlTemplate struct {
+	api.IssueTemplate `yaml:",inline"`
+	Description       string `yaml:"description"`
+}
+
+// IsFormFile returns whether the file is an issue form, which is written in YAML
+func IsFormFile(filename string) bool {
+	ext := strings.ToLower(path.Ext(filename))
+	return ext == ".yaml" || ext == ".yml"
+}
+
+// Unmarshal parses and validates an issue form
+func Unmarshal(filename string, content []byte) (*api.IssueTemplate, error) {
+	var t yamlTemplate
+	if err := yaml.Unmarshal(content, &t); err != nil {
+		return nil, err
+	}
+
+	it := t.IssueTemplate
+	it.FileName = filename
+	if it.About == "" {
+		it.About = t.Description
+	}
+	for i, field := range it.Body {
+		if field != nil && field.ID == "" {
+			field.ID = strconv.Itoa(i)
+		}
+	}
+
+	if err := Validate(&it); err != nil {
+		return nil, fmt.Errorf("invalid issue form %s: %v", filename, err)
+	}
+	return &it, nil
+}
+
+// Validate checks whether an issue form has a name, an about and valid fields
+func Validate(it *api.IssueTemplate) error {
+	if strings.TrimSpace(it.Name) == "" {
+		return fmt.Errorf("name is required")
+	}
+	if strings.TrimSpace(it.About) == "" {
+		return fmt.Errorf("about is required")
+	}
+	if len(it.Body) == 0 {
+		return fmt.Errorf("body is required")
+	}
+
+	ids := make(map[string]bool, len(it.Body))
+	for i, field := range it.Body {
+		if field == nil {
+			return fmt.Errorf("body[%d]: field is empty", i)
+		}
+		if !validFieldID.MatchString(field.ID) {
+			return fmt.Errorf("body[%d]: invalid id %q", i, field.ID)
+		}
+		if ids[field.ID] {
+			return fmt.Errorf("body[%d]: duplicate id %q", i, field.ID)
+		}
+		ids[field.ID] = true
+
+		switch field.Type {
+		case api.IssueFormFieldTypeMarkdown:
+			if strings.TrimSpace(field.Attributes.Value) == "" {
+				return fmt.Errorf("body[%d]: value is required", i)
+			}
+			continue
+		case api.IssueFormFieldTypeInput, api.IssueFormFieldTypeTextarea:
+		case api.IssueFormFieldTypeDropdown, api.IssueFormFieldTypeCheckboxes:
+			if len(field.Attributes.Options) == 0 {
+				return fmt.Errorf("body[%d]: options are required", i)
+			}
+			for j, option := range field.Attributes.Options {
+				if strings.TrimSpace(option.Label) == "" {
+					return fmt.Errorf("body[%d]: options[%d]: label is required", i, j)
+				}
+			}
+		default:
+			return fmt.Errorf("body[%d]: unknown type %q", i, field.Type)
+		}
+
+		if strings.TrimSpace(field.Attributes.Label) == "" {
+			return fmt.Errorf("body[%d]: label is required", i)
+		}
+	}
+	return nil
+}
+
+// FieldName returns the name of the form input of a field,
+// the input of the option of checkboxes with the index is named after the field and the index
+func FieldName(field *api.IssueFormField) string {
+	return "form-field-" + field.ID
+}
+
+func checkboxName(field *api.IssueFormField, index int) string {
+	return FieldName(field) + "-" + strconv.Itoa(index)
+}
+
+// MissingRequiredFields returns the labels of the required fields which have no value,
+// and of the required checkboxes which are not checked
+func MissingRequiredFields(it *api.IssueTemplate, values url.Values) []string {
+	var missing []string
+	for _, field := range it.Body {
+		switch field.Type {
+		case api.IssueFormFieldTypeMarkdown:
+		case api.IssueFormFieldTypeCheckboxes:
+			for i, option := range field.Attributes.Options {
+				if option.Required && values.Get(checkboxName(field, i)) == "" {
+					missing = append(missing, option.Label)
+				}
+			}
+		default:
+			if field.Validations.Required && len(fieldValues(field, values)) == 0 {
+				missing = append(missing, field.Attributes.Label)
+			}
+		}
+	}
+	return missing
+}
+
+// fieldValues returns the non-empty values of a field, for dropdowns only the labels of its options
+func fieldValues(field *api.IssueFormField, values url.Values) []string {
+	if field.Type != api.IssueFormFieldTypeDropdown {
+		if value := strings.TrimSpace(values.Get(FieldName(field))); value != "" {
+			return []string{value}
+		}
+		return nil
+	}
+
+	selected := make(map[string]bool)
+	for _, value := range values[FieldName(field)] {
+		selected[value] = true
+	}
+	var labels []string
+	for _, option := range field.Attributes.Options {
+		if selected[option.Label] {
+			labels = append(labels, option.Label)
+			if !field.Attributes.Multiple {
+				break
+			}
+		}
+	}
+	return labels
+}
+
+// RenderToMarkdown renders the submitted values of an issue form to the Markdown body of an issue,
+// every field but the markdown ones becomes a heading with its label followed by its value
+func RenderToMarkdown(it *api.IssueTemplate, values url.Values) string {
+	var builder strings.Builder
+	for _, field := range it.Body {
+		if field.Type == api.IssueFormFieldTypeMarkdown {
+			continue
+		}
+
+		if builder.Len() > 0 {
+			builder.WriteString("\n\n")
+		}
+		builder.WriteString("### ")
+		builder.WriteString(field.Attributes.Label)
+		builder.WriteString("\n\n")
+
+		if field.Type == api.IssueFormFieldTypeCheckboxes {
+			for i, option := range field.Attributes.Options {
+				if i > 0 {
+					builder.WriteString("\n")
+				}
+				if values.Get(checkboxName(field, i)) != "" {
+					builder.WriteString("- [x] ")
+				} else {
+					builder.WriteString("- [ ] ")
+				}
+				builder.WriteString(option.Label)
+			}
+			continue
+		}
+
+		fieldValues := fieldValues(field, values)
+		switch {
+		case len(fieldValues) == 0:
+			builder.WriteString("_No response_")
+		case field.Type == api.IssueFormFieldTypeTextarea && field.Attributes.Render != "":
+			builder.WriteString("```")
+			builder.WriteString(field.Attributes.Render)
+			builder.WriteString("\n")
+			builder.WriteString(fieldValues[0])
+			builder.WriteString("\n```")
+		default:
+			builder.WriteString(strings.Join(fieldValues, ", "))
+		}
+	}
+	builder.WriteString("\n")
+	return builder.String()
+}
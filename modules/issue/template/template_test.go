@@ -0,0 +1,135 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package template
+
+import (
+	"net/url"
+	"testing"
+
+	api "code.gitea.io/gitea/modules/structs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const bugReport = `name: Bug Report
+description: File a bug report
+title: "[Bug]: "
+labels: ["bug"]
+body:
+  - type: markdown
+    attributes:
+      value: Thanks for taking the time to fill out this bug report!
+  - type: input
+    id: version
+    attributes:
+      label: Version
+      placeholder: "1.17.0"
+    validations:
+      required: true
+  - type: textarea
+    id: logs
+    attributes:
+      label: Logs
+      render: shell
+  - type: dropdown
+    id: browsers
+    attributes:
+      label: Browsers
+      multiple: true
+      options:
+        - Firefox
+        - Chrome
+        - Safari
+  - type: checkboxes
+    attributes:
+      label: Code of Conduct
+      options:
+        - label: I agree to follow the Code of Conduct
+          required: true
+        - label: I searched for existing issues
+`
+
+func TestUnmarshal(t *testing.T) {
+	it, err := Unmarshal("bug.yaml", []byte(bugReport))
+	assert.NoError(t, err)
+	assert.Equal(t, "Bug Report", it.Name)
+	assert.Equal(t, "File a bug report", it.About)
+	assert.Equal(t, "[Bug]: ", it.Title)
+	assert.Equal(t, []string{"bug"}, it.Labels)
+	assert.Equal(t, "bug.yaml", it.FileName)
+	assert.True(t, it.IsForm())
+	assert.True(t, it.Valid())
+
+	if assert.Len(t, it.Body, 5) {
+		assert.Equal(t, api.IssueFormFieldTypeMarkdown, it.Body[0].Type)
+		assert.Equal(t, "0", it.Body[0].ID)
+		assert.Equal(t, "version", it.Body[1].ID)
+		assert.True(t, it.Body[1].Validations.Required)
+		assert.Equal(t, "shell", it.Body[2].Attributes.Render)
+		assert.True(t, it.Body[3].Attributes.Multiple)
+		assert.Equal(t, []api.IssueFormFieldOption{{Label: "Firefox"}, {Label: "Chrome"}, {Label: "Safari"}}, it.Body[3].Attributes.Options)
+		assert.Equal(t, "4", it.Body[4].ID)
+		assert.Equal(t, []api.IssueFormFieldOption{
+			{Label: "I agree to follow the Code of Conduct", Required: true},
+			{Label: "I searched for existing issues"},
+		}, it.Body[4].Attributes.Options)
+	}
+}
+
+func TestUnmarshalInvalid(t *testing.T) {
+	for name, content := range map[string]string{
+		"no name":       "about: a\nbody:\n  - type: input\n    attributes:\n      label: a\n",
+		"no about":      "name: a\nbody:\n  - type: input\n    attributes:\n      label: a\n",
+		"no body":       "name: a\nabout: a\n",
+		"unknown type":  "name: a\nabout: a\nbody:\n  - type: date\n    attributes:\n      label: a\n",
+		"no label":      "name: a\nabout: a\nbody:\n  - type: input\n",
+		"no value":      "name: a\nabout: a\nbody:\n  - type: markdown\n",
+		"no options":    "name: a\nabout: a\nbody:\n  - type: dropdown\n    attributes:\n      label: a\n",
+		"invalid id":    "name: a\nabout: a\nbody:\n  - type: input\n    id: a b\n    attributes:\n      label: a\n",
+		"duplicate id":  "name: a\nabout: a\nbody:\n  - type: input\n    id: a\n    attributes:\n      label: a\n  - type: input\n    id: a\n    attributes:\n      label: b\n",
+		"invalid yaml":  "name: [a\n",
+		"empty options": "name: a\nabout: a\nbody:\n  - type: checkboxes\n    attributes:\n      label: a\n      options:\n        - label: \"\"\n",
+	} {
+		_, err := Unmarshal("invalid.yaml", []byte(content))
+		assert.Error(t, err, name)
+	}
+}
+
+func TestMissingRequiredFields(t *testing.T) {
+	it, err := Unmarshal("bug.yaml", []byte(bugReport))
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{"Version", "I agree to follow the Code of Conduct"}, MissingRequiredFields(it, url.Values{}))
+	assert.Equal(t, []string{"Version"}, MissingRequiredFields(it, url.Values{
+		"form-field-version": {"  "},
+		"form-field-4-0":     {"on"},
+	}))
+	assert.Empty(t, MissingRequiredFields(it, url.Values{
+		"form-field-version": {"1.17.0"},
+		"form-field-4-0":     {"on"},
+	}))
+}
+
+func TestRenderToMarkdown(t *testing.T) {
+	it, err := Unmarshal("bug.yaml", []byte(bugReport))
+	assert.NoError(t, err)
+
+	assert.Equal(t, "### Version\n\n1.17.0\n\n"+
+		"### Logs\n\n```shell\npanic: oops\n```\n\n"+
+		"### Browsers\n\nFirefox, Safari\n\n"+
+		"### Code of Conduct\n\n- [x] I agree to follow the Code of Conduct\n- [ ] I searched for existing issues\n",
+		RenderToMarkdown(it, url.Values{
+			"form-field-version":  {"1.17.0"},
+			"form-field-logs":     {"panic: oops"},
+			"form-field-browsers": {"Safari", "Firefox", "Opera"},
+			"form-field-4-0":      {"on"},
+		}))
+
+	assert.Equal(t, "### Version\n\n_No response_\n\n"+
+		"### Logs\n\n_No response_\n\n"+
+		"### Browsers\n\n_No response_\n\n"+
+		"### Code of Conduct\n\n- [ ] I agree to follow the Code of Conduct\n- [ ] I searched for existing issues\n",
+		RenderToMarkdown(it, url.Values{}))
+}
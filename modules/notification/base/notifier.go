@@ -33,6 +33,7 @@ type Notifier interface {
 	NotifyIssueChangeRef(doer *user_model.User, issue *models.Issue, oldRef string)
 	NotifyIssueChangeLabels(doer *user_model.User, issue *models.Issue,
 		addedLabels, removedLabels []*models.Label)
+	NotifyIssueChangeCustomFields(doer *user_model.User, issue *models.Issue)
 	NotifyNewPullRequest(pr *models.PullRequest, mentions []*user_model.User)
 	NotifyMergePullRequest(*models.PullRequest, *user_model.User)
 	NotifyPullRequestSynchronized(doer *user_model.User, pr *models.PullRequest)
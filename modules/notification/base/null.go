@@ -123,6 +123,10 @@ func (*NullNotifier) NotifyIssueChangeLabels(doer *user_model.User, issue *model
 	addedLabels, removedLabels []*models.Label) {
 }
 
+// NotifyIssueChangeCustomFields places a place holder function
+func (*NullNotifier) NotifyIssueChangeCustomFields(doer *user_model.User, issue *models.Issue) {
+}
+
 // NotifyCreateRepository places a place holder function
 func (*NullNotifier) NotifyCreateRepository(doer, u *user_model.User, repo *repo_model.Repository) {
 }
@@ -153,3 +153,13 @@ func (r *indexerNotifier) NotifyIssueChangeTitle(doer *user_model.User, issue *m
 func (r *indexerNotifier) NotifyIssueChangeRef(doer *user_model.User, issue *models.Issue, oldRef string) {
 	issue_indexer.UpdateIssueIndexer(issue)
 }
+
+func (r *indexerNotifier) NotifyIssueChangeCustomFields(doer *user_model.User, issue *models.Issue) {
+	if issue.Comments == nil {
+		if err := issue.LoadDiscussComments(); err != nil {
+			log.Error("LoadComments failed: %v", err)
+			return
+		}
+	}
+	issue_indexer.UpdateIssueIndexer(issue)
+}
@@ -13,6 +13,7 @@ import (
 	"code.gitea.io/gitea/modules/notification/base"
 	"code.gitea.io/gitea/modules/notification/indexer"
 	"code.gitea.io/gitea/modules/notification/mail"
+	"code.gitea.io/gitea/modules/notification/project"
 	"code.gitea.io/gitea/modules/notification/ui"
 	"code.gitea.io/gitea/modules/notification/webhook"
 	"code.gitea.io/gitea/modules/repository"
@@ -36,6 +37,7 @@ func NewContext() {
 	RegisterNotifier(indexer.NewNotifier())
 	RegisterNotifier(webhook.NewNotifier())
 	RegisterNotifier(action.NewNotifier())
+	RegisterNotifier(project.NewNotifier())
 }
 
 // NotifyCreateIssueComment notifies issue comment related message to notifiers
@@ -217,6 +219,13 @@ func NotifyIssueChangeLabels(doer *user_model.User, issue *models.Issue,
 	}
 }
 
+// NotifyIssueChangeCustomFields notifies change of the values of custom fields to notifiers
+func NotifyIssueChangeCustomFields(doer *user_model.User, issue *models.Issue) {
+	for _, notifier := range notifiers {
+		notifier.NotifyIssueChangeCustomFields(doer, issue)
+	}
+}
+
 // NotifyCreateRepository notifies create repository to notifiers
 func NotifyCreateRepository(doer, u *user_model.User, repo *repo_model.Repository) {
 	for _, notifier := range notifiers {
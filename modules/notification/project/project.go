@@ -0,0 +1,144 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package project
+
+import (
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/models/db"
+	project_model "code.gitea.io/gitea/models/project"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/notification/base"
+)
+
+type projectNotifier struct {
+	base.NullNotifier
+}
+
+var _ base.Notifier = &projectNotifier{}
+
+// NewNotifier create a new projectNotifier notifier which executes the automation rules of projects
+func NewNotifier() base.Notifier {
+	return &projectNotifier{}
+}
+
+func (n *projectNotifier) NotifyIssueChangeStatus(doer *user_model.User, issue *models.Issue, actionComment *models.Comment, isClosed bool) {
+	trigger := project_model.AutomationTriggerIssueReopened
+	if isClosed {
+		trigger = project_model.AutomationTriggerIssueClosed
+	}
+	if err := applyAutomationRules(issue, trigger); err != nil {
+		log.Error("applyAutomationRules[%d]: %v", issue.ID, err)
+	}
+}
+
+func (n *projectNotifier) NotifyNewPullRequest(pr *models.PullRequest, mentions []*user_model.User) {
+	if err := pr.LoadIssue(); err != nil {
+		log.Error("LoadIssue: %v", err)
+		return
+	}
+
+	issueIDs, err := pr.ReferencedIssueIDs(db.DefaultContext)
+	if err != nil {
+		log.Error("ReferencedIssueIDs[%d]: %v", pr.ID, err)
+		return
+	}
+	if len(issueIDs) == 0 {
+		return
+	}
+
+	issues, err := models.GetIssuesByIDs(issueIDs)
+	if err != nil {
+		log.Error("GetIssuesByIDs: %v", err)
+		return
+	}
+	for _, issue := range issues {
+		if err := applyAutomationRules(issue, project_model.AutomationTriggerPullOpened); err != nil {
+			log.Error("applyAutomationRules[%d]: %v", issue.ID, err)
+		}
+	}
+}
+
+func (n *projectNotifier) NotifyIssueChangeLabels(doer *user_model.User, issue *models.Issue,
+	addedLabels, removedLabels []*models.Label,
+) {
+	if len(addedLabels) == 0 {
+		return
+	}
+
+	labelIDs := make([]int64, 0, len(addedLabels))
+	for _, label := range addedLabels {
+		labelIDs = append(labelIDs, label.ID)
+	}
+	rules, err := project_model.GetLabelAutomationRules(db.DefaultContext, labelIDs)
+	if err != nil {
+		log.Error("GetLabelAutomationRules: %v", err)
+		return
+	}
+
+	projectID := issue.ProjectID()
+	for _, rule := range rules {
+		// an issue belongs to at most one project, the rules of other projects are ignored
+		if projectID > 0 && projectID != rule.ProjectID {
+			continue
+		}
+
+		if projectID == 0 {
+			p, err := project_model.GetProjectByID(rule.ProjectID)
+			if err != nil {
+				log.Error("GetProjectByID[%d]: %v", rule.ProjectID, err)
+				continue
+			}
+			if p.IsClosed {
+				continue
+			}
+			if ok, err := issue.CanBeAddedToProject(db.DefaultContext, p); err != nil {
+				log.Error("CanBeAddedToProject[%d]: %v", issue.ID, err)
+				return
+			} else if !ok {
+				continue
+			}
+
+			if err := models.ChangeProjectAssign(issue, doer, p.ID); err != nil {
+				log.Error("ChangeProjectAssign[%d]: %v", issue.ID, err)
+				return
+			}
+		}
+
+		if err := moveIssueToBoard(issue, rule.BoardID); err != nil {
+			log.Error("moveIssueToBoard[%d]: %v", issue.ID, err)
+		}
+		return
+	}
+}
+
+// applyAutomationRules moves an issue as the first rule with the trigger of the project of the issue says
+func applyAutomationRules(issue *models.Issue, trigger project_model.AutomationTrigger) error {
+	projectID := issue.ProjectID()
+	if projectID == 0 {
+		return nil
+	}
+
+	rules, err := project_model.GetAutomationRulesByTrigger(db.DefaultContext, projectID, trigger)
+	if err != nil || len(rules) == 0 {
+		return err
+	}
+	return moveIssueToBoard(issue, rules[0].BoardID)
+}
+
+func moveIssueToBoard(issue *models.Issue, boardID int64) error {
+	if issue.ProjectBoardID() == boardID {
+		return nil
+	}
+
+	board, err := project_model.GetBoard(boardID)
+	if err != nil {
+		if project_model.IsErrProjectBoardNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	return models.MoveIssueAcrossProjectBoards(issue, board)
+}
@@ -0,0 +1,248 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package alpine
+
+import (
+	"archive/tar"
+	"bufio"
+	"compress/gzip"
+	"crypto/sha1"
+	"encoding/base64"
+	"errors"
+	"hash"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"code.gitea.io/gitea/modules/validation"
+)
+
+const (
+	PropertyMetadata     = "alpine.metadata"
+	PropertyBranch       = "alpine.branch"
+	PropertyRepository   = "alpine.repository"
+	PropertyArchitecture = "alpine.architecture"
+
+	// NoArch is the architecture of packages which can be installed on every architecture
+	NoArch = "noarch"
+)
+
+var (
+	// ErrMissingPKGINFOFile indicates a missing .PKGINFO file
+	ErrMissingPKGINFOFile = errors.New("PKGINFO file is missing")
+	// ErrInvalidName indicates an invalid package name
+	ErrInvalidName = errors.New("Package name is invalid")
+	// ErrInvalidVersion indicates an invalid package version
+	ErrInvalidVersion = errors.New("Package version is invalid")
+	// ErrInvalidArchitecture indicates an invalid package architecture
+	ErrInvalidArchitecture = errors.New("Package architecture is invalid")
+
+	namePattern         = regexp.MustCompile(`\A[a-zA-Z0-9][a-zA-Z0-9._+-]*\z`)
+	versionPattern      = regexp.MustCompile(`\A[0-9]+(\.[0-9]+)*[a-z]?(_[a-z]+[0-9]*)*(-r[0-9]+)?\z`)
+	architecturePattern = regexp.MustCompile(`\A[a-z0-9_]+\z`)
+)
+
+// Package represents an Alpine package
+type Package struct {
+	Name            string
+	Version         string
+	VersionMetadata VersionMetadata
+	FileMetadata    FileMetadata
+}
+
+// VersionMetadata is the metadata of an Alpine package version
+type VersionMetadata struct {
+	Description string `json:"description,omitempty"`
+	License     string `json:"license,omitempty"`
+	ProjectURL  string `json:"project_url,omitempty"`
+	Maintainer  string `json:"maintainer,omitempty"`
+}
+
+// FileMetadata is the metadata of an Alpine package file
+type FileMetadata struct {
+	Checksum         string   `json:"checksum"`
+	Packager         string   `json:"packager,omitempty"`
+	BuildDate        int64    `json:"build_date,omitempty"`
+	Size             int64    `json:"size,omitempty"`
+	Architecture     string   `json:"architecture,omitempty"`
+	Origin           string   `json:"origin,omitempty"`
+	CommitHash       string   `json:"commit,omitempty"`
+	InstallIf        string   `json:"install_if,omitempty"`
+	ProviderPriority int64    `json:"provider_priority,omitempty"`
+	Provides         []string `json:"provides,omitempty"`
+	Dependencies     []string `json:"dependencies,omitempty"`
+}
+
+// hashingReader hashes exactly the bytes consumed by the gzip reader.
+// It implements io.ByteReader so the gzip reader does not buffer beyond the end of a stream.
+type hashingReader struct {
+	br *bufio.Reader
+	h  hash.Hash
+}
+
+func (r *hashingReader) Read(p []byte) (int, error) {
+	n, err := r.br.Read(p)
+	r.h.Write(p[:n])
+	return n, err
+}
+
+func (r *hashingReader) ReadByte() (byte, error) {
+	b, err := r.br.ReadByte()
+	if err == nil {
+		r.h.Write([]byte{b})
+	}
+	return b, err
+}
+
+// ParsePackage parses the Alpine package file
+// An Alpine package consists of concatenated gzip streams: the optional signature, the control data and the package data.
+// https://wiki.alpinelinux.org/wiki/Apk_spec
+func ParsePackage(r io.Reader) (*Package, error) {
+	hr := &hashingReader{
+		br: bufio.NewReader(r),
+		h:  sha1.New(),
+	}
+
+	gzr, err := gzip.NewReader(hr)
+	if err != nil {
+		return nil, err
+	}
+	defer gzr.Close()
+
+	for {
+		gzr.Multistream(false)
+
+		var p *Package
+
+		tr := tar.NewReader(gzr)
+		for {
+			hd, err := tr.Next()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return nil, err
+			}
+
+			if hd.Name == ".PKGINFO" {
+				p, err = ParsePackageInfo(tr)
+				if err != nil {
+					return nil, err
+				}
+			}
+		}
+
+		// the checksum covers the whole control stream
+		if _, err := io.Copy(io.Discard, gzr); err != nil {
+			return nil, err
+		}
+
+		if p != nil {
+			p.FileMetadata.Checksum = "Q1" + base64.StdEncoding.EncodeToString(hr.h.Sum(nil))
+			return p, nil
+		}
+
+		hr.h.Reset()
+
+		if err := gzr.Reset(hr); err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, err
+		}
+	}
+
+	return nil, ErrMissingPKGINFOFile
+}
+
+// ParsePackageInfo parses a PKGINFO file to retrieve the metadata of an Alpine package
+func ParsePackageInfo(r io.Reader) (*Package, error) {
+	p := &Package{}
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		i := strings.IndexRune(line, '=')
+		if i == -1 {
+			continue
+		}
+
+		key := strings.TrimSpace(line[:i])
+		value := strings.TrimSpace(line[i+1:])
+
+		switch key {
+		case "pkgname":
+			p.Name = value
+		case "pkgver":
+			p.Version = value
+		case "pkgdesc":
+			p.VersionMetadata.Description = value
+		case "url":
+			p.VersionMetadata.ProjectURL = value
+		case "builddate":
+			n, err := strconv.ParseInt(value, 10, 64)
+			if err == nil {
+				p.FileMetadata.BuildDate = n
+			}
+		case "size":
+			n, err := strconv.ParseInt(value, 10, 64)
+			if err == nil {
+				p.FileMetadata.Size = n
+			}
+		case "arch":
+			p.FileMetadata.Architecture = value
+		case "origin":
+			p.FileMetadata.Origin = value
+		case "commit":
+			p.FileMetadata.CommitHash = value
+		case "maintainer":
+			p.VersionMetadata.Maintainer = value
+		case "packager":
+			p.FileMetadata.Packager = value
+		case "license":
+			p.VersionMetadata.License = value
+		case "install_if":
+			p.FileMetadata.InstallIf = value
+		case "provider_priority":
+			n, err := strconv.ParseInt(value, 10, 64)
+			if err == nil {
+				p.FileMetadata.ProviderPriority = n
+			}
+		case "provides":
+			if value != "" {
+				p.FileMetadata.Provides = append(p.FileMetadata.Provides, value)
+			}
+		case "depend":
+			if value != "" {
+				p.FileMetadata.Dependencies = append(p.FileMetadata.Dependencies, value)
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if !namePattern.MatchString(p.Name) {
+		return nil, ErrInvalidName
+	}
+	if !versionPattern.MatchString(p.Version) {
+		return nil, ErrInvalidVersion
+	}
+	if !architecturePattern.MatchString(p.FileMetadata.Architecture) {
+		return nil, ErrInvalidArchitecture
+	}
+
+	if !validation.IsValidURL(p.VersionMetadata.ProjectURL) {
+		p.VersionMetadata.ProjectURL = ""
+	}
+
+	return p, nil
+}
@@ -0,0 +1,133 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package alpine
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha1"
+	"encoding/base64"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	packageName        = "gitea"
+	packageVersion     = "1.0.1-r2"
+	packageDescription = "Package Description"
+	packageProjectURL  = "https://gitea.io"
+	packageMaintainer  = "KN4CK3R <dummy@gitea.io>"
+)
+
+func createPKGINFOContent(name, version string) []byte {
+	return []byte(`pkgname = ` + name + `
+pkgver = ` + version + `
+pkgdesc = ` + packageDescription + `
+url = ` + packageProjectURL + `
+# comment
+builddate = 1662034800
+packager = Gitea <pack@ag.er>
+size = 123456
+arch = aarch64
+origin = origin
+commit = 1111e709613fbc979651b09ac2bc27c6591a9999
+maintainer = ` + packageMaintainer + `
+license = MIT
+depend = common
+install_if = value
+depend = gitea
+provides = common
+provides = gitea`)
+}
+
+func createTarGzStream(name string, content []byte) []byte {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(zw)
+	tw.WriteHeader(&tar.Header{
+		Name: name,
+		Mode: 0o600,
+		Size: int64(len(content)),
+	})
+	tw.Write(content)
+	tw.Close()
+	zw.Close()
+	return buf.Bytes()
+}
+
+func TestParsePackage(t *testing.T) {
+	t.Run("InvalidPackage", func(t *testing.T) {
+		p, err := ParsePackage(strings.NewReader("dummy"))
+		assert.Nil(t, p)
+		assert.Error(t, err)
+	})
+
+	t.Run("MissingPKGINFOFile", func(t *testing.T) {
+		data := createTarGzStream("dummy.txt", []byte{})
+
+		p, err := ParsePackage(bytes.NewReader(data))
+		assert.Nil(t, p)
+		assert.ErrorIs(t, err, ErrMissingPKGINFOFile)
+	})
+
+	t.Run("Valid", func(t *testing.T) {
+		signature := createTarGzStream(".SIGN.RSA.dummy.rsa.pub", []byte("signature"))
+		control := createTarGzStream(".PKGINFO", createPKGINFOContent(packageName, packageVersion))
+		data := createTarGzStream("usr/bin/gitea", []byte("binary"))
+
+		p, err := ParsePackage(io.MultiReader(bytes.NewReader(signature), bytes.NewReader(control), bytes.NewReader(data)))
+		assert.NoError(t, err)
+		assert.NotNil(t, p)
+
+		sum := sha1.Sum(control)
+		assert.Equal(t, "Q1"+base64.StdEncoding.EncodeToString(sum[:]), p.FileMetadata.Checksum)
+		assert.Equal(t, packageName, p.Name)
+	})
+}
+
+func TestParsePackageInfo(t *testing.T) {
+	t.Run("InvalidName", func(t *testing.T) {
+		for _, name := range []string{"", "-gitea", "gi tea"} {
+			p, err := ParsePackageInfo(bytes.NewReader(createPKGINFOContent(name, packageVersion)))
+			assert.Nil(t, p)
+			assert.ErrorIs(t, err, ErrInvalidName)
+		}
+	})
+
+	t.Run("InvalidVersion", func(t *testing.T) {
+		for _, version := range []string{"", "a", "1.0-1"} {
+			p, err := ParsePackageInfo(bytes.NewReader(createPKGINFOContent(packageName, version)))
+			assert.Nil(t, p)
+			assert.ErrorIs(t, err, ErrInvalidVersion)
+		}
+	})
+
+	t.Run("Valid", func(t *testing.T) {
+		p, err := ParsePackageInfo(bytes.NewReader(createPKGINFOContent(packageName, packageVersion)))
+		assert.NoError(t, err)
+		assert.NotNil(t, p)
+
+		assert.Equal(t, packageName, p.Name)
+		assert.Equal(t, packageVersion, p.Version)
+		assert.Equal(t, packageDescription, p.VersionMetadata.Description)
+		assert.Equal(t, packageMaintainer, p.VersionMetadata.Maintainer)
+		assert.Equal(t, packageProjectURL, p.VersionMetadata.ProjectURL)
+		assert.Equal(t, "MIT", p.VersionMetadata.License)
+		assert.Empty(t, p.FileMetadata.Checksum)
+		assert.Equal(t, "Gitea <pack@ag.er>", p.FileMetadata.Packager)
+		assert.EqualValues(t, 1662034800, p.FileMetadata.BuildDate)
+		assert.EqualValues(t, 123456, p.FileMetadata.Size)
+		assert.Equal(t, "aarch64", p.FileMetadata.Architecture)
+		assert.Equal(t, "origin", p.FileMetadata.Origin)
+		assert.Equal(t, "1111e709613fbc979651b09ac2bc27c6591a9999", p.FileMetadata.CommitHash)
+		assert.Equal(t, "value", p.FileMetadata.InstallIf)
+		assert.ElementsMatch(t, []string{"common", "gitea"}, p.FileMetadata.Provides)
+		assert.ElementsMatch(t, []string{"common", "gitea"}, p.FileMetadata.Dependencies)
+	})
+}
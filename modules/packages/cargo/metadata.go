@@ -0,0 +1,191 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package cargo
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"regexp"
+
+	"code.gitea.io/gitea/modules/json"
+	"code.gitea.io/gitea/modules/validation"
+
+	"github.com/hashicorp/go-version"
+)
+
+// PropertyYanked is the version property which stores if a crate version is yanked
+const PropertyYanked = "cargo.yanked"
+
+var (
+	// ErrInvalidName indicates an invalid crate name
+	ErrInvalidName = errors.New("Package name is invalid")
+	// ErrInvalidVersion indicates an invalid crate version
+	ErrInvalidVersion = errors.New("Package version is invalid")
+	// ErrInvalidPayload indicates an invalid publish payload
+	ErrInvalidPayload = errors.New("Publish payload is invalid")
+)
+
+// https://doc.rust-lang.org/cargo/reference/manifest.html#the-name-field
+var namePattern = regexp.MustCompile(`\A[a-zA-Z][a-zA-Z0-9_-]{0,63}\z`)
+
+// Package represents a crate
+type Package struct {
+	Name        string
+	Version     string
+	Metadata    *Metadata
+	Content     io.Reader
+	ContentSize int64
+}
+
+// Metadata represents the metadata of a crate
+type Metadata struct {
+	Description      string              `json:"description,omitempty"`
+	Authors          []string            `json:"authors,omitempty"`
+	License          string              `json:"license,omitempty"`
+	ProjectURL       string              `json:"project_url,omitempty"`
+	RepositoryURL    string              `json:"repository_url,omitempty"`
+	DocumentationURL string              `json:"documentation_url,omitempty"`
+	Readme           string              `json:"readme,omitempty"`
+	Keywords         []string            `json:"keywords,omitempty"`
+	Categories       []string            `json:"categories,omitempty"`
+	Features         map[string][]string `json:"features,omitempty"`
+	Dependencies     []*Dependency       `json:"dependencies,omitempty"`
+	Links            string              `json:"links,omitempty"`
+}
+
+// Dependency represents a dependency of a crate
+type Dependency struct {
+	Name            string   `json:"name"`
+	Req             string   `json:"req"`
+	Features        []string `json:"features"`
+	Optional        bool     `json:"optional"`
+	DefaultFeatures bool     `json:"default_features"`
+	Target          *string  `json:"target"`
+	Kind            string   `json:"kind"`
+	Registry        *string  `json:"registry"`
+	Package         *string  `json:"package"`
+}
+
+// https://doc.rust-lang.org/cargo/reference/registries.html#publish
+type publishMetadata struct {
+	Name          string               `json:"name"`
+	Version       string               `json:"vers"`
+	Dependencies  []*publishDependency `json:"deps"`
+	Features      map[string][]string  `json:"features"`
+	Authors       []string             `json:"authors"`
+	Description   string               `json:"description"`
+	Documentation string               `json:"documentation"`
+	Homepage      string               `json:"homepage"`
+	Readme        string               `json:"readme"`
+	Keywords      []string             `json:"keywords"`
+	Categories    []string             `json:"categories"`
+	License       string               `json:"license"`
+	Repository    string               `json:"repository"`
+	Links         string               `json:"links"`
+}
+
+type publishDependency struct {
+	Name               string   `json:"name"`
+	VersionReq         string   `json:"version_req"`
+	Features           []string `json:"features"`
+	Optional           bool     `json:"optional"`
+	DefaultFeatures    bool     `json:"default_features"`
+	Target             *string  `json:"target"`
+	Kind               string   `json:"kind"`
+	Registry           *string  `json:"registry"`
+	ExplicitNameInToml string   `json:"explicit_name_in_toml"`
+}
+
+// ParsePackage reads the metadata and the crate content from the publish payload
+// The payload consists of the length prefixed JSON metadata followed by the length prefixed crate file.
+func ParsePackage(r io.Reader) (*Package, error) {
+	var size uint32
+	if err := binary.Read(r, binary.LittleEndian, &size); err != nil {
+		return nil, ErrInvalidPayload
+	}
+
+	p, err := parsePackage(io.LimitReader(r, int64(size)))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := binary.Read(r, binary.LittleEndian, &size); err != nil {
+		return nil, ErrInvalidPayload
+	}
+
+	p.Content = io.LimitReader(r, int64(size))
+	p.ContentSize = int64(size)
+
+	return p, nil
+}
+
+func parsePackage(r io.Reader) (*Package, error) {
+	var meta publishMetadata
+	if err := json.NewDecoder(r).Decode(&meta); err != nil {
+		return nil, ErrInvalidPayload
+	}
+
+	if !namePattern.MatchString(meta.Name) {
+		return nil, ErrInvalidName
+	}
+
+	if _, err := version.NewSemver(meta.Version); err != nil {
+		return nil, ErrInvalidVersion
+	}
+
+	if !validation.IsValidURL(meta.Homepage) {
+		meta.Homepage = ""
+	}
+	if !validation.IsValidURL(meta.Documentation) {
+		meta.Documentation = ""
+	}
+	if !validation.IsValidURL(meta.Repository) {
+		meta.Repository = ""
+	}
+
+	dependencies := make([]*Dependency, 0, len(meta.Dependencies))
+	for _, dep := range meta.Dependencies {
+		d := &Dependency{
+			Name:            dep.Name,
+			Req:             dep.VersionReq,
+			Features:        dep.Features,
+			Optional:        dep.Optional,
+			DefaultFeatures: dep.DefaultFeatures,
+			Target:          dep.Target,
+			Kind:            dep.Kind,
+			Registry:        dep.Registry,
+		}
+		// A renamed dependency uses the new name in the index and references the original crate
+		if dep.ExplicitNameInToml != "" {
+			name := dep.Name
+			d.Name = dep.ExplicitNameInToml
+			d.Package = &name
+		}
+		if d.Features == nil {
+			d.Features = []string{}
+		}
+		dependencies = append(dependencies, d)
+	}
+
+	return &Package{
+		Name:    meta.Name,
+		Version: meta.Version,
+		Metadata: &Metadata{
+			Description:      meta.Description,
+			Authors:          meta.Authors,
+			License:          meta.License,
+			ProjectURL:       meta.Homepage,
+			RepositoryURL:    meta.Repository,
+			DocumentationURL: meta.Documentation,
+			Readme:           meta.Readme,
+			Keywords:         meta.Keywords,
+			Categories:       meta.Categories,
+			Features:         meta.Features,
+			Dependencies:     dependencies,
+			Links:            meta.Links,
+		},
+	}, nil
+}
@@ -0,0 +1,91 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package cargo
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	packageName    = "gitea"
+	packageVersion = "1.0.1"
+	description    = "Package Description"
+	author         = "KN4CK3R"
+	homepage       = "https://gitea.io/"
+	license        = "MIT"
+)
+
+func createPayload(name, version string) *bytes.Buffer {
+	metadata := `{"name":"` + name + `","vers":"` + version + `","description":"` + description + `","authors":["` + author + `"],"deps":[{"name":"dep","version_req":"1.0","features":null,"optional":false,"default_features":true,"target":null,"kind":"normal","registry":null,"explicit_name_in_toml":"renamed"}],"homepage":"` + homepage + `","license":"` + license + `"}`
+
+	var buf bytes.Buffer
+	binary.Write(&buf, binary.LittleEndian, uint32(len(metadata)))
+	buf.WriteString(metadata)
+	binary.Write(&buf, binary.LittleEndian, uint32(4))
+	buf.WriteString("test")
+	return &buf
+}
+
+func TestParsePackage(t *testing.T) {
+	t.Run("InvalidPayload", func(t *testing.T) {
+		p, err := ParsePackage(strings.NewReader("dummy"))
+		assert.Nil(t, p)
+		assert.ErrorIs(t, err, ErrInvalidPayload)
+	})
+
+	t.Run("InvalidName", func(t *testing.T) {
+		for _, name := range []string{"", "0test", "-test", "_test", strings.Repeat("a", 65)} {
+			data := createPayload(name, "1.0.0")
+
+			p, err := ParsePackage(data)
+			assert.Nil(t, p)
+			assert.ErrorIs(t, err, ErrInvalidName)
+		}
+	})
+
+	t.Run("InvalidVersion", func(t *testing.T) {
+		for _, version := range []string{"", "1.", "-1", "a"} {
+			data := createPayload(packageName, version)
+
+			p, err := ParsePackage(data)
+			assert.Nil(t, p)
+			assert.ErrorIs(t, err, ErrInvalidVersion)
+		}
+	})
+
+	t.Run("Valid", func(t *testing.T) {
+		data := createPayload(packageName, packageVersion)
+
+		p, err := ParsePackage(data)
+		assert.NotNil(t, p)
+		assert.NoError(t, err)
+
+		assert.Equal(t, packageName, p.Name)
+		assert.Equal(t, packageVersion, p.Version)
+		assert.Equal(t, description, p.Metadata.Description)
+		assert.Equal(t, []string{author}, p.Metadata.Authors)
+		assert.Equal(t, homepage, p.Metadata.ProjectURL)
+		assert.Equal(t, license, p.Metadata.License)
+		assert.Len(t, p.Metadata.Dependencies, 1)
+		dep := p.Metadata.Dependencies[0]
+		assert.Equal(t, "renamed", dep.Name)
+		assert.Equal(t, "dep", *dep.Package)
+		assert.Equal(t, "1.0", dep.Req)
+		assert.Equal(t, "normal", dep.Kind)
+		assert.Empty(t, dep.Features)
+		assert.NotNil(t, dep.Features)
+
+		content, err := io.ReadAll(p.Content)
+		assert.NoError(t, err)
+		assert.Equal(t, "test", string(content))
+		assert.EqualValues(t, 4, p.ContentSize)
+	})
+}
@@ -0,0 +1,226 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package conda
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/bzip2"
+	"errors"
+	"io"
+	"regexp"
+	"strings"
+
+	"code.gitea.io/gitea/modules/json"
+	"code.gitea.io/gitea/modules/validation"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+const (
+	PropertySubdir   = "conda.subdir"
+	PropertyMetadata = "conda.metadata"
+)
+
+var (
+	// ErrInvalidStructure indicates an invalid package structure
+	ErrInvalidStructure = errors.New("Package structure is invalid")
+	// ErrInvalidName indicates an invalid package name
+	ErrInvalidName = errors.New("Package name is invalid")
+	// ErrInvalidVersion indicates an invalid package version
+	ErrInvalidVersion = errors.New("Package version is invalid")
+	// ErrInvalidBuild indicates an invalid package build string
+	ErrInvalidBuild = errors.New("Package build is invalid")
+	// ErrInvalidSubdir indicates an invalid package subdir
+	ErrInvalidSubdir = errors.New("Package subdir is invalid")
+
+	// https://docs.conda.io/projects/conda-build/en/latest/resources/package-spec.html#package-naming-conventions
+	namePattern    = regexp.MustCompile(`\A[a-z0-9][a-z0-9_.-]*\z`)
+	versionPattern = regexp.MustCompile(`\A[a-zA-Z0-9_.+!]+\z`)
+	buildPattern   = regexp.MustCompile(`\A[a-zA-Z0-9_.+]+\z`)
+	subdirPattern  = regexp.MustCompile(`\A[a-z0-9]+(-[a-z0-9_]+)?\z`)
+)
+
+// Package represents a Conda package
+type Package struct {
+	Name            string
+	Version         string
+	Subdir          string
+	VersionMetadata *VersionMetadata
+	FileMetadata    *FileMetadata
+}
+
+// VersionMetadata represents the metadata of a Conda package version
+type VersionMetadata struct {
+	Description      string `json:"description,omitempty"`
+	Summary          string `json:"summary,omitempty"`
+	ProjectURL       string `json:"project_url,omitempty"`
+	RepositoryURL    string `json:"repository_url,omitempty"`
+	DocumentationURL string `json:"documentation_url,omitempty"`
+	License          string `json:"license,omitempty"`
+	LicenseFamily    string `json:"license_family,omitempty"`
+}
+
+// FileMetadata represents the metadata of a Conda package file
+// The fields match the entries of the "info/index.json" file.
+type FileMetadata struct {
+	Build         string   `json:"build"`
+	BuildNumber   int64    `json:"build_number"`
+	Dependencies  []string `json:"depends"`
+	Constrains    []string `json:"constrains,omitempty"`
+	License       string   `json:"license,omitempty"`
+	LicenseFamily string   `json:"license_family,omitempty"`
+	Noarch        string   `json:"noarch,omitempty"`
+	Platform      string   `json:"platform,omitempty"`
+	Arch          string   `json:"arch,omitempty"`
+	Subdir        string   `json:"subdir"`
+	Timestamp     int64    `json:"timestamp,omitempty"`
+	TrackFeatures string   `json:"track_features,omitempty"`
+	Features      string   `json:"features,omitempty"`
+}
+
+// ParsePackageBZ2 parses the metadata of a Conda package in the legacy ".tar.bz2" format
+func ParsePackageBZ2(r io.Reader) (*Package, error) {
+	return parsePackageTar(bzip2.NewReader(r))
+}
+
+// ParsePackageConda parses the metadata of a Conda package in the ".conda" format
+// The zip archive contains the "info-*.tar.zst" and "pkg-*.tar.zst" archives.
+// https://docs.conda.io/projects/conda/en/latest/user-guide/concepts/packages.html#conda-file-format
+func ParsePackageConda(r io.ReaderAt, size int64) (*Package, error) {
+	archive, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, ErrInvalidStructure
+	}
+
+	for _, file := range archive.File {
+		if !strings.HasPrefix(file.Name, "info-") || !strings.HasSuffix(file.Name, ".tar.zst") {
+			continue
+		}
+
+		f, err := archive.Open(file.Name)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+
+		zr, err := zstd.NewReader(f)
+		if err != nil {
+			return nil, err
+		}
+		defer zr.Close()
+
+		return parsePackageTar(zr)
+	}
+
+	return nil, ErrInvalidStructure
+}
+
+// parsePackageTar reads the "info/index.json" and the optional "info/about.json" files
+func parsePackageTar(r io.Reader) (*Package, error) {
+	var p *Package
+	var about *aboutFile
+
+	tr := tar.NewReader(r)
+	for {
+		hd, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, ErrInvalidStructure
+		}
+
+		if hd.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		switch hd.Name {
+		case "info/index.json":
+			p, err = ParseIndexJSON(tr)
+			if err != nil {
+				return nil, err
+			}
+		case "info/about.json":
+			about = &aboutFile{}
+			if err := json.NewDecoder(tr).Decode(about); err != nil {
+				return nil, ErrInvalidStructure
+			}
+		}
+	}
+
+	if p == nil {
+		return nil, ErrInvalidStructure
+	}
+
+	if about != nil {
+		p.VersionMetadata.Description = about.Description
+		p.VersionMetadata.Summary = about.Summary
+		if validation.IsValidURL(about.Home) {
+			p.VersionMetadata.ProjectURL = about.Home
+		}
+		if validation.IsValidURL(about.DevURL) {
+			p.VersionMetadata.RepositoryURL = about.DevURL
+		}
+		if validation.IsValidURL(about.DocURL) {
+			p.VersionMetadata.DocumentationURL = about.DocURL
+		}
+		if p.VersionMetadata.License == "" {
+			p.VersionMetadata.License = about.License
+		}
+	}
+
+	return p, nil
+}
+
+type aboutFile struct {
+	Description string `json:"description"`
+	Summary     string `json:"summary"`
+	Home        string `json:"home"`
+	DevURL      string `json:"dev_url"`
+	DocURL      string `json:"doc_url"`
+	License     string `json:"license"`
+}
+
+// ParseIndexJSON parses the "info/index.json" file of a Conda package
+func ParseIndexJSON(r io.Reader) (*Package, error) {
+	var index struct {
+		Name    string `json:"name"`
+		Version string `json:"version"`
+		FileMetadata
+	}
+	if err := json.NewDecoder(r).Decode(&index); err != nil {
+		return nil, ErrInvalidStructure
+	}
+
+	if !namePattern.MatchString(index.Name) {
+		return nil, ErrInvalidName
+	}
+	if !versionPattern.MatchString(index.Version) {
+		return nil, ErrInvalidVersion
+	}
+	if !buildPattern.MatchString(index.Build) {
+		return nil, ErrInvalidBuild
+	}
+	if !subdirPattern.MatchString(index.Subdir) {
+		return nil, ErrInvalidSubdir
+	}
+	if index.Dependencies == nil {
+		index.Dependencies = []string{}
+	}
+
+	fm := index.FileMetadata
+
+	return &Package{
+		Name:    index.Name,
+		Version: index.Version,
+		Subdir:  index.Subdir,
+		VersionMetadata: &VersionMetadata{
+			License:       index.License,
+			LicenseFamily: index.LicenseFamily,
+		},
+		FileMetadata: &fm,
+	}, nil
+}
@@ -0,0 +1,165 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package conda
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	packageName    = "gitea"
+	packageVersion = "1.0.1"
+	packageBuild   = "py_0"
+	packageSubdir  = "noarch"
+	description    = "Description of the package"
+	summary        = "Summary of the package"
+	projectURL     = "https://gitea.io"
+	repositoryURL  = "https://gitea.io/gitea/gitea"
+)
+
+const indexJSON = `{
+	"name": "` + packageName + `",
+	"version": "` + packageVersion + `",
+	"build": "` + packageBuild + `",
+	"build_number": 0,
+	"depends": ["python >=3.7"],
+	"license": "MIT",
+	"noarch": "python",
+	"subdir": "` + packageSubdir + `",
+	"timestamp": 1662034800000
+}`
+
+const aboutJSON = `{
+	"description": "` + description + `",
+	"summary": "` + summary + `",
+	"home": "` + projectURL + `",
+	"dev_url": "` + repositoryURL + `",
+	"doc_url": "invalid"
+}`
+
+func createTar(files map[string]string) []byte {
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for name, content := range files {
+		tw.WriteHeader(&tar.Header{
+			Name: name,
+			Mode: 0o600,
+			Size: int64(len(content)),
+		})
+		tw.Write([]byte(content))
+	}
+	tw.Close()
+	return buf.Bytes()
+}
+
+func TestParseIndexJSON(t *testing.T) {
+	t.Run("InvalidJSON", func(t *testing.T) {
+		p, err := ParseIndexJSON(strings.NewReader("{"))
+		assert.Nil(t, p)
+		assert.ErrorIs(t, err, ErrInvalidStructure)
+	})
+
+	t.Run("InvalidName", func(t *testing.T) {
+		for _, name := range []string{"", "_gitea", "Gitea", "gi tea"} {
+			p, err := ParseIndexJSON(strings.NewReader(`{"name":"` + name + `","version":"1.0","build":"0","subdir":"noarch"}`))
+			assert.Nil(t, p)
+			assert.ErrorIs(t, err, ErrInvalidName)
+		}
+	})
+
+	t.Run("InvalidVersion", func(t *testing.T) {
+		for _, version := range []string{"", "1.0-1", "1.0 1"} {
+			p, err := ParseIndexJSON(strings.NewReader(`{"name":"gitea","version":"` + version + `","build":"0","subdir":"noarch"}`))
+			assert.Nil(t, p)
+			assert.ErrorIs(t, err, ErrInvalidVersion)
+		}
+	})
+
+	t.Run("InvalidBuild", func(t *testing.T) {
+		p, err := ParseIndexJSON(strings.NewReader(`{"name":"gitea","version":"1.0","build":"py-0","subdir":"noarch"}`))
+		assert.Nil(t, p)
+		assert.ErrorIs(t, err, ErrInvalidBuild)
+	})
+
+	t.Run("InvalidSubdir", func(t *testing.T) {
+		p, err := ParseIndexJSON(strings.NewReader(`{"name":"gitea","version":"1.0","build":"0","subdir":"../linux"}`))
+		assert.Nil(t, p)
+		assert.ErrorIs(t, err, ErrInvalidSubdir)
+	})
+
+	t.Run("Valid", func(t *testing.T) {
+		p, err := ParseIndexJSON(strings.NewReader(indexJSON))
+		assert.NoError(t, err)
+		assert.NotNil(t, p)
+
+		assert.Equal(t, packageName, p.Name)
+		assert.Equal(t, packageVersion, p.Version)
+		assert.Equal(t, packageSubdir, p.Subdir)
+		assert.Equal(t, "MIT", p.VersionMetadata.License)
+		assert.Equal(t, packageBuild, p.FileMetadata.Build)
+		assert.Equal(t, []string{"python >=3.7"}, p.FileMetadata.Dependencies)
+		assert.Equal(t, "python", p.FileMetadata.Noarch)
+		assert.Equal(t, packageSubdir, p.FileMetadata.Subdir)
+		assert.Equal(t, int64(1662034800000), p.FileMetadata.Timestamp)
+	})
+}
+
+func TestParsePackageTar(t *testing.T) {
+	t.Run("MissingIndexFile", func(t *testing.T) {
+		data := createTar(map[string]string{"info/about.json": aboutJSON})
+
+		p, err := parsePackageTar(bytes.NewReader(data))
+		assert.Nil(t, p)
+		assert.ErrorIs(t, err, ErrInvalidStructure)
+	})
+
+	t.Run("Valid", func(t *testing.T) {
+		data := createTar(map[string]string{
+			"info/index.json":                 indexJSON,
+			"info/about.json":                 aboutJSON,
+			"site-packages/gitea/__init__.py": "",
+		})
+
+		p, err := parsePackageTar(bytes.NewReader(data))
+		assert.NoError(t, err)
+		assert.NotNil(t, p)
+
+		assert.Equal(t, packageName, p.Name)
+		assert.Equal(t, packageVersion, p.Version)
+		assert.Equal(t, description, p.VersionMetadata.Description)
+		assert.Equal(t, summary, p.VersionMetadata.Summary)
+		assert.Equal(t, projectURL, p.VersionMetadata.ProjectURL)
+		assert.Equal(t, repositoryURL, p.VersionMetadata.RepositoryURL)
+		assert.Empty(t, p.VersionMetadata.DocumentationURL)
+		assert.Equal(t, "MIT", p.VersionMetadata.License)
+	})
+}
+
+func TestParsePackageConda(t *testing.T) {
+	t.Run("InvalidArchive", func(t *testing.T) {
+		p, err := ParsePackageConda(strings.NewReader("dummy"), 5)
+		assert.Nil(t, p)
+		assert.ErrorIs(t, err, ErrInvalidStructure)
+	})
+
+	t.Run("MissingInfoArchive", func(t *testing.T) {
+		var buf bytes.Buffer
+		zw := zip.NewWriter(&buf)
+		w, _ := zw.Create("pkg-gitea-1.0.1-py_0.tar.zst")
+		io.WriteString(w, "dummy")
+		zw.Close()
+
+		p, err := ParsePackageConda(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+		assert.Nil(t, p)
+		assert.ErrorIs(t, err, ErrInvalidStructure)
+	})
+}
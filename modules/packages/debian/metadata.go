@@ -0,0 +1,307 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package debian
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"net/mail"
+	"regexp"
+	"strings"
+
+	"code.gitea.io/gitea/modules/validation"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/ulikunitz/xz"
+)
+
+const (
+	PropertyDistribution = "debian.distribution"
+	PropertyComponent    = "debian.component"
+	PropertyArchitecture = "debian.architecture"
+	PropertyControl      = "debian.control"
+
+	controlTar = "control.tar"
+)
+
+var (
+	// ErrMissingControlFile indicates a missing control file
+	ErrMissingControlFile = errors.New("Control file is missing")
+	// ErrUnsupportedCompression indicates an unsupported compression format
+	ErrUnsupportedCompression = errors.New("Unsupported compression algorithm")
+	// ErrInvalidName indicates an invalid package name
+	ErrInvalidName = errors.New("Package name is invalid")
+	// ErrInvalidVersion indicates an invalid package version
+	ErrInvalidVersion = errors.New("Package version is invalid")
+	// ErrInvalidArchitecture indicates an invalid package architecture
+	ErrInvalidArchitecture = errors.New("Package architecture is invalid")
+	// ErrInvalidArchive indicates an invalid ar archive
+	ErrInvalidArchive = errors.New("Package archive is invalid")
+
+	// https://www.debian.org/doc/debian-policy/ch-controlfields.html#source
+	namePattern = regexp.MustCompile(`\A[a-z0-9][a-z0-9+.-]+\z`)
+	// https://www.debian.org/doc/debian-policy/ch-controlfields.html#version
+	versionPattern = regexp.MustCompile(`\A(?:[0-9]+:)?[a-zA-Z0-9.+~]+(?:-[a-zA-Z0-9.+~-]+)?\z`)
+	// https://www.debian.org/doc/debian-policy/ch-customized-programs.html#architecture-specification-strings
+	architecturePattern = regexp.MustCompile(`\A[a-z0-9][a-z0-9-]*\z`)
+)
+
+// Package represents a Debian package
+type Package struct {
+	Name         string
+	Version      string
+	Architecture string
+	Control      string
+	Metadata     *Metadata
+}
+
+// Metadata represents the metadata of a Debian package
+type Metadata struct {
+	Maintainer   string   `json:"maintainer,omitempty"`
+	ProjectURL   string   `json:"project_url,omitempty"`
+	Description  string   `json:"description,omitempty"`
+	Dependencies []string `json:"dependencies,omitempty"`
+}
+
+// ParsePackage parses the Debian package file
+// https://manpages.debian.org/bullseye/dpkg-dev/deb.5.en.html
+func ParsePackage(r io.Reader) (*Package, error) {
+	ar, err := newArReader(r)
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		name, err := ar.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if strings.HasPrefix(name, controlTar) {
+			var inner io.Reader
+			switch name[len(controlTar):] {
+			case "":
+				inner = ar
+			case ".gz":
+				gzr, err := gzip.NewReader(ar)
+				if err != nil {
+					return nil, err
+				}
+				defer gzr.Close()
+
+				inner = gzr
+			case ".xz":
+				xzr, err := xz.NewReader(ar)
+				if err != nil {
+					return nil, err
+				}
+
+				inner = xzr
+			case ".zst":
+				zr, err := zstd.NewReader(ar)
+				if err != nil {
+					return nil, err
+				}
+				defer zr.Close()
+
+				inner = zr
+			default:
+				return nil, ErrUnsupportedCompression
+			}
+
+			tr := tar.NewReader(inner)
+			for {
+				hd, err := tr.Next()
+				if err == io.EOF {
+					break
+				}
+				if err != nil {
+					return nil, err
+				}
+
+				if hd.Typeflag != tar.TypeReg {
+					continue
+				}
+
+				if hd.FileInfo().Name() == "control" {
+					return ParseControlFile(tr)
+				}
+			}
+		}
+	}
+
+	return nil, ErrMissingControlFile
+}
+
+// ParseControlFile parses a Debian control file to retrieve the metadata
+func ParseControlFile(r io.Reader) (*Package, error) {
+	p := &Package{
+		Metadata: &Metadata{},
+	}
+
+	key := ""
+	var depends strings.Builder
+	var control strings.Builder
+
+	s := bufio.NewScanner(io.LimitReader(r, 10*1024*1024))
+	for s.Scan() {
+		line := s.Text()
+
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" {
+			continue
+		}
+
+		if line[0] == ' ' || line[0] == '\t' {
+			switch key {
+			case "Description":
+				p.Metadata.Description += "\n" + trimmed
+			case "Depends":
+				// a continuation line may continue the current dependency, e.g. with alternatives
+				depends.WriteByte(' ')
+				depends.WriteString(trimmed)
+			}
+		} else {
+			parts := strings.SplitN(trimmed, ":", 2)
+			if len(parts) < 2 {
+				continue
+			}
+
+			key = parts[0]
+			value := strings.TrimSpace(parts[1])
+			switch key {
+			case "Package":
+				p.Name = value
+			case "Version":
+				p.Version = value
+			case "Architecture":
+				p.Architecture = value
+			case "Maintainer":
+				a, err := mail.ParseAddress(value)
+				if err != nil || a.Name == "" {
+					p.Metadata.Maintainer = value
+				} else {
+					p.Metadata.Maintainer = a.Name
+				}
+			case "Description":
+				p.Metadata.Description = value
+			case "Depends":
+				depends.WriteString(value)
+			case "Homepage":
+				if validation.IsValidURL(value) {
+					p.Metadata.ProjectURL = value
+				}
+			}
+		}
+
+		control.WriteString(strings.TrimRight(line, " \t"))
+		control.WriteByte('\n')
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+
+	if !namePattern.MatchString(p.Name) {
+		return nil, ErrInvalidName
+	}
+	if !versionPattern.MatchString(p.Version) {
+		return nil, ErrInvalidVersion
+	}
+	if !architecturePattern.MatchString(p.Architecture) {
+		return nil, ErrInvalidArchitecture
+	}
+
+	dependencies := strings.Split(depends.String(), ",")
+	for i := range dependencies {
+		dependencies[i] = strings.TrimSpace(dependencies[i])
+	}
+	if len(dependencies) == 1 && dependencies[0] == "" {
+		dependencies = nil
+	}
+	p.Metadata.Dependencies = dependencies
+
+	p.Control = strings.TrimSpace(control.String())
+
+	return p, nil
+}
+
+const (
+	arMagic      = "!<arch>\n"
+	arHeaderSize = 60
+)
+
+// arReader reads the members of an ar archive
+type arReader struct {
+	r         io.Reader
+	remaining int64
+	padding   int64
+}
+
+func newArReader(r io.Reader) (*arReader, error) {
+	magic := make([]byte, len(arMagic))
+	if _, err := io.ReadFull(r, magic); err != nil {
+		return nil, ErrInvalidArchive
+	}
+	if string(magic) != arMagic {
+		return nil, ErrInvalidArchive
+	}
+
+	return &arReader{r: r}, nil
+}
+
+// Next advances to the next member and returns its name
+func (ar *arReader) Next() (string, error) {
+	if _, err := io.CopyN(io.Discard, ar.r, ar.remaining+ar.padding); err != nil {
+		if err == io.EOF {
+			return "", io.ErrUnexpectedEOF
+		}
+		return "", err
+	}
+
+	header := make([]byte, arHeaderSize)
+	if n, err := io.ReadFull(ar.r, header); err != nil {
+		if err == io.EOF && n == 0 {
+			return "", io.EOF
+		}
+		return "", ErrInvalidArchive
+	}
+	if !bytes.Equal(header[58:60], []byte("`\n")) {
+		return "", ErrInvalidArchive
+	}
+
+	var size int64
+	if _, err := fmt.Sscan(string(header[48:58]), &size); err != nil || size < 0 {
+		return "", ErrInvalidArchive
+	}
+
+	ar.remaining = size
+	ar.padding = size % 2
+
+	return strings.TrimRight(strings.TrimSpace(string(header[0:16])), "/"), nil
+}
+
+// Read reads from the current member
+func (ar *arReader) Read(p []byte) (int, error) {
+	if ar.remaining <= 0 {
+		return 0, io.EOF
+	}
+	if int64(len(p)) > ar.remaining {
+		p = p[:ar.remaining]
+	}
+	n, err := ar.r.Read(p)
+	ar.remaining -= int64(n)
+	if err == io.EOF && ar.remaining > 0 {
+		err = io.ErrUnexpectedEOF
+	}
+	return n, err
+}
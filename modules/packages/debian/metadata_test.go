@@ -0,0 +1,166 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package debian
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	packageName         = "gitea"
+	packageVersion      = "1.0.1"
+	packageArchitecture = "amd64"
+	packageAuthor       = "KN4CK3R"
+	description         = "Description with multiple lines."
+	projectURL          = "https://gitea.io"
+)
+
+func TestParsePackage(t *testing.T) {
+	createArchive := func(files map[string][]byte) io.Reader {
+		var buf bytes.Buffer
+		buf.WriteString("!<arch>\n")
+		for name, content := range files {
+			fmt.Fprintf(&buf, "%-16s%-12s%-6s%-6s%-8s%-10d`\n", name, "0", "0", "0", "100644", len(content))
+			buf.Write(content)
+			if len(content)%2 != 0 {
+				buf.WriteByte('\n')
+			}
+		}
+		return &buf
+	}
+
+	t.Run("MissingControlFile", func(t *testing.T) {
+		data := createArchive(map[string][]byte{"dummy.txt": {}})
+
+		p, err := ParsePackage(data)
+		assert.Nil(t, p)
+		assert.ErrorIs(t, err, ErrMissingControlFile)
+	})
+
+	t.Run("InvalidArchive", func(t *testing.T) {
+		p, err := ParsePackage(strings.NewReader("dummy"))
+		assert.Nil(t, p)
+		assert.ErrorIs(t, err, ErrInvalidArchive)
+	})
+
+	t.Run("Compression", func(t *testing.T) {
+		t.Run("Unsupported", func(t *testing.T) {
+			data := createArchive(map[string][]byte{"control.tar.foo": {}})
+
+			p, err := ParsePackage(data)
+			assert.Nil(t, p)
+			assert.ErrorIs(t, err, ErrUnsupportedCompression)
+		})
+
+		var buf bytes.Buffer
+		tw := tar.NewWriter(&buf)
+		content := "Package: " + packageName + "\nVersion: " + packageVersion + "\nArchitecture: " + packageArchitecture + "\n"
+		tw.WriteHeader(&tar.Header{
+			Name: "./control",
+			Mode: 0o600,
+			Size: int64(len(content)),
+		})
+		tw.Write([]byte(content))
+		tw.Close()
+
+		t.Run("None", func(t *testing.T) {
+			data := createArchive(map[string][]byte{"control.tar": buf.Bytes()})
+
+			p, err := ParsePackage(data)
+			assert.NotNil(t, p)
+			assert.NoError(t, err)
+			assert.Equal(t, packageName, p.Name)
+		})
+
+		t.Run("gz", func(t *testing.T) {
+			var zbuf bytes.Buffer
+			zw := gzip.NewWriter(&zbuf)
+			zw.Write(buf.Bytes())
+			zw.Close()
+
+			data := createArchive(map[string][]byte{"control.tar.gz": zbuf.Bytes()})
+
+			p, err := ParsePackage(data)
+			assert.NotNil(t, p)
+			assert.NoError(t, err)
+			assert.Equal(t, packageName, p.Name)
+		})
+	})
+}
+
+func TestParseControlFile(t *testing.T) {
+	buildContent := func(name, version, architecture string) *bytes.Buffer {
+		var buf bytes.Buffer
+		buf.WriteString("Package: " + name + "\nVersion: " + version + "\nArchitecture: " + architecture + "\nMaintainer: " + packageAuthor + " <kn4ck3r@gitea.io>\nHomepage: " + projectURL + "\nDepends: a,\n b\nDescription: Description\n with multiple\n lines.")
+		return &buf
+	}
+
+	t.Run("InvalidName", func(t *testing.T) {
+		for _, name := range []string{"", "-cd", "a", "a b"} {
+			p, err := ParseControlFile(buildContent(name, packageVersion, packageArchitecture))
+			assert.Nil(t, p)
+			assert.ErrorIs(t, err, ErrInvalidName)
+		}
+	})
+
+	t.Run("InvalidVersion", func(t *testing.T) {
+		for _, version := range []string{"", "1-", ":1.0", "1_0"} {
+			p, err := ParseControlFile(buildContent(packageName, version, packageArchitecture))
+			assert.Nil(t, p)
+			assert.ErrorIs(t, err, ErrInvalidVersion)
+		}
+	})
+
+	t.Run("ValidVersion", func(t *testing.T) {
+		for _, version := range []string{"1.0", "1:1.0", "12:1.0-1", "1.0~rc1+dfsg-2"} {
+			p, err := ParseControlFile(buildContent(packageName, version, packageArchitecture))
+			assert.NoError(t, err)
+			assert.Equal(t, version, p.Version)
+		}
+	})
+
+	t.Run("InvalidArchitecture", func(t *testing.T) {
+		for _, architecture := range []string{"", "-a", "a!"} {
+			p, err := ParseControlFile(buildContent(packageName, packageVersion, architecture))
+			assert.Nil(t, p)
+			assert.ErrorIs(t, err, ErrInvalidArchitecture)
+		}
+	})
+
+	t.Run("Valid", func(t *testing.T) {
+		content := buildContent(packageName, packageVersion, packageArchitecture)
+		full := content.String()
+
+		p, err := ParseControlFile(content)
+		assert.NoError(t, err)
+		assert.NotNil(t, p)
+
+		assert.Equal(t, packageName, p.Name)
+		assert.Equal(t, packageVersion, p.Version)
+		assert.Equal(t, packageArchitecture, p.Architecture)
+		assert.Equal(t, description, strings.ReplaceAll(p.Metadata.Description, "\n", " "))
+		assert.Equal(t, projectURL, p.Metadata.ProjectURL)
+		assert.Equal(t, packageAuthor, p.Metadata.Maintainer)
+		assert.Equal(t, []string{"a", "b"}, p.Metadata.Dependencies)
+		assert.Equal(t, full, p.Control)
+	})
+
+	t.Run("DependsContinuation", func(t *testing.T) {
+		content := strings.Replace(buildContent(packageName, packageVersion, packageArchitecture).String(), "Depends: a,\n b", "Depends: a (>= 1.0)\n | c,\n b", 1)
+
+		p, err := ParseControlFile(strings.NewReader(content))
+		assert.NoError(t, err)
+		assert.NotNil(t, p)
+		assert.Equal(t, []string{"a (>= 1.0) | c", "b"}, p.Metadata.Dependencies)
+	})
+}
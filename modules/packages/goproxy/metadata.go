@@ -0,0 +1,234 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package goproxy
+
+import (
+	"archive/zip"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+const (
+	// PropertyGoMod is the version property which contains the go.mod file of the module
+	PropertyGoMod = "go.mod"
+
+	maxGoModFileSize = 16 * 1024 * 1024 // https://go.dev/ref/mod#zip-path-size-constraints
+)
+
+var (
+	// ErrInvalidStructure indicates an invalid module zip layout
+	ErrInvalidStructure = errors.New("module zip has an invalid structure")
+	// ErrInvalidModulePath indicates an invalid module path
+	ErrInvalidModulePath = errors.New("module path is invalid")
+	// ErrInvalidVersion indicates an invalid module version
+	ErrInvalidVersion = errors.New("module version is invalid")
+	// ErrGoModFileTooLarge indicates a go.mod file which is too large
+	ErrGoModFileTooLarge = errors.New("go.mod file is too large")
+)
+
+// https://go.dev/ref/mod#versions
+var versionPattern = regexp.MustCompile(`\Av(0|[1-9]\d*)\.(0|[1-9]\d*)\.(0|[1-9]\d*)(-[0-9A-Za-z-]+(\.[0-9A-Za-z-]+)*)?(\+incompatible)?\z`)
+
+var modulePattern = regexp.MustCompile(`(?m)^\s*module\s+("([^"]+)"|(\S+))`)
+
+// Package represents a Go module
+type Package struct {
+	Name    string
+	Version string
+	GoMod   string
+}
+
+// ParsePackage validates the layout of a module zip file and extracts the module path, the version and the go.mod file
+// All files must be located in a "{module}@{version}/" directory.
+// https://go.dev/ref/mod#zip-files
+func ParsePackage(r io.ReaderAt, size int64) (*Package, error) {
+	archive, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, ErrInvalidStructure
+	}
+
+	if len(archive.File) == 0 {
+		return nil, ErrInvalidStructure
+	}
+
+	prefix := archive.File[0].Name
+	if i := strings.Index(prefix, "@"); i != -1 {
+		if j := strings.IndexByte(prefix[i:], '/'); j != -1 {
+			prefix = prefix[:i+j+1]
+		}
+	}
+	if !strings.HasSuffix(prefix, "/") {
+		return nil, ErrInvalidStructure
+	}
+
+	i := strings.LastIndex(prefix, "@")
+	if i == -1 {
+		return nil, ErrInvalidStructure
+	}
+
+	p := &Package{
+		Name:    prefix[:i],
+		Version: prefix[i+1 : len(prefix)-1],
+	}
+
+	if err := CheckModulePath(p.Name); err != nil {
+		return nil, err
+	}
+	if err := CheckVersion(p.Name, p.Version); err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool, len(archive.File))
+	for _, file := range archive.File {
+		if !strings.HasPrefix(file.Name, prefix) {
+			return nil, ErrInvalidStructure
+		}
+
+		name := file.Name[len(prefix):]
+		if name == "" || strings.HasSuffix(name, "/") {
+			// directory entries are not part of a module zip
+			return nil, ErrInvalidStructure
+		}
+		if path.Clean(name) != name || strings.HasPrefix(name, "../") || strings.Contains(name, "\\") {
+			return nil, ErrInvalidStructure
+		}
+
+		lower := strings.ToLower(name)
+		if seen[lower] {
+			return nil, ErrInvalidStructure
+		}
+		seen[lower] = true
+
+		if name != "go.mod" {
+			continue
+		}
+
+		if file.UncompressedSize64 > maxGoModFileSize {
+			return nil, ErrGoModFileTooLarge
+		}
+
+		f, err := file.Open()
+		if err != nil {
+			return nil, ErrInvalidStructure
+		}
+		content, err := io.ReadAll(io.LimitReader(f, maxGoModFileSize))
+		f.Close()
+		if err != nil {
+			return nil, ErrInvalidStructure
+		}
+
+		if modulePathFromGoMod(string(content)) != p.Name {
+			return nil, ErrInvalidModulePath
+		}
+
+		p.GoMod = string(content)
+	}
+
+	if p.GoMod == "" {
+		// modules without a go.mod file get a synthesized one like the official proxy does
+		p.GoMod = fmt.Sprintf("module %s\n", p.Name)
+	}
+
+	return p, nil
+}
+
+func modulePathFromGoMod(content string) string {
+	m := modulePattern.FindStringSubmatch(content)
+	if m == nil {
+		return ""
+	}
+	if m[2] != "" {
+		return m[2]
+	}
+	return m[3]
+}
+
+// CheckModulePath checks if the module path is valid
+// https://go.dev/ref/mod#go-mod-file-ident
+func CheckModulePath(modulePath string) error {
+	if modulePath == "" || strings.HasPrefix(modulePath, "/") || strings.HasSuffix(modulePath, "/") || strings.HasPrefix(modulePath, "-") {
+		return ErrInvalidModulePath
+	}
+	for _, elem := range strings.Split(modulePath, "/") {
+		if elem == "" || elem == "." || elem == ".." || strings.HasSuffix(elem, ".") {
+			return ErrInvalidModulePath
+		}
+		for _, r := range elem {
+			if r > unicode.MaxASCII || !(unicode.IsLetter(r) || unicode.IsDigit(r) || strings.ContainsRune("-._~", r)) {
+				return ErrInvalidModulePath
+			}
+		}
+	}
+	return nil
+}
+
+// CheckVersion checks if the version is a canonical semantic version which matches the major version suffix of the module path
+// https://go.dev/ref/mod#major-version-suffixes
+func CheckVersion(modulePath, version string) error {
+	m := versionPattern.FindStringSubmatch(version)
+	if m == nil {
+		return ErrInvalidVersion
+	}
+
+	// gopkg.in paths encode the major version as ".vN" and are not checked
+	if strings.HasPrefix(modulePath, "gopkg.in/") {
+		return nil
+	}
+
+	major, _ := strconv.Atoi(m[1])
+
+	suffix := ""
+	if i := strings.LastIndexByte(modulePath, '/'); i != -1 {
+		if elem := modulePath[i+1:]; len(elem) > 1 && elem[0] == 'v' {
+			if n, err := strconv.Atoi(elem[1:]); err == nil && n >= 2 && elem[1] != '0' {
+				suffix = elem
+			}
+		}
+	}
+
+	if suffix == "" {
+		if major >= 2 && m[6] == "" {
+			return ErrInvalidVersion
+		}
+	} else if suffix != fmt.Sprintf("v%d", major) || m[6] != "" {
+		return ErrInvalidVersion
+	}
+	return nil
+}
+
+// UnescapePath decodes a module path or version escaped for the proxy protocol where upper case letters are encoded as "!" followed by the lower case letter
+// https://go.dev/ref/mod#goproxy-protocol
+func UnescapePath(escaped string) (string, error) {
+	var sb strings.Builder
+	bang := false
+	for _, r := range escaped {
+		if bang {
+			if r < 'a' || r > 'z' {
+				return "", ErrInvalidModulePath
+			}
+			sb.WriteRune(unicode.ToUpper(r))
+			bang = false
+			continue
+		}
+		if r == '!' {
+			bang = true
+			continue
+		}
+		if unicode.IsUpper(r) {
+			return "", ErrInvalidModulePath
+		}
+		sb.WriteRune(r)
+	}
+	if bang {
+		return "", ErrInvalidModulePath
+	}
+	return sb.String(), nil
+}
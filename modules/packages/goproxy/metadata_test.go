@@ -0,0 +1,136 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package goproxy
+
+import (
+	"archive/zip"
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	modulePath    = "gitea.io/gitea/test"
+	moduleVersion = "v1.0.1"
+)
+
+func createArchive(files map[string]string) *bytes.Reader {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, content := range files {
+		w, _ := zw.Create(name)
+		w.Write([]byte(content))
+	}
+	zw.Close()
+	return bytes.NewReader(buf.Bytes())
+}
+
+func TestParsePackage(t *testing.T) {
+	prefix := modulePath + "@" + moduleVersion + "/"
+
+	t.Run("InvalidStructure", func(t *testing.T) {
+		for _, files := range []map[string]string{
+			{},
+			{"main.go": ""},
+			{prefix + "main.go": "", "other@v1.0.0/main.go": ""},
+			{prefix + "a/../main.go": ""},
+			{prefix + "dir/": ""},
+			{prefix + "README": "", prefix + "readme": ""},
+		} {
+			data := createArchive(files)
+
+			p, err := ParsePackage(data, data.Size())
+			assert.Nil(t, p)
+			assert.ErrorIs(t, err, ErrInvalidStructure)
+		}
+	})
+
+	t.Run("InvalidModulePath", func(t *testing.T) {
+		data := createArchive(map[string]string{
+			prefix + "go.mod": "module other.io/module\n",
+		})
+
+		p, err := ParsePackage(data, data.Size())
+		assert.Nil(t, p)
+		assert.ErrorIs(t, err, ErrInvalidModulePath)
+	})
+
+	t.Run("InvalidVersion", func(t *testing.T) {
+		data := createArchive(map[string]string{
+			modulePath + "@1.0.0/main.go": "",
+		})
+
+		p, err := ParsePackage(data, data.Size())
+		assert.Nil(t, p)
+		assert.ErrorIs(t, err, ErrInvalidVersion)
+	})
+
+	t.Run("Valid", func(t *testing.T) {
+		goMod := "module " + modulePath + "\n\ngo 1.18\n"
+
+		data := createArchive(map[string]string{
+			prefix + "go.mod":        goMod,
+			prefix + "main.go":       "package main",
+			prefix + "internal/a.go": "package internal",
+		})
+
+		p, err := ParsePackage(data, data.Size())
+		assert.NoError(t, err)
+		assert.NotNil(t, p)
+		assert.Equal(t, modulePath, p.Name)
+		assert.Equal(t, moduleVersion, p.Version)
+		assert.Equal(t, goMod, p.GoMod)
+	})
+
+	t.Run("MissingGoMod", func(t *testing.T) {
+		data := createArchive(map[string]string{
+			prefix + "main.go": "package main",
+		})
+
+		p, err := ParsePackage(data, data.Size())
+		assert.NoError(t, err)
+		assert.NotNil(t, p)
+		assert.Equal(t, "module "+modulePath+"\n", p.GoMod)
+	})
+}
+
+func TestCheckVersion(t *testing.T) {
+	for _, c := range []struct {
+		Path    string
+		Version string
+		IsValid bool
+	}{
+		{"example.com/mod", "v0.0.1", true},
+		{"example.com/mod", "v1.2.3-beta.1", true},
+		{"example.com/mod", "v2.0.0+incompatible", true},
+		{"example.com/mod", "v2.0.0", false},
+		{"example.com/mod/v2", "v2.0.0", true},
+		{"example.com/mod/v2", "v3.0.0", false},
+		{"example.com/mod/v2", "v2.0.0+incompatible", false},
+		{"gopkg.in/yaml.v3", "v3.0.1", true},
+		{"example.com/mod", "1.0.0", false},
+		{"example.com/mod", "v1.0", false},
+		{"example.com/mod", "v01.0.0", false},
+	} {
+		err := CheckVersion(c.Path, c.Version)
+		if c.IsValid {
+			assert.NoError(t, err, "%s@%s", c.Path, c.Version)
+		} else {
+			assert.ErrorIs(t, err, ErrInvalidVersion, "%s@%s", c.Path, c.Version)
+		}
+	}
+}
+
+func TestUnescapePath(t *testing.T) {
+	p, err := UnescapePath("github.com/!burnt!sushi/toml")
+	assert.NoError(t, err)
+	assert.Equal(t, "github.com/BurntSushi/toml", p)
+
+	for _, escaped := range []string{"github.com/Burnt", "github.com/!", "github.com/!1"} {
+		_, err := UnescapePath(escaped)
+		assert.ErrorIs(t, err, ErrInvalidModulePath)
+	}
+}
@@ -7,19 +7,21 @@ package maven
 import (
 	"encoding/xml"
 	"io"
+	"strings"
 
 	"code.gitea.io/gitea/modules/validation"
 )
 
 // Metadata represents the metadata of a Maven package
 type Metadata struct {
-	GroupID      string        `json:"group_id,omitempty"`
-	ArtifactID   string        `json:"artifact_id,omitempty"`
-	Name         string        `json:"name,omitempty"`
-	Description  string        `json:"description,omitempty"`
-	ProjectURL   string        `json:"project_url,omitempty"`
-	Licenses     []string      `json:"licenses,omitempty"`
-	Dependencies []*Dependency `json:"dependencies,omitempty"`
+	GroupID       string        `json:"group_id,omitempty"`
+	ArtifactID    string        `json:"artifact_id,omitempty"`
+	Name          string        `json:"name,omitempty"`
+	Description   string        `json:"description,omitempty"`
+	ProjectURL    string        `json:"project_url,omitempty"`
+	RepositoryURL string        `json:"repository_url,omitempty"`
+	Licenses      []string      `json:"licenses,omitempty"`
+	Dependencies  []*Dependency `json:"dependencies,omitempty"`
 }
 
 // Dependency represents a dependency of a Maven package
@@ -37,7 +39,11 @@ type pomStruct struct {
 	Name        string   `xml:"name"`
 	Description string   `xml:"description"`
 	URL         string   `xml:"url"`
-	Licenses    []struct {
+	SCM         struct {
+		URL        string `xml:"url"`
+		Connection string `xml:"connection"`
+	} `xml:"scm"`
+	Licenses []struct {
 		Name         string `xml:"name"`
 		URL          string `xml:"url"`
 		Distribution string `xml:"distribution"`
@@ -61,6 +67,15 @@ func ParsePackageMetaData(r io.Reader) (*Metadata, error) {
 		pom.URL = ""
 	}
 
+	// https://maven.apache.org/scm/scm-url-format.html
+	repositoryURL := pom.SCM.URL
+	if !validation.IsValidURL(repositoryURL) {
+		repositoryURL = ""
+		if parts := strings.SplitN(pom.SCM.Connection, ":", 3); len(parts) == 3 && parts[0] == "scm" {
+			repositoryURL = parts[2]
+		}
+	}
+
 	licenses := make([]string, 0, len(pom.Licenses))
 	for _, l := range pom.Licenses {
 		if l.Name != "" {
@@ -78,12 +93,13 @@ func ParsePackageMetaData(r io.Reader) (*Metadata, error) {
 	}
 
 	return &Metadata{
-		GroupID:      pom.GroupID,
-		ArtifactID:   pom.ArtifactID,
-		Name:         pom.Name,
-		Description:  pom.Description,
-		ProjectURL:   pom.URL,
-		Licenses:     licenses,
-		Dependencies: dependencies,
+		GroupID:       pom.GroupID,
+		ArtifactID:    pom.ArtifactID,
+		Name:          pom.Name,
+		Description:   pom.Description,
+		ProjectURL:    pom.URL,
+		RepositoryURL: repositoryURL,
+		Licenses:      licenses,
+		Dependencies:  dependencies,
 	}, nil
 }
@@ -135,6 +135,27 @@ type Repository struct {
 	URL  string `json:"url"`
 }
 
+// UnmarshalJSON is needed because Repository objects can be strings or objects
+func (r *Repository) UnmarshalJSON(data []byte) error {
+	switch data[0] {
+	case '"':
+		if err := json.Unmarshal(data, &r.URL); err != nil {
+			return err
+		}
+	case '{':
+		var tmp struct {
+			Type string `json:"type"`
+			URL  string `json:"url"`
+		}
+		if err := json.Unmarshal(data, &tmp); err != nil {
+			return err
+		}
+		r.Type = tmp.Type
+		r.URL = tmp.URL
+	}
+	return nil
+}
+
 // PackageAttachment https://github.com/npm/registry/blob/master/docs/REGISTRY-API.md#package
 type PackageAttachment struct {
 	ContentType string `json:"content_type"`
@@ -155,53 +176,15 @@ func ParsePackage(r io.Reader) (*Package, error) {
 	}
 
 	for _, meta := range upload.Versions {
-		if !validateName(meta.Name) {
-			return nil, ErrInvalidPackageName
-		}
-
-		v, err := version.NewSemver(meta.Version)
+		p, err := PackageFromVersionMetadata(meta)
 		if err != nil {
-			return nil, ErrInvalidPackageVersion
-		}
-
-		scope := ""
-		name := meta.Name
-		nameParts := strings.SplitN(meta.Name, "/", 2)
-		if len(nameParts) == 2 {
-			scope = nameParts[0]
-			name = nameParts[1]
-		}
-
-		if !validation.IsValidURL(meta.Homepage) {
-			meta.Homepage = ""
-		}
-
-		p := &Package{
-			Name:     meta.Name,
-			Version:  v.String(),
-			DistTags: make([]string, 0, 1),
-			Metadata: Metadata{
-				Scope:                   scope,
-				Name:                    name,
-				Description:             meta.Description,
-				Author:                  meta.Author.Name,
-				License:                 meta.License,
-				ProjectURL:              meta.Homepage,
-				Keywords:                meta.Keywords,
-				Dependencies:            meta.Dependencies,
-				DevelopmentDependencies: meta.DevDependencies,
-				PeerDependencies:        meta.PeerDependencies,
-				OptionalDependencies:    meta.OptionalDependencies,
-				Readme:                  meta.Readme,
-			},
+			return nil, err
 		}
 
 		for tag := range upload.DistTags {
 			p.DistTags = append(p.DistTags, tag)
 		}
 
-		p.Filename = strings.ToLower(fmt.Sprintf("%s-%s.tgz", name, p.Version))
-
 		attachment := func() *PackageAttachment {
 			for _, a := range upload.Attachments {
 				return a
@@ -218,24 +201,7 @@ func ParsePackage(r io.Reader) (*Package, error) {
 		}
 		p.Data = data
 
-		integrity := strings.SplitN(meta.Dist.Integrity, "-", 2)
-		if len(integrity) != 2 {
-			return nil, ErrInvalidIntegrity
-		}
-		integrityHash, err := base64.StdEncoding.DecodeString(integrity[1])
-		if err != nil {
-			return nil, ErrInvalidIntegrity
-		}
-		var hash []byte
-		switch integrity[0] {
-		case "sha1":
-			tmp := sha1.Sum(data)
-			hash = tmp[:]
-		case "sha512":
-			tmp := sha512.Sum512(data)
-			hash = tmp[:]
-		}
-		if !bytes.Equal(integrityHash, hash) {
+		if !ValidateIntegrity(meta.Dist.Integrity, data) {
 			return nil, ErrInvalidIntegrity
 		}
 
@@ -245,6 +211,83 @@ func ParsePackage(r io.Reader) (*Package, error) {
 	return nil, ErrInvalidPackage
 }
 
+// PackageFromVersionMetadata creates a package without data from the metadata of a package version
+func PackageFromVersionMetadata(meta *PackageMetadataVersion) (*Package, error) {
+	if !validateName(meta.Name) {
+		return nil, ErrInvalidPackageName
+	}
+
+	v, err := version.NewSemver(meta.Version)
+	if err != nil {
+		return nil, ErrInvalidPackageVersion
+	}
+
+	scope := ""
+	name := meta.Name
+	nameParts := strings.SplitN(meta.Name, "/", 2)
+	if len(nameParts) == 2 {
+		scope = nameParts[0]
+		name = nameParts[1]
+	}
+
+	projectURL := meta.Homepage
+	if !validation.IsValidURL(projectURL) {
+		projectURL = ""
+	}
+
+	p := &Package{
+		Name:     meta.Name,
+		Version:  v.String(),
+		DistTags: make([]string, 0, 1),
+		Metadata: Metadata{
+			Scope:                   scope,
+			Name:                    name,
+			Description:             meta.Description,
+			Author:                  meta.Author.Name,
+			License:                 meta.License,
+			ProjectURL:              projectURL,
+			RepositoryURL:           meta.Repository.URL,
+			Keywords:                meta.Keywords,
+			Dependencies:            meta.Dependencies,
+			DevelopmentDependencies: meta.DevDependencies,
+			PeerDependencies:        meta.PeerDependencies,
+			OptionalDependencies:    meta.OptionalDependencies,
+			Readme:                  meta.Readme,
+		},
+	}
+
+	p.Filename = strings.ToLower(fmt.Sprintf("%s-%s.tgz", name, p.Version))
+
+	return p, nil
+}
+
+// ValidateIntegrity checks if the data matches the "sha1-" or "sha512-" integrity string
+func ValidateIntegrity(integrity string, data []byte) bool {
+	hashSHA1 := sha1.Sum(data)
+	hashSHA512 := sha512.Sum512(data)
+	return ValidateIntegritySums(integrity, hashSHA1[:], hashSHA512[:])
+}
+
+// ValidateIntegritySums checks if the hashes of the data match the "sha1-" or "sha512-" integrity string
+func ValidateIntegritySums(integrity string, hashSHA1, hashSHA512 []byte) bool {
+	parts := strings.SplitN(integrity, "-", 2)
+	if len(parts) != 2 {
+		return false
+	}
+	integrityHash, err := base64.StdEncoding.DecodeString(parts[1])
+	if err != nil {
+		return false
+	}
+	var hash []byte
+	switch parts[0] {
+	case "sha1":
+		hash = hashSHA1
+	case "sha512":
+		hash = hashSHA512
+	}
+	return hash != nil && bytes.Equal(integrityHash, hash)
+}
+
 func validateName(name string) bool {
 	if strings.TrimSpace(name) != name {
 		return false
@@ -0,0 +1,89 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package packages
+
+import (
+	"bytes"
+	"io"
+	"strings"
+
+	"github.com/keybase/go-crypto/openpgp"
+	"github.com/keybase/go-crypto/openpgp/armor"
+	"github.com/keybase/go-crypto/openpgp/clearsign"
+)
+
+// GeneratePGPKeyPair creates a new armored PGP key pair which can be used to sign repository files
+func GeneratePGPKeyPair(name, comment string) (string, string, error) {
+	e, err := openpgp.NewEntity(name, comment, "", nil)
+	if err != nil {
+		return "", "", err
+	}
+
+	var priv strings.Builder
+	var pub strings.Builder
+
+	w, err := armor.Encode(&priv, openpgp.PrivateKeyType, nil)
+	if err != nil {
+		return "", "", err
+	}
+	if err := e.SerializePrivate(w, nil); err != nil {
+		return "", "", err
+	}
+	w.Close()
+
+	w, err = armor.Encode(&pub, openpgp.PublicKeyType, nil)
+	if err != nil {
+		return "", "", err
+	}
+	if err := e.Serialize(w); err != nil {
+		return "", "", err
+	}
+	w.Close()
+
+	return priv.String(), pub.String(), nil
+}
+
+func readPGPPrivateKey(privateKey string) (*openpgp.Entity, error) {
+	keyring, err := openpgp.ReadArmoredKeyRing(strings.NewReader(privateKey))
+	if err != nil {
+		return nil, err
+	}
+	return keyring[0], nil
+}
+
+// PGPClearSign creates an inline signed message of the content
+func PGPClearSign(privateKey string, content []byte) ([]byte, error) {
+	e, err := readPGPPrivateKey(privateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	w, err := clearsign.Encode(&buf, e.PrivateKey, nil)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(content); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// PGPDetachSign creates an armored detached signature of the content
+func PGPDetachSign(privateKey string, content io.Reader) ([]byte, error) {
+	e, err := readPGPPrivateKey(privateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := openpgp.ArmoredDetachSign(&buf, e, content, nil); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,538 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package rpm
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"code.gitea.io/gitea/modules/validation"
+)
+
+const (
+	PropertyMetadata = "rpm.metadata"
+
+	// RepositoryPackage is the name of the internal package which contains the repository metadata files
+	RepositoryPackage = "_repository"
+	// RepositoryVersion is the version of the internal package which contains the repository metadata files
+	RepositoryVersion = "_repository"
+)
+
+var (
+	// ErrInvalidLead indicates an invalid RPM lead
+	ErrInvalidLead = errors.New("Package lead is invalid")
+	// ErrInvalidHeader indicates an invalid RPM header
+	ErrInvalidHeader = errors.New("Package header is invalid")
+	// ErrInvalidName indicates an invalid package name
+	ErrInvalidName = errors.New("Package name is invalid")
+	// ErrInvalidVersion indicates an invalid package version
+	ErrInvalidVersion = errors.New("Package version is invalid")
+	// ErrInvalidArchitecture indicates an invalid package architecture
+	ErrInvalidArchitecture = errors.New("Package architecture is invalid")
+
+	namePattern         = regexp.MustCompile(`\A[a-zA-Z0-9][a-zA-Z0-9._+-]*\z`)
+	versionPattern      = regexp.MustCompile(`\A[a-zA-Z0-9._+~^]+\z`)
+	architecturePattern = regexp.MustCompile(`\A[a-zA-Z0-9_]+\z`)
+)
+
+// https://github.com/rpm-software-management/rpm/blob/master/include/rpm/rpmtag.h
+const (
+	tagName            = 1000
+	tagVersion         = 1001
+	tagRelease         = 1002
+	tagEpoch           = 1003
+	tagSummary         = 1004
+	tagDescription     = 1005
+	tagBuildTime       = 1006
+	tagBuildHost       = 1007
+	tagSize            = 1009
+	tagVendor          = 1011
+	tagLicense         = 1014
+	tagPackager        = 1015
+	tagGroup           = 1016
+	tagURL             = 1020
+	tagArch            = 1022
+	tagFileModes       = 1030
+	tagFileFlags       = 1037
+	tagSourceRpm       = 1044
+	tagArchiveSize     = 1046
+	tagProvideName     = 1047
+	tagRequireFlags    = 1048
+	tagRequireName     = 1049
+	tagRequireVersion  = 1050
+	tagConflictFlags   = 1053
+	tagConflictName    = 1054
+	tagConflictVersion = 1055
+	tagChangelogTime   = 1080
+	tagChangelogName   = 1081
+	tagChangelogText   = 1082
+	tagObsoleteName    = 1090
+	tagProvideFlags    = 1112
+	tagProvideVersion  = 1113
+	tagObsoleteFlags   = 1114
+	tagObsoleteVersion = 1115
+	tagDirIndexes      = 1116
+	tagBaseNames       = 1117
+	tagDirNames        = 1118
+
+	typeInt8        = 2
+	typeInt16       = 3
+	typeInt32       = 4
+	typeInt64       = 5
+	typeString      = 6
+	typeStringArray = 8
+	typeI18NString  = 9
+
+	senseLess    = 1 << 1
+	senseGreater = 1 << 2
+	senseEqual   = 1 << 3
+	senseRpmlib  = 1 << 24
+
+	fileFlagGhost       = 1 << 6
+	fileModeTypeMask    = 0o170000
+	fileModeDirectory   = 0o040000
+	fileModeExecuteMask = 0o111
+
+	leadSize        = 96
+	headerIndexSize = 16
+)
+
+var (
+	leadMagic   = []byte{0xed, 0xab, 0xee, 0xdb}
+	headerMagic = []byte{0x8e, 0xad, 0xe8, 0x01}
+)
+
+// Package represents a RPM package
+type Package struct {
+	Name            string
+	Version         string
+	VersionMetadata *VersionMetadata
+	FileMetadata    *FileMetadata
+}
+
+// VersionMetadata represents the metadata of a RPM package version
+type VersionMetadata struct {
+	License     string `json:"license,omitempty"`
+	ProjectURL  string `json:"project_url,omitempty"`
+	Summary     string `json:"summary,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// FileMetadata represents the metadata of a RPM package file
+type FileMetadata struct {
+	Architecture  string       `json:"architecture,omitempty"`
+	Epoch         string       `json:"epoch,omitempty"`
+	Version       string       `json:"version,omitempty"`
+	Release       string       `json:"release,omitempty"`
+	Vendor        string       `json:"vendor,omitempty"`
+	Group         string       `json:"group,omitempty"`
+	Packager      string       `json:"packager,omitempty"`
+	SourceRpm     string       `json:"source_rpm,omitempty"`
+	BuildHost     string       `json:"build_host,omitempty"`
+	BuildTime     uint64       `json:"build_time,omitempty"`
+	InstalledSize uint64       `json:"installed_size,omitempty"`
+	ArchiveSize   uint64       `json:"archive_size,omitempty"`
+	HeaderStart   uint64       `json:"header_start,omitempty"`
+	HeaderEnd     uint64       `json:"header_end,omitempty"`
+	Provides      []*Entry     `json:"provide,omitempty"`
+	Requires      []*Entry     `json:"require,omitempty"`
+	Conflicts     []*Entry     `json:"conflict,omitempty"`
+	Obsoletes     []*Entry     `json:"obsolete,omitempty"`
+	Files         []*File      `json:"files,omitempty"`
+	Changelogs    []*Changelog `json:"changelogs,omitempty"`
+}
+
+// Entry is a dependency entry like a provided capability or a requirement
+type Entry struct {
+	Name    string `json:"name" xml:"name,attr"`
+	Flags   string `json:"flags,omitempty" xml:"flags,attr,omitempty"`
+	Epoch   string `json:"epoch,omitempty" xml:"epoch,attr,omitempty"`
+	Version string `json:"version,omitempty" xml:"ver,attr,omitempty"`
+	Release string `json:"release,omitempty" xml:"rel,attr,omitempty"`
+}
+
+// File is a file contained in the package
+type File struct {
+	Path         string `json:"path" xml:",chardata"`
+	Type         string `json:"type,omitempty" xml:"type,attr,omitempty"`
+	IsExecutable bool   `json:"is_executable,omitempty" xml:"-"`
+}
+
+// Changelog is a changelog entry of the package
+type Changelog struct {
+	Author string `json:"author,omitempty"`
+	Date   uint64 `json:"date,omitempty"`
+	Text   string `json:"text,omitempty"`
+}
+
+type indexEntry struct {
+	Type   uint32
+	Offset uint32
+	Count  uint32
+}
+
+type header struct {
+	Entries map[uint32]*indexEntry
+	Data    []byte
+}
+
+// countingReader keeps track of the position in the stream
+type countingReader struct {
+	r io.Reader
+	n uint64
+}
+
+func (cr *countingReader) Read(p []byte) (int, error) {
+	n, err := cr.r.Read(p)
+	cr.n += uint64(n)
+	return n, err
+}
+
+// ParsePackage parses the RPM package file
+// https://rpm-software-management.github.io/rpm/manual/format.html
+func ParsePackage(r io.Reader) (*Package, error) {
+	cr := &countingReader{r: r}
+
+	lead := make([]byte, leadSize)
+	if _, err := io.ReadFull(cr, lead); err != nil {
+		return nil, ErrInvalidLead
+	}
+	if !bytes.Equal(lead[:4], leadMagic) {
+		return nil, ErrInvalidLead
+	}
+
+	signature, err := readHeader(cr)
+	if err != nil {
+		return nil, err
+	}
+
+	// The signature header is padded to a multiple of 8 bytes
+	if padding := (8 - len(signature.Data)%8) % 8; padding > 0 {
+		if _, err := io.CopyN(io.Discard, cr, int64(padding)); err != nil {
+			return nil, ErrInvalidHeader
+		}
+	}
+
+	headerStart := cr.n
+
+	h, err := readHeader(cr)
+	if err != nil {
+		return nil, err
+	}
+
+	headerEnd := cr.n
+
+	return parseHeader(h, headerStart, headerEnd)
+}
+
+func readHeader(r io.Reader) (*header, error) {
+	var intro [16]byte
+	if _, err := io.ReadFull(r, intro[:]); err != nil {
+		return nil, ErrInvalidHeader
+	}
+	if !bytes.Equal(intro[:4], headerMagic) {
+		return nil, ErrInvalidHeader
+	}
+
+	indexCount := binary.BigEndian.Uint32(intro[8:12])
+	dataSize := binary.BigEndian.Uint32(intro[12:16])
+	if indexCount > 0xffff || dataSize > 256*1024*1024 {
+		return nil, ErrInvalidHeader
+	}
+
+	index := make([]byte, indexCount*headerIndexSize)
+	if _, err := io.ReadFull(r, index); err != nil {
+		return nil, ErrInvalidHeader
+	}
+
+	h := &header{
+		Entries: make(map[uint32]*indexEntry, indexCount),
+		Data:    make([]byte, dataSize),
+	}
+	if _, err := io.ReadFull(r, h.Data); err != nil {
+		return nil, ErrInvalidHeader
+	}
+
+	for i := uint32(0); i < indexCount; i++ {
+		b := index[i*headerIndexSize:]
+		e := &indexEntry{
+			Type:   binary.BigEndian.Uint32(b[4:8]),
+			Offset: binary.BigEndian.Uint32(b[8:12]),
+			Count:  binary.BigEndian.Uint32(b[12:16]),
+		}
+		if e.Offset >= dataSize && e.Count > 0 {
+			return nil, ErrInvalidHeader
+		}
+		h.Entries[binary.BigEndian.Uint32(b[0:4])] = e
+	}
+
+	return h, nil
+}
+
+func (h *header) getStrings(tag uint32) []string {
+	e, ok := h.Entries[tag]
+	if !ok {
+		return nil
+	}
+	if e.Type != typeString && e.Type != typeStringArray && e.Type != typeI18NString {
+		return nil
+	}
+
+	count := e.Count
+	if e.Type == typeString {
+		count = 1
+	}
+
+	values := make([]string, 0, count)
+	data := h.Data[e.Offset:]
+	for i := uint32(0); i < count; i++ {
+		end := bytes.IndexByte(data, 0)
+		if end == -1 {
+			break
+		}
+		values = append(values, string(data[:end]))
+		data = data[end+1:]
+	}
+	return values
+}
+
+func (h *header) getString(tag uint32) string {
+	values := h.getStrings(tag)
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
+
+func (h *header) getNumbers(tag uint32) []uint64 {
+	e, ok := h.Entries[tag]
+	if !ok {
+		return nil
+	}
+
+	var size uint32
+	switch e.Type {
+	case typeInt8:
+		size = 1
+	case typeInt16:
+		size = 2
+	case typeInt32:
+		size = 4
+	case typeInt64:
+		size = 8
+	default:
+		return nil
+	}
+
+	if uint64(e.Offset)+uint64(e.Count)*uint64(size) > uint64(len(h.Data)) {
+		return nil
+	}
+
+	values := make([]uint64, 0, e.Count)
+	for i := uint32(0); i < e.Count; i++ {
+		b := h.Data[e.Offset+i*size:]
+		switch size {
+		case 1:
+			values = append(values, uint64(b[0]))
+		case 2:
+			values = append(values, uint64(binary.BigEndian.Uint16(b)))
+		case 4:
+			values = append(values, uint64(binary.BigEndian.Uint32(b)))
+		case 8:
+			values = append(values, binary.BigEndian.Uint64(b))
+		}
+	}
+	return values
+}
+
+func (h *header) getNumber(tag uint32) uint64 {
+	values := h.getNumbers(tag)
+	if len(values) == 0 {
+		return 0
+	}
+	return values[0]
+}
+
+func parseHeader(h *header, headerStart, headerEnd uint64) (*Package, error) {
+	name := h.getString(tagName)
+	if !namePattern.MatchString(name) {
+		return nil, ErrInvalidName
+	}
+
+	version := h.getString(tagVersion)
+	release := h.getString(tagRelease)
+	if !versionPattern.MatchString(version) || !versionPattern.MatchString(release) {
+		return nil, ErrInvalidVersion
+	}
+
+	architecture := h.getString(tagArch)
+	if !architecturePattern.MatchString(architecture) {
+		return nil, ErrInvalidArchitecture
+	}
+
+	var epoch string
+	if e, ok := h.Entries[tagEpoch]; ok && e.Count > 0 {
+		epoch = strconv.FormatUint(h.getNumber(tagEpoch), 10)
+	}
+
+	projectURL := h.getString(tagURL)
+	if !validation.IsValidURL(projectURL) {
+		projectURL = ""
+	}
+
+	p := &Package{
+		Name:    name,
+		Version: fmt.Sprintf("%s-%s", version, release),
+		VersionMetadata: &VersionMetadata{
+			License:     h.getString(tagLicense),
+			ProjectURL:  projectURL,
+			Summary:     h.getString(tagSummary),
+			Description: h.getString(tagDescription),
+		},
+		FileMetadata: &FileMetadata{
+			Architecture:  architecture,
+			Epoch:         epoch,
+			Version:       version,
+			Release:       release,
+			Vendor:        h.getString(tagVendor),
+			Group:         h.getString(tagGroup),
+			Packager:      h.getString(tagPackager),
+			SourceRpm:     h.getString(tagSourceRpm),
+			BuildHost:     h.getString(tagBuildHost),
+			BuildTime:     h.getNumber(tagBuildTime),
+			InstalledSize: h.getNumber(tagSize),
+			ArchiveSize:   h.getNumber(tagArchiveSize),
+			HeaderStart:   headerStart,
+			HeaderEnd:     headerEnd,
+			Provides:      h.getEntries(tagProvideName, tagProvideFlags, tagProvideVersion),
+			Requires:      h.getEntries(tagRequireName, tagRequireFlags, tagRequireVersion),
+			Conflicts:     h.getEntries(tagConflictName, tagConflictFlags, tagConflictVersion),
+			Obsoletes:     h.getEntries(tagObsoleteName, tagObsoleteFlags, tagObsoleteVersion),
+			Files:         h.getFiles(),
+			Changelogs:    h.getChangelogs(),
+		},
+	}
+
+	return p, nil
+}
+
+func (h *header) getEntries(nameTag, flagsTag, versionTag uint32) []*Entry {
+	names := h.getStrings(nameTag)
+	flags := h.getNumbers(flagsTag)
+	versions := h.getStrings(versionTag)
+
+	entries := make([]*Entry, 0, len(names))
+	for i, name := range names {
+		var flag uint64
+		if i < len(flags) {
+			flag = flags[i]
+		}
+		if flag&senseRpmlib != 0 || strings.HasPrefix(name, "rpmlib(") {
+			continue
+		}
+
+		e := &Entry{
+			Name: name,
+		}
+
+		switch flag & (senseLess | senseGreater | senseEqual) {
+		case senseLess:
+			e.Flags = "LT"
+		case senseGreater:
+			e.Flags = "GT"
+		case senseEqual:
+			e.Flags = "EQ"
+		case senseLess | senseEqual:
+			e.Flags = "LE"
+		case senseGreater | senseEqual:
+			e.Flags = "GE"
+		}
+
+		if i < len(versions) && versions[i] != "" {
+			e.Epoch, e.Version, e.Release = splitEVR(versions[i])
+		}
+
+		entries = append(entries, e)
+	}
+	return entries
+}
+
+// splitEVR splits a "epoch:version-release" string
+func splitEVR(evr string) (string, string, string) {
+	epoch := "0"
+	if i := strings.IndexByte(evr, ':'); i != -1 {
+		epoch = evr[:i]
+		evr = evr[i+1:]
+	}
+
+	var release string
+	if i := strings.LastIndexByte(evr, '-'); i != -1 {
+		release = evr[i+1:]
+		evr = evr[:i]
+	}
+
+	return epoch, evr, release
+}
+
+func (h *header) getFiles() []*File {
+	baseNames := h.getStrings(tagBaseNames)
+	dirNames := h.getStrings(tagDirNames)
+	dirIndexes := h.getNumbers(tagDirIndexes)
+	fileModes := h.getNumbers(tagFileModes)
+	fileFlags := h.getNumbers(tagFileFlags)
+
+	files := make([]*File, 0, len(baseNames))
+	for i, baseName := range baseNames {
+		if i >= len(dirIndexes) || dirIndexes[i] >= uint64(len(dirNames)) {
+			continue
+		}
+
+		f := &File{
+			Path: dirNames[dirIndexes[i]] + baseName,
+		}
+
+		if i < len(fileModes) {
+			mode := fileModes[i]
+			if mode&fileModeTypeMask == fileModeDirectory {
+				f.Type = "dir"
+			} else {
+				f.IsExecutable = mode&fileModeExecuteMask != 0
+			}
+		}
+		if i < len(fileFlags) && fileFlags[i]&fileFlagGhost != 0 {
+			f.Type = "ghost"
+		}
+
+		files = append(files, f)
+	}
+	return files
+}
+
+func (h *header) getChangelogs() []*Changelog {
+	times := h.getNumbers(tagChangelogTime)
+	names := h.getStrings(tagChangelogName)
+	texts := h.getStrings(tagChangelogText)
+
+	changelogs := make([]*Changelog, 0, len(times))
+	for i, t := range times {
+		c := &Changelog{
+			Date: t,
+		}
+		if i < len(names) {
+			c.Author = names[i]
+		}
+		if i < len(texts) {
+			c.Text = texts[i]
+		}
+		changelogs = append(changelogs, c)
+	}
+	return changelogs
+}
@@ -0,0 +1,192 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package rpm
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	packageName         = "gitea"
+	packageVersion      = "1.0.1"
+	packageRelease      = "2"
+	packageArchitecture = "x86_64"
+	summary             = "Gitea package"
+	description         = "Description with multiple lines."
+	license             = "MIT"
+	projectURL          = "https://gitea.io"
+)
+
+type testTag struct {
+	Tag   uint32
+	Type  uint32
+	Value interface{}
+}
+
+func writeHeader(buf *bytes.Buffer, tags []testTag) {
+	var index bytes.Buffer
+	var data bytes.Buffer
+	for _, t := range tags {
+		var count int
+		switch v := t.Value.(type) {
+		case []uint32:
+			for data.Len()%4 != 0 {
+				data.WriteByte(0)
+			}
+			count = len(v)
+		case []uint16:
+			for data.Len()%2 != 0 {
+				data.WriteByte(0)
+			}
+			count = len(v)
+		case string:
+			count = 1
+		case []string:
+			count = len(v)
+		}
+
+		binary.Write(&index, binary.BigEndian, []uint32{t.Tag, t.Type, uint32(data.Len()), uint32(count)})
+
+		switch v := t.Value.(type) {
+		case string:
+			data.WriteString(v + "\x00")
+		case []string:
+			for _, s := range v {
+				data.WriteString(s + "\x00")
+			}
+		default:
+			binary.Write(&data, binary.BigEndian, v)
+		}
+	}
+
+	buf.Write(headerMagic)
+	buf.Write([]byte{0, 0, 0, 0})
+	binary.Write(buf, binary.BigEndian, uint32(len(tags)))
+	binary.Write(buf, binary.BigEndian, uint32(data.Len()))
+	buf.Write(index.Bytes())
+	buf.Write(data.Bytes())
+}
+
+func createPackage(name, version, architecture string) io.Reader {
+	var buf bytes.Buffer
+
+	lead := make([]byte, leadSize)
+	copy(lead, leadMagic)
+	buf.Write(lead)
+
+	writeHeader(&buf, []testTag{
+		{1000, typeString, "dummy"},
+	})
+	for buf.Len()%8 != 0 {
+		buf.WriteByte(0)
+	}
+
+	writeHeader(&buf, []testTag{
+		{tagName, typeString, name},
+		{tagVersion, typeString, version},
+		{tagRelease, typeString, packageRelease},
+		{tagSummary, typeI18NString, []string{summary}},
+		{tagDescription, typeI18NString, []string{description}},
+		{tagLicense, typeString, license},
+		{tagURL, typeString, projectURL},
+		{tagArch, typeString, architecture},
+		{tagSize, typeInt32, []uint32{1234}},
+		{tagProvideName, typeStringArray, []string{name, name + "(x86-64)"}},
+		{tagProvideFlags, typeInt32, []uint32{senseEqual, senseEqual}},
+		{tagProvideVersion, typeStringArray, []string{version + "-" + packageRelease, "1:" + version + "-" + packageRelease}},
+		{tagRequireName, typeStringArray, []string{"rpmlib(CompressedFileNames)", "libc.so.6"}},
+		{tagRequireFlags, typeInt32, []uint32{senseLess | senseEqual | senseRpmlib, 0}},
+		{tagRequireVersion, typeStringArray, []string{"3.0.4-1", ""}},
+		{tagDirNames, typeStringArray, []string{"/usr/bin/", "/usr/share/gitea/"}},
+		{tagBaseNames, typeStringArray, []string{"gitea", "gitea", "README"}},
+		{tagDirIndexes, typeInt32, []uint32{0, 1, 1}},
+		{tagFileModes, typeInt16, []uint16{0o100755, 0o040755, 0o100644}},
+	})
+
+	return &buf
+}
+
+func TestParsePackage(t *testing.T) {
+	t.Run("InvalidLead", func(t *testing.T) {
+		p, err := ParsePackage(strings.NewReader("dummy"))
+		assert.Nil(t, p)
+		assert.ErrorIs(t, err, ErrInvalidLead)
+	})
+
+	t.Run("InvalidHeader", func(t *testing.T) {
+		lead := make([]byte, leadSize)
+		copy(lead, leadMagic)
+
+		p, err := ParsePackage(bytes.NewReader(append(lead, []byte("dummy")...)))
+		assert.Nil(t, p)
+		assert.ErrorIs(t, err, ErrInvalidHeader)
+	})
+
+	t.Run("InvalidName", func(t *testing.T) {
+		for _, name := range []string{"", "-gitea", "a b"} {
+			p, err := ParsePackage(createPackage(name, packageVersion, packageArchitecture))
+			assert.Nil(t, p)
+			assert.ErrorIs(t, err, ErrInvalidName)
+		}
+	})
+
+	t.Run("InvalidVersion", func(t *testing.T) {
+		for _, version := range []string{"", "1-0", "1 0"} {
+			p, err := ParsePackage(createPackage(packageName, version, packageArchitecture))
+			assert.Nil(t, p)
+			assert.ErrorIs(t, err, ErrInvalidVersion)
+		}
+	})
+
+	t.Run("InvalidArchitecture", func(t *testing.T) {
+		for _, architecture := range []string{"", "x86-64"} {
+			p, err := ParsePackage(createPackage(packageName, packageVersion, architecture))
+			assert.Nil(t, p)
+			assert.ErrorIs(t, err, ErrInvalidArchitecture)
+		}
+	})
+
+	t.Run("Valid", func(t *testing.T) {
+		p, err := ParsePackage(createPackage(packageName, packageVersion, packageArchitecture))
+		assert.NoError(t, err)
+		assert.NotNil(t, p)
+
+		assert.Equal(t, packageName, p.Name)
+		assert.Equal(t, packageVersion+"-"+packageRelease, p.Version)
+		assert.Equal(t, summary, p.VersionMetadata.Summary)
+		assert.Equal(t, description, p.VersionMetadata.Description)
+		assert.Equal(t, license, p.VersionMetadata.License)
+		assert.Equal(t, projectURL, p.VersionMetadata.ProjectURL)
+
+		fm := p.FileMetadata
+		assert.Equal(t, packageArchitecture, fm.Architecture)
+		assert.Equal(t, packageVersion, fm.Version)
+		assert.Equal(t, packageRelease, fm.Release)
+		assert.Empty(t, fm.Epoch)
+		assert.EqualValues(t, 1234, fm.InstalledSize)
+		assert.EqualValues(t, leadSize+40, fm.HeaderStart)
+		assert.Greater(t, fm.HeaderEnd, fm.HeaderStart)
+
+		assert.Equal(t, []*Entry{
+			{Name: packageName, Flags: "EQ", Epoch: "0", Version: packageVersion, Release: packageRelease},
+			{Name: packageName + "(x86-64)", Flags: "EQ", Epoch: "1", Version: packageVersion, Release: packageRelease},
+		}, fm.Provides)
+		assert.Equal(t, []*Entry{{Name: "libc.so.6"}}, fm.Requires)
+		assert.Empty(t, fm.Conflicts)
+		assert.Empty(t, fm.Obsoletes)
+
+		assert.Equal(t, []*File{
+			{Path: "/usr/bin/gitea", IsExecutable: true},
+			{Path: "/usr/share/gitea/gitea", Type: "dir"},
+			{Path: "/usr/share/gitea/README"},
+		}, fm.Files)
+	})
+}
@@ -0,0 +1,22 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package setting
+
+// Actions settings
+var (
+	Actions = struct {
+		LogStorage Storage
+		Enabled    bool
+	}{
+		Enabled: false,
+	}
+)
+
+func newActions() {
+	sec := Cfg.Section("actions")
+	Actions.Enabled = sec.Key("ENABLED").MustBool(false)
+
+	Actions.LogStorage = getStorage("actions_log", "", nil)
+}
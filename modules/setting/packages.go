@@ -5,22 +5,31 @@
 package setting
 
 import (
+	"math"
 	"net/url"
 	"os"
 	"path/filepath"
+	"time"
 
 	"code.gitea.io/gitea/modules/log"
+
+	"github.com/dustin/go-humanize"
 )
 
 // Package registry settings
 var (
 	Packages = struct {
 		Storage
-		Enabled           bool
-		ChunkedUploadPath string
-		RegistryHost      string
+		Enabled              bool
+		ChunkedUploadPath    string
+		RegistryHost         string
+		ProxyAllowedHostList string
+		ProxyDownloadTimeout time.Duration
+		ProxyLimitSize       int64
 	}{
-		Enabled: true,
+		Enabled:              true,
+		ProxyDownloadTimeout: 60 * time.Second,
+		ProxyLimitSize:       1024 * 1024 * 1024,
 	}
 )
 
@@ -40,6 +49,17 @@ func newPackages() {
 		Packages.ChunkedUploadPath = filepath.ToSlash(filepath.Join(AppDataPath, Packages.ChunkedUploadPath))
 	}
 
+	Packages.ProxyAllowedHostList = sec.Key("PROXY_ALLOWED_HOST_LIST").MustString("")
+	Packages.ProxyDownloadTimeout = sec.Key("PROXY_DOWNLOAD_TIMEOUT").MustDuration(60 * time.Second)
+	Packages.ProxyLimitSize = -1
+	if value := sec.Key("PROXY_LIMIT_SIZE").MustString("1GiB"); value != "-1" {
+		size, err := humanize.ParseBytes(value)
+		if err != nil || size >= math.MaxInt64 {
+			log.Fatal("Failed to parse packages.PROXY_LIMIT_SIZE: %q", value)
+		}
+		Packages.ProxyLimitSize = int64(size)
+	}
+
 	if err := os.MkdirAll(Packages.ChunkedUploadPath, os.ModePerm); err != nil {
 		log.Error("Unable to create chunked upload directory: %s (%v)", Packages.ChunkedUploadPath, err)
 	}
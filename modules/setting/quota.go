@@ -0,0 +1,53 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package setting
+
+import (
+	"math"
+
+	"code.gitea.io/gitea/modules/log"
+
+	"github.com/dustin/go-humanize"
+)
+
+// Quota settings
+var Quota = struct {
+	Enabled            bool
+	DefaultTotal       int64
+	DefaultGit         int64
+	DefaultLFS         int64
+	DefaultPackages    int64
+	DefaultAttachments int64
+}{
+	Enabled:            false,
+	DefaultTotal:       -1,
+	DefaultGit:         -1,
+	DefaultLFS:         -1,
+	DefaultPackages:    -1,
+	DefaultAttachments: -1,
+}
+
+func newQuotaService() {
+	sec := Cfg.Section("quota")
+	Quota.Enabled = sec.Key("ENABLED").MustBool(false)
+
+	mustQuotaSize := func(key string) int64 {
+		value := sec.Key(key).MustString("-1")
+		if value == "-1" {
+			return -1
+		}
+		size, err := humanize.ParseBytes(value)
+		if err != nil || size > math.MaxInt64 {
+			log.Fatal("Failed to parse quota.%s: %q", key, value)
+		}
+		return int64(size)
+	}
+
+	Quota.DefaultTotal = mustQuotaSize("DEFAULT_TOTAL")
+	Quota.DefaultGit = mustQuotaSize("DEFAULT_GIT")
+	Quota.DefaultLFS = mustQuotaSize("DEFAULT_LFS")
+	Quota.DefaultPackages = mustQuotaSize("DEFAULT_PACKAGES")
+	Quota.DefaultAttachments = mustQuotaSize("DEFAULT_ATTACHMENTS")
+}
@@ -126,6 +126,9 @@ var (
 
 	// Packages represents packages storage
 	Packages ObjectStorage
+
+	// Actions represents actions storage
+	Actions ObjectStorage
 )
 
 // Init init the stoarge
@@ -150,7 +153,11 @@ func Init() error {
 		return err
 	}
 
-	return initPackages()
+	if err := initPackages(); err != nil {
+		return err
+	}
+
+	return initActions()
 }
 
 // NewStorage takes a storage type and some config and returns an ObjectStorage or an error
@@ -201,3 +208,9 @@ func initPackages() (err error) {
 	Packages, err = NewStorage(setting.Packages.Storage.Type, &setting.Packages.Storage)
 	return
 }
+
+func initActions() (err error) {
+	log.Info("Initialising Actions storage with type: %s", setting.Actions.LogStorage.Type)
+	Actions, err = NewStorage(setting.Actions.LogStorage.Type, &setting.Actions.LogStorage)
+	return
+}
@@ -0,0 +1,72 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package structs
+
+import (
+	"time"
+)
+
+// ActionRunner represents a runner which executes the jobs of workflows
+type ActionRunner struct {
+	ID     int64    `json:"id"`
+	Name   string   `json:"name"`
+	Labels []string `json:"labels"`
+	// the token is only returned when the runner is created
+	Token string `json:"token,omitempty"`
+	// swagger:strfmt date-time
+	LastOnline *time.Time `json:"last_online,omitempty"`
+	// swagger:strfmt date-time
+	Created time.Time `json:"created_at"`
+}
+
+// CreateActionRunnerOption options for creating a runner
+type CreateActionRunnerOption struct {
+	// required: true
+	Name string `json:"name" binding:"Required;MaxSize(255)"`
+	// the job labels (runs-on) the runner is able to execute
+	Labels []string `json:"labels"`
+}
+
+// ActionRunnerJob represents a job handed to a runner
+// Token can be used to clone the repository while the job is running
+type ActionRunnerJob struct {
+	ID         int64               `json:"id"`
+	RunID      int64               `json:"run_id"`
+	Repository string              `json:"repository"`
+	CloneURL   string              `json:"clone_url"`
+	Token      string              `json:"token"`
+	Workflow   string              `json:"workflow"`
+	Event      string              `json:"event"`
+	Ref        string              `json:"ref"`
+	CommitSHA  string              `json:"sha"`
+	Name       string              `json:"name"`
+	RunsOn     []string            `json:"runs_on"`
+	Env        map[string]string   `json:"env,omitempty"`
+	Steps      []*ActionRunnerStep `json:"steps"`
+}
+
+// ActionRunnerStep represents a step of a job handed to a runner
+type ActionRunnerStep struct {
+	Index int64             `json:"index"`
+	Name  string            `json:"name"`
+	Run   string            `json:"run,omitempty"`
+	Uses  string            `json:"uses,omitempty"`
+	With  map[string]string `json:"with,omitempty"`
+	Env   map[string]string `json:"env,omitempty"`
+}
+
+// UpdateActionRunnerJobOption options a runner uses to report the state of a job
+type UpdateActionRunnerJobOption struct {
+	// the status of the job, one of running, success, failure or cancelled
+	Status string                          `json:"status"`
+	Steps  []*UpdateActionRunnerStepOption `json:"steps"`
+}
+
+// UpdateActionRunnerStepOption options a runner uses to report the state of a step
+type UpdateActionRunnerStepOption struct {
+	Index int64 `json:"index"`
+	// the status of the step, one of running, success, failure, cancelled or skipped
+	Status string `json:"status"`
+}
@@ -0,0 +1,106 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package structs
+
+import (
+	"time"
+)
+
+// CheckRun represents a check reported against a commit
+type CheckRun struct {
+	ID         int64  `json:"id"`
+	Name       string `json:"name"`
+	HeadSHA    string `json:"head_sha"`
+	ExternalID string `json:"external_id"`
+	DetailsURL string `json:"details_url"`
+	HTMLURL    string `json:"html_url"`
+	URL        string `json:"url"`
+	// enum: queued,in_progress,completed
+	Status string `json:"status"`
+	// enum: success,failure,neutral,cancelled,skipped,timed_out
+	Conclusion string          `json:"conclusion"`
+	Output     *CheckRunOutput `json:"output"`
+	Creator    *User           `json:"creator"`
+	// swagger:strfmt date-time
+	StartedAt *time.Time `json:"started_at"`
+	// swagger:strfmt date-time
+	CompletedAt *time.Time `json:"completed_at"`
+	// swagger:strfmt date-time
+	Created time.Time `json:"created_at"`
+	// swagger:strfmt date-time
+	Updated time.Time `json:"updated_at"`
+}
+
+// CheckRunOutput represents the output of a check run
+type CheckRunOutput struct {
+	Title string `json:"title"`
+	// Markdown
+	Summary          string `json:"summary"`
+	Text             string `json:"text"`
+	AnnotationsCount int64  `json:"annotations_count"`
+}
+
+// CheckAnnotation flags a range of lines of a file at the head commit of a check run
+type CheckAnnotation struct {
+	// required: true
+	Path string `json:"path"`
+	// required: true
+	StartLine int `json:"start_line"`
+	// defaults to start_line
+	EndLine int `json:"end_line"`
+	// required: true
+	// enum: notice,warning,failure
+	AnnotationLevel string `json:"annotation_level"`
+	Title           string `json:"title"`
+	// required: true
+	Message    string `json:"message"`
+	RawDetails string `json:"raw_details"`
+}
+
+// CheckRunOutputOption options for the output of a check run
+type CheckRunOutputOption struct {
+	Title string `json:"title"`
+	// Markdown
+	Summary string `json:"summary"`
+	Text    string `json:"text"`
+	// at most 50 annotations are accepted per request, the annotations are added to the existing ones
+	Annotations []*CheckAnnotation `json:"annotations"`
+}
+
+// CreateCheckRunOption options for creating a check run
+type CreateCheckRunOption struct {
+	// required: true
+	Name string `json:"name" binding:"Required;MaxSize(255)"`
+	// required: true
+	HeadSHA    string `json:"head_sha" binding:"Required;MaxSize(64)"`
+	DetailsURL string `json:"details_url" binding:"OmitEmpty;ValidUrl"`
+	ExternalID string `json:"external_id" binding:"MaxSize(255)"`
+	// enum: queued,in_progress,completed
+	Status string `json:"status" binding:"In(,queued,in_progress,completed)"`
+	// required if status is completed
+	// enum: success,failure,neutral,cancelled,skipped,timed_out
+	Conclusion string `json:"conclusion" binding:"In(,success,failure,neutral,cancelled,skipped,timed_out)"`
+	// swagger:strfmt date-time
+	StartedAt *time.Time `json:"started_at"`
+	// swagger:strfmt date-time
+	CompletedAt *time.Time            `json:"completed_at"`
+	Output      *CheckRunOutputOption `json:"output"`
+}
+
+// EditCheckRunOption options for editing a check run
+type EditCheckRunOption struct {
+	Name       *string `json:"name" binding:"MaxSize(255)"`
+	DetailsURL *string `json:"details_url" binding:"OmitEmpty;ValidUrl"`
+	ExternalID *string `json:"external_id"`
+	// enum: queued,in_progress,completed
+	Status *string `json:"status"`
+	// enum: success,failure,neutral,cancelled,skipped,timed_out
+	Conclusion *string `json:"conclusion"`
+	// swagger:strfmt date-time
+	StartedAt *time.Time `json:"started_at"`
+	// swagger:strfmt date-time
+	CompletedAt *time.Time            `json:"completed_at"`
+	Output      *CheckRunOutputOption `json:"output"`
+}
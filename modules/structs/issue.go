@@ -89,6 +89,8 @@ type CreateIssueOption struct {
 	// list of label ids
 	Labels []int64 `json:"labels"`
 	Closed bool    `json:"closed"`
+	// values of custom fields keyed by the IDs of the fields
+	CustomFields map[int64]string `json:"custom_fields"`
 }
 
 // EditIssueOption options for editing an issue
@@ -104,6 +106,8 @@ type EditIssueOption struct {
 	// swagger:strfmt date-time
 	Deadline       *time.Time `json:"due_date"`
 	RemoveDeadline *bool      `json:"unset_due_date"`
+	// values of custom fields keyed by the IDs of the fields, an empty value clears a field
+	CustomFields map[int64]string `json:"custom_fields"`
 }
 
 // EditDeadlineOption options for creating a deadline
@@ -130,9 +134,80 @@ type IssueTemplate struct {
 	Ref      string   `json:"ref" yaml:"ref"`
 	Content  string   `json:"content" yaml:"-"`
 	FileName string   `json:"file_name" yaml:"-"`
+	// the fields of an issue form, which is written in YAML instead of Markdown
+	Body []*IssueFormField `json:"body" yaml:"body"`
 }
 
 // Valid checks whether an IssueTemplate is considered valid, e.g. at least name and about
 func (it IssueTemplate) Valid() bool {
 	return strings.TrimSpace(it.Name) != "" && strings.TrimSpace(it.About) != ""
 }
+
+// IsForm returns whether the template is an issue form made of fields instead of a Markdown text
+func (it IssueTemplate) IsForm() bool {
+	return len(it.Body) > 0
+}
+
+// IssueFormFieldType defines the type of a field of an issue form
+type IssueFormFieldType string
+
+const (
+	// IssueFormFieldTypeMarkdown is a text shown in the form which is not part of the issue
+	IssueFormFieldTypeMarkdown IssueFormFieldType = "markdown"
+	// IssueFormFieldTypeInput is a single line of text
+	IssueFormFieldTypeInput IssueFormFieldType = "input"
+	// IssueFormFieldTypeTextarea is a text of several lines, which can be rendered as a code block
+	IssueFormFieldTypeTextarea IssueFormFieldType = "textarea"
+	// IssueFormFieldTypeDropdown is a choice of one or, if multiple, several of the options
+	IssueFormFieldTypeDropdown IssueFormFieldType = "dropdown"
+	// IssueFormFieldTypeCheckboxes is a list of options which can be checked
+	IssueFormFieldTypeCheckboxes IssueFormFieldType = "checkboxes"
+)
+
+// IssueFormField represents a field of an issue form
+// swagger:model
+type IssueFormField struct {
+	Type        IssueFormFieldType        `json:"type" yaml:"type"`
+	ID          string                    `json:"id" yaml:"id"`
+	Attributes  IssueFormFieldAttributes  `json:"attributes" yaml:"attributes"`
+	Validations IssueFormFieldValidations `json:"validations" yaml:"validations"`
+}
+
+// IssueFormFieldAttributes represents the attributes of a field of an issue form,
+// which of them are used depends on the type of the field
+type IssueFormFieldAttributes struct {
+	Label       string `json:"label,omitempty" yaml:"label"`
+	Description string `json:"description,omitempty" yaml:"description"`
+	Placeholder string `json:"placeholder,omitempty" yaml:"placeholder"`
+	// the default value of an input or a textarea, the text of a markdown field
+	Value string `json:"value,omitempty" yaml:"value"`
+	// the language of the code block the value of a textarea is rendered as
+	Render string `json:"render,omitempty" yaml:"render"`
+	// whether several options of a dropdown can be chosen
+	Multiple bool                   `json:"multiple,omitempty" yaml:"multiple"`
+	Options  []IssueFormFieldOption `json:"options,omitempty" yaml:"options"`
+}
+
+// IssueFormFieldOption represents an option of a dropdown or of checkboxes
+type IssueFormFieldOption struct {
+	Label string `json:"label" yaml:"label"`
+	// whether the checkbox has to be checked
+	Required bool `json:"required" yaml:"required"`
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler, the options of a dropdown are written as plain strings
+func (o *IssueFormFieldOption) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var label string
+	if err := unmarshal(&label); err == nil {
+		*o = IssueFormFieldOption{Label: label}
+		return nil
+	}
+
+	type option IssueFormFieldOption
+	return unmarshal((*option)(o))
+}
+
+// IssueFormFieldValidations represents the validations of the value of a field of an issue form
+type IssueFormFieldValidations struct {
+	Required bool `json:"required" yaml:"required"`
+}
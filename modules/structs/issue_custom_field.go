@@ -0,0 +1,48 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package structs
+
+// CustomField a typed field the issues of a repository or of the repositories of an organization can have
+// swagger:model
+type CustomField struct {
+	ID          int64  `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	// enum: text,number,date,select,user
+	Type string `json:"type"`
+	// the choices of a select field
+	Options    []string `json:"options"`
+	IsOrgField bool     `json:"is_org_field"`
+}
+
+// CreateCustomFieldOption options for creating a custom field
+type CreateCustomFieldOption struct {
+	// required:true
+	Name        string `json:"name" binding:"Required"`
+	Description string `json:"description"`
+	// required:true
+	// enum: text,number,date,select,user
+	Type string `json:"type" binding:"Required"`
+	// the choices of a select field
+	Options []string `json:"options"`
+}
+
+// EditCustomFieldOption options for editing a custom field
+type EditCustomFieldOption struct {
+	Name        *string  `json:"name"`
+	Description *string  `json:"description"`
+	Options     []string `json:"options"`
+}
+
+// IssueCustomFieldValue the value of a custom field of an issue
+// swagger:model
+type IssueCustomFieldValue struct {
+	FieldID int64  `json:"field_id"`
+	Name    string `json:"name"`
+	// enum: text,number,date,select,user
+	Type string `json:"type"`
+	// the value of the field, the username of the user of a user field
+	Value string `json:"value"`
+}
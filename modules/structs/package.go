@@ -31,3 +31,55 @@ type PackageFile struct {
 	HashSHA256 string `json:"sha256"`
 	HashSHA512 string `json:"sha512"`
 }
+
+// PackageProxy represents the upstream registry of a package type
+type PackageProxy struct {
+	Type        string `json:"type"`
+	UpstreamURL string `json:"upstream_url"`
+}
+
+// SetPackageProxyOption options to set the upstream registry of a package type
+type SetPackageProxyOption struct {
+	// required: true
+	UpstreamURL string `json:"upstream_url" binding:"Required"`
+}
+
+// PackageCleanupRule represents a rule which removes package versions of an owner
+type PackageCleanupRule struct {
+	ID      int64  `json:"id"`
+	Enabled bool   `json:"enabled"`
+	Type    string `json:"type"`
+	// regular expression which must match the whole package name, all packages if empty
+	NamePattern string `json:"name_pattern"`
+	// number of newest versions which are kept
+	KeepCount int `json:"keep_count"`
+	// regular expression, matching versions are kept
+	KeepPattern string `json:"keep_pattern"`
+	// only versions older than this number of days are removed
+	RemoveDays int `json:"remove_days"`
+	// swagger:strfmt date-time
+	Created time.Time `json:"created_at"`
+	// swagger:strfmt date-time
+	Updated time.Time `json:"updated_at"`
+}
+
+// CreatePackageCleanupRuleOption options to create a package cleanup rule
+type CreatePackageCleanupRuleOption struct {
+	Enabled bool `json:"enabled"`
+	// required: true
+	// enum: alpine,cargo,composer,conan,conda,container,debian,generic,go,helm,maven,npm,nuget,pypi,rpm,rubygems
+	Type        string `json:"type" binding:"Required"`
+	NamePattern string `json:"name_pattern"`
+	KeepCount   int    `json:"keep_count"`
+	KeepPattern string `json:"keep_pattern"`
+	RemoveDays  int    `json:"remove_days"`
+}
+
+// EditPackageCleanupRuleOption options to edit a package cleanup rule
+type EditPackageCleanupRuleOption struct {
+	Enabled     *bool   `json:"enabled"`
+	NamePattern *string `json:"name_pattern"`
+	KeepCount   *int    `json:"keep_count"`
+	KeepPattern *string `json:"keep_pattern"`
+	RemoveDays  *int    `json:"remove_days"`
+}
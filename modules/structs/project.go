@@ -0,0 +1,98 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package structs
+
+import (
+	"time"
+)
+
+// Project represents a project of a repository, an organization or a user
+type Project struct {
+	ID          int64  `json:"id"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	// enum: individual,repository,organization
+	Type string `json:"type"`
+	// enum: none,basic_kanban,bug_triage
+	BoardType    string          `json:"board_type"`
+	State        StateType       `json:"state"`
+	Repo         *RepositoryMeta `json:"repository"`
+	Owner        *User           `json:"owner"`
+	Creator      *User           `json:"creator"`
+	OpenIssues   int             `json:"open_issues"`
+	ClosedIssues int             `json:"closed_issues"`
+	// swagger:strfmt date-time
+	Created time.Time `json:"created_at"`
+	// swagger:strfmt date-time
+	Updated time.Time `json:"updated_at"`
+	// swagger:strfmt date-time
+	Closed *time.Time `json:"closed_at"`
+}
+
+// CreateProjectOption options for creating a project
+type CreateProjectOption struct {
+	// required:true
+	Title       string `json:"title" binding:"Required;MaxSize(100)"`
+	Description string `json:"description"`
+	// the boards the project starts with
+	// enum: none,basic_kanban,bug_triage
+	BoardType string `json:"board_type"`
+}
+
+// EditProjectOption options for editing a project
+type EditProjectOption struct {
+	Title       *string `json:"title" binding:"MaxSize(100)"`
+	Description *string `json:"description"`
+	// enum: open,closed
+	State *string `json:"state"`
+}
+
+// ProjectBoard represents a board (column) of a project
+type ProjectBoard struct {
+	// the board with id 0 holds the issues which have not been assigned to a board, unless another board is the default
+	ID      int64  `json:"id"`
+	Title   string `json:"title"`
+	Color   string `json:"color"`
+	Sorting int    `json:"sorting"`
+	// issues which have not been assigned to a board are shown on the default board
+	Default bool `json:"default"`
+	// swagger:strfmt date-time
+	Created time.Time `json:"created_at"`
+	// swagger:strfmt date-time
+	Updated time.Time `json:"updated_at"`
+}
+
+// CreateProjectBoardOption options for creating a project board
+type CreateProjectBoardOption struct {
+	// required:true
+	Title string `json:"title" binding:"Required;MaxSize(100)"`
+	// example: #00aabb
+	Color string `json:"color" binding:"MaxSize(7)"`
+}
+
+// EditProjectBoardOption options for editing a project board
+type EditProjectBoardOption struct {
+	Title *string `json:"title" binding:"MaxSize(100)"`
+	// example: #00aabb
+	Color   *string `json:"color" binding:"MaxSize(7)"`
+	Sorting *int    `json:"sorting"`
+	// make the board the default board of the project
+	Default *bool `json:"default"`
+}
+
+// AddProjectIssueOption options for adding an issue to a project
+type AddProjectIssueOption struct {
+	// id of the issue or pull request, it must belong to the repository of a repository project or to a
+	// repository of the owner of an individual or organization project
+	// required:true
+	IssueID int64 `json:"issue_id" binding:"Required"`
+}
+
+// MoveProjectIssuesOption options for moving issues to a project board
+type MoveProjectIssuesOption struct {
+	// ids of the issues in the order they are shown on the board, the issues have to be added to the project before
+	// required:true
+	Issues []int64 `json:"issues" binding:"Required"`
+}
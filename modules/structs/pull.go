@@ -78,6 +78,8 @@ type CreatePullRequestOption struct {
 	Labels    []int64  `json:"labels"`
 	// swagger:strfmt date-time
 	Deadline *time.Time `json:"due_date"`
+	// file name or name of a pull request template of the default branch, which is used as the body if it is empty
+	Template string `json:"template"`
 }
 
 // EditPullRequestOption options when modify pull request
@@ -95,3 +97,26 @@ type EditPullRequestOption struct {
 	RemoveDeadline      *bool      `json:"unset_due_date"`
 	AllowMaintainerEdit *bool      `json:"allow_maintainer_edit"`
 }
+
+// PullRequestIteration represents the head of a pull request after it has been created or pushed to
+type PullRequestIteration struct {
+	Index       int64  `json:"index"`
+	Pusher      *User  `json:"pusher"`
+	HeadSHA     string `json:"head_sha"`
+	MergeBase   string `json:"merge_base"`
+	IsForcePush bool   `json:"is_force_push"`
+	// swagger:strfmt date-time
+	Created time.Time `json:"created_at"`
+}
+
+// PullRequestIterationComparison represents the changes between two iterations of a pull request
+type PullRequestIterationComparison struct {
+	From *PullRequestIteration `json:"from"`
+	To   *PullRequestIteration `json:"to"`
+	// whether the head branch has been rebased or force-pushed between both iterations
+	IsRebased bool `json:"is_rebased"`
+	// unified diff between the heads of both iterations
+	Diff string `json:"diff"`
+	// output of git range-diff comparing the commits of both iterations, only set if the branch has been rebased
+	RangeDiff string `json:"range_diff"`
+}
@@ -0,0 +1,37 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package structs
+
+// Quota represents the storage limits and usage of a user or organization
+// A negative limit means there is no limit.
+type Quota struct {
+	// true if the default quota of the instance applies
+	IsDefault        bool        `json:"is_default"`
+	LimitTotal       int64       `json:"limit_total"`
+	LimitGit         int64       `json:"limit_git"`
+	LimitLFS         int64       `json:"limit_lfs"`
+	LimitPackages    int64       `json:"limit_packages"`
+	LimitAttachments int64       `json:"limit_attachments"`
+	Usage            *QuotaUsage `json:"usage"`
+}
+
+// QuotaUsage represents the used storage of a user or organization in bytes
+type QuotaUsage struct {
+	Total       int64 `json:"total"`
+	Git         int64 `json:"git"`
+	LFS         int64 `json:"lfs"`
+	Packages    int64 `json:"packages"`
+	Attachments int64 `json:"attachments"`
+}
+
+// EditQuotaOption options for editing the quota of a user or organization
+// Omitted limits keep their current value. Use -1 to remove a limit.
+type EditQuotaOption struct {
+	LimitTotal       *int64 `json:"limit_total"`
+	LimitGit         *int64 `json:"limit_git"`
+	LimitLFS         *int64 `json:"limit_lfs"`
+	LimitPackages    *int64 `json:"limit_packages"`
+	LimitAttachments *int64 `json:"limit_attachments"`
+}
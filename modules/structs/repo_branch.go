@@ -45,6 +45,8 @@ type BranchProtection struct {
 	RequireSignedCommits          bool     `json:"require_signed_commits"`
 	ProtectedFilePatterns         string   `json:"protected_file_patterns"`
 	UnprotectedFilePatterns       string   `json:"unprotected_file_patterns"`
+	EnableMergeQueue              bool     `json:"enable_merge_queue"`
+	RequireCodeOwnerApproval      bool     `json:"require_code_owner_approval"`
 	// swagger:strfmt date-time
 	Created time.Time `json:"created_at"`
 	// swagger:strfmt date-time
@@ -75,6 +77,8 @@ type CreateBranchProtectionOption struct {
 	RequireSignedCommits          bool     `json:"require_signed_commits"`
 	ProtectedFilePatterns         string   `json:"protected_file_patterns"`
 	UnprotectedFilePatterns       string   `json:"unprotected_file_patterns"`
+	EnableMergeQueue              bool     `json:"enable_merge_queue"`
+	RequireCodeOwnerApproval      bool     `json:"require_code_owner_approval"`
 }
 
 // EditBranchProtectionOption options for editing a branch protection
@@ -100,4 +104,6 @@ type EditBranchProtectionOption struct {
 	RequireSignedCommits          *bool    `json:"require_signed_commits"`
 	ProtectedFilePatterns         *string  `json:"protected_file_patterns"`
 	UnprotectedFilePatterns       *string  `json:"unprotected_file_patterns"`
+	EnableMergeQueue              *bool    `json:"enable_merge_queue"`
+	RequireCodeOwnerApproval      *bool    `json:"require_code_owner_approval"`
 }
@@ -0,0 +1,63 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package actions
+
+import (
+	"net/http"
+	"strings"
+
+	actions_model "code.gitea.io/gitea/models/actions"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/web"
+)
+
+// Routes registers the routes runners use to fetch jobs and report their results
+func Routes() *web.Route {
+	r := web.NewRoute()
+
+	r.Use(context.ActionsContexter())
+
+	r.Group("/runner", func() {
+		r.Post("/fetch", FetchJob)
+		r.Group("/jobs/{id}", func() {
+			r.Post("/status", UpdateJob)
+			r.Post("/logs", AppendLog)
+		})
+	}, checkActionsEnabled, runnerAssignment)
+
+	return r
+}
+
+func checkActionsEnabled(ctx *context.Context) {
+	if !setting.Actions.Enabled {
+		apiError(ctx, http.StatusNotFound, "Actions are disabled")
+	}
+}
+
+// runnerAssignment authenticates the runner by the token in the Authorization header
+func runnerAssignment(ctx *context.Context) {
+	fields := strings.Fields(ctx.Req.Header.Get("Authorization"))
+	if len(fields) != 2 || !strings.EqualFold(fields[0], "bearer") {
+		apiError(ctx, http.StatusUnauthorized, "Runner token is missing")
+		return
+	}
+
+	runner, err := actions_model.GetRunnerByToken(ctx, fields[1])
+	if err != nil {
+		if err == actions_model.ErrRunnerNotExist {
+			apiError(ctx, http.StatusUnauthorized, "Runner token is invalid")
+		} else {
+			apiError(ctx, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	ctx.Data["ActionsRunner"] = runner
+}
+
+func getRunner(ctx *context.Context) *actions_model.ActionRunner {
+	return ctx.Data["ActionsRunner"].(*actions_model.ActionRunner)
+}
@@ -0,0 +1,122 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package actions
+
+import (
+	"fmt"
+	"net/http"
+
+	actions_model "code.gitea.io/gitea/models/actions"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/json"
+	"code.gitea.io/gitea/modules/log"
+	api "code.gitea.io/gitea/modules/structs"
+	actions_service "code.gitea.io/gitea/services/actions"
+)
+
+// maxLogChunkSize limits the size of a single log upload
+const maxLogChunkSize = 10 * 1024 * 1024
+
+func apiError(ctx *context.Context, status int, obj interface{}) {
+	var message string
+	if err, ok := obj.(error); ok {
+		message = err.Error()
+	} else {
+		message = fmt.Sprintf("%s", obj)
+	}
+	if status == http.StatusInternalServerError {
+		log.ErrorWithSkip(1, message)
+		message = http.StatusText(status)
+	} else {
+		log.Debug(message)
+	}
+
+	ctx.JSON(status, map[string]string{
+		"message": message,
+	})
+}
+
+// FetchJob assigns the next job to the runner
+// If there is no job for the runner, 204 No Content is returned.
+func FetchJob(ctx *context.Context) {
+	job, err := actions_service.PickJob(ctx, getRunner(ctx))
+	if err != nil {
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	if job == nil {
+		ctx.Status(http.StatusNoContent)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, job)
+}
+
+func getJob(ctx *context.Context) *actions_model.ActionRunJob {
+	job, err := actions_service.GetRunnerJob(ctx, getRunner(ctx), ctx.ParamsInt64("id"))
+	if err != nil {
+		switch err {
+		case actions_model.ErrRunJobNotExist:
+			apiError(ctx, http.StatusNotFound, err)
+		case actions_service.ErrJobNotAssigned:
+			apiError(ctx, http.StatusForbidden, err)
+		case actions_service.ErrJobDone:
+			apiError(ctx, http.StatusConflict, err)
+		default:
+			apiError(ctx, http.StatusInternalServerError, err)
+		}
+		return nil
+	}
+	return job
+}
+
+// UpdateJob stores the state of a job and its steps
+func UpdateJob(ctx *context.Context) {
+	job := getJob(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	var opts api.UpdateActionRunnerJobOption
+	if err := json.NewDecoder(ctx.Req.Body).Decode(&opts); err != nil {
+		apiError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := actions_service.UpdateJob(ctx, job, &opts); err != nil {
+		if err == actions_service.ErrInvalidStatus {
+			apiError(ctx, http.StatusBadRequest, err)
+		} else {
+			apiError(ctx, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// AppendLog appends the request body to the log of a job
+func AppendLog(ctx *context.Context) {
+	job := getJob(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	if ctx.Req.ContentLength > maxLogChunkSize {
+		apiError(ctx, http.StatusRequestEntityTooLarge, "Log chunk is too large")
+		return
+	}
+
+	if err := actions_service.AppendJobLog(ctx, job, http.MaxBytesReader(ctx.Resp, ctx.Req.Body, maxLogChunkSize)); err != nil {
+		if err == actions_service.ErrLogConflict {
+			apiError(ctx, http.StatusConflict, err)
+		} else {
+			apiError(ctx, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	ctx.Status(http.StatusCreated)
+}
@@ -0,0 +1,253 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package alpine
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+
+	packages_model "code.gitea.io/gitea/models/packages"
+	quota_model "code.gitea.io/gitea/models/quota"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/json"
+	packages_module "code.gitea.io/gitea/modules/packages"
+	alpine_module "code.gitea.io/gitea/modules/packages/alpine"
+	"code.gitea.io/gitea/routers/api/packages/helper"
+	packages_service "code.gitea.io/gitea/services/packages"
+	alpine_service "code.gitea.io/gitea/services/packages/alpine"
+)
+
+// branchOrRepositoryPattern matches branch names like "v3.16" or "edge" and repository names like "main" or "community"
+var branchOrRepositoryPattern = regexp.MustCompile(`\A[a-zA-Z0-9][a-zA-Z0-9._-]*\z`)
+
+var releasePattern = regexp.MustCompile(`\Ar[0-9]+\z`)
+
+func apiError(ctx *context.Context, status int, obj interface{}) {
+	helper.LogAndProcessError(ctx, status, obj, func(message string) {
+		ctx.PlainText(status, message)
+	})
+}
+
+// GetRepositoryKey serves the public key used to sign the repository index
+func GetRepositoryKey(ctx *context.Context) {
+	_, pub, err := alpine_service.GetOrCreateKeyPair(ctx.Package.Owner.ID)
+	if err != nil {
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	ctx.ServeContent(alpine_service.KeyName(ctx.Package.Owner), strings.NewReader(pub))
+}
+
+// GetRepositoryFile serves the signed "APKINDEX.tar.gz" of a branch, repository and architecture
+func GetRepositoryFile(ctx *context.Context) {
+	branch := ctx.Params("branch")
+	repository := ctx.Params("repository")
+	if !branchOrRepositoryPattern.MatchString(branch) || !branchOrRepositoryPattern.MatchString(repository) {
+		apiError(ctx, http.StatusBadRequest, "invalid branch or repository")
+		return
+	}
+
+	index, err := alpine_service.BuildIndex(ctx, ctx.Package.Owner, branch, repository, ctx.Params("architecture"))
+	if err != nil {
+		if err == packages_model.ErrPackageNotExist {
+			apiError(ctx, http.StatusNotFound, err)
+			return
+		}
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	ctx.ServeContent("APKINDEX.tar.gz", bytes.NewReader(index))
+}
+
+// UploadPackageFile adds a package file to a branch and repository
+func UploadPackageFile(ctx *context.Context) {
+	branch := ctx.Params("branch")
+	repository := ctx.Params("repository")
+	if !branchOrRepositoryPattern.MatchString(branch) || !branchOrRepositoryPattern.MatchString(repository) {
+		apiError(ctx, http.StatusBadRequest, "invalid branch or repository")
+		return
+	}
+
+	upload, close, err := ctx.UploadStream()
+	if err != nil {
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	if close {
+		defer upload.Close()
+	}
+
+	buf, err := packages_module.CreateHashedBufferFromReader(upload, 32*1024*1024)
+	if err != nil {
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	defer buf.Close()
+
+	pck, err := alpine_module.ParsePackage(buf)
+	if err != nil {
+		apiError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	if _, err := buf.Seek(0, io.SeekStart); err != nil {
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	fileMetadata, err := json.Marshal(pck.FileMetadata)
+	if err != nil {
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	_, _, err = packages_service.CreatePackageOrAddFileToExisting(
+		&packages_service.PackageCreationInfo{
+			PackageInfo: packages_service.PackageInfo{
+				Owner:       ctx.Package.Owner,
+				PackageType: packages_model.TypeAlpine,
+				Name:        pck.Name,
+				Version:     pck.Version,
+			},
+			Creator:  ctx.Doer,
+			Metadata: pck.VersionMetadata,
+		},
+		&packages_service.PackageFileCreationInfo{
+			PackageFileInfo: packages_service.PackageFileInfo{
+				Filename:     createFilename(pck.Name, pck.Version),
+				CompositeKey: createCompositeKey(branch, repository, pck.FileMetadata.Architecture),
+			},
+			Data:   buf,
+			IsLead: true,
+			Properties: map[string]string{
+				alpine_module.PropertyBranch:       branch,
+				alpine_module.PropertyRepository:   repository,
+				alpine_module.PropertyArchitecture: pck.FileMetadata.Architecture,
+				alpine_module.PropertyMetadata:     string(fileMetadata),
+			},
+		},
+	)
+	if err != nil {
+		if err == packages_model.ErrDuplicatePackageFile {
+			apiError(ctx, http.StatusConflict, err)
+			return
+		}
+		if err == packages_service.ErrPackageAccessDenied {
+			apiError(ctx, http.StatusForbidden, err)
+			return
+		}
+		if quota_model.IsErrQuotaExceeded(err) {
+			apiError(ctx, http.StatusRequestEntityTooLarge, err)
+			return
+		}
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	ctx.Status(http.StatusCreated)
+}
+
+// DownloadPackageFile serves the content of a package file
+// Packages of the "noarch" architecture are served for every architecture.
+func DownloadPackageFile(ctx *context.Context) {
+	pf, err := getPackageFile(ctx)
+	if err != nil {
+		if err == packages_model.ErrPackageNotExist || err == packages_model.ErrPackageFileNotExist {
+			apiError(ctx, http.StatusNotFound, err)
+			return
+		}
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	s, _, err := packages_service.GetPackageFileStream(ctx, pf)
+	if err != nil {
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	defer s.Close()
+
+	ctx.ServeStream(s, pf.Name)
+}
+
+// DeletePackageFile removes a package file from a branch, repository and architecture
+func DeletePackageFile(ctx *context.Context) {
+	pf, err := getPackageFile(ctx)
+	if err != nil {
+		if err == packages_model.ErrPackageNotExist || err == packages_model.ErrPackageFileNotExist {
+			apiError(ctx, http.StatusNotFound, err)
+			return
+		}
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	if err := packages_service.RemovePackageFileAndVersionIfUnreferenced(ctx.Doer, pf); err != nil {
+		if err == packages_service.ErrPackageAccessDenied {
+			apiError(ctx, http.StatusForbidden, err)
+			return
+		}
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+func getPackageFile(ctx *context.Context) (*packages_model.PackageFile, error) {
+	name, version, err := parseFilename(ctx.Params("filename"))
+	if err != nil {
+		return nil, err
+	}
+
+	pv, err := packages_model.GetVersionByNameAndVersion(ctx, ctx.Package.Owner.ID, packages_model.TypeAlpine, name, version)
+	if err != nil {
+		return nil, err
+	}
+
+	branch := ctx.Params("branch")
+	repository := ctx.Params("repository")
+	architecture := ctx.Params("architecture")
+
+	pf, err := packages_model.GetFileForVersionByName(ctx, pv.ID, createFilename(name, version), createCompositeKey(branch, repository, architecture))
+	if err == packages_model.ErrPackageFileNotExist && architecture != alpine_module.NoArch {
+		pf, err = packages_model.GetFileForVersionByName(ctx, pv.ID, createFilename(name, version), createCompositeKey(branch, repository, alpine_module.NoArch))
+	}
+	return pf, err
+}
+
+func createFilename(name, version string) string {
+	return fmt.Sprintf("%s-%s.apk", name, version)
+}
+
+func createCompositeKey(branch, repository, architecture string) string {
+	return branch + "|" + repository + "|" + architecture
+}
+
+// parseFilename splits a "name-version.apk" filename
+// The version has an optional package release suffix ("1.0.0-r1").
+func parseFilename(filename string) (string, string, error) {
+	if !strings.HasSuffix(filename, ".apk") {
+		return "", "", packages_model.ErrPackageFileNotExist
+	}
+
+	parts := strings.Split(strings.TrimSuffix(filename, ".apk"), "-")
+
+	n := 1
+	if len(parts) > 2 && releasePattern.MatchString(parts[len(parts)-1]) {
+		n = 2
+	}
+	if len(parts) <= n {
+		return "", "", packages_model.ErrPackageFileNotExist
+	}
+
+	return strings.Join(parts[:len(parts)-n], "-"), strings.Join(parts[len(parts)-n:], "-"), nil
+}
@@ -10,18 +10,26 @@ import (
 	"strings"
 
 	"code.gitea.io/gitea/models/perm"
+	access_model "code.gitea.io/gitea/models/perm/access"
+	"code.gitea.io/gitea/models/unit"
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/web"
+	"code.gitea.io/gitea/routers/api/packages/alpine"
+	"code.gitea.io/gitea/routers/api/packages/cargo"
 	"code.gitea.io/gitea/routers/api/packages/composer"
 	"code.gitea.io/gitea/routers/api/packages/conan"
+	"code.gitea.io/gitea/routers/api/packages/conda"
 	"code.gitea.io/gitea/routers/api/packages/container"
+	"code.gitea.io/gitea/routers/api/packages/debian"
 	"code.gitea.io/gitea/routers/api/packages/generic"
+	"code.gitea.io/gitea/routers/api/packages/goproxy"
 	"code.gitea.io/gitea/routers/api/packages/helm"
 	"code.gitea.io/gitea/routers/api/packages/maven"
 	"code.gitea.io/gitea/routers/api/packages/npm"
 	"code.gitea.io/gitea/routers/api/packages/nuget"
 	"code.gitea.io/gitea/routers/api/packages/pypi"
+	"code.gitea.io/gitea/routers/api/packages/rpm"
 	"code.gitea.io/gitea/routers/api/packages/rubygems"
 	"code.gitea.io/gitea/services/auth"
 	context_service "code.gitea.io/gitea/services/context"
@@ -37,6 +45,28 @@ func reqPackageAccess(accessMode perm.AccessMode) func(ctx *context.Context) {
 	}
 }
 
+// reqPackageUploadAccess allows users with write access to the owner and users with write access
+// to the packages unit of a repository of the owner. The service checks the access to the specific package.
+func reqPackageUploadAccess() func(ctx *context.Context) {
+	return func(ctx *context.Context) {
+		if ctx.Package.AccessMode >= perm.AccessModeWrite || ctx.IsUserSiteAdmin() {
+			return
+		}
+		if ctx.Doer != nil {
+			canWrite, err := access_model.CanWriteUnitOfAnyOwnerRepo(ctx, ctx.Doer, ctx.Package.Owner.ID, unit.TypePackages)
+			if err != nil {
+				ctx.Error(http.StatusInternalServerError, "CanWriteUnitOfAnyOwnerRepo", err.Error())
+				return
+			}
+			if canWrite {
+				return
+			}
+		}
+		ctx.Resp.Header().Set("WWW-Authenticate", `Basic realm="Gitea Package API"`)
+		ctx.Error(http.StatusUnauthorized, "reqPackageUploadAccess", "user should have specific permission or be a site admin")
+	}
+}
+
 func Routes() *web.Route {
 	r := web.NewRoute()
 
@@ -57,6 +87,35 @@ func Routes() *web.Route {
 	})
 
 	r.Group("/{username}", func() {
+		r.Group("/alpine", func() {
+			r.Get("/key", alpine.GetRepositoryKey)
+			r.Group("/{branch}/{repository}", func() {
+				r.Put("", reqPackageUploadAccess(), alpine.UploadPackageFile)
+				r.Group("/{architecture}", func() {
+					r.Get("/APKINDEX.tar.gz", alpine.GetRepositoryFile)
+					r.Group("/{filename}", func() {
+						r.Get("", alpine.DownloadPackageFile)
+						r.Delete("", reqPackageUploadAccess(), alpine.DeletePackageFile)
+					})
+				})
+			})
+		})
+		r.Group("/cargo", func() {
+			r.Get("/config.json", cargo.RepositoryConfig)
+			r.Get("/1/{package}", cargo.EnumeratePackageVersions)
+			r.Get("/2/{package}", cargo.EnumeratePackageVersions)
+			r.Get("/3/{_}/{package}", cargo.EnumeratePackageVersions)
+			r.Get("/{_}/{__}/{package}", cargo.EnumeratePackageVersions)
+			r.Group("/api/v1/crates", func() {
+				r.Get("", cargo.SearchPackages)
+				r.Put("/new", reqPackageUploadAccess(), cargo.UploadPackage)
+				r.Group("/{package}/{version}", func() {
+					r.Get("/download", cargo.DownloadPackageFile)
+					r.Delete("/yank", reqPackageAccess(perm.AccessModeWrite), cargo.YankPackage)
+					r.Put("/unyank", reqPackageAccess(perm.AccessModeWrite), cargo.UnyankPackage)
+				})
+			})
+		})
 		r.Group("/composer", func() {
 			r.Get("/packages.json", composer.ServiceIndex)
 			r.Get("/search.json", composer.SearchPackages)
@@ -64,7 +123,7 @@ func Routes() *web.Route {
 			r.Get("/p2/{vendorname}/{projectname}~dev.json", composer.PackageMetadata)
 			r.Get("/p2/{vendorname}/{projectname}.json", composer.PackageMetadata)
 			r.Get("/files/{package}/{version}/{filename}", composer.DownloadPackageFile)
-			r.Put("", reqPackageAccess(perm.AccessModeWrite), composer.UploadPackage)
+			r.Put("", reqPackageUploadAccess(), composer.UploadPackage)
 		})
 		r.Group("/conan", func() {
 			r.Group("/v1", func() {
@@ -154,22 +213,51 @@ func Routes() *web.Route {
 				})
 			})
 		})
+		r.Group("/conda", func() {
+			r.Get("/channeldata.json", conda.ChannelData)
+			r.Put("/{filename}", reqPackageUploadAccess(), conda.UploadPackageFile)
+			r.Group("/{subdir}", func() {
+				r.Get("/repodata.json", conda.RepositoryData)
+				r.Group("/{filename}", func() {
+					r.Get("", conda.DownloadPackageFile)
+					r.Delete("", reqPackageUploadAccess(), conda.DeletePackageFile)
+				})
+			})
+		})
+		r.Group("/debian", func() {
+			r.Get("/repository.key", debian.GetRepositoryKey)
+			r.Group("/dists/{distribution}", func() {
+				r.Get("/{filename}", debian.GetRepositoryFile)
+				r.Get("/{component}/binary-{architecture}/{filename}", debian.GetPackagesIndex)
+			})
+			r.Group("/pool/{distribution}/{component}", func() {
+				r.Get("/{filename}", debian.DownloadPackageFile)
+				r.Group("", func() {
+					r.Put("/upload", debian.UploadPackageFile)
+					r.Delete("/{name}/{version}/{architecture}", debian.DeletePackageFile)
+				}, reqPackageUploadAccess())
+			})
+		})
 		r.Group("/generic", func() {
 			r.Group("/{packagename}/{packageversion}/{filename}", func() {
 				r.Get("", generic.DownloadPackageFile)
 				r.Group("", func() {
 					r.Put("", generic.UploadPackage)
 					r.Delete("", generic.DeletePackage)
-				}, reqPackageAccess(perm.AccessModeWrite))
+				}, reqPackageUploadAccess())
 			})
 		})
+		r.Group("/go", func() {
+			r.Put("/upload", reqPackageUploadAccess(), goproxy.UploadPackage)
+			r.Get("/*", goproxy.ServeRequest)
+		})
 		r.Group("/helm", func() {
 			r.Get("/index.yaml", helm.Index)
 			r.Get("/{filename}", helm.DownloadPackageFile)
-			r.Post("/api/charts", reqPackageAccess(perm.AccessModeWrite), helm.UploadPackage)
+			r.Post("/api/charts", reqPackageUploadAccess(), helm.UploadPackage)
 		})
 		r.Group("/maven", func() {
-			r.Put("/*", reqPackageAccess(perm.AccessModeWrite), maven.UploadPackageFile)
+			r.Put("/*", reqPackageUploadAccess(), maven.UploadPackageFile)
 			r.Get("/*", maven.DownloadPackageFile)
 		})
 		r.Group("/nuget", func() {
@@ -187,18 +275,18 @@ func Routes() *web.Route {
 				r.Put("/", nuget.UploadPackage)
 				r.Put("/symbolpackage", nuget.UploadSymbolPackage)
 				r.Delete("/{id}/{version}", nuget.DeletePackage)
-			}, reqPackageAccess(perm.AccessModeWrite))
+			}, reqPackageUploadAccess())
 			r.Get("/symbols/{filename}/{guid:[0-9a-f]{32}}FFFFFFFF/{filename2}", nuget.DownloadSymbolFile)
 		})
 		r.Group("/npm", func() {
 			r.Group("/@{scope}/{id}", func() {
 				r.Get("", npm.PackageMetadata)
-				r.Put("", reqPackageAccess(perm.AccessModeWrite), npm.UploadPackage)
+				r.Put("", reqPackageUploadAccess(), npm.UploadPackage)
 				r.Get("/-/{version}/{filename}", npm.DownloadPackageFile)
 			})
 			r.Group("/{id}", func() {
 				r.Get("", npm.PackageMetadata)
-				r.Put("", reqPackageAccess(perm.AccessModeWrite), npm.UploadPackage)
+				r.Put("", reqPackageUploadAccess(), npm.UploadPackage)
 				r.Get("/-/{version}/{filename}", npm.DownloadPackageFile)
 			})
 			r.Group("/-/package/@{scope}/{id}/dist-tags", func() {
@@ -217,10 +305,20 @@ func Routes() *web.Route {
 			})
 		})
 		r.Group("/pypi", func() {
-			r.Post("/", reqPackageAccess(perm.AccessModeWrite), pypi.UploadPackageFile)
+			r.Post("/", reqPackageUploadAccess(), pypi.UploadPackageFile)
 			r.Get("/files/{id}/{version}/{filename}", pypi.DownloadPackageFile)
 			r.Get("/simple/{id}", pypi.PackageMetadata)
 		})
+		r.Group("/rpm", func() {
+			r.Get(".repo", rpm.GetRepositoryConfig)
+			r.Get("/repository.key", rpm.GetRepositoryKey)
+			r.Get("/repodata/{filename}", rpm.GetRepositoryFile)
+			r.Get("/package/{filename}", rpm.DownloadPackageFile)
+			r.Group("", func() {
+				r.Put("/upload", rpm.UploadPackageFile)
+				r.Delete("/package/{name}/{version}/{architecture}", rpm.DeletePackageFile)
+			}, reqPackageUploadAccess())
+		})
 		r.Group("/rubygems", func() {
 			r.Get("/specs.4.8.gz", rubygems.EnumeratePackages)
 			r.Get("/latest_specs.4.8.gz", rubygems.EnumeratePackagesLatest)
@@ -230,7 +328,7 @@ func Routes() *web.Route {
 			r.Group("/api/v1/gems", func() {
 				r.Post("/", rubygems.UploadPackageFile)
 				r.Delete("/yank", rubygems.DeletePackage)
-			}, reqPackageAccess(perm.AccessModeWrite))
+			}, reqPackageUploadAccess())
 		})
 	}, context_service.UserAssignmentWeb(), context.PackageAssignment(), reqPackageAccess(perm.AccessModeRead))
 
@@ -265,17 +363,17 @@ func ContainerRoutes() *web.Route {
 					r.Patch("", container.UploadBlob)
 					r.Put("", container.EndUploadBlob)
 				})
-			}, reqPackageAccess(perm.AccessModeWrite))
+			}, reqPackageUploadAccess(), container.ReqImageWriteAccess)
 			r.Group("/blobs/{digest}", func() {
 				r.Head("", container.HeadBlob)
 				r.Get("", container.GetBlob)
 				r.Delete("", reqPackageAccess(perm.AccessModeWrite), container.DeleteBlob)
 			})
 			r.Group("/manifests/{reference}", func() {
-				r.Put("", reqPackageAccess(perm.AccessModeWrite), container.UploadManifest)
+				r.Put("", reqPackageUploadAccess(), container.UploadManifest)
 				r.Head("", container.HeadManifest)
 				r.Get("", container.GetManifest)
-				r.Delete("", reqPackageAccess(perm.AccessModeWrite), container.DeleteManifest)
+				r.Delete("", reqPackageUploadAccess(), container.DeleteManifest)
 			})
 			r.Get("/tags/list", container.GetTagList)
 		}, container.VerifyImageName)
@@ -297,7 +395,7 @@ func ContainerRoutes() *web.Route {
 			isDelete := ctx.Req.Method == "DELETE"
 
 			if isPost && strings.HasSuffix(path, "/blobs/uploads") {
-				reqPackageAccess(perm.AccessModeWrite)(ctx)
+				reqPackageUploadAccess()(ctx)
 				if ctx.Written() {
 					return
 				}
@@ -308,6 +406,11 @@ func ContainerRoutes() *web.Route {
 					return
 				}
 
+				container.ReqImageWriteAccess(ctx)
+				if ctx.Written() {
+					return
+				}
+
 				container.InitiateUploadBlob(ctx)
 				return
 			}
@@ -324,7 +427,7 @@ func ContainerRoutes() *web.Route {
 
 			m := blobsUploadsPattern.FindStringSubmatch(path)
 			if len(m) == 3 && (isPut || isPatch) {
-				reqPackageAccess(perm.AccessModeWrite)(ctx)
+				reqPackageUploadAccess()(ctx)
 				if ctx.Written() {
 					return
 				}
@@ -335,6 +438,11 @@ func ContainerRoutes() *web.Route {
 					return
 				}
 
+				container.ReqImageWriteAccess(ctx)
+				if ctx.Written() {
+					return
+				}
+
 				ctx.SetParams("uuid", m[2])
 
 				if isPatch {
@@ -382,7 +490,7 @@ func ContainerRoutes() *web.Route {
 				} else if isGet {
 					container.GetManifest(ctx)
 				} else {
-					reqPackageAccess(perm.AccessModeWrite)(ctx)
+					reqPackageUploadAccess()(ctx)
 					if ctx.Written() {
 						return
 					}
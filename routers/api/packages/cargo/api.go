@@ -0,0 +1,114 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package cargo
+
+import (
+	packages_model "code.gitea.io/gitea/models/packages"
+	cargo_module "code.gitea.io/gitea/modules/packages/cargo"
+)
+
+// RegistryConfig contains the registry endpoints
+// https://doc.rust-lang.org/cargo/reference/registries.html#index-format
+type RegistryConfig struct {
+	DownloadURL  string `json:"dl"`
+	APIURL       string `json:"api"`
+	AuthRequired bool   `json:"auth-required"`
+}
+
+// IndexVersionEntry is a line in a crate index file
+// https://doc.rust-lang.org/cargo/reference/registries.html#index-format
+type IndexVersionEntry struct {
+	Name         string                     `json:"name"`
+	Version      string                     `json:"vers"`
+	Dependencies []*cargo_module.Dependency `json:"deps"`
+	Checksum     string                     `json:"cksum"`
+	Features     map[string][]string        `json:"features"`
+	Yanked       bool                       `json:"yanked"`
+	Links        string                     `json:"links,omitempty"`
+}
+
+func createIndexVersionEntry(pd *packages_model.PackageDescriptor) *IndexVersionEntry {
+	metadata := pd.Metadata.(*cargo_module.Metadata)
+
+	dependencies := metadata.Dependencies
+	if dependencies == nil {
+		dependencies = []*cargo_module.Dependency{}
+	}
+	features := metadata.Features
+	if features == nil {
+		features = map[string][]string{}
+	}
+
+	checksum := ""
+	if len(pd.Files) > 0 {
+		checksum = pd.Files[0].Blob.HashSHA256
+	}
+
+	return &IndexVersionEntry{
+		Name:         pd.Package.Name,
+		Version:      pd.Version.Version,
+		Dependencies: dependencies,
+		Checksum:     checksum,
+		Features:     features,
+		Yanked:       pd.Properties.GetByName(cargo_module.PropertyYanked) == "true",
+		Links:        metadata.Links,
+	}
+}
+
+// SearchResultResponse contains search results
+// https://doc.rust-lang.org/cargo/reference/registries.html#search
+type SearchResultResponse struct {
+	Crates []*SearchResult   `json:"crates"`
+	Meta   *SearchResultMeta `json:"meta"`
+}
+
+// SearchResult contains a search result
+type SearchResult struct {
+	Name        string `json:"name"`
+	MaxVersion  string `json:"max_version"`
+	Description string `json:"description"`
+}
+
+// SearchResultMeta contains the total number of search results
+type SearchResultMeta struct {
+	Total int64 `json:"total"`
+}
+
+func createSearchResultResponse(total int64, pds []*packages_model.PackageDescriptor) *SearchResultResponse {
+	crates := make([]*SearchResult, 0, len(pds))
+
+	for _, pd := range pds {
+		crates = append(crates, &SearchResult{
+			Name:        pd.Package.Name,
+			MaxVersion:  pd.Version.Version,
+			Description: pd.Metadata.(*cargo_module.Metadata).Description,
+		})
+	}
+
+	return &SearchResultResponse{
+		Crates: crates,
+		Meta: &SearchResultMeta{
+			Total: total,
+		},
+	}
+}
+
+// PublishResponse is returned after a successful publish
+// https://doc.rust-lang.org/cargo/reference/registries.html#publish
+type PublishResponse struct {
+	Warnings *PublishWarnings `json:"warnings"`
+}
+
+// PublishWarnings contains warnings about the published crate
+type PublishWarnings struct {
+	InvalidCategories []string `json:"invalid_categories"`
+	InvalidBadges     []string `json:"invalid_badges"`
+	Other             []string `json:"other"`
+}
+
+// OkResponse is returned after a successful yank or unyank
+type OkResponse struct {
+	Ok bool `json:"ok"`
+}
@@ -0,0 +1,303 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package cargo
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"code.gitea.io/gitea/models/db"
+	packages_model "code.gitea.io/gitea/models/packages"
+	quota_model "code.gitea.io/gitea/models/quota"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/convert"
+	"code.gitea.io/gitea/modules/json"
+	packages_module "code.gitea.io/gitea/modules/packages"
+	cargo_module "code.gitea.io/gitea/modules/packages/cargo"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/routers/api/packages/helper"
+	packages_service "code.gitea.io/gitea/services/packages"
+)
+
+// https://doc.rust-lang.org/cargo/reference/registries.html#web-api
+func apiError(ctx *context.Context, status int, obj interface{}) {
+	helper.LogAndProcessError(ctx, status, obj, func(message string) {
+		type Error struct {
+			Detail string `json:"detail"`
+		}
+		type ErrorResponse struct {
+			Errors []Error `json:"errors"`
+		}
+		ctx.JSON(status, ErrorResponse{
+			Errors: []Error{
+				{Detail: message},
+			},
+		})
+	})
+}
+
+// RepositoryConfig serves the registry configuration of the sparse index
+func RepositoryConfig(ctx *context.Context) {
+	url := fmt.Sprintf("%sapi/packages/%s/cargo", setting.AppURL, ctx.Package.Owner.Name)
+
+	ctx.JSON(http.StatusOK, &RegistryConfig{
+		DownloadURL:  url + "/api/v1/crates",
+		APIURL:       url,
+		AuthRequired: ctx.Package.Owner.Visibility != structs.VisibleTypePublic,
+	})
+}
+
+// EnumeratePackageVersions serves the index file of a crate with one line per version
+func EnumeratePackageVersions(ctx *context.Context) {
+	packageName := ctx.Params("package")
+
+	// The index path depends on the name length: 1/{name}, 2/{name}, 3/{c}/{name} or {ab}/{cd}/{name}
+	if !strings.HasSuffix(strings.ToLower(ctx.Req.URL.Path), "/"+indexPath(packageName)) {
+		apiError(ctx, http.StatusNotFound, nil)
+		return
+	}
+
+	pvs, err := packages_model.GetVersionsByPackageName(ctx, ctx.Package.Owner.ID, packages_model.TypeCargo, packageName)
+	if err != nil {
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	if len(pvs) == 0 {
+		apiError(ctx, http.StatusNotFound, err)
+		return
+	}
+
+	pds, err := packages_model.GetPackageDescriptors(ctx, pvs)
+	if err != nil {
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	var buf bytes.Buffer
+	for _, pd := range pds {
+		entry, err := json.Marshal(createIndexVersionEntry(pd))
+		if err != nil {
+			apiError(ctx, http.StatusInternalServerError, err)
+			return
+		}
+		buf.Write(entry)
+		buf.WriteByte('\n')
+	}
+
+	ctx.PlainTextBytes(http.StatusOK, buf.Bytes())
+}
+
+// indexPath returns the path of the index file of a crate relative to the index root
+// https://doc.rust-lang.org/cargo/reference/registries.html#index-files
+func indexPath(name string) string {
+	name = strings.ToLower(name)
+	switch len(name) {
+	case 0:
+		return ""
+	case 1:
+		return "1/" + name
+	case 2:
+		return "2/" + name
+	case 3:
+		return "3/" + name[:1] + "/" + name
+	default:
+		return name[:2] + "/" + name[2:4] + "/" + name
+	}
+}
+
+// SearchPackages searches crates, only "q" and "per_page" are supported
+// https://doc.rust-lang.org/cargo/reference/registries.html#search
+func SearchPackages(ctx *context.Context) {
+	page := ctx.FormInt("page")
+	if page < 1 {
+		page = 1
+	}
+	paginator := db.ListOptions{
+		Page:     page,
+		PageSize: convert.ToCorrectPageSize(ctx.FormInt("per_page")),
+	}
+
+	pvs, total, err := packages_model.SearchLatestVersions(
+		ctx,
+		&packages_model.PackageSearchOptions{
+			OwnerID:   ctx.Package.Owner.ID,
+			Type:      packages_model.TypeCargo,
+			Name:      packages_model.SearchValue{Value: ctx.FormTrim("q")},
+			Paginator: &paginator,
+		},
+	)
+	if err != nil {
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	pds, err := packages_model.GetPackageDescriptors(ctx, pvs)
+	if err != nil {
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, createSearchResultResponse(total, pds))
+}
+
+// DownloadPackageFile serves the content of a crate
+func DownloadPackageFile(ctx *context.Context) {
+	packageName := ctx.Params("package")
+	packageVersion := ctx.Params("version")
+
+	s, pf, err := packages_service.GetFileStreamByPackageNameAndVersion(
+		ctx,
+		&packages_service.PackageInfo{
+			Owner:       ctx.Package.Owner,
+			PackageType: packages_model.TypeCargo,
+			Name:        packageName,
+			Version:     packageVersion,
+		},
+		&packages_service.PackageFileInfo{
+			Filename: createFilename(packageName, packageVersion),
+		},
+	)
+	if err != nil {
+		if err == packages_model.ErrPackageNotExist || err == packages_model.ErrPackageFileNotExist {
+			apiError(ctx, http.StatusNotFound, err)
+			return
+		}
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	defer s.Close()
+
+	ctx.ServeStream(s, pf.Name)
+}
+
+// UploadPackage publishes a new crate version
+// https://doc.rust-lang.org/cargo/reference/registries.html#publish
+func UploadPackage(ctx *context.Context) {
+	upload, close, err := ctx.UploadStream()
+	if err != nil {
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	if close {
+		defer upload.Close()
+	}
+
+	cp, err := cargo_module.ParsePackage(upload)
+	if err != nil {
+		apiError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	buf, err := packages_module.CreateHashedBufferFromReader(cp.Content, 32*1024*1024)
+	if err != nil {
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	defer buf.Close()
+
+	if buf.Size() != cp.ContentSize {
+		apiError(ctx, http.StatusBadRequest, cargo_module.ErrInvalidPayload)
+		return
+	}
+
+	_, _, err = packages_service.CreatePackageAndAddFile(
+		&packages_service.PackageCreationInfo{
+			PackageInfo: packages_service.PackageInfo{
+				Owner:       ctx.Package.Owner,
+				PackageType: packages_model.TypeCargo,
+				Name:        cp.Name,
+				Version:     cp.Version,
+			},
+			SemverCompatible: true,
+			Creator:          ctx.Doer,
+			Metadata:         cp.Metadata,
+			Properties: map[string]string{
+				cargo_module.PropertyYanked: "false",
+			},
+		},
+		&packages_service.PackageFileCreationInfo{
+			PackageFileInfo: packages_service.PackageFileInfo{
+				Filename: createFilename(cp.Name, cp.Version),
+			},
+			Data:   buf,
+			IsLead: true,
+		},
+	)
+	if err != nil {
+		if err == packages_model.ErrDuplicatePackageVersion {
+			apiError(ctx, http.StatusConflict, err)
+			return
+		}
+		if err == packages_service.ErrPackageAccessDenied {
+			apiError(ctx, http.StatusForbidden, err)
+			return
+		}
+		if quota_model.IsErrQuotaExceeded(err) {
+			apiError(ctx, http.StatusRequestEntityTooLarge, err)
+			return
+		}
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, &PublishResponse{
+		Warnings: &PublishWarnings{
+			InvalidCategories: []string{},
+			InvalidBadges:     []string{},
+			Other:             []string{},
+		},
+	})
+}
+
+// YankPackage marks a crate version as yanked
+// https://doc.rust-lang.org/cargo/reference/registries.html#yank
+func YankPackage(ctx *context.Context) {
+	setPackageYanked(ctx, true)
+}
+
+// UnyankPackage removes the yanked mark of a crate version
+// https://doc.rust-lang.org/cargo/reference/registries.html#unyank
+func UnyankPackage(ctx *context.Context) {
+	setPackageYanked(ctx, false)
+}
+
+func setPackageYanked(ctx *context.Context, yanked bool) {
+	pv, err := packages_model.GetVersionByNameAndVersion(ctx, ctx.Package.Owner.ID, packages_model.TypeCargo, ctx.Params("package"), ctx.Params("version"))
+	if err != nil {
+		if err == packages_model.ErrPackageNotExist {
+			apiError(ctx, http.StatusNotFound, err)
+			return
+		}
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	value := fmt.Sprint(yanked)
+
+	pps, err := packages_model.GetPropertiesByName(ctx, packages_model.PropertyTypeVersion, pv.ID, cargo_module.PropertyYanked)
+	if err != nil {
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	if len(pps) == 0 {
+		_, err = packages_model.InsertProperty(ctx, packages_model.PropertyTypeVersion, pv.ID, cargo_module.PropertyYanked, value)
+	} else {
+		pps[0].Value = value
+		err = packages_model.UpdateProperty(ctx, pps[0])
+	}
+	if err != nil {
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, &OkResponse{Ok: true})
+}
+
+func createFilename(name, version string) string {
+	return strings.ToLower(fmt.Sprintf("%s-%s.crate", name, version))
+}
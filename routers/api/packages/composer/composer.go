@@ -14,6 +14,7 @@ import (
 
 	"code.gitea.io/gitea/models/db"
 	packages_model "code.gitea.io/gitea/models/packages"
+	quota_model "code.gitea.io/gitea/models/quota"
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/convert"
 	packages_module "code.gitea.io/gitea/modules/packages"
@@ -242,6 +243,14 @@ func UploadPackage(ctx *context.Context) {
 			apiError(ctx, http.StatusBadRequest, err)
 			return
 		}
+		if err == packages_service.ErrPackageAccessDenied {
+			apiError(ctx, http.StatusForbidden, err)
+			return
+		}
+		if quota_model.IsErrQuotaExceeded(err) {
+			apiError(ctx, http.StatusRequestEntityTooLarge, err)
+			return
+		}
 		apiError(ctx, http.StatusInternalServerError, err)
 		return
 	}
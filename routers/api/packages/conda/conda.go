@@ -0,0 +1,244 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package conda
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	packages_model "code.gitea.io/gitea/models/packages"
+	quota_model "code.gitea.io/gitea/models/quota"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/json"
+	packages_module "code.gitea.io/gitea/modules/packages"
+	conda_module "code.gitea.io/gitea/modules/packages/conda"
+	"code.gitea.io/gitea/routers/api/packages/helper"
+	packages_service "code.gitea.io/gitea/services/packages"
+	conda_service "code.gitea.io/gitea/services/packages/conda"
+)
+
+const (
+	extensionTarBz2 = ".tar.bz2"
+	extensionConda  = ".conda"
+)
+
+func apiError(ctx *context.Context, status int, obj interface{}) {
+	helper.LogAndProcessError(ctx, status, obj, func(message string) {
+		ctx.JSON(status, struct {
+			Error string `json:"error"`
+		}{
+			Error: message,
+		})
+	})
+}
+
+// ChannelData serves the "channeldata.json" file of the channel
+func ChannelData(ctx *context.Context) {
+	cd, err := conda_service.BuildChannelData(ctx, ctx.Package.Owner.ID)
+	if err != nil {
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, cd)
+}
+
+// RepositoryData serves the "repodata.json" file of a subdir
+func RepositoryData(ctx *context.Context) {
+	rd, err := conda_service.BuildRepositoryData(ctx, ctx.Package.Owner.ID, ctx.Params("subdir"))
+	if err != nil {
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, rd)
+}
+
+// UploadPackageFile adds a ".tar.bz2" or ".conda" package file
+// The subdir is read from the "info/index.json" file of the package.
+func UploadPackageFile(ctx *context.Context) {
+	filename := ctx.Params("filename")
+
+	var extension string
+	switch {
+	case strings.HasSuffix(filename, extensionTarBz2):
+		extension = extensionTarBz2
+	case strings.HasSuffix(filename, extensionConda):
+		extension = extensionConda
+	default:
+		apiError(ctx, http.StatusBadRequest, "unsupported package format")
+		return
+	}
+
+	upload, close, err := ctx.UploadStream()
+	if err != nil {
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	if close {
+		defer upload.Close()
+	}
+
+	buf, err := packages_module.CreateHashedBufferFromReader(upload, 32*1024*1024)
+	if err != nil {
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	defer buf.Close()
+
+	var pck *conda_module.Package
+	if extension == extensionTarBz2 {
+		pck, err = conda_module.ParsePackageBZ2(buf)
+	} else {
+		pck, err = conda_module.ParsePackageConda(buf, buf.Size())
+	}
+	if err != nil {
+		apiError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	if _, err := buf.Seek(0, io.SeekStart); err != nil {
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	fileMetadata, err := json.Marshal(pck.FileMetadata)
+	if err != nil {
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	_, _, err = packages_service.CreatePackageOrAddFileToExisting(
+		&packages_service.PackageCreationInfo{
+			PackageInfo: packages_service.PackageInfo{
+				Owner:       ctx.Package.Owner,
+				PackageType: packages_model.TypeConda,
+				Name:        pck.Name,
+				Version:     pck.Version,
+			},
+			Creator:  ctx.Doer,
+			Metadata: pck.VersionMetadata,
+		},
+		&packages_service.PackageFileCreationInfo{
+			PackageFileInfo: packages_service.PackageFileInfo{
+				Filename:     createFilename(pck.Name, pck.Version, pck.FileMetadata.Build, extension),
+				CompositeKey: pck.Subdir,
+			},
+			Data:   buf,
+			IsLead: true,
+			Properties: map[string]string{
+				conda_module.PropertySubdir:   pck.Subdir,
+				conda_module.PropertyMetadata: string(fileMetadata),
+			},
+		},
+	)
+	if err != nil {
+		if err == packages_model.ErrDuplicatePackageFile {
+			apiError(ctx, http.StatusConflict, err)
+			return
+		}
+		if err == packages_service.ErrPackageAccessDenied {
+			apiError(ctx, http.StatusForbidden, err)
+			return
+		}
+		if quota_model.IsErrQuotaExceeded(err) {
+			apiError(ctx, http.StatusRequestEntityTooLarge, err)
+			return
+		}
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	ctx.Status(http.StatusCreated)
+}
+
+// DownloadPackageFile serves the content of a package file
+func DownloadPackageFile(ctx *context.Context) {
+	pf, err := getPackageFile(ctx)
+	if err != nil {
+		if err == packages_model.ErrPackageNotExist || err == packages_model.ErrPackageFileNotExist {
+			apiError(ctx, http.StatusNotFound, err)
+			return
+		}
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	s, _, err := packages_service.GetPackageFileStream(ctx, pf)
+	if err != nil {
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	defer s.Close()
+
+	ctx.ServeStream(s, pf.Name)
+}
+
+// DeletePackageFile removes a package file from a subdir
+func DeletePackageFile(ctx *context.Context) {
+	pf, err := getPackageFile(ctx)
+	if err != nil {
+		if err == packages_model.ErrPackageNotExist || err == packages_model.ErrPackageFileNotExist {
+			apiError(ctx, http.StatusNotFound, err)
+			return
+		}
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	if err := packages_service.RemovePackageFileAndVersionIfUnreferenced(ctx.Doer, pf); err != nil {
+		if err == packages_service.ErrPackageAccessDenied {
+			apiError(ctx, http.StatusForbidden, err)
+			return
+		}
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+func getPackageFile(ctx *context.Context) (*packages_model.PackageFile, error) {
+	filename := ctx.Params("filename")
+
+	name, version, err := parseFilename(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	pv, err := packages_model.GetVersionByNameAndVersion(ctx, ctx.Package.Owner.ID, packages_model.TypeConda, name, version)
+	if err != nil {
+		return nil, err
+	}
+
+	return packages_model.GetFileForVersionByName(ctx, pv.ID, filename, ctx.Params("subdir"))
+}
+
+func createFilename(name, version, build, extension string) string {
+	return fmt.Sprintf("%s-%s-%s%s", name, version, build, extension)
+}
+
+// parseFilename splits a "name-version-build.ext" filename
+// The version and the build string must not contain a dash but the name may.
+func parseFilename(filename string) (string, string, error) {
+	var base string
+	switch {
+	case strings.HasSuffix(filename, extensionTarBz2):
+		base = strings.TrimSuffix(filename, extensionTarBz2)
+	case strings.HasSuffix(filename, extensionConda):
+		base = strings.TrimSuffix(filename, extensionConda)
+	default:
+		return "", "", packages_model.ErrPackageFileNotExist
+	}
+
+	parts := strings.Split(base, "-")
+	if len(parts) < 3 {
+		return "", "", packages_model.ErrPackageFileNotExist
+	}
+
+	return strings.Join(parts[:len(parts)-2], "-"), parts[len(parts)-2], nil
+}
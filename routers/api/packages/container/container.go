@@ -16,6 +16,7 @@ import (
 
 	packages_model "code.gitea.io/gitea/models/packages"
 	container_model "code.gitea.io/gitea/models/packages/container"
+	quota_model "code.gitea.io/gitea/models/quota"
 	user_model "code.gitea.io/gitea/models/user"
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/json"
@@ -125,6 +126,35 @@ func VerifyImageName(ctx *context.Context) {
 	}
 }
 
+// ReqImageWriteAccess checks if the doer is allowed to upload to an already published image
+// Uploading blobs creates the package too, it only counts as existing once an image has been published.
+func ReqImageWriteAccess(ctx *context.Context) {
+	image := ctx.Params("image")
+
+	pvs, err := packages_model.GetVersionsByPackageName(ctx, ctx.Package.Owner.ID, packages_model.TypeContainer, image)
+	if err != nil {
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	if len(pvs) == 0 {
+		return
+	}
+
+	p, err := packages_model.GetPackageByName(ctx, ctx.Package.Owner.ID, packages_model.TypeContainer, image)
+	if err != nil {
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	if err := packages_service.CheckWritePackage(ctx, ctx.Package.Owner, ctx.Doer, p); err != nil {
+		if err == packages_service.ErrPackageAccessDenied {
+			apiErrorDefined(ctx, errDenied.WithMessage(err.Error()))
+		} else {
+			apiError(ctx, http.StatusInternalServerError, err)
+		}
+	}
+}
+
 // DetermineSupport is used to test if the registry supports OCI
 // https://github.com/opencontainers/distribution-spec/blob/main/spec.md#determining-support
 func DetermineSupport(ctx *context.Context) {
@@ -190,6 +220,10 @@ func InitiateUploadBlob(ctx *context.Context) {
 		}
 
 		if _, err := saveAsPackageBlob(buf, &packages_service.PackageInfo{Owner: ctx.Package.Owner, Name: image}); err != nil {
+			if quota_model.IsErrQuotaExceeded(err) {
+				apiErrorDefined(ctx, errDenied.WithMessage(err.Error()).WithStatusCode(http.StatusRequestEntityTooLarge))
+				return
+			}
 			apiError(ctx, http.StatusInternalServerError, err)
 			return
 		}
@@ -300,6 +334,10 @@ func EndUploadBlob(ctx *context.Context) {
 	}
 
 	if _, err := saveAsPackageBlob(uploader, &packages_service.PackageInfo{Owner: ctx.Package.Owner, Name: image}); err != nil {
+		if quota_model.IsErrQuotaExceeded(err) {
+			apiErrorDefined(ctx, errDenied.WithMessage(err.Error()).WithStatusCode(http.StatusRequestEntityTooLarge))
+			return
+		}
 		apiError(ctx, http.StatusInternalServerError, err)
 		return
 	}
@@ -442,6 +480,8 @@ func UploadManifest(ctx *context.Context) {
 			apiErrorDefined(ctx, namedError)
 		} else if errors.Is(err, container_model.ErrContainerBlobNotExist) {
 			apiErrorDefined(ctx, errBlobUnknown)
+		} else if errors.Is(err, packages_service.ErrPackageAccessDenied) {
+			apiErrorDefined(ctx, errDenied.WithMessage(err.Error()))
 		} else {
 			apiError(ctx, http.StatusInternalServerError, err)
 		}
@@ -556,6 +596,10 @@ func DeleteManifest(ctx *context.Context) {
 
 	for _, pv := range pvs {
 		if err := packages_service.RemovePackageVersion(ctx.Doer, pv); err != nil {
+			if err == packages_service.ErrPackageAccessDenied {
+				apiErrorDefined(ctx, errDenied.WithMessage(err.Error()))
+				return
+			}
 			apiError(ctx, http.StatusInternalServerError, err)
 			return
 		}
@@ -281,6 +281,31 @@ func createPackageAndVersion(ctx context.Context, mci *manifestCreationInfo, met
 		}
 	}
 
+	// Uploading blobs creates the package too, it only counts as existing once an image has been published
+	pvs, err := packages_model.GetVersionsByPackageName(ctx, mci.Owner.ID, packages_model.TypeContainer, p.LowerName)
+	if err != nil {
+		return nil, err
+	}
+	packageCreated := len(pvs) == 0
+
+	// The permissions of an existing package must not depend on the repository referenced by the new image
+	if !packageCreated {
+		if err := packages_service.CheckWritePackage(ctx, mci.Owner, mci.Creator, p); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := packages_service.LinkRepositoryFromMetadata(ctx, mci.Owner, p, metadata); err != nil {
+		log.Error("Error linking package to repository: %v", err)
+		return nil, err
+	}
+
+	if packageCreated {
+		if err := packages_service.CheckWritePackage(ctx, mci.Owner, mci.Creator, p); err != nil {
+			return nil, err
+		}
+	}
+
 	metadata.IsTagged = mci.IsTagged
 
 	metadataJSON, err := json.Marshal(metadata)
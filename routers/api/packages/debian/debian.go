@@ -0,0 +1,300 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package debian
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+
+	packages_model "code.gitea.io/gitea/models/packages"
+	quota_model "code.gitea.io/gitea/models/quota"
+	"code.gitea.io/gitea/modules/context"
+	packages_module "code.gitea.io/gitea/modules/packages"
+	debian_module "code.gitea.io/gitea/modules/packages/debian"
+	"code.gitea.io/gitea/routers/api/packages/helper"
+	packages_service "code.gitea.io/gitea/services/packages"
+	debian_service "code.gitea.io/gitea/services/packages/debian"
+)
+
+// https://wiki.debian.org/DebianRepository/Format#Codename
+var distributionOrComponentPattern = regexp.MustCompile(`\A[a-zA-Z0-9][a-zA-Z0-9._-]*\z`)
+
+func apiError(ctx *context.Context, status int, obj interface{}) {
+	helper.LogAndProcessError(ctx, status, obj, func(message string) {
+		ctx.PlainText(status, message)
+	})
+}
+
+// GetRepositoryKey serves the public key used to sign the repository files
+func GetRepositoryKey(ctx *context.Context) {
+	_, pub, err := debian_service.GetOrCreateKeyPair(ctx.Package.Owner.ID)
+	if err != nil {
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	ctx.ServeContent("repository.key", strings.NewReader(pub))
+}
+
+// GetRepositoryFile serves the "Release", "InRelease" and "Release.gpg" files of a distribution
+// https://wiki.debian.org/DebianRepository/Format#A.22Release.22_files
+func GetRepositoryFile(ctx *context.Context) {
+	distribution := ctx.Params("distribution")
+	if !distributionOrComponentPattern.MatchString(distribution) {
+		apiError(ctx, http.StatusBadRequest, "invalid distribution")
+		return
+	}
+
+	filename := ctx.Params("filename")
+	if filename != "Release" && filename != "InRelease" && filename != "Release.gpg" {
+		apiError(ctx, http.StatusNotFound, nil)
+		return
+	}
+
+	release, err := debian_service.BuildReleaseFile(ctx, ctx.Package.Owner, distribution)
+	if err != nil {
+		if err == packages_model.ErrPackageNotExist {
+			apiError(ctx, http.StatusNotFound, err)
+			return
+		}
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	content := release
+	switch filename {
+	case "InRelease":
+		content, err = debian_service.SignReleaseFile(ctx.Package.Owner.ID, release)
+	case "Release.gpg":
+		content, err = debian_service.SignReleaseFileDetached(ctx.Package.Owner.ID, release)
+	}
+	if err != nil {
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	ctx.ServeContent(filename, bytes.NewReader(content))
+}
+
+// GetPackagesIndex serves the "Packages" index of a component and architecture
+// https://wiki.debian.org/DebianRepository/Format#A.22Packages.22_Indices
+func GetPackagesIndex(ctx *context.Context) {
+	distribution := ctx.Params("distribution")
+	component := ctx.Params("component")
+	architecture := ctx.Params("architecture")
+	if !distributionOrComponentPattern.MatchString(distribution) || !distributionOrComponentPattern.MatchString(component) {
+		apiError(ctx, http.StatusBadRequest, "invalid distribution or component")
+		return
+	}
+
+	filename := ctx.Params("filename")
+	if filename != "Packages" && filename != "Packages.gz" {
+		apiError(ctx, http.StatusNotFound, nil)
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := debian_service.BuildPackagesIndex(ctx, ctx.Package.Owner.ID, distribution, component, architecture, &buf); err != nil {
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	content := buf.Bytes()
+	if filename == "Packages.gz" {
+		var err error
+		content, err = debian_service.CompressIndex(content)
+		if err != nil {
+			apiError(ctx, http.StatusInternalServerError, err)
+			return
+		}
+	}
+
+	ctx.ServeContent(filename, bytes.NewReader(content))
+}
+
+// UploadPackageFile adds a package file to a distribution and component
+func UploadPackageFile(ctx *context.Context) {
+	distribution := ctx.Params("distribution")
+	component := ctx.Params("component")
+	if !distributionOrComponentPattern.MatchString(distribution) || !distributionOrComponentPattern.MatchString(component) {
+		apiError(ctx, http.StatusBadRequest, "invalid distribution or component")
+		return
+	}
+
+	upload, close, err := ctx.UploadStream()
+	if err != nil {
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	if close {
+		defer upload.Close()
+	}
+
+	buf, err := packages_module.CreateHashedBufferFromReader(upload, 32*1024*1024)
+	if err != nil {
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	defer buf.Close()
+
+	pck, err := debian_module.ParsePackage(buf)
+	if err != nil {
+		apiError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	if _, err := buf.Seek(0, io.SeekStart); err != nil {
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	_, _, err = packages_service.CreatePackageOrAddFileToExisting(
+		&packages_service.PackageCreationInfo{
+			PackageInfo: packages_service.PackageInfo{
+				Owner:       ctx.Package.Owner,
+				PackageType: packages_model.TypeDebian,
+				Name:        pck.Name,
+				Version:     pck.Version,
+			},
+			Creator:  ctx.Doer,
+			Metadata: pck.Metadata,
+		},
+		&packages_service.PackageFileCreationInfo{
+			PackageFileInfo: packages_service.PackageFileInfo{
+				Filename:     createFilename(pck.Name, pck.Version, pck.Architecture),
+				CompositeKey: createCompositeKey(distribution, component),
+			},
+			Data:   buf,
+			IsLead: true,
+			Properties: map[string]string{
+				debian_module.PropertyDistribution: distribution,
+				debian_module.PropertyComponent:    component,
+				debian_module.PropertyArchitecture: pck.Architecture,
+				debian_module.PropertyControl:      pck.Control,
+			},
+		},
+	)
+	if err != nil {
+		if err == packages_model.ErrDuplicatePackageFile {
+			apiError(ctx, http.StatusConflict, err)
+			return
+		}
+		if err == packages_service.ErrPackageAccessDenied {
+			apiError(ctx, http.StatusForbidden, err)
+			return
+		}
+		if quota_model.IsErrQuotaExceeded(err) {
+			apiError(ctx, http.StatusRequestEntityTooLarge, err)
+			return
+		}
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	ctx.Status(http.StatusCreated)
+}
+
+// DownloadPackageFile serves the content of a package file
+func DownloadPackageFile(ctx *context.Context) {
+	name, version, architecture, err := parseFilename(ctx.Params("filename"))
+	if err != nil {
+		apiError(ctx, http.StatusNotFound, err)
+		return
+	}
+
+	s, pf, err := packages_service.GetFileStreamByPackageNameAndVersion(
+		ctx,
+		&packages_service.PackageInfo{
+			Owner:       ctx.Package.Owner,
+			PackageType: packages_model.TypeDebian,
+			Name:        name,
+			Version:     version,
+		},
+		&packages_service.PackageFileInfo{
+			Filename:     createFilename(name, version, architecture),
+			CompositeKey: createCompositeKey(ctx.Params("distribution"), ctx.Params("component")),
+		},
+	)
+	if err != nil {
+		if err == packages_model.ErrPackageNotExist || err == packages_model.ErrPackageFileNotExist {
+			apiError(ctx, http.StatusNotFound, err)
+			return
+		}
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	defer s.Close()
+
+	ctx.ServeStream(s, pf.Name)
+}
+
+// DeletePackageFile removes a package file from a distribution and component
+func DeletePackageFile(ctx *context.Context) {
+	name := ctx.Params("name")
+	version := ctx.Params("version")
+	architecture := ctx.Params("architecture")
+
+	pv, err := packages_model.GetVersionByNameAndVersion(ctx, ctx.Package.Owner.ID, packages_model.TypeDebian, name, version)
+	if err != nil {
+		if err == packages_model.ErrPackageNotExist {
+			apiError(ctx, http.StatusNotFound, err)
+			return
+		}
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	pf, err := packages_model.GetFileForVersionByName(
+		ctx,
+		pv.ID,
+		createFilename(name, version, architecture),
+		createCompositeKey(ctx.Params("distribution"), ctx.Params("component")),
+	)
+	if err != nil {
+		if err == packages_model.ErrPackageFileNotExist {
+			apiError(ctx, http.StatusNotFound, err)
+			return
+		}
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	if err := packages_service.RemovePackageFileAndVersionIfUnreferenced(ctx.Doer, pf); err != nil {
+		if err == packages_service.ErrPackageAccessDenied {
+			apiError(ctx, http.StatusForbidden, err)
+			return
+		}
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+func createFilename(name, version, architecture string) string {
+	return fmt.Sprintf("%s_%s_%s.deb", name, version, architecture)
+}
+
+func createCompositeKey(distribution, component string) string {
+	return distribution + "|" + component
+}
+
+// parseFilename splits a "name_version_architecture.deb" filename
+func parseFilename(filename string) (string, string, string, error) {
+	if !strings.HasSuffix(filename, ".deb") {
+		return "", "", "", debian_module.ErrInvalidArchive
+	}
+
+	parts := strings.Split(strings.TrimSuffix(filename, ".deb"), "_")
+	if len(parts) != 3 {
+		return "", "", "", debian_module.ErrInvalidArchive
+	}
+
+	return parts[0], parts[1], parts[2], nil
+}
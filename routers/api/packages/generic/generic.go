@@ -10,6 +10,7 @@ import (
 	"regexp"
 
 	packages_model "code.gitea.io/gitea/models/packages"
+	quota_model "code.gitea.io/gitea/models/quota"
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/log"
 	packages_module "code.gitea.io/gitea/modules/packages"
@@ -113,6 +114,14 @@ func UploadPackage(ctx *context.Context) {
 			apiError(ctx, http.StatusBadRequest, err)
 			return
 		}
+		if err == packages_service.ErrPackageAccessDenied {
+			apiError(ctx, http.StatusForbidden, err)
+			return
+		}
+		if quota_model.IsErrQuotaExceeded(err) {
+			apiError(ctx, http.StatusRequestEntityTooLarge, err)
+			return
+		}
 		apiError(ctx, http.StatusInternalServerError, err)
 		return
 	}
@@ -142,6 +151,10 @@ func DeletePackage(ctx *context.Context) {
 			apiError(ctx, http.StatusNotFound, err)
 			return
 		}
+		if err == packages_service.ErrPackageAccessDenied {
+			apiError(ctx, http.StatusForbidden, err)
+			return
+		}
 		apiError(ctx, http.StatusInternalServerError, err)
 		return
 	}
@@ -0,0 +1,298 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package goproxy
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	packages_model "code.gitea.io/gitea/models/packages"
+	quota_model "code.gitea.io/gitea/models/quota"
+	"code.gitea.io/gitea/modules/context"
+	packages_module "code.gitea.io/gitea/modules/packages"
+	goproxy_module "code.gitea.io/gitea/modules/packages/goproxy"
+	"code.gitea.io/gitea/routers/api/packages/helper"
+	packages_service "code.gitea.io/gitea/services/packages"
+
+	"github.com/hashicorp/go-version"
+)
+
+func apiError(ctx *context.Context, status int, obj interface{}) {
+	helper.LogAndProcessError(ctx, status, obj, func(message string) {
+		ctx.PlainText(status, message)
+	})
+}
+
+// VersionInfo is the response of the .info and @latest endpoints
+type VersionInfo struct {
+	Version string    `json:"Version"`
+	Time    time.Time `json:"Time"`
+}
+
+// ServeRequest dispatches the GOPROXY protocol requests
+// https://go.dev/ref/mod#goproxy-protocol
+//
+//	{module}/@v/list
+//	{module}/@v/{version}.info
+//	{module}/@v/{version}.mod
+//	{module}/@v/{version}.zip
+//	{module}/@latest
+func ServeRequest(ctx *context.Context) {
+	path := ctx.Params("*")
+
+	if strings.HasSuffix(path, "/@latest") {
+		modulePath, err := goproxy_module.UnescapePath(strings.TrimSuffix(path, "/@latest"))
+		if err != nil {
+			apiError(ctx, http.StatusNotFound, err)
+			return
+		}
+
+		pv, err := getLatestVersion(ctx, modulePath)
+		if err != nil {
+			if err == packages_model.ErrPackageNotExist {
+				apiError(ctx, http.StatusNotFound, err)
+				return
+			}
+			apiError(ctx, http.StatusInternalServerError, err)
+			return
+		}
+
+		serveInfo(ctx, pv)
+		return
+	}
+
+	parts := strings.SplitN(path, "/@v/", 2)
+	if len(parts) != 2 {
+		apiError(ctx, http.StatusNotFound, nil)
+		return
+	}
+
+	modulePath, err := goproxy_module.UnescapePath(parts[0])
+	if err != nil {
+		apiError(ctx, http.StatusNotFound, err)
+		return
+	}
+
+	if parts[1] == "list" {
+		enumerateVersions(ctx, modulePath)
+		return
+	}
+
+	i := strings.LastIndexByte(parts[1], '.')
+	if i == -1 {
+		apiError(ctx, http.StatusNotFound, nil)
+		return
+	}
+
+	moduleVersion, err := goproxy_module.UnescapePath(parts[1][:i])
+	if err != nil {
+		apiError(ctx, http.StatusNotFound, err)
+		return
+	}
+
+	switch parts[1][i:] {
+	case ".info":
+		pv, err := packages_model.GetVersionByNameAndVersion(ctx, ctx.Package.Owner.ID, packages_model.TypeGo, modulePath, moduleVersion)
+		if err != nil {
+			if err == packages_model.ErrPackageNotExist {
+				apiError(ctx, http.StatusNotFound, err)
+				return
+			}
+			apiError(ctx, http.StatusInternalServerError, err)
+			return
+		}
+		serveInfo(ctx, pv)
+	case ".mod":
+		serveGoMod(ctx, modulePath, moduleVersion)
+	case ".zip":
+		servePackageFile(ctx, modulePath, moduleVersion)
+	default:
+		apiError(ctx, http.StatusNotFound, nil)
+	}
+}
+
+// enumerateVersions serves the list of known versions of a module
+func enumerateVersions(ctx *context.Context, modulePath string) {
+	pvs, err := packages_model.GetVersionsByPackageName(ctx, ctx.Package.Owner.ID, packages_model.TypeGo, modulePath)
+	if err != nil {
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	if len(pvs) == 0 {
+		apiError(ctx, http.StatusNotFound, packages_model.ErrPackageNotExist)
+		return
+	}
+
+	var sb strings.Builder
+	for _, pv := range pvs {
+		sb.WriteString(pv.Version)
+		sb.WriteByte('\n')
+	}
+
+	ctx.PlainText(http.StatusOK, sb.String())
+}
+
+// getLatestVersion returns the highest release version or the highest pre-release version if there is no release
+func getLatestVersion(ctx *context.Context, modulePath string) (*packages_model.PackageVersion, error) {
+	pvs, err := packages_model.GetVersionsByPackageName(ctx, ctx.Package.Owner.ID, packages_model.TypeGo, modulePath)
+	if err != nil {
+		return nil, err
+	}
+
+	var latest *packages_model.PackageVersion
+	var latestVersion *version.Version
+	for _, pv := range pvs {
+		v, err := version.NewSemver(pv.Version)
+		if err != nil {
+			continue
+		}
+		if latestVersion == nil ||
+			(latestVersion.Prerelease() != "" && v.Prerelease() == "") ||
+			((latestVersion.Prerelease() == "") == (v.Prerelease() == "") && v.GreaterThan(latestVersion)) {
+			latest = pv
+			latestVersion = v
+		}
+	}
+	if latest == nil {
+		return nil, packages_model.ErrPackageNotExist
+	}
+	return latest, nil
+}
+
+func serveInfo(ctx *context.Context, pv *packages_model.PackageVersion) {
+	ctx.JSON(http.StatusOK, &VersionInfo{
+		Version: pv.Version,
+		Time:    pv.CreatedUnix.AsTime(),
+	})
+}
+
+func serveGoMod(ctx *context.Context, modulePath, moduleVersion string) {
+	pv, err := packages_model.GetVersionByNameAndVersion(ctx, ctx.Package.Owner.ID, packages_model.TypeGo, modulePath, moduleVersion)
+	if err != nil {
+		if err == packages_model.ErrPackageNotExist {
+			apiError(ctx, http.StatusNotFound, err)
+			return
+		}
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	pps, err := packages_model.GetPropertiesByName(ctx, packages_model.PropertyTypeVersion, pv.ID, goproxy_module.PropertyGoMod)
+	if err != nil {
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	goMod := fmt.Sprintf("module %s\n", modulePath)
+	if len(pps) > 0 {
+		goMod = pps[0].Value
+	}
+
+	ctx.PlainText(http.StatusOK, goMod)
+}
+
+func servePackageFile(ctx *context.Context, modulePath, moduleVersion string) {
+	s, pf, err := packages_service.GetFileStreamByPackageNameAndVersion(
+		ctx,
+		&packages_service.PackageInfo{
+			Owner:       ctx.Package.Owner,
+			PackageType: packages_model.TypeGo,
+			Name:        modulePath,
+			Version:     moduleVersion,
+		},
+		&packages_service.PackageFileInfo{
+			Filename: createFilename(moduleVersion),
+		},
+	)
+	if err != nil {
+		if err == packages_model.ErrPackageNotExist || err == packages_model.ErrPackageFileNotExist {
+			apiError(ctx, http.StatusNotFound, err)
+			return
+		}
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	defer s.Close()
+
+	ctx.ServeStream(s, pf.Name)
+}
+
+// UploadPackage adds a module zip file
+// The module path and version are read from the zip layout.
+func UploadPackage(ctx *context.Context) {
+	upload, close, err := ctx.UploadStream()
+	if err != nil {
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	if close {
+		defer upload.Close()
+	}
+
+	buf, err := packages_module.CreateHashedBufferFromReader(upload, 32*1024*1024)
+	if err != nil {
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	defer buf.Close()
+
+	gp, err := goproxy_module.ParsePackage(buf, buf.Size())
+	if err != nil {
+		apiError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	if _, err := buf.Seek(0, io.SeekStart); err != nil {
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	_, _, err = packages_service.CreatePackageAndAddFile(
+		&packages_service.PackageCreationInfo{
+			PackageInfo: packages_service.PackageInfo{
+				Owner:       ctx.Package.Owner,
+				PackageType: packages_model.TypeGo,
+				Name:        gp.Name,
+				Version:     gp.Version,
+			},
+			Creator: ctx.Doer,
+			Properties: map[string]string{
+				goproxy_module.PropertyGoMod: gp.GoMod,
+			},
+		},
+		&packages_service.PackageFileCreationInfo{
+			PackageFileInfo: packages_service.PackageFileInfo{
+				Filename: createFilename(gp.Version),
+			},
+			Data:   buf,
+			IsLead: true,
+		},
+	)
+	if err != nil {
+		if err == packages_model.ErrDuplicatePackageVersion {
+			apiError(ctx, http.StatusConflict, err)
+			return
+		}
+		if err == packages_service.ErrPackageAccessDenied {
+			apiError(ctx, http.StatusForbidden, err)
+			return
+		}
+		if quota_model.IsErrQuotaExceeded(err) {
+			apiError(ctx, http.StatusRequestEntityTooLarge, err)
+			return
+		}
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	ctx.Status(http.StatusCreated)
+}
+
+func createFilename(moduleVersion string) string {
+	return strings.ToLower(moduleVersion) + ".zip"
+}
@@ -13,6 +13,7 @@ import (
 	"time"
 
 	packages_model "code.gitea.io/gitea/models/packages"
+	quota_model "code.gitea.io/gitea/models/quota"
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/json"
 	"code.gitea.io/gitea/modules/log"
@@ -193,6 +194,14 @@ func UploadPackage(ctx *context.Context) {
 			apiError(ctx, http.StatusConflict, err)
 			return
 		}
+		if err == packages_service.ErrPackageAccessDenied {
+			apiError(ctx, http.StatusForbidden, err)
+			return
+		}
+		if quota_model.IsErrQuotaExceeded(err) {
+			apiError(ctx, http.StatusRequestEntityTooLarge, err)
+			return
+		}
 		apiError(ctx, http.StatusInternalServerError, err)
 		return
 	}
@@ -19,6 +19,7 @@ import (
 	"strings"
 
 	packages_model "code.gitea.io/gitea/models/packages"
+	quota_model "code.gitea.io/gitea/models/quota"
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/json"
 	"code.gitea.io/gitea/modules/log"
@@ -26,6 +27,7 @@ import (
 	maven_module "code.gitea.io/gitea/modules/packages/maven"
 	"code.gitea.io/gitea/routers/api/packages/helper"
 	packages_service "code.gitea.io/gitea/services/packages"
+	proxy_service "code.gitea.io/gitea/services/packages/proxy"
 )
 
 const (
@@ -71,18 +73,33 @@ func serveMavenMetadata(ctx *context.Context, params parameters) {
 		apiError(ctx, http.StatusInternalServerError, err)
 		return
 	}
-	if len(pvs) == 0 {
+
+	// the local versions are served if the upstream registry is not available
+	upstream, err := fetchUpstreamMetadata(ctx, params)
+	if err != nil && err != proxy_service.ErrUpstreamNotExist {
+		log.Warn("Error fetching upstream metadata of Maven package %s: %v", packageName, err)
+	}
+
+	if len(pvs) == 0 && upstream == nil {
 		apiError(ctx, http.StatusNotFound, packages_model.ErrPackageNotExist)
 		return
 	}
 
-	pds, err := packages_model.GetPackageDescriptors(ctx, pvs)
-	if err != nil {
-		apiError(ctx, http.StatusInternalServerError, err)
-		return
+	var metadata *MetadataResponse
+	if len(pvs) != 0 {
+		pds, err := packages_model.GetPackageDescriptors(ctx, pvs)
+		if err != nil {
+			apiError(ctx, http.StatusInternalServerError, err)
+			return
+		}
+
+		metadata = createMetadataResponse(pds)
+	}
+	if upstream != nil {
+		metadata = mergeUpstreamMetadata(metadata, upstream)
 	}
 
-	xmlMetadata, err := xml.Marshal(createMetadataResponse(pds))
+	xmlMetadata, err := xml.Marshal(metadata)
 	if err != nil {
 		apiError(ctx, http.StatusInternalServerError, err)
 		return
@@ -116,16 +133,6 @@ func serveMavenMetadata(ctx *context.Context, params parameters) {
 func servePackageFile(ctx *context.Context, params parameters) {
 	packageName := params.GroupID + "-" + params.ArtifactID
 
-	pv, err := packages_model.GetVersionByNameAndVersion(ctx, ctx.Package.Owner.ID, packages_model.TypeMaven, packageName, params.Version)
-	if err != nil {
-		if err == packages_model.ErrPackageNotExist {
-			apiError(ctx, http.StatusNotFound, err)
-		} else {
-			apiError(ctx, http.StatusInternalServerError, err)
-		}
-		return
-	}
-
 	filename := params.Filename
 
 	ext := strings.ToLower(filepath.Ext(filename))
@@ -133,10 +140,20 @@ func servePackageFile(ctx *context.Context, params parameters) {
 		filename = filename[:len(filename)-len(ext)]
 	}
 
-	pf, err := packages_model.GetFileForVersionByName(ctx, pv.ID, filename, packages_model.EmptyFileKey)
+	pv, pf, err := getPackageFile(ctx, packageName, params.Version, filename)
+	if err == packages_model.ErrPackageNotExist || err == packages_model.ErrPackageFileNotExist {
+		if err = cacheUpstreamPackageFile(ctx, params, filename); err == nil {
+			pv, pf, err = getPackageFile(ctx, packageName, params.Version, filename)
+		}
+	}
 	if err != nil {
-		if err == packages_model.ErrPackageFileNotExist {
+		if err == packages_model.ErrPackageNotExist || err == packages_model.ErrPackageFileNotExist || err == proxy_service.ErrUpstreamNotExist {
 			apiError(ctx, http.StatusNotFound, err)
+		} else if err == proxy_service.ErrUpstreamTooLarge {
+			apiError(ctx, http.StatusBadGateway, err)
+		} else if quota_model.IsErrQuotaExceeded(err) {
+			// the upstream file can't be cached
+			apiError(ctx, http.StatusInsufficientStorage, err)
 		} else {
 			apiError(ctx, http.StatusInternalServerError, err)
 		}
@@ -262,16 +279,38 @@ func UploadPackageFile(ctx *context.Context) {
 		return
 	}
 
+	if err := addPackageFile(ctx, pvci, params.Filename, buf); err != nil {
+		if err == packages_model.ErrDuplicatePackageFile {
+			apiError(ctx, http.StatusBadRequest, err)
+			return
+		}
+		if err == packages_service.ErrPackageAccessDenied {
+			apiError(ctx, http.StatusForbidden, err)
+			return
+		}
+		if quota_model.IsErrQuotaExceeded(err) {
+			apiError(ctx, http.StatusRequestEntityTooLarge, err)
+			return
+		}
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	ctx.Status(http.StatusCreated)
+}
+
+// addPackageFile adds the file to the package version. If it's the package pom file the metadata of the version gets updated.
+func addPackageFile(ctx *context.Context, pvci *packages_service.PackageCreationInfo, filename string, buf *packages_module.HashedBuffer) error {
 	pfci := &packages_service.PackageFileCreationInfo{
 		PackageFileInfo: packages_service.PackageFileInfo{
-			Filename: params.Filename,
+			Filename: filename,
 		},
 		Data:   buf,
 		IsLead: false,
 	}
 
 	// If it's the package pom file extract the metadata
-	if ext == ".pom" {
+	if filepath.Ext(filename) == ".pom" {
 		pfci.IsLead = true
 
 		var err error
@@ -280,46 +319,46 @@ func UploadPackageFile(ctx *context.Context) {
 			log.Error("Error parsing package metadata: %v", err)
 		}
 
-		if pvci.Metadata != nil {
-			pv, err := packages_model.GetVersionByNameAndVersion(ctx, pvci.Owner.ID, pvci.PackageType, pvci.Name, pvci.Version)
-			if err != nil && err != packages_model.ErrPackageNotExist {
-				apiError(ctx, http.StatusInternalServerError, err)
-				return
-			}
-			if pv != nil {
-				raw, err := json.Marshal(pvci.Metadata)
-				if err != nil {
-					apiError(ctx, http.StatusInternalServerError, err)
-					return
-				}
-				pv.MetadataJSON = string(raw)
-				if err := packages_model.UpdateVersion(ctx, pv); err != nil {
-					apiError(ctx, http.StatusInternalServerError, err)
-					return
-				}
-			}
-		}
-
 		if _, err := buf.Seek(0, io.SeekStart); err != nil {
-			apiError(ctx, http.StatusInternalServerError, err)
-			return
+			return err
 		}
 	}
 
-	_, _, err = packages_service.CreatePackageOrAddFileToExisting(
+	pv, _, err := packages_service.CreatePackageOrAddFileToExisting(
 		pvci,
 		pfci,
 	)
 	if err != nil {
-		if err == packages_model.ErrDuplicatePackageFile {
-			apiError(ctx, http.StatusBadRequest, err)
-			return
+		return err
+	}
+
+	// Update the metadata of an existing version after the permission checks of the service passed
+	if pfci.IsLead && pvci.Metadata != nil {
+		raw, err := json.Marshal(pvci.Metadata)
+		if err != nil {
+			return err
+		}
+		if pv.MetadataJSON != string(raw) {
+			pv.MetadataJSON = string(raw)
+			if err := packages_model.UpdateVersion(ctx, pv); err != nil {
+				return err
+			}
 		}
-		apiError(ctx, http.StatusInternalServerError, err)
-		return
 	}
+	return nil
+}
 
-	ctx.Status(http.StatusCreated)
+func getPackageFile(ctx *context.Context, packageName, packageVersion, filename string) (*packages_model.PackageVersion, *packages_model.PackageFile, error) {
+	pv, err := packages_model.GetVersionByNameAndVersion(ctx, ctx.Package.Owner.ID, packages_model.TypeMaven, packageName, packageVersion)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	pf, err := packages_model.GetFileForVersionByName(ctx, pv.ID, filename, packages_model.EmptyFileKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	return pv, pf, nil
 }
 
 func isChecksumExtension(ext string) bool {
@@ -0,0 +1,109 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package maven
+
+import (
+	"encoding/xml"
+	"strings"
+
+	packages_model "code.gitea.io/gitea/models/packages"
+	"code.gitea.io/gitea/modules/context"
+	packages_module "code.gitea.io/gitea/modules/packages"
+	packages_service "code.gitea.io/gitea/services/packages"
+	proxy_service "code.gitea.io/gitea/services/packages/proxy"
+)
+
+// upstreamPath creates the path segments of a file in a Maven repository layout
+func upstreamPath(params parameters, segments ...string) []string {
+	path := strings.Split(params.GroupID, ".")
+	path = append(path, params.ArtifactID)
+	return append(path, segments...)
+}
+
+// fetchUpstreamMetadata fetches the package metadata from the upstream registry of the owner
+// nil is returned if the owner has not configured an upstream registry.
+func fetchUpstreamMetadata(ctx *context.Context, params parameters) (*MetadataResponse, error) {
+	upstreamURL, err := proxy_service.GetUpstreamURL(ctx.Package.Owner.ID, packages_model.TypeMaven)
+	if err != nil || upstreamURL == "" {
+		return nil, err
+	}
+
+	r, err := proxy_service.Fetch(ctx, proxy_service.JoinURL(upstreamURL, upstreamPath(params, mavenMetadataFile)...))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	var metadata *MetadataResponse
+	if err := xml.NewDecoder(r).Decode(&metadata); err != nil {
+		return nil, err
+	}
+	return metadata, nil
+}
+
+// mergeUpstreamMetadata adds the versions of the upstream registry which are not available locally
+func mergeUpstreamMetadata(local, upstream *MetadataResponse) *MetadataResponse {
+	if local == nil {
+		return upstream
+	}
+
+	versions := make(map[string]bool)
+	for _, v := range upstream.Version {
+		versions[v] = true
+	}
+	for _, v := range local.Version {
+		if !versions[v] {
+			upstream.Version = append(upstream.Version, v)
+		}
+	}
+
+	if upstream.Latest == "" {
+		upstream.Latest = local.Latest
+	}
+	if upstream.Release == "" {
+		upstream.Release = local.Release
+	}
+	return upstream
+}
+
+// cacheUpstreamPackageFile fetches the package file from the upstream registry and stores it
+// ErrPackageNotExist is returned if the owner has not configured an upstream registry.
+func cacheUpstreamPackageFile(ctx *context.Context, params parameters, filename string) error {
+	upstreamURL, err := proxy_service.GetUpstreamURL(ctx.Package.Owner.ID, packages_model.TypeMaven)
+	if err != nil {
+		return err
+	}
+	if upstreamURL == "" {
+		return packages_model.ErrPackageNotExist
+	}
+
+	r, err := proxy_service.Fetch(ctx, proxy_service.JoinURL(upstreamURL, upstreamPath(params, params.Version, filename)...))
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	buf, err := packages_module.CreateHashedBufferFromReader(r, 32*1024*1024)
+	if err != nil {
+		return err
+	}
+	defer buf.Close()
+
+	pvci := &packages_service.PackageCreationInfo{
+		PackageInfo: packages_service.PackageInfo{
+			Owner:       ctx.Package.Owner,
+			PackageType: packages_model.TypeMaven,
+			Name:        params.GroupID + "-" + params.ArtifactID,
+			Version:     params.Version,
+		},
+		SemverCompatible: false,
+		Creator:          ctx.Package.Owner,
+	}
+
+	if err := addPackageFile(ctx, pvci, filename, buf); err != nil && err != packages_model.ErrDuplicatePackageFile {
+		return err
+	}
+	return nil
+}
@@ -14,12 +14,15 @@ import (
 
 	"code.gitea.io/gitea/models/db"
 	packages_model "code.gitea.io/gitea/models/packages"
+	quota_model "code.gitea.io/gitea/models/quota"
 	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/log"
 	packages_module "code.gitea.io/gitea/modules/packages"
 	npm_module "code.gitea.io/gitea/modules/packages/npm"
 	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/routers/api/packages/helper"
 	packages_service "code.gitea.io/gitea/services/packages"
+	proxy_service "code.gitea.io/gitea/services/packages/proxy"
 
 	"github.com/hashicorp/go-version"
 )
@@ -55,21 +58,33 @@ func PackageMetadata(ctx *context.Context) {
 		apiError(ctx, http.StatusInternalServerError, err)
 		return
 	}
-	if len(pvs) == 0 {
-		apiError(ctx, http.StatusNotFound, err)
-		return
+
+	// the local versions are served if the upstream registry is not available
+	upstream, err := fetchUpstreamPackageMetadata(ctx, packageName)
+	if err != nil && err != proxy_service.ErrUpstreamNotExist {
+		log.Warn("Error fetching upstream metadata of npm package %s: %v", packageName, err)
 	}
 
-	pds, err := packages_model.GetPackageDescriptors(ctx, pvs)
-	if err != nil {
-		apiError(ctx, http.StatusInternalServerError, err)
+	if len(pvs) == 0 && upstream == nil {
+		apiError(ctx, http.StatusNotFound, packages_model.ErrPackageNotExist)
 		return
 	}
 
-	resp := createPackageMetadataResponse(
-		setting.AppURL+"api/packages/"+ctx.Package.Owner.Name+"/npm",
-		pds,
-	)
+	registryURL := setting.AppURL + "api/packages/" + ctx.Package.Owner.Name + "/npm"
+
+	var resp *npm_module.PackageMetadata
+	if len(pvs) != 0 {
+		pds, err := packages_model.GetPackageDescriptors(ctx, pvs)
+		if err != nil {
+			apiError(ctx, http.StatusInternalServerError, err)
+			return
+		}
+
+		resp = createPackageMetadataResponse(registryURL, pds)
+	}
+	if upstream != nil {
+		resp = mergeUpstreamPackageMetadata(registryURL, resp, upstream)
+	}
 
 	ctx.JSON(http.StatusOK, resp)
 }
@@ -80,23 +95,36 @@ func DownloadPackageFile(ctx *context.Context) {
 	packageVersion := ctx.Params("version")
 	filename := ctx.Params("filename")
 
-	s, pf, err := packages_service.GetFileStreamByPackageNameAndVersion(
-		ctx,
-		&packages_service.PackageInfo{
-			Owner:       ctx.Package.Owner,
-			PackageType: packages_model.TypeNpm,
-			Name:        packageName,
-			Version:     packageVersion,
-		},
-		&packages_service.PackageFileInfo{
-			Filename: filename,
-		},
-	)
+	pvi := &packages_service.PackageInfo{
+		Owner:       ctx.Package.Owner,
+		PackageType: packages_model.TypeNpm,
+		Name:        packageName,
+		Version:     packageVersion,
+	}
+	pfi := &packages_service.PackageFileInfo{
+		Filename: filename,
+	}
+
+	s, pf, err := packages_service.GetFileStreamByPackageNameAndVersion(ctx, pvi, pfi)
+	if err == packages_model.ErrPackageNotExist || err == packages_model.ErrPackageFileNotExist {
+		if err = cacheUpstreamPackage(ctx, packageName, packageVersion, filename); err == nil {
+			s, pf, err = packages_service.GetFileStreamByPackageNameAndVersion(ctx, pvi, pfi)
+		}
+	}
 	if err != nil {
-		if err == packages_model.ErrPackageNotExist || err == packages_model.ErrPackageFileNotExist {
+		if err == packages_model.ErrPackageNotExist || err == packages_model.ErrPackageFileNotExist || err == proxy_service.ErrUpstreamNotExist {
 			apiError(ctx, http.StatusNotFound, err)
 			return
 		}
+		if err == proxy_service.ErrUpstreamTooLarge {
+			apiError(ctx, http.StatusBadGateway, err)
+			return
+		}
+		if quota_model.IsErrQuotaExceeded(err) {
+			// the upstream file can't be cached
+			apiError(ctx, http.StatusInsufficientStorage, err)
+			return
+		}
 		apiError(ctx, http.StatusInternalServerError, err)
 		return
 	}
@@ -145,6 +173,14 @@ func UploadPackage(ctx *context.Context) {
 			apiError(ctx, http.StatusBadRequest, err)
 			return
 		}
+		if err == packages_service.ErrPackageAccessDenied {
+			apiError(ctx, http.StatusForbidden, err)
+			return
+		}
+		if quota_model.IsErrQuotaExceeded(err) {
+			apiError(ctx, http.StatusRequestEntityTooLarge, err)
+			return
+		}
 		apiError(ctx, http.StatusInternalServerError, err)
 		return
 	}
@@ -0,0 +1,161 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package npm
+
+import (
+	"fmt"
+	"net/url"
+
+	packages_model "code.gitea.io/gitea/models/packages"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/json"
+	packages_module "code.gitea.io/gitea/modules/packages"
+	npm_module "code.gitea.io/gitea/modules/packages/npm"
+	packages_service "code.gitea.io/gitea/services/packages"
+	proxy_service "code.gitea.io/gitea/services/packages/proxy"
+)
+
+// fetchUpstreamPackageMetadata fetches the package metadata from the upstream registry of the owner
+// nil is returned if the owner has not configured an upstream registry.
+func fetchUpstreamPackageMetadata(ctx *context.Context, packageName string) (*npm_module.PackageMetadata, error) {
+	upstreamURL, err := proxy_service.GetUpstreamURL(ctx.Package.Owner.ID, packages_model.TypeNpm)
+	if err != nil || upstreamURL == "" {
+		return nil, err
+	}
+
+	r, err := proxy_service.Fetch(ctx, proxy_service.JoinURL(upstreamURL, packageName))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	var metadata *npm_module.PackageMetadata
+	if err := json.NewDecoder(r).Decode(&metadata); err != nil {
+		return nil, err
+	}
+	return metadata, nil
+}
+
+// mergeUpstreamPackageMetadata adds the versions of the upstream registry which are not available locally
+// The tarball urls of the upstream versions point to this registry which fetches the files on demand.
+func mergeUpstreamPackageMetadata(registryURL string, local, upstream *npm_module.PackageMetadata) *npm_module.PackageMetadata {
+	if local == nil {
+		local = &npm_module.PackageMetadata{
+			ID:          upstream.Name,
+			Name:        upstream.Name,
+			Description: upstream.Description,
+			Readme:      upstream.Readme,
+			Homepage:    upstream.Homepage,
+			Author:      upstream.Author,
+			License:     upstream.License,
+			DistTags:    make(map[string]string),
+			Versions:    make(map[string]*npm_module.PackageMetadataVersion),
+		}
+	}
+
+	for v, pmv := range upstream.Versions {
+		if _, exists := local.Versions[v]; exists {
+			continue
+		}
+
+		p, err := npm_module.PackageFromVersionMetadata(pmv)
+		if err != nil {
+			continue
+		}
+
+		pmv.Dist.Tarball = fmt.Sprintf("%s/%s/-/%s/%s", registryURL, url.QueryEscape(p.Name), url.PathEscape(p.Version), url.PathEscape(p.Filename))
+		local.Versions[v] = pmv
+	}
+
+	for tag, v := range upstream.DistTags {
+		if _, exists := local.DistTags[tag]; !exists {
+			local.DistTags[tag] = v
+		}
+	}
+
+	return local
+}
+
+// cacheUpstreamPackage fetches the package version from the upstream registry and stores it
+// ErrPackageNotExist is returned if the owner has not configured an upstream registry.
+func cacheUpstreamPackage(ctx *context.Context, packageName, packageVersion, filename string) error {
+	upstream, err := fetchUpstreamPackageMetadata(ctx, packageName)
+	if err != nil {
+		return err
+	}
+	if upstream == nil {
+		return packages_model.ErrPackageNotExist
+	}
+
+	pmv, ok := upstream.Versions[packageVersion]
+	if !ok {
+		return packages_model.ErrPackageNotExist
+	}
+
+	npmPackage, err := npm_module.PackageFromVersionMetadata(pmv)
+	if err != nil {
+		return err
+	}
+	if npmPackage.Filename != filename {
+		return packages_model.ErrPackageFileNotExist
+	}
+
+	r, err := proxy_service.Fetch(ctx, pmv.Dist.Tarball)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	buf, err := packages_module.CreateHashedBufferFromReader(r, 32*1024*1024)
+	if err != nil {
+		return err
+	}
+	defer buf.Close()
+
+	_, hashSHA1, _, hashSHA512 := buf.Sums()
+	if !npm_module.ValidateIntegritySums(pmv.Dist.Integrity, hashSHA1, hashSHA512) {
+		return npm_module.ErrInvalidIntegrity
+	}
+
+	pv, _, err := packages_service.CreatePackageAndAddFile(
+		&packages_service.PackageCreationInfo{
+			PackageInfo: packages_service.PackageInfo{
+				Owner:       ctx.Package.Owner,
+				PackageType: packages_model.TypeNpm,
+				Name:        npmPackage.Name,
+				Version:     npmPackage.Version,
+			},
+			SemverCompatible: true,
+			Creator:          ctx.Package.Owner,
+			Metadata:         npmPackage.Metadata,
+		},
+		&packages_service.PackageFileCreationInfo{
+			PackageFileInfo: packages_service.PackageFileInfo{
+				Filename: npmPackage.Filename,
+			},
+			Data:   buf,
+			IsLead: true,
+		},
+	)
+	if err != nil {
+		if err == packages_model.ErrDuplicatePackageVersion {
+			// the version was cached by a concurrent request
+			return nil
+		}
+		return err
+	}
+
+	// keep the upstream tags so the package can be installed if the upstream registry is not available
+	for tag, v := range upstream.DistTags {
+		if v != packageVersion {
+			continue
+		}
+		if err := setPackageTag(tag, pv, false); err != nil && err != errInvalidTagName {
+			return err
+		}
+	}
+
+	return nil
+}
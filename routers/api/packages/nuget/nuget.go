@@ -13,6 +13,7 @@ import (
 
 	"code.gitea.io/gitea/models/db"
 	packages_model "code.gitea.io/gitea/models/packages"
+	quota_model "code.gitea.io/gitea/models/quota"
 	"code.gitea.io/gitea/modules/context"
 	packages_module "code.gitea.io/gitea/modules/packages"
 	nuget_module "code.gitea.io/gitea/modules/packages/nuget"
@@ -218,6 +219,14 @@ func UploadPackage(ctx *context.Context) {
 			apiError(ctx, http.StatusBadRequest, err)
 			return
 		}
+		if err == packages_service.ErrPackageAccessDenied {
+			apiError(ctx, http.StatusForbidden, err)
+			return
+		}
+		if quota_model.IsErrQuotaExceeded(err) {
+			apiError(ctx, http.StatusRequestEntityTooLarge, err)
+			return
+		}
 		apiError(ctx, http.StatusInternalServerError, err)
 		return
 	}
@@ -258,6 +267,7 @@ func UploadSymbolPackage(ctx *context.Context) {
 	}
 
 	_, _, err = packages_service.AddFileToExistingPackage(
+		ctx.Doer,
 		pi,
 		&packages_service.PackageFileCreationInfo{
 			PackageFileInfo: packages_service.PackageFileInfo{
@@ -268,11 +278,17 @@ func UploadSymbolPackage(ctx *context.Context) {
 		},
 	)
 	if err != nil {
+		if quota_model.IsErrQuotaExceeded(err) {
+			apiError(ctx, http.StatusRequestEntityTooLarge, err)
+			return
+		}
 		switch err {
 		case packages_model.ErrPackageNotExist:
 			apiError(ctx, http.StatusNotFound, err)
 		case packages_model.ErrDuplicatePackageFile:
 			apiError(ctx, http.StatusBadRequest, err)
+		case packages_service.ErrPackageAccessDenied:
+			apiError(ctx, http.StatusForbidden, err)
 		default:
 			apiError(ctx, http.StatusInternalServerError, err)
 		}
@@ -281,6 +297,7 @@ func UploadSymbolPackage(ctx *context.Context) {
 
 	for _, pdb := range pdbs {
 		_, _, err := packages_service.AddFileToExistingPackage(
+			ctx.Doer,
 			pi,
 			&packages_service.PackageFileCreationInfo{
 				PackageFileInfo: packages_service.PackageFileInfo{
@@ -295,9 +312,15 @@ func UploadSymbolPackage(ctx *context.Context) {
 			},
 		)
 		if err != nil {
+			if quota_model.IsErrQuotaExceeded(err) {
+				apiError(ctx, http.StatusRequestEntityTooLarge, err)
+				return
+			}
 			switch err {
 			case packages_model.ErrDuplicatePackageFile:
 				apiError(ctx, http.StatusBadRequest, err)
+			case packages_service.ErrPackageAccessDenied:
+				apiError(ctx, http.StatusForbidden, err)
 			default:
 				apiError(ctx, http.StatusInternalServerError, err)
 			}
@@ -410,6 +433,10 @@ func DeletePackage(ctx *context.Context) {
 			apiError(ctx, http.StatusNotFound, err)
 			return
 		}
+		if err == packages_service.ErrPackageAccessDenied {
+			apiError(ctx, http.StatusForbidden, err)
+			return
+		}
 		apiError(ctx, http.StatusInternalServerError, err)
 	}
 }
@@ -0,0 +1,207 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package pypi
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"net/url"
+	"regexp"
+	"strings"
+
+	packages_model "code.gitea.io/gitea/models/packages"
+	"code.gitea.io/gitea/modules/context"
+	packages_module "code.gitea.io/gitea/modules/packages"
+	pypi_module "code.gitea.io/gitea/modules/packages/pypi"
+	packages_service "code.gitea.io/gitea/services/packages"
+	proxy_service "code.gitea.io/gitea/services/packages/proxy"
+)
+
+var (
+	linkPattern      = regexp.MustCompile(`(?is)<a\s([^>]*)>([^<]*)</a>`)
+	hrefPattern      = regexp.MustCompile(`(?i)href\s*=\s*"([^"]*)"`)
+	requiresPattern  = regexp.MustCompile(`(?i)data-requires-python\s*=\s*"([^"]*)"`)
+	sdistExtensions  = []string{".tar.gz", ".tar.bz2", ".zip"}
+	wheelExtension   = ".whl"
+	sha256FragmentID = "sha256="
+)
+
+// upstreamFile is a file listed on the simple index page of the upstream registry
+type upstreamFile struct {
+	Filename       string
+	Version        string
+	URL            string
+	SHA256         string
+	RequiresPython string
+}
+
+// fetchUpstreamFiles fetches the files of the package from the simple index of the upstream registry
+// nil is returned if the owner has not configured an upstream registry.
+// https://peps.python.org/pep-0503/
+func fetchUpstreamFiles(ctx *context.Context, packageName string) ([]*upstreamFile, error) {
+	upstreamURL, err := proxy_service.GetUpstreamURL(ctx.Package.Owner.ID, packages_model.TypePyPI)
+	if err != nil || upstreamURL == "" {
+		return nil, err
+	}
+
+	pageURL := proxy_service.JoinURL(upstreamURL, "simple", packageName) + "/"
+
+	r, err := proxy_service.Fetch(ctx, pageURL)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	page, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	base, err := url.Parse(pageURL)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseSimpleIndex(base, packageName, string(page)), nil
+}
+
+// parseSimpleIndex extracts the files of the package from the anchors of a simple index page
+func parseSimpleIndex(base *url.URL, packageName, page string) []*upstreamFile {
+	files := make([]*upstreamFile, 0, 10)
+	for _, link := range linkPattern.FindAllStringSubmatch(page, -1) {
+		href := hrefPattern.FindStringSubmatch(link[1])
+		if href == nil {
+			continue
+		}
+
+		fileURL, err := base.Parse(html.UnescapeString(href[1]))
+		if err != nil {
+			continue
+		}
+
+		filename := strings.TrimSpace(html.UnescapeString(link[2]))
+		version := versionFromFilename(packageName, filename)
+		if version == "" {
+			continue
+		}
+
+		f := &upstreamFile{
+			Filename: filename,
+			Version:  version,
+		}
+		if strings.HasPrefix(fileURL.Fragment, sha256FragmentID) {
+			f.SHA256 = strings.ToLower(strings.TrimPrefix(fileURL.Fragment, sha256FragmentID))
+		}
+		fileURL.Fragment = ""
+		f.URL = fileURL.String()
+
+		if requires := requiresPattern.FindStringSubmatch(link[1]); requires != nil {
+			f.RequiresPython = html.UnescapeString(requires[1])
+		}
+
+		files = append(files, f)
+	}
+	return files
+}
+
+// versionFromFilename extracts the version from a wheel or source distribution filename
+// An empty string is returned if the file does not belong to the package.
+func versionFromFilename(packageName, filename string) string {
+	lower := strings.ToLower(filename)
+
+	var version string
+	if strings.HasSuffix(lower, wheelExtension) {
+		// {distribution}-{version}(-{build tag})?-{python tag}-{abi tag}-{platform tag}.whl
+		parts := strings.Split(filename, "-")
+		if len(parts) < 5 || normalizer.Replace(strings.ToLower(parts[0])) != packageName {
+			return ""
+		}
+		version = parts[1]
+	} else {
+		for _, ext := range sdistExtensions {
+			if !strings.HasSuffix(lower, ext) {
+				continue
+			}
+			// {name}-{version}.tar.gz
+			base := filename[:len(filename)-len(ext)]
+			if len(base) <= len(packageName)+1 || base[len(packageName)] != '-' || normalizer.Replace(strings.ToLower(base[:len(packageName)])) != packageName {
+				return ""
+			}
+			version = base[len(packageName)+1:]
+			break
+		}
+	}
+
+	if !versionMatcher.MatchString(version) {
+		return ""
+	}
+	return version
+}
+
+// cacheUpstreamPackageFile fetches the package file from the upstream registry and stores it
+// ErrPackageNotExist is returned if the owner has not configured an upstream registry.
+func cacheUpstreamPackageFile(ctx *context.Context, packageName, packageVersion, filename string) error {
+	files, err := fetchUpstreamFiles(ctx, packageName)
+	if err != nil {
+		return err
+	}
+
+	var file *upstreamFile
+	for _, f := range files {
+		if f.Filename == filename && f.Version == packageVersion {
+			file = f
+			break
+		}
+	}
+	if file == nil {
+		return packages_model.ErrPackageFileNotExist
+	}
+
+	r, err := proxy_service.Fetch(ctx, file.URL)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	buf, err := packages_module.CreateHashedBufferFromReader(r, 32*1024*1024)
+	if err != nil {
+		return err
+	}
+	defer buf.Close()
+
+	_, _, hashSHA256, _ := buf.Sums()
+
+	if file.SHA256 != "" && file.SHA256 != fmt.Sprintf("%x", hashSHA256) {
+		return fmt.Errorf("hash mismatch of upstream file %s", file.URL)
+	}
+
+	_, _, err = packages_service.CreatePackageOrAddFileToExisting(
+		&packages_service.PackageCreationInfo{
+			PackageInfo: packages_service.PackageInfo{
+				Owner:       ctx.Package.Owner,
+				PackageType: packages_model.TypePyPI,
+				Name:        packageName,
+				Version:     packageVersion,
+			},
+			SemverCompatible: true,
+			Creator:          ctx.Package.Owner,
+			Metadata: &pypi_module.Metadata{
+				RequiresPython: file.RequiresPython,
+			},
+		},
+		&packages_service.PackageFileCreationInfo{
+			PackageFileInfo: packages_service.PackageFileInfo{
+				Filename: filename,
+			},
+			Data:   buf,
+			IsLead: true,
+		},
+	)
+	if err != nil && err != packages_model.ErrDuplicatePackageFile {
+		return err
+	}
+	return nil
+}
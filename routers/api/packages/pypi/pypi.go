@@ -12,7 +12,9 @@ import (
 	"strings"
 
 	packages_model "code.gitea.io/gitea/models/packages"
+	quota_model "code.gitea.io/gitea/models/quota"
 	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/log"
 	packages_module "code.gitea.io/gitea/modules/packages"
 	pypi_module "code.gitea.io/gitea/modules/packages/pypi"
 	"code.gitea.io/gitea/modules/setting"
@@ -20,6 +22,7 @@ import (
 	"code.gitea.io/gitea/modules/validation"
 	"code.gitea.io/gitea/routers/api/packages/helper"
 	packages_service "code.gitea.io/gitea/services/packages"
+	proxy_service "code.gitea.io/gitea/services/packages/proxy"
 )
 
 // https://www.python.org/dev/peps/pep-0503/#normalized-names
@@ -44,8 +47,15 @@ func PackageMetadata(ctx *context.Context) {
 		apiError(ctx, http.StatusInternalServerError, err)
 		return
 	}
-	if len(pvs) == 0 {
-		apiError(ctx, http.StatusNotFound, err)
+
+	// the local files are served if the upstream registry is not available
+	upstreamFiles, err := fetchUpstreamFiles(ctx, packageName)
+	if err != nil && err != proxy_service.ErrUpstreamNotExist {
+		log.Warn("Error fetching upstream files of PyPI package %s: %v", packageName, err)
+	}
+
+	if len(pvs) == 0 && len(upstreamFiles) == 0 {
+		apiError(ctx, http.StatusNotFound, packages_model.ErrPackageNotExist)
 		return
 	}
 
@@ -55,9 +65,23 @@ func PackageMetadata(ctx *context.Context) {
 		return
 	}
 
+	localFiles := make(map[string]bool)
+	for _, pd := range pds {
+		for _, pfd := range pd.Files {
+			localFiles[pfd.File.Name] = true
+		}
+	}
+	missingFiles := make([]*upstreamFile, 0, len(upstreamFiles))
+	for _, f := range upstreamFiles {
+		if !localFiles[f.Filename] {
+			missingFiles = append(missingFiles, f)
+		}
+	}
+
 	ctx.Data["RegistryURL"] = setting.AppURL + "api/packages/" + ctx.Package.Owner.Name + "/pypi"
-	ctx.Data["PackageDescriptor"] = pds[0]
+	ctx.Data["PackageName"] = packageName
 	ctx.Data["PackageDescriptors"] = pds
+	ctx.Data["UpstreamFiles"] = missingFiles
 	ctx.Render = templates.HTMLRenderer()
 	ctx.HTML(http.StatusOK, "api/packages/pypi/simple")
 }
@@ -68,23 +92,36 @@ func DownloadPackageFile(ctx *context.Context) {
 	packageVersion := ctx.Params("version")
 	filename := ctx.Params("filename")
 
-	s, pf, err := packages_service.GetFileStreamByPackageNameAndVersion(
-		ctx,
-		&packages_service.PackageInfo{
-			Owner:       ctx.Package.Owner,
-			PackageType: packages_model.TypePyPI,
-			Name:        packageName,
-			Version:     packageVersion,
-		},
-		&packages_service.PackageFileInfo{
-			Filename: filename,
-		},
-	)
+	pvi := &packages_service.PackageInfo{
+		Owner:       ctx.Package.Owner,
+		PackageType: packages_model.TypePyPI,
+		Name:        packageName,
+		Version:     packageVersion,
+	}
+	pfi := &packages_service.PackageFileInfo{
+		Filename: filename,
+	}
+
+	s, pf, err := packages_service.GetFileStreamByPackageNameAndVersion(ctx, pvi, pfi)
+	if err == packages_model.ErrPackageNotExist || err == packages_model.ErrPackageFileNotExist {
+		if err = cacheUpstreamPackageFile(ctx, packageName, packageVersion, filename); err == nil {
+			s, pf, err = packages_service.GetFileStreamByPackageNameAndVersion(ctx, pvi, pfi)
+		}
+	}
 	if err != nil {
-		if err == packages_model.ErrPackageNotExist || err == packages_model.ErrPackageFileNotExist {
+		if err == packages_model.ErrPackageNotExist || err == packages_model.ErrPackageFileNotExist || err == proxy_service.ErrUpstreamNotExist {
 			apiError(ctx, http.StatusNotFound, err)
 			return
 		}
+		if err == proxy_service.ErrUpstreamTooLarge {
+			apiError(ctx, http.StatusBadGateway, err)
+			return
+		}
+		if quota_model.IsErrQuotaExceeded(err) {
+			// the upstream file can't be cached
+			apiError(ctx, http.StatusInsufficientStorage, err)
+			return
+		}
 		apiError(ctx, http.StatusInternalServerError, err)
 		return
 	}
@@ -166,6 +203,14 @@ func UploadPackageFile(ctx *context.Context) {
 			apiError(ctx, http.StatusBadRequest, err)
 			return
 		}
+		if err == packages_service.ErrPackageAccessDenied {
+			apiError(ctx, http.StatusForbidden, err)
+			return
+		}
+		if quota_model.IsErrQuotaExceeded(err) {
+			apiError(ctx, http.StatusRequestEntityTooLarge, err)
+			return
+		}
 		apiError(ctx, http.StatusInternalServerError, err)
 		return
 	}
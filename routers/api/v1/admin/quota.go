@@ -0,0 +1,139 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package admin
+
+import (
+	"net/http"
+
+	quota_model "code.gitea.io/gitea/models/quota"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/convert"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/web"
+)
+
+// GetQuota gets the storage quota of a user or organization
+func GetQuota(ctx *context.APIContext) {
+	// swagger:operation GET /admin/users/{username}/quota admin adminGetQuota
+	// ---
+	// summary: Get the storage quota and usage of a user or organization
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: username
+	//   in: path
+	//   description: name of the user or organization
+	//   type: string
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/Quota"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	q, err := quota_model.GetQuotaByOwnerID(ctx, ctx.ContextUser.ID)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetQuotaByOwnerID", err)
+		return
+	}
+
+	writeQuota(ctx, q)
+}
+
+// EditQuota edits the storage quota of a user or organization
+func EditQuota(ctx *context.APIContext) {
+	// swagger:operation PATCH /admin/users/{username}/quota admin adminEditQuota
+	// ---
+	// summary: Edit the storage quota of a user or organization
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: username
+	//   in: path
+	//   description: name of the user or organization
+	//   type: string
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/EditQuotaOption"
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/Quota"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	form := web.GetForm(ctx).(*api.EditQuotaOption)
+
+	q, err := quota_model.GetQuotaByOwnerID(ctx, ctx.ContextUser.ID)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetQuotaByOwnerID", err)
+		return
+	}
+
+	setLimit := func(limit *int64, value *int64) {
+		if value != nil {
+			*limit = *value
+			if *limit < 0 {
+				*limit = -1
+			}
+		}
+	}
+	setLimit(&q.LimitTotal, form.LimitTotal)
+	setLimit(&q.LimitGit, form.LimitGit)
+	setLimit(&q.LimitLFS, form.LimitLFS)
+	setLimit(&q.LimitPackages, form.LimitPackages)
+	setLimit(&q.LimitAttachments, form.LimitAttachments)
+
+	if err := quota_model.SetQuota(ctx, q); err != nil {
+		ctx.Error(http.StatusInternalServerError, "SetQuota", err)
+		return
+	}
+
+	writeQuota(ctx, q)
+}
+
+// DeleteQuota resets the storage quota of a user or organization to the default quota
+func DeleteQuota(ctx *context.APIContext) {
+	// swagger:operation DELETE /admin/users/{username}/quota admin adminDeleteQuota
+	// ---
+	// summary: Reset the storage quota of a user or organization to the default quota
+	// parameters:
+	// - name: username
+	//   in: path
+	//   description: name of the user or organization
+	//   type: string
+	//   required: true
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	if err := quota_model.DeleteQuotaByOwnerID(ctx, ctx.ContextUser.ID); err != nil {
+		ctx.Error(http.StatusInternalServerError, "DeleteQuotaByOwnerID", err)
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+func writeQuota(ctx *context.APIContext, q *quota_model.Quota) {
+	u, err := quota_model.GetUsageByOwnerID(ctx, ctx.ContextUser.ID)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetUsageByOwnerID", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, convert.ToQuota(q, u))
+}
@@ -0,0 +1,112 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package admin
+
+import (
+	"net/http"
+
+	actions_model "code.gitea.io/gitea/models/actions"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/convert"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/web"
+)
+
+// ListActionRunners lists all runners
+func ListActionRunners(ctx *context.APIContext) {
+	// swagger:operation GET /admin/actions/runners admin adminListActionRunners
+	// ---
+	// summary: List the runners which execute workflow jobs
+	// produces:
+	// - application/json
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/ActionRunnerList"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+
+	runners, err := actions_model.GetRunners(ctx)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetRunners", err)
+		return
+	}
+
+	apiRunners := make([]*api.ActionRunner, 0, len(runners))
+	for _, r := range runners {
+		apiRunners = append(apiRunners, convert.ToActionRunner(r))
+	}
+
+	ctx.JSON(http.StatusOK, apiRunners)
+}
+
+// CreateActionRunner registers a runner
+func CreateActionRunner(ctx *context.APIContext) {
+	// swagger:operation POST /admin/actions/runners admin adminCreateActionRunner
+	// ---
+	// summary: Register a runner. The returned token is only shown once.
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/CreateActionRunnerOption"
+	// responses:
+	//   "201":
+	//     "$ref": "#/responses/ActionRunner"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	form := web.GetForm(ctx).(*api.CreateActionRunnerOption)
+
+	r := &actions_model.ActionRunner{
+		Name:   form.Name,
+		Labels: form.Labels,
+	}
+	if err := actions_model.CreateRunner(ctx, r); err != nil {
+		ctx.Error(http.StatusInternalServerError, "CreateRunner", err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, convert.ToActionRunner(r))
+}
+
+// DeleteActionRunner deletes a runner
+func DeleteActionRunner(ctx *context.APIContext) {
+	// swagger:operation DELETE /admin/actions/runners/{id} admin adminDeleteActionRunner
+	// ---
+	// summary: Delete a runner. Jobs which are executed by the runner are queued again.
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: id
+	//   in: path
+	//   description: id of the runner to delete
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	if err := actions_model.DeleteRunnerByID(ctx, ctx.ParamsInt64(":id")); err != nil {
+		if err == actions_model.ErrRunnerNotExist {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "DeleteRunnerByID", err)
+		}
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
@@ -86,6 +86,7 @@ import (
 	"code.gitea.io/gitea/routers/api/v1/notify"
 	"code.gitea.io/gitea/routers/api/v1/org"
 	"code.gitea.io/gitea/routers/api/v1/packages"
+	"code.gitea.io/gitea/routers/api/v1/project"
 	"code.gitea.io/gitea/routers/api/v1/repo"
 	"code.gitea.io/gitea/routers/api/v1/settings"
 	"code.gitea.io/gitea/routers/api/v1/user"
@@ -205,6 +206,21 @@ func reqPackageAccess(accessMode perm.AccessMode) func(ctx *context.APIContext)
 	}
 }
 
+// reqProjectAccess user should have a specific permission to the project or its owner, or be a site admin.
+// Projects of archived repositories cannot be changed.
+func reqProjectAccess(accessMode perm.AccessMode) func(ctx *context.APIContext) {
+	return func(ctx *context.APIContext) {
+		if ctx.Project.AccessMode < accessMode {
+			ctx.Error(http.StatusForbidden, "reqProjectAccess", "user should have specific permission to the project or be a site admin")
+			return
+		}
+		if accessMode >= perm.AccessModeWrite && ctx.Project.Project != nil && ctx.Project.Project.Repo != nil && ctx.Project.Project.Repo.IsArchived {
+			ctx.Error(http.StatusForbidden, "reqProjectAccess", "projects of archived repositories cannot be changed")
+			return
+		}
+	}
+}
+
 // Contexter middleware already checks token for user sign in process.
 func reqToken() func(ctx *context.APIContext) {
 	return func(ctx *context.APIContext) {
@@ -676,6 +692,7 @@ func Routes() *web.Route {
 				}
 
 				m.Get("/repos", reqExploreSignIn(), user.ListUserRepos)
+				m.Get("/projects", context.ProjectAssignmentAPI(), project.ListUserProjects)
 				m.Group("/tokens", func() {
 					m.Combo("").Get(user.ListAccessTokens).
 						Post(bind(api.CreateAccessTokenOption{}), user.CreateAccessToken)
@@ -765,6 +782,8 @@ func Routes() *web.Route {
 			m.Get("/subscriptions", user.GetMyWatchedRepos)
 
 			m.Get("/teams", org.ListUserTeams)
+
+			m.Post("/projects", bind(api.CreateProjectOption{}), project.CreateUserProject)
 		}, reqToken())
 
 		// Repositories
@@ -903,6 +922,7 @@ func Routes() *web.Route {
 								Delete(reqToken(), repo.ClearIssueLabels)
 							m.Delete("/{id}", reqToken(), repo.DeleteIssueLabel)
 						})
+						m.Get("/custom_fields", repo.ListIssueCustomFields)
 						m.Group("/times", func() {
 							m.Combo("").
 								Get(repo.ListTrackedTimes).
@@ -935,8 +955,19 @@ func Routes() *web.Route {
 						Patch(reqToken(), reqRepoWriter(unit.TypeIssues, unit.TypePullRequests), bind(api.EditLabelOption{}), repo.EditLabel).
 						Delete(reqToken(), reqRepoWriter(unit.TypeIssues, unit.TypePullRequests), repo.DeleteLabel)
 				})
+				m.Group("/custom_fields", func() {
+					m.Combo("").Get(repo.ListCustomFields).
+						Post(reqToken(), reqRepoWriter(unit.TypeIssues, unit.TypePullRequests), bind(api.CreateCustomFieldOption{}), repo.CreateCustomField)
+					m.Combo("/{id}").Get(repo.GetCustomField).
+						Patch(reqToken(), reqRepoWriter(unit.TypeIssues, unit.TypePullRequests), bind(api.EditCustomFieldOption{}), repo.EditCustomField).
+						Delete(reqToken(), reqRepoWriter(unit.TypeIssues, unit.TypePullRequests), repo.DeleteCustomField)
+				}, mustEnableIssuesOrPulls)
 				m.Post("/markdown", bind(api.MarkdownOption{}), misc.Markdown)
 				m.Post("/markdown/raw", misc.MarkdownRaw)
+				m.Group("/projects", func() {
+					m.Combo("").Get(project.ListRepoProjects).
+						Post(reqToken(), mustNotBeArchived, reqRepoWriter(unit.TypeProjects), bind(api.CreateProjectOption{}), project.CreateRepoProject)
+				}, reqRepoReader(unit.TypeProjects))
 				m.Group("/milestones", func() {
 					m.Combo("").Get(repo.ListMilestones).
 						Post(reqToken(), reqRepoWriter(unit.TypeIssues, unit.TypePullRequests), bind(api.CreateMilestoneOption{}), repo.CreateMilestone)
@@ -983,6 +1014,10 @@ func Routes() *web.Route {
 						m.Get(".{diffType:diff|patch}", repo.DownloadPullDiffOrPatch)
 						m.Post("/update", reqToken(), repo.UpdatePullRequest)
 						m.Get("/commits", repo.GetPullRequestCommits)
+						m.Group("/iterations", func() {
+							m.Get("", repo.ListPullRequestIterations)
+							m.Get("/compare", repo.ComparePullRequestIterations)
+						})
 						m.Combo("/merge").Get(repo.IsPullRequestMerged).
 							Post(reqToken(), mustNotBeArchived, bind(forms.MergePullRequestForm{}), repo.MergePullRequest).
 							Delete(reqToken(), mustNotBeArchived, repo.CancelScheduledAutoMerge)
@@ -1010,11 +1045,21 @@ func Routes() *web.Route {
 					m.Combo("/{sha}").Get(repo.GetCommitStatuses).
 						Post(reqToken(), bind(api.CreateStatusOption{}), repo.NewCommitStatus)
 				}, reqRepoReader(unit.TypeCode))
+				m.Post("/actions/runs/{id}/approve", reqToken(), reqRepoWriter(unit.TypeCode), repo.ApproveActionRun)
+				m.Group("/check-runs", func() {
+					m.Post("", reqToken(), reqRepoWriter(unit.TypeCode), context.ReferencesGitRepo(), bind(api.CreateCheckRunOption{}), repo.CreateCheckRun)
+					m.Group("/{id}", func() {
+						m.Combo("").Get(repo.GetCheckRun).
+							Patch(reqToken(), reqRepoWriter(unit.TypeCode), bind(api.EditCheckRunOption{}), repo.EditCheckRun)
+						m.Get("/annotations", repo.ListCheckRunAnnotations)
+					})
+				}, reqRepoReader(unit.TypeCode))
 				m.Group("/commits", func() {
 					m.Get("", context.ReferencesGitRepo(), repo.GetAllCommits)
 					m.Group("/{ref}", func() {
 						m.Get("/status", repo.GetCombinedCommitStatusByRef)
 						m.Get("/statuses", repo.GetCommitStatusesByRef)
+						m.Get("/check-runs", repo.ListCheckRunsByRef)
 					}, context.ReferencesGitRepo())
 				}, reqRepoReader(unit.TypeCode))
 				m.Group("/git", func() {
@@ -1049,6 +1094,7 @@ func Routes() *web.Route {
 					}, reqAdmin())
 				}, reqAnyRepoReader())
 				m.Get("/issue_templates", context.ReferencesGitRepo(), repo.GetIssueTemplates)
+				m.Get("/pull_request_templates", context.ReferencesGitRepo(), repo.GetPullRequestTemplates)
 				m.Get("/languages", reqRepoReader(unit.TypeCode), repo.GetLanguages)
 			}, repoAssignment())
 		})
@@ -1059,6 +1105,21 @@ func Routes() *web.Route {
 				m.Delete("", reqPackageAccess(perm.AccessModeWrite), packages.DeletePackage)
 				m.Get("/files", packages.ListPackageFiles)
 			})
+			m.Group("/proxies/{type}", func() {
+				m.Get("", packages.GetPackageProxy)
+				m.Put("", bind(api.SetPackageProxyOption{}), packages.SetPackageProxy)
+				m.Delete("", packages.DeletePackageProxy)
+			}, reqPackageAccess(perm.AccessModeAdmin))
+			m.Group("/cleanup-rules", func() {
+				m.Combo("").Get(packages.ListPackageCleanupRules).
+					Post(bind(api.CreatePackageCleanupRuleOption{}), packages.CreatePackageCleanupRule)
+				m.Group("/{id}", func() {
+					m.Combo("").Get(packages.GetPackageCleanupRule).
+						Patch(bind(api.EditPackageCleanupRuleOption{}), packages.EditPackageCleanupRule).
+						Delete(packages.DeletePackageCleanupRule)
+					m.Get("/preview", packages.PreviewPackageCleanupRule)
+				})
+			}, reqPackageAccess(perm.AccessModeAdmin))
 			m.Get("/", packages.ListPackages)
 		}, context_service.UserAssignmentAPI(), context.PackageAssignmentAPI(), reqPackageAccess(perm.AccessModeRead))
 
@@ -1099,6 +1160,17 @@ func Routes() *web.Route {
 					Patch(reqToken(), reqOrgOwnership(), bind(api.EditLabelOption{}), org.EditLabel).
 					Delete(reqToken(), reqOrgOwnership(), org.DeleteLabel)
 			})
+			m.Group("/custom_fields", func() {
+				m.Get("", org.ListCustomFields)
+				m.Post("", reqToken(), reqOrgOwnership(), bind(api.CreateCustomFieldOption{}), org.CreateCustomField)
+				m.Combo("/{id}").Get(org.GetCustomField).
+					Patch(reqToken(), reqOrgOwnership(), bind(api.EditCustomFieldOption{}), org.EditCustomField).
+					Delete(reqToken(), reqOrgOwnership(), org.DeleteCustomField)
+			})
+			m.Group("/projects", func() {
+				m.Combo("").Get(project.ListOrgProjects).
+					Post(reqToken(), reqProjectAccess(perm.AccessModeWrite), bind(api.CreateProjectOption{}), project.CreateOrgProject)
+			}, context.ProjectAssignmentAPI())
 			m.Group("/hooks", func() {
 				m.Combo("").Get(org.ListHooks).
 					Post(bind(api.CreateHookOption{}), org.CreateHook)
@@ -1127,11 +1199,37 @@ func Routes() *web.Route {
 			})
 		}, orgAssignment(false, true), reqToken(), reqTeamMembership())
 
+		m.Group("/projects/{id}", func() {
+			m.Combo("").Get(project.GetProject).
+				Patch(reqToken(), reqProjectAccess(perm.AccessModeWrite), bind(api.EditProjectOption{}), project.EditProject).
+				Delete(reqToken(), reqProjectAccess(perm.AccessModeWrite), project.DeleteProject)
+			m.Group("/boards", func() {
+				m.Combo("").Get(project.ListProjectBoards).
+					Post(reqToken(), reqProjectAccess(perm.AccessModeWrite), bind(api.CreateProjectBoardOption{}), project.CreateProjectBoard)
+				m.Group("/{boardid}", func() {
+					m.Combo("").Get(project.GetProjectBoard).
+						Patch(reqToken(), reqProjectAccess(perm.AccessModeWrite), bind(api.EditProjectBoardOption{}), project.EditProjectBoard).
+						Delete(reqToken(), reqProjectAccess(perm.AccessModeWrite), project.DeleteProjectBoard)
+					m.Combo("/issues").Get(project.ListProjectBoardIssues).
+						Put(reqToken(), reqProjectAccess(perm.AccessModeWrite), bind(api.MoveProjectIssuesOption{}), project.MoveProjectBoardIssues)
+				})
+			})
+			m.Group("/issues", func() {
+				m.Post("", bind(api.AddProjectIssueOption{}), project.AddProjectIssue)
+				m.Delete("/{issueid}", project.RemoveProjectIssue)
+			}, reqToken(), reqProjectAccess(perm.AccessModeWrite))
+		}, context.ProjectAssignmentAPI())
+
 		m.Group("/admin", func() {
 			m.Group("/cron", func() {
 				m.Get("", admin.ListCronTasks)
 				m.Post("/{task}", admin.PostCronTask)
 			})
+			m.Group("/actions/runners", func() {
+				m.Get("", admin.ListActionRunners)
+				m.Post("", bind(api.CreateActionRunnerOption{}), admin.CreateActionRunner)
+				m.Delete("/{id}", admin.DeleteActionRunner)
+			})
 			m.Get("/orgs", admin.GetAllOrgs)
 			m.Group("/users", func() {
 				m.Get("", admin.GetAllUsers)
@@ -1146,6 +1244,9 @@ func Routes() *web.Route {
 					m.Get("/orgs", org.ListUserOrgs)
 					m.Post("/orgs", bind(api.CreateOrgOption{}), admin.CreateOrg)
 					m.Post("/repos", bind(api.CreateRepoOption{}), admin.CreateRepo)
+					m.Combo("/quota").Get(admin.GetQuota).
+						Patch(bind(api.EditQuotaOption{}), admin.EditQuota).
+						Delete(admin.DeleteQuota)
 				}, context_service.UserAssignmentAPI())
 			})
 			m.Group("/unadopted", func() {
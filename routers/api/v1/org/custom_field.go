@@ -0,0 +1,238 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package org
+
+import (
+	"net/http"
+
+	issues_model "code.gitea.io/gitea/models/issues"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/convert"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/web"
+)
+
+// ListCustomFields list the custom fields of an organization
+func ListCustomFields(ctx *context.APIContext) {
+	// swagger:operation GET /orgs/{org}/custom_fields organization orgListCustomFields
+	// ---
+	// summary: List an organization's custom fields
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: org
+	//   in: path
+	//   description: name of the organization
+	//   type: string
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/CustomFieldList"
+
+	fields, err := issues_model.GetCustomFieldsByOrgID(ctx, ctx.Org.Organization.ID)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetCustomFieldsByOrgID", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, convert.ToCustomFieldList(fields))
+}
+
+// CreateCustomField create a custom field for an organization
+func CreateCustomField(ctx *context.APIContext) {
+	// swagger:operation POST /orgs/{org}/custom_fields organization orgCreateCustomField
+	// ---
+	// summary: Create a custom field for an organization
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: org
+	//   in: path
+	//   description: name of the organization
+	//   type: string
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/CreateCustomFieldOption"
+	// responses:
+	//   "201":
+	//     "$ref": "#/responses/CustomField"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	form := web.GetForm(ctx).(*api.CreateCustomFieldOption)
+	fieldType, ok := issues_model.CustomFieldTypeFromName(form.Type)
+	if !ok {
+		ctx.Error(http.StatusUnprocessableEntity, "CustomFieldTypeFromName", issues_model.ErrInvalidCustomField{Name: form.Name})
+		return
+	}
+
+	f := &issues_model.CustomField{
+		OrgID:       ctx.Org.Organization.ID,
+		Name:        form.Name,
+		Description: form.Description,
+		Type:        fieldType,
+		Options:     form.Options,
+	}
+	if err := issues_model.NewCustomField(ctx, f); err != nil {
+		if issues_model.IsErrInvalidCustomField(err) || issues_model.IsErrCustomFieldAlreadyExist(err) {
+			ctx.Error(http.StatusUnprocessableEntity, "NewCustomField", err)
+		} else {
+			ctx.Error(http.StatusInternalServerError, "NewCustomField", err)
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, convert.ToCustomField(f))
+}
+
+// GetCustomField get a custom field of an organization
+func GetCustomField(ctx *context.APIContext) {
+	// swagger:operation GET /orgs/{org}/custom_fields/{id} organization orgGetCustomField
+	// ---
+	// summary: Get a single custom field
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: org
+	//   in: path
+	//   description: name of the organization
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the custom field to get
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/CustomField"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	f := getOrgCustomField(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, convert.ToCustomField(f))
+}
+
+// EditCustomField modify a custom field of an organization
+func EditCustomField(ctx *context.APIContext) {
+	// swagger:operation PATCH /orgs/{org}/custom_fields/{id} organization orgEditCustomField
+	// ---
+	// summary: Update a custom field
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: org
+	//   in: path
+	//   description: name of the organization
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the custom field to edit
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/EditCustomFieldOption"
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/CustomField"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	form := web.GetForm(ctx).(*api.EditCustomFieldOption)
+	f := getOrgCustomField(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	if form.Name != nil {
+		f.Name = *form.Name
+	}
+	if form.Description != nil {
+		f.Description = *form.Description
+	}
+	if form.Options != nil {
+		f.Options = form.Options
+	}
+	if err := issues_model.UpdateCustomField(ctx, f); err != nil {
+		if issues_model.IsErrInvalidCustomField(err) || issues_model.IsErrCustomFieldAlreadyExist(err) {
+			ctx.Error(http.StatusUnprocessableEntity, "UpdateCustomField", err)
+		} else {
+			ctx.Error(http.StatusInternalServerError, "UpdateCustomField", err)
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, convert.ToCustomField(f))
+}
+
+// DeleteCustomField delete a custom field of an organization
+func DeleteCustomField(ctx *context.APIContext) {
+	// swagger:operation DELETE /orgs/{org}/custom_fields/{id} organization orgDeleteCustomField
+	// ---
+	// summary: Delete a custom field and its values
+	// parameters:
+	// - name: org
+	//   in: path
+	//   description: name of the organization
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the custom field to delete
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	f := getOrgCustomField(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	if err := issues_model.DeleteCustomField(ctx, f); err != nil {
+		ctx.Error(http.StatusInternalServerError, "DeleteCustomField", err)
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+func getOrgCustomField(ctx *context.APIContext) *issues_model.CustomField {
+	f, err := issues_model.GetCustomFieldByID(ctx, ctx.ParamsInt64(":id"))
+	if err != nil {
+		if issues_model.IsErrCustomFieldNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetCustomFieldByID", err)
+		}
+		return nil
+	}
+	if f.OrgID != ctx.Org.Organization.ID {
+		ctx.NotFound()
+		return nil
+	}
+	return f
+}
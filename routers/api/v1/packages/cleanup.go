@@ -0,0 +1,310 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package packages
+
+import (
+	"net/http"
+
+	"code.gitea.io/gitea/models/packages"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/convert"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/web"
+	packages_cleanup_service "code.gitea.io/gitea/services/packages/cleanup"
+)
+
+// ListPackageCleanupRules gets all cleanup rules of an owner
+func ListPackageCleanupRules(ctx *context.APIContext) {
+	// swagger:operation GET /packages/{owner}/cleanup-rules package listPackageCleanupRules
+	// ---
+	// summary: Gets all package cleanup rules of an owner
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the packages
+	//   type: string
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/PackageCleanupRuleList"
+
+	pcrs, err := packages.GetCleanupRulesByOwner(ctx, ctx.Package.Owner.ID)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetCleanupRulesByOwner", err)
+		return
+	}
+
+	apiRules := make([]*api.PackageCleanupRule, 0, len(pcrs))
+	for _, pcr := range pcrs {
+		apiRules = append(apiRules, convert.ToPackageCleanupRule(pcr))
+	}
+
+	ctx.JSON(http.StatusOK, apiRules)
+}
+
+// CreatePackageCleanupRule creates a cleanup rule
+func CreatePackageCleanupRule(ctx *context.APIContext) {
+	// swagger:operation POST /packages/{owner}/cleanup-rules package createPackageCleanupRule
+	// ---
+	// summary: Creates a package cleanup rule. Enabled rules are executed by the package cleanup cron task.
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the packages
+	//   type: string
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/CreatePackageCleanupRuleOption"
+	// responses:
+	//   "201":
+	//     "$ref": "#/responses/PackageCleanupRule"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	form := web.GetForm(ctx).(*api.CreatePackageCleanupRuleOption)
+
+	pcr := &packages.PackageCleanupRule{
+		Enabled:     form.Enabled,
+		OwnerID:     ctx.Package.Owner.ID,
+		Type:        packages.Type(form.Type),
+		NamePattern: form.NamePattern,
+		KeepCount:   form.KeepCount,
+		KeepPattern: form.KeepPattern,
+		RemoveDays:  form.RemoveDays,
+	}
+	if !validateCleanupRule(ctx, pcr) {
+		return
+	}
+
+	pcr, err := packages.InsertCleanupRule(ctx, pcr)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "InsertCleanupRule", err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, convert.ToPackageCleanupRule(pcr))
+}
+
+// GetPackageCleanupRule gets a cleanup rule
+func GetPackageCleanupRule(ctx *context.APIContext) {
+	// swagger:operation GET /packages/{owner}/cleanup-rules/{id} package getPackageCleanupRule
+	// ---
+	// summary: Gets a package cleanup rule
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the packages
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the rule
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/PackageCleanupRule"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	pcr := getCleanupRule(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, convert.ToPackageCleanupRule(pcr))
+}
+
+// EditPackageCleanupRule edits a cleanup rule
+func EditPackageCleanupRule(ctx *context.APIContext) {
+	// swagger:operation PATCH /packages/{owner}/cleanup-rules/{id} package editPackageCleanupRule
+	// ---
+	// summary: Edits a package cleanup rule
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the packages
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the rule
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/EditPackageCleanupRuleOption"
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/PackageCleanupRule"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	form := web.GetForm(ctx).(*api.EditPackageCleanupRuleOption)
+
+	pcr := getCleanupRule(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	if form.Enabled != nil {
+		pcr.Enabled = *form.Enabled
+	}
+	if form.NamePattern != nil {
+		pcr.NamePattern = *form.NamePattern
+	}
+	if form.KeepCount != nil {
+		pcr.KeepCount = *form.KeepCount
+	}
+	if form.KeepPattern != nil {
+		pcr.KeepPattern = *form.KeepPattern
+	}
+	if form.RemoveDays != nil {
+		pcr.RemoveDays = *form.RemoveDays
+	}
+	if !validateCleanupRule(ctx, pcr) {
+		return
+	}
+
+	if err := packages.UpdateCleanupRule(ctx, pcr); err != nil {
+		ctx.Error(http.StatusInternalServerError, "UpdateCleanupRule", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, convert.ToPackageCleanupRule(pcr))
+}
+
+// DeletePackageCleanupRule deletes a cleanup rule
+func DeletePackageCleanupRule(ctx *context.APIContext) {
+	// swagger:operation DELETE /packages/{owner}/cleanup-rules/{id} package deletePackageCleanupRule
+	// ---
+	// summary: Deletes a package cleanup rule
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the packages
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the rule
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	pcr := getCleanupRule(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	if err := packages.DeleteCleanupRuleByID(ctx, pcr.ID); err != nil {
+		ctx.Error(http.StatusInternalServerError, "DeleteCleanupRuleByID", err)
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// PreviewPackageCleanupRule lists the package versions which would be removed by a cleanup rule
+func PreviewPackageCleanupRule(ctx *context.APIContext) {
+	// swagger:operation GET /packages/{owner}/cleanup-rules/{id}/preview package previewPackageCleanupRule
+	// ---
+	// summary: Gets the package versions which would be removed by a package cleanup rule. Nothing is removed.
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the packages
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the rule
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/PackageList"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	pcr := getCleanupRule(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	pds, err := packages_cleanup_service.GetCleanupPreview(ctx, pcr)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetCleanupPreview", err)
+		return
+	}
+
+	apiPackages := make([]*api.Package, 0, len(pds))
+	for _, pd := range pds {
+		apiPackage, err := convert.ToPackage(ctx, pd, ctx.Doer)
+		if err != nil {
+			ctx.Error(http.StatusInternalServerError, "Error converting package for api", err)
+			return
+		}
+		apiPackages = append(apiPackages, apiPackage)
+	}
+
+	ctx.JSON(http.StatusOK, apiPackages)
+}
+
+func getCleanupRule(ctx *context.APIContext) *packages.PackageCleanupRule {
+	pcr, err := packages.GetCleanupRuleByOwnerAndID(ctx, ctx.Package.Owner.ID, ctx.ParamsInt64(":id"))
+	if err != nil {
+		if err == packages.ErrPackageCleanupRuleNotExist {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetCleanupRuleByOwnerAndID", err)
+		}
+		return nil
+	}
+	return pcr
+}
+
+func validateCleanupRule(ctx *context.APIContext, pcr *packages.PackageCleanupRule) bool {
+	if !packages.IsValidType(pcr.Type) {
+		ctx.Error(http.StatusUnprocessableEntity, "", "package type is invalid")
+		return false
+	}
+	if pcr.KeepCount < 0 || pcr.RemoveDays < 0 {
+		ctx.Error(http.StatusUnprocessableEntity, "", "keep_count and remove_days must not be negative")
+		return false
+	}
+	if err := pcr.CompilePatterns(); err != nil {
+		ctx.Error(http.StatusUnprocessableEntity, "", err)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,157 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package packages
+
+import (
+	"net/http"
+
+	"code.gitea.io/gitea/models/packages"
+	"code.gitea.io/gitea/modules/context"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/web"
+	proxy_service "code.gitea.io/gitea/services/packages/proxy"
+)
+
+// GetPackageProxy gets the upstream registry of a package type
+func GetPackageProxy(ctx *context.APIContext) {
+	// swagger:operation GET /packages/{owner}/proxies/{type} package getPackageProxy
+	// ---
+	// summary: Gets the upstream registry of a package type
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the packages
+	//   type: string
+	//   required: true
+	// - name: type
+	//   in: path
+	//   description: type of the packages
+	//   type: string
+	//   enum: [maven, npm, pypi]
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/PackageProxy"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	packageType := packages.Type(ctx.Params("type"))
+
+	upstreamURL, err := proxy_service.GetUpstreamURL(ctx.Package.Owner.ID, packageType)
+	if err != nil {
+		if err == proxy_service.ErrUnsupportedPackageType {
+			ctx.NotFound()
+			return
+		}
+		ctx.Error(http.StatusInternalServerError, "GetUpstreamURL", err)
+		return
+	}
+	if upstreamURL == "" {
+		ctx.NotFound()
+		return
+	}
+
+	ctx.JSON(http.StatusOK, &api.PackageProxy{
+		Type:        string(packageType),
+		UpstreamURL: upstreamURL,
+	})
+}
+
+// SetPackageProxy sets the upstream registry of a package type
+func SetPackageProxy(ctx *context.APIContext) {
+	// swagger:operation PUT /packages/{owner}/proxies/{type} package setPackageProxy
+	// ---
+	// summary: Sets the upstream registry of a package type. Missing packages are fetched from the upstream registry and stored.
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the packages
+	//   type: string
+	//   required: true
+	// - name: type
+	//   in: path
+	//   description: type of the packages
+	//   type: string
+	//   enum: [maven, npm, pypi]
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/SetPackageProxyOption"
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/PackageProxy"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	form := web.GetForm(ctx).(*api.SetPackageProxyOption)
+	packageType := packages.Type(ctx.Params("type"))
+
+	if err := proxy_service.SetUpstreamURL(ctx.Package.Owner.ID, packageType, form.UpstreamURL); err != nil {
+		switch err {
+		case proxy_service.ErrUnsupportedPackageType:
+			ctx.NotFound()
+		case proxy_service.ErrInvalidUpstreamURL:
+			ctx.Error(http.StatusUnprocessableEntity, "", err)
+		default:
+			ctx.Error(http.StatusInternalServerError, "SetUpstreamURL", err)
+		}
+		return
+	}
+
+	upstreamURL, err := proxy_service.GetUpstreamURL(ctx.Package.Owner.ID, packageType)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetUpstreamURL", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, &api.PackageProxy{
+		Type:        string(packageType),
+		UpstreamURL: upstreamURL,
+	})
+}
+
+// DeletePackageProxy removes the upstream registry of a package type
+func DeletePackageProxy(ctx *context.APIContext) {
+	// swagger:operation DELETE /packages/{owner}/proxies/{type} package deletePackageProxy
+	// ---
+	// summary: Removes the upstream registry of a package type. Already fetched packages are kept.
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the packages
+	//   type: string
+	//   required: true
+	// - name: type
+	//   in: path
+	//   description: type of the packages
+	//   type: string
+	//   enum: [maven, npm, pypi]
+	//   required: true
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	if err := proxy_service.RemoveUpstreamURL(ctx.Package.Owner.ID, packages.Type(ctx.Params("type"))); err != nil {
+		if err == proxy_service.ErrUnsupportedPackageType {
+			ctx.NotFound()
+			return
+		}
+		ctx.Error(http.StatusInternalServerError, "RemoveUpstreamURL", err)
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
@@ -0,0 +1,285 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package project
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strings"
+
+	project_model "code.gitea.io/gitea/models/project"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/convert"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/web"
+)
+
+// ListProjectBoards lists the boards of a project
+func ListProjectBoards(ctx *context.APIContext) {
+	// swagger:operation GET /projects/{id}/boards project projectListBoards
+	// ---
+	// summary: List the boards of a project, starting with the default board
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: id
+	//   in: path
+	//   description: id of the project
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/ProjectBoardList"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	boards, err := project_model.GetBoards(ctx.Project.Project.ID)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetBoards", err)
+		return
+	}
+
+	apiBoards := make([]*api.ProjectBoard, len(boards))
+	for i, b := range boards {
+		apiBoards[i] = convert.ToAPIProjectBoard(b)
+	}
+	ctx.JSON(http.StatusOK, apiBoards)
+}
+
+// CreateProjectBoard creates a board of a project
+func CreateProjectBoard(ctx *context.APIContext) {
+	// swagger:operation POST /projects/{id}/boards project projectCreateBoard
+	// ---
+	// summary: Create a board of a project
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: id
+	//   in: path
+	//   description: id of the project
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/CreateProjectBoardOption"
+	// responses:
+	//   "201":
+	//     "$ref": "#/responses/ProjectBoard"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	form := web.GetForm(ctx).(*api.CreateProjectBoardOption)
+
+	color, ok := normalizeBoardColor(form.Color)
+	if !ok {
+		ctx.Error(http.StatusUnprocessableEntity, "ColorPattern", fmt.Errorf("bad color code: %s", form.Color))
+		return
+	}
+
+	board := &project_model.Board{
+		ProjectID: ctx.Project.Project.ID,
+		Title:     form.Title,
+		Color:     color,
+		CreatorID: ctx.Doer.ID,
+	}
+	if err := project_model.NewBoard(board); err != nil {
+		ctx.Error(http.StatusInternalServerError, "NewBoard", err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, convert.ToAPIProjectBoard(board))
+}
+
+// GetProjectBoard gets a board of a project
+func GetProjectBoard(ctx *context.APIContext) {
+	// swagger:operation GET /projects/{id}/boards/{boardid} project projectGetBoard
+	// ---
+	// summary: Get a board of a project
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: id
+	//   in: path
+	//   description: id of the project
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: boardid
+	//   in: path
+	//   description: id of the board
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/ProjectBoard"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	board := getProjectBoard(ctx)
+	if ctx.Written() {
+		return
+	}
+	ctx.JSON(http.StatusOK, convert.ToAPIProjectBoard(board))
+}
+
+// EditProjectBoard edits a board of a project
+func EditProjectBoard(ctx *context.APIContext) {
+	// swagger:operation PATCH /projects/{id}/boards/{boardid} project projectEditBoard
+	// ---
+	// summary: Edit a board of a project
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: id
+	//   in: path
+	//   description: id of the project
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: boardid
+	//   in: path
+	//   description: id of the board
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/EditProjectBoardOption"
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/ProjectBoard"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	form := web.GetForm(ctx).(*api.EditProjectBoardOption)
+	board := getProjectBoard(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	if form.Sorting != nil && (*form.Sorting < math.MinInt8 || *form.Sorting > math.MaxInt8) {
+		ctx.Error(http.StatusUnprocessableEntity, "Sorting", fmt.Errorf("sorting out of range: %d", *form.Sorting))
+		return
+	}
+	if form.Color != nil {
+		color, ok := normalizeBoardColor(*form.Color)
+		if !ok {
+			ctx.Error(http.StatusUnprocessableEntity, "ColorPattern", fmt.Errorf("bad color code: %s", *form.Color))
+			return
+		}
+		board.Color = color
+	}
+	if form.Title != nil && *form.Title != "" {
+		board.Title = *form.Title
+	}
+
+	if err := project_model.UpdateBoard(board); err != nil {
+		ctx.Error(http.StatusInternalServerError, "UpdateBoard", err)
+		return
+	}
+	if form.Sorting != nil {
+		board.Sorting = int8(*form.Sorting)
+		if err := project_model.UpdateBoardSorting(project_model.BoardList{board}); err != nil {
+			ctx.Error(http.StatusInternalServerError, "UpdateBoardSorting", err)
+			return
+		}
+	}
+	if form.Default != nil && *form.Default != board.Default {
+		defaultBoardID := int64(0)
+		if *form.Default {
+			defaultBoardID = board.ID
+		}
+		if err := project_model.SetDefaultBoard(board.ProjectID, defaultBoardID); err != nil {
+			ctx.Error(http.StatusInternalServerError, "SetDefaultBoard", err)
+			return
+		}
+		board.Default = *form.Default
+	}
+
+	ctx.JSON(http.StatusOK, convert.ToAPIProjectBoard(board))
+}
+
+// DeleteProjectBoard deletes a board of a project
+func DeleteProjectBoard(ctx *context.APIContext) {
+	// swagger:operation DELETE /projects/{id}/boards/{boardid} project projectDeleteBoard
+	// ---
+	// summary: Delete a board of a project, its issues are moved to the default board
+	// parameters:
+	// - name: id
+	//   in: path
+	//   description: id of the project
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: boardid
+	//   in: path
+	//   description: id of the board
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	board := getProjectBoard(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	if err := project_model.DeleteBoardByID(board.ID); err != nil {
+		ctx.Error(http.StatusInternalServerError, "DeleteBoardByID", err)
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
+
+// getProjectBoard returns the board of the ":boardid" parameter, which has to belong to the project of the request
+func getProjectBoard(ctx *context.APIContext) *project_model.Board {
+	board, err := project_model.GetBoard(ctx.ParamsInt64(":boardid"))
+	if err != nil {
+		if project_model.IsErrProjectBoardNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetBoard", err)
+		}
+		return nil
+	}
+	if board.ProjectID != ctx.Project.Project.ID {
+		ctx.NotFound()
+		return nil
+	}
+	return board
+}
+
+// normalizeBoardColor adds the missing "#" to a color and validates it, an empty color is valid
+func normalizeBoardColor(color string) (string, bool) {
+	color = strings.TrimSpace(color)
+	if len(color) == 6 {
+		color = "#" + color
+	}
+	return color, color == "" || project_model.BoardColorPattern.MatchString(color)
+}
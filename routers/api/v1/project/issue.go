@@ -0,0 +1,281 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package project
+
+import (
+	"fmt"
+	"net/http"
+
+	"code.gitea.io/gitea/models"
+	access_model "code.gitea.io/gitea/models/perm/access"
+	project_model "code.gitea.io/gitea/models/project"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/convert"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/web"
+)
+
+// ListProjectBoardIssues lists the issues of a board of a project
+func ListProjectBoardIssues(ctx *context.APIContext) {
+	// swagger:operation GET /projects/{id}/boards/{boardid}/issues project projectListBoardIssues
+	// ---
+	// summary: List the issues of a board of a project in the order of the board
+	// description: The board with id 0 contains the issues which have not been assigned to a board.
+	//   Only the issues the user is allowed to read are listed.
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: id
+	//   in: path
+	//   description: id of the project
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: boardid
+	//   in: path
+	//   description: id of the board
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/IssueList"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	board := getProjectBoardOrUncategorized(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	issues, err := models.GetIssuesOfBoard(board)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetIssuesOfBoard", err)
+		return
+	}
+	if issues, err = models.FilterIssuesReadableBy(ctx, issues, ctx.Doer); err != nil {
+		ctx.Error(http.StatusInternalServerError, "FilterIssuesReadableBy", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, convert.ToAPIIssueList(issues))
+}
+
+// MoveProjectBoardIssues moves issues of a project to a board and sorts them
+func MoveProjectBoardIssues(ctx *context.APIContext) {
+	// swagger:operation PUT /projects/{id}/boards/{boardid}/issues project projectMoveBoardIssues
+	// ---
+	// summary: Move issues of a project to a board and sort them in the given order
+	// description: The board with id 0 contains the issues which have not been assigned to a board.
+	// consumes:
+	// - application/json
+	// parameters:
+	// - name: id
+	//   in: path
+	//   description: id of the project
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: boardid
+	//   in: path
+	//   description: id of the board
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/MoveProjectIssuesOption"
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	form := web.GetForm(ctx).(*api.MoveProjectIssuesOption)
+	board := getProjectBoardOrUncategorized(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	sortedIssueIDs := make(map[int64]int64, len(form.Issues))
+	seen := make(map[int64]bool, len(form.Issues))
+	for sorting, issueID := range form.Issues {
+		if seen[issueID] {
+			ctx.Error(http.StatusUnprocessableEntity, "Issues", fmt.Errorf("issue %d is listed more than once", issueID))
+			return
+		}
+		seen[issueID] = true
+		sortedIssueIDs[int64(sorting)] = issueID
+	}
+
+	issues, err := models.GetIssuesByIDs(form.Issues)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetIssuesByIDs", err)
+		return
+	}
+	if len(issues) != len(form.Issues) {
+		ctx.Error(http.StatusUnprocessableEntity, "Issues", fmt.Errorf("issues do not exist"))
+		return
+	}
+	for _, issue := range issues {
+		if issue.ProjectID() != board.ProjectID {
+			ctx.Error(http.StatusUnprocessableEntity, "Issues", fmt.Errorf("issue %d has not been added to the project", issue.ID))
+			return
+		}
+	}
+
+	if err := project_model.MoveIssuesOnProjectBoard(board, sortedIssueIDs); err != nil {
+		ctx.Error(http.StatusInternalServerError, "MoveIssuesOnProjectBoard", err)
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
+
+// AddProjectIssue adds an issue to a project
+func AddProjectIssue(ctx *context.APIContext) {
+	// swagger:operation POST /projects/{id}/issues project projectAddIssue
+	// ---
+	// summary: Add an issue or pull request to a project, removing it from its previous project
+	// description: The issue is added to the default board of the project.
+	// consumes:
+	// - application/json
+	// parameters:
+	// - name: id
+	//   in: path
+	//   description: id of the project
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/AddProjectIssueOption"
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	form := web.GetForm(ctx).(*api.AddProjectIssueOption)
+	p := ctx.Project.Project
+
+	issue, err := models.GetIssueByID(form.IssueID)
+	if err != nil {
+		if models.IsErrIssueNotExist(err) {
+			ctx.Error(http.StatusUnprocessableEntity, "IssueID", err)
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetIssueByID", err)
+		}
+		return
+	}
+	if ok, err := issue.CanBeAddedToProject(ctx, p); err != nil {
+		ctx.Error(http.StatusInternalServerError, "CanBeAddedToProject", err)
+		return
+	} else if !ok {
+		ctx.Error(http.StatusUnprocessableEntity, "IssueID", fmt.Errorf("issue %d cannot be added to project %d", issue.ID, p.ID))
+		return
+	}
+	if !canWriteIssue(ctx, issue) {
+		return
+	}
+
+	if issue.ProjectID() != p.ID {
+		if err := models.ChangeProjectAssign(issue, ctx.Doer, p.ID); err != nil {
+			ctx.Error(http.StatusInternalServerError, "ChangeProjectAssign", err)
+			return
+		}
+	}
+	ctx.Status(http.StatusNoContent)
+}
+
+// RemoveProjectIssue removes an issue from a project
+func RemoveProjectIssue(ctx *context.APIContext) {
+	// swagger:operation DELETE /projects/{id}/issues/{issueid} project projectRemoveIssue
+	// ---
+	// summary: Remove an issue or pull request from a project
+	// parameters:
+	// - name: id
+	//   in: path
+	//   description: id of the project
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: issueid
+	//   in: path
+	//   description: id of the issue
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	issue, err := models.GetIssueByID(ctx.ParamsInt64(":issueid"))
+	if err != nil {
+		if models.IsErrIssueNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetIssueByID", err)
+		}
+		return
+	}
+	if issue.ProjectID() != ctx.Project.Project.ID {
+		ctx.NotFound()
+		return
+	}
+	if !canWriteIssue(ctx, issue) {
+		return
+	}
+
+	if err := models.ChangeProjectAssign(issue, ctx.Doer, 0); err != nil {
+		ctx.Error(http.StatusInternalServerError, "ChangeProjectAssign", err)
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
+
+// getProjectBoardOrUncategorized returns the board of the ":boardid" parameter, or the board of the issues
+// which have not been assigned to a board if it is 0
+func getProjectBoardOrUncategorized(ctx *context.APIContext) *project_model.Board {
+	if ctx.ParamsInt64(":boardid") == 0 {
+		return &project_model.Board{
+			ProjectID: ctx.Project.Project.ID,
+			Default:   true,
+		}
+	}
+	return getProjectBoard(ctx)
+}
+
+// canWriteIssue checks whether the doer is allowed to change the project of an issue, which requires write
+// access to the issues or pull requests of its repository besides write access to the project
+func canWriteIssue(ctx *context.APIContext, issue *models.Issue) bool {
+	if err := issue.LoadRepo(ctx); err != nil {
+		ctx.Error(http.StatusInternalServerError, "LoadRepo", err)
+		return false
+	}
+	perm, err := access_model.GetUserRepoPermission(ctx, issue.Repo, ctx.Doer)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetUserRepoPermission", err)
+		return false
+	}
+	if !perm.CanWriteIssuesOrPulls(issue.IsPull) {
+		ctx.Error(http.StatusForbidden, "", "user should have a permission to write to the issues of the repository")
+		return false
+	}
+	return true
+}
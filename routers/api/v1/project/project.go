@@ -0,0 +1,404 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package project
+
+import (
+	"fmt"
+	"net/http"
+
+	project_model "code.gitea.io/gitea/models/project"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/convert"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/util"
+	"code.gitea.io/gitea/modules/web"
+	"code.gitea.io/gitea/routers/api/v1/utils"
+)
+
+// ListRepoProjects lists the projects of a repository
+func ListRepoProjects(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/projects project projectListRepoProjects
+	// ---
+	// summary: List a repository's projects
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: state
+	//   in: query
+	//   description: Project state, recognised values are open, closed and all. Defaults to "open"
+	//   type: string
+	// - name: page
+	//   in: query
+	//   description: page number of results to return (1-based)
+	//   type: integer
+	// - name: limit
+	//   in: query
+	//   description: page size of results
+	//   type: integer
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/ProjectList"
+
+	listProjects(ctx, project_model.SearchOptions{
+		RepoID: ctx.Repo.Repository.ID,
+		Type:   project_model.TypeRepository,
+	})
+}
+
+// CreateRepoProject creates a project of a repository
+func CreateRepoProject(ctx *context.APIContext) {
+	// swagger:operation POST /repos/{owner}/{repo}/projects project projectCreateRepoProject
+	// ---
+	// summary: Create a project of a repository
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/CreateProjectOption"
+	// responses:
+	//   "201":
+	//     "$ref": "#/responses/Project"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	createProject(ctx, &project_model.Project{
+		RepoID: ctx.Repo.Repository.ID,
+		Type:   project_model.TypeRepository,
+	})
+}
+
+// ListOrgProjects lists the projects of an organization
+func ListOrgProjects(ctx *context.APIContext) {
+	// swagger:operation GET /orgs/{org}/projects project projectListOrgProjects
+	// ---
+	// summary: List an organization's projects
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: org
+	//   in: path
+	//   description: name of the organization
+	//   type: string
+	//   required: true
+	// - name: state
+	//   in: query
+	//   description: Project state, recognised values are open, closed and all. Defaults to "open"
+	//   type: string
+	// - name: page
+	//   in: query
+	//   description: page number of results to return (1-based)
+	//   type: integer
+	// - name: limit
+	//   in: query
+	//   description: page size of results
+	//   type: integer
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/ProjectList"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	listProjects(ctx, project_model.SearchOptions{
+		OwnerID: ctx.Project.Owner.ID,
+	})
+}
+
+// CreateOrgProject creates a project of an organization
+func CreateOrgProject(ctx *context.APIContext) {
+	// swagger:operation POST /orgs/{org}/projects project projectCreateOrgProject
+	// ---
+	// summary: Create a project of an organization
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: org
+	//   in: path
+	//   description: name of the organization
+	//   type: string
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/CreateProjectOption"
+	// responses:
+	//   "201":
+	//     "$ref": "#/responses/Project"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	createProject(ctx, &project_model.Project{
+		OwnerID: ctx.Project.Owner.ID,
+		Type:    project_model.TypeOrganization,
+	})
+}
+
+// ListUserProjects lists the projects of a user
+func ListUserProjects(ctx *context.APIContext) {
+	// swagger:operation GET /users/{username}/projects project projectListUserProjects
+	// ---
+	// summary: List a user's projects
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: username
+	//   in: path
+	//   description: username of user
+	//   type: string
+	//   required: true
+	// - name: state
+	//   in: query
+	//   description: Project state, recognised values are open, closed and all. Defaults to "open"
+	//   type: string
+	// - name: page
+	//   in: query
+	//   description: page number of results to return (1-based)
+	//   type: integer
+	// - name: limit
+	//   in: query
+	//   description: page size of results
+	//   type: integer
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/ProjectList"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	listProjects(ctx, project_model.SearchOptions{
+		OwnerID: ctx.Project.Owner.ID,
+	})
+}
+
+// CreateUserProject creates a project of the authenticated user
+func CreateUserProject(ctx *context.APIContext) {
+	// swagger:operation POST /user/projects project projectCreateUserProject
+	// ---
+	// summary: Create a project of the authenticated user
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/CreateProjectOption"
+	// responses:
+	//   "201":
+	//     "$ref": "#/responses/Project"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	createProject(ctx, &project_model.Project{
+		OwnerID: ctx.Doer.ID,
+		Type:    project_model.TypeIndividual,
+	})
+}
+
+func listProjects(ctx *context.APIContext, opts project_model.SearchOptions) {
+	listOptions := utils.GetListOptions(ctx)
+	if listOptions.Page <= 0 {
+		listOptions.Page = 1
+	}
+	opts.Page = listOptions.Page
+	opts.PageSize = listOptions.PageSize
+
+	switch api.StateType(ctx.FormString("state")) {
+	case api.StateClosed:
+		opts.IsClosed = util.OptionalBoolTrue
+	case api.StateAll:
+		opts.IsClosed = util.OptionalBoolNone
+	default:
+		opts.IsClosed = util.OptionalBoolFalse
+	}
+
+	projects, count, err := project_model.GetProjects(opts)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetProjects", err)
+		return
+	}
+
+	apiProjects := make([]*api.Project, len(projects))
+	for i, p := range projects {
+		if err := p.LoadAttributes(ctx); err != nil {
+			ctx.Error(http.StatusInternalServerError, "LoadAttributes", err)
+			return
+		}
+		apiProjects[i] = convert.ToAPIProject(p, ctx.Doer)
+	}
+
+	ctx.SetLinkHeader(int(count), listOptions.PageSize)
+	ctx.SetTotalCountHeader(count)
+	ctx.JSON(http.StatusOK, apiProjects)
+}
+
+func createProject(ctx *context.APIContext, p *project_model.Project) {
+	form := web.GetForm(ctx).(*api.CreateProjectOption)
+
+	boardType, ok := project_model.BoardTypeFromName(form.BoardType)
+	if !ok {
+		ctx.Error(http.StatusUnprocessableEntity, "BoardType", fmt.Errorf("unknown board type: %s", form.BoardType))
+		return
+	}
+
+	p.Title = form.Title
+	p.Description = form.Description
+	p.BoardType = boardType
+	p.CreatorID = ctx.Doer.ID
+	if err := project_model.NewProject(p); err != nil {
+		ctx.Error(http.StatusInternalServerError, "NewProject", err)
+		return
+	}
+	if err := p.LoadAttributes(ctx); err != nil {
+		ctx.Error(http.StatusInternalServerError, "LoadAttributes", err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, convert.ToAPIProject(p, ctx.Doer))
+}
+
+// GetProject gets a project
+func GetProject(ctx *context.APIContext) {
+	// swagger:operation GET /projects/{id} project projectGetProject
+	// ---
+	// summary: Get a project
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: id
+	//   in: path
+	//   description: id of the project
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/Project"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	ctx.JSON(http.StatusOK, convert.ToAPIProject(ctx.Project.Project, ctx.Doer))
+}
+
+// EditProject edits a project
+func EditProject(ctx *context.APIContext) {
+	// swagger:operation PATCH /projects/{id} project projectEditProject
+	// ---
+	// summary: Edit a project
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: id
+	//   in: path
+	//   description: id of the project
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/EditProjectOption"
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/Project"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	form := web.GetForm(ctx).(*api.EditProjectOption)
+	p := ctx.Project.Project
+
+	if form.State != nil && *form.State != string(api.StateOpen) && *form.State != string(api.StateClosed) {
+		ctx.Error(http.StatusUnprocessableEntity, "State", fmt.Errorf("unknown state: %s", *form.State))
+		return
+	}
+
+	if form.Title != nil || form.Description != nil {
+		if form.Title != nil && *form.Title != "" {
+			p.Title = *form.Title
+		}
+		if form.Description != nil {
+			p.Description = *form.Description
+		}
+		if err := project_model.UpdateProject(p); err != nil {
+			ctx.Error(http.StatusInternalServerError, "UpdateProject", err)
+			return
+		}
+	}
+
+	if form.State != nil {
+		if isClosed := *form.State == string(api.StateClosed); isClosed != p.IsClosed {
+			if err := project_model.ChangeProjectStatus(p, isClosed); err != nil {
+				ctx.Error(http.StatusInternalServerError, "ChangeProjectStatus", err)
+				return
+			}
+		}
+	}
+
+	ctx.JSON(http.StatusOK, convert.ToAPIProject(p, ctx.Doer))
+}
+
+// DeleteProject deletes a project
+func DeleteProject(ctx *context.APIContext) {
+	// swagger:operation DELETE /projects/{id} project projectDeleteProject
+	// ---
+	// summary: Delete a project
+	// parameters:
+	// - name: id
+	//   in: path
+	//   description: id of the project
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	if err := project_model.DeleteProjectByID(ctx.Project.Project.ID); err != nil {
+		ctx.Error(http.StatusInternalServerError, "DeleteProjectByID", err)
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
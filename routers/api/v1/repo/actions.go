@@ -0,0 +1,79 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"errors"
+	"net/http"
+
+	actions_model "code.gitea.io/gitea/models/actions"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/setting"
+	actions_service "code.gitea.io/gitea/services/actions"
+)
+
+// ApproveActionRun approves a workflow run waiting for approval
+func ApproveActionRun(ctx *context.APIContext) {
+	// swagger:operation POST /repos/{owner}/{repo}/actions/runs/{id}/approve repository repoApproveActionRun
+	// ---
+	// summary: Approve a workflow run of a pull request from a fork, so that runners execute its jobs
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the run
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "409":
+	//     "$ref": "#/responses/conflict"
+
+	if !setting.Actions.Enabled {
+		ctx.NotFound()
+		return
+	}
+
+	run, err := actions_model.GetRunByID(ctx, ctx.ParamsInt64(":id"))
+	if err != nil {
+		if errors.Is(err, actions_model.ErrRunNotExist) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetRunByID", err)
+		}
+		return
+	}
+	if run.RepoID != ctx.Repo.Repository.ID {
+		ctx.NotFound()
+		return
+	}
+
+	if err := actions_service.ApproveRun(ctx, run, ctx.Doer); err != nil {
+		if errors.Is(err, actions_service.ErrRunNotNeedApproval) {
+			ctx.Error(http.StatusConflict, "ApproveRun", err)
+		} else {
+			ctx.Error(http.StatusInternalServerError, "ApproveRun", err)
+		}
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
@@ -502,6 +502,8 @@ func CreateBranchProtection(ctx *context.APIContext) {
 		ProtectedFilePatterns:         form.ProtectedFilePatterns,
 		UnprotectedFilePatterns:       form.UnprotectedFilePatterns,
 		BlockOnOutdatedBranch:         form.BlockOnOutdatedBranch,
+		EnableMergeQueue:              form.EnableMergeQueue,
+		RequireCodeOwnerApproval:      form.RequireCodeOwnerApproval,
 	}
 
 	err = models.UpdateProtectBranch(ctx, ctx.Repo.Repository, protectBranch, models.WhitelistOptions{
@@ -653,6 +655,14 @@ func EditBranchProtection(ctx *context.APIContext) {
 		protectBranch.BlockOnOutdatedBranch = *form.BlockOnOutdatedBranch
 	}
 
+	if form.EnableMergeQueue != nil {
+		protectBranch.EnableMergeQueue = *form.EnableMergeQueue
+	}
+
+	if form.RequireCodeOwnerApproval != nil {
+		protectBranch.RequireCodeOwnerApproval = *form.RequireCodeOwnerApproval
+	}
+
 	var whitelistUsers []int64
 	if form.PushWhitelistUsernames != nil {
 		whitelistUsers, err = user_model.GetUserIDsByNames(form.PushWhitelistUsernames, false)
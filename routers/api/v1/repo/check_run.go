@@ -0,0 +1,327 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"errors"
+	"net/http"
+
+	checks_model "code.gitea.io/gitea/models/checks"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/convert"
+	"code.gitea.io/gitea/modules/git"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/web"
+	"code.gitea.io/gitea/routers/api/v1/utils"
+	checks_service "code.gitea.io/gitea/services/checks"
+)
+
+// CreateCheckRun creates a check run against a commit
+func CreateCheckRun(ctx *context.APIContext) {
+	// swagger:operation POST /repos/{owner}/{repo}/check-runs repository repoCreateCheckRun
+	// ---
+	// summary: Create a check run
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/CreateCheckRunOption"
+	// responses:
+	//   "201":
+	//     "$ref": "#/responses/CheckRun"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	form := web.GetForm(ctx).(*api.CreateCheckRunOption)
+
+	commit, err := ctx.Repo.GitRepo.GetCommit(form.HeadSHA)
+	if err != nil {
+		if git.IsErrNotExist(err) {
+			ctx.Error(http.StatusUnprocessableEntity, "GetCommit", err)
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetCommit", err)
+		}
+		return
+	}
+
+	run, err := checks_service.CreateCheckRun(ctx, ctx.Repo.Repository, ctx.Doer, commit.ID.String(), form)
+	if err != nil {
+		if errors.Is(err, checks_service.ErrInvalidCheckRun) {
+			ctx.Error(http.StatusUnprocessableEntity, "CreateCheckRun", err)
+		} else {
+			ctx.Error(http.StatusInternalServerError, "CreateCheckRun", err)
+		}
+		return
+	}
+
+	apiRun, err := convert.ToCheckRun(ctx, run)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "ToCheckRun", err)
+		return
+	}
+	ctx.JSON(http.StatusCreated, apiRun)
+}
+
+// GetCheckRun gets a check run
+func GetCheckRun(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/check-runs/{id} repository repoGetCheckRun
+	// ---
+	// summary: Get a check run
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the check run
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/CheckRun"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	run := getCheckRunByParams(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	apiRun, err := convert.ToCheckRun(ctx, run)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "ToCheckRun", err)
+		return
+	}
+	ctx.JSON(http.StatusOK, apiRun)
+}
+
+// EditCheckRun updates a check run
+func EditCheckRun(ctx *context.APIContext) {
+	// swagger:operation PATCH /repos/{owner}/{repo}/check-runs/{id} repository repoEditCheckRun
+	// ---
+	// summary: Update a check run, annotations are added to the existing ones
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the check run
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/EditCheckRunOption"
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/CheckRun"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	form := web.GetForm(ctx).(*api.EditCheckRunOption)
+
+	run := getCheckRunByParams(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	if err := checks_service.EditCheckRun(ctx, run, ctx.Doer, form); err != nil {
+		if errors.Is(err, checks_service.ErrInvalidCheckRun) {
+			ctx.Error(http.StatusUnprocessableEntity, "EditCheckRun", err)
+		} else {
+			ctx.Error(http.StatusInternalServerError, "EditCheckRun", err)
+		}
+		return
+	}
+
+	apiRun, err := convert.ToCheckRun(ctx, run)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "ToCheckRun", err)
+		return
+	}
+	ctx.JSON(http.StatusOK, apiRun)
+}
+
+// ListCheckRunAnnotations lists the annotations of a check run
+func ListCheckRunAnnotations(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/check-runs/{id}/annotations repository repoListCheckRunAnnotations
+	// ---
+	// summary: List the annotations of a check run
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the check run
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: page
+	//   in: query
+	//   description: page number of results to return (1-based)
+	//   type: integer
+	// - name: limit
+	//   in: query
+	//   description: page size of results
+	//   type: integer
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/CheckAnnotationList"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	run := getCheckRunByParams(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	listOptions := utils.GetListOptions(ctx)
+	annotations, count, err := checks_model.GetAnnotationsByCheckRunID(ctx, run.ID, listOptions)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetAnnotationsByCheckRunID", err)
+		return
+	}
+
+	apiAnnotations := make([]*api.CheckAnnotation, 0, len(annotations))
+	for _, annotation := range annotations {
+		apiAnnotations = append(apiAnnotations, convert.ToCheckAnnotation(annotation))
+	}
+
+	ctx.SetLinkHeader(int(count), listOptions.PageSize)
+	ctx.SetTotalCountHeader(count)
+	ctx.JSON(http.StatusOK, apiAnnotations)
+}
+
+// ListCheckRunsByRef lists the check runs of a commit
+func ListCheckRunsByRef(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/commits/{ref}/check-runs repository repoListCheckRunsByRef
+	// ---
+	// summary: List the check runs of a commit, by branch/tag/commit reference
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: ref
+	//   in: path
+	//   description: name of branch/tag/commit
+	//   type: string
+	//   required: true
+	// - name: page
+	//   in: query
+	//   description: page number of results to return (1-based)
+	//   type: integer
+	// - name: limit
+	//   in: query
+	//   description: page size of results
+	//   type: integer
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/CheckRunList"
+	//   "400":
+	//     "$ref": "#/responses/error"
+
+	sha := utils.ResolveRefOrSha(ctx, ctx.Params("ref"))
+	if ctx.Written() {
+		return
+	}
+
+	listOptions := utils.GetListOptions(ctx)
+	runs, count, err := checks_model.GetCheckRunsBySHA(ctx, ctx.Repo.Repository.ID, sha, listOptions)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetCheckRunsBySHA", err)
+		return
+	}
+
+	apiRuns := make([]*api.CheckRun, 0, len(runs))
+	for _, run := range runs {
+		run.Repo = ctx.Repo.Repository
+		apiRun, err := convert.ToCheckRun(ctx, run)
+		if err != nil {
+			ctx.Error(http.StatusInternalServerError, "ToCheckRun", err)
+			return
+		}
+		apiRuns = append(apiRuns, apiRun)
+	}
+
+	ctx.SetLinkHeader(int(count), listOptions.PageSize)
+	ctx.SetTotalCountHeader(count)
+	ctx.JSON(http.StatusOK, apiRuns)
+}
+
+func getCheckRunByParams(ctx *context.APIContext) *checks_model.CheckRun {
+	run, err := checks_model.GetCheckRunByID(ctx, ctx.Repo.Repository.ID, ctx.ParamsInt64(":id"))
+	if err != nil {
+		if errors.Is(err, checks_model.ErrCheckRunNotExist) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetCheckRunByID", err)
+		}
+		return nil
+	}
+	run.Repo = ctx.Repo.Repository
+	return run
+}
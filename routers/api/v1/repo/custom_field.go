@@ -0,0 +1,315 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"net/http"
+
+	"code.gitea.io/gitea/models"
+	issues_model "code.gitea.io/gitea/models/issues"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/convert"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/web"
+)
+
+// ListCustomFields list the custom fields the issues of a repository can have
+func ListCustomFields(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/custom_fields issue issueListCustomFields
+	// ---
+	// summary: Get the custom fields of a repository's issues, including the fields of the organization owning it
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/CustomFieldList"
+
+	fields, err := issues_model.GetCustomFieldsOfRepo(ctx, ctx.Repo.Repository)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetCustomFieldsOfRepo", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, convert.ToCustomFieldList(fields))
+}
+
+// CreateCustomField create a custom field for a repository
+func CreateCustomField(ctx *context.APIContext) {
+	// swagger:operation POST /repos/{owner}/{repo}/custom_fields issue issueCreateCustomField
+	// ---
+	// summary: Create a custom field for a repository
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/CreateCustomFieldOption"
+	// responses:
+	//   "201":
+	//     "$ref": "#/responses/CustomField"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	form := web.GetForm(ctx).(*api.CreateCustomFieldOption)
+	fieldType, ok := issues_model.CustomFieldTypeFromName(form.Type)
+	if !ok {
+		ctx.Error(http.StatusUnprocessableEntity, "CustomFieldTypeFromName", issues_model.ErrInvalidCustomField{Name: form.Name})
+		return
+	}
+
+	f := &issues_model.CustomField{
+		RepoID:      ctx.Repo.Repository.ID,
+		Name:        form.Name,
+		Description: form.Description,
+		Type:        fieldType,
+		Options:     form.Options,
+	}
+	if err := issues_model.NewCustomField(ctx, f); err != nil {
+		if issues_model.IsErrInvalidCustomField(err) || issues_model.IsErrCustomFieldAlreadyExist(err) {
+			ctx.Error(http.StatusUnprocessableEntity, "NewCustomField", err)
+		} else {
+			ctx.Error(http.StatusInternalServerError, "NewCustomField", err)
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, convert.ToCustomField(f))
+}
+
+// GetCustomField get a custom field the issues of a repository can have
+func GetCustomField(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/custom_fields/{id} issue issueGetCustomField
+	// ---
+	// summary: Get a single custom field
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the custom field to get
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/CustomField"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	f := getRepoCustomField(ctx, false)
+	if ctx.Written() {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, convert.ToCustomField(f))
+}
+
+// EditCustomField modify a custom field of a repository
+func EditCustomField(ctx *context.APIContext) {
+	// swagger:operation PATCH /repos/{owner}/{repo}/custom_fields/{id} issue issueEditCustomField
+	// ---
+	// summary: Update a custom field
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the custom field to edit
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/EditCustomFieldOption"
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/CustomField"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	form := web.GetForm(ctx).(*api.EditCustomFieldOption)
+	f := getRepoCustomField(ctx, true)
+	if ctx.Written() {
+		return
+	}
+
+	if form.Name != nil {
+		f.Name = *form.Name
+	}
+	if form.Description != nil {
+		f.Description = *form.Description
+	}
+	if form.Options != nil {
+		f.Options = form.Options
+	}
+	if err := issues_model.UpdateCustomField(ctx, f); err != nil {
+		if issues_model.IsErrInvalidCustomField(err) || issues_model.IsErrCustomFieldAlreadyExist(err) {
+			ctx.Error(http.StatusUnprocessableEntity, "UpdateCustomField", err)
+		} else {
+			ctx.Error(http.StatusInternalServerError, "UpdateCustomField", err)
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, convert.ToCustomField(f))
+}
+
+// DeleteCustomField delete a custom field of a repository
+func DeleteCustomField(ctx *context.APIContext) {
+	// swagger:operation DELETE /repos/{owner}/{repo}/custom_fields/{id} issue issueDeleteCustomField
+	// ---
+	// summary: Delete a custom field and its values
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the custom field to delete
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	f := getRepoCustomField(ctx, true)
+	if ctx.Written() {
+		return
+	}
+
+	if err := issues_model.DeleteCustomField(ctx, f); err != nil {
+		ctx.Error(http.StatusInternalServerError, "DeleteCustomField", err)
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// ListIssueCustomFields list the values of the custom fields of an issue
+func ListIssueCustomFields(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/issues/{index}/custom_fields issue issueGetCustomFields
+	// ---
+	// summary: Get the values of an issue's custom fields
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: index
+	//   in: path
+	//   description: index of the issue
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/IssueCustomFieldValueList"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	issue, err := models.GetIssueByIndex(ctx.Repo.Repository.ID, ctx.ParamsInt64(":index"))
+	if err != nil {
+		if models.IsErrIssueNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetIssueByIndex", err)
+		}
+		return
+	}
+
+	values, err := issues_model.GetCustomFieldValues(ctx, issue.ID)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetCustomFieldValues", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, convert.ToIssueCustomFieldValueList(values))
+}
+
+// getRepoCustomField returns the custom field of the request, which has to belong to the repository
+// or, unless ownedOnly, to the organization owning the repository
+func getRepoCustomField(ctx *context.APIContext, ownedOnly bool) *issues_model.CustomField {
+	f, err := issues_model.GetCustomFieldByID(ctx, ctx.ParamsInt64(":id"))
+	if err != nil {
+		if issues_model.IsErrCustomFieldNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetCustomFieldByID", err)
+		}
+		return nil
+	}
+	if f.RepoID != ctx.Repo.Repository.ID && (ownedOnly || !f.AppliesTo(ctx.Repo.Repository)) {
+		ctx.NotFound()
+		return nil
+	}
+	return f
+}
@@ -351,6 +351,13 @@ func ListIssues(ctx *context.APIContext) {
 	//   in: query
 	//   description: Only show items in which the given user was mentioned
 	//   type: string
+	// - name: custom_fields
+	//   in: query
+	//   description: Only show items whose custom fields have the given values, each filter is formatted as {field id}:{value}
+	//   type: array
+	//   items:
+	//     type: string
+	//   collectionFormat: multi
 	// - name: page
 	//   in: query
 	//   description: page number of results to return (1-based)
@@ -362,6 +369,8 @@ func ListIssues(ctx *context.APIContext) {
 	// responses:
 	//   "200":
 	//     "$ref": "#/responses/IssueList"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
 	before, since, err := context.GetQueryBeforeSince(ctx.Context)
 	if err != nil {
 		ctx.Error(http.StatusUnprocessableEntity, "GetQueryBeforeSince", err)
@@ -458,6 +467,10 @@ func ListIssues(ctx *context.APIContext) {
 	if ctx.Written() {
 		return
 	}
+	customFields := getCustomFieldsForFilter(ctx, "custom_fields")
+	if ctx.Written() {
+		return
+	}
 
 	// Only fetch the issues if we either don't have a keyword or the search returned issues
 	// This would otherwise return all issues if no issues were found by the search.
@@ -475,6 +488,7 @@ func ListIssues(ctx *context.APIContext) {
 			PosterID:          createdByID,
 			AssigneeID:        assignedByID,
 			MentionedID:       mentionedByID,
+			CustomFields:      customFields,
 		}
 
 		if issues, err = models.Issues(issuesOpt); err != nil {
@@ -516,6 +530,57 @@ func getUserIDForFilter(ctx *context.APIContext, queryName string) int64 {
 	return user.ID
 }
 
+// getCustomFieldsForFilter parses the {field id}:{value} filters of the query into the stored values of the
+// custom fields keyed by their IDs
+func getCustomFieldsForFilter(ctx *context.APIContext, queryName string) map[int64]string {
+	filters := ctx.FormStrings(queryName)
+	if len(filters) == 0 {
+		return nil
+	}
+
+	customFields := make(map[int64]string, len(filters))
+	for _, filter := range filters {
+		parts := strings.SplitN(filter, ":", 2)
+		if len(parts) != 2 {
+			ctx.Error(http.StatusUnprocessableEntity, "InvalidCustomFieldFilter", fmt.Errorf("invalid custom field filter: %s", filter))
+			return nil
+		}
+		fieldID, err := strconv.ParseInt(parts[0], 10, 64)
+		if err != nil {
+			ctx.Error(http.StatusUnprocessableEntity, "InvalidCustomFieldFilter", fmt.Errorf("invalid custom field filter: %s", filter))
+			return nil
+		}
+
+		f, err := issues_model.GetCustomFieldByID(ctx, fieldID)
+		if err == nil && !f.AppliesTo(ctx.Repo.Repository) {
+			err = issues_model.ErrCustomFieldNotExist{ID: fieldID}
+		}
+		if err != nil {
+			if issues_model.IsErrCustomFieldNotExist(err) {
+				ctx.Error(http.StatusUnprocessableEntity, "GetCustomFieldByID", err)
+			} else {
+				ctx.Error(http.StatusInternalServerError, "GetCustomFieldByID", err)
+			}
+			return nil
+		}
+
+		value, err := f.NormalizeValue(ctx, parts[1])
+		if err == nil && value == "" {
+			err = issues_model.ErrInvalidCustomFieldValue{Field: f.Name, Value: parts[1]}
+		}
+		if err != nil {
+			if issues_model.IsErrInvalidCustomFieldValue(err) {
+				ctx.Error(http.StatusUnprocessableEntity, "NormalizeValue", err)
+			} else {
+				ctx.Error(http.StatusInternalServerError, "NormalizeValue", err)
+			}
+			return nil
+		}
+		customFields[fieldID] = value
+	}
+	return customFields
+}
+
 // GetIssue get an issue of a repository
 func GetIssue(ctx *context.APIContext) {
 	// swagger:operation GET /repos/{owner}/{repo}/issues/{index} issue issueGetIssue
@@ -640,9 +705,19 @@ func CreateIssue(ctx *context.APIContext) {
 				return
 			}
 		}
+
+		if err := issue_service.ValidateCustomFields(ctx, ctx.Repo.Repository, form.CustomFields); err != nil {
+			if issues_model.IsErrCustomFieldNotExist(err) || issues_model.IsErrInvalidCustomFieldValue(err) {
+				ctx.Error(http.StatusUnprocessableEntity, "ValidateCustomFields", err)
+			} else {
+				ctx.Error(http.StatusInternalServerError, "ValidateCustomFields", err)
+			}
+			return
+		}
 	} else {
-		// setting labels is not allowed if user is not a writer
+		// setting labels and custom fields is not allowed if user is not a writer
 		form.Labels = make([]int64, 0)
+		form.CustomFields = nil
 	}
 
 	if err := issue_service.NewIssue(ctx.Repo.Repository, issue, form.Labels, nil, assigneeIDs); err != nil {
@@ -654,6 +729,13 @@ func CreateIssue(ctx *context.APIContext) {
 		return
 	}
 
+	if len(form.CustomFields) > 0 {
+		if err := issue_service.SetCustomFields(ctx, ctx.Doer, issue, form.CustomFields); err != nil {
+			ctx.Error(http.StatusInternalServerError, "SetCustomFields", err)
+			return
+		}
+	}
+
 	if form.Closed {
 		if err := issue_service.ChangeStatus(issue, ctx.Doer, true); err != nil {
 			if models.IsErrDependenciesLeft(err) {
@@ -713,6 +795,8 @@ func EditIssue(ctx *context.APIContext) {
 	//     "$ref": "#/responses/notFound"
 	//   "412":
 	//     "$ref": "#/responses/error"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
 
 	form := web.GetForm(ctx).(*api.EditIssueOption)
 	issue, err := models.GetIssueByIndex(ctx.Repo.Repository.ID, ctx.ParamsInt64(":index"))
@@ -738,6 +822,18 @@ func EditIssue(ctx *context.APIContext) {
 		return
 	}
 
+	// Custom fields are updated first so that invalid values reject the request before anything else changes
+	if canWrite && len(form.CustomFields) > 0 {
+		if err := issue_service.SetCustomFields(ctx, ctx.Doer, issue, form.CustomFields); err != nil {
+			if issues_model.IsErrCustomFieldNotExist(err) || issues_model.IsErrInvalidCustomFieldValue(err) {
+				ctx.Error(http.StatusUnprocessableEntity, "SetCustomFields", err)
+			} else {
+				ctx.Error(http.StatusInternalServerError, "SetCustomFields", err)
+			}
+			return
+		}
+	}
+
 	oldTitle := issue.Title
 	if len(form.Title) > 0 {
 		issue.Title = form.Title
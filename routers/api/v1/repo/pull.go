@@ -14,6 +14,7 @@ import (
 	"time"
 
 	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/models/db"
 	issues_model "code.gitea.io/gitea/models/issues"
 	access_model "code.gitea.io/gitea/models/perm/access"
 	pull_model "code.gitea.io/gitea/models/pull"
@@ -33,6 +34,7 @@ import (
 	"code.gitea.io/gitea/services/automerge"
 	"code.gitea.io/gitea/services/forms"
 	issue_service "code.gitea.io/gitea/services/issue"
+	"code.gitea.io/gitea/services/mergequeue"
 	pull_service "code.gitea.io/gitea/services/pull"
 	repo_service "code.gitea.io/gitea/services/repository"
 )
@@ -364,6 +366,18 @@ func CreatePullRequest(ctx *context.APIContext) {
 		deadlineUnix = timeutil.TimeStamp(form.Deadline.Unix())
 	}
 
+	body := form.Body
+	if form.Template != "" {
+		template := findPullRequestTemplate(ctx, form.Template)
+		if template == nil {
+			ctx.Error(http.StatusUnprocessableEntity, "Template", fmt.Sprintf("Pull request template does not exist: %s", form.Template))
+			return
+		}
+		if body == "" {
+			body = template.Content
+		}
+	}
+
 	prIssue := &models.Issue{
 		RepoID:       repo.ID,
 		Title:        form.Title,
@@ -371,7 +385,7 @@ func CreatePullRequest(ctx *context.APIContext) {
 		Poster:       ctx.Doer,
 		MilestoneID:  milestoneID,
 		IsPull:       true,
-		Content:      form.Body,
+		Content:      body,
 		DeadlineUnix: deadlineUnix,
 	}
 	pr := &models.PullRequest{
@@ -427,6 +441,17 @@ func CreatePullRequest(ctx *context.APIContext) {
 	ctx.JSON(http.StatusCreated, convert.ToAPIPullRequest(ctx, pr, ctx.Doer))
 }
 
+// findPullRequestTemplate returns the pull request template of the default branch with the given file name or name
+func findPullRequestTemplate(ctx *context.APIContext, name string) *api.IssueTemplate {
+	for _, template := range ctx.PullRequestTemplatesFromDefaultBranch() {
+		if template.FileName == name || template.Name == name {
+			template := template
+			return &template
+		}
+	}
+	return nil
+}
+
 // EditPullRequest does what it says
 func EditPullRequest(ctx *context.APIContext) {
 	// swagger:operation PATCH /repos/{owner}/{repo}/pulls/{index} repository repoEditPullRequest
@@ -723,6 +748,8 @@ func MergePullRequest(ctx *context.APIContext) {
 	// responses:
 	//   "200":
 	//     "$ref": "#/responses/empty"
+	//   "202":
+	//     "$ref": "#/responses/empty"
 	//   "405":
 	//     "$ref": "#/responses/empty"
 	//   "409":
@@ -820,6 +847,29 @@ func MergePullRequest(ctx *context.APIContext) {
 		message += "\n\n" + form.MergeMessageField
 	}
 
+	// with a merge queue the pull request gets merged once the checks of the queue succeed
+	queued, err := mergequeue.IsEnabled(ctx, pr)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "IsEnabled", err)
+		return
+	}
+	if queued {
+		if err := mergequeue.Add(ctx, ctx.Doer, pr, repo_model.MergeStyle(form.Do), form.HeadCommitID, message); err != nil {
+			if pull_model.IsErrAlreadyInMergeQueue(err) {
+				ctx.Error(http.StatusConflict, "Add", err)
+			} else if models.IsErrInvalidMergeStyle(err) {
+				ctx.Error(http.StatusMethodNotAllowed, "Invalid merge style", fmt.Errorf("%s is not allowed an allowed merge style for this repository", repo_model.MergeStyle(form.Do)))
+			} else if models.IsErrSHADoesNotMatch(err) {
+				ctx.Error(http.StatusConflict, "Add", "head out of date")
+			} else {
+				ctx.Error(http.StatusInternalServerError, "Add", err)
+			}
+			return
+		}
+		ctx.Status(http.StatusAccepted)
+		return
+	}
+
 	if form.MergeWhenChecksSucceed {
 		scheduled, err := automerge.ScheduleAutoMerge(ctx, ctx.Doer, pr, repo_model.MergeStyle(form.Do), message)
 		if err != nil {
@@ -848,8 +898,12 @@ func MergePullRequest(ctx *context.APIContext) {
 		} else if models.IsErrMergeUnrelatedHistories(err) {
 			conflictError := err.(models.ErrMergeUnrelatedHistories)
 			ctx.JSON(http.StatusConflict, conflictError)
+		} else if models.IsErrMergeDivergingFastForwardOnly(err) {
+			ctx.Error(http.StatusConflict, "Merge", "head is not a descendant of the base branch, it cannot be fast-forwarded")
 		} else if git.IsErrPushOutOfDate(err) {
 			ctx.Error(http.StatusConflict, "Merge", "merge push out of date")
+		} else if errors.Is(err, pull_service.ErrMergeQueueRequired) {
+			ctx.Error(http.StatusConflict, "Merge", "the pull request has to be merged through the merge queue")
 		} else if models.IsErrSHADoesNotMatch(err) {
 			ctx.Error(http.StatusConflict, "Merge", "head out of date")
 		} else if git.IsErrPushRejected(err) {
@@ -1148,7 +1202,7 @@ func UpdatePullRequest(ctx *context.APIContext) {
 func CancelScheduledAutoMerge(ctx *context.APIContext) {
 	// swagger:operation DELETE /repos/{owner}/{repo}/pulls/{index}/merge repository repoCancelScheduledAutoMerge
 	// ---
-	// summary: Cancel the scheduled auto merge for the given pull request
+	// summary: Cancel the scheduled auto merge for the given pull request or remove it from the merge queue
 	// produces:
 	// - application/json
 	// parameters:
@@ -1193,7 +1247,7 @@ func CancelScheduledAutoMerge(ctx *context.APIContext) {
 		return
 	}
 	if !exist {
-		ctx.NotFound()
+		removeFromMergeQueue(ctx, pull)
 		return
 	}
 
@@ -1322,3 +1376,33 @@ func GetPullRequestCommits(ctx *context.APIContext) {
 
 	ctx.JSON(http.StatusOK, &apiCommits)
 }
+
+func removeFromMergeQueue(ctx *context.APIContext, pull *models.PullRequest) {
+	entry, err := pull_model.GetMergeQueueEntryByPullID(ctx, pull.ID)
+	if err != nil {
+		if db.IsErrNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.InternalServerError(err)
+		}
+		return
+	}
+
+	if ctx.Doer.ID != entry.DoerID {
+		allowed, err := access_model.IsUserRepoAdminCtx(ctx, ctx.Repo.Repository, ctx.Doer)
+		if err != nil {
+			ctx.InternalServerError(err)
+			return
+		}
+		if !allowed {
+			ctx.Error(http.StatusForbidden, "No permission to remove", "user has no permission to remove the pull request from the merge queue")
+			return
+		}
+	}
+
+	if err := mergequeue.Remove(ctx, ctx.Doer, pull); err != nil {
+		ctx.InternalServerError(err)
+	} else {
+		ctx.Status(http.StatusNoContent)
+	}
+}
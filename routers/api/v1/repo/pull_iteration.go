@@ -0,0 +1,179 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"net/http"
+	"strings"
+
+	"code.gitea.io/gitea/models"
+	pull_model "code.gitea.io/gitea/models/pull"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/convert"
+	api "code.gitea.io/gitea/modules/structs"
+	pull_service "code.gitea.io/gitea/services/pull"
+)
+
+// ListPullRequestIterations lists the iterations of a pull request
+func ListPullRequestIterations(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/pulls/{index}/iterations repository repoListPullRequestIterations
+	// ---
+	// summary: List the iterations of a pull request, which are the states of its head after it was created and after every push
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: index
+	//   in: path
+	//   description: index of the pull request
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/PullRequestIterationList"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	pr, err := models.GetPullRequestByIndex(ctx.Repo.Repository.ID, ctx.ParamsInt64(":index"))
+	if err != nil {
+		if models.IsErrPullRequestNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetPullRequestByIndex", err)
+		}
+		return
+	}
+
+	iterations, err := pull_model.GetIterations(ctx, pr.ID)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetIterations", err)
+		return
+	}
+
+	apiIterations := make([]*api.PullRequestIteration, 0, len(iterations))
+	for _, it := range iterations {
+		apiIteration, err := convert.ToPullRequestIteration(ctx, it, ctx.Doer)
+		if err != nil {
+			ctx.Error(http.StatusInternalServerError, "ToPullRequestIteration", err)
+			return
+		}
+		apiIterations = append(apiIterations, apiIteration)
+	}
+	ctx.JSON(http.StatusOK, apiIterations)
+}
+
+// ComparePullRequestIterations shows the changes between two iterations of a pull request
+func ComparePullRequestIterations(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/pulls/{index}/iterations/compare repository repoComparePullRequestIterations
+	// ---
+	// summary: Get the changes between two iterations of a pull request, including a range-diff if the branch has been rebased
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: index
+	//   in: path
+	//   description: index of the pull request
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: from
+	//   in: query
+	//   description: index of the older iteration, defaults to the one before the newer iteration
+	//   type: integer
+	//   format: int64
+	// - name: to
+	//   in: query
+	//   description: index of the newer iteration, defaults to the latest iteration
+	//   type: integer
+	//   format: int64
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/PullRequestIterationComparison"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	pr, err := models.GetPullRequestByIndex(ctx.Repo.Repository.ID, ctx.ParamsInt64(":index"))
+	if err != nil {
+		if models.IsErrPullRequestNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetPullRequestByIndex", err)
+		}
+		return
+	}
+
+	to := ctx.FormInt64("to")
+	if to <= 0 {
+		latest, err := pull_model.GetLatestIteration(ctx, pr.ID)
+		if err != nil {
+			if pull_model.IsErrIterationNotExist(err) {
+				ctx.NotFound()
+			} else {
+				ctx.Error(http.StatusInternalServerError, "GetLatestIteration", err)
+			}
+			return
+		}
+		to = latest.Index
+	}
+	from := ctx.FormInt64("from")
+	if from <= 0 {
+		from = to - 1
+	}
+
+	cmp, err := pull_service.CompareIterations(ctx, ctx.Repo.GitRepo, pr, from, to)
+	if err != nil {
+		if pull_model.IsErrIterationNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "CompareIterations", err)
+		}
+		return
+	}
+
+	var diff strings.Builder
+	if err := ctx.Repo.GitRepo.GetDiff(cmp.From.HeadCommitID, cmp.To.HeadCommitID, &diff); err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetDiff", err)
+		return
+	}
+
+	apiFrom, err := convert.ToPullRequestIteration(ctx, cmp.From, ctx.Doer)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "ToPullRequestIteration", err)
+		return
+	}
+	apiTo, err := convert.ToPullRequestIteration(ctx, cmp.To, ctx.Doer)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "ToPullRequestIteration", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, &api.PullRequestIterationComparison{
+		From:      apiFrom,
+		To:        apiTo,
+		IsRebased: cmp.IsRebased,
+		Diff:      diff.String(),
+		RangeDiff: cmp.RangeDiff,
+	})
+}
@@ -8,6 +8,7 @@ import (
 	"net/http"
 
 	"code.gitea.io/gitea/models"
+	quota_model "code.gitea.io/gitea/models/quota"
 	repo_model "code.gitea.io/gitea/models/repo"
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/convert"
@@ -155,6 +156,8 @@ func CreateReleaseAttachment(ctx *context.APIContext) {
 	//     "$ref": "#/responses/Attachment"
 	//   "400":
 	//     "$ref": "#/responses/error"
+	//   "413":
+	//     "$ref": "#/responses/error"
 
 	// Check if attachments are enabled
 	if !setting.Attachment.Enabled {
@@ -190,6 +193,10 @@ func CreateReleaseAttachment(ctx *context.APIContext) {
 			ctx.Error(http.StatusBadRequest, "DetectContentType", err)
 			return
 		}
+		if quota_model.IsErrQuotaExceeded(err) {
+			ctx.Error(http.StatusRequestEntityTooLarge, "UploadAttachment", err)
+			return
+		}
 		ctx.Error(http.StatusInternalServerError, "NewAttachment", err)
 		return
 	}
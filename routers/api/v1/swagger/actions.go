@@ -0,0 +1,23 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package swagger
+
+import (
+	api "code.gitea.io/gitea/modules/structs"
+)
+
+// ActionRunner
+// swagger:response ActionRunner
+type swaggerResponseActionRunner struct {
+	// in:body
+	Body api.ActionRunner `json:"body"`
+}
+
+// ActionRunnerList
+// swagger:response ActionRunnerList
+type swaggerResponseActionRunnerList struct {
+	// in:body
+	Body []api.ActionRunner `json:"body"`
+}
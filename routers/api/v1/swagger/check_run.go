@@ -0,0 +1,30 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package swagger
+
+import (
+	api "code.gitea.io/gitea/modules/structs"
+)
+
+// CheckRun
+// swagger:response CheckRun
+type swaggerResponseCheckRun struct {
+	// in:body
+	Body api.CheckRun `json:"body"`
+}
+
+// CheckRunList
+// swagger:response CheckRunList
+type swaggerResponseCheckRunList struct {
+	// in:body
+	Body []api.CheckRun `json:"body"`
+}
+
+// CheckAnnotationList
+// swagger:response CheckAnnotationList
+type swaggerResponseCheckAnnotationList struct {
+	// in:body
+	Body []api.CheckAnnotation `json:"body"`
+}
@@ -57,6 +57,27 @@ type swaggerResponseLabelList struct {
 	Body []api.Label `json:"body"`
 }
 
+// CustomField
+// swagger:response CustomField
+type swaggerResponseCustomField struct {
+	// in:body
+	Body api.CustomField `json:"body"`
+}
+
+// CustomFieldList
+// swagger:response CustomFieldList
+type swaggerResponseCustomFieldList struct {
+	// in:body
+	Body []api.CustomField `json:"body"`
+}
+
+// IssueCustomFieldValueList
+// swagger:response IssueCustomFieldValueList
+type swaggerResponseIssueCustomFieldValueList struct {
+	// in:body
+	Body []api.IssueCustomFieldValue `json:"body"`
+}
+
 // Milestone
 // swagger:response Milestone
 type swaggerResponseMilestone struct {
@@ -54,6 +54,11 @@ type swaggerParameterBodies struct {
 	// in:body
 	EditLabelOption api.EditLabelOption
 
+	// in:body
+	CreateCustomFieldOption api.CreateCustomFieldOption
+	// in:body
+	EditCustomFieldOption api.EditCustomFieldOption
+
 	// in:body
 	MarkdownOption api.MarkdownOption
 
@@ -172,4 +177,43 @@ type swaggerParameterBodies struct {
 
 	// in:body
 	CreateWikiPageOptions api.CreateWikiPageOptions
+
+	// in:body
+	SetPackageProxyOption api.SetPackageProxyOption
+
+	// in:body
+	CreatePackageCleanupRuleOption api.CreatePackageCleanupRuleOption
+
+	// in:body
+	EditPackageCleanupRuleOption api.EditPackageCleanupRuleOption
+
+	// in:body
+	EditQuotaOption api.EditQuotaOption
+
+	// in:body
+	CreateActionRunnerOption api.CreateActionRunnerOption
+
+	// in:body
+	CreateCheckRunOption api.CreateCheckRunOption
+
+	// in:body
+	EditCheckRunOption api.EditCheckRunOption
+
+	// in:body
+	CreateProjectOption api.CreateProjectOption
+
+	// in:body
+	EditProjectOption api.EditProjectOption
+
+	// in:body
+	CreateProjectBoardOption api.CreateProjectBoardOption
+
+	// in:body
+	EditProjectBoardOption api.EditProjectBoardOption
+
+	// in:body
+	AddProjectIssueOption api.AddProjectIssueOption
+
+	// in:body
+	MoveProjectIssuesOption api.MoveProjectIssuesOption
 }
@@ -28,3 +28,24 @@ type swaggerResponsePackageFileList struct {
 	// in:body
 	Body []api.PackageFile `json:"body"`
 }
+
+// PackageProxy
+// swagger:response PackageProxy
+type swaggerResponsePackageProxy struct {
+	// in:body
+	Body api.PackageProxy `json:"body"`
+}
+
+// PackageCleanupRule
+// swagger:response PackageCleanupRule
+type swaggerResponsePackageCleanupRule struct {
+	// in:body
+	Body api.PackageCleanupRule `json:"body"`
+}
+
+// PackageCleanupRuleList
+// swagger:response PackageCleanupRuleList
+type swaggerResponsePackageCleanupRuleList struct {
+	// in:body
+	Body []api.PackageCleanupRule `json:"body"`
+}
@@ -155,6 +155,20 @@ type swaggerResponsePullReviewList struct {
 	Body []api.PullReview `json:"body"`
 }
 
+// PullRequestIterationList
+// swagger:response PullRequestIterationList
+type swaggerResponsePullRequestIterationList struct {
+	// in:body
+	Body []api.PullRequestIteration `json:"body"`
+}
+
+// PullRequestIterationComparison
+// swagger:response PullRequestIterationComparison
+type swaggerResponsePullRequestIterationComparison struct {
+	// in:body
+	Body api.PullRequestIterationComparison `json:"body"`
+}
+
 // PullComment
 // swagger:response PullReviewComment
 type swaggerPullReviewComment struct {
@@ -15,12 +15,15 @@ import (
 	asymkey_model "code.gitea.io/gitea/models/asymkey"
 	perm_model "code.gitea.io/gitea/models/perm"
 	access_model "code.gitea.io/gitea/models/perm/access"
+	quota_model "code.gitea.io/gitea/models/quota"
 	"code.gitea.io/gitea/models/unit"
 	user_model "code.gitea.io/gitea/models/user"
 	gitea_context "code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/git"
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/private"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/util"
 	"code.gitea.io/gitea/modules/web"
 	pull_service "code.gitea.io/gitea/services/pull"
 )
@@ -133,9 +136,62 @@ func HookPreReceive(ctx *gitea_context.PrivateContext) {
 		}
 	}
 
+	if !preReceiveQuota(ourCtx) {
+		return
+	}
+
 	ctx.PlainText(http.StatusOK, "ok")
 }
 
+// preReceiveQuota checks if the pushed objects fit into the git quota of the repository owner
+func preReceiveQuota(ctx *preReceiveContext) bool {
+	if !setting.Quota.Enabled {
+		return true
+	}
+
+	// Deleting refs never needs additional space
+	onlyDeletions := true
+	for _, newCommitID := range ctx.opts.NewCommitIDs {
+		if newCommitID != git.EmptySHA {
+			onlyDeletions = false
+			break
+		}
+	}
+	if onlyDeletions {
+		return true
+	}
+
+	var size int64
+	if ctx.opts.GitQuarantinePath != "" {
+		var err error
+		size, err = util.GetDirectorySize(ctx.opts.GitQuarantinePath)
+		if err != nil {
+			log.Error("Unable to get size of quarantine directory %s: %v", ctx.opts.GitQuarantinePath, err)
+			ctx.JSON(http.StatusInternalServerError, private.Response{
+				Err: err.Error(),
+			})
+			return false
+		}
+	}
+
+	repo := ctx.Repo.Repository
+	if err := quota_model.CheckQuota(ctx, repo.OwnerID, quota_model.CategoryGit, size); err != nil {
+		if quota_model.IsErrQuotaExceeded(err) {
+			log.Warn("Forbidden: Push of %d bytes to %-v exceeds the quota of the owner", size, repo)
+			ctx.JSON(http.StatusRequestEntityTooLarge, private.Response{
+				Err: fmt.Sprintf("the push exceeds the storage quota of %s", repo.OwnerName),
+			})
+		} else {
+			log.Error("Unable to check quota of %-v: %v", repo, err)
+			ctx.JSON(http.StatusInternalServerError, private.Response{
+				Err: err.Error(),
+			})
+		}
+		return false
+	}
+	return true
+}
+
 func preReceiveBranch(ctx *preReceiveContext, oldCommitID, newCommitID, refFullName string) {
 	branchName := strings.TrimPrefix(refFullName, git.BranchPrefix)
 	ctx.branchName = branchName
@@ -36,6 +36,8 @@ const (
 	tplSettingsHooks base.TplName = "org/settings/hooks"
 	// tplSettingsLabels template path for render labels settings
 	tplSettingsLabels base.TplName = "org/settings/labels"
+	// tplSettingsStorage template path for render storage usage
+	tplSettingsStorage base.TplName = "org/settings/storage"
 )
 
 // Settings render the main settings page
@@ -239,3 +241,16 @@ func Labels(ctx *context.Context) {
 	ctx.Data["LabelTemplates"] = repo_module.LabelTemplates
 	ctx.HTML(http.StatusOK, tplSettingsLabels)
 }
+
+// Storage render the storage usage of the organization
+func Storage(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("settings.storage")
+	ctx.Data["PageIsOrgSettings"] = true
+	ctx.Data["PageIsSettingsStorage"] = true
+
+	if !user_setting.LoadQuotaUsage(ctx, ctx.Org.Organization.ID) {
+		return
+	}
+
+	ctx.HTML(http.StatusOK, tplSettingsStorage)
+}
@@ -0,0 +1,171 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package org
+
+import (
+	"net/http"
+	"strings"
+
+	issues_model "code.gitea.io/gitea/models/issues"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/web"
+	"code.gitea.io/gitea/services/forms"
+)
+
+const tplSettingsCustomFields base.TplName = "org/settings/custom_fields"
+
+// CustomFields render the page to manage the custom fields of the issues of all repositories of an organization
+func CustomFields(ctx *context.Context) {
+	if setCustomFieldsContext(ctx) != nil {
+		return
+	}
+
+	ctx.HTML(http.StatusOK, tplSettingsCustomFields)
+}
+
+// NewCustomFieldPost handles creation of a custom field
+func NewCustomFieldPost(ctx *context.Context) {
+	if setCustomFieldsContext(ctx) != nil {
+		return
+	}
+
+	if ctx.HasError() {
+		ctx.HTML(http.StatusOK, tplSettingsCustomFields)
+		return
+	}
+
+	form := web.GetForm(ctx).(*forms.CustomFieldForm)
+	fieldType, ok := issues_model.CustomFieldTypeFromName(form.Type)
+	if !ok {
+		ctx.RenderWithErr(ctx.Tr("repo.settings.custom_fields.invalid"), tplSettingsCustomFields, form)
+		return
+	}
+
+	f := &issues_model.CustomField{
+		OrgID:       ctx.Org.Organization.ID,
+		Name:        form.Name,
+		Description: form.Description,
+		Type:        fieldType,
+		Options:     form.OptionList(),
+	}
+	if err := issues_model.NewCustomField(ctx, f); err != nil {
+		renderCustomFieldError(ctx, err, form)
+		return
+	}
+
+	ctx.Flash.Success(ctx.Tr("repo.settings.custom_fields.create_success", f.Name))
+	ctx.Redirect(ctx.Org.OrgLink + "/settings/custom_fields")
+}
+
+// EditCustomField render the page to edit a custom field
+func EditCustomField(ctx *context.Context) {
+	if setCustomFieldsContext(ctx) != nil {
+		return
+	}
+
+	f := selectCustomFieldByContext(ctx)
+	if f == nil {
+		return
+	}
+
+	ctx.Data["name"] = f.Name
+	ctx.Data["description"] = f.Description
+	ctx.Data["type"] = f.Type.Name()
+	ctx.Data["options"] = strings.Join(f.Options, "\n")
+
+	ctx.HTML(http.StatusOK, tplSettingsCustomFields)
+}
+
+// EditCustomFieldPost handles the update of a custom field, its type cannot change
+func EditCustomFieldPost(ctx *context.Context) {
+	if setCustomFieldsContext(ctx) != nil {
+		return
+	}
+
+	f := selectCustomFieldByContext(ctx)
+	if f == nil {
+		return
+	}
+	ctx.Data["type"] = f.Type.Name()
+
+	if ctx.HasError() {
+		ctx.HTML(http.StatusOK, tplSettingsCustomFields)
+		return
+	}
+
+	form := web.GetForm(ctx).(*forms.CustomFieldForm)
+	form.Type = f.Type.Name()
+	f.Name = form.Name
+	f.Description = form.Description
+	f.Options = form.OptionList()
+	if err := issues_model.UpdateCustomField(ctx, f); err != nil {
+		renderCustomFieldError(ctx, err, form)
+		return
+	}
+
+	ctx.Flash.Success(ctx.Tr("repo.settings.update_settings_success"))
+	ctx.Redirect(ctx.Org.OrgLink + "/settings/custom_fields")
+}
+
+// DeleteCustomFieldPost handles deletion of a custom field and its values
+func DeleteCustomFieldPost(ctx *context.Context) {
+	f := selectCustomFieldByContext(ctx)
+	if f == nil {
+		return
+	}
+
+	if err := issues_model.DeleteCustomField(ctx, f); err != nil {
+		ctx.ServerError("DeleteCustomField", err)
+		return
+	}
+
+	ctx.Flash.Success(ctx.Tr("repo.settings.custom_fields.delete_success", f.Name))
+	ctx.Redirect(ctx.Org.OrgLink + "/settings/custom_fields")
+}
+
+func setCustomFieldsContext(ctx *context.Context) error {
+	ctx.Data["Title"] = ctx.Tr("org.settings")
+	ctx.Data["PageIsOrgSettings"] = true
+	ctx.Data["PageIsSettingsCustomFields"] = true
+	ctx.Data["CustomFieldsLink"] = ctx.Org.OrgLink + "/settings/custom_fields"
+	ctx.Data["CustomFieldTypes"] = issues_model.CustomFieldTypes()
+
+	fields, err := issues_model.GetCustomFieldsByOrgID(ctx, ctx.Org.Organization.ID)
+	if err != nil {
+		ctx.ServerError("GetCustomFieldsByOrgID", err)
+		return err
+	}
+	ctx.Data["CustomFields"] = fields
+	return nil
+}
+
+func selectCustomFieldByContext(ctx *context.Context) *issues_model.CustomField {
+	f, err := issues_model.GetCustomFieldByID(ctx, ctx.ParamsInt64(":id"))
+	if err != nil {
+		ctx.NotFoundOrServerError("GetCustomFieldByID", issues_model.IsErrCustomFieldNotExist, err)
+		return nil
+	}
+	if f.OrgID != ctx.Org.Organization.ID {
+		ctx.NotFound("GetCustomFieldByID", nil)
+		return nil
+	}
+
+	ctx.Data["PageIsEditCustomField"] = true
+	ctx.Data["CustomField"] = f
+	return f
+}
+
+func renderCustomFieldError(ctx *context.Context, err error, form *forms.CustomFieldForm) {
+	switch {
+	case issues_model.IsErrCustomFieldAlreadyExist(err):
+		ctx.Data["Err_Name"] = true
+		ctx.RenderWithErr(ctx.Tr("repo.settings.custom_fields.already_exists", form.Name), tplSettingsCustomFields, form)
+	case issues_model.IsErrInvalidCustomField(err):
+		ctx.RenderWithErr(ctx.Tr("repo.settings.custom_fields.invalid"), tplSettingsCustomFields, form)
+	default:
+		ctx.ServerError("CustomField", err)
+	}
+}
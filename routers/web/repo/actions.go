@@ -0,0 +1,54 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"fmt"
+	"net/http"
+
+	actions_model "code.gitea.io/gitea/models/actions"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+	actions_service "code.gitea.io/gitea/services/actions"
+)
+
+// ActionJobLogs shows the log of a workflow job as plain text
+func ActionJobLogs(ctx *context.Context) {
+	if !setting.Actions.Enabled {
+		ctx.NotFound("ActionJobLogs", nil)
+		return
+	}
+
+	job, err := actions_model.GetRunJobByID(ctx, ctx.ParamsInt64(":id"))
+	if err != nil {
+		if err == actions_model.ErrRunJobNotExist {
+			ctx.NotFound("GetRunJobByID", err)
+		} else {
+			ctx.ServerError("GetRunJobByID", err)
+		}
+		return
+	}
+	if job.RepoID != ctx.Repo.Repository.ID {
+		ctx.NotFound("GetRunJobByID", nil)
+		return
+	}
+
+	run, err := actions_model.GetRunByID(ctx, job.RunID)
+	if err != nil {
+		ctx.ServerError("GetRunByID", err)
+		return
+	}
+
+	ctx.Resp.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	ctx.Resp.WriteHeader(http.StatusOK)
+	if run.NeedApproval {
+		_, _ = fmt.Fprintf(ctx.Resp, "Run %d waits for the approval of a user with write access to the repository.\n", run.ID)
+		return
+	}
+	if err := actions_service.WriteJobLog(job, ctx.Resp); err != nil {
+		log.Error("WriteJobLog: %v", err)
+	}
+}
@@ -10,6 +10,7 @@ import (
 
 	"code.gitea.io/gitea/models"
 	access_model "code.gitea.io/gitea/models/perm/access"
+	quota_model "code.gitea.io/gitea/models/quota"
 	repo_model "code.gitea.io/gitea/models/repo"
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/httpcache"
@@ -51,6 +52,10 @@ func uploadAttachment(ctx *context.Context, repoID int64, allowedTypes string) {
 			ctx.Error(http.StatusBadRequest, err.Error())
 			return
 		}
+		if quota_model.IsErrQuotaExceeded(err) {
+			ctx.Error(http.StatusRequestEntityTooLarge, err.Error())
+			return
+		}
 		ctx.Error(http.StatusInternalServerError, fmt.Sprintf("NewAttachment: %v", err))
 		return
 	}
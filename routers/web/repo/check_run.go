@@ -0,0 +1,73 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"net/http"
+
+	checks_model "code.gitea.io/gitea/models/checks"
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/markup"
+	"code.gitea.io/gitea/modules/markup/markdown"
+)
+
+const (
+	tplCheckRun base.TplName = "repo/check_run"
+
+	checkAnnotationsPagingNum = 50
+)
+
+// CheckRun shows a check run with its summary and annotations
+func CheckRun(ctx *context.Context) {
+	run, err := checks_model.GetCheckRunByID(ctx, ctx.Repo.Repository.ID, ctx.ParamsInt64(":id"))
+	if err != nil {
+		if err == checks_model.ErrCheckRunNotExist {
+			ctx.NotFound("GetCheckRunByID", err)
+		} else {
+			ctx.ServerError("GetCheckRunByID", err)
+		}
+		return
+	}
+	run.Repo = ctx.Repo.Repository
+
+	ctx.Data["Title"] = run.Name
+	ctx.Data["CheckRun"] = run
+
+	renderCtx := &markup.RenderContext{
+		URLPrefix: ctx.Repo.RepoLink,
+		Metas:     ctx.Repo.Repository.ComposeMetas(),
+		GitRepo:   ctx.Repo.GitRepo,
+		Ctx:       ctx,
+	}
+	if ctx.Data["Summary"], err = markdown.RenderString(renderCtx, run.Summary); err != nil {
+		ctx.ServerError("RenderString", err)
+		return
+	}
+	if ctx.Data["Text"], err = markdown.RenderString(renderCtx, run.Text); err != nil {
+		ctx.ServerError("RenderString", err)
+		return
+	}
+
+	page := ctx.FormInt("page")
+	if page <= 1 {
+		page = 1
+	}
+	annotations, count, err := checks_model.GetAnnotationsByCheckRunID(ctx, run.ID, db.ListOptions{
+		Page:     page,
+		PageSize: checkAnnotationsPagingNum,
+	})
+	if err != nil {
+		ctx.ServerError("GetAnnotationsByCheckRunID", err)
+		return
+	}
+	ctx.Data["Annotations"] = annotations
+
+	pager := context.NewPagination(int(count), checkAnnotationsPagingNum, page, 5)
+	ctx.Data["Page"] = pager
+
+	ctx.HTML(http.StatusOK, tplCheckRun)
+}
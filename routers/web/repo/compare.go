@@ -783,7 +783,19 @@ func CompareDiff(ctx *context.Context) {
 	ctx.Data["IsRepoToolbarCommits"] = true
 	ctx.Data["IsDiffCompare"] = true
 	ctx.Data["RequireTribute"] = true
-	setTemplateIfExists(ctx, pullRequestTemplateKey, nil, pullRequestTemplateCandidates)
+	setTemplateIfExists(ctx, pullRequestTemplateKey, context.PullRequestTemplateDirCandidates, pullRequestTemplateCandidates)
+	pullRequestTemplates := ctx.PullRequestTemplatesFromDefaultBranch()
+	ctx.Data["PullRequestTemplates"] = pullRequestTemplates
+	ctx.Data["PullRequestTemplateName"] = ctx.FormString("template")
+
+	// choosing a template keeps the other parameters of the comparison, like the prefilled title and body
+	templateLinks := make(map[string]string, len(pullRequestTemplates))
+	for _, t := range pullRequestTemplates {
+		query := ctx.Req.URL.Query()
+		query.Set("template", t.FileName)
+		templateLinks[t.FileName] = "?" + query.Encode()
+	}
+	ctx.Data["PullRequestTemplateLinks"] = templateLinks
 	ctx.Data["IsAttachmentEnabled"] = setting.Attachment.Enabled
 	upload.AddUploadContext(ctx, "comment")
 
@@ -9,6 +9,7 @@ import (
 	"bytes"
 	"compress/gzip"
 	gocontext "context"
+	"errors"
 	"fmt"
 	"net/http"
 	"os"
@@ -19,11 +20,13 @@ import (
 	"sync"
 	"time"
 
+	actions_model "code.gitea.io/gitea/models/actions"
 	"code.gitea.io/gitea/models/auth"
 	"code.gitea.io/gitea/models/perm"
 	access_model "code.gitea.io/gitea/models/perm/access"
 	repo_model "code.gitea.io/gitea/models/repo"
 	"code.gitea.io/gitea/models/unit"
+	"code.gitea.io/gitea/modules/base"
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/git"
 	"code.gitea.io/gitea/modules/log"
@@ -154,6 +157,16 @@ func httpBase(ctx *context.Context) (h *serviceHandler) {
 		askAuth = askAuth || (repo.Owner.Visibility != structs.VisibleTypePublic)
 	}
 
+	// a runner may pull the repository with the token of a job it is running
+	if askAuth && isPull && repoExist && !isWiki && !ctx.IsSigned && setting.Actions.Enabled {
+		job, err := getRunningActionJob(ctx)
+		if err != nil {
+			ctx.ServerError("getRunningActionJob", err)
+			return
+		}
+		askAuth = job == nil || job.RepoID != repo.ID
+	}
+
 	// check access
 	if askAuth {
 		// rely on the results of Contexter
@@ -290,6 +303,25 @@ func httpBase(ctx *context.Context) (h *serviceHandler) {
 	return &serviceHandler{cfg, w, r, dir, cfg.Env}
 }
 
+// getRunningActionJob returns the running job whose token is sent with the Basic authentication of the request
+func getRunningActionJob(ctx *context.Context) (*actions_model.ActionRunJob, error) {
+	auths := strings.SplitN(ctx.Req.Header.Get("Authorization"), " ", 2)
+	if len(auths) != 2 || strings.ToLower(auths[0]) != "basic" {
+		return nil, nil
+	}
+	uname, passwd, _ := base.BasicAuthDecode(auths[1])
+	token := passwd
+	if len(passwd) == 0 || passwd == "x-oauth-basic" {
+		token = uname
+	}
+
+	job, err := actions_model.GetRunningJobByToken(ctx, token)
+	if errors.Is(err, actions_model.ErrRunJobNotExist) {
+		return nil, nil
+	}
+	return job, err
+}
+
 var (
 	infoRefsCache []byte
 	infoRefsOnce  sync.Once
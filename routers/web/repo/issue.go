@@ -10,6 +10,7 @@ import (
 	stdCtx "context"
 	"errors"
 	"fmt"
+	"html"
 	"io"
 	"math/big"
 	"net/http"
@@ -23,6 +24,7 @@ import (
 	"code.gitea.io/gitea/models/db"
 	issues_model "code.gitea.io/gitea/models/issues"
 	"code.gitea.io/gitea/models/organization"
+	"code.gitea.io/gitea/models/perm"
 	access_model "code.gitea.io/gitea/models/perm/access"
 	project_model "code.gitea.io/gitea/models/project"
 	pull_model "code.gitea.io/gitea/models/pull"
@@ -34,6 +36,7 @@ import (
 	"code.gitea.io/gitea/modules/convert"
 	"code.gitea.io/gitea/modules/git"
 	issue_indexer "code.gitea.io/gitea/modules/indexer/issues"
+	issue_template "code.gitea.io/gitea/modules/issue/template"
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/markup"
 	"code.gitea.io/gitea/modules/markup/markdown"
@@ -63,6 +66,8 @@ const (
 
 	issueTemplateKey      = "IssueTemplate"
 	issueTemplateTitleKey = "IssueTemplateTitle"
+	issueFormTemplateKey  = "IssueFormTemplate"
+	issueFormValuesKey    = "IssueFormValues"
 )
 
 // IssueTemplateCandidates issue templates
@@ -343,11 +348,7 @@ func issues(ctx *context.Context, milestoneID, projectID int64, isPullOption uti
 	}
 
 	if ctx.Repo.CanWriteIssuesOrPulls(ctx.Params(":type") == "pulls") {
-		projects, _, err := project_model.GetProjects(project_model.SearchOptions{
-			RepoID:   repo.ID,
-			Type:     project_model.TypeRepository,
-			IsClosed: util.OptionalBoolOf(isShowClosed),
-		})
+		projects, err := getProjectsForRepo(ctx, repo, util.OptionalBoolOf(isShowClosed))
 		if err != nil {
 			ctx.ServerError("GetProjects", err)
 			return
@@ -450,26 +451,59 @@ func RetrieveRepoMilestonesAndAssignees(ctx *context.Context, repo *repo_model.R
 	handleTeamMentions(ctx)
 }
 
-func retrieveProjects(ctx *context.Context, repo *repo_model.Repository) {
-	var err error
-
-	ctx.Data["OpenProjects"], _, err = project_model.GetProjects(project_model.SearchOptions{
+// getProjectsForRepo returns the projects the issues of a repository can be added to, which are the projects of the
+// repository followed by the projects of its owner if the doer can write to them
+func getProjectsForRepo(ctx *context.Context, repo *repo_model.Repository, isClosed util.OptionalBool) ([]*project_model.Project, error) {
+	projects, _, err := project_model.GetProjects(project_model.SearchOptions{
 		RepoID:   repo.ID,
 		Page:     -1,
-		IsClosed: util.OptionalBoolFalse,
+		IsClosed: isClosed,
 		Type:     project_model.TypeRepository,
 	})
 	if err != nil {
-		ctx.ServerError("GetProjects", err)
-		return
+		return nil, err
+	}
+	for _, p := range projects {
+		p.Repo = repo
 	}
 
-	ctx.Data["ClosedProjects"], _, err = project_model.GetProjects(project_model.SearchOptions{
-		RepoID:   repo.ID,
+	ownerProjects, _, err := project_model.GetProjects(project_model.SearchOptions{
+		OwnerID:  repo.OwnerID,
 		Page:     -1,
-		IsClosed: util.OptionalBoolTrue,
-		Type:     project_model.TypeRepository,
+		IsClosed: isClosed,
 	})
+	if err != nil {
+		return nil, err
+	}
+	if len(ownerProjects) == 0 {
+		return projects, nil
+	}
+	if err := repo.GetOwner(ctx); err != nil {
+		return nil, err
+	}
+	accessMode, err := context.OwnerProjectsAccessMode(ctx, repo.Owner, ctx.Doer)
+	if err != nil {
+		return nil, err
+	}
+	if accessMode < perm.AccessModeWrite {
+		return projects, nil
+	}
+	for _, p := range ownerProjects {
+		p.Owner = repo.Owner
+	}
+	return append(projects, ownerProjects...), nil
+}
+
+func retrieveProjects(ctx *context.Context, repo *repo_model.Repository) {
+	var err error
+
+	ctx.Data["OpenProjects"], err = getProjectsForRepo(ctx, repo, util.OptionalBoolFalse)
+	if err != nil {
+		ctx.ServerError("GetProjects", err)
+		return
+	}
+
+	ctx.Data["ClosedProjects"], err = getProjectsForRepo(ctx, repo, util.OptionalBoolTrue)
 	if err != nil {
 		ctx.ServerError("GetProjects", err)
 		return
@@ -740,9 +774,27 @@ func getFileContentFromDefaultBranch(ctx *context.Context, filename string) (str
 	return string(bytes), true
 }
 
+// getIssueFormTemplate returns the issue form of the repository with the file name, or nil if there is none
+func getIssueFormTemplate(ctx *context.Context, fileName string) *api.IssueTemplate {
+	for _, it := range ctx.IssueTemplatesFromDefaultBranch() {
+		if it.IsForm() && it.FileName == fileName {
+			return &it
+		}
+	}
+	return nil
+}
+
 func setTemplateIfExists(ctx *context.Context, ctxDataKey string, possibleDirs, possibleFiles []string) {
 	templateCandidates := make([]string, 0, len(possibleFiles))
-	if ctx.FormString("template") != "" {
+	if ctxDataKey == issueTemplateKey && issue_template.IsFormFile(ctx.FormString("template")) {
+		// an invalid issue form is ignored in favor of the fallback files
+		if it := getIssueFormTemplate(ctx, ctx.FormString("template")); it != nil {
+			ctx.Data[issueFormTemplateKey] = it
+			ctx.Data[issueFormValuesKey] = url.Values{}
+			setTemplateMeta(ctx, it)
+			return
+		}
+	} else if ctx.FormString("template") != "" {
 		for _, dirName := range possibleDirs {
 			templateCandidates = append(templateCandidates, path.Join(dirName, ctx.FormString("template")))
 		}
@@ -758,35 +810,40 @@ func setTemplateIfExists(ctx *context.Context, ctxDataKey string, possibleDirs,
 				ctx.Data[ctxDataKey] = templateContent
 				return
 			}
-			ctx.Data[issueTemplateTitleKey] = meta.Title
 			ctx.Data[ctxDataKey] = templateBody
-			labelIDs := make([]string, 0, len(meta.Labels))
-			if repoLabels, err := models.GetLabelsByRepoID(ctx.Repo.Repository.ID, "", db.ListOptions{}); err == nil {
-				ctx.Data["Labels"] = repoLabels
-				if ctx.Repo.Owner.IsOrganization() {
-					if orgLabels, err := models.GetLabelsByOrgID(ctx.Repo.Owner.ID, ctx.FormString("sort"), db.ListOptions{}); err == nil {
-						ctx.Data["OrgLabels"] = orgLabels
-						repoLabels = append(repoLabels, orgLabels...)
-					}
-				}
+			setTemplateMeta(ctx, &meta)
+			return
+		}
+	}
+}
 
-				for _, metaLabel := range meta.Labels {
-					for _, repoLabel := range repoLabels {
-						if strings.EqualFold(repoLabel.Name, metaLabel) {
-							repoLabel.IsChecked = true
-							labelIDs = append(labelIDs, strconv.FormatInt(repoLabel.ID, 10))
-							break
-						}
-					}
+// setTemplateMeta sets the title, the labels and the reference of a new issue or pull request to those of its template
+func setTemplateMeta(ctx *context.Context, meta *api.IssueTemplate) {
+	ctx.Data[issueTemplateTitleKey] = meta.Title
+	labelIDs := make([]string, 0, len(meta.Labels))
+	if repoLabels, err := models.GetLabelsByRepoID(ctx.Repo.Repository.ID, "", db.ListOptions{}); err == nil {
+		ctx.Data["Labels"] = repoLabels
+		if ctx.Repo.Owner.IsOrganization() {
+			if orgLabels, err := models.GetLabelsByOrgID(ctx.Repo.Owner.ID, ctx.FormString("sort"), db.ListOptions{}); err == nil {
+				ctx.Data["OrgLabels"] = orgLabels
+				repoLabels = append(repoLabels, orgLabels...)
+			}
+		}
+
+		for _, metaLabel := range meta.Labels {
+			for _, repoLabel := range repoLabels {
+				if strings.EqualFold(repoLabel.Name, metaLabel) {
+					repoLabel.IsChecked = true
+					labelIDs = append(labelIDs, strconv.FormatInt(repoLabel.ID, 10))
+					break
 				}
 			}
-			ctx.Data["HasSelectedLabel"] = len(labelIDs) > 0
-			ctx.Data["label_ids"] = strings.Join(labelIDs, ",")
-			ctx.Data["Reference"] = meta.Ref
-			ctx.Data["RefEndName"] = git.RefEndName(meta.Ref)
-			return
 		}
 	}
+	ctx.Data["HasSelectedLabel"] = len(labelIDs) > 0
+	ctx.Data["label_ids"] = strings.Join(labelIDs, ",")
+	ctx.Data["Reference"] = meta.Ref
+	ctx.Data["RefEndName"] = git.RefEndName(meta.Ref)
 }
 
 // NewIssue render creating issue page
@@ -821,8 +878,10 @@ func NewIssue(ctx *context.Context) {
 		project, err := project_model.GetProjectByID(projectID)
 		if err != nil {
 			log.Error("GetProjectByID: %d: %v", projectID, err)
-		} else if project.RepoID != ctx.Repo.Repository.ID {
-			log.Error("GetProjectByID: %d: %v", projectID, fmt.Errorf("project[%d] not in repo [%d]", project.ID, ctx.Repo.Repository.ID))
+		} else if !project.AcceptsIssuesOf(ctx.Repo.Repository) {
+			log.Error("GetProjectByID: %d: %v", projectID, fmt.Errorf("project[%d] does not accept issues of repo [%d]", project.ID, ctx.Repo.Repository.ID))
+		} else if err := project.LoadAttributes(ctx); err != nil {
+			log.Error("LoadAttributes: %d: %v", projectID, err)
 		} else {
 			ctx.Data["project_id"] = projectID
 			ctx.Data["Project"] = project
@@ -935,10 +994,14 @@ func ValidateRepoMetas(ctx *context.Context, form forms.CreateIssueForm, isPull
 			ctx.ServerError("GetProjectByID", err)
 			return nil, nil, 0, 0
 		}
-		if p.RepoID != ctx.Repo.Repository.ID {
+		if !p.AcceptsIssuesOf(ctx.Repo.Repository) {
 			ctx.NotFound("", nil)
 			return nil, nil, 0, 0
 		}
+		if err := p.LoadAttributes(ctx); err != nil {
+			ctx.ServerError("LoadAttributes", err)
+			return nil, nil, 0, 0
+		}
 
 		ctx.Data["Project"] = p
 		ctx.Data["project_id"] = form.ProjectID
@@ -992,10 +1055,21 @@ func NewIssuePost(ctx *context.Context) {
 	upload.AddUploadContext(ctx, "comment")
 
 	var (
-		repo        = ctx.Repo.Repository
-		attachments []string
+		repo         = ctx.Repo.Repository
+		attachments  []string
+		formTemplate *api.IssueTemplate
 	)
 
+	if form.FormTemplate != "" {
+		formTemplate = getIssueFormTemplate(ctx, form.FormTemplate)
+		if formTemplate == nil {
+			ctx.NotFound("getIssueFormTemplate", nil)
+			return
+		}
+		ctx.Data[issueFormTemplateKey] = formTemplate
+		ctx.Data[issueFormValuesKey] = ctx.Req.Form
+	}
+
 	labelIDs, assigneeIDs, milestoneID, projectID := ValidateRepoMetas(ctx, *form, false)
 	if ctx.Written() {
 		return
@@ -1015,6 +1089,14 @@ func NewIssuePost(ctx *context.Context) {
 		return
 	}
 
+	if formTemplate != nil {
+		if missing := issue_template.MissingRequiredFields(formTemplate, ctx.Req.Form); len(missing) > 0 {
+			ctx.RenderWithErr(ctx.Tr("repo.issues.new.form_fields_required", html.EscapeString(strings.Join(missing, ", "))), tplIssueNew, form)
+			return
+		}
+		form.Content = issue_template.RenderToMarkdown(formTemplate, ctx.Req.Form)
+	}
+
 	issue := &models.Issue{
 		RepoID:      repo.ID,
 		Repo:        repo,
@@ -1043,8 +1125,8 @@ func NewIssuePost(ctx *context.Context) {
 	}
 
 	log.Trace("Issue created: %d/%d", repo.ID, issue.ID)
-	if ctx.FormString("redirect_after_creation") == "project" {
-		ctx.Redirect(ctx.Repo.RepoLink + "/projects/" + strconv.FormatInt(form.ProjectID, 10))
+	if p, ok := ctx.Data["Project"].(*project_model.Project); ok && ctx.FormString("redirect_after_creation") == "project" {
+		ctx.Redirect(p.Link())
 	} else {
 		ctx.Redirect(issue.Link())
 	}
@@ -1184,6 +1266,12 @@ func ViewIssue(ctx *context.Context) {
 		ctx.ServerError("LoadAttributes", err)
 		return
 	}
+	if issue.Project != nil && issue.Project.ID > 0 {
+		if err = issue.Project.LoadAttributes(ctx); err != nil {
+			ctx.ServerError("LoadAttributes", err)
+			return
+		}
+	}
 
 	if err = filterXRefComments(ctx, issue); err != nil {
 		ctx.ServerError("filterXRefComments", err)
@@ -1229,6 +1317,17 @@ func ViewIssue(ctx *context.Context) {
 		if ctx.Written() {
 			return
 		}
+
+		if !issue.IsClosed {
+			stack, err := pull_service.GetStack(ctx, issue.PullRequest)
+			if err != nil {
+				ctx.ServerError("GetStack", err)
+				return
+			}
+			if len(stack) > 1 {
+				ctx.Data["PullStack"] = stack
+			}
+		}
 	}
 
 	// Metas.
@@ -1588,6 +1687,8 @@ func ViewIssue(ctx *context.Context) {
 				mergeStyle = repo_model.MergeStyleRebaseMerge
 			} else if prConfig.AllowSquash {
 				mergeStyle = repo_model.MergeStyleSquash
+			} else if prConfig.AllowFastForwardOnly {
+				mergeStyle = repo_model.MergeStyleFastForwardOnly
 			} else if prConfig.AllowManualMerge {
 				mergeStyle = repo_model.MergeStyleManuallyMerged
 			}
@@ -1623,6 +1724,14 @@ func ViewIssue(ctx *context.Context) {
 			ctx.Data["IsBlockedByRejection"] = pull.ProtectedBranch.MergeBlockedByRejectedReview(ctx, pull)
 			ctx.Data["IsBlockedByOfficialReviewRequests"] = pull.ProtectedBranch.MergeBlockedByOfficialReviewRequests(ctx, pull)
 			ctx.Data["IsBlockedByOutdatedBranch"] = pull.ProtectedBranch.MergeBlockedByOutdatedBranch(pull)
+			if pull.ProtectedBranch.RequireCodeOwnerApproval {
+				approved, err := pull_service.IsApprovedByCodeOwners(ctx, pull)
+				if err != nil {
+					ctx.ServerError("IsApprovedByCodeOwners", err)
+					return
+				}
+				ctx.Data["IsBlockedByCodeOwners"] = !approved
+			}
 			ctx.Data["GrantedApprovals"] = pull.ProtectedBranch.GetGrantedApprovalsCount(ctx, pull)
 			ctx.Data["RequireSigned"] = pull.ProtectedBranch.RequireSignedCommits
 			ctx.Data["ChangedProtectedFiles"] = pull.ChangedProtectedFiles
@@ -1699,6 +1808,24 @@ func ViewIssue(ctx *context.Context) {
 		return
 	}
 
+	// Get custom fields
+	customFields, err := issues_model.GetCustomFieldsOfRepo(ctx, ctx.Repo.Repository)
+	if err != nil {
+		ctx.ServerError("GetCustomFieldsOfRepo", err)
+		return
+	}
+	values, err := issues_model.GetCustomFieldValues(ctx, issue.ID)
+	if err != nil {
+		ctx.ServerError("GetCustomFieldValues", err)
+		return
+	}
+	customFieldValues := make(map[int64]string, len(values))
+	for _, v := range values {
+		customFieldValues[v.FieldID] = v.DisplayValue()
+	}
+	ctx.Data["CustomFields"] = customFields
+	ctx.Data["CustomFieldValues"] = customFieldValues
+
 	ctx.Data["Participants"] = participants
 	ctx.Data["NumParticipants"] = len(participants)
 	ctx.Data["Issue"] = issue
@@ -1930,6 +2057,42 @@ func UpdateIssueDeadline(ctx *context.Context) {
 	ctx.JSON(http.StatusCreated, api.IssueDeadline{Deadline: &deadline})
 }
 
+// UpdateIssueCustomFields change the values of the custom fields of an issue
+func UpdateIssueCustomFields(ctx *context.Context) {
+	issue := GetActionIssue(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	if !ctx.Repo.CanWriteIssuesOrPulls(issue.IsPull) {
+		ctx.Error(http.StatusForbidden)
+		return
+	}
+
+	fields, err := issues_model.GetCustomFieldsOfRepo(ctx, ctx.Repo.Repository)
+	if err != nil {
+		ctx.ServerError("GetCustomFieldsOfRepo", err)
+		return
+	}
+	values := make(map[int64]string, len(fields))
+	for _, f := range fields {
+		values[f.ID] = ctx.FormString(fmt.Sprintf("custom_field_%d", f.ID))
+	}
+
+	if err := issue_service.SetCustomFields(ctx, ctx.Doer, issue, values); err != nil {
+		if issues_model.IsErrInvalidCustomFieldValue(err) {
+			invalid := err.(issues_model.ErrInvalidCustomFieldValue)
+			ctx.Flash.Error(ctx.Tr("repo.issues.custom_fields.invalid_value", invalid.Value, invalid.Field))
+			ctx.Redirect(issue.HTMLURL())
+			return
+		}
+		ctx.ServerError("SetCustomFields", err)
+		return
+	}
+
+	ctx.Redirect(issue.HTMLURL())
+}
+
 // UpdateIssueMilestone change issue's milestone
 func UpdateIssueMilestone(ctx *context.Context) {
 	issues := getActionIssues(ctx)
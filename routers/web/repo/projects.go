@@ -29,7 +29,7 @@ const (
 	tplProjects           base.TplName = "repo/projects/list"
 	tplProjectsNew        base.TplName = "repo/projects/new"
 	tplProjectsView       base.TplName = "repo/projects/view"
-	tplGenericProjectsNew base.TplName = "user/project"
+	tplProjectsAutomation base.TplName = "repo/projects/automation"
 )
 
 // MustEnableProjects check if projects are enabled in settings
@@ -296,31 +296,16 @@ func ViewProject(ctx *context.Context) {
 		boards[0].Title = ctx.Tr("repo.projects.type.uncategorized")
 	}
 
-	issuesMap, err := models.LoadIssuesFromBoardList(boards)
+	issuesMap, err := models.LoadIssuesFromBoardList(ctx, boards, ctx.Doer)
 	if err != nil {
 		ctx.ServerError("LoadIssuesOfBoards", err)
 		return
 	}
 
-	linkedPrsMap := make(map[int64][]*models.Issue)
-	for _, issuesList := range issuesMap {
-		for _, issue := range issuesList {
-			var referencedIds []int64
-			for _, comment := range issue.Comments {
-				if comment.RefIssueID != 0 && comment.RefIsPull {
-					referencedIds = append(referencedIds, comment.RefIssueID)
-				}
-			}
-
-			if len(referencedIds) > 0 {
-				if linkedPrs, err := models.Issues(&models.IssuesOptions{
-					IssueIDs: referencedIds,
-					IsPull:   util.OptionalBoolTrue,
-				}); err == nil {
-					linkedPrsMap[issue.ID] = linkedPrs
-				}
-			}
-		}
+	linkedPrsMap, err := models.LoadLinkedPullsOfBoardIssues(ctx, issuesMap, ctx.Doer)
+	if err != nil {
+		ctx.ServerError("LoadLinkedPullsOfBoardIssues", err)
+		return
 	}
 	ctx.Data["LinkedPRs"] = linkedPrsMap
 
@@ -335,8 +320,10 @@ func ViewProject(ctx *context.Context) {
 		return
 	}
 
+	project.Repo = ctx.Repo.Repository
+
 	ctx.Data["IsProjectsPage"] = true
-	ctx.Data["CanWriteProjects"] = ctx.Repo.Permission.CanWrite(unit.TypeProjects)
+	ctx.Data["CanWriteProjects"] = ctx.Repo.Permission.CanWrite(unit.TypeProjects) && !ctx.Repo.Repository.IsArchived
 	ctx.Data["Project"] = project
 	ctx.Data["IssuesMap"] = issuesMap
 	ctx.Data["Boards"] = boards
@@ -352,6 +339,39 @@ func UpdateIssueProject(ctx *context.Context) {
 	}
 
 	projectID := ctx.FormInt64("id")
+	if projectID > 0 {
+		p, err := project_model.GetProjectByID(projectID)
+		if err != nil {
+			if project_model.IsErrProjectNotExist(err) {
+				ctx.NotFound("", nil)
+			} else {
+				ctx.ServerError("GetProjectByID", err)
+			}
+			return
+		}
+		if !p.AcceptsIssuesOf(ctx.Repo.Repository) {
+			ctx.NotFound("", nil)
+			return
+		}
+		if p.Type != project_model.TypeRepository {
+			if err := ctx.Repo.Repository.GetOwner(ctx); err != nil {
+				ctx.ServerError("GetOwner", err)
+				return
+			}
+			accessMode, err := context.OwnerProjectsAccessMode(ctx, ctx.Repo.Repository.Owner, ctx.Doer)
+			if err != nil {
+				ctx.ServerError("OwnerProjectsAccessMode", err)
+				return
+			}
+			if accessMode < perm.AccessModeWrite {
+				ctx.JSON(http.StatusForbidden, map[string]string{
+					"message": "Only users with write access to the project are allowed to add issues to it.",
+				})
+				return
+			}
+		}
+	}
+
 	for _, issue := range issues {
 		oldProjectID := issue.ProjectID()
 		if oldProjectID == projectID {
@@ -647,46 +667,124 @@ func MoveIssues(ctx *context.Context) {
 	})
 }
 
-// CreateProject renders the generic project creation page
-func CreateProject(ctx *context.Context) {
-	ctx.Data["Title"] = ctx.Tr("repo.projects.new")
-	ctx.Data["ProjectTypes"] = project_model.GetProjectsConfig()
-	ctx.Data["CanWriteProjects"] = ctx.Repo.Permission.CanWrite(unit.TypeProjects)
-
-	ctx.HTML(http.StatusOK, tplGenericProjectsNew)
+// getRepoProject returns the project of the ":id" parameter, which has to be a project of the repository
+func getRepoProject(ctx *context.Context) *project_model.Project {
+	p, err := project_model.GetProjectByID(ctx.ParamsInt64(":id"))
+	if err != nil {
+		if project_model.IsErrProjectNotExist(err) {
+			ctx.NotFound("", nil)
+		} else {
+			ctx.ServerError("GetProjectByID", err)
+		}
+		return nil
+	}
+	if p.RepoID != ctx.Repo.Repository.ID {
+		ctx.NotFound("", nil)
+		return nil
+	}
+	p.Repo = ctx.Repo.Repository
+	return p
 }
 
-// CreateProjectPost creates an individual and/or organization project
-func CreateProjectPost(ctx *context.Context, form forms.UserCreateProjectForm) {
-	user := checkContextUser(ctx, form.UID)
+// ProjectAutomation renders the automation rules of a project
+func ProjectAutomation(ctx *context.Context) {
+	p := getRepoProject(ctx)
 	if ctx.Written() {
 		return
 	}
 
-	ctx.Data["ContextUser"] = user
+	ctx.Data["Title"] = ctx.Tr("repo.projects.automation")
+	ctx.Data["IsProjectsPage"] = true
+	ctx.Data["Project"] = p
+	ctx.Data["AutomationTriggers"] = project_model.AutomationTriggers()
 
-	if ctx.HasError() {
-		ctx.Data["CanWriteProjects"] = ctx.Repo.Permission.CanWrite(unit.TypeProjects)
-		ctx.HTML(http.StatusOK, tplGenericProjectsNew)
+	rules, err := project_model.GetAutomationRules(ctx, p.ID)
+	if err != nil {
+		ctx.ServerError("GetAutomationRules", err)
 		return
 	}
+	ctx.Data["AutomationRules"] = rules
 
-	projectType := project_model.TypeIndividual
-	if user.IsOrganization() {
-		projectType = project_model.TypeOrganization
+	boards, err := project_model.GetBoards(p.ID)
+	if err != nil {
+		ctx.ServerError("GetProjectBoards", err)
+		return
+	}
+	boardsMap := make(map[int64]*project_model.Board, len(boards))
+	for _, board := range boards {
+		boardsMap[board.ID] = board
 	}
+	// the uncategorized board has no ID, it cannot be the target of a rule
+	if len(boards) > 0 && boards[0].ID == 0 {
+		boards = boards[1:]
+	}
+	ctx.Data["Boards"] = boards
+	ctx.Data["BoardsMap"] = boardsMap
 
-	if err := project_model.NewProject(&project_model.Project{
-		Title:       form.Title,
-		Description: form.Content,
-		CreatorID:   user.ID,
-		BoardType:   form.BoardType,
-		Type:        projectType,
+	labels, err := models.GetLabelsOfProject(ctx, p)
+	if err != nil {
+		ctx.ServerError("GetLabelsOfProject", err)
+		return
+	}
+	labelsMap := make(map[int64]*models.Label, len(labels))
+	for _, label := range labels {
+		labelsMap[label.ID] = label
+	}
+	ctx.Data["Labels"] = labels
+	ctx.Data["LabelsMap"] = labelsMap
+
+	ctx.HTML(http.StatusOK, tplProjectsAutomation)
+}
+
+// NewProjectAutomationRulePost adds an automation rule to a project
+func NewProjectAutomationRulePost(ctx *context.Context) {
+	form := web.GetForm(ctx).(*forms.ProjectAutomationRuleForm)
+	p := getRepoProject(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	trigger, ok := project_model.AutomationTriggerFromName(form.TriggerType)
+	if ctx.HasError() || !ok {
+		ctx.Flash.Error(ctx.Tr("repo.projects.automation.invalid_rule"))
+		ctx.Redirect(p.Link() + "/automation")
+		return
+	}
+	if trigger != project_model.AutomationTriggerIssueLabeled {
+		form.LabelID = 0
+	}
+
+	if err := models.NewProjectAutomationRule(ctx, p, &project_model.AutomationRule{
+		TriggerType: trigger,
+		LabelID:     form.LabelID,
+		BoardID:     form.BoardID,
+		CreatorID:   ctx.Doer.ID,
 	}); err != nil {
-		ctx.ServerError("NewProject", err)
+		if project_model.IsErrInvalidAutomationRule(err) {
+			ctx.Flash.Error(ctx.Tr("repo.projects.automation.invalid_rule"))
+			ctx.Redirect(p.Link() + "/automation")
+			return
+		}
+		ctx.ServerError("NewProjectAutomationRule", err)
 		return
 	}
 
-	ctx.Flash.Success(ctx.Tr("repo.projects.create_success", form.Title))
-	ctx.Redirect(setting.AppSubURL + "/")
+	ctx.Flash.Success(ctx.Tr("repo.projects.automation.add_success"))
+	ctx.Redirect(p.Link() + "/automation")
+}
+
+// DeleteProjectAutomationRule deletes an automation rule of a project
+func DeleteProjectAutomationRule(ctx *context.Context) {
+	p := getRepoProject(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	if err := project_model.DeleteAutomationRule(ctx, p.ID, ctx.ParamsInt64(":ruleID")); err != nil {
+		ctx.ServerError("DeleteAutomationRule", err)
+		return
+	}
+
+	ctx.Flash.Success(ctx.Tr("repo.projects.automation.delete_success"))
+	ctx.Redirect(p.Link() + "/automation")
 }
@@ -20,6 +20,7 @@ import (
 	"code.gitea.io/gitea/models/db"
 	"code.gitea.io/gitea/models/organization"
 	access_model "code.gitea.io/gitea/models/perm/access"
+	pull_model "code.gitea.io/gitea/models/pull"
 	repo_model "code.gitea.io/gitea/models/repo"
 	"code.gitea.io/gitea/models/unit"
 	user_model "code.gitea.io/gitea/models/user"
@@ -38,15 +39,18 @@ import (
 	asymkey_service "code.gitea.io/gitea/services/asymkey"
 	"code.gitea.io/gitea/services/forms"
 	"code.gitea.io/gitea/services/gitdiff"
+	"code.gitea.io/gitea/services/mergequeue"
 	pull_service "code.gitea.io/gitea/services/pull"
 	repo_service "code.gitea.io/gitea/services/repository"
 )
 
 const (
-	tplFork        base.TplName = "repo/pulls/fork"
-	tplCompareDiff base.TplName = "repo/diff/compare"
-	tplPullCommits base.TplName = "repo/pulls/commits"
-	tplPullFiles   base.TplName = "repo/pulls/files"
+	tplFork           base.TplName = "repo/pulls/fork"
+	tplCompareDiff    base.TplName = "repo/diff/compare"
+	tplPullCommits    base.TplName = "repo/pulls/commits"
+	tplPullFiles      base.TplName = "repo/pulls/files"
+	tplPullIterations base.TplName = "repo/pulls/iterations"
+	tplPullConflicts  base.TplName = "repo/pulls/conflicts"
 
 	pullRequestTemplateKey = "PullRequestTemplate"
 )
@@ -415,6 +419,21 @@ func PrepareViewPullInfo(ctx *context.Context, issue *models.Issue) *git.Compare
 		return nil
 	}
 	ctx.Data["EnableStatusCheck"] = pull.ProtectedBranch != nil && pull.ProtectedBranch.EnableStatusCheck
+	ctx.Data["EnableMergeQueue"] = pull.ProtectedBranch != nil && pull.ProtectedBranch.EnableMergeQueue
+
+	if entry, err := pull_model.GetMergeQueueEntryByPullID(ctx, pull.ID); err == nil {
+		position, err := pull_model.GetMergeQueuePosition(ctx, entry)
+		if err != nil {
+			ctx.ServerError("GetMergeQueuePosition", err)
+			return nil
+		}
+		ctx.Data["MergeQueueEntry"] = entry
+		ctx.Data["MergeQueuePosition"] = position
+		ctx.Data["CanRemoveFromMergeQueue"] = ctx.IsSigned && (ctx.Doer.ID == entry.DoerID || ctx.Repo.IsAdmin())
+	} else if !db.IsErrNotExist(err) {
+		ctx.ServerError("GetMergeQueueEntryByPullID", err)
+		return nil
+	}
 
 	var baseGitRepo *git.Repository
 	if pull.BaseRepoID == ctx.Repo.Repository.ID && ctx.Repo.GitRepo != nil {
@@ -592,6 +611,7 @@ func PrepareViewPullInfo(ctx *context.Context, issue *models.Issue) *git.Compare
 	if pull.IsFilesConflicted() {
 		ctx.Data["IsPullFilesConflicted"] = true
 		ctx.Data["ConflictedFiles"] = pull.ConflictedFiles
+		ctx.Data["CanResolveConflicts"] = ctx.Data["UpdateAllowed"] == true
 	}
 
 	ctx.Data["NumCommits"] = len(compareInfo.Commits)
@@ -720,6 +740,11 @@ func ViewPullFiles(ctx *context.Context) {
 		return
 	}
 
+	if err = diff.LoadAnnotations(ctx, ctx.Repo.Repository.ID, endCommitID); err != nil {
+		ctx.ServerError("LoadAnnotations", err)
+		return
+	}
+
 	if err = pull.LoadProtectedBranch(); err != nil {
 		ctx.ServerError("LoadProtectedBranch", err)
 		return
@@ -966,6 +991,31 @@ func MergePullRequest(ctx *context.Context) {
 		message += "\n\n" + form.MergeMessageField
 	}
 
+	// with a merge queue the pull request gets merged once the checks of the queue succeed
+	queued, err := mergequeue.IsEnabled(ctx, pr)
+	if err != nil {
+		ctx.ServerError("IsEnabled", err)
+		return
+	}
+	if queued {
+		if err := mergequeue.Add(ctx, ctx.Doer, pr, repo_model.MergeStyle(form.Do), form.HeadCommitID, message); err != nil {
+			if pull_model.IsErrAlreadyInMergeQueue(err) {
+				ctx.Flash.Error(ctx.Tr("repo.pulls.merge_queue.already_queued"))
+			} else if models.IsErrInvalidMergeStyle(err) {
+				ctx.Flash.Error(ctx.Tr("repo.pulls.invalid_merge_option"))
+			} else if models.IsErrSHADoesNotMatch(err) {
+				ctx.Flash.Error(ctx.Tr("repo.pulls.head_out_of_date"))
+			} else {
+				ctx.ServerError("Add", err)
+				return
+			}
+		} else {
+			ctx.Flash.Success(ctx.Tr("repo.pulls.merge_queue.queued"))
+		}
+		ctx.Redirect(issue.Link())
+		return
+	}
+
 	if err := pull_service.Merge(ctx, pr, ctx.Doer, ctx.Repo.GitRepo, repo_model.MergeStyle(form.Do), form.HeadCommitID, message); err != nil {
 		if models.IsErrInvalidMergeStyle(err) {
 			ctx.Flash.Error(ctx.Tr("repo.pulls.invalid_merge_option"))
@@ -1000,10 +1050,17 @@ func MergePullRequest(ctx *context.Context) {
 			log.Debug("MergeUnrelatedHistories error: %v", err)
 			ctx.Flash.Error(ctx.Tr("repo.pulls.unrelated_histories"))
 			ctx.Redirect(issue.Link())
+		} else if models.IsErrMergeDivergingFastForwardOnly(err) {
+			log.Debug("MergeDivergingFastForwardOnly error: %v", err)
+			ctx.Flash.Error(ctx.Tr("repo.pulls.merge_diverging_fast_forward_only"))
+			ctx.Redirect(issue.Link())
 		} else if git.IsErrPushOutOfDate(err) {
 			log.Debug("MergePushOutOfDate error: %v", err)
 			ctx.Flash.Error(ctx.Tr("repo.pulls.merge_out_of_date"))
 			ctx.Redirect(issue.Link())
+		} else if errors.Is(err, pull_service.ErrMergeQueueRequired) {
+			ctx.Flash.Error(ctx.Tr("repo.pulls.merge_queue.required"))
+			ctx.Redirect(issue.Link())
 		} else if models.IsErrSHADoesNotMatch(err) {
 			log.Debug("MergeHeadOutOfDate error: %v", err)
 			ctx.Flash.Error(ctx.Tr("repo.pulls.head_out_of_date"))
@@ -1070,6 +1127,37 @@ func MergePullRequest(ctx *context.Context) {
 	ctx.Redirect(issue.Link())
 }
 
+// RemoveFromMergeQueue removes a pull request from the merge queue of its base branch
+func RemoveFromMergeQueue(ctx *context.Context) {
+	issue := checkPullInfo(ctx)
+	if ctx.Written() {
+		return
+	}
+	pr := issue.PullRequest
+
+	entry, err := pull_model.GetMergeQueueEntryByPullID(ctx, pr.ID)
+	if err != nil {
+		if db.IsErrNotExist(err) {
+			ctx.NotFound("GetMergeQueueEntryByPullID", err)
+		} else {
+			ctx.ServerError("GetMergeQueueEntryByPullID", err)
+		}
+		return
+	}
+
+	if ctx.Doer.ID != entry.DoerID && !ctx.Repo.IsAdmin() {
+		ctx.NotFound("RemoveFromMergeQueue", nil)
+		return
+	}
+
+	if err := mergequeue.Remove(ctx, ctx.Doer, pr); err != nil {
+		ctx.ServerError("Remove", err)
+		return
+	}
+
+	ctx.Redirect(issue.Link())
+}
+
 func stopTimerIfAvailable(user *user_model.User, issue *models.Issue) error {
 	if models.StopwatchExists(user.ID, issue.ID) {
 		if err := models.CreateOrStopIssueStopwatch(user, issue); err != nil {
@@ -0,0 +1,125 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"fmt"
+	"net/http"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/git"
+	pull_service "code.gitea.io/gitea/services/pull"
+)
+
+// ViewPullConflicts shows the conflicted files of a pull request and lets the user resolve them
+func ViewPullConflicts(ctx *context.Context) {
+	ctx.Data["PageIsPullList"] = true
+
+	issue := checkPullInfo(ctx)
+	if ctx.Written() {
+		return
+	}
+	pull := issue.PullRequest
+	if issue.IsClosed || pull.HasMerged {
+		ctx.NotFound("ViewPullConflicts", nil)
+		return
+	}
+
+	prInfo := PrepareViewPullInfo(ctx, issue)
+	if ctx.Written() {
+		return
+	} else if prInfo == nil {
+		ctx.NotFound("ViewPullConflicts", nil)
+		return
+	}
+	if !pull.IsFilesConflicted() {
+		ctx.Redirect(issue.Link())
+		return
+	}
+	if ctx.Data["CanResolveConflicts"] != true {
+		ctx.NotFound("ViewPullConflicts", nil)
+		return
+	}
+	getBranchData(ctx, issue)
+
+	conflicts, headCommitID, err := pull_service.GetConflictedFiles(ctx, pull)
+	if err != nil {
+		ctx.ServerError("GetConflictedFiles", err)
+		return
+	}
+	ctx.Data["Conflicts"] = conflicts
+	ctx.Data["HeadCommitID"] = headCommitID
+	ctx.Data["DefaultCommitMessage"] = fmt.Sprintf("Merge branch '%s' into %s", pull.BaseBranch, pull.HeadBranch)
+
+	ctx.HTML(http.StatusOK, tplPullConflicts)
+}
+
+// ResolvePullConflicts commits the resolution of the conflicted files of a pull request to its head branch
+func ResolvePullConflicts(ctx *context.Context) {
+	issue := checkPullInfo(ctx)
+	if ctx.Written() {
+		return
+	}
+	pull := issue.PullRequest
+	if issue.IsClosed || pull.HasMerged {
+		ctx.NotFound("ResolvePullConflicts", nil)
+		return
+	}
+
+	if err := pull.LoadBaseRepoCtx(ctx); err != nil {
+		ctx.ServerError("LoadBaseRepo", err)
+		return
+	}
+	if err := pull.LoadHeadRepoCtx(ctx); err != nil {
+		ctx.ServerError("LoadHeadRepo", err)
+		return
+	}
+	if pull.HeadRepo == nil {
+		ctx.NotFound("ResolvePullConflicts", nil)
+		return
+	}
+
+	allowed, _, err := pull_service.IsUserAllowedToUpdate(ctx, pull, ctx.Doer)
+	if err != nil {
+		ctx.ServerError("IsUserAllowedToUpdate", err)
+		return
+	}
+	if !allowed {
+		ctx.Flash.Error(ctx.Tr("repo.pulls.update_not_allowed"))
+		ctx.Redirect(issue.Link())
+		return
+	}
+
+	treePaths := ctx.FormStrings("tree_path")
+	contents := ctx.FormStrings("content")
+	if len(treePaths) != len(contents) {
+		ctx.Error(http.StatusBadRequest, "content")
+		return
+	}
+	resolutions := make(map[string]string, len(treePaths))
+	for i, treePath := range treePaths {
+		resolutions[treePath] = contents[i]
+	}
+
+	if _, err := pull_service.ResolveConflicts(ctx, ctx.Doer, pull, ctx.FormString("head_commit_id"), resolutions, ctx.FormString("message")); err != nil {
+		switch {
+		case models.IsErrConflictNotResolved(err):
+			ctx.Flash.Error(ctx.Tr("repo.pulls.conflicts.not_resolved", err.(models.ErrConflictNotResolved).TreePath))
+		case git.IsErrPushOutOfDate(err):
+			ctx.Flash.Error(ctx.Tr("repo.pulls.conflicts.out_of_date"))
+		case models.IsErrUserCannotCommit(err), git.IsErrPushRejected(err):
+			ctx.Flash.Error(ctx.Tr("repo.pulls.update_not_allowed"))
+		default:
+			ctx.ServerError("ResolveConflicts", err)
+			return
+		}
+		ctx.Redirect(issue.Link() + "/conflicts")
+		return
+	}
+
+	ctx.Flash.Success(ctx.Tr("repo.pulls.conflicts.resolved"))
+	ctx.Redirect(issue.Link())
+}
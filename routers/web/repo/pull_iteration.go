@@ -0,0 +1,115 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"net/http"
+
+	pull_model "code.gitea.io/gitea/models/pull"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/services/gitdiff"
+	pull_service "code.gitea.io/gitea/services/pull"
+)
+
+// ViewPullIterations lists the iterations of a pull request and shows the changes between two of them
+func ViewPullIterations(ctx *context.Context) {
+	ctx.Data["PageIsPullList"] = true
+	ctx.Data["PageIsPullIterations"] = true
+
+	issue := checkPullInfo(ctx)
+	if ctx.Written() {
+		return
+	}
+	pull := issue.PullRequest
+
+	var prInfo *git.CompareInfo
+	if pull.HasMerged {
+		prInfo = PrepareMergedViewPullInfo(ctx, issue)
+	} else {
+		prInfo = PrepareViewPullInfo(ctx, issue)
+	}
+	if ctx.Written() {
+		return
+	} else if prInfo == nil {
+		ctx.NotFound("ViewPullIterations", nil)
+		return
+	}
+
+	ctx.Data["Username"] = ctx.Repo.Owner.Name
+	ctx.Data["Reponame"] = ctx.Repo.Repository.Name
+	getBranchData(ctx, issue)
+
+	iterations, err := pull_model.GetIterations(ctx, pull.ID)
+	if err != nil {
+		ctx.ServerError("GetIterations", err)
+		return
+	}
+	for _, it := range iterations {
+		if err := it.LoadPusher(ctx); err != nil {
+			ctx.ServerError("LoadPusher", err)
+			return
+		}
+	}
+	ctx.Data["Iterations"] = iterations
+
+	if len(iterations) < 2 {
+		ctx.Data["DiffNotAvailable"] = true
+		ctx.HTML(http.StatusOK, tplPullIterations)
+		return
+	}
+
+	to := ctx.FormInt64("to")
+	if to <= 0 {
+		to = iterations[len(iterations)-1].Index
+	}
+	from := ctx.FormInt64("from")
+	if from <= 0 {
+		from = to - 1
+	}
+
+	cmp, err := pull_service.CompareIterations(ctx, ctx.Repo.GitRepo, pull, from, to)
+	if err != nil {
+		if pull_model.IsErrIterationNotExist(err) {
+			ctx.NotFound("CompareIterations", err)
+		} else {
+			ctx.ServerError("CompareIterations", err)
+		}
+		return
+	}
+	ctx.Data["Comparison"] = cmp
+	ctx.Data["AfterCommitID"] = cmp.To.HeadCommitID
+
+	diff, err := gitdiff.GetDiff(ctx.Repo.GitRepo, &gitdiff.DiffOptions{
+		BeforeCommitID:     cmp.From.HeadCommitID,
+		AfterCommitID:      cmp.To.HeadCommitID,
+		SkipTo:             ctx.FormString("skip-to"),
+		MaxLines:           setting.Git.MaxGitDiffLines,
+		MaxLineCharacters:  setting.Git.MaxGitDiffLineCharacters,
+		MaxFiles:           setting.Git.MaxGitDiffFiles,
+		WhitespaceBehavior: gitdiff.GetWhitespaceFlag(ctx.Data["WhitespaceBehavior"].(string)),
+	})
+	if err != nil {
+		ctx.ServerError("GetDiff", err)
+		return
+	}
+	ctx.Data["Diff"] = diff
+	ctx.Data["DiffNotAvailable"] = diff.NumFiles == 0
+
+	fromCommit, err := ctx.Repo.GitRepo.GetCommit(cmp.From.HeadCommitID)
+	if err != nil {
+		ctx.ServerError("GetCommit", err)
+		return
+	}
+	toCommit, err := ctx.Repo.GitRepo.GetCommit(cmp.To.HeadCommitID)
+	if err != nil {
+		ctx.ServerError("GetCommit", err)
+		return
+	}
+	setCompareContext(ctx, fromCommit, toCommit, ctx.Repo.Owner.Name, ctx.Repo.Repository.Name)
+
+	ctx.HTML(http.StatusOK, tplPullIterations)
+}
@@ -7,17 +7,20 @@ package repo
 import (
 	"fmt"
 	"net/http"
+	"strings"
 
 	"code.gitea.io/gitea/models"
 	pull_model "code.gitea.io/gitea/models/pull"
 	"code.gitea.io/gitea/modules/base"
 	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/git"
 	"code.gitea.io/gitea/modules/json"
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/web"
 	"code.gitea.io/gitea/services/forms"
 	pull_service "code.gitea.io/gitea/services/pull"
+	files_service "code.gitea.io/gitea/services/repository/files"
 )
 
 const (
@@ -173,6 +176,21 @@ func renderConversation(ctx *context.Context, comment *models.Comment) {
 		return
 	}
 	ctx.Data["AfterCommitID"] = pullHeadCommitID
+	if err := comment.Issue.PullRequest.LoadBaseRepoCtx(ctx); err != nil {
+		ctx.ServerError("LoadBaseRepo", err)
+		return
+	}
+	if err := comment.Issue.PullRequest.LoadHeadRepoCtx(ctx); err != nil {
+		ctx.ServerError("LoadHeadRepo", err)
+		return
+	}
+	if comment.Issue.PullRequest.HeadRepo != nil {
+		ctx.Data["UpdateAllowed"], _, err = pull_service.IsUserAllowedToUpdate(ctx, comment.Issue.PullRequest, ctx.Doer)
+		if err != nil {
+			ctx.ServerError("IsUserAllowedToUpdate", err)
+			return
+		}
+	}
 	ctx.HTML(http.StatusOK, tplConversation)
 }
 
@@ -288,3 +306,76 @@ func UpdateViewedFiles(ctx *context.Context) {
 		ctx.ServerError("UpdateReview", err)
 	}
 }
+
+// ApplySuggestions commits the suggestions of the selected code comments onto the head branch of a pull request
+func ApplySuggestions(ctx *context.Context) {
+	issue := checkPullInfo(ctx)
+	if ctx.Written() {
+		return
+	}
+	pull := issue.PullRequest
+	if issue.IsClosed || pull.HasMerged {
+		ctx.NotFound("ApplySuggestions", nil)
+		return
+	}
+	redirectTo := issue.Link() + "/files"
+
+	if err := pull.LoadBaseRepoCtx(ctx); err != nil {
+		ctx.ServerError("LoadBaseRepo", err)
+		return
+	}
+	if err := pull.LoadHeadRepoCtx(ctx); err != nil {
+		ctx.ServerError("LoadHeadRepo", err)
+		return
+	}
+	if pull.HeadRepo == nil {
+		ctx.NotFound("ApplySuggestions", nil)
+		return
+	}
+
+	allowed, _, err := pull_service.IsUserAllowedToUpdate(ctx, pull, ctx.Doer)
+	if err != nil {
+		ctx.ServerError("IsUserAllowedToUpdate", err)
+		return
+	}
+	if !allowed {
+		ctx.Flash.Error(ctx.Tr("repo.diff.suggestion.not_allowed"))
+		ctx.RedirectToFirst(ctx.FormString("redirect_to"), redirectTo)
+		return
+	}
+
+	ids, err := base.StringsToInt64s(strings.Split(ctx.FormString("comment_ids"), ","))
+	if err != nil {
+		ctx.Error(http.StatusBadRequest, "comment_ids")
+		return
+	}
+	commentIDs := make([]int64, 0, len(ids))
+	seen := make(map[int64]bool, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			commentIDs = append(commentIDs, id)
+		}
+	}
+
+	_, applied, err := files_service.ApplyCodeSuggestions(ctx, ctx.Doer, pull, commentIDs, ctx.FormString("message"))
+	if err != nil {
+		switch {
+		case models.IsErrCommentNotExist(err):
+			ctx.NotFound("ApplyCodeSuggestions", err)
+			return
+		case models.IsErrSuggestionNotApplicable(err), git.IsErrPushOutOfDate(err):
+			ctx.Flash.Error(ctx.Tr("repo.diff.suggestion.not_applicable"))
+		case models.IsErrUserCannotCommit(err), git.IsErrPushRejected(err):
+			ctx.Flash.Error(ctx.Tr("repo.diff.suggestion.not_allowed"))
+		default:
+			ctx.ServerError("ApplyCodeSuggestions", err)
+			return
+		}
+		ctx.RedirectToFirst(ctx.FormString("redirect_to"), redirectTo)
+		return
+	}
+
+	ctx.Flash.Success(ctx.Tr("repo.diff.suggestion.applied", applied))
+	ctx.RedirectToFirst(ctx.FormString("redirect_to"), redirectTo)
+}
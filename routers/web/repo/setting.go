@@ -470,16 +470,18 @@ func SettingsPost(ctx *context.Context) {
 				RepoID: repo.ID,
 				Type:   unit_model.TypePullRequests,
 				Config: &repo_model.PullRequestsConfig{
-					IgnoreWhitespaceConflicts:     form.PullsIgnoreWhitespace,
-					AllowMerge:                    form.PullsAllowMerge,
-					AllowRebase:                   form.PullsAllowRebase,
-					AllowRebaseMerge:              form.PullsAllowRebaseMerge,
-					AllowSquash:                   form.PullsAllowSquash,
-					AllowManualMerge:              form.PullsAllowManualMerge,
-					AutodetectManualMerge:         form.EnableAutodetectManualMerge,
-					AllowRebaseUpdate:             form.PullsAllowRebaseUpdate,
-					DefaultDeleteBranchAfterMerge: form.DefaultDeleteBranchAfterMerge,
-					DefaultMergeStyle:             repo_model.MergeStyle(form.PullsDefaultMergeStyle),
+					IgnoreWhitespaceConflicts:      form.PullsIgnoreWhitespace,
+					AllowMerge:                     form.PullsAllowMerge,
+					AllowRebase:                    form.PullsAllowRebase,
+					AllowRebaseMerge:               form.PullsAllowRebaseMerge,
+					AllowSquash:                    form.PullsAllowSquash,
+					AllowFastForwardOnly:           form.PullsAllowFastForwardOnly,
+					AllowManualMerge:               form.PullsAllowManualMerge,
+					AutodetectManualMerge:          form.EnableAutodetectManualMerge,
+					AllowRebaseUpdate:              form.PullsAllowRebaseUpdate,
+					DefaultDeleteBranchAfterMerge:  form.DefaultDeleteBranchAfterMerge,
+					DefaultMergeStyle:              repo_model.MergeStyle(form.PullsDefaultMergeStyle),
+					RebaseDependentPullsAfterMerge: form.RebaseDependentPullsAfterMerge,
 				},
 			})
 		} else if !unit_model.TypePullRequests.UnitGlobalDisabled() {
@@ -261,6 +261,8 @@ func SettingsProtectedBranchPost(ctx *context.Context) {
 		protectBranch.ProtectedFilePatterns = f.ProtectedFilePatterns
 		protectBranch.UnprotectedFilePatterns = f.UnprotectedFilePatterns
 		protectBranch.BlockOnOutdatedBranch = f.BlockOnOutdatedBranch
+		protectBranch.EnableMergeQueue = f.EnableMergeQueue
+		protectBranch.RequireCodeOwnerApproval = f.RequireCodeOwnerApproval
 
 		err = models.UpdateProtectBranch(ctx, ctx.Repo.Repository, protectBranch, models.WhitelistOptions{
 			UserIDs:          whitelistUsers,
@@ -218,6 +218,7 @@ func Profile(ctx *context.Context) {
 		total = int(count)
 	case "projects":
 		ctx.Data["OpenProjects"], _, err = project_model.GetProjects(project_model.SearchOptions{
+			OwnerID:  ctx.ContextUser.ID,
 			Page:     -1,
 			IsClosed: util.OptionalBoolFalse,
 			Type:     project_model.TypeIndividual,
@@ -0,0 +1,510 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package user
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/models/perm"
+	project_model "code.gitea.io/gitea/models/project"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/json"
+	"code.gitea.io/gitea/modules/markup"
+	"code.gitea.io/gitea/modules/markup/markdown"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/util"
+	"code.gitea.io/gitea/modules/web"
+	"code.gitea.io/gitea/services/forms"
+)
+
+const (
+	tplProjects           base.TplName = "user/overview/projects"
+	tplProjectsNew        base.TplName = "user/overview/project_new"
+	tplProjectsView       base.TplName = "user/overview/project_view"
+	tplProjectsAutomation base.TplName = "user/overview/project_automation"
+)
+
+func projectsLink(ctx *context.Context) string {
+	return ctx.ContextUser.HomeLink() + "/-/projects"
+}
+
+func renderProjectDescription(ctx *context.Context, p *project_model.Project) (err error) {
+	p.RenderedContent, err = markdown.RenderString(&markup.RenderContext{
+		URLPrefix: ctx.ContextUser.HomeLink(),
+		Ctx:       ctx,
+	}, p.Description)
+	return err
+}
+
+// Projects renders the projects of the context user
+func Projects(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("repo.project_board")
+
+	sortType := ctx.FormTrim("sort")
+
+	isShowClosed := strings.ToLower(ctx.FormTrim("state")) == "closed"
+	page := ctx.FormInt("page")
+	if page <= 1 {
+		page = 1
+	}
+
+	projects, total, err := project_model.GetProjects(project_model.SearchOptions{
+		OwnerID:  ctx.ContextUser.ID,
+		Page:     page,
+		IsClosed: util.OptionalBoolOf(isShowClosed),
+		SortType: sortType,
+	})
+	if err != nil {
+		ctx.ServerError("GetProjects", err)
+		return
+	}
+
+	otherCount, err := project_model.CountProjects(ctx, project_model.SearchOptions{
+		OwnerID:  ctx.ContextUser.ID,
+		IsClosed: util.OptionalBoolOf(!isShowClosed),
+	})
+	if err != nil {
+		ctx.ServerError("CountProjects", err)
+		return
+	}
+	if isShowClosed {
+		ctx.Data["OpenCount"] = otherCount
+		ctx.Data["ClosedCount"] = total
+		ctx.Data["State"] = "closed"
+	} else {
+		ctx.Data["OpenCount"] = total
+		ctx.Data["ClosedCount"] = otherCount
+		ctx.Data["State"] = "open"
+	}
+
+	for _, p := range projects {
+		p.Owner = ctx.ContextUser
+		if err := renderProjectDescription(ctx, p); err != nil {
+			ctx.ServerError("RenderString", err)
+			return
+		}
+	}
+	ctx.Data["Projects"] = projects
+
+	pager := context.NewPagination(int(total), setting.UI.IssuePagingNum, page, 5)
+	pager.AddParam(ctx, "state", "State")
+	ctx.Data["Page"] = pager
+
+	ctx.Data["ContextUser"] = ctx.ContextUser
+	ctx.Data["CanWriteProjects"] = ctx.Project.AccessMode >= perm.AccessModeWrite
+	ctx.Data["IsShowClosed"] = isShowClosed
+	ctx.Data["IsProjectsPage"] = true
+	ctx.Data["SortType"] = sortType
+
+	ctx.HTML(http.StatusOK, tplProjects)
+}
+
+// NewProject renders the page to create a project of the context user
+func NewProject(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("repo.projects.new")
+	ctx.Data["ContextUser"] = ctx.ContextUser
+	ctx.Data["IsProjectsPage"] = true
+	ctx.Data["ProjectTypes"] = project_model.GetProjectsConfig()
+	ctx.Data["ProjectsLink"] = projectsLink(ctx)
+	ctx.HTML(http.StatusOK, tplProjectsNew)
+}
+
+// NewProjectPost creates a project of the context user
+func NewProjectPost(ctx *context.Context) {
+	form := web.GetForm(ctx).(*forms.CreateProjectForm)
+	ctx.Data["Title"] = ctx.Tr("repo.projects.new")
+	ctx.Data["ContextUser"] = ctx.ContextUser
+	ctx.Data["IsProjectsPage"] = true
+	ctx.Data["ProjectsLink"] = projectsLink(ctx)
+
+	if ctx.HasError() {
+		ctx.Data["ProjectTypes"] = project_model.GetProjectsConfig()
+		ctx.HTML(http.StatusOK, tplProjectsNew)
+		return
+	}
+
+	projectType := project_model.TypeIndividual
+	if ctx.ContextUser.IsOrganization() {
+		projectType = project_model.TypeOrganization
+	}
+
+	if err := project_model.NewProject(&project_model.Project{
+		OwnerID:     ctx.ContextUser.ID,
+		Title:       form.Title,
+		Description: form.Content,
+		CreatorID:   ctx.Doer.ID,
+		BoardType:   form.BoardType,
+		Type:        projectType,
+	}); err != nil {
+		ctx.ServerError("NewProject", err)
+		return
+	}
+
+	ctx.Flash.Success(ctx.Tr("repo.projects.create_success", form.Title))
+	ctx.Redirect(projectsLink(ctx))
+}
+
+// ChangeProjectStatus updates the status of a project between "open" and "close"
+func ChangeProjectStatus(ctx *context.Context) {
+	var toClose bool
+	switch ctx.Params(":action") {
+	case "open":
+		toClose = false
+	case "close":
+		toClose = true
+	default:
+		ctx.Redirect(projectsLink(ctx))
+		return
+	}
+
+	if err := project_model.ChangeProjectStatus(ctx.Project.Project, toClose); err != nil {
+		ctx.ServerError("ChangeProjectStatus", err)
+		return
+	}
+	ctx.Redirect(projectsLink(ctx) + "?state=" + url.QueryEscape(ctx.Params(":action")))
+}
+
+// DeleteProject deletes a project
+func DeleteProject(ctx *context.Context) {
+	if err := project_model.DeleteProjectByID(ctx.Project.Project.ID); err != nil {
+		ctx.Flash.Error("DeleteProjectByID: " + err.Error())
+	} else {
+		ctx.Flash.Success(ctx.Tr("repo.projects.deletion_success"))
+	}
+
+	ctx.JSON(http.StatusOK, map[string]interface{}{
+		"redirect": projectsLink(ctx),
+	})
+}
+
+// EditProject renders the page to edit a project
+func EditProject(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("repo.projects.edit")
+	ctx.Data["PageIsEditProjects"] = true
+	ctx.Data["ContextUser"] = ctx.ContextUser
+	ctx.Data["IsProjectsPage"] = true
+	ctx.Data["ProjectsLink"] = projectsLink(ctx)
+
+	ctx.Data["title"] = ctx.Project.Project.Title
+	ctx.Data["content"] = ctx.Project.Project.Description
+
+	ctx.HTML(http.StatusOK, tplProjectsNew)
+}
+
+// EditProjectPost updates a project
+func EditProjectPost(ctx *context.Context) {
+	form := web.GetForm(ctx).(*forms.CreateProjectForm)
+	ctx.Data["Title"] = ctx.Tr("repo.projects.edit")
+	ctx.Data["PageIsEditProjects"] = true
+	ctx.Data["ContextUser"] = ctx.ContextUser
+	ctx.Data["IsProjectsPage"] = true
+	ctx.Data["ProjectsLink"] = projectsLink(ctx)
+
+	if ctx.HasError() {
+		ctx.HTML(http.StatusOK, tplProjectsNew)
+		return
+	}
+
+	p := ctx.Project.Project
+	p.Title = form.Title
+	p.Description = form.Content
+	if err := project_model.UpdateProject(p); err != nil {
+		ctx.ServerError("UpdateProject", err)
+		return
+	}
+
+	ctx.Flash.Success(ctx.Tr("repo.projects.edit_success", p.Title))
+	ctx.Redirect(projectsLink(ctx))
+}
+
+// ViewProject renders the boards of a project, only the issues the doer is allowed to read are shown
+func ViewProject(ctx *context.Context) {
+	project := ctx.Project.Project
+
+	boards, err := project_model.GetBoards(project.ID)
+	if err != nil {
+		ctx.ServerError("GetProjectBoards", err)
+		return
+	}
+
+	if boards[0].ID == 0 {
+		boards[0].Title = ctx.Tr("repo.projects.type.uncategorized")
+	}
+
+	issuesMap, err := models.LoadIssuesFromBoardList(ctx, boards, ctx.Doer)
+	if err != nil {
+		ctx.ServerError("LoadIssuesOfBoards", err)
+		return
+	}
+
+	linkedPrsMap, err := models.LoadLinkedPullsOfBoardIssues(ctx, issuesMap, ctx.Doer)
+	if err != nil {
+		ctx.ServerError("LoadLinkedPullsOfBoardIssues", err)
+		return
+	}
+
+	if err := renderProjectDescription(ctx, project); err != nil {
+		ctx.ServerError("RenderString", err)
+		return
+	}
+
+	ctx.Data["Title"] = project.Title
+	ctx.Data["ContextUser"] = ctx.ContextUser
+	ctx.Data["IsProjectsPage"] = true
+	ctx.Data["ProjectsLink"] = projectsLink(ctx)
+	ctx.Data["CanWriteProjects"] = ctx.Project.AccessMode >= perm.AccessModeWrite
+	ctx.Data["Project"] = project
+	ctx.Data["IssuesMap"] = issuesMap
+	ctx.Data["LinkedPRs"] = linkedPrsMap
+	ctx.Data["Boards"] = boards
+
+	ctx.HTML(http.StatusOK, tplProjectsView)
+}
+
+// AddBoardToProjectPost adds a board to a project
+func AddBoardToProjectPost(ctx *context.Context) {
+	form := web.GetForm(ctx).(*forms.EditProjectBoardForm)
+
+	if err := project_model.NewBoard(&project_model.Board{
+		ProjectID: ctx.Project.Project.ID,
+		Title:     form.Title,
+		Color:     form.Color,
+		CreatorID: ctx.Doer.ID,
+	}); err != nil {
+		ctx.ServerError("NewProjectBoard", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, map[string]interface{}{
+		"ok": true,
+	})
+}
+
+// getProjectBoard returns the board of the ":boardID" parameter if it belongs to the project of the context
+func getProjectBoard(ctx *context.Context) *project_model.Board {
+	board, err := project_model.GetBoard(ctx.ParamsInt64(":boardID"))
+	if err != nil {
+		if project_model.IsErrProjectBoardNotExist(err) {
+			ctx.NotFound("ProjectBoardNotExist", nil)
+		} else {
+			ctx.ServerError("GetProjectBoard", err)
+		}
+		return nil
+	}
+	if board.ProjectID != ctx.Project.Project.ID {
+		ctx.JSON(http.StatusUnprocessableEntity, map[string]string{
+			"message": fmt.Sprintf("ProjectBoard[%d] is not in Project[%d] as expected", board.ID, ctx.Project.Project.ID),
+		})
+		return nil
+	}
+	return board
+}
+
+// EditProjectBoard updates the title, color or sorting of a board
+func EditProjectBoard(ctx *context.Context) {
+	form := web.GetForm(ctx).(*forms.EditProjectBoardForm)
+	board := getProjectBoard(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	if form.Title != "" {
+		board.Title = form.Title
+	}
+
+	board.Color = form.Color
+
+	if form.Sorting != 0 {
+		board.Sorting = form.Sorting
+	}
+
+	if err := project_model.UpdateBoard(board); err != nil {
+		ctx.ServerError("UpdateProjectBoard", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, map[string]interface{}{
+		"ok": true,
+	})
+}
+
+// DeleteProjectBoard deletes a board, its issues become uncategorized
+func DeleteProjectBoard(ctx *context.Context) {
+	board := getProjectBoard(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	if err := project_model.DeleteBoardByID(board.ID); err != nil {
+		ctx.ServerError("DeleteProjectBoardByID", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, map[string]interface{}{
+		"ok": true,
+	})
+}
+
+// SetDefaultProjectBoard sets the board uncategorized issues are shown in
+func SetDefaultProjectBoard(ctx *context.Context) {
+	board := getProjectBoard(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	if err := project_model.SetDefaultBoard(ctx.Project.Project.ID, board.ID); err != nil {
+		ctx.ServerError("SetDefaultBoard", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, map[string]interface{}{
+		"ok": true,
+	})
+}
+
+// MoveIssues moves or keeps issues in a board and sorts them inside of it
+func MoveIssues(ctx *context.Context) {
+	var board *project_model.Board
+	if ctx.ParamsInt64(":boardID") == 0 {
+		board = &project_model.Board{
+			ID:        0,
+			ProjectID: ctx.Project.Project.ID,
+			Title:     ctx.Tr("repo.projects.type.uncategorized"),
+		}
+	} else {
+		board = getProjectBoard(ctx)
+		if ctx.Written() {
+			return
+		}
+	}
+
+	type movedIssuesForm struct {
+		Issues []struct {
+			IssueID int64 `json:"issueID"`
+			Sorting int64 `json:"sorting"`
+		} `json:"issues"`
+	}
+
+	form := &movedIssuesForm{}
+	if err := json.NewDecoder(ctx.Req.Body).Decode(&form); err != nil {
+		ctx.ServerError("DecodeMovedIssuesForm", err)
+		return
+	}
+
+	sortedIssueIDs := make(map[int64]int64, len(form.Issues))
+	for _, issue := range form.Issues {
+		sortedIssueIDs[issue.Sorting] = issue.IssueID
+	}
+
+	// MoveIssuesOnProjectBoard makes sure that all issues belong to the project
+	if err := project_model.MoveIssuesOnProjectBoard(board, sortedIssueIDs); err != nil {
+		ctx.ServerError("MoveIssuesOnProjectBoard", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, map[string]interface{}{
+		"ok": true,
+	})
+}
+
+// ProjectAutomation renders the automation rules of a project
+func ProjectAutomation(ctx *context.Context) {
+	p := ctx.Project.Project
+
+	ctx.Data["Title"] = ctx.Tr("repo.projects.automation")
+	ctx.Data["ContextUser"] = ctx.ContextUser
+	ctx.Data["IsProjectsPage"] = true
+	ctx.Data["Project"] = p
+	ctx.Data["AutomationTriggers"] = project_model.AutomationTriggers()
+
+	rules, err := project_model.GetAutomationRules(ctx, p.ID)
+	if err != nil {
+		ctx.ServerError("GetAutomationRules", err)
+		return
+	}
+	ctx.Data["AutomationRules"] = rules
+
+	boards, err := project_model.GetBoards(p.ID)
+	if err != nil {
+		ctx.ServerError("GetProjectBoards", err)
+		return
+	}
+	boardsMap := make(map[int64]*project_model.Board, len(boards))
+	for _, board := range boards {
+		boardsMap[board.ID] = board
+	}
+	// the uncategorized board has no ID, it cannot be the target of a rule
+	if len(boards) > 0 && boards[0].ID == 0 {
+		boards = boards[1:]
+	}
+	ctx.Data["Boards"] = boards
+	ctx.Data["BoardsMap"] = boardsMap
+
+	labels, err := models.GetLabelsOfProject(ctx, p)
+	if err != nil {
+		ctx.ServerError("GetLabelsOfProject", err)
+		return
+	}
+	labelsMap := make(map[int64]*models.Label, len(labels))
+	for _, label := range labels {
+		labelsMap[label.ID] = label
+	}
+	ctx.Data["Labels"] = labels
+	ctx.Data["LabelsMap"] = labelsMap
+
+	ctx.HTML(http.StatusOK, tplProjectsAutomation)
+}
+
+// NewProjectAutomationRulePost adds an automation rule to a project
+func NewProjectAutomationRulePost(ctx *context.Context) {
+	form := web.GetForm(ctx).(*forms.ProjectAutomationRuleForm)
+	p := ctx.Project.Project
+
+	trigger, ok := project_model.AutomationTriggerFromName(form.TriggerType)
+	if ctx.HasError() || !ok {
+		ctx.Flash.Error(ctx.Tr("repo.projects.automation.invalid_rule"))
+		ctx.Redirect(p.Link() + "/automation")
+		return
+	}
+	if trigger != project_model.AutomationTriggerIssueLabeled {
+		form.LabelID = 0
+	}
+
+	if err := models.NewProjectAutomationRule(ctx, p, &project_model.AutomationRule{
+		TriggerType: trigger,
+		LabelID:     form.LabelID,
+		BoardID:     form.BoardID,
+		CreatorID:   ctx.Doer.ID,
+	}); err != nil {
+		if project_model.IsErrInvalidAutomationRule(err) {
+			ctx.Flash.Error(ctx.Tr("repo.projects.automation.invalid_rule"))
+			ctx.Redirect(p.Link() + "/automation")
+			return
+		}
+		ctx.ServerError("NewProjectAutomationRule", err)
+		return
+	}
+
+	ctx.Flash.Success(ctx.Tr("repo.projects.automation.add_success"))
+	ctx.Redirect(p.Link() + "/automation")
+}
+
+// DeleteProjectAutomationRule deletes an automation rule of a project
+func DeleteProjectAutomationRule(ctx *context.Context) {
+	p := ctx.Project.Project
+
+	if err := project_model.DeleteAutomationRule(ctx, p.ID, ctx.ParamsInt64(":ruleID")); err != nil {
+		ctx.ServerError("DeleteAutomationRule", err)
+		return
+	}
+
+	ctx.Flash.Success(ctx.Tr("repo.projects.automation.delete_success"))
+	ctx.Redirect(p.Link() + "/automation")
+}
@@ -0,0 +1,47 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package setting
+
+import (
+	"net/http"
+
+	quota_model "code.gitea.io/gitea/models/quota"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+const tplSettingsStorage base.TplName = "user/settings/storage"
+
+// Storage render the storage usage of the user
+func Storage(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("settings.storage")
+	ctx.Data["PageIsSettingsStorage"] = true
+
+	if !LoadQuotaUsage(ctx, ctx.Doer.ID) {
+		return
+	}
+
+	ctx.HTML(http.StatusOK, tplSettingsStorage)
+}
+
+// LoadQuotaUsage loads the quota and storage usage of the owner into the context data
+func LoadQuotaUsage(ctx *context.Context, ownerID int64) bool {
+	q, err := quota_model.GetQuotaByOwnerID(ctx, ownerID)
+	if err != nil {
+		ctx.ServerError("GetQuotaByOwnerID", err)
+		return false
+	}
+	u, err := quota_model.GetUsageByOwnerID(ctx, ownerID)
+	if err != nil {
+		ctx.ServerError("GetUsageByOwnerID", err)
+		return false
+	}
+
+	ctx.Data["Quota"] = q
+	ctx.Data["QuotaUsage"] = u
+	ctx.Data["QuotaEnabled"] = setting.Quota.Enabled
+	return true
+}
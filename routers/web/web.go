@@ -433,6 +433,7 @@ func RegisterRoutes(m *web.Route) {
 		m.Get("/organization", user_setting.Organization)
 		m.Get("/repos", user_setting.Repos)
 		m.Post("/repos/unadopted", user_setting.AdoptOrDeleteRepository)
+		m.Get("/storage", user_setting.Storage)
 	}, reqSignIn, func(ctx *context.Context) {
 		ctx.Data["PageIsUserSettings"] = true
 		ctx.Data["AllThemes"] = setting.UI.Themes
@@ -597,6 +598,7 @@ func RegisterRoutes(m *web.Route) {
 	reqRepoIssuesOrPullsReader := context.RequireRepoReaderOr(unit.TypeIssues, unit.TypePullRequests)
 	reqRepoProjectsReader := context.RequireRepoReader(unit.TypeProjects)
 	reqRepoProjectsWriter := context.RequireRepoWriter(unit.TypeProjects)
+	reqRepoPackagesReader := context.RequireRepoReader(unit.TypePackages)
 
 	reqPackageAccess := func(accessMode perm.AccessMode) func(ctx *context.Context) {
 		return func(ctx *context.Context) {
@@ -606,6 +608,14 @@ func RegisterRoutes(m *web.Route) {
 		}
 	}
 
+	reqProjectAccess := func(accessMode perm.AccessMode) func(ctx *context.Context) {
+		return func(ctx *context.Context) {
+			if ctx.Project.AccessMode < accessMode {
+				ctx.NotFound("", nil)
+			}
+		}
+	}
+
 	// ***** START: Organization *****
 	m.Group("/org", func() {
 		m.Group("", func() {
@@ -686,6 +696,15 @@ func RegisterRoutes(m *web.Route) {
 					m.Post("/initialize", bindIgnErr(forms.InitializeLabelsForm{}), org.InitializeLabels)
 				})
 
+				m.Group("/custom_fields", func() {
+					m.Get("", org.CustomFields)
+					m.Post("", bindIgnErr(forms.CustomFieldForm{}), org.NewCustomFieldPost)
+					m.Get("/{id}", org.EditCustomField)
+					m.Post("/{id}", bindIgnErr(forms.CustomFieldForm{}), org.EditCustomFieldPost)
+					m.Post("/{id}/delete", org.DeleteCustomFieldPost)
+				})
+
+				m.Get("/storage", org.Storage)
 				m.Route("/delete", "GET,POST", org.SettingsDelete)
 			})
 		}, context.OrgAssignment(true, true))
@@ -723,6 +742,35 @@ func RegisterRoutes(m *web.Route) {
 				})
 			}, context.PackageAssignment(), reqPackageAccess(perm.AccessModeRead))
 		}
+
+		m.Group("/projects", func() {
+			m.Get("", user.Projects)
+			m.Get("/{id}", user.ViewProject)
+			m.Group("", func() {
+				m.Get("/new", user.NewProject)
+				m.Post("/new", bindIgnErr(forms.CreateProjectForm{}), user.NewProjectPost)
+				m.Group("/{id}", func() {
+					m.Post("", bindIgnErr(forms.EditProjectBoardForm{}), user.AddBoardToProjectPost)
+					m.Post("/delete", user.DeleteProject)
+
+					m.Get("/edit", user.EditProject)
+					m.Post("/edit", bindIgnErr(forms.CreateProjectForm{}), user.EditProjectPost)
+					m.Post("/{action:open|close}", user.ChangeProjectStatus)
+
+					m.Get("/automation", user.ProjectAutomation)
+					m.Post("/automation", bindIgnErr(forms.ProjectAutomationRuleForm{}), user.NewProjectAutomationRulePost)
+					m.Post("/automation/{ruleID}/delete", user.DeleteProjectAutomationRule)
+
+					m.Group("/{boardID}", func() {
+						m.Put("", bindIgnErr(forms.EditProjectBoardForm{}), user.EditProjectBoard)
+						m.Delete("", user.DeleteProjectBoard)
+						m.Post("/default", user.SetDefaultProjectBoard)
+
+						m.Post("/move", user.MoveIssues)
+					})
+				})
+			}, reqSignIn, reqProjectAccess(perm.AccessModeWrite))
+		}, context.ProjectAssignment(), reqProjectAccess(perm.AccessModeRead))
 	}, context_service.UserAssignmentWeb())
 
 	// ***** Release Attachment Download without Signin
@@ -760,6 +808,14 @@ func RegisterRoutes(m *web.Route) {
 				m.Post("/{id}", bindIgnErr(forms.ProtectTagForm{}), context.RepoMustNotBeArchived(), repo.EditProtectedTagPost)
 			})
 
+			m.Group("/custom_fields", func() {
+				m.Get("", repo.CustomFields)
+				m.Post("", bindIgnErr(forms.CustomFieldForm{}), repo.NewCustomFieldPost)
+				m.Get("/{id}", repo.EditCustomField)
+				m.Post("/{id}", bindIgnErr(forms.CustomFieldForm{}), repo.EditCustomFieldPost)
+				m.Post("/{id}/delete", repo.DeleteCustomFieldPost)
+			}, context.RepoMustNotBeArchived())
+
 			m.Group("/hooks/git", func() {
 				m.Get("", repo.GitHooks)
 				m.Combo("/{name}").Get(repo.GitHooksEdit).
@@ -874,6 +930,7 @@ func RegisterRoutes(m *web.Route) {
 				m.Post("/reactions/{action}", bindIgnErr(forms.ReactionForm{}), repo.ChangeIssueReaction)
 				m.Post("/lock", reqRepoIssueWriter, bindIgnErr(forms.IssueLockForm{}), repo.LockIssue)
 				m.Post("/unlock", reqRepoIssueWriter, repo.UnlockIssue)
+				m.Post("/custom_fields", reqRepoIssuesOrPullsWriter, repo.UpdateIssueCustomFields)
 				m.Post("/delete", reqRepoAdmin, repo.DeleteIssue)
 			}, context.RepoMustNotBeArchived())
 			m.Group("/{index}", func() {
@@ -1017,7 +1074,7 @@ func RegisterRoutes(m *web.Route) {
 		}, context.RepoRef())
 
 		if setting.Packages.Enabled {
-			m.Get("/packages", repo.Packages)
+			m.Get("/packages", reqRepoPackagesReader, repo.Packages)
 		}
 
 		m.Group("/projects", func() {
@@ -1034,6 +1091,10 @@ func RegisterRoutes(m *web.Route) {
 					m.Post("/edit", bindIgnErr(forms.CreateProjectForm{}), repo.EditProjectPost)
 					m.Post("/{action:open|close}", repo.ChangeProjectStatus)
 
+					m.Get("/automation", repo.ProjectAutomation)
+					m.Post("/automation", bindIgnErr(forms.ProjectAutomationRuleForm{}), repo.NewProjectAutomationRulePost)
+					m.Post("/automation/{ruleID}/delete", repo.DeleteProjectAutomationRule)
+
 					m.Group("/{boardID}", func() {
 						m.Put("", bindIgnErr(forms.EditProjectBoardForm{}), repo.EditProjectBoard)
 						m.Delete("", repo.DeleteProjectBoard)
@@ -1116,8 +1177,13 @@ func RegisterRoutes(m *web.Route) {
 			m.Get(".diff", repo.DownloadPullDiff)
 			m.Get(".patch", repo.DownloadPullPatch)
 			m.Get("/commits", context.RepoRef(), repo.ViewPullCommits)
+			m.Get("/iterations", context.RepoRef(), repo.SetDiffViewStyle, repo.SetWhitespaceBehavior, repo.ViewPullIterations)
 			m.Post("/merge", context.RepoMustNotBeArchived(), bindIgnErr(forms.MergePullRequestForm{}), repo.MergePullRequest)
 			m.Post("/update", repo.UpdatePullRequest)
+			m.Combo("/conflicts").Get(reqSignIn, context.RepoRef(), repo.ViewPullConflicts).
+				Post(reqSignIn, context.RepoMustNotBeArchived(), repo.ResolvePullConflicts)
+			m.Post("/merge_queue/remove", reqSignIn, repo.RemoveFromMergeQueue)
+			m.Post("/suggestions/apply", reqSignIn, context.RepoMustNotBeArchived(), repo.ApplySuggestions)
 			m.Post("/set_allow_maintainer_edit", bindIgnErr(forms.UpdateAllowEditsForm{}), repo.SetAllowEdits)
 			m.Post("/cleanup", context.RepoMustNotBeArchived(), context.RepoRef(), repo.CleanUpPullRequest)
 			m.Group("/files", func() {
@@ -1179,6 +1245,8 @@ func RegisterRoutes(m *web.Route) {
 		m.Group("", func() {
 			m.Get("/forks", repo.Forks)
 		}, context.RepoRef(), reqRepoCodeReader)
+		m.Get("/actions/jobs/{id}/logs", reqRepoCodeReader, repo.ActionJobLogs)
+		m.Get("/checks/{id}", reqRepoCodeReader, repo.CheckRun)
 		m.Get("/commit/{sha:([a-f0-9]{7,40})}.{ext:patch|diff}",
 			repo.MustBeNotEmpty, reqRepoCodeReader, repo.RawDiff)
 	}, ignSignIn, context.RepoAssignment, context.UnitTypes())
@@ -0,0 +1,15 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package actions
+
+import (
+	"code.gitea.io/gitea/modules/notification"
+)
+
+// Init registers the notifier which creates workflow runs
+func Init() error {
+	notification.RegisterNotifier(NewNotifier())
+	return nil
+}
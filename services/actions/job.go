@@ -0,0 +1,242 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package actions
+
+import (
+	"context"
+	"errors"
+
+	actions_model "code.gitea.io/gitea/models/actions"
+	"code.gitea.io/gitea/modules/json"
+	"code.gitea.io/gitea/modules/log"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/timeutil"
+)
+
+var (
+	// ErrJobNotAssigned indicates the job is not executed by the runner
+	ErrJobNotAssigned = errors.New("Job is not assigned to the runner")
+	// ErrJobDone indicates the job has already finished
+	ErrJobDone = errors.New("Job has already finished")
+	// ErrInvalidStatus indicates an unknown or not allowed status
+	ErrInvalidStatus = errors.New("Status is invalid")
+	// ErrLogConflict indicates a concurrent write to the log of a job
+	ErrLogConflict = errors.New("Log was written concurrently")
+	// ErrRunNotNeedApproval indicates the run has already been approved or never needed an approval
+	ErrRunNotNeedApproval = errors.New("Run does not need approval")
+)
+
+// PickJob assigns the next job the runner can execute to the runner
+// If there is no such job, nil is returned.
+func PickJob(ctx context.Context, runner *actions_model.ActionRunner) (*api.ActionRunnerJob, error) {
+	if err := actions_model.UpdateRunnerLastOnline(ctx, runner); err != nil {
+		return nil, err
+	}
+
+	job, err := actions_model.PickRunJob(ctx, runner)
+	if err != nil || job == nil {
+		return nil, err
+	}
+
+	run, err := actions_model.GetRunByID(ctx, job.RunID)
+	if err != nil {
+		return nil, err
+	}
+	if err := run.LoadRepo(ctx); err != nil {
+		return nil, err
+	}
+
+	if run.Status == actions_model.StatusWaiting {
+		run.Status = actions_model.StatusRunning
+		run.StartedUnix = timeutil.TimeStampNow()
+		if err := actions_model.UpdateRun(ctx, run, "status", "started_unix"); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := updateCommitStatus(ctx, run, job); err != nil {
+		log.Error("updateCommitStatus: %v", err)
+	}
+
+	return toRunnerJob(run, job)
+}
+
+func toRunnerJob(run *actions_model.ActionRun, job *actions_model.ActionRunJob) (*api.ActionRunnerJob, error) {
+	var payload jobPayload
+	if err := json.Unmarshal([]byte(job.Payload), &payload); err != nil {
+		return nil, err
+	}
+
+	steps := make([]*api.ActionRunnerStep, 0, len(payload.Steps))
+	for i, s := range payload.Steps {
+		steps = append(steps, &api.ActionRunnerStep{
+			Index: int64(i),
+			Name:  s.DisplayName(),
+			Run:   s.Run,
+			Uses:  s.Uses,
+			With:  s.With,
+			Env:   s.Env,
+		})
+	}
+
+	return &api.ActionRunnerJob{
+		ID:         job.ID,
+		RunID:      run.ID,
+		Repository: run.Repo.FullName(),
+		CloneURL:   run.Repo.CloneLink().HTTPS,
+		Workflow:   run.WorkflowID,
+		Event:      run.Event,
+		Ref:        run.Ref,
+		CommitSHA:  run.CommitSHA,
+		Name:       job.Name,
+		Token:      job.Token,
+		RunsOn:     job.RunsOn,
+		Env:        payload.Env,
+		Steps:      steps,
+	}, nil
+}
+
+// GetRunnerJob gets a job which is executed by the runner
+func GetRunnerJob(ctx context.Context, runner *actions_model.ActionRunner, jobID int64) (*actions_model.ActionRunJob, error) {
+	job, err := actions_model.GetRunJobByID(ctx, jobID)
+	if err != nil {
+		return nil, err
+	}
+	if job.RunnerID != runner.ID {
+		return nil, ErrJobNotAssigned
+	}
+	if job.Status.IsDone() {
+		return nil, ErrJobDone
+	}
+	return job, nil
+}
+
+// UpdateJob stores the state of the job and its steps reported by the runner
+// If the job is finished, the jobs which need it are scheduled or skipped.
+func UpdateJob(ctx context.Context, job *actions_model.ActionRunJob, opts *api.UpdateActionRunnerJobOption) error {
+	status, ok := actions_model.StatusFromString(opts.Status)
+	if !ok || (status != actions_model.StatusRunning && status != actions_model.StatusSuccess && status != actions_model.StatusFailure && status != actions_model.StatusCancelled) {
+		return ErrInvalidStatus
+	}
+
+	steps, err := actions_model.GetRunStepsByJobID(ctx, job.ID)
+	if err != nil {
+		return err
+	}
+	for _, so := range opts.Steps {
+		stepStatus, ok := actions_model.StatusFromString(so.Status)
+		if !ok || stepStatus < actions_model.StatusWaiting {
+			return ErrInvalidStatus
+		}
+		if so.Index < 0 || so.Index >= int64(len(steps)) {
+			continue
+		}
+
+		step := steps[so.Index]
+		if step.Status == stepStatus {
+			continue
+		}
+		step.Status = stepStatus
+		if step.StartedUnix == 0 && stepStatus >= actions_model.StatusRunning {
+			step.StartedUnix = timeutil.TimeStampNow()
+		}
+		if stepStatus.IsDone() {
+			step.StoppedUnix = timeutil.TimeStampNow()
+		}
+		if err := actions_model.UpdateRunStep(ctx, step, "status", "started_unix", "stopped_unix"); err != nil {
+			return err
+		}
+	}
+
+	if !status.IsDone() {
+		return nil
+	}
+
+	job.Status = status
+	job.StoppedUnix = timeutil.TimeStampNow()
+	job.RevokeToken()
+	if err := actions_model.UpdateRunJob(ctx, job, "status", "stopped_unix", "token_hash", "token_salt", "token_last_eight"); err != nil {
+		return err
+	}
+
+	run, err := actions_model.GetRunByID(ctx, job.RunID)
+	if err != nil {
+		return err
+	}
+
+	if err := updateCommitStatus(ctx, run, job); err != nil {
+		log.Error("updateCommitStatus: %v", err)
+	}
+
+	return resolveRun(ctx, run)
+}
+
+// resolveRun schedules or skips blocked jobs whose needed jobs are finished and
+// sets the status of the run when all jobs are finished
+func resolveRun(ctx context.Context, run *actions_model.ActionRun) error {
+	jobs, err := actions_model.GetRunJobsByRunID(ctx, run.ID)
+	if err != nil {
+		return err
+	}
+
+	byID := make(map[string]*actions_model.ActionRunJob, len(jobs))
+	for _, job := range jobs {
+		byID[job.JobID] = job
+	}
+
+	// Skipping a job may resolve the jobs which need it
+	for changed := true; changed; {
+		changed = false
+
+		for _, job := range jobs {
+			if job.Status != actions_model.StatusBlocked {
+				continue
+			}
+
+			status := actions_model.StatusWaiting
+			for _, need := range job.Needs {
+				needed, ok := byID[need]
+				if !ok || !needed.Status.IsDone() {
+					status = actions_model.StatusBlocked
+					break
+				}
+				if needed.Status != actions_model.StatusSuccess {
+					status = actions_model.StatusSkipped
+				}
+			}
+			if status == actions_model.StatusBlocked {
+				continue
+			}
+
+			job.Status = status
+			if status == actions_model.StatusSkipped {
+				job.StoppedUnix = timeutil.TimeStampNow()
+			}
+			if err := actions_model.UpdateRunJob(ctx, job, "status", "stopped_unix"); err != nil {
+				return err
+			}
+			if err := updateCommitStatus(ctx, run, job); err != nil {
+				log.Error("updateCommitStatus: %v", err)
+			}
+			changed = true
+		}
+	}
+
+	status := actions_model.StatusSuccess
+	for _, job := range jobs {
+		switch {
+		case !job.Status.IsDone():
+			return nil
+		case job.Status == actions_model.StatusFailure:
+			status = actions_model.StatusFailure
+		case job.Status == actions_model.StatusCancelled && status != actions_model.StatusFailure:
+			status = actions_model.StatusCancelled
+		}
+	}
+
+	run.Status = status
+	run.StoppedUnix = timeutil.TimeStampNow()
+	return actions_model.UpdateRun(ctx, run, "status", "stopped_unix")
+}
@@ -0,0 +1,55 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package actions
+
+import (
+	"context"
+	"io"
+	"os"
+
+	actions_model "code.gitea.io/gitea/models/actions"
+	"code.gitea.io/gitea/modules/storage"
+)
+
+// AppendJobLog stores the content as the next log chunk of the job
+func AppendJobLog(ctx context.Context, job *actions_model.ActionRunJob, r io.Reader) error {
+	chunk := job.LogChunks
+
+	ok, err := actions_model.IncrementLogChunks(ctx, job)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrLogConflict
+	}
+
+	_, err = storage.Actions.Save(job.LogChunkPath(chunk), r, -1)
+	return err
+}
+
+// WriteJobLog writes the log of the job
+// Chunks which are not stored (yet) are skipped.
+func WriteJobLog(job *actions_model.ActionRunJob, w io.Writer) error {
+	for _, p := range job.LogChunkPaths() {
+		if err := writeLogChunk(p, w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeLogChunk(p string, w io.Writer) error {
+	f, err := storage.Actions.Open(p)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	defer f.Close()
+
+	_, err = io.Copy(w, f)
+	return err
+}
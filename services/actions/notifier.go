@@ -0,0 +1,111 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package actions
+
+import (
+	"fmt"
+
+	"code.gitea.io/gitea/models"
+	access_model "code.gitea.io/gitea/models/perm/access"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/models/unit"
+	user_model "code.gitea.io/gitea/models/user"
+	actions_module "code.gitea.io/gitea/modules/actions"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/graceful"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/notification/base"
+	"code.gitea.io/gitea/modules/process"
+	"code.gitea.io/gitea/modules/repository"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+type actionsNotifier struct {
+	base.NullNotifier
+}
+
+var _ base.Notifier = &actionsNotifier{}
+
+// NewNotifier create a new actionsNotifier notifier
+func NewNotifier() base.Notifier {
+	return &actionsNotifier{}
+}
+
+func (n *actionsNotifier) NotifyPushCommits(pusher *user_model.User, repo *repo_model.Repository, opts *repository.PushUpdateOptions, commits *repository.PushCommits) {
+	if !setting.Actions.Enabled || opts.IsDelRef() {
+		return
+	}
+
+	ctx, _, finished := process.GetManager().AddContext(graceful.GetManager().HammerContext(), fmt.Sprintf("actions.NotifyPushCommits User: %s[%d] in %s[%d]", pusher.Name, pusher.ID, repo.FullName(), repo.ID))
+	defer finished()
+
+	if err := CreateRuns(ctx, &TriggerOptions{
+		Repo:      repo,
+		Doer:      pusher,
+		Event:     actions_module.EventPush,
+		Ref:       opts.RefFullName,
+		CommitSHA: opts.NewCommitID,
+	}); err != nil {
+		log.Error("CreateRuns: %v", err)
+	}
+}
+
+func (n *actionsNotifier) NotifyNewPullRequest(pr *models.PullRequest, mentions []*user_model.User) {
+	if !setting.Actions.Enabled {
+		return
+	}
+
+	ctx, _, finished := process.GetManager().AddContext(graceful.GetManager().HammerContext(), fmt.Sprintf("actions.NotifyNewPullRequest PR: %d", pr.ID))
+	defer finished()
+
+	if err := pr.LoadIssueCtx(ctx); err != nil {
+		log.Error("LoadIssue: %v", err)
+		return
+	}
+	if err := pr.Issue.LoadPoster(); err != nil {
+		log.Error("LoadPoster: %v", err)
+		return
+	}
+	if err := pr.LoadBaseRepoCtx(ctx); err != nil {
+		log.Error("LoadBaseRepo: %v", err)
+		return
+	}
+
+	gitRepo, closer, err := git.RepositoryFromContextOrOpen(ctx, pr.BaseRepo.RepoPath())
+	if err != nil {
+		log.Error("OpenRepository: %v", err)
+		return
+	}
+	defer closer.Close()
+
+	commitID, err := gitRepo.GetRefCommitID(pr.GetGitRefName())
+	if err != nil {
+		log.Error("GetRefCommitID: %v", err)
+		return
+	}
+
+	// the workflows of a fork run on the runners of the base repository only once someone who can write to it approves them
+	needApproval := false
+	if !pr.IsSameRepo() {
+		perm, err := access_model.GetUserRepoPermission(ctx, pr.BaseRepo, pr.Issue.Poster)
+		if err != nil {
+			log.Error("GetUserRepoPermission: %v", err)
+			return
+		}
+		needApproval = !perm.CanWrite(unit.TypeCode)
+	}
+
+	if err := CreateRuns(ctx, &TriggerOptions{
+		Repo:         pr.BaseRepo,
+		Doer:         pr.Issue.Poster,
+		Event:        actions_module.EventPullRequest,
+		Ref:          pr.GetGitRefName(),
+		CommitSHA:    commitID,
+		PullRequest:  pr,
+		NeedApproval: needApproval,
+	}); err != nil {
+		log.Error("CreateRuns: %v", err)
+	}
+}
@@ -0,0 +1,241 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package actions
+
+import (
+	"context"
+	"fmt"
+
+	"code.gitea.io/gitea/models"
+	actions_model "code.gitea.io/gitea/models/actions"
+	"code.gitea.io/gitea/models/db"
+	repo_model "code.gitea.io/gitea/models/repo"
+	user_model "code.gitea.io/gitea/models/user"
+	actions_module "code.gitea.io/gitea/modules/actions"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/json"
+	"code.gitea.io/gitea/modules/log"
+	api "code.gitea.io/gitea/modules/structs"
+	files_service "code.gitea.io/gitea/services/repository/files"
+)
+
+// TriggerOptions describes the event which triggers workflow runs
+type TriggerOptions struct {
+	Repo        *repo_model.Repository
+	Doer        *user_model.User
+	Event       string
+	Ref         string // the full name of the ref
+	CommitSHA   string
+	PullRequest *models.PullRequest
+	// the runs wait for the approval of a user with write access, e.g. for pull requests from forks
+	NeedApproval bool
+}
+
+// jobPayload is the part of a job definition which is handed to the runner
+type jobPayload struct {
+	Env   map[string]string      `json:"env,omitempty"`
+	Steps []*actions_module.Step `json:"steps"`
+}
+
+// CreateRuns creates a run for every workflow of the commit which is triggered by the event
+func CreateRuns(ctx context.Context, opts *TriggerOptions) error {
+	gitRepo, closer, err := git.RepositoryFromContextOrOpen(ctx, opts.Repo.RepoPath())
+	if err != nil {
+		return err
+	}
+	defer closer.Close()
+
+	commit, err := gitRepo.GetCommit(opts.CommitSHA)
+	if err != nil {
+		return err
+	}
+
+	workflows, err := actions_module.ListWorkflows(commit)
+	if err != nil {
+		return err
+	}
+
+	matchRef := opts.Ref
+	if opts.PullRequest != nil {
+		matchRef = opts.PullRequest.BaseBranch
+	}
+
+	for _, wf := range workflows {
+		if !wf.Workflow.Match(opts.Event, matchRef) {
+			continue
+		}
+
+		run, jobs, err := createRun(ctx, opts, wf)
+		if err != nil {
+			return err
+		}
+
+		log.Trace("Created run %d of workflow %s in %s for %s", run.ID, wf.ID, opts.Repo.FullName(), opts.Ref)
+
+		for _, job := range jobs {
+			if err := updateCommitStatus(ctx, run, job); err != nil {
+				log.Error("updateCommitStatus: %v", err)
+			}
+		}
+	}
+	return nil
+}
+
+func createRun(ctx context.Context, opts *TriggerOptions, wf *actions_module.WorkflowFile) (*actions_model.ActionRun, []*actions_model.ActionRunJob, error) {
+	ctx, committer, err := db.TxContext()
+	if err != nil {
+		return nil, nil, err
+	}
+	defer committer.Close()
+
+	run := &actions_model.ActionRun{
+		RepoID:        opts.Repo.ID,
+		Repo:          opts.Repo,
+		WorkflowID:    wf.ID,
+		Name:          wf.Workflow.Name,
+		TriggerUserID: opts.Doer.ID,
+		Event:         opts.Event,
+		Ref:           opts.Ref,
+		CommitSHA:     opts.CommitSHA,
+		NeedApproval:  opts.NeedApproval,
+		Status:        actions_model.StatusWaiting,
+	}
+	if opts.PullRequest != nil {
+		run.PullRequestID = opts.PullRequest.ID
+	}
+	if err := db.Insert(ctx, run); err != nil {
+		return nil, nil, err
+	}
+
+	jobs := make([]*actions_model.ActionRunJob, 0, len(wf.Workflow.Jobs))
+	for _, j := range wf.Workflow.Jobs {
+		env := make(map[string]string, len(wf.Workflow.Env)+len(j.Env))
+		for k, v := range wf.Workflow.Env {
+			env[k] = v
+		}
+		for k, v := range j.Env {
+			env[k] = v
+		}
+
+		payload, err := json.Marshal(&jobPayload{
+			Env:   env,
+			Steps: j.Steps,
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+
+		status := actions_model.StatusWaiting
+		if len(j.Needs) != 0 {
+			status = actions_model.StatusBlocked
+		}
+
+		job := &actions_model.ActionRunJob{
+			RunID:   run.ID,
+			RepoID:  run.RepoID,
+			JobID:   j.ID,
+			Name:    j.DisplayName(),
+			Needs:   j.Needs,
+			RunsOn:  j.RunsOn,
+			Payload: string(payload),
+			Status:  status,
+		}
+		if err := db.Insert(ctx, job); err != nil {
+			return nil, nil, err
+		}
+
+		for i, s := range j.Steps {
+			if err := db.Insert(ctx, &actions_model.ActionRunStep{
+				JobID:  job.ID,
+				RepoID: job.RepoID,
+				Index:  int64(i),
+				Name:   s.DisplayName(),
+				Status: actions_model.StatusWaiting,
+			}); err != nil {
+				return nil, nil, err
+			}
+		}
+
+		jobs = append(jobs, job)
+	}
+
+	return run, jobs, committer.Commit()
+}
+
+// ApproveRun allows runners to pick the jobs of a run which waits for approval
+func ApproveRun(ctx context.Context, run *actions_model.ActionRun, doer *user_model.User) error {
+	if !run.NeedApproval {
+		return ErrRunNotNeedApproval
+	}
+
+	run.NeedApproval = false
+	run.ApprovedBy = doer.ID
+	if err := actions_model.UpdateRun(ctx, run, "need_approval", "approved_by"); err != nil {
+		return err
+	}
+
+	jobs, err := actions_model.GetRunJobsByRunID(ctx, run.ID)
+	if err != nil {
+		return err
+	}
+	for _, job := range jobs {
+		if err := updateCommitStatus(ctx, run, job); err != nil {
+			log.Error("updateCommitStatus: %v", err)
+		}
+	}
+	return nil
+}
+
+// updateCommitStatus reflects the status of the job in the commit status of the run's commit
+func updateCommitStatus(ctx context.Context, run *actions_model.ActionRun, job *actions_model.ActionRunJob) error {
+	if err := run.LoadRepo(ctx); err != nil {
+		return err
+	}
+
+	creator, err := user_model.GetUserByIDCtx(ctx, run.TriggerUserID)
+	if err != nil {
+		if !user_model.IsErrUserNotExist(err) {
+			return err
+		}
+		creator = user_model.NewGhostUser()
+	}
+
+	var state api.CommitStatusState
+	var description string
+	switch job.Status {
+	case actions_model.StatusBlocked:
+		state, description = api.CommitStatusPending, "Waiting for the jobs it needs"
+	case actions_model.StatusWaiting:
+		if run.NeedApproval {
+			state, description = api.CommitStatusPending, "Waiting for approval"
+		} else {
+			state, description = api.CommitStatusPending, "Waiting for a runner"
+		}
+	case actions_model.StatusRunning:
+		state, description = api.CommitStatusPending, "Running"
+	case actions_model.StatusSuccess:
+		state, description = api.CommitStatusSuccess, "Successful"
+	case actions_model.StatusFailure:
+		state, description = api.CommitStatusFailure, "Failed"
+	case actions_model.StatusCancelled:
+		state, description = api.CommitStatusError, "Cancelled"
+	case actions_model.StatusSkipped:
+		state, description = api.CommitStatusWarning, "Skipped"
+	default:
+		return fmt.Errorf("unknown job status %d", job.Status)
+	}
+
+	return files_service.CreateCommitStatus(ctx, run.Repo, creator, run.CommitSHA, &models.CommitStatus{
+		State:       state,
+		TargetURL:   JobLogLink(run, job),
+		Description: description,
+		Context:     fmt.Sprintf("%s / %s (%s)", run.Name, job.Name, run.Event),
+	})
+}
+
+// JobLogLink returns the link to the log of the job
+func JobLogLink(run *actions_model.ActionRun, job *actions_model.ActionRunJob) string {
+	return fmt.Sprintf("%s/actions/jobs/%d/logs", run.Repo.HTMLURL(), job.ID)
+}
@@ -252,6 +252,9 @@ func ProcRecive(ctx *context.PrivateContext, opts *private.HookOptions) []privat
 		comment, err := models.CreatePushPullComment(ctx, pusher, pr, oldCommitID, opts.NewCommitIDs[i])
 		if err == nil && comment != nil {
 			notification.NotifyPullRequestPushCommits(pusher, pr, comment)
+			if err := pull_service.RecordIteration(ctx, pr, pusher, oldCommitID, comment); err != nil {
+				log.Error("RecordIteration [%d]: %v", pr.ID, err)
+			}
 		}
 		notification.NotifyPullRequestSynchronized(pusher, pr)
 		isForcePush := comment != nil && comment.IsForcePush
@@ -11,7 +11,9 @@ import (
 	"io"
 
 	"code.gitea.io/gitea/models/db"
+	quota_model "code.gitea.io/gitea/models/quota"
 	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/storage"
 	"code.gitea.io/gitea/modules/upload"
 	"code.gitea.io/gitea/modules/util"
@@ -33,6 +35,17 @@ func NewAttachment(attach *repo_model.Attachment, file io.Reader) (*repo_model.A
 		}
 		attach.Size = size
 
+		repo, err := repo_model.GetRepositoryByIDCtx(ctx, attach.RepoID)
+		if err != nil {
+			return err
+		}
+		if err := quota_model.CheckQuota(ctx, repo.OwnerID, quota_model.CategoryAttachments, size); err != nil {
+			if err := storage.Attachments.Delete(attach.RelativePath()); err != nil {
+				log.Error("Error deleting attachment %s from storage: %v", attach.RelativePath(), err)
+			}
+			return err
+		}
+
 		return db.Insert(ctx, attach)
 	})
 
@@ -22,6 +22,7 @@ import (
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/process"
 	"code.gitea.io/gitea/modules/queue"
+	"code.gitea.io/gitea/services/mergequeue"
 	pull_service "code.gitea.io/gitea/services/pull"
 )
 
@@ -240,6 +241,19 @@ func handlePull(pullID int64, sha string) {
 		return
 	}
 
+	// a branch with a merge queue only accepts pull requests merged through the queue
+	queued, err := mergequeue.IsEnabled(ctx, pr)
+	if err != nil {
+		log.Error("IsEnabled: %v", err)
+		return
+	}
+	if queued {
+		if err := mergequeue.Add(ctx, doer, pr, scheduledPRM.MergeStyle, "", scheduledPRM.Message); err != nil {
+			log.Error("mergequeue.Add: %v", err)
+		}
+		return
+	}
+
 	var baseGitRepo *git.Repository
 	if pr.BaseRepoID == pr.HeadRepoID {
 		baseGitRepo = headGitRepo
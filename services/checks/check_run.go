@@ -0,0 +1,258 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package checks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"code.gitea.io/gitea/models"
+	checks_model "code.gitea.io/gitea/models/checks"
+	"code.gitea.io/gitea/models/db"
+	repo_model "code.gitea.io/gitea/models/repo"
+	user_model "code.gitea.io/gitea/models/user"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/timeutil"
+	files_service "code.gitea.io/gitea/services/repository/files"
+)
+
+const (
+	// MaxAnnotationsPerRequest is the maximum number of annotations accepted by a single create or edit
+	MaxAnnotationsPerRequest = 50
+	// MaxSummaryLength is the maximum length of the summary and the text of a check run
+	MaxSummaryLength = 65535
+)
+
+// ErrInvalidCheckRun indicates that the options of a check run are invalid
+var ErrInvalidCheckRun = errors.New("invalid check run")
+
+func invalidf(format string, args ...interface{}) error {
+	return fmt.Errorf("%w: %s", ErrInvalidCheckRun, fmt.Sprintf(format, args...))
+}
+
+// CreateCheckRun creates a check run against the commit sha of the repository
+// and reflects it in the commit status of the commit
+func CreateCheckRun(ctx context.Context, repo *repo_model.Repository, doer *user_model.User, sha string, opts *api.CreateCheckRunOption) (*checks_model.CheckRun, error) {
+	run := &checks_model.CheckRun{
+		RepoID:     repo.ID,
+		Repo:       repo,
+		HeadSHA:    sha,
+		Name:       strings.TrimSpace(opts.Name),
+		Status:     checks_model.Status(opts.Status),
+		Conclusion: checks_model.Conclusion(opts.Conclusion),
+		DetailsURL: opts.DetailsURL,
+		ExternalID: opts.ExternalID,
+		CreatorID:  doer.ID,
+	}
+	if run.Name == "" {
+		return nil, invalidf("name is required")
+	}
+	if run.Status == "" {
+		run.Status = checks_model.StatusQueued
+	}
+	if opts.StartedAt != nil {
+		run.StartedUnix = timeutil.TimeStamp(opts.StartedAt.Unix())
+	}
+	if opts.CompletedAt != nil {
+		run.CompletedUnix = timeutil.TimeStamp(opts.CompletedAt.Unix())
+	}
+
+	annotations, err := applyOutput(run, opts.Output)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkStatus(run); err != nil {
+		return nil, err
+	}
+
+	if err := db.WithTx(func(ctx context.Context) error {
+		if err := checks_model.CreateCheckRun(ctx, run); err != nil {
+			return err
+		}
+		return checks_model.AddAnnotations(ctx, run, annotations)
+	}, ctx); err != nil {
+		return nil, err
+	}
+
+	return run, updateCommitStatus(ctx, run, doer)
+}
+
+// EditCheckRun updates a check run, appends the given annotations
+// and reflects the changes in the commit status of the commit
+func EditCheckRun(ctx context.Context, run *checks_model.CheckRun, doer *user_model.User, opts *api.EditCheckRunOption) error {
+	if err := run.LoadRepo(ctx); err != nil {
+		return err
+	}
+
+	if opts.Name != nil {
+		run.Name = strings.TrimSpace(*opts.Name)
+		if run.Name == "" {
+			return invalidf("name is required")
+		}
+	}
+	if opts.DetailsURL != nil {
+		run.DetailsURL = *opts.DetailsURL
+	}
+	if opts.ExternalID != nil {
+		run.ExternalID = *opts.ExternalID
+	}
+	if opts.Status != nil {
+		run.Status = checks_model.Status(*opts.Status)
+		if run.Status != checks_model.StatusCompleted {
+			// the check run is restarted
+			run.Conclusion = checks_model.ConclusionNone
+			run.CompletedUnix = 0
+		}
+	}
+	if opts.Conclusion != nil {
+		run.Conclusion = checks_model.Conclusion(*opts.Conclusion)
+	}
+	if opts.StartedAt != nil {
+		run.StartedUnix = timeutil.TimeStamp(opts.StartedAt.Unix())
+	}
+	if opts.CompletedAt != nil {
+		run.CompletedUnix = timeutil.TimeStamp(opts.CompletedAt.Unix())
+	}
+
+	annotations, err := applyOutput(run, opts.Output)
+	if err != nil {
+		return err
+	}
+	if err := checkStatus(run); err != nil {
+		return err
+	}
+
+	if err := db.WithTx(func(ctx context.Context) error {
+		if err := checks_model.UpdateCheckRun(ctx, run, "name", "details_url", "external_id", "status", "conclusion",
+			"title", "summary", "text", "started_unix", "completed_unix"); err != nil {
+			return err
+		}
+		return checks_model.AddAnnotations(ctx, run, annotations)
+	}, ctx); err != nil {
+		return err
+	}
+
+	return updateCommitStatus(ctx, run, doer)
+}
+
+// applyOutput copies the output to the check run and returns the validated annotations
+func applyOutput(run *checks_model.CheckRun, output *api.CheckRunOutputOption) ([]*checks_model.CheckAnnotation, error) {
+	if output == nil {
+		return nil, nil
+	}
+	if len(output.Summary) > MaxSummaryLength || len(output.Text) > MaxSummaryLength {
+		return nil, invalidf("summary and text must not be longer than %d characters", MaxSummaryLength)
+	}
+	if len(output.Annotations) > MaxAnnotationsPerRequest {
+		return nil, invalidf("at most %d annotations are accepted per request", MaxAnnotationsPerRequest)
+	}
+
+	run.Title = output.Title
+	run.Summary = output.Summary
+	run.Text = output.Text
+
+	annotations := make([]*checks_model.CheckAnnotation, 0, len(output.Annotations))
+	for i, a := range output.Annotations {
+		annotation := &checks_model.CheckAnnotation{
+			Path:       strings.TrimPrefix(a.Path, "/"),
+			StartLine:  a.StartLine,
+			EndLine:    a.EndLine,
+			Level:      checks_model.AnnotationLevel(a.AnnotationLevel),
+			Title:      a.Title,
+			Message:    a.Message,
+			RawDetails: a.RawDetails,
+		}
+		if annotation.EndLine == 0 {
+			annotation.EndLine = annotation.StartLine
+		}
+		switch {
+		case annotation.Path == "":
+			return nil, invalidf("annotation %d: path is required", i)
+		case annotation.StartLine < 1 || annotation.EndLine < annotation.StartLine:
+			return nil, invalidf("annotation %d: invalid line range %d-%d", i, annotation.StartLine, annotation.EndLine)
+		case !annotation.Level.IsValid():
+			return nil, invalidf("annotation %d: invalid annotation level %q", i, annotation.Level)
+		case annotation.Message == "":
+			return nil, invalidf("annotation %d: message is required", i)
+		}
+		annotations = append(annotations, annotation)
+	}
+	return annotations, nil
+}
+
+// checkStatus validates the status and the conclusion of the check run and fills in the missing times
+func checkStatus(run *checks_model.CheckRun) error {
+	if run.Conclusion != checks_model.ConclusionNone {
+		if !run.Conclusion.IsValid() {
+			return invalidf("invalid conclusion %q", run.Conclusion)
+		}
+		// reporting a conclusion completes the check run
+		run.Status = checks_model.StatusCompleted
+	}
+	if !run.Status.IsValid() {
+		return invalidf("invalid status %q", run.Status)
+	}
+
+	switch run.Status {
+	case checks_model.StatusInProgress:
+		if run.StartedUnix == 0 {
+			run.StartedUnix = timeutil.TimeStampNow()
+		}
+	case checks_model.StatusCompleted:
+		if run.Conclusion == checks_model.ConclusionNone {
+			return invalidf("a completed check run requires a conclusion")
+		}
+		if run.CompletedUnix == 0 {
+			run.CompletedUnix = timeutil.TimeStampNow()
+		}
+		if run.StartedUnix == 0 {
+			run.StartedUnix = run.CompletedUnix
+		}
+	}
+	return nil
+}
+
+// updateCommitStatus reflects the check run in the commit status of its commit,
+// so that check runs count for protected branches and the commit lists like any other status
+func updateCommitStatus(ctx context.Context, run *checks_model.CheckRun, doer *user_model.User) error {
+	var state api.CommitStatusState
+	var description string
+	switch run.Status {
+	case checks_model.StatusQueued:
+		state, description = api.CommitStatusPending, "Queued"
+	case checks_model.StatusInProgress:
+		state, description = api.CommitStatusPending, "In progress"
+	default:
+		switch run.Conclusion {
+		case checks_model.ConclusionSuccess, checks_model.ConclusionNeutral:
+			state, description = api.CommitStatusSuccess, "Successful"
+		case checks_model.ConclusionSkipped:
+			state, description = api.CommitStatusWarning, "Skipped"
+		case checks_model.ConclusionCancelled:
+			state, description = api.CommitStatusError, "Cancelled"
+		case checks_model.ConclusionTimedOut:
+			state, description = api.CommitStatusFailure, "Timed out"
+		default:
+			state, description = api.CommitStatusFailure, "Failed"
+		}
+	}
+	if run.Title != "" {
+		description = run.Title
+	}
+
+	targetURL := run.DetailsURL
+	if targetURL == "" {
+		targetURL = run.HTMLURL()
+	}
+
+	return files_service.CreateCommitStatus(ctx, run.Repo, doer, run.HeadSHA, &models.CommitStatus{
+		State:       state,
+		TargetURL:   targetURL,
+		Description: description,
+		Context:     run.Name,
+	})
+}
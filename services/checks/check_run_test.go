@@ -0,0 +1,67 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package checks
+
+import (
+	"errors"
+	"testing"
+
+	checks_model "code.gitea.io/gitea/models/checks"
+	api "code.gitea.io/gitea/modules/structs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckStatus(t *testing.T) {
+	run := &checks_model.CheckRun{Status: checks_model.StatusInProgress}
+	assert.NoError(t, checkStatus(run))
+	assert.NotZero(t, run.StartedUnix)
+	assert.Zero(t, run.CompletedUnix)
+
+	run = &checks_model.CheckRun{Status: checks_model.StatusQueued, Conclusion: checks_model.ConclusionSuccess}
+	assert.NoError(t, checkStatus(run))
+	assert.Equal(t, checks_model.StatusCompleted, run.Status)
+	assert.NotZero(t, run.CompletedUnix)
+	assert.Equal(t, run.CompletedUnix, run.StartedUnix)
+
+	for _, run := range []*checks_model.CheckRun{
+		{Status: checks_model.StatusCompleted},
+		{Status: "unknown"},
+		{Status: checks_model.StatusCompleted, Conclusion: "unknown"},
+	} {
+		assert.True(t, errors.Is(checkStatus(run), ErrInvalidCheckRun))
+	}
+}
+
+func TestApplyOutput(t *testing.T) {
+	run := &checks_model.CheckRun{}
+	annotations, err := applyOutput(run, &api.CheckRunOutputOption{
+		Title:   "title",
+		Summary: "summary",
+		Annotations: []*api.CheckAnnotation{
+			{Path: "/README.md", StartLine: 3, AnnotationLevel: "notice", Message: "message"},
+		},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "title", run.Title)
+	assert.Equal(t, "summary", run.Summary)
+	assert.Len(t, annotations, 1)
+	assert.Equal(t, "README.md", annotations[0].Path)
+	assert.Equal(t, 3, annotations[0].EndLine)
+
+	for _, annotation := range []*api.CheckAnnotation{
+		{StartLine: 1, AnnotationLevel: "notice", Message: "message"},
+		{Path: "README.md", AnnotationLevel: "notice", Message: "message"},
+		{Path: "README.md", StartLine: 2, EndLine: 1, AnnotationLevel: "notice", Message: "message"},
+		{Path: "README.md", StartLine: 1, AnnotationLevel: "error", Message: "message"},
+		{Path: "README.md", StartLine: 1, AnnotationLevel: "notice"},
+	} {
+		_, err := applyOutput(&checks_model.CheckRun{}, &api.CheckRunOutputOption{Annotations: []*api.CheckAnnotation{annotation}})
+		assert.True(t, errors.Is(err, ErrInvalidCheckRun))
+	}
+
+	_, err = applyOutput(&checks_model.CheckRun{}, &api.CheckRunOutputOption{Annotations: make([]*api.CheckAnnotation, MaxAnnotationsPerRequest+1)})
+	assert.True(t, errors.Is(err, ErrInvalidCheckRun))
+}
@@ -150,11 +150,13 @@ type RepoSettingForm struct {
 	PullsAllowRebase                      bool
 	PullsAllowRebaseMerge                 bool
 	PullsAllowSquash                      bool
+	PullsAllowFastForwardOnly             bool
 	PullsAllowManualMerge                 bool
 	PullsDefaultMergeStyle                string
 	EnableAutodetectManualMerge           bool
 	PullsAllowRebaseUpdate                bool
 	DefaultDeleteBranchAfterMerge         bool
+	RebaseDependentPullsAfterMerge        bool
 	EnableTimetracker                     bool
 	AllowOnlyContributorsToTrackTime      bool
 	EnableIssueDependencies               bool
@@ -199,11 +201,13 @@ type ProtectBranchForm struct {
 	ApprovalsWhitelistTeams       string
 	BlockOnRejectedReviews        bool
 	BlockOnOfficialReviewRequests bool
+	RequireCodeOwnerApproval      bool
 	BlockOnOutdatedBranch         bool
 	DismissStaleApprovals         bool
 	RequireSignedCommits          bool
 	ProtectedFilePatterns         string
 	UnprotectedFilePatterns       string
+	EnableMergeQueue              bool
 }
 
 // Validate validates the fields
@@ -433,6 +437,7 @@ type CreateIssueForm struct {
 	Content             string
 	Files               []string
 	AllowMaintainerEdit bool
+	FormTemplate        string `form:"form_template"`
 }
 
 // Validate validates the fields
@@ -506,15 +511,6 @@ type CreateProjectForm struct {
 	BoardType project_model.BoardType
 }
 
-// UserCreateProjectForm is a from for creating an individual or organization
-// form.
-type UserCreateProjectForm struct {
-	Title     string `binding:"Required;MaxSize(100)"`
-	Content   string
-	BoardType project_model.BoardType
-	UID       int64 `binding:"Required"`
-}
-
 // EditProjectBoardForm is a form for editing a project board
 type EditProjectBoardForm struct {
 	Title   string `binding:"Required;MaxSize(100)"`
@@ -522,6 +518,13 @@ type EditProjectBoardForm struct {
 	Color   string `binding:"MaxSize(7)"`
 }
 
+// ProjectAutomationRuleForm is a form for adding an automation rule to a project
+type ProjectAutomationRuleForm struct {
+	TriggerType string `binding:"Required"`
+	LabelID     int64
+	BoardID     int64 `binding:"Required"`
+}
+
 //    _____  .__.__                   __
 //   /     \ |__|  |   ____   _______/  |_  ____   ____   ____
 //  /  \ /  \|  |  | _/ __ \ /  ___/\   __\/  _ \ /    \_/ __ \
@@ -563,6 +566,25 @@ func (f *CreateLabelForm) Validate(req *http.Request, errs binding.Errors) bindi
 	return middleware.Validate(errs, ctx.Data, f, ctx.Locale)
 }
 
+// CustomFieldForm form for creating or editing a custom field of issues
+type CustomFieldForm struct {
+	Name        string `binding:"Required;MaxSize(50)" locale:"repo.settings.custom_fields.name"`
+	Description string `binding:"MaxSize(255)" locale:"repo.settings.custom_fields.description"`
+	Type        string
+	Options     string // one option of a select field per line
+}
+
+// Validate validates the fields
+func (f *CustomFieldForm) Validate(req *http.Request, errs binding.Errors) binding.Errors {
+	ctx := context.GetContext(req)
+	return middleware.Validate(errs, ctx.Data, f, ctx.Locale)
+}
+
+// OptionList returns the options of a select field, one per line of the form
+func (f *CustomFieldForm) OptionList() []string {
+	return strings.Split(f.Options, "\n")
+}
+
 // InitializeLabelsForm form for initializing labels
 type InitializeLabelsForm struct {
 	TemplateName string `binding:"Required"`
@@ -585,8 +607,8 @@ func (f *InitializeLabelsForm) Validate(req *http.Request, errs binding.Errors)
 // swagger:model MergePullRequestOption
 type MergePullRequestForm struct {
 	// required: true
-	// enum: merge,rebase,rebase-merge,squash,manually-merged
-	Do                     string `binding:"Required;In(merge,rebase,rebase-merge,squash,manually-merged)"`
+	// enum: merge,rebase,rebase-merge,squash,fast-forward-only,manually-merged
+	Do                     string `binding:"Required;In(merge,rebase,rebase-merge,squash,fast-forward-only,manually-merged)"`
 	MergeTitleField        string
 	MergeMessageField      string
 	MergeCommitID          string // only used for manually-merged
@@ -21,6 +21,7 @@ import (
 	"time"
 
 	"code.gitea.io/gitea/models"
+	checks_model "code.gitea.io/gitea/models/checks"
 	"code.gitea.io/gitea/models/db"
 	pull_model "code.gitea.io/gitea/models/pull"
 	user_model "code.gitea.io/gitea/models/user"
@@ -82,6 +83,7 @@ type DiffLine struct {
 	Type        DiffLineType
 	Content     string
 	Comments    []*models.Comment
+	Annotations []*checks_model.CheckAnnotation
 	SectionInfo *DiffLineSectionInfo
 }
 
@@ -728,6 +730,49 @@ func (diff *Diff) LoadComments(ctx context.Context, issue *models.Issue, current
 	return nil
 }
 
+// LoadAnnotations loads the annotations of the latest check runs reported against the commit into
+// the lines they end on. Annotations refer to the file at the commit, so only lines existing on
+// the new side of the diff can be annotated.
+func (diff *Diff) LoadAnnotations(ctx context.Context, repoID int64, commitID string) error {
+	runs, err := checks_model.GetLatestCheckRunsBySHA(ctx, repoID, commitID)
+	if err != nil {
+		return err
+	}
+	annotations, err := checks_model.GetAnnotationsByCheckRuns(ctx, runs)
+	if err != nil {
+		return err
+	}
+	if len(annotations) == 0 {
+		return nil
+	}
+
+	fileAnnotations := make(map[string]map[int][]*checks_model.CheckAnnotation)
+	for _, annotation := range annotations {
+		lines, ok := fileAnnotations[annotation.Path]
+		if !ok {
+			lines = make(map[int][]*checks_model.CheckAnnotation)
+			fileAnnotations[annotation.Path] = lines
+		}
+		lines[annotation.EndLine] = append(lines[annotation.EndLine], annotation)
+	}
+
+	for _, file := range diff.Files {
+		lines, ok := fileAnnotations[file.Name]
+		if !ok {
+			continue
+		}
+		for _, section := range file.Sections {
+			for _, line := range section.Lines {
+				if line.Type == DiffLineDel || line.Type == DiffLineSection || line.RightIdx == 0 {
+					continue
+				}
+				line.Annotations = lines[line.RightIdx]
+			}
+		}
+	}
+	return nil
+}
+
 const cmdDiffHead = "diff --git "
 
 // ParsePatch builds a Diff object from a io.Reader and some parameters.
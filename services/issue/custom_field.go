@@ -0,0 +1,75 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package issue
+
+import (
+	"context"
+	"sort"
+
+	"code.gitea.io/gitea/models"
+	issues_model "code.gitea.io/gitea/models/issues"
+	repo_model "code.gitea.io/gitea/models/repo"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/notification"
+)
+
+// ValidateCustomFields checks that the values, keyed by the IDs of their custom fields, are valid values of
+// custom fields the issues of the repository can have
+func ValidateCustomFields(ctx context.Context, repo *repo_model.Repository, values map[int64]string) error {
+	_, err := getCustomFields(ctx, repo, values)
+	return err
+}
+
+// SetCustomFields sets the values, keyed by the IDs of their custom fields, of custom fields of an issue.
+// An empty value clears a field. No value is stored unless all of them are valid.
+func SetCustomFields(ctx context.Context, doer *user_model.User, issue *models.Issue, values map[int64]string) error {
+	if err := issue.LoadRepo(ctx); err != nil {
+		return err
+	}
+
+	fields, err := getCustomFields(ctx, issue.Repo, values)
+	if err != nil {
+		return err
+	}
+
+	changed := false
+	for _, f := range fields {
+		fieldChanged, err := issues_model.SetCustomFieldValue(ctx, issue.ID, f, values[f.ID])
+		if err != nil {
+			return err
+		}
+		changed = changed || fieldChanged
+	}
+
+	if changed {
+		notification.NotifyIssueChangeCustomFields(doer, issue)
+	}
+	return nil
+}
+
+// getCustomFields returns the custom fields of the values ordered by their IDs after validating the values
+func getCustomFields(ctx context.Context, repo *repo_model.Repository, values map[int64]string) ([]*issues_model.CustomField, error) {
+	fieldIDs := make([]int64, 0, len(values))
+	for id := range values {
+		fieldIDs = append(fieldIDs, id)
+	}
+	sort.Slice(fieldIDs, func(i, j int) bool { return fieldIDs[i] < fieldIDs[j] })
+
+	fields := make([]*issues_model.CustomField, 0, len(fieldIDs))
+	for _, id := range fieldIDs {
+		f, err := issues_model.GetCustomFieldByID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if !f.AppliesTo(repo) {
+			return nil, issues_model.ErrCustomFieldNotExist{ID: id}
+		}
+		if _, err := f.NormalizeValue(ctx, values[id]); err != nil {
+			return nil, err
+		}
+		fields = append(fields, f)
+	}
+	return fields, nil
+}
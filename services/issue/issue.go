@@ -148,6 +148,17 @@ func DeleteIssue(doer *user_model.User, gitRepo *git.Repository, issue *models.I
 		if err := gitRepo.RemoveReference(fmt.Sprintf("%s%d", git.PullPrefix, issue.PullRequest.Index)); err != nil {
 			return err
 		}
+
+		// the heads of the iterations are kept below the pull request ref
+		iterationRefs, err := gitRepo.GetRefsFiltered(fmt.Sprintf("%s%d/iterations/", git.PullPrefix, issue.PullRequest.Index))
+		if err != nil {
+			return err
+		}
+		for _, ref := range iterationRefs {
+			if err := gitRepo.RemoveReference(ref.Name); err != nil {
+				return err
+			}
+		}
 	}
 
 	notification.NotifyDeleteIssue(doer, issue)
@@ -21,6 +21,7 @@ import (
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/models/perm"
 	access_model "code.gitea.io/gitea/models/perm/access"
+	quota_model "code.gitea.io/gitea/models/quota"
 	repo_model "code.gitea.io/gitea/models/repo"
 	"code.gitea.io/gitea/models/unit"
 	user_model "code.gitea.io/gitea/models/user"
@@ -181,6 +182,8 @@ func BatchHandler(ctx *context.Context) {
 
 	var responseObjects []*lfs_module.ObjectResponse
 
+	var uploadSize int64
+
 	for _, p := range br.Objects {
 		if !p.IsValid() {
 			responseObjects = append(responseObjects, buildObjectResponse(rc, p, false, false, &lfs_module.ObjectError{
@@ -222,17 +225,39 @@ func BatchHandler(ctx *context.Context) {
 				}
 			}
 
-			if exists && meta == nil {
-				accessible, err := models.LFSObjectAccessible(ctx.Doer, p.Oid)
-				if err != nil {
-					log.Error("Unable to check if LFS MetaObject [%s] is accessible. Error: %v", p.Oid, err)
+			if err == nil && meta == nil {
+				uploadSize += p.Size
+				if qerr := quota_model.CheckQuota(ctx, repository.OwnerID, quota_model.CategoryLFS, uploadSize); qerr != nil {
+					if !quota_model.IsErrQuotaExceeded(qerr) {
+						log.Error("Unable to check quota of %-v: %v", repository, qerr)
+						writeStatus(ctx, http.StatusInternalServerError)
+						return
+					}
+					uploadSize -= p.Size
+					err = &lfs_module.ObjectError{
+						Code:    http.StatusRequestEntityTooLarge,
+						Message: "Storage quota exceeded",
+					}
+				}
+			}
+
+			// objects which cannot be uploaded must not be linked to the repository either
+			if err == nil && exists && meta == nil {
+				accessible, aerr := models.LFSObjectAccessible(ctx.Doer, p.Oid)
+				if aerr != nil {
+					log.Error("Unable to check if LFS MetaObject [%s] is accessible. Error: %v", p.Oid, aerr)
 					writeStatus(ctx, http.StatusInternalServerError)
 					return
 				}
 				if accessible {
-					_, err := models.NewLFSMetaObject(&models.LFSMetaObject{Pointer: p, RepositoryID: repository.ID})
-					if err != nil {
-						log.Error("Unable to create LFS MetaObject [%s] for %s/%s. Error: %v", p.Oid, rc.User, rc.Repo, err)
+					_, merr := models.NewLFSMetaObjectWithinQuota(&models.LFSMetaObject{Pointer: p, RepositoryID: repository.ID}, repository.OwnerID)
+					if quota_model.IsErrQuotaExceeded(merr) {
+						err = &lfs_module.ObjectError{
+							Code:    http.StatusRequestEntityTooLarge,
+							Message: "Storage quota exceeded",
+						}
+					} else if merr != nil {
+						log.Error("Unable to create LFS MetaObject [%s] for %s/%s. Error: %v", p.Oid, rc.User, rc.Repo, merr)
 						writeStatus(ctx, http.StatusInternalServerError)
 						return
 					}
@@ -287,6 +312,24 @@ func UploadHandler(ctx *context.Context) {
 		return
 	}
 
+	meta, err := models.GetLFSMetaObjectByOid(repository.ID, p.Oid)
+	if err != nil && err != models.ErrLFSObjectNotExist {
+		log.Error("Unable to get LFS MetaObject [%s] for %s/%s. Error: %v", p.Oid, rc.User, rc.Repo, err)
+		writeStatus(ctx, http.StatusInternalServerError)
+		return
+	}
+	if meta == nil {
+		if err := quota_model.CheckQuota(ctx, repository.OwnerID, quota_model.CategoryLFS, p.Size); err != nil {
+			if quota_model.IsErrQuotaExceeded(err) {
+				writeStatusMessage(ctx, http.StatusRequestEntityTooLarge, err.Error())
+			} else {
+				log.Error("Unable to check quota of %-v: %v", repository, err)
+				writeStatus(ctx, http.StatusInternalServerError)
+			}
+			return
+		}
+	}
+
 	contentStore := lfs_module.NewContentStore()
 	exists, err := contentStore.Exists(p)
 	if err != nil {
@@ -323,7 +366,7 @@ func UploadHandler(ctx *context.Context) {
 			log.Error("Error putting LFS MetaObject [%s] into content store. Error: %v", p.Oid, err)
 			return err
 		}
-		_, err := models.NewLFSMetaObject(&models.LFSMetaObject{Pointer: p, RepositoryID: repository.ID})
+		_, err := models.NewLFSMetaObjectWithinQuota(&models.LFSMetaObject{Pointer: p, RepositoryID: repository.ID}, repository.OwnerID)
 		return err
 	}
 
@@ -332,6 +375,8 @@ func UploadHandler(ctx *context.Context) {
 		if errors.Is(err, lfs_module.ErrSizeMismatch) || errors.Is(err, lfs_module.ErrHashMismatch) {
 			log.Error("Upload does not match LFS MetaObject [%s]. Error: %v", p.Oid, err)
 			writeStatusMessage(ctx, http.StatusUnprocessableEntity, err.Error())
+		} else if quota_model.IsErrQuotaExceeded(err) {
+			writeStatusMessage(ctx, http.StatusRequestEntityTooLarge, err.Error())
 		} else {
 			writeStatus(ctx, http.StatusInternalServerError)
 		}
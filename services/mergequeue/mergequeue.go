@@ -0,0 +1,370 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package mergequeue
+
+import (
+	"context"
+	"fmt"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/models/db"
+	pull_model "code.gitea.io/gitea/models/pull"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/models/unit"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/graceful"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/notification"
+	"code.gitea.io/gitea/modules/process"
+	"code.gitea.io/gitea/modules/queue"
+	"code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/sync"
+	pull_service "code.gitea.io/gitea/services/pull"
+)
+
+// The reasons a pull request is removed from the merge queue, they are stored as the content of the comment
+const (
+	reasonChecksFailed = "checks_failed"
+	reasonConflicts    = "conflicts"
+	reasonHeadChanged  = "head_changed"
+	reasonBaseChanged  = "base_changed"
+	reasonMergeFailed  = "merge_failed"
+	reasonDisabled     = "disabled"
+)
+
+// mergeQueue represents a queue of the branches whose merge queue has to be processed
+var mergeQueue queue.UniqueQueue
+
+// branchWorkingPool makes sure the merge queue of a branch is only processed once at a time
+var branchWorkingPool = sync.NewExclusivePool()
+
+// Init runs the task queue that processes the merge queues
+func Init() error {
+	mergeQueue = queue.CreateUniqueQueue("pr_merge_queue", handle, "")
+	if mergeQueue == nil {
+		return fmt.Errorf("Unable to create pr_merge_queue Queue")
+	}
+	go graceful.GetManager().RunWithShutdownFns(mergeQueue.Run)
+
+	notification.RegisterNotifier(&mergeQueueNotifier{})
+	return nil
+}
+
+// handle passed repository IDs and branches and process their merge queues
+func handle(data ...queue.Data) []queue.Data {
+	for _, d := range data {
+		var repoID int64
+		var branch string
+		if _, err := fmt.Sscanf(d.(string), "%d_%s", &repoID, &branch); err != nil {
+			log.Error("could not parse data from pr_merge_queue queue (%v): %v", d, err)
+			continue
+		}
+		processBranch(repoID, branch)
+	}
+	return nil
+}
+
+func addToQueue(repoID int64, branch string) {
+	if err := mergeQueue.PushFunc(fmt.Sprintf("%d_%s", repoID, branch), func() error {
+		log.Trace("Adding branch: %s of repo: %d to the merge queue processing queue", branch, repoID)
+		return nil
+	}); err != nil {
+		log.Error("Error adding branch: %s of repo: %d to the merge queue processing queue: %v", branch, repoID, err)
+	}
+}
+
+// IsEnabled returns whether merging the pull request adds it to the merge queue of its base branch
+func IsEnabled(ctx context.Context, pr *models.PullRequest) (bool, error) {
+	if err := pr.LoadProtectedBranchCtx(ctx); err != nil {
+		return false, err
+	}
+	return pr.ProtectedBranch != nil && pr.ProtectedBranch.EnableMergeQueue, nil
+}
+
+// Add adds a pull request to the end of the merge queue of its base branch.
+// Caller should check PR is ready to be merged (review and status checks)
+func Add(ctx context.Context, doer *user_model.User, pr *models.PullRequest, style repo_model.MergeStyle, expectedHeadCommitID, message string) error {
+	if err := pr.LoadBaseRepoCtx(ctx); err != nil {
+		return err
+	}
+
+	prUnit, err := pr.BaseRepo.GetUnit(unit.TypePullRequests)
+	if err != nil {
+		return err
+	}
+	if !prUnit.PullRequestsConfig().IsMergeStyleAllowed(style) ||
+		style == repo_model.MergeStyleRebaseUpdate || style == repo_model.MergeStyleManuallyMerged {
+		return models.ErrInvalidMergeStyle{ID: pr.BaseRepoID, Style: style}
+	}
+
+	gitRepo, closer, err := git.RepositoryFromContextOrOpen(ctx, pr.BaseRepo.RepoPath())
+	if err != nil {
+		return err
+	}
+	defer closer.Close()
+
+	headCommitID, err := gitRepo.GetRefCommitID(pr.GetGitRefName())
+	if err != nil {
+		return err
+	}
+	if expectedHeadCommitID != "" && expectedHeadCommitID != headCommitID {
+		return models.ErrSHADoesNotMatch{
+			GivenSHA:   expectedHeadCommitID,
+			CurrentSHA: headCommitID,
+		}
+	}
+
+	if err := db.WithTx(func(ctx context.Context) error {
+		if err := pull_model.AddToMergeQueue(ctx, &pull_model.MergeQueueEntry{
+			PullID:       pr.ID,
+			RepoID:       pr.BaseRepoID,
+			BaseBranch:   pr.BaseBranch,
+			DoerID:       doer.ID,
+			MergeStyle:   style,
+			Message:      message,
+			HeadCommitID: headCommitID,
+		}); err != nil {
+			return err
+		}
+
+		// the merge queue takes over from a scheduled auto merge
+		if err := pull_model.DeleteScheduledAutoMerge(ctx, pr.ID); err != nil && !db.IsErrNotExist(err) {
+			return err
+		}
+
+		_, err := models.CreateMergeQueueComment(ctx, models.CommentTypePRAddedToMergeQueue, pr, doer, "")
+		return err
+	}, ctx); err != nil {
+		return err
+	}
+
+	addToQueue(pr.BaseRepoID, pr.BaseBranch)
+	return nil
+}
+
+// Remove removes a pull request from the merge queue of its base branch
+func Remove(ctx context.Context, doer *user_model.User, pr *models.PullRequest) error {
+	entry, err := pull_model.GetMergeQueueEntryByPullID(ctx, pr.ID)
+	if err != nil {
+		return err
+	}
+
+	if err := removeEntry(ctx, doer, entry, pr, ""); err != nil {
+		return err
+	}
+
+	// the following entries have to be rebuilt without this pull request
+	addToQueue(entry.RepoID, entry.BaseBranch)
+	return nil
+}
+
+// HandleCommitStatus processes the merge queues waiting for the status checks of the commit
+func HandleCommitStatus(ctx context.Context, repo *repo_model.Repository, sha string) error {
+	entries, err := pull_model.GetMergeQueueEntriesByCommitID(ctx, repo.ID, sha)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		addToQueue(entry.RepoID, entry.BaseBranch)
+	}
+	return nil
+}
+
+// removeEntry removes an entry from the merge queue and deletes its temporary merge commit.
+// A comment on the pull request tells about the reason if it is not empty.
+func removeEntry(ctx context.Context, doer *user_model.User, entry *pull_model.MergeQueueEntry, pr *models.PullRequest, reason string) error {
+	if err := db.WithTx(func(ctx context.Context) error {
+		if err := pull_model.DeleteMergeQueueEntry(ctx, entry); err != nil {
+			return err
+		}
+
+		_, err := models.CreateMergeQueueComment(ctx, models.CommentTypePRRemovedFromMergeQueue, pr, doer, reason)
+		return err
+	}, ctx); err != nil {
+		return err
+	}
+
+	if err := pull_service.DeleteMergeQueueBranch(ctx, pr, doer); err != nil {
+		log.Error("DeleteMergeQueueBranch [%d]: %v", pr.ID, err)
+	}
+	return nil
+}
+
+// processBranch walks through the merge queue of a branch in order. Every entry gets a temporary
+// merge commit of the base branch and all earlier entries. As long as the required status checks
+// of these commits succeed, the base branch is fast-forwarded to them. Entries with failing checks
+// or which cannot be merged anymore are removed, so that the following entries are rebuilt without them.
+func processBranch(repoID int64, branch string) {
+	ctx, _, finished := process.GetManager().AddContext(graceful.GetManager().HammerContext(),
+		fmt.Sprintf("Process merge queue of branch[%s] in repo[%d]", branch, repoID))
+	defer finished()
+
+	key := fmt.Sprintf("%d_%s", repoID, branch)
+	branchWorkingPool.CheckIn(key)
+	defer branchWorkingPool.CheckOut(key)
+
+	entries, err := pull_model.GetMergeQueueEntries(ctx, repoID, branch)
+	if err != nil {
+		log.Error("GetMergeQueueEntries[%d, %s]: %v", repoID, branch, err)
+		return
+	} else if len(entries) == 0 {
+		return
+	}
+
+	repo, err := repo_model.GetRepositoryByIDCtx(ctx, repoID)
+	if err != nil {
+		log.Error("GetRepositoryByIDCtx[%d]: %v", repoID, err)
+		return
+	}
+
+	gitRepo, err := git.OpenRepository(ctx, repo.RepoPath())
+	if err != nil {
+		log.Error("OpenRepository[%s]: %v", repo.RepoPath(), err)
+		return
+	}
+	defer gitRepo.Close()
+
+	protectBranch, err := models.GetProtectedBranchBy(repoID, branch)
+	if err != nil {
+		log.Error("GetProtectedBranchBy[%d, %s]: %v", repoID, branch, err)
+		return
+	}
+	enabled := protectBranch != nil && protectBranch.EnableMergeQueue
+	checkStatus := enabled && protectBranch.EnableStatusCheck
+	var requiredContexts []string
+	if checkStatus {
+		requiredContexts = protectBranch.StatusCheckContexts
+	}
+
+	parentCommitID, err := gitRepo.GetBranchCommitID(branch)
+	if err != nil {
+		log.Error("GetBranchCommitID[%s]: %v", branch, err)
+		return
+	}
+
+	// only the entries at the front of the queue can be merged, the others are built in advance
+	merging := true
+	for _, entry := range entries {
+		pr, err := models.GetPullRequestByID(ctx, entry.PullID)
+		if err != nil {
+			if models.IsErrPullRequestNotExist(err) {
+				if err := pull_model.DeleteMergeQueueEntry(ctx, entry); err != nil {
+					log.Error("DeleteMergeQueueEntry[%d]: %v", entry.ID, err)
+				}
+				continue
+			}
+			log.Error("GetPullRequestByID[%d]: %v", entry.PullID, err)
+			return
+		}
+		pr.BaseRepo = repo
+		if err := pr.LoadIssueCtx(ctx); err != nil {
+			log.Error("LoadIssue[%d]: %v", pr.ID, err)
+			return
+		}
+		if err := entry.LoadDoer(ctx); err != nil {
+			log.Error("LoadDoer[%d]: %v", entry.ID, err)
+			return
+		}
+
+		if pr.HasMerged || pr.Issue.IsClosed {
+			if err := pull_model.DeleteMergeQueueEntry(ctx, entry); err != nil {
+				log.Error("DeleteMergeQueueEntry[%d]: %v", entry.ID, err)
+			}
+			if err := pull_service.DeleteMergeQueueBranch(ctx, pr, entry.Doer); err != nil {
+				log.Error("DeleteMergeQueueBranch[%d]: %v", pr.ID, err)
+			}
+			continue
+		}
+
+		eject := func(reason string) {
+			log.Debug("Remove pull request %d from merge queue of branch %s: %s", pr.ID, branch, reason)
+			if err := removeEntry(ctx, entry.Doer, entry, pr, reason); err != nil {
+				log.Error("removeEntry[%d]: %v", entry.ID, err)
+			}
+		}
+
+		if !enabled {
+			eject(reasonDisabled)
+			continue
+		}
+		if pr.BaseBranch != entry.BaseBranch {
+			eject(reasonBaseChanged)
+			continue
+		}
+		headCommitID, err := gitRepo.GetRefCommitID(pr.GetGitRefName())
+		if err != nil {
+			log.Error("GetRefCommitID[%s]: %v", pr.GetGitRefName(), err)
+			return
+		}
+		if headCommitID != entry.HeadCommitID {
+			eject(reasonHeadChanged)
+			continue
+		}
+
+		if entry.CommitID == "" || entry.ParentCommitID != parentCommitID {
+			commitID, err := pull_service.BuildMergeQueueCommit(ctx, pr, entry.Doer, entry.MergeStyle, entry.HeadCommitID, entry.Message, parentCommitID)
+			if err != nil {
+				if models.IsErrMergeConflicts(err) || models.IsErrRebaseConflicts(err) || models.IsErrMergeUnrelatedHistories(err) ||
+					models.IsErrMergeDivergingFastForwardOnly(err) {
+					eject(reasonConflicts)
+				} else {
+					log.Error("BuildMergeQueueCommit[%d]: %v", pr.ID, err)
+					eject(reasonMergeFailed)
+				}
+				continue
+			}
+			entry.ParentCommitID = parentCommitID
+			entry.CommitID = commitID
+			if err := pull_model.UpdateMergeQueueEntryCommit(ctx, entry); err != nil {
+				log.Error("UpdateMergeQueueEntryCommit[%d]: %v", entry.ID, err)
+				return
+			}
+		}
+
+		if merging {
+			// without status checks on the branch nothing has to be waited for
+			state := structs.CommitStatusSuccess
+			if checkStatus {
+				commitStatuses, _, err := models.GetLatestCommitStatusCtx(ctx, repoID, entry.CommitID, db.ListOptions{})
+				if err != nil {
+					log.Error("GetLatestCommitStatusCtx[%s]: %v", entry.CommitID, err)
+					return
+				}
+				state = commitStatusState(commitStatuses, requiredContexts)
+			}
+
+			switch {
+			case state.IsSuccess():
+				if err := pull_service.MergeFromQueue(ctx, pr, entry.Doer, entry.CommitID); err != nil {
+					log.Error("MergeFromQueue[%d]: %v", pr.ID, err)
+					eject(reasonMergeFailed)
+					continue
+				}
+				if err := pull_model.DeleteMergeQueueEntry(ctx, entry); err != nil {
+					log.Error("DeleteMergeQueueEntry[%d]: %v", entry.ID, err)
+				}
+			case state.IsPending():
+				merging = false
+			default:
+				// like a pull request merged directly, a warning does not count as a success
+				eject(reasonChecksFailed)
+				continue
+			}
+		}
+
+		parentCommitID = entry.CommitID
+	}
+}
+
+// commitStatusState returns the combined state of the status checks of a temporary merge commit.
+// Missing required contexts are pending, a commit without any status is a success if no context is required.
+func commitStatusState(commitStatuses []*models.CommitStatus, requiredContexts []string) structs.CommitStatusState {
+	if len(requiredContexts) == 0 && len(commitStatuses) == 0 {
+		return structs.CommitStatusSuccess
+	}
+	return pull_service.MergeRequiredContextsCommitStatus(commitStatuses, requiredContexts)
+}
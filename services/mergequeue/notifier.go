@@ -0,0 +1,75 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package mergequeue
+
+import (
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/models/db"
+	pull_model "code.gitea.io/gitea/models/pull"
+	repo_model "code.gitea.io/gitea/models/repo"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/notification/base"
+	"code.gitea.io/gitea/modules/repository"
+)
+
+// mergeQueueNotifier processes the merge queues affected by changes of their branches and pull requests
+type mergeQueueNotifier struct {
+	base.NullNotifier
+}
+
+var _ base.Notifier = &mergeQueueNotifier{}
+
+// NotifyPushCommits rebuilds the merge queue of a branch when the branch is pushed to
+func (n *mergeQueueNotifier) NotifyPushCommits(pusher *user_model.User, repo *repo_model.Repository, opts *repository.PushUpdateOptions, commits *repository.PushCommits) {
+	if !opts.IsBranch() || opts.IsDelRef() {
+		return
+	}
+
+	branch := opts.BranchName()
+	has, err := pull_model.HasMergeQueueEntries(db.DefaultContext, repo.ID, branch)
+	if err != nil {
+		log.Error("HasMergeQueueEntries[%d, %s]: %v", repo.ID, branch, err)
+		return
+	}
+	if has {
+		addToQueue(repo.ID, branch)
+	}
+}
+
+// NotifyPullRequestSynchronized removes a pull request from the merge queue when its head changes
+func (n *mergeQueueNotifier) NotifyPullRequestSynchronized(doer *user_model.User, pr *models.PullRequest) {
+	notifyPullRequestChanged(pr)
+}
+
+// NotifyPullRequestChangeTargetBranch removes a pull request from the merge queue when its base changes
+func (n *mergeQueueNotifier) NotifyPullRequestChangeTargetBranch(doer *user_model.User, pr *models.PullRequest, oldBranch string) {
+	notifyPullRequestChanged(pr)
+}
+
+// NotifyIssueChangeStatus removes a pull request from the merge queue when it is closed
+func (n *mergeQueueNotifier) NotifyIssueChangeStatus(doer *user_model.User, issue *models.Issue, actionComment *models.Comment, isClosed bool) {
+	if !issue.IsPull || !isClosed {
+		return
+	}
+	if err := issue.LoadPullRequest(); err != nil {
+		log.Error("LoadPullRequest[%d]: %v", issue.ID, err)
+		return
+	}
+	notifyPullRequestChanged(issue.PullRequest)
+}
+
+// notifyPullRequestChanged processes the merge queue the pull request is in, if any,
+// which removes the pull request if it cannot be merged as queued anymore
+func notifyPullRequestChanged(pr *models.PullRequest) {
+	entry, err := pull_model.GetMergeQueueEntryByPullID(db.DefaultContext, pr.ID)
+	if err != nil {
+		if !db.IsErrNotExist(err) {
+			log.Error("GetMergeQueueEntryByPullID[%d]: %v", pr.ID, err)
+		}
+		return
+	}
+	addToQueue(entry.RepoID, entry.BaseBranch)
+}
@@ -0,0 +1,294 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package alpine
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	packages_model "code.gitea.io/gitea/models/packages"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/json"
+	alpine_module "code.gitea.io/gitea/modules/packages/alpine"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+const (
+	settingKeyPrivate = "alpine.key.private"
+	settingKeyPublic  = "alpine.key.public"
+)
+
+// GetOrCreateKeyPair gets or creates the RSA keys used to sign the repository index
+func GetOrCreateKeyPair(ownerID int64) (string, string, error) {
+	priv, err := user_model.GetUserSetting(ownerID, settingKeyPrivate)
+	if err != nil {
+		return "", "", err
+	}
+
+	pub, err := user_model.GetUserSetting(ownerID, settingKeyPublic)
+	if err != nil {
+		return "", "", err
+	}
+
+	if priv == "" || pub == "" {
+		priv, pub, err = generateKeyPair()
+		if err != nil {
+			return "", "", err
+		}
+
+		if err := user_model.SetUserSetting(ownerID, settingKeyPrivate, priv); err != nil {
+			return "", "", err
+		}
+
+		if err := user_model.SetUserSetting(ownerID, settingKeyPublic, pub); err != nil {
+			return "", "", err
+		}
+	}
+
+	return priv, pub, nil
+}
+
+func generateKeyPair() (string, string, error) {
+	priv, err := rsa.GenerateKey(rand.Reader, 4096)
+	if err != nil {
+		return "", "", err
+	}
+
+	privPem := pem.EncodeToMemory(&pem.Block{
+		Type:  "RSA PRIVATE KEY",
+		Bytes: x509.MarshalPKCS1PrivateKey(priv),
+	})
+
+	pubBytes, err := x509.MarshalPKIXPublicKey(&priv.PublicKey)
+	if err != nil {
+		return "", "", err
+	}
+
+	pubPem := pem.EncodeToMemory(&pem.Block{
+		Type:  "PUBLIC KEY",
+		Bytes: pubBytes,
+	})
+
+	return string(privPem), string(pubPem), nil
+}
+
+// KeyName returns the name of the public key file
+// apk looks up the key with this name in /etc/apk/keys to verify the index signature.
+func KeyName(owner *user_model.User) string {
+	return fmt.Sprintf("%s@%s.rsa.pub", owner.LowerName, setting.Domain)
+}
+
+// SearchPackageFiles gets the files of a branch and repository, optionally filtered by architecture
+func SearchPackageFiles(ctx context.Context, ownerID int64, branch, repository, architecture string) ([]*packages_model.PackageFile, error) {
+	props := map[string]string{
+		alpine_module.PropertyBranch:     branch,
+		alpine_module.PropertyRepository: repository,
+	}
+	if architecture != "" {
+		props[alpine_module.PropertyArchitecture] = architecture
+	}
+
+	pfs, _, err := packages_model.SearchFiles(ctx, &packages_model.PackageFileSearchOptions{
+		OwnerID:     ownerID,
+		PackageType: string(packages_model.TypeAlpine),
+		Properties:  props,
+	})
+	return pfs, err
+}
+
+// BuildIndex creates the signed "APKINDEX.tar.gz" of the branch, repository and architecture.
+// Packages built for the "noarch" architecture are part of every index.
+// https://wiki.alpinelinux.org/wiki/Apk_spec#APKINDEX_Format
+func BuildIndex(ctx context.Context, owner *user_model.User, branch, repository, architecture string) ([]byte, error) {
+	pfs, err := SearchPackageFiles(ctx, owner.ID, branch, repository, architecture)
+	if err != nil {
+		return nil, err
+	}
+	if architecture != alpine_module.NoArch {
+		pfsNoArch, err := SearchPackageFiles(ctx, owner.ID, branch, repository, alpine_module.NoArch)
+		if err != nil {
+			return nil, err
+		}
+		pfs = append(pfs, pfsNoArch...)
+	}
+	if len(pfs) == 0 {
+		return nil, packages_model.ErrPackageNotExist
+	}
+
+	sort.Slice(pfs, func(i, j int) bool {
+		return pfs[i].LowerName < pfs[j].LowerName
+	})
+
+	var index bytes.Buffer
+	for _, pf := range pfs {
+		pfd, err := packages_model.GetPackageFileDescriptor(ctx, pf)
+		if err != nil {
+			return nil, err
+		}
+		pv, err := packages_model.GetVersionByID(ctx, pf.VersionID)
+		if err != nil {
+			return nil, err
+		}
+		p, err := packages_model.GetPackageByID(ctx, pv.PackageID)
+		if err != nil {
+			return nil, err
+		}
+
+		var vm *alpine_module.VersionMetadata
+		if err := json.Unmarshal([]byte(pv.MetadataJSON), &vm); err != nil {
+			return nil, err
+		}
+		var fm *alpine_module.FileMetadata
+		if err := json.Unmarshal([]byte(pfd.Properties.GetByName(alpine_module.PropertyMetadata)), &fm); err != nil {
+			return nil, err
+		}
+
+		fmt.Fprintf(&index, "C:%s\n", fm.Checksum)
+		fmt.Fprintf(&index, "P:%s\n", p.Name)
+		fmt.Fprintf(&index, "V:%s\n", pv.Version)
+		fmt.Fprintf(&index, "A:%s\n", fm.Architecture)
+		fmt.Fprintf(&index, "S:%d\n", pfd.Blob.Size)
+		fmt.Fprintf(&index, "I:%d\n", fm.Size)
+		if vm.Description != "" {
+			fmt.Fprintf(&index, "T:%s\n", vm.Description)
+		}
+		if vm.ProjectURL != "" {
+			fmt.Fprintf(&index, "U:%s\n", vm.ProjectURL)
+		}
+		if vm.License != "" {
+			fmt.Fprintf(&index, "L:%s\n", vm.License)
+		}
+		if fm.Origin != "" {
+			fmt.Fprintf(&index, "o:%s\n", fm.Origin)
+		}
+		if vm.Maintainer != "" {
+			fmt.Fprintf(&index, "m:%s\n", vm.Maintainer)
+		}
+		if fm.BuildDate != 0 {
+			fmt.Fprintf(&index, "t:%d\n", fm.BuildDate)
+		}
+		if fm.CommitHash != "" {
+			fmt.Fprintf(&index, "c:%s\n", fm.CommitHash)
+		}
+		if fm.ProviderPriority != 0 {
+			fmt.Fprintf(&index, "k:%d\n", fm.ProviderPriority)
+		}
+		if len(fm.Dependencies) > 0 {
+			fmt.Fprintf(&index, "D:%s\n", strings.Join(fm.Dependencies, " "))
+		}
+		if len(fm.Provides) > 0 {
+			fmt.Fprintf(&index, "p:%s\n", strings.Join(fm.Provides, " "))
+		}
+		if fm.InstallIf != "" {
+			fmt.Fprintf(&index, "i:%s\n", fm.InstallIf)
+		}
+		fmt.Fprint(&index, "\n")
+	}
+
+	unsigned, err := writeTarGz(map[string][]byte{
+		"DESCRIPTION": []byte(fmt.Sprintf("%s %s/%s", setting.AppName, branch, repository)),
+		"APKINDEX":    index.Bytes(),
+	}, true)
+	if err != nil {
+		return nil, err
+	}
+
+	signature, err := signIndex(owner, unsigned)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(signature, unsigned...), nil
+}
+
+// signIndex creates the signature stream which is prepended to the index.
+// The signature is a RSA PKCS#1 v1.5 signature of the SHA1 hash of the gzip compressed index.
+func signIndex(owner *user_model.User, content []byte) ([]byte, error) {
+	priv, _, err := GetOrCreateKeyPair(owner.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode([]byte(priv))
+	if block == nil {
+		return nil, errors.New("failed to decode private key pem")
+	}
+
+	privateKey, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	h := sha1.New()
+	if _, err := h.Write(content); err != nil {
+		return nil, err
+	}
+
+	sign, err := rsa.SignPKCS1v15(rand.Reader, privateKey, crypto.SHA1, h.Sum(nil))
+	if err != nil {
+		return nil, err
+	}
+
+	// the signature stream must not contain the tar end-of-archive blocks
+	return writeTarGz(map[string][]byte{
+		".SIGN.RSA." + KeyName(owner): sign,
+	}, false)
+}
+
+func writeTarGz(files map[string][]byte, addEndOfArchive bool) ([]byte, error) {
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(zw)
+
+	for _, name := range names {
+		content := files[name]
+
+		if err := tw.WriteHeader(&tar.Header{
+			Name: name,
+			Mode: 0o600,
+			Size: int64(len(content)),
+		}); err != nil {
+			return nil, err
+		}
+		if _, err := io.Copy(tw, bytes.NewReader(content)); err != nil {
+			return nil, err
+		}
+	}
+
+	var err error
+	if addEndOfArchive {
+		err = tw.Close()
+	} else {
+		err = tw.Flush()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
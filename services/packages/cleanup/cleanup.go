@@ -0,0 +1,137 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package cleanup
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"code.gitea.io/gitea/models/db"
+	packages_model "code.gitea.io/gitea/models/packages"
+	"code.gitea.io/gitea/modules/log"
+	packages_service "code.gitea.io/gitea/services/packages"
+	container_service "code.gitea.io/gitea/services/packages/container"
+	rpm_service "code.gitea.io/gitea/services/packages/rpm"
+)
+
+// ExecuteCleanupRules removes the package versions matching the enabled cleanup rules
+func ExecuteCleanupRules(ctx context.Context) error {
+	pcrs, err := packages_model.GetEnabledCleanupRules(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, pcr := range pcrs {
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("aborted package cleanup before rule %d", pcr.ID)
+		default:
+		}
+
+		if err := executeCleanupRule(pcr); err != nil {
+			log.Error("Error executing package cleanup rule %d: %v", pcr.ID, err)
+		}
+	}
+
+	return nil
+}
+
+func executeCleanupRule(pcr *packages_model.PackageCleanupRule) error {
+	ctx, committer, err := db.TxContext()
+	if err != nil {
+		return err
+	}
+	defer committer.Close()
+
+	pvs, err := GetVersionsToRemove(ctx, pcr)
+	if err != nil {
+		return err
+	}
+	if len(pvs) == 0 {
+		return nil
+	}
+
+	for _, pv := range pvs {
+		log.Debug("Package cleanup rule %d removes package version %d", pcr.ID, pv.ID)
+
+		if err := packages_service.DeletePackageVersionAndReferences(ctx, pv); err != nil {
+			return err
+		}
+	}
+
+	if err := committer.Commit(); err != nil {
+		return err
+	}
+
+	if pcr.Type == packages_model.TypeRPM {
+		return rpm_service.BuildRepositoryFiles(pcr.OwnerID)
+	}
+	return nil
+}
+
+// GetCleanupPreview gets the package versions which would be removed by the cleanup rule
+func GetCleanupPreview(ctx context.Context, pcr *packages_model.PackageCleanupRule) ([]*packages_model.PackageDescriptor, error) {
+	pvs, err := GetVersionsToRemove(ctx, pcr)
+	if err != nil {
+		return nil, err
+	}
+	return packages_model.GetPackageDescriptors(ctx, pvs)
+}
+
+// GetVersionsToRemove gets the package versions matching the cleanup rule
+// The newest KeepCount versions of every package and versions matching the keep pattern are never selected.
+func GetVersionsToRemove(ctx context.Context, pcr *packages_model.PackageCleanupRule) ([]*packages_model.PackageVersion, error) {
+	if err := pcr.CompilePatterns(); err != nil {
+		return nil, err
+	}
+
+	ps, err := packages_model.GetPackagesByType(ctx, pcr.OwnerID, pcr.Type)
+	if err != nil {
+		return nil, err
+	}
+
+	olderThan := time.Now().AddDate(0, 0, -pcr.RemoveDays)
+
+	toRemove := make([]*packages_model.PackageVersion, 0, 10)
+	for _, p := range ps {
+		if pcr.NamePatternMatcher != nil && !pcr.NamePatternMatcher.MatchString(p.LowerName) {
+			continue
+		}
+
+		pvs, _, err := packages_model.SearchVersions(ctx, &packages_model.PackageSearchOptions{
+			PackageID:  p.ID,
+			IsInternal: false,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		for i, pv := range pvs {
+			if i < pcr.KeepCount {
+				continue
+			}
+			if pcr.KeepPatternMatcher != nil && pcr.KeepPatternMatcher.MatchString(pv.LowerVersion) {
+				continue
+			}
+			if pcr.RemoveDays > 0 && pv.CreatedUnix.AsTime().After(olderThan) {
+				continue
+			}
+			if p.Type == packages_model.TypeContainer {
+				skip, err := container_service.ShouldBeSkippedByCleanup(ctx, pv)
+				if err != nil {
+					return nil, err
+				}
+				if skip {
+					continue
+				}
+			}
+
+			toRemove = append(toRemove, pv)
+		}
+	}
+
+	return toRemove, nil
+}
@@ -0,0 +1,248 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package conda
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	packages_model "code.gitea.io/gitea/models/packages"
+	"code.gitea.io/gitea/modules/json"
+	conda_module "code.gitea.io/gitea/modules/packages/conda"
+)
+
+// RepositoryData is the content of a "repodata.json" file
+// https://docs.conda.io/projects/conda-build/en/latest/concepts/generating-index.html#repodata-json
+type RepositoryData struct {
+	Info            RepositoryInfo                     `json:"info"`
+	Packages        map[string]*RepositoryPackageEntry `json:"packages"`
+	PackagesConda   map[string]*RepositoryPackageEntry `json:"packages.conda"`
+	Removed         []string                           `json:"removed"`
+	RepodataVersion int                                `json:"repodata_version"`
+}
+
+// RepositoryInfo contains the subdir of the repository data
+type RepositoryInfo struct {
+	Subdir string `json:"subdir"`
+}
+
+// RepositoryPackageEntry is the entry of a package file in the repository data
+type RepositoryPackageEntry struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	*conda_module.FileMetadata
+	MD5    string `json:"md5"`
+	SHA256 string `json:"sha256"`
+	Size   int64  `json:"size"`
+}
+
+// ChannelData is the content of the "channeldata.json" file
+// https://docs.conda.io/projects/conda-build/en/latest/concepts/generating-index.html#channeldata-json
+type ChannelData struct {
+	ChannelDataVersion int                                 `json:"channeldata_version"`
+	Packages           map[string]*ChannelDataPackageEntry `json:"packages"`
+	Subdirs            []string                            `json:"subdirs"`
+}
+
+// ChannelDataPackageEntry is the entry of a package in the channel data
+type ChannelDataPackageEntry struct {
+	Description   string   `json:"description,omitempty"`
+	Summary       string   `json:"summary,omitempty"`
+	Home          string   `json:"home,omitempty"`
+	DevURL        string   `json:"dev_url,omitempty"`
+	DocURL        string   `json:"doc_url,omitempty"`
+	License       string   `json:"license,omitempty"`
+	LicenseFamily string   `json:"license_family,omitempty"`
+	Version       string   `json:"version"`
+	Subdirs       []string `json:"subdirs"`
+	Timestamp     int64    `json:"timestamp,omitempty"`
+}
+
+// SearchPackageFiles gets the package files of the owner, optionally filtered by subdir
+func SearchPackageFiles(ctx context.Context, ownerID int64, subdir string) ([]*packages_model.PackageFile, error) {
+	opts := &packages_model.PackageFileSearchOptions{
+		OwnerID:     ownerID,
+		PackageType: string(packages_model.TypeConda),
+	}
+	if subdir != "" {
+		opts.Properties = map[string]string{
+			conda_module.PropertySubdir: subdir,
+		}
+	}
+
+	pfs, _, err := packages_model.SearchFiles(ctx, opts)
+	return pfs, err
+}
+
+type fileInfo struct {
+	Package *packages_model.Package
+	Version *packages_model.PackageVersion
+	File    *packages_model.PackageFileDescriptor
+}
+
+func loadFileInfos(ctx context.Context, pfs []*packages_model.PackageFile) ([]*fileInfo, error) {
+	packages := make(map[int64]*packages_model.Package)
+	versions := make(map[int64]*packages_model.PackageVersion)
+
+	infos := make([]*fileInfo, 0, len(pfs))
+	for _, pf := range pfs {
+		pv, ok := versions[pf.VersionID]
+		if !ok {
+			var err error
+			pv, err = packages_model.GetVersionByID(ctx, pf.VersionID)
+			if err != nil {
+				return nil, err
+			}
+			versions[pv.ID] = pv
+		}
+
+		p, ok := packages[pv.PackageID]
+		if !ok {
+			var err error
+			p, err = packages_model.GetPackageByID(ctx, pv.PackageID)
+			if err != nil {
+				return nil, err
+			}
+			packages[p.ID] = p
+		}
+
+		pfd, err := packages_model.GetPackageFileDescriptor(ctx, pf)
+		if err != nil {
+			return nil, err
+		}
+
+		infos = append(infos, &fileInfo{
+			Package: p,
+			Version: pv,
+			File:    pfd,
+		})
+	}
+	return infos, nil
+}
+
+// BuildRepositoryData creates the "repodata.json" content of a subdir
+// An unknown subdir results in empty repository data because conda always requests the "noarch" subdir.
+func BuildRepositoryData(ctx context.Context, ownerID int64, subdir string) (*RepositoryData, error) {
+	pfs, err := SearchPackageFiles(ctx, ownerID, subdir)
+	if err != nil {
+		return nil, err
+	}
+
+	infos, err := loadFileInfos(ctx, pfs)
+	if err != nil {
+		return nil, err
+	}
+
+	rd := &RepositoryData{
+		Info: RepositoryInfo{
+			Subdir: subdir,
+		},
+		Packages:        make(map[string]*RepositoryPackageEntry),
+		PackagesConda:   make(map[string]*RepositoryPackageEntry),
+		Removed:         []string{},
+		RepodataVersion: 1,
+	}
+
+	for _, info := range infos {
+		var fm *conda_module.FileMetadata
+		if err := json.Unmarshal([]byte(info.File.Properties.GetByName(conda_module.PropertyMetadata)), &fm); err != nil {
+			return nil, err
+		}
+
+		entry := &RepositoryPackageEntry{
+			Name:         info.Package.Name,
+			Version:      info.Version.Version,
+			FileMetadata: fm,
+			MD5:          info.File.Blob.HashMD5,
+			SHA256:       info.File.Blob.HashSHA256,
+			Size:         info.File.Blob.Size,
+		}
+
+		if strings.HasSuffix(info.File.File.Name, ".conda") {
+			rd.PackagesConda[info.File.File.Name] = entry
+		} else {
+			rd.Packages[info.File.File.Name] = entry
+		}
+	}
+
+	return rd, nil
+}
+
+// BuildChannelData creates the "channeldata.json" content of the owner
+// The package entries contain the metadata of the latest uploaded version.
+func BuildChannelData(ctx context.Context, ownerID int64) (*ChannelData, error) {
+	pfs, err := SearchPackageFiles(ctx, ownerID, "")
+	if err != nil {
+		return nil, err
+	}
+
+	infos, err := loadFileInfos(ctx, pfs)
+	if err != nil {
+		return nil, err
+	}
+
+	cd := &ChannelData{
+		ChannelDataVersion: 1,
+		Packages:           make(map[string]*ChannelDataPackageEntry),
+		Subdirs:            []string{},
+	}
+
+	subdirs := make(map[string]bool)
+	packageSubdirs := make(map[string]map[string]bool)
+	latestCreated := make(map[string]int64)
+
+	for _, info := range infos {
+		subdir := info.File.Properties.GetByName(conda_module.PropertySubdir)
+		subdirs[subdir] = true
+
+		if _, ok := packageSubdirs[info.Package.Name]; !ok {
+			packageSubdirs[info.Package.Name] = make(map[string]bool)
+		}
+		packageSubdirs[info.Package.Name][subdir] = true
+
+		if created, ok := latestCreated[info.Package.Name]; ok && created >= int64(info.Version.CreatedUnix) {
+			continue
+		}
+		latestCreated[info.Package.Name] = int64(info.Version.CreatedUnix)
+
+		var vm *conda_module.VersionMetadata
+		if err := json.Unmarshal([]byte(info.Version.MetadataJSON), &vm); err != nil {
+			return nil, err
+		}
+		var fm *conda_module.FileMetadata
+		if err := json.Unmarshal([]byte(info.File.Properties.GetByName(conda_module.PropertyMetadata)), &fm); err != nil {
+			return nil, err
+		}
+
+		cd.Packages[info.Package.Name] = &ChannelDataPackageEntry{
+			Description:   vm.Description,
+			Summary:       vm.Summary,
+			Home:          vm.ProjectURL,
+			DevURL:        vm.RepositoryURL,
+			DocURL:        vm.DocumentationURL,
+			License:       vm.License,
+			LicenseFamily: vm.LicenseFamily,
+			Version:       info.Version.Version,
+			Timestamp:     fm.Timestamp,
+		}
+	}
+
+	for name, entry := range cd.Packages {
+		entry.Subdirs = sortedKeys(packageSubdirs[name])
+	}
+	cd.Subdirs = sortedKeys(subdirs)
+
+	return cd, nil
+}
+
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
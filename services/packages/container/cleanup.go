@@ -8,8 +8,10 @@ import (
 	"context"
 	"time"
 
+	"code.gitea.io/gitea/models/db"
 	packages_model "code.gitea.io/gitea/models/packages"
 	container_model "code.gitea.io/gitea/models/packages/container"
+	container_module "code.gitea.io/gitea/modules/packages/container"
 	"code.gitea.io/gitea/modules/util"
 )
 
@@ -78,3 +80,24 @@ func cleanupExpiredUploadedBlobs(ctx context.Context, olderThan time.Duration) e
 
 	return nil
 }
+
+// ShouldBeSkippedByCleanup checks if a cleanup rule must keep the image version
+// The "latest" tag and manifests referenced by a multi-arch image are always kept.
+func ShouldBeSkippedByCleanup(ctx context.Context, pv *packages_model.PackageVersion) (bool, error) {
+	if pv.LowerVersion == "latest" {
+		return true, nil
+	}
+
+	_, count, err := packages_model.SearchVersions(ctx, &packages_model.PackageSearchOptions{
+		PackageID: pv.PackageID,
+		Properties: map[string]string{
+			container_module.PropertyManifestReference: pv.LowerVersion,
+		},
+		IsInternal: false,
+		Paginator:  db.NewAbsoluteListOptions(0, 1),
+	})
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
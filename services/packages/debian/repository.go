@@ -0,0 +1,278 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package debian
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	packages_model "code.gitea.io/gitea/models/packages"
+	user_model "code.gitea.io/gitea/models/user"
+	packages_module "code.gitea.io/gitea/modules/packages"
+	debian_module "code.gitea.io/gitea/modules/packages/debian"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/timeutil"
+)
+
+const (
+	settingKeyPrivate = "debian.key.private"
+	settingKeyPublic  = "debian.key.public"
+	// settingKeyReleasePrefix stores the generation time and the hash of the indices of a distribution
+	settingKeyReleasePrefix = "debian.release."
+)
+
+// GetOrCreateKeyPair gets or creates the PGP keys used to sign repository files
+func GetOrCreateKeyPair(ownerID int64) (string, string, error) {
+	priv, err := user_model.GetUserSetting(ownerID, settingKeyPrivate)
+	if err != nil {
+		return "", "", err
+	}
+
+	pub, err := user_model.GetUserSetting(ownerID, settingKeyPublic)
+	if err != nil {
+		return "", "", err
+	}
+
+	if priv == "" || pub == "" {
+		priv, pub, err = packages_module.GeneratePGPKeyPair(setting.AppName, "Debian Registry")
+		if err != nil {
+			return "", "", err
+		}
+
+		if err := user_model.SetUserSetting(ownerID, settingKeyPrivate, priv); err != nil {
+			return "", "", err
+		}
+
+		if err := user_model.SetUserSetting(ownerID, settingKeyPublic, pub); err != nil {
+			return "", "", err
+		}
+	}
+
+	return priv, pub, nil
+}
+
+// SearchPackageFiles gets the files of a distribution, optionally filtered by component and architecture
+func SearchPackageFiles(ctx context.Context, ownerID int64, distribution, component, architecture string) ([]*packages_model.PackageFile, error) {
+	props := map[string]string{
+		debian_module.PropertyDistribution: distribution,
+	}
+	if component != "" {
+		props[debian_module.PropertyComponent] = component
+	}
+	if architecture != "" {
+		props[debian_module.PropertyArchitecture] = architecture
+	}
+
+	pfs, _, err := packages_model.SearchFiles(ctx, &packages_model.PackageFileSearchOptions{
+		OwnerID:     ownerID,
+		PackageType: string(packages_model.TypeDebian),
+		Properties:  props,
+	})
+	return pfs, err
+}
+
+// BuildPackagesIndex writes the "Packages" index of the component and architecture.
+// Packages built for the "all" architecture are part of every index.
+func BuildPackagesIndex(ctx context.Context, ownerID int64, distribution, component, architecture string, w io.Writer) error {
+	pfs, err := SearchPackageFiles(ctx, ownerID, distribution, component, architecture)
+	if err != nil {
+		return err
+	}
+	if architecture != "all" {
+		pfsAll, err := SearchPackageFiles(ctx, ownerID, distribution, component, "all")
+		if err != nil {
+			return err
+		}
+		pfs = append(pfs, pfsAll...)
+	}
+
+	sort.Slice(pfs, func(i, j int) bool {
+		return pfs[i].LowerName < pfs[j].LowerName
+	})
+
+	for _, pf := range pfs {
+		pfd, err := packages_model.GetPackageFileDescriptor(ctx, pf)
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(w, "%s\n", pfd.Properties.GetByName(debian_module.PropertyControl))
+		fmt.Fprintf(w, "Filename: pool/%s/%s/%s\n", distribution, component, pf.Name)
+		fmt.Fprintf(w, "Size: %d\n", pfd.Blob.Size)
+		fmt.Fprintf(w, "MD5sum: %s\n", pfd.Blob.HashMD5)
+		fmt.Fprintf(w, "SHA1: %s\n", pfd.Blob.HashSHA1)
+		fmt.Fprintf(w, "SHA256: %s\n", pfd.Blob.HashSHA256)
+		fmt.Fprintf(w, "SHA512: %s\n\n", pfd.Blob.HashSHA512)
+	}
+
+	return nil
+}
+
+// CompressIndex returns the gzip compressed index
+func CompressIndex(index []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(index); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+type indexFile struct {
+	Path    string
+	Content []byte
+}
+
+// BuildReleaseFile creates the "Release" file of the distribution which lists the hashes of all indices
+func BuildReleaseFile(ctx context.Context, owner *user_model.User, distribution string) ([]byte, error) {
+	pfs, err := SearchPackageFiles(ctx, owner.ID, distribution, "", "")
+	if err != nil {
+		return nil, err
+	}
+	if len(pfs) == 0 {
+		return nil, packages_model.ErrPackageNotExist
+	}
+
+	componentSet := make(map[string]bool)
+	architectureSet := make(map[string]bool)
+	for _, pf := range pfs {
+		pps, err := packages_model.GetProperties(ctx, packages_model.PropertyTypeFile, pf.ID)
+		if err != nil {
+			return nil, err
+		}
+		ppl := packages_model.PackagePropertyList(pps)
+		componentSet[ppl.GetByName(debian_module.PropertyComponent)] = true
+		architectureSet[ppl.GetByName(debian_module.PropertyArchitecture)] = true
+	}
+
+	components := make([]string, 0, len(componentSet))
+	for component := range componentSet {
+		components = append(components, component)
+	}
+	sort.Strings(components)
+
+	architectures := make([]string, 0, len(architectureSet))
+	for architecture := range architectureSet {
+		architectures = append(architectures, architecture)
+	}
+	sort.Strings(architectures)
+
+	files := make([]*indexFile, 0, len(components)*len(architectures)*2)
+	for _, component := range components {
+		for _, architecture := range architectures {
+			var buf bytes.Buffer
+			if err := BuildPackagesIndex(ctx, owner.ID, distribution, component, architecture, &buf); err != nil {
+				return nil, err
+			}
+			compressed, err := CompressIndex(buf.Bytes())
+			if err != nil {
+				return nil, err
+			}
+
+			path := fmt.Sprintf("%s/binary-%s/Packages", component, architecture)
+			files = append(files, &indexFile{path, buf.Bytes()}, &indexFile{path + ".gz", compressed})
+		}
+	}
+
+	indexHash := sha256.New()
+	for _, f := range files {
+		fmt.Fprintf(indexHash, "%s %x\n", f.Path, sha256.Sum256(f.Content))
+	}
+	generated, err := getIndexGenerationTime(owner.ID, distribution, fmt.Sprintf("%x", indexHash.Sum(nil)))
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "Origin: %s\n", owner.Name)
+	fmt.Fprintf(&buf, "Label: %s\n", owner.Name)
+	fmt.Fprintf(&buf, "Suite: %s\n", distribution)
+	fmt.Fprintf(&buf, "Codename: %s\n", distribution)
+	fmt.Fprintf(&buf, "Date: %s\n", generated.AsTime().UTC().Format(time.RFC1123))
+	fmt.Fprintf(&buf, "Architectures: %s\n", strings.Join(architectures, " "))
+	fmt.Fprintf(&buf, "Components: %s\n", strings.Join(components, " "))
+
+	for _, h := range []struct {
+		Name string
+		Sum  func([]byte) string
+	}{
+		{"MD5Sum", func(b []byte) string { return fmt.Sprintf("%x", md5.Sum(b)) }},
+		{"SHA1", func(b []byte) string { return fmt.Sprintf("%x", sha1.Sum(b)) }},
+		{"SHA256", func(b []byte) string { return fmt.Sprintf("%x", sha256.Sum256(b)) }},
+		{"SHA512", func(b []byte) string { return fmt.Sprintf("%x", sha512.Sum512(b)) }},
+	} {
+		fmt.Fprintf(&buf, "%s:\n", h.Name)
+		for _, f := range files {
+			fmt.Fprintf(&buf, " %s %d %s\n", h.Sum(f.Content), len(f.Content), f.Path)
+		}
+	}
+
+	return buf.Bytes(), nil
+}
+
+// getIndexGenerationTime returns the time the indices of the distribution with the given hash have been generated.
+// The time is stored with the hash so the "Release" file stays unchanged as long as the distribution does not change,
+// and it only increases so clients never consider a newer "Release" file to be older than the one they have.
+func getIndexGenerationTime(ownerID int64, distribution, indexHash string) (timeutil.TimeStamp, error) {
+	// setting keys must be lowercase but distribution names are case sensitive
+	key := fmt.Sprintf("%s%x", settingKeyReleasePrefix, sha1.Sum([]byte(distribution)))
+
+	value, err := user_model.GetUserSetting(ownerID, key)
+	if err != nil {
+		return 0, err
+	}
+
+	var previous timeutil.TimeStamp
+	if parts := strings.SplitN(value, " ", 2); len(parts) == 2 {
+		if unix, err := strconv.ParseInt(parts[0], 10, 64); err == nil {
+			previous = timeutil.TimeStamp(unix)
+			if parts[1] == indexHash {
+				return previous, nil
+			}
+		}
+	}
+
+	generated := timeutil.TimeStampNow()
+	if generated <= previous {
+		generated = previous + 1
+	}
+
+	if err := user_model.SetUserSetting(ownerID, key, fmt.Sprintf("%d %s", generated, indexHash)); err != nil {
+		return 0, err
+	}
+	return generated, nil
+}
+
+// SignReleaseFile creates the inline signed "InRelease" file
+func SignReleaseFile(ownerID int64, release []byte) ([]byte, error) {
+	priv, _, err := GetOrCreateKeyPair(ownerID)
+	if err != nil {
+		return nil, err
+	}
+	return packages_module.PGPClearSign(priv, release)
+}
+
+// SignReleaseFileDetached creates the detached "Release.gpg" signature
+func SignReleaseFileDetached(ownerID int64, release []byte) ([]byte, error) {
+	priv, _, err := GetOrCreateKeyPair(ownerID)
+	if err != nil {
+		return nil, err
+	}
+	return packages_module.PGPDetachSign(priv, bytes.NewReader(release))
+}
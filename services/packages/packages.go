@@ -13,12 +13,14 @@ import (
 
 	"code.gitea.io/gitea/models/db"
 	packages_model "code.gitea.io/gitea/models/packages"
+	quota_model "code.gitea.io/gitea/models/quota"
 	user_model "code.gitea.io/gitea/models/user"
 	"code.gitea.io/gitea/modules/json"
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/notification"
 	packages_module "code.gitea.io/gitea/modules/packages"
 	container_service "code.gitea.io/gitea/services/packages/container"
+	rpm_service "code.gitea.io/gitea/services/packages/rpm"
 )
 
 // PackageInfo describes a package
@@ -75,6 +77,10 @@ func createPackageAndAddFile(pvci *PackageCreationInfo, pfci *PackageFileCreatio
 		return nil, nil, err
 	}
 
+	if err := quota_model.CheckQuota(ctx, pvci.Owner.ID, quota_model.CategoryPackages, pfci.Data.Size()); err != nil {
+		return nil, nil, err
+	}
+
 	pf, pb, blobCreated, err := addFileToPackageVersion(ctx, pv, pfci)
 	removeBlob := false
 	defer func() {
@@ -118,11 +124,31 @@ func createPackageAndVersion(ctx context.Context, pvci *PackageCreationInfo, all
 		SemverCompatible: pvci.SemverCompatible,
 	}
 	var err error
+	packageCreated := true
 	if p, err = packages_model.TryInsertPackage(ctx, p); err != nil {
 		if err != packages_model.ErrDuplicatePackage {
 			log.Error("Error inserting package: %v", err)
 			return nil, false, err
 		}
+		packageCreated = false
+	}
+
+	// The permissions of an existing package must not depend on the repository referenced by the new metadata
+	if !packageCreated {
+		if err := CheckWritePackage(ctx, pvci.Owner, pvci.Creator, p); err != nil {
+			return nil, false, err
+		}
+	}
+
+	if err := LinkRepositoryFromMetadata(ctx, pvci.Owner, p, pvci.Metadata); err != nil {
+		log.Error("Error linking package to repository: %v", err)
+		return nil, false, err
+	}
+
+	if packageCreated {
+		if err := CheckWritePackage(ctx, pvci.Owner, pvci.Creator, p); err != nil {
+			return nil, false, err
+		}
 	}
 
 	metadataJSON, err := json.Marshal(pvci.Metadata)
@@ -161,7 +187,7 @@ func createPackageAndVersion(ctx context.Context, pvci *PackageCreationInfo, all
 }
 
 // AddFileToExistingPackage adds a file to an existing package. If the package does not exist, ErrPackageNotExist is returned
-func AddFileToExistingPackage(pvi *PackageInfo, pfci *PackageFileCreationInfo) (*packages_model.PackageVersion, *packages_model.PackageFile, error) {
+func AddFileToExistingPackage(doer *user_model.User, pvi *PackageInfo, pfci *PackageFileCreationInfo) (*packages_model.PackageVersion, *packages_model.PackageFile, error) {
 	ctx, committer, err := db.TxContext()
 	if err != nil {
 		return nil, nil, err
@@ -173,6 +199,19 @@ func AddFileToExistingPackage(pvi *PackageInfo, pfci *PackageFileCreationInfo) (
 		return nil, nil, err
 	}
 
+	p, err := packages_model.GetPackageByID(ctx, pv.PackageID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := CheckWritePackage(ctx, pvi.Owner, doer, p); err != nil {
+		return nil, nil, err
+	}
+
+	if err := quota_model.CheckQuota(ctx, pvi.Owner.ID, quota_model.CategoryPackages, pfci.Data.Size()); err != nil {
+		return nil, nil, err
+	}
+
 	pf, pb, blobCreated, err := addFileToPackageVersion(ctx, pv, pfci)
 	removeBlob := false
 	defer func() {
@@ -293,6 +332,10 @@ func RemovePackageVersion(doer *user_model.User, pv *packages_model.PackageVersi
 		return err
 	}
 
+	if err := CheckWritePackage(ctx, pd.Owner, doer, pd.Package); err != nil {
+		return err
+	}
+
 	log.Trace("Deleting package: %v", pv.ID)
 
 	if err := DeletePackageVersionAndReferences(ctx, pv); err != nil {
@@ -303,6 +346,61 @@ func RemovePackageVersion(doer *user_model.User, pv *packages_model.PackageVersi
 		return err
 	}
 
+	if pd.Package.Type == packages_model.TypeRPM {
+		if err := rpm_service.BuildRepositoryFiles(pd.Owner.ID); err != nil {
+			return err
+		}
+	}
+
+	notification.NotifyPackageDelete(doer, pd)
+
+	return nil
+}
+
+// RemovePackageFileAndVersionIfUnreferenced deletes the package file and the version if there are no more files
+func RemovePackageFileAndVersionIfUnreferenced(doer *user_model.User, pf *packages_model.PackageFile) error {
+	ctx, committer, err := db.TxContext()
+	if err != nil {
+		return err
+	}
+	defer committer.Close()
+
+	pv, err := packages_model.GetVersionByID(ctx, pf.VersionID)
+	if err != nil {
+		return err
+	}
+
+	pd, err := packages_model.GetPackageDescriptor(ctx, pv)
+	if err != nil {
+		return err
+	}
+
+	if err := CheckWritePackage(ctx, pd.Owner, doer, pd.Package); err != nil {
+		return err
+	}
+
+	log.Trace("Deleting package file: %v", pf.ID)
+
+	if err := DeletePackageFile(ctx, pf); err != nil {
+		return err
+	}
+
+	has, err := packages_model.HasVersionFileReferences(ctx, pf.VersionID)
+	if err != nil {
+		return err
+	}
+	if has {
+		return committer.Commit()
+	}
+
+	if err := DeletePackageVersionAndReferences(ctx, pv); err != nil {
+		return err
+	}
+
+	if err := committer.Commit(); err != nil {
+		return err
+	}
+
 	notification.NotifyPackageDelete(doer, pd)
 
 	return nil
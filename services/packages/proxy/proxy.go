@@ -0,0 +1,159 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package proxy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	packages_model "code.gitea.io/gitea/models/packages"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/hostmatcher"
+	proxy_module "code.gitea.io/gitea/modules/proxy"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/validation"
+)
+
+var (
+	// ErrUnsupportedPackageType indicates a package type which can't be proxied
+	ErrUnsupportedPackageType = errors.New("package type does not support an upstream registry")
+	// ErrInvalidUpstreamURL indicates an invalid upstream registry url
+	ErrInvalidUpstreamURL = errors.New("upstream url is invalid")
+	// ErrUpstreamNotExist indicates that the upstream registry does not have the requested file
+	ErrUpstreamNotExist = errors.New("file does not exist in the upstream registry")
+	// ErrUpstreamTooLarge indicates that the requested file exceeds setting.Packages.ProxyLimitSize
+	ErrUpstreamTooLarge = errors.New("file of the upstream registry is too large")
+)
+
+// SupportedTypes are the package types which can fetch missing packages from an upstream registry
+var SupportedTypes = []packages_model.Type{
+	packages_model.TypeMaven,
+	packages_model.TypeNpm,
+	packages_model.TypePyPI,
+}
+
+// IsSupportedType checks if the package type can fetch missing packages from an upstream registry
+func IsSupportedType(packageType packages_model.Type) bool {
+	for _, t := range SupportedTypes {
+		if t == packageType {
+			return true
+		}
+	}
+	return false
+}
+
+func settingKey(packageType packages_model.Type) string {
+	return "packages.proxy." + string(packageType)
+}
+
+// GetUpstreamURL gets the upstream registry url of the owner for the package type
+// An empty string is returned if no upstream registry is configured.
+func GetUpstreamURL(ownerID int64, packageType packages_model.Type) (string, error) {
+	if !IsSupportedType(packageType) {
+		return "", ErrUnsupportedPackageType
+	}
+	return user_model.GetUserSetting(ownerID, settingKey(packageType))
+}
+
+// SetUpstreamURL sets the upstream registry url of the owner for the package type
+func SetUpstreamURL(ownerID int64, packageType packages_model.Type, upstreamURL string) error {
+	if !IsSupportedType(packageType) {
+		return ErrUnsupportedPackageType
+	}
+	if !validation.IsValidURL(upstreamURL) {
+		return ErrInvalidUpstreamURL
+	}
+	return user_model.SetUserSetting(ownerID, settingKey(packageType), strings.TrimSuffix(upstreamURL, "/"))
+}
+
+// RemoveUpstreamURL removes the upstream registry url of the owner for the package type
+func RemoveUpstreamURL(ownerID int64, packageType packages_model.Type) error {
+	if !IsSupportedType(packageType) {
+		return ErrUnsupportedPackageType
+	}
+	return user_model.DeleteUserSetting(ownerID, settingKey(packageType))
+}
+
+func newHTTPClient() *http.Client {
+	allowedHostListValue := setting.Packages.ProxyAllowedHostList
+	if allowedHostListValue == "" {
+		allowedHostListValue = hostmatcher.MatchBuiltinExternal
+	}
+	allowedHostMatcher := hostmatcher.ParseHostMatchList("packages.PROXY_ALLOWED_HOST_LIST", allowedHostListValue)
+
+	return &http.Client{
+		Timeout: setting.Packages.ProxyDownloadTimeout,
+		Transport: &http.Transport{
+			Proxy:       proxy_module.Proxy(),
+			DialContext: hostmatcher.NewDialContext("packages proxy", allowedHostMatcher, nil),
+		},
+	}
+}
+
+// JoinURL appends the escaped path segments to the upstream url
+func JoinURL(upstreamURL string, segments ...string) string {
+	escaped := make([]string, 0, len(segments))
+	for _, segment := range segments {
+		escaped = append(escaped, url.PathEscape(segment))
+	}
+	return strings.TrimSuffix(upstreamURL, "/") + "/" + strings.Join(escaped, "/")
+}
+
+// limitedReadCloser fails with ErrUpstreamTooLarge once more than the remaining bytes are read
+type limitedReadCloser struct {
+	io.ReadCloser
+	remaining int64
+}
+
+func (r *limitedReadCloser) Read(p []byte) (int, error) {
+	// read one byte more than allowed to detect files exceeding the limit
+	if int64(len(p)) > r.remaining+1 {
+		p = p[:r.remaining+1]
+	}
+	n, err := r.ReadCloser.Read(p)
+	r.remaining -= int64(n)
+	if r.remaining < 0 {
+		return n, ErrUpstreamTooLarge
+	}
+	return n, err
+}
+
+// Fetch requests the url from the upstream registry
+// The caller must close the returned reader. Reading more than setting.Packages.ProxyLimitSize fails with ErrUpstreamTooLarge.
+func Fetch(ctx context.Context, requestURL string) (io.ReadCloser, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, requestURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := newHTTPClient().Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode == http.StatusNotFound {
+		resp.Body.Close()
+		return nil, ErrUpstreamNotExist
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("upstream registry responded with status %d for %s", resp.StatusCode, requestURL)
+	}
+
+	limit := setting.Packages.ProxyLimitSize
+	if limit < 0 {
+		return resp.Body, nil
+	}
+	if resp.ContentLength > limit {
+		resp.Body.Close()
+		return nil, ErrUpstreamTooLarge
+	}
+	return &limitedReadCloser{ReadCloser: resp.Body, remaining: limit}, nil
+}
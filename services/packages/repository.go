@@ -0,0 +1,146 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package packages
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strings"
+
+	"code.gitea.io/gitea/models/organization"
+	packages_model "code.gitea.io/gitea/models/packages"
+	"code.gitea.io/gitea/models/perm"
+	access_model "code.gitea.io/gitea/models/perm/access"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/models/unit"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/json"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+// ErrPackageAccessDenied indicates the doer is not allowed to modify the package
+var ErrPackageAccessDenied = errors.New("Package access denied")
+
+// GetRepositoryByURL gets the repository of the owner which is referenced by the url
+// Only urls pointing to this instance are resolved. If no repository matches, nil is returned.
+func GetRepositoryByURL(ctx context.Context, owner *user_model.User, repositoryURL string) (*repo_model.Repository, error) {
+	repositoryURL = strings.TrimPrefix(strings.TrimSpace(repositoryURL), "git+")
+
+	u, err := url.Parse(repositoryURL)
+	if err != nil {
+		return nil, nil
+	}
+
+	var repoPath string
+	switch u.Scheme {
+	case "http", "https":
+		appURL, err := url.Parse(setting.AppURL)
+		if err != nil || !strings.EqualFold(u.Host, appURL.Host) || !strings.HasPrefix(u.Path, appURL.Path) {
+			return nil, nil
+		}
+		repoPath = strings.TrimPrefix(u.Path, appURL.Path)
+	case "ssh":
+		if setting.SSH.Domain == "" || !strings.EqualFold(u.Hostname(), setting.SSH.Domain) {
+			return nil, nil
+		}
+		repoPath = u.Path
+	default:
+		return nil, nil
+	}
+
+	parts := strings.Split(strings.Trim(repoPath, "/"), "/")
+	if len(parts) != 2 || !strings.EqualFold(parts[0], owner.Name) {
+		return nil, nil
+	}
+
+	repo, err := repo_model.GetRepositoryByOwnerAndNameCtx(ctx, owner.Name, strings.TrimSuffix(parts[1], ".git"))
+	if err != nil {
+		if repo_model.IsErrRepoNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return repo, nil
+}
+
+// LinkRepositoryFromMetadata links the package to the repository referenced in the metadata
+// Packages which are already linked are not changed.
+func LinkRepositoryFromMetadata(ctx context.Context, owner *user_model.User, p *packages_model.Package, metadata interface{}) error {
+	if p.RepoID != 0 || metadata == nil {
+		return nil
+	}
+
+	var m struct {
+		RepositoryURL string `json:"repository_url"`
+	}
+	metadataJSON, err := json.Marshal(metadata)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(metadataJSON, &m); err != nil || m.RepositoryURL == "" {
+		return nil
+	}
+
+	repo, err := GetRepositoryByURL(ctx, owner, m.RepositoryURL)
+	if err != nil || repo == nil {
+		return err
+	}
+
+	if err := packages_model.SetRepositoryLink(ctx, p.ID, repo.ID); err != nil {
+		return err
+	}
+	p.RepoID = repo.ID
+	return nil
+}
+
+// CanWritePackage checks if the doer is allowed to modify the package
+// Besides users with write access to the owner, users with write access to the packages unit of the linked repository are allowed.
+// Without a doer the package cannot be modified. Internal callers acting on behalf of the owner, like the caching of
+// upstream packages, pass the owner as doer, the cleanup deletes without access checks by DeletePackageVersionAndReferences.
+func CanWritePackage(ctx context.Context, owner, doer *user_model.User, p *packages_model.Package) (bool, error) {
+	if doer == nil {
+		return false, nil
+	}
+	if doer.IsAdmin || doer.ID == owner.ID {
+		return true, nil
+	}
+
+	if owner.IsOrganization() {
+		mode, err := organization.OrgFromUser(owner).GetOrgUserMaxAuthorizeLevel(doer.ID)
+		if err != nil {
+			return false, err
+		}
+		if mode >= perm.AccessModeWrite {
+			return true, nil
+		}
+	}
+
+	if p.RepoID == 0 {
+		return false, nil
+	}
+
+	repo, err := repo_model.GetRepositoryByIDCtx(ctx, p.RepoID)
+	if err != nil {
+		return false, err
+	}
+	permission, err := access_model.GetUserRepoPermission(ctx, repo, doer)
+	if err != nil {
+		return false, err
+	}
+	return permission.CanWrite(unit.TypePackages), nil
+}
+
+// CheckWritePackage returns ErrPackageAccessDenied if the doer is not allowed to modify the package
+func CheckWritePackage(ctx context.Context, owner, doer *user_model.User, p *packages_model.Package) error {
+	can, err := CanWritePackage(ctx, owner, doer, p)
+	if err != nil {
+		return err
+	}
+	if !can {
+		return ErrPackageAccessDenied
+	}
+	return nil
+}
@@ -0,0 +1,678 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package rpm
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"code.gitea.io/gitea/models/db"
+	packages_model "code.gitea.io/gitea/models/packages"
+	"code.gitea.io/gitea/modules/appstate"
+	"code.gitea.io/gitea/modules/json"
+	"code.gitea.io/gitea/modules/log"
+	packages_module "code.gitea.io/gitea/modules/packages"
+	rpm_module "code.gitea.io/gitea/modules/packages/rpm"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+// signingKeyState stores the instance wide key pair used to sign the repository metadata
+type signingKeyState struct {
+	PrivateKey string `json:"private_key"`
+	PublicKey  string `json:"public_key"`
+}
+
+// Name returns the item name
+func (s signingKeyState) Name() string {
+	return "packages-rpm-signing-key"
+}
+
+// GetOrCreateKeyPair gets or creates the PGP keys used to sign repository metadata files
+func GetOrCreateKeyPair() (string, string, error) {
+	state := new(signingKeyState)
+	if err := appstate.AppState.Get(state); err != nil {
+		return "", "", err
+	}
+
+	if state.PrivateKey == "" || state.PublicKey == "" {
+		priv, pub, err := packages_module.GeneratePGPKeyPair(setting.AppName, "RPM Registry")
+		if err != nil {
+			return "", "", err
+		}
+
+		state.PrivateKey = priv
+		state.PublicKey = pub
+
+		if err := appstate.AppState.Set(state); err != nil {
+			return "", "", err
+		}
+	}
+
+	return state.PrivateKey, state.PublicKey, nil
+}
+
+// GetRepositoryVersion gets the internal version which contains the repository metadata files
+func GetRepositoryVersion(ctx context.Context, ownerID int64) (*packages_model.PackageVersion, error) {
+	return packages_model.GetInternalVersionByNameAndVersion(ctx, ownerID, packages_model.TypeRPM, rpm_module.RepositoryPackage, rpm_module.RepositoryVersion)
+}
+
+func getOrCreateRepositoryVersion(ctx context.Context, ownerID int64) (*packages_model.PackageVersion, error) {
+	p := &packages_model.Package{
+		OwnerID:   ownerID,
+		Type:      packages_model.TypeRPM,
+		Name:      rpm_module.RepositoryPackage,
+		LowerName: rpm_module.RepositoryPackage,
+	}
+	var err error
+	if p, err = packages_model.TryInsertPackage(ctx, p); err != nil {
+		if err != packages_model.ErrDuplicatePackage {
+			log.Error("Error inserting package: %v", err)
+			return nil, err
+		}
+	}
+
+	pv := &packages_model.PackageVersion{
+		PackageID:    p.ID,
+		CreatorID:    ownerID,
+		Version:      rpm_module.RepositoryVersion,
+		LowerVersion: rpm_module.RepositoryVersion,
+		IsInternal:   true,
+		MetadataJSON: "null",
+	}
+	if pv, err = packages_model.GetOrInsertVersion(ctx, pv); err != nil {
+		if err != packages_model.ErrDuplicatePackageVersion {
+			log.Error("Error inserting package version: %v", err)
+			return nil, err
+		}
+	}
+
+	return pv, nil
+}
+
+type packageData struct {
+	Package         *packages_model.Package
+	Version         *packages_model.PackageVersion
+	Blob            *packages_model.PackageBlob
+	VersionMetadata *rpm_module.VersionMetadata
+	FileMetadata    *rpm_module.FileMetadata
+}
+
+type packageCache = map[*packages_model.PackageFile]*packageData
+
+type repoChecksum struct {
+	Value string `xml:",chardata"`
+	Type  string `xml:"type,attr"`
+}
+
+type repoLocation struct {
+	Href string `xml:"href,attr"`
+}
+
+type repoData struct {
+	Type         string       `xml:"type,attr"`
+	Checksum     repoChecksum `xml:"checksum"`
+	OpenChecksum repoChecksum `xml:"open-checksum"`
+	Location     repoLocation `xml:"location"`
+	Timestamp    int64        `xml:"timestamp"`
+	Size         int64        `xml:"size"`
+	OpenSize     int64        `xml:"open-size"`
+}
+
+// BuildRepositoryFiles (re)creates the repository metadata files of the owner.
+// https://docs.pulpproject.org/en/2.19/plugins/pulp_rpm/tech-reference/rpm.html#repodata
+func BuildRepositoryFiles(ownerID int64) error {
+	priv, _, err := GetOrCreateKeyPair()
+	if err != nil {
+		return err
+	}
+
+	return db.WithTx(func(ctx context.Context) error {
+		pfs, _, err := packages_model.SearchFiles(ctx, &packages_model.PackageFileSearchOptions{
+			OwnerID:     ownerID,
+			PackageType: string(packages_model.TypeRPM),
+		})
+		if err != nil {
+			return err
+		}
+
+		// Delete the repository files if there are no packages
+		if len(pfs) == 0 {
+			pv, err := GetRepositoryVersion(ctx, ownerID)
+			if err != nil {
+				if err == packages_model.ErrPackageNotExist {
+					return nil
+				}
+				return err
+			}
+
+			oldFiles, err := packages_model.GetFilesByVersionID(ctx, pv.ID)
+			if err != nil {
+				return err
+			}
+			for _, pf := range oldFiles {
+				if err := packages_model.DeleteFileByID(ctx, pf.ID); err != nil {
+					return err
+				}
+			}
+			return packages_model.DeleteVersionByID(ctx, pv.ID)
+		}
+
+		repositoryVersion, err := getOrCreateRepositoryVersion(ctx, ownerID)
+		if err != nil {
+			return err
+		}
+
+		cache := make(packageCache)
+		for _, pf := range pfs {
+			pv, err := packages_model.GetVersionByID(ctx, pf.VersionID)
+			if err != nil {
+				return err
+			}
+			p, err := packages_model.GetPackageByID(ctx, pv.PackageID)
+			if err != nil {
+				return err
+			}
+			pb, err := packages_model.GetBlobByID(ctx, pf.BlobID)
+			if err != nil {
+				return err
+			}
+			pps, err := packages_model.GetPropertiesByName(ctx, packages_model.PropertyTypeFile, pf.ID, rpm_module.PropertyMetadata)
+			if err != nil {
+				return err
+			}
+
+			pd := &packageData{
+				Package: p,
+				Version: pv,
+				Blob:    pb,
+			}
+
+			if err := json.Unmarshal([]byte(pv.MetadataJSON), &pd.VersionMetadata); err != nil {
+				return err
+			}
+			if len(pps) > 0 {
+				if err := json.Unmarshal([]byte(pps[0].Value), &pd.FileMetadata); err != nil {
+					return err
+				}
+			}
+			if pd.VersionMetadata == nil || pd.FileMetadata == nil {
+				continue
+			}
+
+			cache[pf] = pd
+		}
+
+		primary, err := buildPrimary(ctx, repositoryVersion, pfs, cache)
+		if err != nil {
+			return err
+		}
+		fileLists, err := buildFilelists(ctx, repositoryVersion, pfs, cache)
+		if err != nil {
+			return err
+		}
+		other, err := buildOther(ctx, repositoryVersion, pfs, cache)
+		if err != nil {
+			return err
+		}
+
+		return buildRepomd(ctx, repositoryVersion, priv, []*repoData{primary, fileLists, other})
+	})
+}
+
+// https://docs.pulpproject.org/en/2.19/plugins/pulp_rpm/tech-reference/rpm.html#repomd-xml
+func buildRepomd(ctx context.Context, pv *packages_model.PackageVersion, privateKey string, data []*repoData) error {
+	type Repomd struct {
+		XMLName  xml.Name    `xml:"repomd"`
+		Xmlns    string      `xml:"xmlns,attr"`
+		XmlnsRpm string      `xml:"xmlns:rpm,attr"`
+		Revision int64       `xml:"revision"`
+		Data     []*repoData `xml:"data"`
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	if err := xml.NewEncoder(&buf).Encode(&Repomd{
+		Xmlns:    "http://linux.duke.edu/metadata/repo",
+		XmlnsRpm: "http://linux.duke.edu/metadata/rpm",
+		Revision: time.Now().Unix(),
+		Data:     data,
+	}); err != nil {
+		return err
+	}
+
+	signature, err := packages_module.PGPDetachSign(privateKey, bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		return err
+	}
+
+	for _, file := range []struct {
+		Name string
+		Data []byte
+	}{
+		{"repomd.xml", buf.Bytes()},
+		{"repomd.xml.asc", signature},
+	} {
+		if err := addRepositoryFile(ctx, pv, file.Name, file.Data); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// https://docs.pulpproject.org/en/2.19/plugins/pulp_rpm/tech-reference/rpm.html#primary-xml
+func buildPrimary(ctx context.Context, pv *packages_model.PackageVersion, pfs []*packages_model.PackageFile, c packageCache) (*repoData, error) {
+	type Version struct {
+		Epoch   string `xml:"epoch,attr"`
+		Version string `xml:"ver,attr"`
+		Release string `xml:"rel,attr"`
+	}
+
+	type Checksum struct {
+		Checksum string `xml:",chardata"`
+		Type     string `xml:"type,attr"`
+		Pkgid    string `xml:"pkgid,attr"`
+	}
+
+	type Times struct {
+		File  int64  `xml:"file,attr"`
+		Build uint64 `xml:"build,attr"`
+	}
+
+	type Sizes struct {
+		Package   int64  `xml:"package,attr"`
+		Installed uint64 `xml:"installed,attr"`
+		Archive   uint64 `xml:"archive,attr"`
+	}
+
+	type Location struct {
+		Href string `xml:"href,attr"`
+	}
+
+	type EntryList struct {
+		Entries []*rpm_module.Entry `xml:"rpm:entry"`
+	}
+
+	type Format struct {
+		License   string             `xml:"rpm:license"`
+		Vendor    string             `xml:"rpm:vendor"`
+		Group     string             `xml:"rpm:group"`
+		Buildhost string             `xml:"rpm:buildhost"`
+		Sourcerpm string             `xml:"rpm:sourcerpm"`
+		Provides  EntryList          `xml:"rpm:provides"`
+		Requires  EntryList          `xml:"rpm:requires"`
+		Conflicts EntryList          `xml:"rpm:conflicts"`
+		Obsoletes EntryList          `xml:"rpm:obsoletes"`
+		Files     []*rpm_module.File `xml:"file"`
+	}
+
+	type Package struct {
+		XMLName      xml.Name `xml:"package"`
+		Type         string   `xml:"type,attr"`
+		Name         string   `xml:"name"`
+		Architecture string   `xml:"arch"`
+		Version      Version  `xml:"version"`
+		Checksum     Checksum `xml:"checksum"`
+		Summary      string   `xml:"summary"`
+		Description  string   `xml:"description"`
+		Packager     string   `xml:"packager"`
+		URL          string   `xml:"url"`
+		Time         Times    `xml:"time"`
+		Size         Sizes    `xml:"size"`
+		Location     Location `xml:"location"`
+		Format       Format   `xml:"format"`
+	}
+
+	type Metadata struct {
+		XMLName      xml.Name   `xml:"metadata"`
+		Xmlns        string     `xml:"xmlns,attr"`
+		XmlnsRpm     string     `xml:"xmlns:rpm,attr"`
+		PackageCount int        `xml:"packages,attr"`
+		Packages     []*Package `xml:"package"`
+	}
+
+	packages := make([]*Package, 0, len(pfs))
+	for _, pf := range pfs {
+		pd := c[pf]
+		if pd == nil {
+			continue
+		}
+
+		files := make([]*rpm_module.File, 0, 3)
+		for _, f := range pd.FileMetadata.Files {
+			if isPrimaryFile(f.Path) {
+				files = append(files, f)
+			}
+		}
+
+		packages = append(packages, &Package{
+			Type:         "rpm",
+			Name:         pd.Package.Name,
+			Architecture: pd.FileMetadata.Architecture,
+			Version: Version{
+				Epoch:   epochOrDefault(pd.FileMetadata.Epoch),
+				Version: pd.FileMetadata.Version,
+				Release: pd.FileMetadata.Release,
+			},
+			Checksum: Checksum{
+				Type:     "sha256",
+				Checksum: pd.Blob.HashSHA256,
+				Pkgid:    "YES",
+			},
+			Summary:     pd.VersionMetadata.Summary,
+			Description: pd.VersionMetadata.Description,
+			Packager:    pd.FileMetadata.Packager,
+			URL:         pd.VersionMetadata.ProjectURL,
+			Time: Times{
+				File:  int64(pf.CreatedUnix),
+				Build: pd.FileMetadata.BuildTime,
+			},
+			Size: Sizes{
+				Package:   pd.Blob.Size,
+				Installed: pd.FileMetadata.InstalledSize,
+				Archive:   pd.FileMetadata.ArchiveSize,
+			},
+			Location: Location{
+				Href: "package/" + pf.Name,
+			},
+			Format: Format{
+				License:   pd.VersionMetadata.License,
+				Vendor:    pd.FileMetadata.Vendor,
+				Group:     pd.FileMetadata.Group,
+				Buildhost: pd.FileMetadata.BuildHost,
+				Sourcerpm: pd.FileMetadata.SourceRpm,
+				Provides:  EntryList{pd.FileMetadata.Provides},
+				Requires:  EntryList{pd.FileMetadata.Requires},
+				Conflicts: EntryList{pd.FileMetadata.Conflicts},
+				Obsoletes: EntryList{pd.FileMetadata.Obsoletes},
+				Files:     files,
+			},
+		})
+	}
+
+	return addDataAsFileToRepo(ctx, pv, "primary", &Metadata{
+		Xmlns:        "http://linux.duke.edu/metadata/common",
+		XmlnsRpm:     "http://linux.duke.edu/metadata/rpm",
+		PackageCount: len(packages),
+		Packages:     packages,
+	})
+}
+
+// https://docs.pulpproject.org/en/2.19/plugins/pulp_rpm/tech-reference/rpm.html#filelists-xml
+func buildFilelists(ctx context.Context, pv *packages_model.PackageVersion, pfs []*packages_model.PackageFile, c packageCache) (*repoData, error) {
+	type Version struct {
+		Epoch   string `xml:"epoch,attr"`
+		Version string `xml:"ver,attr"`
+		Release string `xml:"rel,attr"`
+	}
+
+	type Package struct {
+		Pkgid        string             `xml:"pkgid,attr"`
+		Name         string             `xml:"name,attr"`
+		Architecture string             `xml:"arch,attr"`
+		Version      Version            `xml:"version"`
+		Files        []*rpm_module.File `xml:"file"`
+	}
+
+	type Filelists struct {
+		XMLName      xml.Name   `xml:"filelists"`
+		Xmlns        string     `xml:"xmlns,attr"`
+		PackageCount int        `xml:"packages,attr"`
+		Packages     []*Package `xml:"package"`
+	}
+
+	packages := make([]*Package, 0, len(pfs))
+	for _, pf := range pfs {
+		pd := c[pf]
+		if pd == nil {
+			continue
+		}
+
+		packages = append(packages, &Package{
+			Pkgid:        pd.Blob.HashSHA256,
+			Name:         pd.Package.Name,
+			Architecture: pd.FileMetadata.Architecture,
+			Version: Version{
+				Epoch:   epochOrDefault(pd.FileMetadata.Epoch),
+				Version: pd.FileMetadata.Version,
+				Release: pd.FileMetadata.Release,
+			},
+			Files: pd.FileMetadata.Files,
+		})
+	}
+
+	return addDataAsFileToRepo(ctx, pv, "filelists", &Filelists{
+		Xmlns:        "http://linux.duke.edu/metadata/filelists",
+		PackageCount: len(packages),
+		Packages:     packages,
+	})
+}
+
+// https://docs.pulpproject.org/en/2.19/plugins/pulp_rpm/tech-reference/rpm.html#other-xml
+func buildOther(ctx context.Context, pv *packages_model.PackageVersion, pfs []*packages_model.PackageFile, c packageCache) (*repoData, error) {
+	type Version struct {
+		Epoch   string `xml:"epoch,attr"`
+		Version string `xml:"ver,attr"`
+		Release string `xml:"rel,attr"`
+	}
+
+	type Changelog struct {
+		Author string `xml:"author,attr"`
+		Date   uint64 `xml:"date,attr"`
+		Text   string `xml:",chardata"`
+	}
+
+	type Package struct {
+		Pkgid        string       `xml:"pkgid,attr"`
+		Name         string       `xml:"name,attr"`
+		Architecture string       `xml:"arch,attr"`
+		Version      Version      `xml:"version"`
+		Changelogs   []*Changelog `xml:"changelog"`
+	}
+
+	type Otherdata struct {
+		XMLName      xml.Name   `xml:"otherdata"`
+		Xmlns        string     `xml:"xmlns,attr"`
+		PackageCount int        `xml:"packages,attr"`
+		Packages     []*Package `xml:"package"`
+	}
+
+	packages := make([]*Package, 0, len(pfs))
+	for _, pf := range pfs {
+		pd := c[pf]
+		if pd == nil {
+			continue
+		}
+
+		changelogs := make([]*Changelog, 0, len(pd.FileMetadata.Changelogs))
+		for _, cl := range pd.FileMetadata.Changelogs {
+			changelogs = append(changelogs, &Changelog{
+				Author: cl.Author,
+				Date:   cl.Date,
+				Text:   cl.Text,
+			})
+		}
+
+		packages = append(packages, &Package{
+			Pkgid:        pd.Blob.HashSHA256,
+			Name:         pd.Package.Name,
+			Architecture: pd.FileMetadata.Architecture,
+			Version: Version{
+				Epoch:   epochOrDefault(pd.FileMetadata.Epoch),
+				Version: pd.FileMetadata.Version,
+				Release: pd.FileMetadata.Release,
+			},
+			Changelogs: changelogs,
+		})
+	}
+
+	return addDataAsFileToRepo(ctx, pv, "other", &Otherdata{
+		Xmlns:        "http://linux.duke.edu/metadata/other",
+		PackageCount: len(packages),
+		Packages:     packages,
+	})
+}
+
+// isPrimaryFile checks if the file belongs into the primary.xml file
+// https://github.com/rpm-software-management/createrepo_c/blob/master/src/misc.c
+func isPrimaryFile(path string) bool {
+	return strings.HasPrefix(path, "/etc/") || strings.Contains(path, "bin/") || path == "/usr/lib/sendmail"
+}
+
+func epochOrDefault(epoch string) string {
+	if epoch == "" {
+		return "0"
+	}
+	return epoch
+}
+
+// writtenCounter counts all written bytes
+type writtenCounter struct {
+	written int64
+}
+
+func (wc *writtenCounter) Write(buf []byte) (int, error) {
+	n := len(buf)
+	wc.written += int64(n)
+	return n, nil
+}
+
+func addDataAsFileToRepo(ctx context.Context, pv *packages_model.PackageVersion, filetype string, obj interface{}) (*repoData, error) {
+	content, err := packages_module.NewHashedBuffer(32 * 1024 * 1024)
+	if err != nil {
+		return nil, err
+	}
+	defer content.Close()
+
+	gzw := gzip.NewWriter(content)
+	wc := &writtenCounter{}
+	h := sha256.New()
+
+	w := io.MultiWriter(gzw, wc, h)
+	_, _ = w.Write([]byte(xml.Header))
+
+	if err := xml.NewEncoder(w).Encode(obj); err != nil {
+		return nil, err
+	}
+
+	if err := gzw.Close(); err != nil {
+		return nil, err
+	}
+
+	filename := filetype + ".xml.gz"
+
+	if err := addRepositoryFileFromBuffer(ctx, pv, filename, content); err != nil {
+		return nil, err
+	}
+
+	_, _, hashSHA256, _ := content.Sums()
+
+	return &repoData{
+		Type: filetype,
+		Checksum: repoChecksum{
+			Type:  "sha256",
+			Value: hex.EncodeToString(hashSHA256),
+		},
+		OpenChecksum: repoChecksum{
+			Type:  "sha256",
+			Value: hex.EncodeToString(h.Sum(nil)),
+		},
+		Location: repoLocation{
+			Href: "repodata/" + filename,
+		},
+		Timestamp: time.Now().Unix(),
+		Size:      content.Size(),
+		OpenSize:  wc.written,
+	}, nil
+}
+
+func addRepositoryFile(ctx context.Context, pv *packages_model.PackageVersion, filename string, data []byte) error {
+	buf, err := packages_module.CreateHashedBufferFromReader(bytes.NewReader(data), 32*1024*1024)
+	if err != nil {
+		return err
+	}
+	defer buf.Close()
+
+	return addRepositoryFileFromBuffer(ctx, pv, filename, buf)
+}
+
+// addRepositoryFileFromBuffer stores the content as repository file and replaces an existing file with the same name
+func addRepositoryFileFromBuffer(ctx context.Context, pv *packages_model.PackageVersion, filename string, buf *packages_module.HashedBuffer) error {
+	if _, err := buf.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	hashMD5, hashSHA1, hashSHA256, hashSHA512 := buf.Sums()
+
+	pb, exists, err := packages_model.GetOrInsertBlob(ctx, &packages_model.PackageBlob{
+		Size:       buf.Size(),
+		HashMD5:    fmt.Sprintf("%x", hashMD5),
+		HashSHA1:   fmt.Sprintf("%x", hashSHA1),
+		HashSHA256: fmt.Sprintf("%x", hashSHA256),
+		HashSHA512: fmt.Sprintf("%x", hashSHA512),
+	})
+	if err != nil {
+		log.Error("Error inserting package blob: %v", err)
+		return err
+	}
+	if !exists {
+		if err := packages_module.NewContentStore().Save(packages_module.BlobHash256Key(pb.HashSHA256), buf, buf.Size()); err != nil {
+			log.Error("Error saving package blob in content store: %v", err)
+			return err
+		}
+	}
+
+	pf, err := packages_model.GetFileForVersionByName(ctx, pv.ID, filename, packages_model.EmptyFileKey)
+	if err != nil && err != packages_model.ErrPackageFileNotExist {
+		return err
+	}
+	if pf != nil {
+		if pf.BlobID == pb.ID {
+			return nil
+		}
+		if err := packages_model.DeleteFileByID(ctx, pf.ID); err != nil {
+			return err
+		}
+	}
+
+	_, err = packages_model.TryInsertFile(ctx, &packages_model.PackageFile{
+		VersionID:    pv.ID,
+		BlobID:       pb.ID,
+		Name:         filename,
+		LowerName:    strings.ToLower(filename),
+		CompositeKey: packages_model.EmptyFileKey,
+		IsLead:       false,
+	})
+	return err
+}
+
+// GetRepositoryFileStream returns the content of a repository metadata file
+func GetRepositoryFileStream(ctx context.Context, ownerID int64, filename string) (io.ReadCloser, *packages_model.PackageFile, error) {
+	pv, err := GetRepositoryVersion(ctx, ownerID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	pf, err := packages_model.GetFileForVersionByName(ctx, pv.ID, filename, packages_model.EmptyFileKey)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	pb, err := packages_model.GetBlobByID(ctx, pf.BlobID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	s, err := packages_module.NewContentStore().Get(packages_module.BlobHash256Key(pb.HashSHA256))
+	return s, pf, err
+}
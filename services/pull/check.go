@@ -41,6 +41,7 @@ var (
 	ErrIsChecking            = errors.New("cannot merge while conflict checking is in progress")
 	ErrNotMergableState      = errors.New("not in mergeable state")
 	ErrDependenciesLeft      = errors.New("is blocked by an open dependency")
+	ErrMergeQueueRequired    = errors.New("has to be merged through the merge queue")
 )
 
 // AddToTaskQueue adds itself to pull request test task queue.
@@ -278,6 +279,7 @@ func manuallyMerged(ctx context.Context, pr *models.PullRequest) bool {
 		}
 
 		notification.NotifyMergePullRequest(pr, merger)
+		RetargetDependentPulls(pr)
 
 		log.Info("manuallyMerged[%d]: Marked as manually merged into %s/%s by commit id: %s", pr.ID, pr.BaseRepo.Name, pr.BaseBranch, commit.ID.String())
 		return true
@@ -372,5 +374,5 @@ func Init() error {
 
 	go graceful.GetManager().RunWithShutdownFns(prPatchCheckerQueue.Run)
 	go graceful.GetManager().RunWithShutdownContext(InitializePullRequests)
-	return nil
+	return initRetargetQueue()
 }
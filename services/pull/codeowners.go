@@ -0,0 +1,363 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package pull
+
+import (
+	"context"
+	"regexp"
+	"strings"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/models/organization"
+	access_model "code.gitea.io/gitea/models/perm/access"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/models/unit"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/log"
+	issue_service "code.gitea.io/gitea/services/issue"
+)
+
+// CodeOwnersFiles are the paths the CODEOWNERS file is looked up at on the base branch, in order of precedence
+var CodeOwnersFiles = []string{"CODEOWNERS", "docs/CODEOWNERS", ".gitea/CODEOWNERS"}
+
+// codeOwnersMaxSize is the maximum size of a CODEOWNERS file that is read
+const codeOwnersMaxSize = 3 * 1024 * 1024
+
+// CodeOwnerRule represents a line of a CODEOWNERS file
+type CodeOwnerRule struct {
+	Pattern string
+	// Owners are the owners as written: @user, @org/team or an email address
+	Owners []string
+	regexp *regexp.Regexp
+}
+
+// Match returns whether the rule matches the given path
+func (rule *CodeOwnerRule) Match(path string) bool {
+	return rule.regexp.MatchString(path)
+}
+
+// CodeOwnerRules are the rules of a CODEOWNERS file in file order
+type CodeOwnerRules []*CodeOwnerRule
+
+// Match returns the rule applying to path, which is the last matching one, or nil if none does
+func (rules CodeOwnerRules) Match(path string) *CodeOwnerRule {
+	for i := len(rules) - 1; i >= 0; i-- {
+		if rules[i].Match(path) {
+			return rules[i]
+		}
+	}
+	return nil
+}
+
+// ParseCodeOwners parses the content of a CODEOWNERS file. Lines with invalid patterns are skipped.
+func ParseCodeOwners(content string) CodeOwnerRules {
+	rules := make(CodeOwnerRules, 0, 10)
+	for _, line := range strings.Split(content, "\n") {
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		re, err := codeOwnerPatternToRegexp(fields[0])
+		if err != nil {
+			log.Debug("Invalid CODEOWNERS pattern %q: %v", fields[0], err)
+			continue
+		}
+		rules = append(rules, &CodeOwnerRule{
+			Pattern: fields[0],
+			Owners:  fields[1:],
+			regexp:  re,
+		})
+	}
+	return rules
+}
+
+// codeOwnerPatternToRegexp converts a gitignore style pattern to a regular expression matching the paths it owns
+func codeOwnerPatternToRegexp(pattern string) (*regexp.Regexp, error) {
+	// a pattern containing a slash other than a trailing one is relative to the repository root
+	anchored := strings.Contains(strings.TrimSuffix(pattern, "/"), "/")
+	pattern = strings.TrimPrefix(pattern, "/")
+	dirOnly := strings.HasSuffix(pattern, "/")
+	pattern = strings.TrimSuffix(pattern, "/")
+
+	var sb strings.Builder
+	sb.WriteString("^")
+	if !anchored {
+		sb.WriteString("(?:.*/)?")
+	}
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; {
+		case strings.HasPrefix(pattern[i:], "**/"):
+			sb.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(pattern[i:], "**"):
+			sb.WriteString(".*")
+			i++
+		case c == '*':
+			sb.WriteString("[^/]*")
+		case c == '?':
+			sb.WriteString("[^/]")
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+
+	// A matching directory owns everything below it, except for patterns like "docs/*"
+	// which only match the files directly inside a directory
+	switch {
+	case dirOnly:
+		sb.WriteString("/.*")
+	case !strings.HasSuffix(pattern, "/*"):
+		sb.WriteString("(?:/.*)?")
+	}
+	sb.WriteString("$")
+
+	return regexp.Compile(sb.String())
+}
+
+// codeOwners are the resolved owners of a rule
+type codeOwners struct {
+	Users []*user_model.User
+	Teams []*organization.Team
+}
+
+// isCodeOwnerTeam returns whether the team can own code of the repository,
+// only teams of the organization owning the repository can review its pull requests
+func isCodeOwnerTeam(ctx context.Context, t *organization.Team, repo *repo_model.Repository) bool {
+	return t.OrgID == repo.OwnerID && organization.HasTeamRepo(ctx, t.OrgID, t.ID, repo.ID)
+}
+
+// resolveCodeOwners looks up the users and teams of the owners of a rule, skipping the ones that don't exist
+// and the teams which can't own code of the repository
+func resolveCodeOwners(ctx context.Context, rule *CodeOwnerRule, repo *repo_model.Repository) *codeOwners {
+	owners := &codeOwners{}
+	for _, owner := range rule.Owners {
+		if !strings.HasPrefix(owner, "@") {
+			u, err := user_model.GetUserByEmail(owner)
+			if err != nil {
+				if !user_model.IsErrUserNotExist(err) {
+					log.Error("GetUserByEmail: %v", err)
+				}
+				continue
+			}
+			owners.Users = append(owners.Users, u)
+			continue
+		}
+
+		names := strings.SplitN(owner[1:], "/", 2)
+		if len(names) == 1 {
+			u, err := user_model.GetUserByName(names[0])
+			if err != nil {
+				if !user_model.IsErrUserNotExist(err) {
+					log.Error("GetUserByName: %v", err)
+				}
+				continue
+			}
+			if !u.IsOrganization() {
+				owners.Users = append(owners.Users, u)
+			}
+			continue
+		}
+
+		org, err := organization.GetOrgByName(names[0])
+		if err != nil {
+			if !organization.IsErrOrgNotExist(err) {
+				log.Error("GetOrgByName: %v", err)
+			}
+			continue
+		}
+		team, err := organization.GetTeam(org.ID, names[1])
+		if err != nil {
+			if !organization.IsErrTeamNotExist(err) {
+				log.Error("GetTeam: %v", err)
+			}
+			continue
+		}
+		if !isCodeOwnerTeam(ctx, team, repo) {
+			continue
+		}
+		owners.Teams = append(owners.Teams, team)
+	}
+	return owners
+}
+
+// GetCodeOwnerRules reads the CODEOWNERS file of a commit. It returns nil if there is none.
+func GetCodeOwnerRules(commit *git.Commit) (CodeOwnerRules, error) {
+	for _, path := range CodeOwnersFiles {
+		content, err := commit.GetFileContent(path, codeOwnersMaxSize)
+		if err != nil {
+			if git.IsErrNotExist(err) {
+				continue
+			}
+			return nil, err
+		}
+		return ParseCodeOwners(content), nil
+	}
+	return nil, nil
+}
+
+// getPullCodeOwners returns the owners of each file changed by the pull request according to the CODEOWNERS
+// file of its base branch. Files without owners are left out, files whose owners can't be resolved are
+// returned without owners so that they can't be approved.
+func getPullCodeOwners(ctx context.Context, pr *models.PullRequest) (map[string]*codeOwners, error) {
+	if err := pr.LoadBaseRepoCtx(ctx); err != nil {
+		return nil, err
+	}
+
+	gitRepo, err := git.OpenRepository(ctx, pr.BaseRepo.RepoPath())
+	if err != nil {
+		return nil, err
+	}
+	defer gitRepo.Close()
+
+	commit, err := gitRepo.GetBranchCommit(pr.BaseBranch)
+	if err != nil {
+		return nil, err
+	}
+	rules, err := GetCodeOwnerRules(commit)
+	if err != nil || len(rules) == 0 {
+		return nil, err
+	}
+
+	mergeBase, _, err := gitRepo.GetMergeBase("", git.BranchPrefix+pr.BaseBranch, pr.GetGitRefName())
+	if err != nil {
+		return nil, err
+	}
+	files, err := gitRepo.GetFilesChangedBetween(mergeBase, pr.GetGitRefName())
+	if err != nil {
+		return nil, err
+	}
+
+	resolved := make(map[*CodeOwnerRule]*codeOwners)
+	fileOwners := make(map[string]*codeOwners, len(files))
+	for _, file := range files {
+		if file == "" {
+			continue
+		}
+		rule := rules.Match(file)
+		if rule == nil {
+			continue
+		}
+		owners, ok := resolved[rule]
+		if !ok {
+			owners = resolveCodeOwners(ctx, rule, pr.BaseRepo)
+			resolved[rule] = owners
+			if len(rule.Owners) > 0 && len(owners.Users) == 0 && len(owners.Teams) == 0 {
+				log.Warn("None of the code owners %v of %q in %s can be resolved", rule.Owners, rule.Pattern, pr.BaseRepo.FullName())
+			}
+		}
+		if len(rule.Owners) > 0 {
+			fileOwners[file] = owners
+		}
+	}
+	return fileOwners, nil
+}
+
+// RequestCodeOwnerReviews requests reviews from the code owners of the files changed by the pull request
+// who have not been requested or reviewed yet
+func RequestCodeOwnerReviews(ctx context.Context, pr *models.PullRequest, doer *user_model.User) error {
+	fileOwners, err := getPullCodeOwners(ctx, pr)
+	if err != nil || len(fileOwners) == 0 {
+		return err
+	}
+
+	if err := pr.LoadIssueCtx(ctx); err != nil {
+		return err
+	} else if err := pr.Issue.LoadRepo(ctx); err != nil {
+		return err
+	}
+	issue := pr.Issue
+
+	users := make(map[int64]*user_model.User)
+	teams := make(map[int64]*organization.Team)
+	for _, owners := range fileOwners {
+		for _, u := range owners.Users {
+			users[u.ID] = u
+		}
+		for _, t := range owners.Teams {
+			teams[t.ID] = t
+		}
+	}
+
+	for _, u := range users {
+		if u.ID == issue.PosterID || !u.IsActive || u.ProhibitLogin {
+			continue
+		}
+		if _, err := models.GetReviewByIssueIDAndUserID(issue.ID, u.ID); err == nil {
+			continue
+		} else if !models.IsErrReviewNotExist(err) {
+			return err
+		}
+		perm, err := access_model.GetUserRepoPermission(ctx, issue.Repo, u)
+		if err != nil {
+			return err
+		}
+		if !perm.CanRead(unit.TypePullRequests) {
+			continue
+		}
+		if _, err := issue_service.ReviewRequest(issue, doer, u, true); err != nil {
+			return err
+		}
+	}
+
+	for _, t := range teams {
+		if _, err := issue_service.TeamReviewRequest(issue, doer, t, true); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// IsApprovedByCodeOwners returns whether every file changed by the pull request that has code owners
+// has been approved by at least one of them
+func IsApprovedByCodeOwners(ctx context.Context, pr *models.PullRequest) (bool, error) {
+	fileOwners, err := getPullCodeOwners(ctx, pr)
+	if err != nil || len(fileOwners) == 0 {
+		return err == nil, err
+	}
+
+	if err := pr.LoadProtectedBranchCtx(ctx); err != nil {
+		return false, err
+	}
+	excludeStale := pr.ProtectedBranch != nil && pr.ProtectedBranch.DismissStaleApprovals
+	approverIDs, err := models.GetApproverIDs(ctx, pr.IssueID, excludeStale)
+	if err != nil {
+		return false, err
+	}
+
+	isApprovedBy := func(owners *codeOwners) (bool, error) {
+		for _, approverID := range approverIDs {
+			for _, u := range owners.Users {
+				if u.ID == approverID {
+					return true, nil
+				}
+			}
+			for _, t := range owners.Teams {
+				if isMember, err := organization.IsTeamMember(ctx, t.OrgID, t.ID, approverID); err != nil {
+					return false, err
+				} else if isMember {
+					return true, nil
+				}
+			}
+		}
+		return false, nil
+	}
+
+	for file, owners := range fileOwners {
+		approved, err := isApprovedBy(owners)
+		if err != nil {
+			return false, err
+		}
+		if !approved {
+			log.Trace("IsApprovedByCodeOwners [%d]: %s has not been approved by its code owners", pr.ID, file)
+			return false, nil
+		}
+	}
+	return true, nil
+}
@@ -0,0 +1,58 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package pull
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseCodeOwners(t *testing.T) {
+	rules := ParseCodeOwners(`# comment
+*                 @user1
+*.js              @user2 # trailing comment
+
+/docs/            @org3/team1 user4@example.com
+apps/             @user5
+/build/*          @user6
+**/logs           @user7
+src/**/test.go    @user8
+/UNOWNED.md
+`)
+	if !assert.Len(t, rules, 8) {
+		return
+	}
+	assert.Equal(t, "*.js", rules[1].Pattern)
+	assert.Equal(t, []string{"@user2"}, rules[1].Owners)
+	assert.Equal(t, []string{"@org3/team1", "user4@example.com"}, rules[2].Owners)
+	assert.Empty(t, rules[7].Owners)
+
+	cases := map[string]string{
+		"README.md":              "*",
+		"main.js":                "*.js",
+		"web_src/js/index.js":    "*.js",
+		"docs/index.md":          "/docs/",
+		"docs/api/index.js":      "/docs/",
+		"sub/docs/index.md":      "*",
+		"apps/main.go":           "apps/",
+		"cmd/apps/main.go":       "apps/",
+		"build/Makefile":         "/build/*",
+		"build/sub/Makefile":     "*",
+		"logs/today.log":         "**/logs",
+		"deep/down/logs/old.log": "**/logs",
+		"src/test.go":            "src/**/test.go",
+		"src/a/b/test.go":        "src/**/test.go",
+		"UNOWNED.md":             "/UNOWNED.md",
+	}
+	for path, pattern := range cases {
+		rule := rules.Match(path)
+		if assert.NotNil(t, rule, path) {
+			assert.Equal(t, pattern, rule.Pattern, path)
+		}
+	}
+
+	assert.Nil(t, ParseCodeOwners("/docs/ @user1").Match("README.md"))
+}
@@ -0,0 +1,339 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package pull
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"code.gitea.io/gitea/models"
+	repo_model "code.gitea.io/gitea/models/repo"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/log"
+	repo_module "code.gitea.io/gitea/modules/repository"
+	asymkey_service "code.gitea.io/gitea/services/asymkey"
+)
+
+// ConflictedFile is a file which has been changed on both the head and the base branch of a pull request in
+// a way that cannot be merged automatically
+type ConflictedFile struct {
+	TreePath string
+	// Head and Base are the contents of the file on the head and the base branch
+	Head string
+	Base string
+	// HeadDeleted and BaseDeleted are true if the file has been deleted on that branch
+	HeadDeleted bool
+	BaseDeleted bool
+	// Merged is the content of the file after merging the base branch into the head branch, with conflict markers
+	Merged   string
+	IsBinary bool
+}
+
+// conflictMarkers are the prefixes of the lines git marks a conflict with
+var conflictMarkers = []string{"<<<<<<< ", "=======", ">>>>>>> "}
+
+// HasConflictMarkers returns whether content still contains a conflict marked by git
+func HasConflictMarkers(content string) bool {
+	found := 0
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSuffix(line, "\r")
+		if found < len(conflictMarkers) && strings.HasPrefix(line, conflictMarkers[found]) &&
+			(conflictMarkers[found] != "=======" || line == "=======") {
+			found++
+		}
+	}
+	return found == len(conflictMarkers)
+}
+
+// GetConflictedFiles merges the base branch of a pull request into its head branch and returns the files which conflict
+// and the ID of the head commit they were computed for
+func GetConflictedFiles(ctx context.Context, pr *models.PullRequest) ([]*ConflictedFile, string, error) {
+	tmpBasePath, headCommitID, treePaths, err := mergeBaseIntoHead(ctx, pr)
+	if err != nil {
+		return nil, "", err
+	}
+	defer func() {
+		if err := repo_module.RemoveTemporaryPath(tmpBasePath); err != nil {
+			log.Error("GetConflictedFiles: RemoveTemporaryPath: %s", err)
+		}
+	}()
+
+	files := make([]*ConflictedFile, 0, len(treePaths))
+	for _, treePath := range treePaths {
+		stages, err := readUnmergedStages(ctx, tmpBasePath, treePath)
+		if err != nil {
+			return nil, "", err
+		}
+		file := &ConflictedFile{TreePath: treePath}
+		head, headExists := stages[2]
+		base, baseExists := stages[3]
+		file.Head, file.HeadDeleted = string(head), !headExists
+		file.Base, file.BaseDeleted = string(base), !baseExists
+		file.IsBinary = isBinaryContent(head) || isBinaryContent(base)
+
+		merged, err := os.ReadFile(filepath.Join(tmpBasePath, treePath))
+		if err != nil && !os.IsNotExist(err) {
+			return nil, "", err
+		}
+		file.Merged = string(merged)
+		files = append(files, file)
+	}
+	return files, headCommitID, nil
+}
+
+// ResolveConflicts commits a merge of the base branch of a pull request into its head branch, in which the
+// conflicted files are resolved with the given contents, and pushes it to the head branch.
+// headCommitID is the head commit the resolutions were made for, git.ErrPushOutOfDate is returned if the head
+// branch has changed since. It returns the ID of the merge commit.
+func ResolveConflicts(ctx context.Context, doer *user_model.User, pr *models.PullRequest, headCommitID string, resolutions map[string]string, message string) (string, error) {
+	pullWorkingPool.CheckIn(fmt.Sprint(pr.ID))
+	defer pullWorkingPool.CheckOut(fmt.Sprint(pr.ID))
+
+	tmpBasePath, currentHeadCommitID, treePaths, err := mergeBaseIntoHead(ctx, pr)
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		if err := repo_module.RemoveTemporaryPath(tmpBasePath); err != nil {
+			log.Error("ResolveConflicts: RemoveTemporaryPath: %s", err)
+		}
+	}()
+
+	if headCommitID != currentHeadCommitID {
+		return "", &git.ErrPushOutOfDate{
+			Err: fmt.Errorf("head branch %s is at %s instead of %s", pr.HeadBranch, currentHeadCommitID, headCommitID),
+		}
+	}
+
+	for _, treePath := range treePaths {
+		content, ok := resolutions[treePath]
+		if !ok || HasConflictMarkers(content) {
+			return "", models.ErrConflictNotResolved{TreePath: treePath}
+		}
+		if err := addResolvedFile(ctx, tmpBasePath, treePath, content); err != nil {
+			return "", err
+		}
+	}
+
+	if unmerged, err := listUnmergedFiles(ctx, tmpBasePath); err != nil {
+		return "", err
+	} else if len(unmerged) > 0 {
+		return "", models.ErrConflictNotResolved{TreePath: unmerged[0]}
+	}
+
+	// Sign the merge like when updating the head branch by merge
+	headPR := &models.PullRequest{
+		HeadRepoID: pr.BaseRepoID,
+		BaseRepoID: pr.HeadRepoID,
+		HeadBranch: pr.BaseBranch,
+		BaseBranch: pr.HeadBranch,
+	}
+	if err := headPR.LoadHeadRepoCtx(ctx); err != nil {
+		return "", err
+	} else if err := headPR.LoadBaseRepoCtx(ctx); err != nil {
+		return "", err
+	}
+
+	sig := doer.NewGitSig()
+	committer := sig
+	signArg := ""
+	if git.CheckGitVersionAtLeast("1.7.9") == nil {
+		sign, keyID, signer, _ := asymkey_service.SignMerge(ctx, headPR, doer, tmpBasePath, "HEAD", "base")
+		if sign {
+			signArg = "-S" + keyID
+			if pr.HeadRepo.GetTrustModel() == repo_model.CommitterTrustModel || pr.HeadRepo.GetTrustModel() == repo_model.CollaboratorCommitterTrustModel {
+				committer = signer
+			}
+		} else if git.CheckGitVersionAtLeast("2.0.0") == nil {
+			signArg = "--no-gpg-sign"
+		}
+	}
+
+	commitTimeStr := time.Now().Format(time.RFC3339)
+	env := append(os.Environ(),
+		"GIT_AUTHOR_NAME="+sig.Name,
+		"GIT_AUTHOR_EMAIL="+sig.Email,
+		"GIT_AUTHOR_DATE="+commitTimeStr,
+		"GIT_COMMITTER_NAME="+committer.Name,
+		"GIT_COMMITTER_EMAIL="+committer.Email,
+		"GIT_COMMITTER_DATE="+commitTimeStr,
+	)
+
+	message = strings.TrimSpace(message)
+	if message == "" {
+		message = fmt.Sprintf("Merge branch '%s' into %s", pr.BaseBranch, pr.HeadBranch)
+	}
+	if err := commitAndSignNoAuthor(ctx, pr, message, signArg, tmpBasePath, env); err != nil {
+		return "", err
+	}
+
+	commitID, _, err := git.NewCommand(ctx, "rev-parse", "HEAD").RunStdString(&git.RunOpts{Dir: tmpBasePath})
+	if err != nil {
+		return "", fmt.Errorf("git rev-parse HEAD: %v", err)
+	}
+	commitID = strings.TrimSpace(commitID)
+
+	var outbuf, errbuf strings.Builder
+	if err := git.NewCommand(ctx, "push", "head_repo", "HEAD:"+git.BranchPrefix+pr.HeadBranch).
+		Run(&git.RunOpts{
+			Env:    repo_module.FullPushingEnvironment(doer, doer, pr.HeadRepo, pr.HeadRepo.Name, 0),
+			Dir:    tmpBasePath,
+			Stdout: &outbuf,
+			Stderr: &errbuf,
+		}); err != nil {
+		if strings.Contains(errbuf.String(), "non-fast-forward") {
+			return "", &git.ErrPushOutOfDate{
+				StdOut: outbuf.String(),
+				StdErr: errbuf.String(),
+				Err:    err,
+			}
+		} else if strings.Contains(errbuf.String(), "! [remote rejected]") {
+			err := &git.ErrPushRejected{
+				StdOut: outbuf.String(),
+				StdErr: errbuf.String(),
+				Err:    err,
+			}
+			err.GenerateMessage()
+			return "", err
+		}
+		return "", fmt.Errorf("git push: %s", errbuf.String())
+	}
+	return commitID, nil
+}
+
+// mergeBaseIntoHead creates a temporary repository with the head branch of a pull request checked out and the
+// base branch merged into it without committing. It returns the path of the repository, the ID of the head commit
+// and the conflicted files.
+func mergeBaseIntoHead(ctx context.Context, pr *models.PullRequest) (tmpBasePath, headCommitID string, conflicted []string, err error) {
+	if pr.Flow != models.PullRequestFlowGithub {
+		return "", "", nil, fmt.Errorf("conflicts of agit pull request %d cannot be resolved", pr.ID)
+	}
+
+	tmpBasePath, err = createTemporaryRepo(ctx, pr)
+	if err != nil {
+		return "", "", nil, err
+	}
+	defer func() {
+		if err != nil {
+			if err := repo_module.RemoveTemporaryPath(tmpBasePath); err != nil {
+				log.Error("mergeBaseIntoHead: RemoveTemporaryPath: %s", err)
+			}
+		}
+	}()
+
+	// Switch off LFS process
+	for _, kv := range [][2]string{{"filter.lfs.process", ""}, {"filter.lfs.required", "false"}, {"filter.lfs.clean", ""}, {"filter.lfs.smudge", ""}} {
+		if _, _, err := git.NewCommand(ctx, "config", "--local", kv[0], kv[1]).RunStdString(&git.RunOpts{Dir: tmpBasePath}); err != nil {
+			return "", "", nil, fmt.Errorf("git config [%s -> <%s>]: %v", kv[0], kv[1], err)
+		}
+	}
+
+	if _, stderr, err := git.NewCommand(ctx, "checkout", "-f", "-q", "tracking").RunStdString(&git.RunOpts{Dir: tmpBasePath}); err != nil {
+		return "", "", nil, fmt.Errorf("git checkout tracking: %v\n%s", err, stderr)
+	}
+
+	headCommitID, _, err = git.NewCommand(ctx, "rev-parse", "HEAD").RunStdString(&git.RunOpts{Dir: tmpBasePath})
+	if err != nil {
+		return "", "", nil, fmt.Errorf("git rev-parse HEAD: %v", err)
+	}
+	headCommitID = strings.TrimSpace(headCommitID)
+
+	// The merge fails if there are conflicts, which are then left in the index and the work tree
+	_, mergeStderr, mergeErr := git.NewCommand(ctx, "merge", "--no-ff", "--no-commit", "base").RunStdString(&git.RunOpts{Dir: tmpBasePath})
+
+	conflicted, err = listUnmergedFiles(ctx, tmpBasePath)
+	if err != nil {
+		return "", "", nil, err
+	}
+	if mergeErr != nil && len(conflicted) == 0 {
+		return "", "", nil, fmt.Errorf("git merge base: %v\n%s", mergeErr, mergeStderr)
+	}
+	return tmpBasePath, headCommitID, conflicted, nil
+}
+
+// listUnmergedFiles returns the paths of the files with unresolved conflicts in the index
+func listUnmergedFiles(ctx context.Context, tmpBasePath string) ([]string, error) {
+	stdout, _, err := git.NewCommand(ctx, "diff", "--name-only", "--diff-filter=U", "-z").RunStdString(&git.RunOpts{Dir: tmpBasePath})
+	if err != nil {
+		return nil, fmt.Errorf("git diff --diff-filter=U: %v", err)
+	}
+	paths := make([]string, 0, 5)
+	for _, path := range strings.Split(stdout, "\x00") {
+		if path != "" {
+			paths = append(paths, path)
+		}
+	}
+	return paths, nil
+}
+
+// readUnmergedStages returns the contents of an unmerged file by stage: 1 is the merge base, 2 the head branch and 3 the base branch
+func readUnmergedStages(ctx context.Context, tmpBasePath, treePath string) (map[int][]byte, error) {
+	stdout, _, err := git.NewCommand(ctx, "ls-files", "-u", "-z", "--", treePath).RunStdString(&git.RunOpts{Dir: tmpBasePath})
+	if err != nil {
+		return nil, fmt.Errorf("git ls-files -u %s: %v", treePath, err)
+	}
+
+	stages := make(map[int][]byte, 3)
+	for _, line := range strings.Split(stdout, "\x00") {
+		// <mode> SP <object> SP <stage> TAB <file>
+		var mode, object string
+		var stage int
+		if _, err := fmt.Sscanf(line, "%s %s %d", &mode, &object, &stage); err != nil {
+			continue
+		}
+		var content bytes.Buffer
+		if err := git.NewCommand(ctx, "cat-file", "blob", object).Run(&git.RunOpts{Dir: tmpBasePath, Stdout: &content}); err != nil {
+			return nil, fmt.Errorf("git cat-file blob %s: %v", object, err)
+		}
+		stages[stage] = content.Bytes()
+	}
+	return stages, nil
+}
+
+// addResolvedFile adds the resolved content of a conflicted file to the index, keeping the line endings and
+// the mode of the file in the work tree
+func addResolvedFile(ctx context.Context, tmpBasePath, treePath, content string) error {
+	fullPath := filepath.Join(tmpBasePath, treePath)
+	mode := os.FileMode(0o644)
+	if info, err := os.Lstat(fullPath); err == nil {
+		if !info.Mode().IsRegular() {
+			return models.ErrConflictNotResolved{TreePath: treePath}
+		}
+		mode = info.Mode().Perm()
+		if merged, err := os.ReadFile(fullPath); err == nil && !bytes.Contains(merged, []byte("\r\n")) {
+			// browsers submit text areas with CRLF line endings
+			content = strings.ReplaceAll(content, "\r\n", "\n")
+		}
+	} else if os.IsNotExist(err) {
+		content = strings.ReplaceAll(content, "\r\n", "\n")
+	} else {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(fullPath), 0o755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(fullPath, []byte(content), mode); err != nil {
+		return err
+	}
+	if _, stderr, err := git.NewCommand(ctx, "add", "--", treePath).RunStdString(&git.RunOpts{Dir: tmpBasePath}); err != nil {
+		return fmt.Errorf("git add %s: %v\n%s", treePath, err, stderr)
+	}
+	return nil
+}
+
+// isBinaryContent guesses whether content is binary the way git does, by looking for a NUL byte at its start
+func isBinaryContent(content []byte) bool {
+	if len(content) > 8000 {
+		content = content[:8000]
+	}
+	return bytes.IndexByte(content, 0) >= 0
+}
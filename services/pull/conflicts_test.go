@@ -0,0 +1,20 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package pull
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHasConflictMarkers(t *testing.T) {
+	assert.True(t, HasConflictMarkers("a\n<<<<<<< HEAD\nb\n=======\nc\n>>>>>>> base\nd\n"))
+	assert.True(t, HasConflictMarkers("<<<<<<< HEAD\r\nb\r\n=======\r\nc\r\n>>>>>>> base\r\n"))
+	assert.False(t, HasConflictMarkers("a\nb\n"))
+	assert.False(t, HasConflictMarkers("Title\n=======\n"))
+	assert.False(t, HasConflictMarkers("<<<<<<< HEAD\nb\n======== \nc\n"))
+	assert.False(t, HasConflictMarkers(">>>>>>> base\n=======\n<<<<<<< HEAD\n"))
+}
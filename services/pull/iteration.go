@@ -0,0 +1,152 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package pull
+
+import (
+	"context"
+	"fmt"
+
+	"code.gitea.io/gitea/models"
+	pull_model "code.gitea.io/gitea/models/pull"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/json"
+	"code.gitea.io/gitea/modules/log"
+)
+
+// IterationRefName returns the reference which keeps the head commit of an iteration of a pull request
+// from being garbage collected after a force-push
+func IterationRefName(pr *models.PullRequest, index int64) string {
+	return fmt.Sprintf("%s%d/iterations/%d", git.PullPrefix, pr.Index, index)
+}
+
+// RecordIteration records the current head of a pull request as its next iteration. If the pull request has
+// no iterations yet because it was created before they were recorded, the previous head is recorded first.
+func RecordIteration(ctx context.Context, pr *models.PullRequest, pusher *user_model.User, oldCommitID string, comment *models.Comment) error {
+	if err := pr.LoadBaseRepoCtx(ctx); err != nil {
+		return err
+	}
+
+	gitRepo, closer, err := git.RepositoryFromContextOrOpen(ctx, pr.BaseRepo.RepoPath())
+	if err != nil {
+		return err
+	}
+	defer closer.Close()
+
+	if oldCommitID != "" {
+		if _, err := pull_model.GetLatestIteration(ctx, pr.ID); pull_model.IsErrIterationNotExist(err) {
+			if err := pr.LoadIssueCtx(ctx); err != nil {
+				return err
+			}
+			if err := createIteration(ctx, gitRepo, pr, &pull_model.Iteration{
+				PullID:       pr.ID,
+				PusherID:     pr.Issue.PosterID,
+				HeadCommitID: oldCommitID,
+			}); err != nil {
+				return err
+			}
+		} else if err != nil {
+			return err
+		}
+	}
+
+	headCommitID, err := gitRepo.GetRefCommitID(pr.GetGitRefName())
+	if err != nil {
+		return err
+	}
+	it := &pull_model.Iteration{
+		PullID:       pr.ID,
+		PusherID:     pusher.ID,
+		HeadCommitID: headCommitID,
+	}
+	if comment != nil {
+		var data models.PushActionContent
+		if err := json.Unmarshal([]byte(comment.Content), &data); err != nil {
+			return err
+		}
+		it.CommentID = comment.ID
+		it.IsForcePush = data.IsForcePush
+	}
+	return createIteration(ctx, gitRepo, pr, it)
+}
+
+func createIteration(ctx context.Context, gitRepo *git.Repository, pr *models.PullRequest, it *pull_model.Iteration) error {
+	mergeBase, _, err := gitRepo.GetMergeBase("", git.BranchPrefix+pr.BaseBranch, it.HeadCommitID)
+	if err != nil {
+		return err
+	}
+	it.MergeBase = mergeBase
+
+	created, err := pull_model.CreateIteration(ctx, it)
+	if err != nil || !created {
+		return err
+	}
+	return gitRepo.SetReference(IterationRefName(pr, it.Index), it.HeadCommitID)
+}
+
+// IterationComparison is the comparison of two iterations of a pull request
+type IterationComparison struct {
+	From *pull_model.Iteration
+	To   *pull_model.Iteration
+	// IsRebased is true if the head branch has been rebased or force-pushed between the iterations
+	IsRebased bool
+	// RangeDiff is the output of git range-diff comparing the commits of both iterations, only set if IsRebased
+	RangeDiff string
+}
+
+// CompareIterations compares two iterations of a pull request
+func CompareIterations(ctx context.Context, gitRepo *git.Repository, pr *models.PullRequest, fromIndex, toIndex int64) (*IterationComparison, error) {
+	iterations, err := pull_model.GetIterations(ctx, pr.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	cmp := &IterationComparison{}
+	for _, it := range iterations {
+		switch it.Index {
+		case fromIndex:
+			cmp.From = it
+		case toIndex:
+			cmp.To = it
+		}
+		if it.Index > fromIndex && it.Index <= toIndex && it.IsForcePush {
+			cmp.IsRebased = true
+		}
+	}
+	if cmp.From == nil {
+		return nil, pull_model.ErrIterationNotExist{PullID: pr.ID, Index: fromIndex}
+	}
+	if cmp.To == nil {
+		return nil, pull_model.ErrIterationNotExist{PullID: pr.ID, Index: toIndex}
+	}
+	if cmp.From.MergeBase != cmp.To.MergeBase {
+		cmp.IsRebased = true
+	}
+
+	if cmp.IsRebased {
+		if cmp.RangeDiff, err = RangeDiff(ctx, gitRepo, cmp.From, cmp.To); err != nil {
+			return nil, err
+		}
+	}
+	return cmp, nil
+}
+
+// RangeDiff returns the output of git range-diff comparing the commits of two iterations.
+// It is empty if git is too old to support range-diff.
+func RangeDiff(ctx context.Context, gitRepo *git.Repository, from, to *pull_model.Iteration) (string, error) {
+	if err := git.CheckGitVersionAtLeast("2.19"); err != nil {
+		log.Debug("git range-diff is not supported: %v", err)
+		return "", nil
+	}
+
+	stdout, _, err := git.NewCommand(ctx, "range-diff", "--no-color",
+		from.MergeBase+".."+from.HeadCommitID,
+		to.MergeBase+".."+to.HeadCommitID,
+	).RunStdString(&git.RunOpts{Dir: gitRepo.Path})
+	if err != nil {
+		return "", fmt.Errorf("git range-diff: %v", err)
+	}
+	return stdout, nil
+}
@@ -130,6 +130,7 @@ func GetDefaultMergeMessage(baseGitRepo *git.Repository, pr *models.PullRequest,
 
 // Merge merges pull request to base repository.
 // Caller should check PR is ready to be merged (review and status checks)
+// and add it to the merge queue instead if the base branch has one.
 func Merge(ctx context.Context, pr *models.PullRequest, doer *user_model.User, baseGitRepo *git.Repository, mergeStyle repo_model.MergeStyle, expectedHeadCommitID, message string) error {
 	if err := pr.LoadHeadRepo(); err != nil {
 		log.Error("LoadHeadRepo: %v", err)
@@ -139,6 +140,14 @@ func Merge(ctx context.Context, pr *models.PullRequest, doer *user_model.User, b
 		return fmt.Errorf("LoadBaseRepo: %v", err)
 	}
 
+	if err := pr.LoadProtectedBranchCtx(ctx); err != nil {
+		log.Error("LoadProtectedBranchCtx: %v", err)
+		return fmt.Errorf("LoadProtectedBranchCtx: %v", err)
+	}
+	if pr.ProtectedBranch != nil && pr.ProtectedBranch.EnableMergeQueue {
+		return ErrMergeQueueRequired
+	}
+
 	pullWorkingPool.CheckIn(fmt.Sprint(pr.ID))
 	defer pullWorkingPool.CheckOut(fmt.Sprint(pr.ID))
 
@@ -165,11 +174,18 @@ func Merge(ctx context.Context, pr *models.PullRequest, doer *user_model.User, b
 
 	// TODO: make it able to do this in a database session
 	mergeCtx := context.Background()
-	pr.MergedCommitID, err = rawMerge(mergeCtx, pr, doer, mergeStyle, expectedHeadCommitID, message)
+	mergedCommitID, err := rawMerge(mergeCtx, pr, doer, mergeStyle, expectedHeadCommitID, message)
 	if err != nil {
 		return err
 	}
 
+	return setMerged(ctx, pr, doer, mergedCommitID)
+}
+
+// setMerged marks the pull request as merged as mergedCommitID after it has been pushed to the base branch,
+// notifies about the merge and resolves the cross references of the pull request
+func setMerged(ctx context.Context, pr *models.PullRequest, doer *user_model.User, mergedCommitID string) error {
+	pr.MergedCommitID = mergedCommitID
 	pr.MergedUnix = timeutil.TimeStampNow()
 	pr.Merger = doer
 	pr.MergerID = doer.ID
@@ -190,6 +206,7 @@ func Merge(ctx context.Context, pr *models.PullRequest, doer *user_model.User, b
 	}
 
 	notification.NotifyMergePullRequest(pr, doer)
+	RetargetDependentPulls(pr)
 
 	// Reset cached commit count
 	cache.Remove(pr.Issue.Repo.GetCommitsCountCacheKey(pr.BaseBranch, true))
@@ -223,6 +240,12 @@ func Merge(ctx context.Context, pr *models.PullRequest, doer *user_model.User, b
 
 // rawMerge perform the merge operation without changing any pull information in database
 func rawMerge(ctx context.Context, pr *models.PullRequest, doer *user_model.User, mergeStyle repo_model.MergeStyle, expectedHeadCommitID, message string) (string, error) {
+	return rawMergeOnto(ctx, pr, doer, mergeStyle, expectedHeadCommitID, message, "", pr.BaseBranch)
+}
+
+// rawMergeOnto merges the pull request onto parentCommitID, or onto the base branch if it is empty,
+// and pushes the result to targetBranch of the base repository
+func rawMergeOnto(ctx context.Context, pr *models.PullRequest, doer *user_model.User, mergeStyle repo_model.MergeStyle, expectedHeadCommitID, message, parentCommitID, targetBranch string) (string, error) {
 	err := git.LoadGitVersion()
 	if err != nil {
 		log.Error("git.LoadGitVersion: %v", err)
@@ -261,6 +284,23 @@ func rawMerge(ctx context.Context, pr *models.PullRequest, doer *user_model.User
 
 	var outbuf, errbuf strings.Builder
 
+	if parentCommitID != "" {
+		// Build on top of the given commit instead of the base branch
+		for _, branch := range []string{baseBranch, "original_" + baseBranch} {
+			if err := git.NewCommand(ctx, "update-ref", git.BranchPrefix+branch, parentCommitID).
+				Run(&git.RunOpts{
+					Dir:    tmpBasePath,
+					Stdout: &outbuf,
+					Stderr: &errbuf,
+				}); err != nil {
+				log.Error("git update-ref %s %s: %v\n%s\n%s", branch, parentCommitID, err, outbuf.String(), errbuf.String())
+				return "", fmt.Errorf("git update-ref %s %s: %v\n%s\n%s", branch, parentCommitID, err, outbuf.String(), errbuf.String())
+			}
+			outbuf.Reset()
+			errbuf.Reset()
+		}
+	}
+
 	// Enable sparse-checkout
 	sparseCheckoutList, err := getDiffTree(ctx, tmpBasePath, baseBranch, trackingBranch)
 	if err != nil {
@@ -510,6 +550,13 @@ func rawMerge(ctx context.Context, pr *models.PullRequest, doer *user_model.User
 				return "", err
 			}
 		}
+	case repo_model.MergeStyleFastForwardOnly:
+		// Fast-forward to the head, so the commits are kept as they are including their signatures
+		cmd := git.NewCommand(ctx, "merge", "--ff-only", trackingBranch)
+		if err := runMergeCommand(pr, mergeStyle, cmd, tmpBasePath); err != nil {
+			log.Error("Unable to fast-forward base to tracking: %v", err)
+			return "", err
+		}
 	case repo_model.MergeStyleSquash:
 		// Merge with squash
 		cmd := git.NewCommand(ctx, "merge", "--squash", trackingBranch)
@@ -604,6 +651,9 @@ func rawMerge(ctx context.Context, pr *models.PullRequest, doer *user_model.User
 	if mergeStyle == repo_model.MergeStyleRebaseUpdate {
 		// force push the rebase result to head branch
 		pushCmd = git.NewCommand(ctx, "push", "-f", "head_repo", stagingBranch+":"+git.BranchPrefix+pr.HeadBranch)
+	} else if targetBranch != pr.BaseBranch {
+		// the target branch is rebuilt from scratch, so overwrite it
+		pushCmd = git.NewCommand(ctx, "push", "-f", "origin", baseBranch+":"+git.BranchPrefix+targetBranch)
 	} else {
 		pushCmd = git.NewCommand(ctx, "push", "origin", baseBranch+":"+git.BranchPrefix+pr.BaseBranch)
 	}
@@ -685,6 +735,13 @@ func runMergeCommand(pr *models.PullRequest, mergeStyle repo_model.MergeStyle, c
 				StdErr: errbuf.String(),
 				Err:    err,
 			}
+		} else if strings.Contains(errbuf.String(), "Not possible to fast-forward") {
+			log.Debug("MergeDivergingFastForwardOnly [%s:%s -> %s:%s]: %v\n%s\n%s", pr.HeadRepo.FullName(), pr.HeadBranch, pr.BaseRepo.FullName(), pr.BaseBranch, err, outbuf.String(), errbuf.String())
+			return models.ErrMergeDivergingFastForwardOnly{
+				StdOut: outbuf.String(),
+				StdErr: errbuf.String(),
+				Err:    err,
+			}
 		} else if strings.Contains(errbuf.String(), "refusing to merge unrelated histories") {
 			log.Debug("MergeUnrelatedHistories [%s:%s -> %s:%s]: %v\n%s\n%s", pr.HeadRepo.FullName(), pr.HeadBranch, pr.BaseRepo.FullName(), pr.BaseBranch, err, outbuf.String(), errbuf.String())
 			return models.ErrMergeUnrelatedHistories{
@@ -807,6 +864,17 @@ func CheckPullBranchProtections(ctx context.Context, pr *models.PullRequest, ski
 			Reason: "There are official review requests",
 		}
 	}
+	if pr.ProtectedBranch.RequireCodeOwnerApproval {
+		approved, err := IsApprovedByCodeOwners(ctx, pr)
+		if err != nil {
+			return fmt.Errorf("IsApprovedByCodeOwners: %v", err)
+		}
+		if !approved {
+			return models.ErrDisallowedToMerge{
+				Reason: "Not all changed files have been approved by their code owners",
+			}
+		}
+	}
 
 	if pr.ProtectedBranch.MergeBlockedByOutdatedBranch(pr) {
 		return models.ErrDisallowedToMerge{
@@ -883,6 +951,7 @@ func MergedManually(pr *models.PullRequest, doer *user_model.User, baseGitRepo *
 	}
 
 	notification.NotifyMergePullRequest(pr, doer)
+	RetargetDependentPulls(pr)
 	log.Info("manuallyMerged[%d]: Marked as manually merged into %s/%s by commit id: %s", pr.ID, pr.BaseRepo.Name, pr.BaseBranch, commitID)
 	return nil
 }
@@ -0,0 +1,104 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package pull
+
+import (
+	"context"
+	"fmt"
+
+	"code.gitea.io/gitea/models"
+	repo_model "code.gitea.io/gitea/models/repo"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/log"
+	repo_module "code.gitea.io/gitea/modules/repository"
+)
+
+// MergeQueueBranchPrefix is the prefix of the branches the temporary merge commits of merge queues are pushed to
+const MergeQueueBranchPrefix = "merge-queue/"
+
+// MergeQueueBranch returns the name of the branch the temporary merge commit of the pull request is pushed to
+func MergeQueueBranch(pr *models.PullRequest) string {
+	return fmt.Sprintf("%s%s/pr-%d", MergeQueueBranchPrefix, pr.BaseBranch, pr.Index)
+}
+
+// BuildMergeQueueCommit merges the pull request onto parentCommitID the way it will be merged into the base branch
+// and pushes the result to the merge queue branch of the pull request, so that the status checks run against it.
+// It returns the id of the temporary merge commit.
+func BuildMergeQueueCommit(ctx context.Context, pr *models.PullRequest, doer *user_model.User, mergeStyle repo_model.MergeStyle, expectedHeadCommitID, message, parentCommitID string) (string, error) {
+	if err := pr.LoadBaseRepoCtx(ctx); err != nil {
+		return "", err
+	}
+	// the result of a merge queue entry has to end up on the base branch
+	if mergeStyle == repo_model.MergeStyleRebaseUpdate || mergeStyle == repo_model.MergeStyleManuallyMerged {
+		return "", models.ErrInvalidMergeStyle{ID: pr.BaseRepoID, Style: mergeStyle}
+	}
+
+	pullWorkingPool.CheckIn(fmt.Sprint(pr.ID))
+	defer pullWorkingPool.CheckOut(fmt.Sprint(pr.ID))
+
+	return rawMergeOnto(ctx, pr, doer, mergeStyle, expectedHeadCommitID, message, parentCommitID, MergeQueueBranch(pr))
+}
+
+// MergeFromQueue fast-forwards the base branch of the pull request to its temporary merge commit
+// and marks the pull request as merged
+func MergeFromQueue(ctx context.Context, pr *models.PullRequest, doer *user_model.User, commitID string) error {
+	if err := pr.LoadHeadRepoCtx(ctx); err != nil {
+		return err
+	} else if err := pr.LoadBaseRepoCtx(ctx); err != nil {
+		return err
+	}
+
+	pullWorkingPool.CheckIn(fmt.Sprint(pr.ID))
+	defer pullWorkingPool.CheckOut(fmt.Sprint(pr.ID))
+
+	headUser := doer
+	if pr.HeadRepo != nil {
+		if err := pr.HeadRepo.GetOwner(ctx); err != nil {
+			if !user_model.IsErrUserNotExist(err) {
+				return err
+			}
+			log.Error("Can't find user: %d for head repository - defaulting to doer: %s - %v", pr.HeadRepo.OwnerID, doer.Name, err)
+		} else {
+			headUser = pr.HeadRepo.Owner
+		}
+	}
+
+	// Without force the push is refused unless it is a fast-forward of the base branch
+	if err := git.Push(ctx, pr.BaseRepo.RepoPath(), git.PushOptions{
+		Remote: pr.BaseRepo.RepoPath(),
+		Branch: commitID + ":" + git.BranchPrefix + pr.BaseBranch,
+		Env:    repo_module.FullPushingEnvironment(headUser, doer, pr.BaseRepo, pr.BaseRepo.Name, pr.ID),
+	}); err != nil {
+		if git.IsErrPushOutOfDate(err) || git.IsErrPushRejected(err) {
+			return err
+		}
+		return fmt.Errorf("Push: %v", err)
+	}
+
+	if err := DeleteMergeQueueBranch(ctx, pr, doer); err != nil {
+		log.Error("DeleteMergeQueueBranch [%d]: %v", pr.ID, err)
+	}
+
+	return setMerged(ctx, pr, doer, commitID)
+}
+
+// DeleteMergeQueueBranch deletes the merge queue branch of the pull request if it exists
+func DeleteMergeQueueBranch(ctx context.Context, pr *models.PullRequest, doer *user_model.User) error {
+	if err := pr.LoadBaseRepoCtx(ctx); err != nil {
+		return err
+	}
+
+	branch := MergeQueueBranch(pr)
+	if !git.IsBranchExist(ctx, pr.BaseRepo.RepoPath(), branch) {
+		return nil
+	}
+
+	return git.Push(ctx, pr.BaseRepo.RepoPath(), git.PushOptions{
+		Remote: pr.BaseRepo.RepoPath(),
+		Branch: ":" + git.BranchPrefix + branch,
+		Env:    repo_module.PushingEnvironment(doer, pr.BaseRepo),
+	})
+}
@@ -100,6 +100,7 @@ func NewPullRequest(ctx context.Context, repo *repo_model.Repository, pull *mode
 		return err
 	}
 
+	var pushComment *models.Comment
 	if len(compareInfo.Commits) > 0 {
 		data := models.PushActionContent{IsForcePush: false}
 		data.CommitIDs = make([]string, 0, len(compareInfo.Commits))
@@ -121,7 +122,15 @@ func NewPullRequest(ctx context.Context, repo *repo_model.Repository, pull *mode
 			Content:     string(dataJSON),
 		}
 
-		_, _ = models.CreateComment(ops)
+		pushComment, _ = models.CreateComment(ops)
+	}
+
+	if err := RecordIteration(prCtx, pr, pull.Poster, "", pushComment); err != nil {
+		log.Error("RecordIteration [%d]: %v", pr.ID, err)
+	}
+
+	if err := RequestCodeOwnerReviews(prCtx, pr, pull.Poster); err != nil {
+		log.Error("RequestCodeOwnerReviews [%d]: %v", pr.ID, err)
 	}
 
 	return nil
@@ -318,6 +327,15 @@ func AddTestPullRequestTask(doer *user_model.User, repoID int64, branch string,
 			comment, err := models.CreatePushPullComment(ctx, doer, pr, oldCommitID, newCommitID)
 			if err == nil && comment != nil {
 				notification.NotifyPullRequestPushCommits(doer, pr, comment)
+				if err := RecordIteration(ctx, pr, doer, oldCommitID, comment); err != nil {
+					log.Error("RecordIteration [%d]: %v", pr.ID, err)
+				}
+			}
+
+			if isSync {
+				if err := RequestCodeOwnerReviews(ctx, pr, doer); err != nil {
+					log.Error("RequestCodeOwnerReviews [%d]: %v", pr.ID, err)
+				}
 			}
 		}
 
@@ -0,0 +1,226 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package pull
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/models/unit"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/graceful"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/process"
+	"code.gitea.io/gitea/modules/queue"
+)
+
+// prRetargetQueue represents a queue to handle the pull requests stacked onto merged pull requests
+var prRetargetQueue queue.UniqueQueue
+
+// StackEntry is a pull request of a stack of pull requests which are based on the head branches of each other
+type StackEntry struct {
+	Pull *models.PullRequest
+	// Depth is the number of pull requests below this one, the pull request at the bottom of the stack has depth 0
+	Depth int
+}
+
+// maxStackDepth limits how far a stack is followed in either direction
+const maxStackDepth = 20
+
+// GetStack returns the stack of open pull requests the given pull request belongs to, from its bottom to its top.
+// Pull requests based on the same branch are listed after each other with the same depth.
+// The stack only contains the given pull request if no other open pull request is stacked onto or below it.
+func GetStack(ctx context.Context, pr *models.PullRequest) ([]*StackEntry, error) {
+	seen := map[int64]bool{pr.ID: true}
+
+	// follow the base branches down to the bottom of the stack
+	below := make([]*models.PullRequest, 0, 2)
+	for current := pr; current.HeadRepoID == current.BaseRepoID && len(below) < maxStackDepth; {
+		parents, err := models.GetUnmergedPullRequestsByHeadInfo(current.BaseRepoID, current.BaseBranch)
+		if err != nil {
+			return nil, err
+		}
+		var parent *models.PullRequest
+		for _, p := range parents {
+			if p.BaseRepoID == current.BaseRepoID && !seen[p.ID] {
+				parent = p
+				break
+			}
+		}
+		if parent == nil {
+			break
+		}
+		seen[parent.ID] = true
+		below = append(below, parent)
+		current = parent
+	}
+
+	stack := make([]*StackEntry, 0, len(below)+2)
+	for i := len(below) - 1; i >= 0; i-- {
+		stack = append(stack, &StackEntry{Pull: below[i], Depth: len(below) - 1 - i})
+	}
+	stack = append(stack, &StackEntry{Pull: pr, Depth: len(below)})
+
+	stack, err := appendStackedPulls(stack, pr, len(below)+1, seen)
+	if err != nil {
+		return nil, err
+	}
+
+	prs := make(models.PullRequestList, 0, len(stack))
+	for _, entry := range stack {
+		if entry.Pull.Issue == nil {
+			prs = append(prs, entry.Pull)
+		}
+	}
+	if err := prs.LoadAttributes(); err != nil {
+		return nil, err
+	}
+	return stack, nil
+}
+
+// appendStackedPulls appends the open pull requests stacked onto the head branch of pr depth-first
+func appendStackedPulls(stack []*StackEntry, pr *models.PullRequest, depth int, seen map[int64]bool) ([]*StackEntry, error) {
+	if pr.HeadRepoID != pr.BaseRepoID || depth > maxStackDepth {
+		return stack, nil
+	}
+
+	children, err := models.GetUnmergedPullRequestsByBaseInfo(pr.HeadRepoID, pr.HeadBranch)
+	if err != nil {
+		return nil, err
+	}
+	for _, child := range children {
+		if seen[child.ID] {
+			continue
+		}
+		seen[child.ID] = true
+		stack = append(stack, &StackEntry{Pull: child, Depth: depth})
+		if stack, err = appendStackedPulls(stack, child, depth+1, seen); err != nil {
+			return nil, err
+		}
+	}
+	return stack, nil
+}
+
+func initRetargetQueue() error {
+	prRetargetQueue = queue.CreateUniqueQueue("pr_retarget", handleRetarget, "")
+	if prRetargetQueue == nil {
+		return fmt.Errorf("Unable to create pr_retarget Queue")
+	}
+	go graceful.GetManager().RunWithShutdownFns(prRetargetQueue.Run)
+	return nil
+}
+
+// handleRetarget retargets the pull requests stacked onto the passed merged PR IDs
+func handleRetarget(data ...queue.Data) []queue.Data {
+	for _, datum := range data {
+		id, _ := strconv.ParseInt(datum.(string), 10, 64)
+
+		retargetPR(id)
+	}
+	return nil
+}
+
+func retargetPR(id int64) {
+	ctx, _, finished := process.GetManager().AddContext(graceful.GetManager().HammerContext(), fmt.Sprintf("Retarget pull requests stacked onto PR[%d]", id))
+	defer finished()
+
+	pr, err := models.GetPullRequestByID(ctx, id)
+	if err != nil {
+		log.Error("GetPullRequestByID[%d]: %v", id, err)
+		return
+	}
+	if !pr.HasMerged {
+		return
+	}
+	if err := pr.LoadAttributes(); err != nil {
+		log.Error("LoadAttributes[%d]: %v", id, err)
+		return
+	}
+
+	retargetDependentPulls(ctx, pr, pr.Merger)
+}
+
+// RetargetDependentPulls queues the retargeting of the open pull requests based on the head branch of a merged
+// pull request, see retargetDependentPulls. The pull requests are changed by the merger of the pull request.
+func RetargetDependentPulls(pr *models.PullRequest) {
+	if pr.HeadRepoID != pr.BaseRepoID {
+		return
+	}
+	if err := prRetargetQueue.PushFunc(strconv.FormatInt(pr.ID, 10), func() error {
+		log.Trace("Adding PR ID: %d to the pull requests retarget queue", pr.ID)
+		return nil
+	}); err != nil {
+		log.Error("Error adding prID: %d to the pull requests retarget queue %v", pr.ID, err)
+	}
+}
+
+// retargetDependentPulls changes the base branch of the open pull requests based on the head branch of a
+// merged pull request to its base branch, and rebases them onto it if the repository is configured to.
+// Failures are only logged because the merge itself has already succeeded.
+func retargetDependentPulls(ctx context.Context, pr *models.PullRequest, doer *user_model.User) {
+	if pr.HeadRepoID != pr.BaseRepoID {
+		return
+	}
+
+	children, err := models.GetUnmergedPullRequestsByBaseInfo(pr.HeadRepoID, pr.HeadBranch)
+	if err != nil {
+		log.Error("GetUnmergedPullRequestsByBaseInfo[%d, %s]: %v", pr.HeadRepoID, pr.HeadBranch, err)
+		return
+	}
+	if len(children) == 0 {
+		return
+	}
+	if err := models.PullRequestList(children).LoadAttributes(); err != nil {
+		log.Error("LoadAttributes: %v", err)
+		return
+	}
+
+	if err := pr.LoadBaseRepoCtx(ctx); err != nil {
+		log.Error("LoadBaseRepo[%d]: %v", pr.ID, err)
+		return
+	}
+	rebase := false
+	if prUnit, err := pr.BaseRepo.GetUnit(unit.TypePullRequests); err == nil {
+		rebase = prUnit.PullRequestsConfig().RebaseDependentPullsAfterMerge
+	}
+
+	for _, child := range children {
+		if err := child.Issue.LoadRepo(ctx); err != nil {
+			log.Error("LoadRepo[%d]: %v", child.IssueID, err)
+			continue
+		}
+		if err := ChangeTargetBranch(ctx, child, doer, pr.BaseBranch); err != nil {
+			log.Warn("Unable to retarget pull request %d from %s to %s: %v", child.ID, pr.HeadBranch, pr.BaseBranch, err)
+			continue
+		}
+		if rebase {
+			rebaseDependentPull(ctx, child, doer)
+		}
+	}
+}
+
+func rebaseDependentPull(ctx context.Context, pr *models.PullRequest, doer *user_model.User) {
+	if err := pr.LoadHeadRepoCtx(ctx); err != nil {
+		log.Error("LoadHeadRepo[%d]: %v", pr.ID, err)
+		return
+	} else if err := pr.LoadBaseRepoCtx(ctx); err != nil {
+		log.Error("LoadBaseRepo[%d]: %v", pr.ID, err)
+		return
+	}
+	if pr.HeadRepo == nil {
+		return
+	}
+	if _, rebaseAllowed, err := IsUserAllowedToUpdate(ctx, pr, doer); err != nil {
+		log.Error("IsUserAllowedToUpdate[%d]: %v", pr.ID, err)
+		return
+	} else if !rebaseAllowed {
+		return
+	}
+	if err := Update(ctx, pr, doer, "", true); err != nil {
+		log.Warn("Unable to rebase pull request %d onto %s: %v", pr.ID, pr.BaseBranch, err)
+	}
+}
@@ -15,6 +15,7 @@ import (
 	"code.gitea.io/gitea/modules/git"
 	"code.gitea.io/gitea/modules/structs"
 	"code.gitea.io/gitea/services/automerge"
+	"code.gitea.io/gitea/services/mergequeue"
 )
 
 // CreateCommitStatus creates a new CommitStatus given a bunch of parameters
@@ -51,6 +52,11 @@ func CreateCommitStatus(ctx context.Context, repo *repo_model.Repository, creato
 		}
 	}
 
+	// merge queues wait for any status of their merge commits, failures remove pull requests from the queue
+	if err := mergequeue.HandleCommitStatus(ctx, repo, sha); err != nil {
+		return fmt.Errorf("HandleCommitStatus[repo_id: %d, user_id: %d, sha: %s]: %w", repo.ID, creator.ID, sha, err)
+	}
+
 	return nil
 }
 
@@ -0,0 +1,222 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package files
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"code.gitea.io/gitea/models"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/git"
+	asymkey_service "code.gitea.io/gitea/services/asymkey"
+)
+
+// DefaultSuggestionsCommitMessage is the commit message used when applying suggestions without a message
+const DefaultSuggestionsCommitMessage = "Apply suggestions from code review"
+
+// ApplyCodeSuggestions commits the suggestions of the given code comments of a pull request as a single commit
+// onto its head branch and returns the ID of the commit and the number of applied suggestions. The commented
+// lines must not have changed since the comments were made.
+func ApplyCodeSuggestions(ctx context.Context, doer *user_model.User, pr *models.PullRequest, commentIDs []int64, message string) (string, int, error) {
+	if err := pr.LoadHeadRepoCtx(ctx); err != nil {
+		return "", 0, err
+	}
+	if pr.HeadRepo == nil || pr.Flow == models.PullRequestFlowAGit {
+		return "", 0, fmt.Errorf("pull request %d has no head branch to apply suggestions to", pr.ID)
+	}
+
+	comments, err := loadSuggestionComments(pr, commentIDs)
+	if err != nil {
+		return "", 0, err
+	}
+
+	protectedBranch, err := models.GetProtectedBranchBy(pr.HeadRepo.ID, pr.HeadBranch)
+	if err != nil {
+		return "", 0, err
+	}
+	if protectedBranch != nil && !protectedBranch.CanUserPush(doer.ID) {
+		return "", 0, models.ErrUserCannotCommit{
+			UserName: doer.LowerName,
+		}
+	}
+	if protectedBranch != nil && protectedBranch.RequireSignedCommits {
+		_, _, _, err := asymkey_service.SignCRUDAction(ctx, pr.HeadRepo.RepoPath(), doer, pr.HeadRepo.RepoPath(), pr.HeadBranch)
+		if err != nil {
+			if !asymkey_service.IsErrWontSign(err) {
+				return "", 0, err
+			}
+			return "", 0, models.ErrUserCannotCommit{
+				UserName: doer.LowerName,
+			}
+		}
+	}
+
+	t, err := NewTemporaryUploadRepository(ctx, pr.HeadRepo)
+	if err != nil {
+		return "", 0, err
+	}
+	defer t.Close()
+	if err := t.Clone(pr.HeadBranch); err != nil {
+		return "", 0, err
+	}
+	if err := t.SetDefaultIndex(); err != nil {
+		return "", 0, err
+	}
+
+	commit, err := t.GetBranchCommit(pr.HeadBranch)
+	if err != nil {
+		return "", 0, err
+	}
+
+	// The suggestions of a file are applied from its bottom up so that the line numbers of the others stay valid
+	sort.SliceStable(comments, func(i, j int) bool {
+		if comments[i].TreePath != comments[j].TreePath {
+			return comments[i].TreePath < comments[j].TreePath
+		}
+		return comments[i].Line > comments[j].Line
+	})
+
+	for i := 0; i < len(comments); {
+		treePath := comments[i].TreePath
+		entry, err := commit.GetTreeEntryByPath(treePath)
+		if err != nil {
+			if git.IsErrNotExist(err) {
+				return "", 0, errSuggestionNotApplicable(comments[i])
+			}
+			return "", 0, err
+		}
+		if !entry.IsRegular() && !entry.IsExecutable() {
+			return "", 0, errSuggestionNotApplicable(comments[i])
+		}
+		content, err := entry.Blob().GetBlobContent()
+		if err != nil {
+			return "", 0, err
+		}
+
+		lines := strings.Split(content, "\n")
+		for ; i < len(comments) && comments[i].TreePath == treePath; i++ {
+			comment := comments[i]
+			idx := int(comment.Line) - 1
+			// two suggestions replacing the same line cannot be applied together
+			if i > 0 && comments[i-1].TreePath == treePath && comments[i-1].Line == comment.Line {
+				return "", 0, errSuggestionNotApplicable(comment)
+			}
+			if idx >= len(lines) || strings.TrimSuffix(lines[idx], "\r") != comment.Suggestion.OldLine {
+				return "", 0, errSuggestionNotApplicable(comment)
+			}
+
+			// keep the line endings of the file
+			eol := ""
+			if strings.HasSuffix(lines[idx], "\r") {
+				eol = "\r"
+			}
+			replaced := make([]string, 0, len(lines)+len(comment.Suggestion.NewLines)-1)
+			replaced = append(replaced, lines[:idx]...)
+			for _, line := range comment.Suggestion.NewLines {
+				replaced = append(replaced, line+eol)
+			}
+			lines = append(replaced, lines[idx+1:]...)
+		}
+
+		objectHash, err := t.HashObject(strings.NewReader(strings.Join(lines, "\n")))
+		if err != nil {
+			return "", 0, err
+		}
+		mode := "100644"
+		if entry.IsExecutable() {
+			mode = "100755"
+		}
+		if err := t.AddObjectToIndex(mode, objectHash, treePath); err != nil {
+			return "", 0, err
+		}
+	}
+
+	treeHash, err := t.WriteTree()
+	if err != nil {
+		return "", 0, err
+	}
+
+	message = strings.TrimSpace(message)
+	if message == "" {
+		message = DefaultSuggestionsCommitMessage
+	}
+	message += suggestionsCoAuthors(doer, comments)
+
+	author, committer := GetAuthorAndCommitterUsers(nil, nil, doer)
+	commitHash, err := t.CommitTree("HEAD", author, committer, treeHash, message, false)
+	if err != nil {
+		return "", 0, err
+	}
+
+	if err := t.Push(doer, commitHash, pr.HeadBranch); err != nil {
+		return "", 0, err
+	}
+	return commitHash, len(comments), nil
+}
+
+// loadSuggestionComments loads the code comments of a pull request with a suggestion that can be applied
+func loadSuggestionComments(pr *models.PullRequest, commentIDs []int64) ([]*models.Comment, error) {
+	if len(commentIDs) == 0 {
+		return nil, fmt.Errorf("no suggestions to apply")
+	}
+
+	comments := make([]*models.Comment, 0, len(commentIDs))
+	seen := make(map[int64]bool, len(commentIDs))
+	for _, id := range commentIDs {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		comment, err := models.GetCommentByID(id)
+		if err != nil {
+			return nil, err
+		}
+		if comment.IssueID != pr.IssueID || comment.Type != models.CommentTypeCode {
+			return nil, models.ErrCommentNotExist{ID: id, IssueID: pr.IssueID}
+		}
+		if err := comment.LoadReview(); err != nil {
+			return nil, err
+		}
+		if comment.LoadCodeSuggestion(); !comment.CanApplySuggestion() {
+			return nil, errSuggestionNotApplicable(comment)
+		}
+		comments = append(comments, comment)
+	}
+	return comments, nil
+}
+
+// suggestionsCoAuthors returns the Co-authored-by trailers crediting the authors of the suggestions
+func suggestionsCoAuthors(doer *user_model.User, comments []*models.Comment) string {
+	var sb strings.Builder
+	seen := map[int64]bool{doer.ID: true}
+	for _, comment := range comments {
+		if seen[comment.PosterID] {
+			continue
+		}
+		seen[comment.PosterID] = true
+		if err := comment.LoadPoster(); err != nil || comment.Poster.ID <= 0 {
+			continue
+		}
+		if sb.Len() == 0 {
+			sb.WriteString("\n\n")
+		}
+		sb.WriteString("Co-authored-by: ")
+		sb.WriteString(comment.Poster.NewGitSig().String())
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+func errSuggestionNotApplicable(comment *models.Comment) error {
+	return models.ErrSuggestionNotApplicable{
+		CommentID: comment.ID,
+		TreePath:  comment.TreePath,
+		Line:      comment.Line,
+	}
+}
@@ -40,7 +40,7 @@ export function initRepoDiffFileViewToggle() {
 }
 
 export function initRepoDiffConversationForm() {
-  $(document).on('submit', '.conversation-holder form', async (e) => {
+  $(document).on('submit', '.conversation-holder form:not(.suggestion-apply-form)', async (e) => {
     e.preventDefault();
 
     const form = $(e.target);
@@ -83,6 +83,20 @@ export function initRepoDiffConversationForm() {
   });
 }
 
+export function initRepoDiffSuggestions() {
+  // Checked suggestions are applied together by the apply button of any of them
+  $(document).on('change', '.suggestion-batch-checkbox', () => {
+    const ids = $('.suggestion-batch-checkbox:checked').map((_, el) => el.value).get();
+    $('.suggestion-apply-form').each(function () {
+      const $form = $(this);
+      $form.find('input[name="comment_ids"]').val(ids.length ? ids.join(',') : $form.data('comment-id'));
+      $form.find('.suggestion-apply-single').toggleClass('hide', ids.length > 0);
+      $form.find('.suggestion-apply-batch').toggleClass('hide', ids.length === 0);
+      $form.find('.suggestion-batch-count').text(ids.length);
+    });
+  });
+}
+
 export function initRepoDiffConversationNav() {
   // Previous/Next code review conversation
   $(document).on('click', '.previous-conversation', (e) => {
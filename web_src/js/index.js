@@ -28,6 +28,7 @@ import {
   initRepoDiffConversationForm,
   initRepoDiffFileViewToggle,
   initRepoDiffReviewButton, initRepoDiffShowMore,
+  initRepoDiffSuggestions,
 } from './features/repo-diff.js';
 import {
   initRepoIssueDue,
@@ -148,6 +149,7 @@ $(document).ready(() => {
   initRepoDiffFileViewToggle();
   initRepoDiffReviewButton();
   initRepoDiffShowMore();
+  initRepoDiffSuggestions();
   initRepoEditor();
   initRepoGraphGit();
   initRepoIssueContentHistory();